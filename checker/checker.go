@@ -0,0 +1,428 @@
+/*
+File    : go-mix/checker/checker.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+/*
+Package checker verifies the type annotations of a Go-Mix program before it
+runs (go-mix check file.gm).
+
+The checker infers the types of expressions from literals, operators,
+annotated variables, let and const declarations, and the declared return
+types of the functions and methods called, then verifies:
+  - The initial value of an annotated variable or struct field
+  - Assignments to annotated variables, let variables and annotated fields
+  - The arguments of calls to functions, methods and constructors with
+    annotated parameters
+  - The values returned by functions with a declared return type, including
+    the value of the last statement when a function reaches the end of its
+    body without a return (a generator function returns a generator)
+  - That the annotations only name builtin types or declared types
+  - That a match naming members of an enum covers all of them
+
+Expressions whose type cannot be inferred (the results of most builtins,
+the elements of unannotated collections, imported values, ...) are accepted
+everywhere. Assignments to fields are checked when the struct of the
+instance is known (this, a let, const or annotated variable, a constructor
+call, ...); a var variable without an annotation may be reassigned any
+value, so the fields of the instance it holds are not checked. The evaluator
+still checks every value against the annotations
+at runtime, so a program that passes the checker cannot store a value of
+the wrong type unnoticed.
+*/
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// binding is what the checker knows about a name.
+type binding struct {
+	typ   *std.TypeAnnotation           // The type of the name's values (nil if unknown)
+	exact bool                          // Whether the values must have exactly typ (a let variable)
+	fn    *parser.FunctionStatementNode // The function the name is bound to, if known
+}
+
+// env is a lexical scope of the checked program.
+type env struct {
+	names  map[string]*binding
+	parent *env
+}
+
+// lookUp finds the binding of a name in the scope chain.
+func (s *env) lookUp(name string) (*binding, bool) {
+	for ; s != nil; s = s.parent {
+		if b, ok := s.names[name]; ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// declaration is a struct, interface or enum declared by the program.
+type declaration struct {
+	kind   lexer.TokenType               // STRUCT_KEY, INTERFACE_KEY or ENUM_KEY
+	node   *parser.StructDeclarationNode // The struct declaration (nil for interfaces and enums)
+	enum   *parser.EnumDeclarationNode   // The enum declaration (nil for structs and interfaces)
+	parent string                        // The struct it extends ("" if none)
+}
+
+// checker holds the state of a check.
+type checker struct {
+	errors []string
+	scope  *env
+	decls  map[string]*declaration
+	fn     *parser.FunctionStatementNode // The function whose body is being checked (nil at top level)
+}
+
+// Check verifies the type annotations of a parsed program.
+//
+// Parameters:
+//   - root: The program, as returned by the parser
+//
+// Returns:
+//   - []string: The type errors, in the order they were found
+//     (e.g., "[3:5] TYPE ERROR: can't assign `string` to variable (x) of type `int`")
+func Check(root *parser.RootNode) []string {
+	c := &checker{
+		errors: make([]string, 0),
+		scope:  &env{names: make(map[string]*binding)},
+		decls:  make(map[string]*declaration),
+	}
+	c.declare(root.Statements)
+	c.statements(root.Statements)
+	return c.errors
+}
+
+// errorf records a type error at the position of tok. An expression checked
+// twice (such as the target of a compound assignment) reports its errors once.
+func (c *checker) errorf(tok lexer.Token, format string, args ...interface{}) {
+	msg := fmt.Sprintf("[%d:%d] TYPE ERROR: %s", tok.Line, tok.Column, fmt.Sprintf(format, args...))
+	for _, prev := range c.errors {
+		if prev == msg {
+			return
+		}
+	}
+	c.errors = append(c.errors, msg)
+}
+
+// declare records the structs, interfaces and enums declared by the
+// statements of the program (at any depth).
+func (c *checker) declare(stmts []parser.StatementNode) {
+	for _, stmt := range stmts {
+		switch n := stmt.(type) {
+		case *parser.StructDeclarationNode:
+			decl := &declaration{kind: lexer.STRUCT_KEY, node: n}
+			if n.Parent != nil {
+				decl.parent = n.Parent.Name
+			}
+			c.decls[n.StructName.Name] = decl
+		case *parser.InterfaceDeclarationNode:
+			c.decls[n.InterfaceName.Name] = &declaration{kind: lexer.INTERFACE_KEY}
+		case *parser.EnumDeclarationNode:
+			c.decls[n.EnumName.Name] = &declaration{kind: lexer.ENUM_KEY, enum: n}
+		case *parser.BlockStatementNode:
+			c.declare(n.Statements)
+		}
+	}
+}
+
+// hoist binds the functions declared by a block before its statements are
+// checked, so that calls may precede the declarations.
+func (c *checker) hoist(stmts []parser.StatementNode) {
+	for _, stmt := range stmts {
+		if fn, ok := stmt.(*parser.FunctionStatementNode); ok && fn.FuncName.Name != "" {
+			c.scope.names[fn.FuncName.Name] = &binding{typ: named(std.FunctionType), fn: fn}
+		}
+	}
+}
+
+// statements checks the statements of a block in a new scope.
+func (c *checker) statements(stmts []parser.StatementNode) {
+	c.hoist(stmts)
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+}
+
+// block checks a block in a new scope.
+func (c *checker) block(b *parser.BlockStatementNode) {
+	c.blockWith(b)
+}
+
+// blockWith checks a block in a new scope where names (such as loop
+// variables) are bound to values of unknown types.
+func (c *checker) blockWith(b *parser.BlockStatementNode, names ...string) {
+	if b == nil {
+		return
+	}
+	outer := c.scope
+	c.scope = &env{names: make(map[string]*binding), parent: outer}
+	for _, name := range names {
+		c.bind(name, &binding{})
+	}
+	c.statements(b.Statements)
+	c.scope = outer
+}
+
+// bind binds a name in the current scope.
+func (c *checker) bind(name string, b *binding) {
+	c.scope.names[name] = b
+}
+
+// statement checks a statement.
+func (c *checker) statement(stmt parser.StatementNode) {
+	switch n := stmt.(type) {
+	case *parser.DeclarativeStatementNode:
+		c.declaration(n)
+	case *parser.FunctionStatementNode:
+		c.function(n, "")
+	case *parser.StructDeclarationNode:
+		c.structDecl(n)
+	case *parser.ReturnStatementNode:
+		typ := c.expr(n.Expr)
+		if c.fn != nil && c.fn.ReturnType != nil && !c.fn.Generator && !c.assignable(typ, c.fn.ReturnType) {
+			c.errorf(n.ReturnToken, "function (%s) must return `%s`, got `%s`", functionName(c.fn), c.fn.ReturnType, typ)
+		}
+	case *parser.BlockStatementNode:
+		c.block(n)
+	case *parser.ForLoopStatementNode:
+		outer := c.scope
+		c.scope = &env{names: make(map[string]*binding), parent: outer}
+		for _, init := range n.Initializers {
+			c.statement(init)
+		}
+		c.expr(n.Condition)
+		for _, update := range n.Updates {
+			c.expr(update)
+		}
+		c.block(&n.Body)
+		c.scope = outer
+	case *parser.WhileLoopStatementNode:
+		for _, cond := range n.Conditions {
+			c.expr(cond)
+		}
+		c.block(&n.Body)
+	case *parser.ForeachLoopStatementNode:
+		c.expr(n.Iterable)
+		names := []string{n.Iterator.Name}
+		if n.Key != nil {
+			names = append(names, n.Key.Name)
+		}
+		if n.Pattern != nil {
+			for _, name := range n.Pattern.Bindings() {
+				names = append(names, name.Name)
+			}
+		}
+		c.blockWith(&n.Body, names...)
+	case *parser.SwitchStatementNode:
+		c.expr(n.Expression)
+		for i := range n.Cases {
+			c.expr(n.Cases[i].Value)
+			c.block(&n.Cases[i].Body)
+		}
+		if n.Default != nil {
+			c.block(&n.Default.Body)
+		}
+	case *parser.TryStatementNode:
+		c.block(&n.TryBlock)
+		if n.CatchParam != nil {
+			c.blockWith(n.CatchBlock, n.CatchParam.Name)
+		} else {
+			c.block(n.CatchBlock)
+		}
+		c.block(n.FinallyBlock)
+	case *parser.ThrowStatementNode:
+		c.expr(n.Expr)
+	case *parser.YieldStatementNode:
+		c.expr(n.Expr)
+	case *parser.SpawnStatementNode:
+		c.expr(n.Call)
+	case parser.ExpressionNode:
+		c.expr(n)
+	}
+}
+
+// declaration checks a var, let or const declaration and binds its name.
+func (c *checker) declaration(n *parser.DeclarativeStatementNode) {
+	typ := c.expr(n.Expr)
+	if n.Pattern != nil {
+		for _, name := range n.Pattern.Bindings() {
+			c.bind(name.Name, &binding{})
+		}
+		return
+	}
+	name := n.Identifier.Name
+	if n.VarType != nil {
+		c.validate(n.VarType, n.Identifier.Token)
+		if !c.assignable(typ, n.VarType) {
+			c.errorf(n.Identifier.Token, "can't assign `%s` to variable (%s) of type `%s`", typ, name, n.VarType)
+		}
+		c.bind(name, &binding{typ: n.VarType, fn: functionValue(n.Expr)})
+		return
+	}
+	b := &binding{fn: functionValue(n.Expr)}
+	if n.VarToken.Type != lexer.VAR_KEY && typ != nil && !typ.IsUnion() {
+		// let and const keep the type of their initial value
+		b.typ, b.exact = named(std.GoMixType(typ.Name)), true
+		if n.VarToken.Type == lexer.CONST_KEY {
+			b.typ = typ
+		}
+	}
+	c.bind(name, b)
+}
+
+// function checks a function declaration or literal; owner is the struct of
+// a method ("" for functions).
+func (c *checker) function(n *parser.FunctionStatementNode, owner string) {
+	anchor := n.FuncToken
+	if n.FuncName.Name != "" {
+		anchor = n.FuncName.Token
+		if owner == "" {
+			c.bind(n.FuncName.Name, &binding{typ: named(std.FunctionType), fn: n})
+		}
+	}
+	if n.ReturnType != nil {
+		c.validate(n.ReturnType, anchor)
+		if n.Generator && !c.assignable(named(std.GeneratorType), n.ReturnType) {
+			// A call of a generator function returns the generator
+			c.errorf(anchor, "generator function (%s) returns a `generator`, not `%s`", functionName(n), n.ReturnType)
+		}
+	}
+
+	outer, outerFn := c.scope, c.fn
+	c.scope = &env{names: make(map[string]*binding), parent: outer}
+	c.fn = n
+	if owner != "" {
+		c.bind("this", &binding{typ: &std.TypeAnnotation{Name: owner}})
+	}
+	for i, param := range n.FuncParams {
+		typ := n.ParamType(i)
+		if typ != nil {
+			c.validate(typ, param.Token)
+		}
+		if def := n.Default(i); def != nil {
+			if defType := c.expr(def); typ != nil && !c.assignable(defType, typ) {
+				c.errorf(param.Token, "default value `%s` of parameter (%s) does not conform to its type `%s`", defType, param.Name, typ)
+			}
+		}
+		c.bind(param.Name, &binding{typ: typ})
+	}
+	if n.ReturnType != nil && !n.Generator && !c.assignable(named(std.NilType), n.ReturnType) {
+		c.results(n.FuncBody.Statements)
+	} else {
+		c.statements(n.FuncBody.Statements)
+	}
+	c.scope, c.fn = outer, outerFn
+}
+
+// results checks the statements of a function body, or of a branch that
+// ends it, and verifies the value they leave when the function reaches its
+// end: a function returns the value of its last statement (nil if there is
+// none).
+func (c *checker) results(stmts []parser.StatementNode) {
+	if len(stmts) == 0 {
+		c.missingReturn()
+		return
+	}
+	c.hoist(stmts)
+	for _, stmt := range stmts[:len(stmts)-1] {
+		c.statement(stmt)
+	}
+	c.result(stmts[len(stmts)-1])
+}
+
+// branch checks a block that ends a function body in a new scope.
+func (c *checker) branch(b *parser.BlockStatementNode) {
+	outer := c.scope
+	c.scope = &env{names: make(map[string]*binding), parent: outer}
+	c.results(b.Statements)
+	c.scope = outer
+}
+
+// result checks the last statement of a function body (or of a branch that
+// ends it). Statements whose value is not known (declarations, try
+// statements, ...) are accepted.
+func (c *checker) result(stmt parser.StatementNode) {
+	switch n := stmt.(type) {
+	case *parser.BlockStatementNode:
+		c.branch(n)
+	case *parser.IfExpressionNode:
+		c.expr(n.Condition)
+		c.branch(&n.ThenBlock)
+		c.branch(&n.ElseBlock)
+	case *parser.SwitchStatementNode:
+		c.expr(n.Expression)
+		for i := range n.Cases {
+			c.expr(n.Cases[i].Value)
+			c.branch(&n.Cases[i].Body)
+		}
+		if n.Default != nil {
+			c.branch(&n.Default.Body)
+		} else {
+			c.missingReturn()
+		}
+	case *parser.ForLoopStatementNode, *parser.ForeachLoopStatementNode:
+		c.statement(n)
+		if loop, ok := n.(*parser.ForLoopStatementNode); !ok || loop.Condition != nil {
+			c.missingReturn()
+		}
+	case *parser.WhileLoopStatementNode:
+		c.statement(n)
+		if len(n.Conditions) != 1 || n.Conditions[0].Literal() != "true" {
+			// A while (true) loop only ends by returning (or breaking)
+			c.missingReturn()
+		}
+	case *parser.ReturnStatementNode, *parser.FunctionStatementNode, *parser.StructDeclarationNode:
+		c.statement(n)
+	case parser.ExpressionNode:
+		if typ := c.expr(n); !c.assignable(typ, c.fn.ReturnType) {
+			c.errorf(c.fn.FuncBody.RBrace, "function (%s) must return `%s`, got `%s`", functionName(c.fn), c.fn.ReturnType, typ)
+		}
+	default:
+		c.statement(n)
+	}
+}
+
+// missingReturn reports a function with a declared return type that can
+// reach the end of its body without a value of that type.
+func (c *checker) missingReturn() {
+	c.errorf(c.fn.FuncBody.RBrace, "function (%s) can reach its end without returning `%s`", functionName(c.fn), c.fn.ReturnType)
+}
+
+// structDecl checks the fields and methods of a struct.
+func (c *checker) structDecl(n *parser.StructDeclarationNode) {
+	for _, field := range n.Fields {
+		typ := c.expr(field.Expr)
+		if field.VarType == nil {
+			continue
+		}
+		c.validate(field.VarType, field.Identifier.Token)
+		if !c.assignable(typ, field.VarType) {
+			c.errorf(field.Identifier.Token, "can't assign `%s` to field (%s) of type `%s` in struct (%s)", typ, field.Identifier.Name, field.VarType, n.StructName.Name)
+		}
+	}
+	for _, method := range n.Methods {
+		c.function(method, n.StructName.Name)
+	}
+}
+
+// validate reports the names of an annotation that are neither builtin
+// types nor types declared by the program. Names qualified by a module
+// (util.Point) are not verified.
+func (c *checker) validate(typ *std.TypeAnnotation, tok lexer.Token) {
+	if !typ.IsUnion() && !typ.IsBuiltin() && !strings.Contains(typ.Name, ".") {
+		if _, ok := c.decls[typ.Name]; !ok {
+			c.errorf(tok, "unknown type (%s)", typ.Name)
+		}
+	}
+	for _, arg := range typ.Args {
+		c.validate(arg, tok)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+File    : go-mix/checker/checker_test.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akashmaji946/go-mix/parser"
+)
+
+// decls are the declarations shared by the tests below
+const decls = `
+enum Color { RED, GREEN }
+interface Shape { area(); }
+struct Square implements Shape {
+    var side: float = 1;
+    func init(side: float) { this.side = side; }
+    func area(): float { return this.side * this.side; }
+}
+struct Cube extends Square { func init(side: float) { super.init(side); } }
+struct Other {}
+func scale(w: float, h: float): float { return w * h; }
+func total(...nums: array<int>): int { return length(nums); }
+`
+
+// check parses and checks a program, failing the test on parse errors
+func check(t *testing.T, src string) []string {
+	t.Helper()
+	par := parser.NewParser(src)
+	root := par.Parse()
+	if par.HasErrors() {
+		t.Fatalf("parser errors: %v", par.GetErrors())
+	}
+	return Check(root)
+}
+
+// TestCheck_Accepted verifies that well-typed programs pass the check
+func TestCheck_Accepted(t *testing.T) {
+	tests := []string{
+		`var x: float = 2; x = 3.5; x += 1;`,
+		`var names: array<string> = ["a", "b"]; names = [];`,
+		`var m: map<string, array<int>> = map{"a": [1, 2]};`,
+		`var fs: array<float> = [1, 2.5]; var opt: list<int | nil> = list(1, nil);`,
+		`var t: tuple<int, string> = (1, "a");`,
+		`var v: int | nil = nil; v = 5;`,
+		`var a: any = 1; a = "s";`,
+		`var area: float = scale(2, 3) + 1;`,
+		`var n: int = total(1, 2, 3);`,
+		`var s: Shape = new Cube(2); var q: Square = new Cube(1);`,
+		`var o: object = new Other(); var c: Color = Color.RED;`,
+		`var sq = new Square(2); sq.side = 3; var f: float = sq.area();`,
+		`var up = upper("a"); var x: int = up;`,
+		`let k = 1; func f() { var k = "shadow"; k = "again"; }`,
+		`var x: int = 0; foreach x in ["a", "b"] { x = "c"; }`,
+		`var e: string = ""; try { throw "e"; } catch (e) { e = 1; }`,
+		`func f(n: int): string { if (n > 0) { return "pos"; } return "neg"; }`,
+		`func g(cb: func) { } g(func(a: int): int { return a; });`,
+		`import math as m; var p: m.Point = nil;`,
+		`var later: float = twice(2); func twice(x: int): int { return x * 2; }`,
+		`func gen(n: int) { yield n; return; } var g: generator = gen(1);`,
+		`func nums(): generator { yield 1; } var g: generator | nil = nums();`,
+		`var b: bigint = 5n * 2; var n: int = b; var d: decimal = decimal("1.5");`,
+		`let f: float = 2n * 1.5; let big: bigint = 1n << 70; let i: int = 100000000000000000000;`,
+		`var c = Color.RED; match (c) { Color.RED => 1, Color.GREEN => 2 };`,
+		`match (Color.RED) { Color.RED | Color.GREEN if true => 1, _ => 2 };`,
+		`func f(Color) { return match (1) { Color.RED => 1 }; }`,
+		`func sign(n: int): int { if (n < 0) { return -1; } else if (n > 0) { return 1; } else { return 0; } }`,
+		`func f(n: int): int { switch (n) { case 1: return 1; default: throw "bad"; } }`,
+		`func f(): int { while (true) { return 1; } } func g(): int { 5; } func h(): int | nil { }`,
+		`var sq = new Square(2); sq.side = "wide";`,
+	}
+	for _, src := range tests {
+		if errs := check(t, decls+src); len(errs) != 0 {
+			t.Errorf("%s: unexpected type errors: %v", src, errs)
+		}
+	}
+}
+
+// TestCheck_Errors verifies the type errors the checker reports
+func TestCheck_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{`var x: int = "s";`, "TYPE ERROR: can't assign `string` to variable (x) of type `int`"},
+		{`var x: int = 1; x = 2.5;`, "can't assign `float` to variable (x) of type `int`"},
+		{`var x: int = 1; x += 0.5;`, "can't assign `float` to variable (x) of type `int`"},
+		{`let k = 1; k = "s";`, "can't assign `string` to variable (k) of type `int`"},
+		{`var names: array<string> = ["a", 1];`, "can't assign `array<string | int>` to variable (names) of type `array<string>`"},
+		{`var m: map<string, int> = map{"a": "b"};`, "of type `map<string, int>`"},
+		{`var v: int | nil = "s";`, "can't assign `string` to variable (v) of type `int | nil`"},
+		{`scale("a", 1);`, "can't pass `string` as parameter (w) of type `float` to (scale)"},
+		{`total(1, "b");`, "can't pass `string` as an element of parameter (...nums) of type `array<int>` to (total)"},
+		{`new Square("wide");`, "can't pass `string` as parameter (side) of type `float` to (init)"},
+		{`func bad(): int { return "s"; }`, "function (bad) must return `int`, got `string`"},
+		{`var x: string = scale(1, 2);`, "can't assign `float` to variable (x) of type `string`"},
+		{`let sq = new Square(1); sq.side = "wide";`, "can't assign `string` to field (side) of type `float` in struct (Square)"},
+		{`struct Bad { var n: int = "s"; }`, "can't assign `string` to field (n) of type `int` in struct (Bad)"},
+		{`var s: Square = new Other();`, "can't assign `Other` to variable (s) of type `Square`"},
+		{`var s: Shape = new Other();`, "can't assign `Other` to variable (s) of type `Shape`"},
+		{`var u: Unknown = nil;`, "unknown type (Unknown)"},
+		{`func f(p: array<Nope>) { }`, "unknown type (Nope)"},
+		{`func gen() { yield 1; } var n: int = gen();`, "can't assign `generator` to variable (n) of type `int`"},
+		{`func gen(): int { yield 1; }`, "generator function (gen) returns a `generator`, not `int`"},
+		{`var b: bigint = 1;`, "can't assign `int` to variable (b) of type `bigint`"},
+		{`var s: string = 2n + 1;`, "can't assign `bigint` to variable (s) of type `string`"},
+		{`func gen(n: int) { yield n; } gen("s");`, "can't pass `string` as parameter (n) of type `int` to (gen)"},
+		{`func f(n: int = "s") { }`, "default value `string` of parameter (n) does not conform to its type `int`"},
+		{`func name(c) { return match (c) { Color.RED => "red" }; }`, "match over enum (Color) is not exhaustive: missing Color.GREEN"},
+		{`match (Color.RED) { Color.RED => 1, Color.GREEN if true => 2 };`, "match over enum (Color) is not exhaustive: missing Color.GREEN"},
+		{`struct P { var n: int = 0; func set() { this.n = "s"; } }`, "can't assign `string` to field (n) of type `int` in struct (P)"},
+		{`var sq: Square = new Square(1); sq.side += "s";`, "can't assign `string` to field (side) of type `float` in struct (Square)"},
+		{`func f(n: int): int { if (n > 0) { return 1; } }`, "function (f) can reach its end without returning `int`"},
+		{`func f(n: int): int { if (n > 0) { return 1; } else if (n < 0) { return -1; } }`, "function (f) can reach its end without returning `int`"},
+		{`func f(xs): int { foreach x in xs { return x; } }`, "function (f) can reach its end without returning `int`"},
+		{`func f(n: int): int { switch (n) { case 1: return 1; } }`, "function (f) can reach its end without returning `int`"},
+		{`struct S { func name(): string { } }`, "function (name) can reach its end without returning `string`"},
+		{`func f(): int { "s"; }`, "function (f) must return `int`, got `string`"},
+	}
+	for _, tt := range tests {
+		errs := check(t, decls+tt.src)
+		if len(errs) == 0 || !strings.Contains(errs[0], tt.err) {
+			t.Errorf("%s: expected a type error containing %q, got %v", tt.src, tt.err, errs)
+		}
+	}
+
+	// Errors carry their position and are reported in source order
+	errs := check(t, "var a: int = 1;\nvar b: string = a;\na = \"s\";")
+	want := []string{
+		"[2:7] TYPE ERROR: can't assign `int` to variable (b) of type `string`",
+		"[3:3] TYPE ERROR: can't assign `string` to variable (a) of type `int`",
+	}
+	if strings.Join(errs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected errors %v, got %v", want, errs)
+	}
+}
+
+// TestCheck_Samples verifies that every sample program that parses passes
+// the check
+func TestCheck_Samples(t *testing.T) {
+	files, _ := filepath.Glob("../samples/*/*.gm")
+	nested, _ := filepath.Glob("../samples/*/*/*.gm")
+	files = append(files, nested...)
+	if len(files) == 0 {
+		t.Fatal("no samples found")
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		par := parser.NewParser(string(src))
+		root := par.Parse()
+		if par.HasErrors() {
+			continue // samples that demonstrate parse errors
+		}
+		if errs := Check(root); len(errs) != 0 {
+			t.Errorf("%s: unexpected type errors: %v", file, errs)
+		}
+	}
+}
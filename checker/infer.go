@@ -0,0 +1,571 @@
+/*
+File    : go-mix/checker/infer.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package checker - infer.go
+// This file infers the types of expressions and decides which types may be
+// stored where another is declared.
+package checker
+
+import (
+	"strings"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// named returns the annotation of a builtin type.
+func named(typ std.GoMixType) *std.TypeAnnotation {
+	return &std.TypeAnnotation{Name: string(typ)}
+}
+
+// functionName returns the name of a function in type errors.
+func functionName(fn *parser.FunctionStatementNode) string {
+	if fn.FuncName.Name == "" {
+		return "<anonymous>"
+	}
+	return fn.FuncName.Name
+}
+
+// functionValue returns the function literal an expression is, or nil.
+func functionValue(expr parser.ExpressionNode) *parser.FunctionStatementNode {
+	fn, _ := expr.(*parser.FunctionStatementNode)
+	return fn
+}
+
+// expr checks an expression and infers its type.
+//
+// Returns:
+//   - *std.TypeAnnotation: The type of the expression's values, or nil if
+//     it cannot be inferred
+func (c *checker) expr(expr parser.ExpressionNode) *std.TypeAnnotation {
+	switch n := expr.(type) {
+	case nil:
+		return nil
+	case *parser.IntegerLiteralExpressionNode:
+		return named(n.Value.GetType())
+	case *parser.FloatLiteralExpressionNode:
+		return named(std.FloatType)
+	case *parser.StringLiteralExpressionNode:
+		return named(std.StringType)
+	case *parser.CharLiteralExpressionNode:
+		return named(std.CharType)
+	case *parser.BooleanLiteralExpressionNode:
+		return named(std.BooleanType)
+	case *parser.NilLiteralExpressionNode:
+		return named(std.NilType)
+	case *parser.InterpolatedStringExpressionNode:
+		for _, e := range n.Exprs {
+			c.expr(e)
+		}
+		return named(std.StringType)
+	case *parser.ParenthesizedExpressionNode:
+		return c.expr(n.Expr)
+	case *parser.IdentifierExpressionNode:
+		if b, ok := c.scope.lookUp(n.Name); ok {
+			return b.typ
+		}
+		return nil
+	case *parser.UnaryExpressionNode:
+		typ := c.expr(n.Right)
+		if n.Operation.Type == lexer.NOT_OP {
+			return named(std.BooleanType)
+		}
+		if isNumeric(typ) {
+			return typ
+		}
+		return nil
+	case *parser.BooleanExpressionNode:
+		c.expr(n.Left)
+		c.expr(n.Right)
+		return named(std.BooleanType)
+	case *parser.BinaryExpressionNode:
+		return c.binary(n)
+	case *parser.AssignmentExpressionNode:
+		return c.assignment(n)
+	case *parser.ArrayExpressionNode:
+		return generic(std.ArrayType, c.common(n.Elements))
+	case *parser.SetExpressionNode:
+		return generic(std.SetType, c.common(n.Elements))
+	case *parser.MapExpressionNode:
+		keys, values := c.common(n.Keys), c.common(n.Values)
+		if keys == nil || values == nil {
+			return named(std.MapType)
+		}
+		return &std.TypeAnnotation{Name: string(std.MapType), Args: []*std.TypeAnnotation{keys, values}}
+	case *parser.TupleExpressionNode:
+		typ := named(std.TupleType)
+		for _, elem := range n.Elements {
+			elemType := c.expr(elem)
+			if elemType == nil {
+				elemType = &std.TypeAnnotation{Name: std.AnyType}
+			}
+			typ.Args = append(typ.Args, elemType)
+		}
+		return typ
+	case *parser.RangeExpressionNode:
+		c.expr(n.Start)
+		c.expr(n.End)
+		return named(std.RangeType)
+	case *parser.IndexExpressionNode:
+		return c.index(n)
+	case *parser.SliceExpressionNode:
+		typ := c.expr(n.Left)
+		c.expr(n.Start)
+		c.expr(n.End)
+		return typ
+	case *parser.FunctionStatementNode:
+		c.function(n, "")
+		return named(std.FunctionType)
+	case *parser.CallExpressionNode:
+		return c.call(n)
+	case *parser.NewCallExpressionNode:
+		return c.newCall(n)
+	case *parser.IfExpressionNode:
+		c.expr(n.Condition)
+		c.block(&n.ThenBlock)
+		c.block(&n.ElseBlock)
+	case *parser.MatchExpressionNode:
+		c.expr(n.Subject)
+		for _, arm := range n.Arms {
+			outer := c.scope
+			c.scope = &env{names: make(map[string]*binding), parent: outer}
+			for _, name := range arm.Pattern.Bindings() {
+				c.bind(name.Name, &binding{})
+			}
+			c.expr(arm.Guard)
+			c.statement(arm.Body)
+			c.scope = outer
+		}
+		c.exhaustive(n)
+	case *parser.EnumAccessExpressionNode:
+		if n.Value != nil {
+			return named(n.Value.GetType())
+		}
+	}
+	return nil
+}
+
+// isNumeric reports whether typ is int, float, bigint or decimal.
+func isNumeric(typ *std.TypeAnnotation) bool {
+	if typ == nil {
+		return false
+	}
+	switch std.GoMixType(typ.Name) {
+	case std.IntegerType, std.FloatType, std.BigIntType, std.DecimalType:
+		return true
+	}
+	return false
+}
+
+// isInteger reports whether typ is int or bigint.
+func isInteger(typ *std.TypeAnnotation) bool {
+	return typ.Name == string(std.IntegerType) || typ.Name == string(std.BigIntType)
+}
+
+// arithmetic infers the type of the result of arithmetic on two numbers:
+// the widest of their types (int < bigint < decimal, and int < bigint <
+// float), or nil for a decimal and a float, which do not mix.
+func arithmetic(left, right *std.TypeAnnotation) *std.TypeAnnotation {
+	for _, wide := range []std.GoMixType{std.DecimalType, std.FloatType, std.BigIntType} {
+		if left.Name == string(wide) || right.Name == string(wide) {
+			if wide == std.DecimalType && (left.Name == string(std.FloatType) || right.Name == string(std.FloatType)) {
+				return nil
+			}
+			return named(wide)
+		}
+	}
+	return named(std.IntegerType)
+}
+
+// generic returns a collection type with the element type elem (a plain
+// collection type if elem is nil).
+func generic(name std.GoMixType, elem *std.TypeAnnotation) *std.TypeAnnotation {
+	typ := named(name)
+	if elem != nil {
+		typ.Args = []*std.TypeAnnotation{elem}
+	}
+	return typ
+}
+
+// exhaustive reports a match whose patterns name members of an enum but do
+// not cover all of them, unless an arm matches any value. Arms with a guard
+// cover nothing.
+func (c *checker) exhaustive(n *parser.MatchExpressionNode) {
+	enums := make([]*parser.EnumDeclarationNode, 0)
+	covered := make(map[*parser.EnumDeclarationNode]map[string]bool)
+	for _, arm := range n.Arms {
+		if arm.Guard != nil {
+			continue
+		}
+		if arm.Pattern.Irrefutable() {
+			return
+		}
+		for _, p := range arm.Pattern.ValuePatterns() {
+			access, ok := p.Value.(*parser.BinaryExpressionNode)
+			if !ok || access.Operation.Type != lexer.DOT_OP {
+				continue
+			}
+			left, isIdent := access.Left.(*parser.IdentifierExpressionNode)
+			member, isName := access.Right.(*parser.IdentifierExpressionNode)
+			if !isIdent || !isName {
+				continue
+			}
+			if _, shadowed := c.scope.lookUp(left.Name); shadowed {
+				continue
+			}
+			decl, ok := c.decls[left.Name]
+			if !ok || decl.enum == nil {
+				continue
+			}
+			if covered[decl.enum] == nil {
+				covered[decl.enum] = make(map[string]bool)
+				enums = append(enums, decl.enum)
+			}
+			covered[decl.enum][member.Name] = true
+		}
+	}
+	for _, enum := range enums {
+		missing := make([]string, 0)
+		for _, m := range enum.Members {
+			if !covered[enum][m.Name] {
+				missing = append(missing, enum.EnumName.Name+"."+m.Name)
+			}
+		}
+		if len(missing) > 0 {
+			c.errorf(n.Token, "match over enum (%s) is not exhaustive: missing %s", enum.EnumName.Name, strings.Join(missing, ", "))
+			return
+		}
+	}
+}
+
+// common checks the elements of a collection literal and returns their
+// type: the type they all have, or the union of their types (nil if the
+// type of an element is unknown, or if there are none).
+func (c *checker) common(elems []parser.ExpressionNode) *std.TypeAnnotation {
+	union := &std.TypeAnnotation{}
+	seen := make(map[string]bool)
+	known := true
+	for _, elem := range elems {
+		elemType := c.expr(elem)
+		if elemType == nil {
+			known = false
+		} else if !seen[elemType.String()] {
+			seen[elemType.String()] = true
+			union.Args = append(union.Args, elemType)
+		}
+	}
+	if !known || len(union.Args) == 0 {
+		return nil
+	}
+	if len(union.Args) == 1 {
+		return union.Args[0]
+	}
+	return union
+}
+
+// binary infers the type of an arithmetic, bitwise or member access expression.
+func (c *checker) binary(n *parser.BinaryExpressionNode) *std.TypeAnnotation {
+	if n.Operation.Type == lexer.DOT_OP {
+		owner := c.receiver(n.Left)
+		if member, ok := n.Right.(*parser.IdentifierExpressionNode); ok && owner != "" {
+			return c.fieldType(owner, member.Name)
+		}
+		return nil
+	}
+	left, right := c.expr(n.Left), c.expr(n.Right)
+	if left == nil || right == nil {
+		return nil
+	}
+	switch n.Operation.Type {
+	case lexer.PLUS_OP:
+		if left.Name == string(std.StringType) || right.Name == string(std.StringType) {
+			return named(std.StringType)
+		}
+		fallthrough
+	case lexer.MINUS_OP, lexer.MUL_OP, lexer.DIV_OP, lexer.MOD_OP:
+		if isNumeric(left) && isNumeric(right) {
+			return arithmetic(left, right)
+		}
+	case lexer.BIT_AND_OP, lexer.BIT_OR_OP, lexer.BIT_XOR_OP, lexer.BIT_LEFT_OP, lexer.BIT_RIGHT_OP:
+		if isInteger(left) && isInteger(right) {
+			return arithmetic(left, right)
+		}
+	}
+	return nil
+}
+
+// index infers the type of an element of an annotated array, list or map.
+func (c *checker) index(n *parser.IndexExpressionNode) *std.TypeAnnotation {
+	typ := c.expr(n.Left)
+	c.expr(n.Index)
+	if typ == nil {
+		return nil
+	}
+	switch typ.Name {
+	case string(std.ArrayType), string(std.ListType):
+		return typ.Arg(0)
+	case string(std.MapType):
+		return typ.Arg(1)
+	}
+	return nil
+}
+
+// assignment checks an assignment to an annotated or let variable, or to an
+// annotated field, and infers its value's type.
+func (c *checker) assignment(n *parser.AssignmentExpressionNode) *std.TypeAnnotation {
+	var typ *std.TypeAnnotation
+	if op, ok := compoundOperators[n.Operation.Type]; ok {
+		// The type of a compound assignment is that of its binary operation
+		typ = c.binary(&parser.BinaryExpressionNode{Operation: lexer.Token{Type: op}, Left: n.Left, Right: n.Right})
+	} else {
+		typ = c.expr(n.Right)
+	}
+	switch left := n.Left.(type) {
+	case *parser.IdentifierExpressionNode:
+		b, ok := c.scope.lookUp(left.Name)
+		if !ok || b.typ == nil {
+			return typ
+		}
+		if (b.exact && typ != nil && typ.Name != b.typ.Name) || !c.assignable(typ, b.typ) {
+			c.errorf(left.Token, "can't assign `%s` to variable (%s) of type `%s`", typ, left.Name, b.typ)
+		}
+	case *parser.BinaryExpressionNode:
+		member, ok := left.Right.(*parser.IdentifierExpressionNode)
+		if !ok || left.Operation.Type != lexer.DOT_OP {
+			c.expr(left)
+			return typ
+		}
+		owner := c.receiver(left.Left)
+		if field := c.fieldType(owner, member.Name); field != nil && !c.assignable(typ, field) {
+			c.errorf(member.Token, "can't assign `%s` to field (%s) of type `%s` in struct (%s)", typ, member.Name, field, owner)
+		}
+	default:
+		c.expr(n.Left)
+	}
+	return typ
+}
+
+// compoundOperators maps the compound assignment operators to the binary
+// operators they apply.
+var compoundOperators = map[lexer.TokenType]lexer.TokenType{
+	lexer.PLUS_ASSIGN:      lexer.PLUS_OP,
+	lexer.MINUS_ASSIGN:     lexer.MINUS_OP,
+	lexer.MUL_ASSIGN:       lexer.MUL_OP,
+	lexer.DIV_ASSIGN:       lexer.DIV_OP,
+	lexer.MOD_ASSIGN:       lexer.MOD_OP,
+	lexer.BIT_AND_ASSIGN:   lexer.BIT_AND_OP,
+	lexer.BIT_OR_ASSIGN:    lexer.BIT_OR_OP,
+	lexer.BIT_XOR_ASSIGN:   lexer.BIT_XOR_OP,
+	lexer.BIT_LEFT_ASSIGN:  lexer.BIT_LEFT_OP,
+	lexer.BIT_RIGHT_ASSIGN: lexer.BIT_RIGHT_OP,
+}
+
+// receiver checks the left operand of a member access and returns the name
+// of the struct whose fields and methods it accesses: the struct itself for
+// a struct name, the struct of an instance, or "" if it is not known.
+func (c *checker) receiver(expr parser.ExpressionNode) string {
+	if ident, ok := expr.(*parser.IdentifierExpressionNode); ok {
+		if _, bound := c.scope.lookUp(ident.Name); !bound && c.decls[ident.Name] != nil {
+			return ident.Name
+		}
+	}
+	if typ := c.expr(expr); typ != nil {
+		return typ.Name
+	}
+	return ""
+}
+
+// fieldType returns the annotated type of a field of the struct named
+// owner (or of one of its parents), or nil.
+func (c *checker) fieldType(owner, name string) *std.TypeAnnotation {
+	for decl := c.decls[owner]; decl != nil && decl.node != nil; decl = c.decls[decl.parent] {
+		for _, field := range decl.node.Fields {
+			if field.Identifier.Name == name {
+				return field.VarType
+			}
+		}
+	}
+	return nil
+}
+
+// method returns the declaration of a method of the struct named owner (or
+// of one of its parents), or nil.
+func (c *checker) method(owner, name string) *parser.FunctionStatementNode {
+	for decl := c.decls[owner]; decl != nil && decl.node != nil; decl = c.decls[decl.parent] {
+		for _, method := range decl.node.Methods {
+			if method.FuncName.Name == name {
+				return method
+			}
+		}
+	}
+	return nil
+}
+
+// call checks a call of a known function or method and returns its declared
+// return type.
+func (c *checker) call(n *parser.CallExpressionNode) *std.TypeAnnotation {
+	var fn *parser.FunctionStatementNode
+	tok := n.LeftParen
+	switch callee := n.Callee.(type) {
+	case *parser.IdentifierExpressionNode:
+		if b, ok := c.scope.lookUp(callee.Name); ok {
+			fn = b.fn
+		}
+		tok = callee.Token
+	case *parser.BinaryExpressionNode:
+		member, ok := callee.Right.(*parser.IdentifierExpressionNode)
+		if ok && callee.Operation.Type == lexer.DOT_OP {
+			fn = c.method(c.receiver(callee.Left), member.Name)
+			tok = member.Token
+		} else {
+			c.expr(callee)
+		}
+	default:
+		c.expr(n.Callee)
+	}
+	c.arguments(fn, tok, n.Arguments, n.ArgNames)
+	if fn == nil {
+		return nil
+	}
+	if fn.Generator && fn.ReturnType == nil {
+		return named(std.GeneratorType)
+	}
+	return fn.ReturnType
+}
+
+// newCall checks the arguments of the constructor of a struct instantiation.
+func (c *checker) newCall(n *parser.NewCallExpressionNode) *std.TypeAnnotation {
+	c.arguments(c.method(n.StructName.Name, "init"), n.NewToken, n.Arguments, n.ArgNames)
+	return &std.TypeAnnotation{Name: n.StructName.Name}
+}
+
+// arguments checks the arguments of a call against the annotated parameters
+// of fn (nil if the called function is not known).
+func (c *checker) arguments(fn *parser.FunctionStatementNode, tok lexer.Token, args []parser.ExpressionNode, names []string) {
+	fixed := -1
+	if fn != nil {
+		fixed = len(fn.FuncParams)
+		if fn.FuncRest {
+			fixed--
+		}
+	}
+	for i, arg := range args {
+		typ := c.expr(arg)
+		if fn == nil {
+			continue
+		}
+		index := i
+		if names != nil && names[i] != "" {
+			index = -1
+			for j, param := range fn.FuncParams {
+				if param.Name == names[i] {
+					index = j
+				}
+			}
+		}
+		if index < 0 || index >= fixed {
+			// Unknown names are reported by the evaluator; rest arguments
+			// are elements of the rest parameter's array
+			if index >= fixed && fn.FuncRest {
+				if elem := restElement(fn.ParamType(fixed)); elem != nil && !c.assignable(typ, elem) {
+					c.errorf(tok, "can't pass `%s` as an element of parameter (...%s) of type `%s` to (%s)", typ, fn.FuncParams[fixed].Name, fn.ParamType(fixed), functionName(fn))
+				}
+			}
+			continue
+		}
+		if param := fn.ParamType(index); param != nil && !c.assignable(typ, param) {
+			c.errorf(tok, "can't pass `%s` as parameter (%s) of type `%s` to (%s)", typ, fn.FuncParams[index].Name, param, functionName(fn))
+		}
+	}
+}
+
+// restElement returns the element type of the annotated type of a rest
+// parameter (array<T>), or nil.
+func restElement(typ *std.TypeAnnotation) *std.TypeAnnotation {
+	if typ == nil || typ.Name != string(std.ArrayType) {
+		return nil
+	}
+	return typ.Arg(0)
+}
+
+// assignable reports whether a value of type actual may be stored where
+// declared is declared. Unknown types (nil) are assignable to and from
+// every type.
+func (c *checker) assignable(actual, declared *std.TypeAnnotation) bool {
+	if actual == nil || declared == nil || actual.Name == std.AnyType || declared.Name == std.AnyType {
+		return true
+	}
+	if actual.IsUnion() {
+		for _, alt := range actual.Args {
+			if !c.assignable(alt, declared) {
+				return false
+			}
+		}
+		return true
+	}
+	if declared.IsUnion() {
+		for _, alt := range declared.Args {
+			if c.assignable(actual, alt) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if actual.Name == declared.Name {
+		if len(actual.Args) == 0 || len(declared.Args) == 0 {
+			return true
+		}
+		if len(actual.Args) != len(declared.Args) {
+			return false
+		}
+		for i := range actual.Args {
+			if !c.assignable(actual.Args[i], declared.Args[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if actual.Name == string(std.IntegerType) && declared.Name == string(std.FloatType) {
+		return true
+	}
+	if actual.Name == string(std.BigIntType) && declared.Name == string(std.IntegerType) {
+		return true
+	}
+
+	if _, known := c.decls[actual.Name]; !known && !actual.IsBuiltin() {
+		// A value of an imported type
+		return true
+	}
+	decl, isDeclared := c.decls[declared.Name]
+	if declared.IsBuiltin() || (!isDeclared && declared.Name != "") {
+		// Instances of declared structs are objects; values of imported
+		// types are not known
+		_, isStruct := c.decls[actual.Name]
+		return (declared.Name == string(std.ObjectType) && isStruct) || (!declared.IsBuiltin() && !isDeclared)
+	}
+	switch decl.kind {
+	case lexer.ENUM_KEY:
+		// Enum members are ints, strings, ...
+		return actual.IsBuiltin()
+	case lexer.STRUCT_KEY:
+		for s := c.decls[actual.Name]; s != nil && s.node != nil; s = c.decls[s.parent] {
+			if s.node.StructName.Name == declared.Name {
+				return true
+			}
+		}
+	case lexer.INTERFACE_KEY:
+		for s := c.decls[actual.Name]; s != nil && s.node != nil; s = c.decls[s.parent] {
+			for _, iface := range s.node.Interfaces {
+				if iface.Name == declared.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -30,6 +30,17 @@ type Evaluator struct {
 	Writer   io.Writer                   // Output writer for builtin functions (default: os.Stdout)
 	Reader   *bufio.Reader               // Input reader for builtin functions (default: os.Stdin)
 	Imports  map[string]*std.Package     // Map of imported packages (e.g., "math" -> Package)
+	File     string                      // Absolute path of the source file being evaluated ("" for REPL/strings)
+	UseVM    bool                        // Run programs and function bodies on the bytecode VM (see vm.go)
+	Perms    *std.Permissions            // Capabilities granted to the program (fs, process, env, network)
+
+	modules *moduleLoader                            // Cache and loading stack for user modules (shared with module evaluators)
+	vmCode  map[*parser.BlockStatementNode]*Bytecode // Compiled function bodies (nil entries could not be compiled)
+	limits  *limitState                              // Execution limits and usage of the running program (see eval_limits.go)
+	trace   *callStack                               // Active calls and current position, for tracebacks (see eval_trace.go)
+	sched   *scheduler                               // Turns of the program's goroutines (see eval_concurrency.go)
+	spawned bool                                     // Runs a goroutine started by spawn rather than the main program
+	gen     *coroutine                               // The generator whose body this evaluator runs (see eval_generators.go)
 }
 
 // NewEvaluator creates and initializes a new Evaluator instance with default configuration.
@@ -57,6 +68,13 @@ func NewEvaluator() *Evaluator {
 		Writer:   os.Stdout, // Default to stdout
 		Reader:   bufio.NewReader(os.Stdin),
 		Imports:  make(map[string]*std.Package),
+		UseVM:    VMByDefault,
+		Perms:    DefaultPermissions.Copy(),
+		modules:  newModuleLoader(),
+		vmCode:   make(map[*parser.BlockStatementNode]*Bytecode),
+		limits:   newLimitState(DefaultLimits),
+		trace:    &callStack{root: "<main>"},
+		sched:    newScheduler(),
 	}
 	for _, builtin := range std.Builtins {
 		ev.Builtins[builtin.Name] = builtin
@@ -98,6 +116,33 @@ func (e *Evaluator) GetInputReader() *bufio.Reader {
 	return e.Reader
 }
 
+// DefaultPermissions are copied into every new evaluator. They grant every
+// capability unless the host (or the CLI sandbox flags) restricts them.
+var DefaultPermissions = std.AllowAll()
+
+// SetPermissions sets the capabilities granted to the evaluated program.
+//
+// Builtins that read or write files, run processes, access the environment or
+// use the network fail with a permission error when their capability is missing.
+//
+// Parameters:
+//   - perms: The granted capabilities (e.g., std.Restricted() for a sandbox)
+//
+// Example usage:
+//
+//	perms := std.Restricted()
+//	perms.Grant(std.CapFSRead, "./data")
+//	ev.SetPermissions(perms)
+func (e *Evaluator) SetPermissions(perms *std.Permissions) {
+	e.Perms = perms
+}
+
+// GetPermissions returns the capabilities granted to the program.
+// This implements the std.Runtime interface.
+func (e *Evaluator) GetPermissions() *std.Permissions {
+	return e.Perms
+}
+
 // SetParser assigns a parser instance to the evaluator for enhanced error reporting.
 //
 // The parser reference is used by CreateError() to include source code position
@@ -138,15 +183,21 @@ func (e *Evaluator) SetParser(p *parser.Parser) {
 //	func add(a, b) { return a + b; }  // Creates and registers 'add' function
 func (e *Evaluator) RegisterFunction(n *parser.FunctionStatementNode) std.GoMixObject {
 	function := &function.Function{
-		Name:   n.FuncName.Name,
-		Params: n.FuncParams,
-		Body:   &n.FuncBody,
-		Scp:    e.Scp, // Reference the current scope directly, not a copy
+		Name:       n.FuncName.Name,
+		Params:     n.FuncParams,
+		Defaults:   n.FuncDefaults,
+		Rest:       n.FuncRest,
+		ParamTypes: n.ParamTypes,
+		Result:     n.ReturnType,
+		Generator:  n.Generator,
+		Body:       &n.FuncBody,
+		Scp:        e.Scp, // Reference the current scope directly, not a copy
+		File:       e.File,
 	}
 	// redeclared?
 	name, has := e.Scp.Bind(n.FuncName.Name, function)
 	if has && name != "" {
-		return e.CreateError("ERROR: function redeclaration found: (%s)", n.FuncName.Name)
+		return e.createError(n.FuncToken, "ERROR: function redeclaration found: (%s)", n.FuncName.Name)
 	}
 	e.Scp.Bind(n.FuncName.Name, function)
 	return function
@@ -196,34 +247,20 @@ func (e *Evaluator) IsBuiltin(name string) bool {
 func (e *Evaluator) InvokeBuiltin(name string, args ...std.GoMixObject) std.GoMixObject {
 
 	if builtin, ok := e.Builtins[name]; ok {
-		return builtin.Callback(e, e.Writer, args...)
+		res := builtin.Callback(e, e.Writer, args...)
+		e.charge(res)
+		return res
 	}
 	return &std.Nil{}
 }
 
-// CallFunction executes a Go-Mix function object with the provided arguments.
+// CallFunction executes a Go-Mix function, builtin or bound method with the provided arguments.
 // This implements the std.Runtime interface.
 func (e *Evaluator) CallFunction(fn std.GoMixObject, args ...std.GoMixObject) std.GoMixObject {
 	if fn.GetType() != std.FunctionType {
 		return e.CreateError("ERROR: object is not a function")
 	}
-	functionObject := fn.(*function.Function)
-
-	if len(args) != len(functionObject.Params) {
-		return e.CreateError("ERROR: wrong number of arguments: expected %d, got %d", len(functionObject.Params), len(args))
-	}
-
-	callSiteScope := scope.NewScope(functionObject.Scp)
-	for i, param := range functionObject.Params {
-		callSiteScope.Bind(param.Name, args[i])
-	}
-
-	oldScope := e.Scp
-	e.Scp = callSiteScope
-	result := e.Eval(functionObject.Body)
-	e.Scp = oldScope
-
-	return UnwrapReturnValue(result)
+	return e.callValue(fn, "", lexer.Token{}, args, nil)
 }
 
 // CreateError creates a new Error object with a formatted message including source position.
@@ -233,9 +270,9 @@ func (e *Evaluator) CallFunction(fn std.GoMixObject, args ...std.GoMixObject) st
 // - Column number: The column position in that line
 // - Error message: A formatted description of the error
 //
-// The parser must be set via SetParser() before calling this method to ensure
-// accurate position information. The format string and arguments follow the
-// same conventions as fmt.Sprintf().
+// The position is that of the statement or call being evaluated; before any
+// position is known the message has no prefix. The format string and
+// arguments follow the same conventions as fmt.Sprintf().
 //
 // Parameters:
 //   - format: A format string following fmt.Sprintf conventions
@@ -250,8 +287,13 @@ func (e *Evaluator) CallFunction(fn std.GoMixObject, args ...std.GoMixObject) st
 //	// Output: "[10:5] ERROR: identifier not found: (myVar)"
 func (e *Evaluator) CreateError(format string, a ...interface{}) *std.Error {
 	msg := fmt.Sprintf(format, a...)
-	fullMsg := fmt.Sprintf("[%d:%d] %s", e.Par.Lex.Line, e.Par.Lex.Column, msg)
-	return &std.Error{Message: fullMsg}
+	line, column := e.position()
+	if line == 0 {
+		// nothing has been evaluated yet (e.g., a host call through gomix)
+		return &std.Error{Message: msg}
+	}
+	fullMsg := fmt.Sprintf("[%d:%d] %s", line, column, msg)
+	return &std.Error{Message: fullMsg, Line: line, Column: column}
 }
 
 // createError creates an error object with line and column information from a token.
@@ -269,5 +311,7 @@ func (e *Evaluator) CreateError(format string, a ...interface{}) *std.Error {
 func (e *Evaluator) createError(token lexer.Token, format string, args ...interface{}) std.GoMixObject {
 	return &std.Error{
 		Message: fmt.Sprintf("[%d:%d] %s", token.Line, token.Column, fmt.Sprintf(format, args...)),
+		Line:    token.Line,
+		Column:  token.Column,
 	}
 }
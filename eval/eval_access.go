@@ -6,7 +6,6 @@ Contact : akashmaji(@iisc.ac.in)
 package eval
 
 import (
-	"github.com/akashmaji946/go-mix/function"
 	"github.com/akashmaji946/go-mix/parser"
 	"github.com/akashmaji946/go-mix/std"
 )
@@ -64,11 +63,32 @@ func (e *Evaluator) evalIndexExpression(n *parser.IndexExpressionNode) std.GoMix
 	}
 
 	// Handle map indexing
+	e.at(n.Token)
+	return e.indexValue(left, index)
+}
+
+// indexValue returns left[index] for an evaluated container and index.
+//
+// Parameters:
+//   - left: The map, range, bytes, array, list, tuple or struct instance being indexed
+//   - index: The index or key
+//
+// Returns:
+//   - std.GoMixObject: The element (Nil for missing map keys), or an Error
+func (e *Evaluator) indexValue(left, index std.GoMixObject) std.GoMixObject {
+	// Struct instances are indexed with their __index__ method
+	if res, ok := std.CallProtocol(e, left, "__index__", index); ok {
+		return res
+	}
+
 	if left.GetType() == std.MapType {
 		mapObj := left.(*std.Map)
-		keyStr := index.ToString()
+		hk, err := e.hash(index)
+		if err != nil {
+			return err
+		}
 
-		if value, exists := mapObj.Pairs[keyStr]; exists {
+		if value, exists := mapObj.Get(hk); exists {
 			return value
 		}
 		// Return nil if key doesn't exist
@@ -80,6 +100,11 @@ func (e *Evaluator) evalIndexExpression(n *parser.IndexExpressionNode) std.GoMix
 		return e.evalRangeIndexExpression(left, index)
 	}
 
+	// Handle bytes indexing
+	if left.GetType() == std.BytesType {
+		return e.evalBytesIndexExpression(left, index)
+	}
+
 	// Handle array, list, and tuple indexing
 	leftType := left.GetType()
 	if leftType != std.ArrayType && leftType != std.ListType && leftType != std.TupleType {
@@ -183,6 +208,44 @@ func (e *Evaluator) evalRangeIndexExpression(left, index std.GoMixObject) std.Go
 	return &std.Integer{Value: value}
 }
 
+// evalBytesIndexExpression evaluates index access on bytes, which gives the
+// byte at the index as an int from 0 to 255.
+//
+// Parameters:
+//   - left: The Bytes object
+//   - index: The index object (must be Integer)
+//
+// Returns:
+//   - objects.GoMixObject: The byte at the index, or an Error if invalid
+//
+// Example:
+//
+//	bytes("AB")[0]   // Returns 65
+//	bytes("AB")[-1]  // Returns 66
+func (e *Evaluator) evalBytesIndexExpression(left, index std.GoMixObject) std.GoMixObject {
+	data := left.(*std.Bytes).Value
+
+	// Check if index is an integer
+	if index.GetType() != std.IntegerType {
+		return e.CreateError("ERROR: index must be an integer, got '%s'", index.GetType())
+	}
+
+	idx := index.(*std.Integer).Value
+	length := int64(len(data))
+
+	// Handle negative indices (Python-style)
+	if idx < 0 {
+		idx = length + idx
+	}
+
+	// Bounds checking
+	if idx < 0 || idx >= length {
+		return e.CreateError("ERROR: index out of bounds: index %d, length %d", idx, length)
+	}
+
+	return &std.Integer{Value: int64(data[idx])}
+}
+
 // evalSliceExpression evaluates array, list, and tuple slicing operations to extract sub-sequences.
 //
 // This method implements Python-style slicing with the syntax arr[start:end]:
@@ -201,6 +264,7 @@ func (e *Evaluator) evalRangeIndexExpression(left, index std.GoMixObject) std.Go
 // - If start > end after processing: Returns empty array
 //
 // Note: Slicing always returns an array, even for lists and tuples (as per requirements).
+// Slicing bytes returns bytes.
 //
 // Parameters:
 //   - n: A SliceExpressionNode containing the array/list/tuple, optional start, and optional end expressions
@@ -228,9 +292,38 @@ func (e *Evaluator) evalSliceExpression(n *parser.SliceExpressionNode) std.GoMix
 		return left
 	}
 
-	// Check if left is an array, list, or tuple
+	// Start and end are optional (arr[:2], arr[1:])
+	var startObj, endObj std.GoMixObject
+	if n.Start != nil {
+		startObj = e.Eval(n.Start)
+		if IsError(startObj) {
+			return startObj
+		}
+	}
+	if n.End != nil {
+		endObj = e.Eval(n.End)
+		if IsError(endObj) {
+			return endObj
+		}
+	}
+	e.at(n.Token)
+	return e.sliceValue(left, startObj, endObj)
+}
+
+// sliceValue returns left[start:end] for an evaluated container and bounds.
+//
+// Parameters:
+//   - left: The array, list, tuple or bytes being sliced
+//   - startObj: The start index, or nil when omitted
+//   - endObj: The end index, or nil when omitted
+//
+// Returns:
+//   - std.GoMixObject: A new Array with the selected elements (Bytes for
+//     bytes), or an Error
+func (e *Evaluator) sliceValue(left, startObj, endObj std.GoMixObject) std.GoMixObject {
+	// Check if left is an array, list, tuple or bytes
 	leftType := left.GetType()
-	if leftType != std.ArrayType && leftType != std.ListType && leftType != std.TupleType {
+	if leftType != std.ArrayType && leftType != std.ListType && leftType != std.TupleType && leftType != std.BytesType {
 		return e.CreateError("ERROR: slice operator not supported for type '%s'", leftType)
 	}
 
@@ -239,6 +332,8 @@ func (e *Evaluator) evalSliceExpression(n *parser.SliceExpressionNode) std.GoMix
 
 	// Get elements based on type
 	switch leftType {
+	case std.BytesType:
+		length = int64(len(left.(*std.Bytes).Value))
 	case std.ArrayType:
 		arr := left.(*std.Array)
 		elements = arr.Elements
@@ -255,11 +350,7 @@ func (e *Evaluator) evalSliceExpression(n *parser.SliceExpressionNode) std.GoMix
 
 	// Determine start index
 	var start int64 = 0
-	if n.Start != nil {
-		startObj := e.Eval(n.Start)
-		if IsError(startObj) {
-			return startObj
-		}
+	if startObj != nil {
 		if startObj.GetType() != std.IntegerType {
 			return e.CreateError("ERROR: slice start index must be an integer, got '%s'", startObj.GetType())
 		}
@@ -279,11 +370,7 @@ func (e *Evaluator) evalSliceExpression(n *parser.SliceExpressionNode) std.GoMix
 
 	// Determine end index
 	var end int64 = length
-	if n.End != nil {
-		endObj := e.Eval(n.End)
-		if IsError(endObj) {
-			return endObj
-		}
+	if endObj != nil {
 		if endObj.GetType() != std.IntegerType {
 			return e.CreateError("ERROR: slice end index must be an integer, got '%s'", endObj.GetType())
 		}
@@ -306,11 +393,20 @@ func (e *Evaluator) evalSliceExpression(n *parser.SliceExpressionNode) std.GoMix
 		start = end
 	}
 
+	// Bytes are immutable, so their slices share the bytes
+	if leftType == std.BytesType {
+		res := &std.Bytes{Value: left.(*std.Bytes).Value[start:end]}
+		e.charge(res)
+		return res
+	}
+
 	// Create the sliced array (always returns array, even for lists/tuples)
 	slicedElements := make([]std.GoMixObject, end-start)
 	copy(slicedElements, elements[start:end])
 
-	return &std.Array{Elements: slicedElements}
+	res := &std.Array{Elements: slicedElements}
+	e.charge(res)
+	return res
 }
 
 // getIndexValue retrieves a value from a container (array, list, or map) at a given index.
@@ -325,10 +421,16 @@ func (e *Evaluator) evalSliceExpression(n *parser.SliceExpressionNode) std.GoMix
 // Returns:
 //   - objects.GoMixObject: The value at the index, or an Error if invalid
 func (e *Evaluator) getIndexValue(container, index std.GoMixObject) std.GoMixObject {
+	if res, ok := std.CallProtocol(e, container, "__index__", index); ok {
+		return res
+	}
 	if container.GetType() == std.MapType {
 		mapObj := container.(*std.Map)
-		keyStr := index.ToString()
-		if value, exists := mapObj.Pairs[keyStr]; exists {
+		hk, err := e.hash(index)
+		if err != nil {
+			return err
+		}
+		if value, exists := mapObj.Get(hk); exists {
 			return value
 		}
 		return &std.Nil{}
@@ -370,71 +472,39 @@ func (e *Evaluator) getIndexValue(container, index std.GoMixObject) std.GoMixObj
 
 // evalMemberAccess evaluates member access (dot operator) on a struct instance.
 //
-// This method handles accessing fields or calling methods on an object instance.
-// It distinguishes between:
-// - Method calls: Dispatches to callFunctionOnObject
-// - Field access: Looks up instance fields, then static fields
+// This method handles accessing fields or methods of an object instance:
+// - Field access: Looks up instance fields, then static fields (inherited ones too)
+// - Method access: Returns the method bound to the instance, to be called later
 //
 // Parameters:
 //   - structInstance: The object instance being accessed
-//   - node: The expression to the right of the dot (Identifier or CallExpression)
+//   - node: The expression to the right of the dot (an Identifier)
 //
 // Returns:
-//   - objects.GoMixObject: The field value or method return value
+//   - objects.GoMixObject: The field value or the bound method
 func (e *Evaluator) evalMemberAccess(structInstance *std.GoMixObjectInstance, node parser.ExpressionNode) std.GoMixObject {
-	// Handle Method Call
-	if fn, ok := node.(*parser.CallExpressionNode); ok {
-		methodName := fn.FunctionIdentifier.Name
-		methodInterface, exists := structInstance.Struct.Methods[methodName]
-		if !exists {
-			return e.CreateError("ERROR: method (%s) does not exist in struct (%s)", methodName, structInstance.Struct.GetName())
-		}
-		method, ok := methodInterface.(*function.Function)
-		if !ok {
-			return e.CreateError("ERROR: method (%s) not found in struct (%s)", methodName, structInstance.Struct.GetName())
-		}
-		params := make([]NamedParameter, len(fn.Arguments))
-		if len(fn.Arguments) != len(method.Params) {
-			return e.CreateError("ERROR: wrong number of arguments for method (%s): expected %d, got %d", methodName, len(method.Params), len(fn.Arguments))
-		}
-		for i, arg := range fn.Arguments {
-			params[i] = NamedParameter{
-				Name:  method.Params[i].Name,
-				Value: e.Eval(arg),
-			}
-			if IsError(params[i].Value) {
-				return params[i].Value
-			}
-		}
-
-		res := e.callFunctionOnObject(methodName, structInstance, params...)
-		if res.GetType() == std.ErrorType {
-			return res
-		}
-		return res
-	}
-
 	// Handle Field Access
 	if ident, ok := node.(*parser.IdentifierExpressionNode); ok {
 		fieldName := ident.Name
 		if val, ok := structInstance.InstanceFields[fieldName]; ok {
 			return val
 		}
-		if val, ok := structInstance.Struct.ClassFields[fieldName]; ok {
+		if val, _, ok := structInstance.Struct.LookUpField(fieldName); ok {
 			return val
 		}
-		if val, ok := structInstance.Struct.ClassFields[fieldName]; ok {
-			return val
+		if _, ok := structInstance.Struct.GetMethod(fieldName); ok {
+			return &std.BoundMethod{Receiver: structInstance, Name: fieldName}
 		}
 		return e.CreateError("ERROR: field (%s) not found in struct instance", fieldName)
 	}
 
-	return e.CreateError("ERROR: member access operator (.) must be followed by a function call or identifier")
+	return e.CreateError("ERROR: member access operator (.) must be followed by an identifier")
 }
 
 // evalStructMemberAccess evaluates member access on a struct type (static access).
 //
-// This method handles accessing static fields on the struct type itself.
+// This method handles accessing static fields on the struct type itself,
+// including the ones inherited from its parents.
 //
 // Parameters:
 //   - s: The struct type definition
@@ -446,7 +516,7 @@ func (e *Evaluator) evalStructMemberAccess(s *std.GoMixStruct, node parser.Expre
 	// Handle Field Access
 	if ident, ok := node.(*parser.IdentifierExpressionNode); ok {
 		fieldName := ident.Name
-		if val, ok := s.ClassFields[fieldName]; ok {
+		if val, _, ok := s.LookUpField(fieldName); ok {
 			return val
 		}
 		return e.CreateError("ERROR: class field (%s) not found in struct (%s)", fieldName, s.Name)
@@ -456,10 +526,9 @@ func (e *Evaluator) evalStructMemberAccess(s *std.GoMixStruct, node parser.Expre
 
 // evalPackageMemberAccess evaluates member access on a package (e.g., math.abs).
 //
-// This method handles accessing functions from an imported package. Note that this
-// is primarily used for direct access (e.g., getting a reference to the function).
-// Actual function calls are handled through evalCallExpression which has special
-// logic for package.function() calls.
+// This method handles accessing members of an imported package: the exported
+// members of a user module, or the package functions as builtin values
+// (e.g., map_array(xs, math.abs)).
 //
 // Parameters:
 //   - pkg: The package object
@@ -472,12 +541,14 @@ func (e *Evaluator) evalPackageMemberAccess(pkg *std.Package, node parser.Expres
 	// Handle Function Access
 	if ident, ok := node.(*parser.IdentifierExpressionNode); ok {
 		funcName := ident.Name
-		if _, ok := pkg.Functions[funcName]; ok {
-			// Function exists - the actual call will be handled in evalCallExpression
-			// We return nil here since this path is only hit for non-call accesses
-			return &std.Nil{}
+		// User modules export structs, enums, constants and functions as values
+		if val, ok := pkg.Members[funcName]; ok {
+			return val
+		}
+		if fn, ok := pkg.Functions[funcName]; ok {
+			return fn
 		}
-		return e.CreateError("ERROR: function '%s' not found in package '%s'", funcName, pkg.Name)
+		return e.CreateError("ERROR: member '%s' not found in package '%s'", funcName, pkg.Name)
 	}
 	return e.CreateError("ERROR: invalid member access on package")
 }
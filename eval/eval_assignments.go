@@ -72,6 +72,11 @@ func (e *Evaluator) evalAssignmentExpression(n *parser.AssignmentExpressionNode)
 		}
 	}
 
+	// Handle destructuring assignment ([a, b] = [b, a])
+	if pattern, ok := n.Left.(*parser.PatternNode); ok {
+		return e.evalPatternAssignment(pattern, rightVal)
+	}
+
 	// Should not reach here if parser is correct
 	return e.CreateError("ERROR: invalid assignment target")
 }
@@ -89,29 +94,8 @@ func (e *Evaluator) evalAssignmentExpression(n *parser.AssignmentExpressionNode)
 // Returns:
 //   - objects.GoMixObject: The result of the assignment (the new value), or an Error object
 func (e *Evaluator) evalCompoundAssignment(n *parser.AssignmentExpressionNode) std.GoMixObject {
-	var binOpType lexer.TokenType
-	switch n.Operation.Type {
-	case lexer.PLUS_ASSIGN:
-		binOpType = lexer.PLUS_OP
-	case lexer.MINUS_ASSIGN:
-		binOpType = lexer.MINUS_OP
-	case lexer.MUL_ASSIGN:
-		binOpType = lexer.MUL_OP
-	case lexer.DIV_ASSIGN:
-		binOpType = lexer.DIV_OP
-	case lexer.MOD_ASSIGN:
-		binOpType = lexer.MOD_OP
-	case lexer.BIT_AND_ASSIGN:
-		binOpType = lexer.BIT_AND_OP
-	case lexer.BIT_OR_ASSIGN:
-		binOpType = lexer.BIT_OR_OP
-	case lexer.BIT_XOR_ASSIGN:
-		binOpType = lexer.BIT_XOR_OP
-	case lexer.BIT_LEFT_ASSIGN:
-		binOpType = lexer.BIT_LEFT_OP
-	case lexer.BIT_RIGHT_ASSIGN:
-		binOpType = lexer.BIT_RIGHT_OP
-	default:
+	binOpType, ok := compoundOperator(n.Operation.Type)
+	if !ok {
 		return e.createError(n.Operation, "ERROR: unknown compound assignment operator: %s", n.Operation.Literal)
 	}
 
@@ -144,26 +128,8 @@ func (e *Evaluator) evalCompoundAssignment(n *parser.AssignmentExpressionNode) s
 			return index
 		}
 
-		leftVal := e.getIndexValue(container, index)
-		if IsError(leftVal) {
-			return leftVal
-		}
-
-		newVal := e.evaluateBinaryOp(n.Operation, binOpType, leftVal, rightVal)
-		if IsError(newVal) {
-			return newVal
-		}
-
-		switch container.GetType() {
-		case std.ArrayType:
-			return e.evalArrayIndexAssignment(container, index, newVal)
-		case std.ListType:
-			return e.evalListIndexAssignment(container, index, newVal)
-		case std.MapType:
-			return e.evalMapIndexAssignment(container, index, newVal)
-		default:
-			return e.CreateError("ERROR: index assignment not supported for type '%s'", container.GetType())
-		}
+		e.at(indexNode.Token)
+		return e.compoundIndexAssign(n.Operation, binOpType, container, index, rightVal)
 	}
 
 	// 3. Member Access
@@ -174,23 +140,27 @@ func (e *Evaluator) evalCompoundAssignment(n *parser.AssignmentExpressionNode) s
 				return leftObj
 			}
 			if leftObj.GetType() == std.StructType {
-				s := leftObj.(*std.GoMixStruct)
 				ident, ok := binNode.Right.(*parser.IdentifierExpressionNode)
 				if !ok {
 					return e.CreateError("ERROR: invalid member assignment target")
 				}
-				if s.ConstFields[ident.Name] {
-					return e.CreateError("ERROR: can't assign to constant field (%s) in struct (%s)", ident.Name, s.Name)
-				}
-				leftVal, ok := s.ClassFields[ident.Name]
+				// Inherited static fields are shared with the struct declaring them
+				leftVal, s, ok := leftObj.(*std.GoMixStruct).LookUpField(ident.Name)
 				if !ok {
 					return e.CreateError("ERROR: class field (%s) not found", ident.Name)
 				}
+				if s.ConstFields[ident.Name] {
+					return e.CreateError("ERROR: can't assign to constant field (%s) in struct (%s)", ident.Name, s.Name)
+				}
 				newVal := e.evaluateBinaryOp(n.Operation, binOpType, leftVal, rightVal)
 				if IsError(newVal) {
 					return newVal
 				}
-				if s.LetFields[ident.Name] {
+				if s.FieldTypes[ident.Name] != nil {
+					if newVal = e.conformField(s, ident.Name, newVal); IsError(newVal) {
+						return newVal
+					}
+				} else if s.LetFields[ident.Name] {
 					expectedType := s.LetTypes[ident.Name]
 					if newVal.GetType() != expectedType {
 						return e.CreateError("ERROR: can't assign `%s` to field (%s) of type `%s` in struct (%s)", newVal.GetType(), ident.Name, expectedType, s.Name)
@@ -216,6 +186,9 @@ func (e *Evaluator) evalCompoundAssignment(n *parser.AssignmentExpressionNode) s
 			if IsError(newVal) {
 				return newVal
 			}
+			if newVal = e.conformField(inst.Struct, ident.Name, newVal); IsError(newVal) {
+				return newVal
+			}
 			inst.InstanceFields[ident.Name] = newVal
 			return newVal
 		}
@@ -228,6 +201,7 @@ func (e *Evaluator) evalCompoundAssignment(n *parser.AssignmentExpressionNode) s
 //
 // This method performs the necessary checks to ensure that the variable exists,
 // is not a constant, and if it's a 'let' variable, that the assigned value matches the declared type.
+// The value must also conform to the annotated type of the variable, if any (see conform).
 // It then updates the variable in its defining scope using Scope.Assign(),
 // which is crucial for closures to work correctly.
 //
@@ -249,8 +223,16 @@ func (e *Evaluator) evalIdentifierAssignment(ident *parser.IdentifierExpressionN
 		return e.createError(ident.Token, "ERROR: can't assign to constant (%s)", ident.Name)
 	}
 
-	// Check if it's a let variable and if the type is compatible
-	if e.Scp.IsLetVariable(ident.Name) {
+	// Check that the value conforms to the annotated type, which takes the
+	// place of the type a let variable is locked to
+	if typ := e.Scp.DeclaredType(ident.Name); typ != nil {
+		conformed, ok := e.conform(val, typ)
+		if !ok {
+			return e.createError(ident.Token, "ERROR: can't assign `%s` to variable (%s) of type `%s`", typeOf(val), ident.Name, typ)
+		}
+		val = conformed
+	} else if e.Scp.IsLetVariable(ident.Name) {
+		// Check if it's a let variable and if the type is compatible
 		expectedType, ok := e.Scp.GetLetType(ident.Name)
 		if !ok {
 			return e.createError(ident.Token, "ERROR: let variable type not found: (%s)", ident.Name)
@@ -292,6 +274,28 @@ func (e *Evaluator) evalIndexAssignment(indexNode *parser.IndexExpressionNode, v
 		return index
 	}
 
+	e.at(indexNode.Token)
+	return e.assignIndex(container, index, val)
+}
+
+// assignIndex stores val at container[index] for an evaluated container and index.
+//
+// Parameters:
+//   - container: The array, list, map or struct instance being assigned into
+//   - index: The index or key
+//   - val: The value to store
+//
+// Returns:
+//   - std.GoMixObject: val on success, or an Error for bad indices and unsupported containers
+func (e *Evaluator) assignIndex(container, index, val std.GoMixObject) std.GoMixObject {
+	// Struct instances store elements with their __setindex__ method
+	if res, ok := std.CallProtocol(e, container, "__setindex__", index, val); ok {
+		if IsError(res) {
+			return res
+		}
+		return val
+	}
+
 	// Handle different container types
 	switch container.GetType() {
 	case std.ArrayType:
@@ -383,32 +387,27 @@ func (e *Evaluator) evalListIndexAssignment(container, index, val std.GoMixObjec
 
 // evalMapIndexAssignment handles assignment to a map key.
 //
-// This method assigns a value to a specific key in a map. It converts the index
-// object to a string key, checks if the key exists to maintain insertion order
-// (if it's a new key), and then updates the map's pairs.
+// This method assigns a value to a specific key in a map. It hashes the index
+// object (see std.Hash) and stores the value; a new key is added after the
+// existing ones, to maintain insertion order.
 //
 // Parameters:
 //   - container: The Map object to update
-//   - index: The key object (converted to string)
+//   - index: The key object (any hashable value)
 //   - val: The value to assign
 //
 // Returns:
-//   - objects.GoMixObject: The assigned value
+//   - objects.GoMixObject: The assigned value, or an Error if the key is not hashable
 func (e *Evaluator) evalMapIndexAssignment(container, index, val std.GoMixObject) std.GoMixObject {
 	m := container.(*std.Map)
 
-	// Convert index to string key
-	keyStr := index.ToString()
-
-	// Check if key already exists
-	_, exists := m.Pairs[keyStr]
-	if !exists {
-		// New key - add to keys list to maintain insertion order
-		m.Keys = append(m.Keys, keyStr)
+	hk, err := e.hash(index)
+	if err != nil {
+		return err
 	}
 
 	// Assign the value
-	m.Pairs[keyStr] = val
+	m.Set(hk, index, val)
 	return val
 }
 
@@ -436,10 +435,18 @@ func (e *Evaluator) evalMemberAssignment(node *parser.BinaryExpressionNode, val
 		if !ok {
 			return e.CreateError("ERROR: invalid member assignment target")
 		}
+		// Inherited static fields are shared with the struct declaring them
+		if _, owner, found := s.LookUpField(ident.Name); found {
+			s = owner
+		}
 		if s.ConstFields[ident.Name] {
 			return e.CreateError("ERROR: can't assign to constant field (%s) in struct (%s)", ident.Name, s.Name)
 		}
-		if s.LetFields[ident.Name] {
+		if s.FieldTypes[ident.Name] != nil {
+			if val = e.conformField(s, ident.Name, val); IsError(val) {
+				return val
+			}
+		} else if s.LetFields[ident.Name] {
 			expectedType := s.LetTypes[ident.Name]
 			if val.GetType() != expectedType {
 				return e.CreateError("ERROR: can't assign `%s` to field (%s) of type `%s` in struct (%s)", val.GetType(), ident.Name, expectedType, s.Name)
@@ -459,7 +466,67 @@ func (e *Evaluator) evalMemberAssignment(node *parser.BinaryExpressionNode, val
 	if !ok {
 		return e.CreateError("ERROR: invalid member assignment target")
 	}
+	if val = e.conformField(inst.Struct, ident.Name, val); IsError(val) {
+		return val
+	}
 
 	inst.InstanceFields[ident.Name] = val
 	return val
 }
+
+// compoundOperator maps a compound assignment operator (+=, -=, ...) to the
+// binary operator it applies.
+//
+// Returns:
+//   - lexer.TokenType: The binary operator (e.g., PLUS_OP for PLUS_ASSIGN)
+//   - bool: false if op is not a compound assignment operator
+func compoundOperator(op lexer.TokenType) (lexer.TokenType, bool) {
+	switch op {
+	case lexer.PLUS_ASSIGN:
+		return lexer.PLUS_OP, true
+	case lexer.MINUS_ASSIGN:
+		return lexer.MINUS_OP, true
+	case lexer.MUL_ASSIGN:
+		return lexer.MUL_OP, true
+	case lexer.DIV_ASSIGN:
+		return lexer.DIV_OP, true
+	case lexer.MOD_ASSIGN:
+		return lexer.MOD_OP, true
+	case lexer.BIT_AND_ASSIGN:
+		return lexer.BIT_AND_OP, true
+	case lexer.BIT_OR_ASSIGN:
+		return lexer.BIT_OR_OP, true
+	case lexer.BIT_XOR_ASSIGN:
+		return lexer.BIT_XOR_OP, true
+	case lexer.BIT_LEFT_ASSIGN:
+		return lexer.BIT_LEFT_OP, true
+	case lexer.BIT_RIGHT_ASSIGN:
+		return lexer.BIT_RIGHT_OP, true
+	}
+	return "", false
+}
+
+// compoundIndexAssign performs container[index] op= right for an evaluated
+// container, index and right-hand side.
+//
+// Parameters:
+//   - op: The compound operator token (used for error messages)
+//   - binOpType: The binary operator to apply (see compoundOperator)
+//   - container: The array, list or map being updated
+//   - index: The index or key
+//   - right: The evaluated right-hand side
+//
+// Returns:
+//   - std.GoMixObject: The new element value, or an Error
+func (e *Evaluator) compoundIndexAssign(op lexer.Token, binOpType lexer.TokenType, container, index, right std.GoMixObject) std.GoMixObject {
+	leftVal := e.getIndexValue(container, index)
+	if IsError(leftVal) {
+		return leftVal
+	}
+
+	newVal := e.evaluateBinaryOp(op, binOpType, leftVal, right)
+	if IsError(newVal) {
+		return newVal
+	}
+	return e.assignIndex(container, index, newVal)
+}
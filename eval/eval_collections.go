@@ -6,6 +6,7 @@ Contact : akashmaji(@iisc.ac.in)
 package eval
 
 import (
+	"github.com/akashmaji946/go-mix/lexer"
 	"github.com/akashmaji946/go-mix/parser"
 	"github.com/akashmaji946/go-mix/std"
 )
@@ -43,7 +44,9 @@ func (e *Evaluator) evalArrayExpression(n *parser.ArrayExpressionNode) std.GoMix
 		}
 		elements[i] = evaluated
 	}
-	return &std.Array{Elements: elements}
+	arr := &std.Array{Elements: elements}
+	e.charge(arr)
+	return arr
 }
 
 // evalMapExpression evaluates map literal expressions to create map objects.
@@ -55,7 +58,7 @@ func (e *Evaluator) evalArrayExpression(n *parser.ArrayExpressionNode) std.GoMix
 // 4. Creating a Map object with the key-value pairs
 //
 // Maps in Go-Mix:
-// - Keys are converted to strings using ToString() for consistent hashing
+// - Keys are hashable values (see std.Hash) and keep their types: 1 and "1" are different keys
 // - Values can be of any type
 // - Duplicate keys: Later values overwrite earlier ones
 // - Empty maps are supported: map{}
@@ -74,50 +77,117 @@ func (e *Evaluator) evalArrayExpression(n *parser.ArrayExpressionNode) std.GoMix
 //	map{1: "one", 2: "two", 3: "three"}    // Mixed content
 //	map{x: y, a+b: c*d}                    // Computed keys and values
 func (e *Evaluator) evalMapExpression(n *parser.MapExpressionNode) std.GoMixObject {
-	pairs := make(map[string]std.GoMixObject)
-	keys := make([]string, 0, len(n.Keys))
-
+	keys := make([]std.GoMixObject, len(n.Keys))
+	values := make([]std.GoMixObject, len(n.Keys))
 	for i := range n.Keys {
-		// Evaluate key
-		keyObj := e.Eval(n.Keys[i])
-		if IsError(keyObj) {
-			return keyObj
+		keys[i] = e.Eval(n.Keys[i])
+		if IsError(keys[i]) {
+			return keys[i]
 		}
 
-		// Evaluate value
-		valueObj := e.Eval(n.Values[i])
-		if IsError(valueObj) {
-			return valueObj
+		values[i] = e.Eval(n.Values[i])
+		if IsError(values[i]) {
+			return values[i]
 		}
+	}
+	m := e.newMapObject(n, keys, values)
+	if IsError(m) {
+		return m
+	}
+	e.charge(m)
+	return m
+}
 
-		// Convert key to string for map storage
-		keyStr := keyObj.ToString()
-
-		// Check if key already exists
-		if _, exists := pairs[keyStr]; !exists {
-			keys = append(keys, keyStr)
+// newMapObject builds a Map from evaluated keys and values.
+// Keys keep their types and their first-insertion order; a repeated key
+// overwrites the earlier value.
+//
+// Returns:
+//   - std.GoMixObject: The Map, or an Error if a key is not hashable
+func (e *Evaluator) newMapObject(n *parser.MapExpressionNode, keys, values []std.GoMixObject) std.GoMixObject {
+	m := std.NewMap()
+	for i, keyObj := range keys {
+		hk, err := e.hashAt(keyObj, exprToken(n.Keys[i]))
+		if err != nil {
+			return err
 		}
+		m.Set(hk, keyObj, values[i])
+	}
+	return m
+}
 
-		// Store the key-value pair
-		pairs[keyStr] = valueObj
+// hash returns the HashKey of a map key or set element (see std.Hash); an
+// error is reported at the current position.
+func (e *Evaluator) hash(key std.GoMixObject) (std.HashKey, std.GoMixObject) {
+	return e.hashAt(key, lexer.Token{})
+}
+
+// hashAt is hash for a key written in the source at the given token (e.g. a
+// key of a map literal), where an error is reported. A zero token stands for
+// the current position.
+func (e *Evaluator) hashAt(key std.GoMixObject, token lexer.Token) (std.HashKey, std.GoMixObject) {
+	hk, err := std.Hash(e, key)
+	if stdErr, ok := err.(*std.Error); ok && stdErr.Line == 0 {
+		if token.Line == 0 {
+			token.Line, token.Column = e.position()
+		}
+		return hk, e.createError(token, "%s", stdErr.Message)
 	}
+	return hk, err
+}
 
-	return &std.Map{
-		Pairs: pairs,
-		Keys:  keys,
+// exprToken returns the first token of an expression, where errors about
+// its value are reported, or a zero token if it is not known.
+func exprToken(node parser.ExpressionNode) lexer.Token {
+	switch n := node.(type) {
+	case *parser.IntegerLiteralExpressionNode:
+		return n.Token
+	case *parser.FloatLiteralExpressionNode:
+		return n.Token
+	case *parser.BooleanLiteralExpressionNode:
+		return n.Token
+	case *parser.NilLiteralExpressionNode:
+		return n.Token
+	case *parser.StringLiteralExpressionNode:
+		return n.Token
+	case *parser.InterpolatedStringExpressionNode:
+		return n.Token
+	case *parser.CharLiteralExpressionNode:
+		return n.Token
+	case *parser.IdentifierExpressionNode:
+		return n.Token
+	case *parser.ArrayExpressionNode:
+		return n.Token
+	case *parser.MapExpressionNode:
+		return n.Token
+	case *parser.SetExpressionNode:
+		return n.Token
+	case *parser.TupleExpressionNode:
+		return n.LeftParen
+	case *parser.NewCallExpressionNode:
+		return n.NewToken
+	case *parser.UnaryExpressionNode:
+		return n.Operation
+	case *parser.BinaryExpressionNode:
+		return exprToken(n.Left)
+	case *parser.IndexExpressionNode:
+		return exprToken(n.Left)
+	case *parser.CallExpressionNode:
+		return exprToken(n.Callee)
 	}
+	return lexer.Token{}
 }
 
 // evalSetExpression evaluates set literal expressions to create set objects.
 //
 // This method processes set literals by:
 // 1. Evaluating each element expression
-// 2. Converting elements to strings for uniqueness checking
+// 2. Hashing elements for uniqueness checking
 // 3. Automatically removing duplicates
 // 4. Creating a Set object with unique values
 //
 // Sets in Go-Mix:
-// - Elements are converted to strings using ToString() for uniqueness
+// - Elements are hashable values (see std.Hash) and keep their types
 // - Duplicates are automatically removed
 // - Order of first occurrence is preserved
 // - Empty sets are supported: set{}
@@ -136,30 +206,36 @@ func (e *Evaluator) evalMapExpression(n *parser.MapExpressionNode) std.GoMixObje
 //	set{1, 2, 2, 3}                 // Duplicates removed -> set{1, 2, 3}
 //	set{x, y, x+y}                  // Computed elements
 func (e *Evaluator) evalSetExpression(n *parser.SetExpressionNode) std.GoMixObject {
-	elements := make(map[string]bool)
-	values := make([]string, 0)
-
-	for _, elemExpr := range n.Elements {
-		// Evaluate element
-		elemObj := e.Eval(elemExpr)
-		if IsError(elemObj) {
-			return elemObj
-		}
-
-		// Convert element to string for uniqueness
-		elemStr := elemObj.ToString()
-
-		// Add only if not already present (ensures uniqueness)
-		if !elements[elemStr] {
-			elements[elemStr] = true
-			values = append(values, elemStr)
+	elems := make([]std.GoMixObject, len(n.Elements))
+	for i, elemExpr := range n.Elements {
+		elems[i] = e.Eval(elemExpr)
+		if IsError(elems[i]) {
+			return elems[i]
 		}
 	}
+	set := e.newSetObject(n, elems)
+	if IsError(set) {
+		return set
+	}
+	e.charge(set)
+	return set
+}
 
-	return &std.Set{
-		Elements: elements,
-		Values:   values,
+// newSetObject builds a Set from evaluated elements, dropping duplicates
+// and keeping first-insertion order.
+//
+// Returns:
+//   - std.GoMixObject: The Set, or an Error if an element is not hashable
+func (e *Evaluator) newSetObject(n *parser.SetExpressionNode, elems []std.GoMixObject) std.GoMixObject {
+	set := std.NewSet()
+	for i, elemObj := range elems {
+		hk, err := e.hashAt(elemObj, exprToken(n.Elements[i]))
+		if err != nil {
+			return err
+		}
+		set.Add(hk, elemObj)
 	}
+	return set
 }
 
 // evalRangeExpression evaluates range expressions to create Range objects.
@@ -198,6 +274,13 @@ func (e *Evaluator) evalRangeExpression(n *parser.RangeExpressionNode) std.GoMix
 		return end
 	}
 
+	e.at(n.Token)
+	return e.makeRange(start, end)
+}
+
+// makeRange builds a Range from evaluated start and end values.
+// Both must be integers.
+func (e *Evaluator) makeRange(start, end std.GoMixObject) std.GoMixObject {
 	// Validate both are integers
 	if start.GetType() != std.IntegerType {
 		return e.CreateError("ERROR: range start must be an integer, got '%s'", start.GetType())
@@ -0,0 +1,261 @@
+/*
+File    : go-mix/eval/eval_concurrency.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// yieldInterval is the number of steps after which a goroutine lets the
+// other goroutines run.
+const yieldInterval = 256
+
+// scheduler lets the goroutines of a program take turns running Go-Mix code.
+//
+// Values, scopes and the evaluator's caches are shared by all goroutines, so
+// only the goroutine holding mu may run: the main program holds it while it
+// runs (see RunLimited) and each spawned goroutine while it is not waiting.
+// Goroutines hand it over every yieldInterval steps and whenever they wait
+// (see Wait and Blocking). The scheduler is shared by an evaluator, the
+// evaluators of its goroutines and those of the modules they import.
+type scheduler struct {
+	mu      sync.Mutex
+	changed *sync.Cond // Broadcast when a channel, mutex or wait group changes
+	tasks   int        // Goroutines running Go-Mix code, including the main program
+	blocked int        // Tasks waiting for a change without a timeout
+	main    bool       // Whether the main program is running
+}
+
+// newScheduler creates the scheduler of a new evaluator.
+func newScheduler() *scheduler {
+	s := &scheduler{}
+	s.changed = sync.NewCond(&s.mu)
+	return s
+}
+
+// wake wakes every waiting task so that it checks its condition again.
+// It must be called with mu held.
+func (s *scheduler) wake() {
+	s.blocked = 0
+	s.changed.Broadcast()
+}
+
+// wakeLater wakes every waiting task from another goroutine (a timer or a
+// context callback).
+func (s *scheduler) wakeLater() {
+	s.mu.Lock()
+	s.wake()
+	s.mu.Unlock()
+}
+
+// enterProgram takes the turn for the main program of the evaluator.
+func (s *scheduler) enterProgram() {
+	s.mu.Lock()
+	s.tasks++
+	s.main = true
+}
+
+// leaveProgram ends the main program and hands the turn over.
+func (s *scheduler) leaveProgram() {
+	s.tasks--
+	s.main = false
+	s.wake()
+	s.mu.Unlock()
+}
+
+// yield lets the other goroutines run if any is waiting for its turn.
+func (s *scheduler) yield() {
+	if s.tasks > 1 {
+		s.mu.Unlock()
+		runtime.Gosched()
+		s.mu.Lock()
+	}
+}
+
+// Wait blocks the current goroutine until ready returns true, letting the
+// other goroutines run meanwhile. ready is called in turn with the other
+// goroutines and may change the objects it checks (e.g., take a value from a
+// channel); the waiting goroutines are woken up whenever it succeeds.
+// This implements the std.Runtime interface.
+//
+// A wait without timeout fails when the main program is running and every
+// goroutine is waiting (a deadlock), and a wait stops with a LimitError when
+// the program is canceled or times out.
+//
+// Parameters:
+//   - ready: The condition to wait for
+//   - timeout: How long to wait at most (0 waits until ready succeeds)
+//
+// Returns:
+//   - bool: Whether ready succeeded (false if the timeout expired)
+//   - std.GoMixObject: nil, or an Error on deadlock, cancellation or timeout
+func (e *Evaluator) Wait(ready func() bool, timeout time.Duration) (bool, std.GoMixObject) {
+	s := e.sched
+	if ready() {
+		s.wake()
+		return true, nil
+	}
+
+	expired := false
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			s.mu.Lock()
+			expired = true
+			s.wake()
+			s.mu.Unlock()
+		})
+		defer timer.Stop()
+	}
+	if l := e.limits; l.ctx != nil {
+		stop := context.AfterFunc(l.ctx, s.wakeLater)
+		defer stop()
+	}
+	if l := e.limits; !l.deadline.IsZero() {
+		timer := time.AfterFunc(time.Until(l.deadline), s.wakeLater)
+		defer timer.Stop()
+	}
+
+	for {
+		if err := e.checkCanceled(); err != nil {
+			return false, err
+		}
+		if timeout <= 0 {
+			s.blocked++
+			if s.main && s.blocked >= s.tasks {
+				s.blocked--
+				return false, e.CreateError("ERROR: deadlock: all goroutines are waiting")
+			}
+		}
+		s.changed.Wait()
+		if ready() {
+			s.wake()
+			return true, nil
+		}
+		if expired {
+			return false, nil
+		}
+	}
+}
+
+// Blocking runs fn (e.g., a sleep or a server loop) while the other goroutines
+// run. fn must not touch Go-Mix values. This implements the std.Runtime interface.
+func (e *Evaluator) Blocking(fn func()) {
+	s := e.sched
+	s.mu.Unlock()
+	defer s.mu.Lock()
+	fn()
+}
+
+// Attach runs fn as a new goroutine of the program on the calling Go
+// goroutine, waiting for its turn first. It lets code running outside the
+// program (e.g., an HTTP handler) call Go-Mix functions.
+// This implements the std.Runtime interface.
+func (e *Evaluator) Attach(fn func(rt std.Runtime) std.GoMixObject) std.GoMixObject {
+	s := e.sched
+	s.mu.Lock()
+	s.tasks++
+	return e.fork(e.trace.line, e.trace.column).runTask(func(child *Evaluator) std.GoMixObject {
+		return fn(child)
+	})
+}
+
+// fork creates the evaluator of a new goroutine. It shares the program's
+// values, caches, limits and scheduler with e but has its own current scope
+// and call stack, whose outermost frame is the spawn at line:column.
+func (e *Evaluator) fork(line, column int) *Evaluator {
+	child := *e
+	child.trace = &callStack{root: "<spawn>", file: e.File, line: line, column: column}
+	child.spawned = true
+	child.gen = nil
+	return &child
+}
+
+// runTask runs call as a goroutine of the program. The caller must hold the
+// turn and have counted the task; runTask releases both when call returns.
+// An error that ends the goroutine is written to the evaluator's writer, with
+// its traceback, since nothing else would report it.
+func (e *Evaluator) runTask(call func(*Evaluator) std.GoMixObject) (result std.GoMixObject) {
+	s := e.sched
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = e.CreateError("ERROR: goroutine panicked: %v", recovered)
+		}
+		if IsError(result) || IsLimitError(result) {
+			fmt.Fprintf(e.Writer, "%s\n%s", result.ToString(), std.Traceback(result))
+		}
+		s.tasks--
+		s.wake()
+		s.mu.Unlock()
+	}()
+	return call(e)
+}
+
+// evalSpawnStatement evaluates a spawn statement: it starts a goroutine that
+// runs the call in turn with the rest of the program.
+//
+// The callee (function, builtin, package function or object) and the
+// arguments are evaluated right away, so that the goroutine sees the values
+// they had at the spawn (e.g., the current loop variable). The goroutine runs
+// on an evaluator of its own (see fork); its errors do not affect the caller.
+//
+// Parameters:
+//   - n: A SpawnStatementNode holding the call to run
+//
+// Returns:
+//   - std.GoMixObject: Nil, or an Error if the callee or an argument fails
+//
+// Example:
+//
+//	spawn worker(i, results);
+//	spawn counter.run(ch);
+func (e *Evaluator) evalSpawnStatement(n *parser.SpawnStatementNode) std.GoMixObject {
+	call, err := e.spawnTarget(n.Call)
+	if err != nil {
+		return err
+	}
+	e.at(n.Token)
+	child := e.fork(n.Token.Line, n.Token.Column)
+	e.sched.tasks++
+	go func() {
+		e.sched.mu.Lock()
+		child.runTask(call)
+	}()
+	return &std.Nil{}
+}
+
+// spawnTarget evaluates the callee and the arguments of a spawned call and
+// returns the call to run on the goroutine's evaluator.
+func (e *Evaluator) spawnTarget(n parser.ExpressionNode) (func(*Evaluator) std.GoMixObject, std.GoMixObject) {
+	callNode, ok := n.(*parser.CallExpressionNode)
+	if !ok {
+		return nil, e.CreateError("ERROR: spawn expects a function call")
+	}
+	callee := e.resolveCallee(callNode)
+	if IsError(callee) {
+		return nil, callee
+	}
+
+	args := make([]std.GoMixObject, len(callNode.Arguments))
+	for i, arg := range callNode.Arguments {
+		args[i] = e.Eval(arg)
+		if IsError(args[i]) {
+			return nil, args[i]
+		}
+	}
+
+	token := callToken(callNode)
+	return func(child *Evaluator) std.GoMixObject {
+		child.at(token)
+		return child.callValue(callee, callName(token), token, args, callNode.ArgNames)
+	}, nil
+}
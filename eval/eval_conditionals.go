@@ -7,6 +7,7 @@ package eval
 
 import (
 	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/scope"
 	"github.com/akashmaji946/go-mix/std"
 )
 
@@ -111,3 +112,59 @@ func (e *Evaluator) evalSwitchStatement(node parser.SwitchStatementNode) std.GoM
 
 	return &std.Nil{}
 }
+
+// evalMatchExpression evaluates a match expression: the result of the first
+// arm whose pattern matches the subject and whose guard, if any, holds.
+//
+// Each arm is tried in a new scope, holding the names bound by its pattern
+// for its guard and its result. A value matched by no arm is an error, which
+// names the missing members when the arms name members of an enum without
+// covering all of them (see checkExhaustive; go-mix check reports such a
+// match before the program runs).
+//
+// Parameters:
+//   - n: The MatchExpressionNode
+//
+// Returns:
+//   - std.GoMixObject: The result of the matching arm, or an Error
+//
+// Example:
+//
+//	match (code) { 200 => "ok", 400...499 => "client error", _ => "other" }
+func (e *Evaluator) evalMatchExpression(n *parser.MatchExpressionNode) std.GoMixObject {
+	subject := e.Eval(n.Subject)
+	if IsError(subject) {
+		return subject
+	}
+
+	oldScope := e.Scp
+	defer func() { e.Scp = oldScope }()
+	for _, arm := range n.Arms {
+		e.Scp = scope.NewScope(oldScope)
+		matched, err := e.matchPattern(arm.Pattern, subject)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := e.Eval(arm.Guard)
+			if IsError(guard) {
+				return guard
+			}
+			if guard.GetType() != std.BooleanType {
+				return e.CreateError("ERROR: match guard must be (bool), got (%s)", guard.GetType())
+			}
+			if !guard.(*std.Boolean).Value {
+				continue
+			}
+		}
+		return e.Eval(arm.Body)
+	}
+	e.Scp = oldScope
+	if err := e.checkExhaustive(n); err != nil {
+		return err
+	}
+	return e.createError(n.Token, "ERROR: no match arm matches value (%s)", subject.ToString())
+}
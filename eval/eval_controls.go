@@ -6,38 +6,37 @@ Contact : akashmaji(@iisc.ac.in)
 package eval
 
 import (
+	"fmt"
+
 	"github.com/akashmaji946/go-mix/function"
+	"github.com/akashmaji946/go-mix/lexer"
 	"github.com/akashmaji946/go-mix/parser"
 	"github.com/akashmaji946/go-mix/scope"
 	"github.com/akashmaji946/go-mix/std"
 )
 
-// evalCallExpression evaluates function call expressions for both builtin and user-defined functions.
+// evalCallExpression evaluates function call expressions on any callee.
 //
 // This method handles the complete function call process:
-// 1. Checks if the function name is a builtin (print, len, push, etc.)
-//   - If builtin: evaluates arguments and invokes the builtin directly
-//
-// 2. For user-defined functions:
-//   - Looks up the function in the scope chain
-//   - Validates it's actually a function object
-//   - Checks argument count matches parameter count
-//   - Creates a new call-site scope with the function's captured scope as parent
-//   - Binds arguments to parameters in the call-site scope
-//   - Evaluates the function body in the new scope
-//   - Unwraps return values and handles closure scope updates
+// 1. Evaluates the callee to a callable value (see evalCallee):
+//   - A name resolves to a builtin (print, len, push, etc.) or a function in scope
+//   - A member resolves to a package function or a method bound to its instance
+//   - Any other expression (a call, an index, a function literal) is evaluated
+//
+// 2. Checks the argument count of user-defined functions and methods
+// 3. Evaluates the arguments and calls the value (see callValue)
 //
 // The scope handling is critical for closures: functions capture their defining scope,
 // and when they return other functions, those returned functions get an updated scope
 // that includes variables from the call site.
 //
 // Parameters:
-//   - n: A CallExpressionNode containing the function identifier and argument expressions
+//   - n: A CallExpressionNode containing the callee and argument expressions
 //
 // Returns:
 //   - objects.GoMixObject: The function's return value, or an Error object if:
 //   - Function not found
-//   - Identifier is not a function
+//   - Callee is not a function
 //   - Wrong number of arguments provided
 //
 // Example:
@@ -45,109 +44,393 @@ import (
 //	print("Hello");           // Builtin function call
 //	add(5, 3);                // User-defined function call
 //	makeCounter()(10);        // Closure returning a function
+//	handlers[key](req);       // Function stored in a map
+//	obj.field.method();       // Method of a field
 func (e *Evaluator) evalCallExpression(n *parser.CallExpressionNode) std.GoMixObject {
+	callee := e.resolveCallee(n)
+	if IsError(callee) {
+		return callee
+	}
 
-	funcName := n.FunctionIdentifier.Name
+	args := make([]std.GoMixObject, len(n.Arguments))
+	for i, arg := range n.Arguments {
+		args[i] = e.Eval(arg)
+		if IsError(args[i]) {
+			return args[i]
+		}
+	}
+	token := callToken(n)
+	e.at(token)
+	return e.callValue(callee, callName(token), token, args, n.ArgNames)
+}
 
-	// Support package function calls: package.function()
-	if dotIdx := IndexOfDot(funcName); dotIdx > 0 {
-		objName := funcName[:dotIdx]
-		methodName := funcName[dotIdx+1:]
+// resolveCallee evaluates the callee of a call and checks that it is a
+// function taking the number of arguments of the call, before the arguments
+// are evaluated.
+//
+// Parameters:
+//   - n: The CallExpressionNode
+//
+// Returns:
+//   - std.GoMixObject: The function, builtin or bound method to call, or an Error
+func (e *Evaluator) resolveCallee(n *parser.CallExpressionNode) std.GoMixObject {
+	callee := e.evalCallee(n.Callee)
+	if IsError(callee) {
+		return callee
+	}
+	if callee.GetType() != std.FunctionType {
+		return e.createError(callToken(n), "ERROR: not a function: (%s)", n.Callee.Literal())
+	}
+	// Validate argument count
+	if err := e.checkArgumentCount(callee, len(n.Arguments), n.ArgNames); err != nil {
+		return err
+	}
+	return callee
+}
 
-		objVal, ok := e.Scp.LookUp(objName)
+// evalCallee evaluates the callee of a call expression.
+//
+// Names are looked up among the builtins first, then in the scope chain.
+// Package members resolve to the package function, and members of struct
+// instances to the method of that name (bound to the instance) or, if there
+// is none, to the field. super.name resolves to the method of the parent of
+// the struct whose method is running. Other callees are evaluated as expressions.
+//
+// Parameters:
+//   - callee: The expression before the argument list
+//
+// Returns:
+//   - std.GoMixObject: The value to call, or an Error if it cannot be resolved
+func (e *Evaluator) evalCallee(callee parser.ExpressionNode) std.GoMixObject {
+	switch n := callee.(type) {
+	case *parser.IdentifierExpressionNode:
+		if builtin, ok := e.Builtins[n.Name]; ok {
+			return builtin
+		}
+		obj, ok := e.Scp.LookUp(n.Name)
 		if !ok {
-			return e.createError(n.FunctionIdentifier.Token, "ERROR: object not found: (%s)", objName)
+			return e.createError(n.Token, "ERROR: function not found: (%s)", n.Name)
 		}
-
-		// Check if it's a package (imported module)
-		if pkg, isPkg := objVal.(*std.Package); isPkg {
-			// Look up the function in the package
-			fn, exists := pkg.Functions[methodName]
-			if !exists {
-				return e.createError(n.FunctionIdentifier.Token, "ERROR: function '%s' not found in package '%s'", methodName, objName)
+		return obj
+	case *parser.BinaryExpressionNode:
+		ident, isIdent := n.Right.(*parser.IdentifierExpressionNode)
+		if n.Operation.Type != lexer.DOT_OP || !isIdent {
+			break
+		}
+		if super, ok := n.Left.(*parser.SuperExpressionNode); ok {
+			e.at(n.Operation)
+			return e.evalSuperMember(super, ident)
+		}
+		left := e.Eval(n.Left)
+		if IsError(left) {
+			return left
+		}
+		e.at(n.Operation)
+		switch obj := left.(type) {
+		case *std.Package:
+			// the functions of user modules are members, called like any function
+			if member, ok := obj.Members[ident.Name]; ok {
+				return member
+			}
+			if fn, ok := obj.Functions[ident.Name]; ok {
+				return fn
 			}
-			// Evaluate arguments
-			args := make([]std.GoMixObject, len(n.Arguments))
-			for i, arg := range n.Arguments {
-				args[i] = e.Eval(arg)
-				if IsError(args[i]) {
-					return args[i]
+			return e.createError(ident.Token, "ERROR: function '%s' not found in package '%s'", ident.Name, obj.Name)
+		case *std.GoMixObjectInstance:
+			if _, ok := obj.Struct.GetMethod(ident.Name); ok {
+				return &std.BoundMethod{Receiver: obj, Name: ident.Name}
+			}
+			if _, ok := obj.InstanceFields[ident.Name]; !ok {
+				if _, _, ok := obj.Struct.LookUpField(ident.Name); !ok {
+					return e.CreateError("ERROR: method (%s) does not exist in struct (%s)", ident.Name, obj.Struct.GetName())
 				}
 			}
-			// Call the package function
-			return fn.Callback(e, e.Writer, args...)
+			return e.evalMemberAccess(obj, ident)
 		}
+		return e.evalMember(n, left)
+	}
+	return e.Eval(callee)
+}
 
-		// Handle struct instance method calls
-		inst, ok := objVal.(*std.GoMixObjectInstance)
+// checkArgumentCount checks the arguments passed to a user-defined function
+// or to a method: their number, and the names of the named arguments.
+// Builtins check their own arguments, but take no named arguments.
+//
+// Parameters:
+//   - callee: The value being called
+//   - argc: The number of arguments of the call
+//   - names: The names of the arguments ("" for positional ones), or nil if none is named
+//
+// Returns:
+//   - An Error if the arguments do not fit the parameters, otherwise nil
+func (e *Evaluator) checkArgumentCount(callee std.GoMixObject, argc int, names []string) std.GoMixObject {
+	switch fn := callee.(type) {
+	case *function.Function:
+		return e.checkArguments(fn, argc, names, func(expected string) std.GoMixObject {
+			return e.CreateError("ERROR: wrong number of arguments: expected %s, got %d", expected, argc)
+		})
+	case *std.BoundMethod:
+		found, _, _ := fn.LookUp()
+		method, ok := found.(*function.Function)
 		if !ok {
-			return e.CreateError("ERROR: (%s) is not a struct instance or package", objName)
-		}
-		// Prepare named parameters for method call
-		params := make([]NamedParameter, len(n.Arguments))
-		for i, arg := range n.Arguments {
-			evaluated := e.Eval(arg)
-			if IsError(evaluated) {
-				return evaluated
-			}
-			params[i] = NamedParameter{Name: "", Value: evaluated}
+			return e.CreateError("ERROR: method (%s) does not exist in struct (%s)", fn.Name, fn.Receiver.Struct.GetName())
+		}
+		return e.checkArguments(method, argc, names, func(expected string) std.GoMixObject {
+			return e.CreateError("ERROR: wrong number of arguments for method (%s): expected %s, got %d", fn.Name, expected, argc)
+		})
+	case *std.Builtin:
+		if names != nil {
+			return e.CreateError("ERROR: builtin (%s) does not take named arguments", fn.Name)
+		}
+	}
+	return nil
+}
+
+// callValue calls a callable value with evaluated arguments.
+//
+// User-defined functions run in a new call-site scope whose parent is the
+// scope they captured; builtins are invoked directly; bound methods run with
+// 'this' bound to their receiver.
+//
+// Parameters:
+//   - callee: The function, builtin or bound method to call
+//   - calledAs: The name the function was called by, for tracebacks ("" if none)
+//   - token: The position of the call, given to errors raised by builtins
+//   - args: The evaluated arguments
+//   - names: The names of the arguments ("" for positional ones), or nil if none is named
+//
+// Returns:
+//   - std.GoMixObject: The return value, or an Error
+func (e *Evaluator) callValue(callee std.GoMixObject, calledAs string, token lexer.Token, args []std.GoMixObject, names []string) std.GoMixObject {
+	if err := e.checkArgumentCount(callee, len(args), names); err != nil {
+		return err
+	}
+	switch fn := callee.(type) {
+	case *function.Function:
+		// Create a new scope with the function's captured scope as parent
+		parentScope := e.Scp
+		if fn.Scp != nil {
+			parentScope = fn.Scp
+		}
+		callSiteScope := scope.NewScope(parentScope)
+		if err := e.bindArguments(fn, callSiteScope, namedParameters(args, names)); err != nil {
+			return err
+		}
+		result := e.runFunctionBody(fn, functionFrameName(fn, calledAs), callSiteScope)
+		return returnFromCall(result, callSiteScope)
+	case *std.Builtin:
+		res := fn.Callback(e, e.Writer, args...)
+		e.charge(res)
+		return e.withPosition(res, token)
+	case *std.BoundMethod:
+		return e.callFunctionOnObject(fn, namedParameters(args, names)...)
+	}
+	return e.CreateError("ERROR: object is not a function")
+}
+
+// checkArguments checks that the arguments of a call fit the parameters of fn:
+// the positional arguments fill the parameters in order (the extra ones go to
+// the rest parameter), the named arguments fill the parameters of that name,
+// and the parameters left without an argument must have a default value.
+//
+// Parameters:
+//   - fn: The called function
+//   - argc: The number of arguments of the call
+//   - names: The names of the arguments ("" for positional ones), or nil if none is named
+//   - wrongCount: Builds the error for a wrong number of arguments, given the
+//     number expected (e.g., "2", "1 to 3" or "at least 1")
+//
+// Returns:
+//   - An Error if the arguments do not fit, otherwise nil
+func (e *Evaluator) checkArguments(fn *function.Function, argc int, names []string, wrongCount func(expected string) std.GoMixObject) std.GoMixObject {
+	required, fixed := fn.Arity()
+	positional := positionalCount(argc, names)
+	if (positional > fixed && !fn.Rest) || (names == nil && argc < required) {
+		expected := fmt.Sprint(fixed)
+		if fn.Rest {
+			expected = fmt.Sprintf("at least %d", required)
+		} else if required < fixed {
+			expected = fmt.Sprintf("%d to %d", required, fixed)
+		}
+		return wrongCount(expected)
+	}
+	if names == nil {
+		return nil
+	}
+
+	given := make([]bool, fixed)
+	for i := 0; i < positional && i < fixed; i++ {
+		given[i] = true
+	}
+	for _, name := range names[positional:] {
+		i := fn.ParamIndex(name)
+		if i < 0 || i >= fixed {
+			return e.CreateError("ERROR: unknown argument (%s) in call to (%s)", name, fn.Name)
+		}
+		if given[i] {
+			return e.CreateError("ERROR: argument (%s) given more than once in call to (%s)", name, fn.Name)
+		}
+		given[i] = true
+	}
+	for i := 0; i < fixed; i++ {
+		if !given[i] && fn.Default(i) == nil {
+			return e.CreateError("ERROR: missing argument (%s) in call to (%s)", fn.Params[i].Name, fn.Name)
+		}
+	}
+	return nil
+}
+
+// bindArguments binds the arguments of a call, checked by checkArguments, to
+// the parameters of fn in its call scope. Parameters without an argument get
+// their default value, evaluated in the call scope so that it can refer to
+// the parameters before it; the rest parameter gets an array of the extra
+// positional arguments. Values are checked against the annotated types of
+// the parameters.
+//
+// Parameters:
+//   - fn: The called function
+//   - callScope: The scope the function body runs in
+//   - args: The arguments, positional ones (with no name) first
+//
+// Returns:
+//   - An Error if a default value failed to evaluate or a value does not
+//     conform to the type of its parameter, otherwise nil
+func (e *Evaluator) bindArguments(fn *function.Function, callScope *scope.Scope, args []NamedParameter) std.GoMixObject {
+	_, fixed := fn.Arity()
+	values := make([]std.GoMixObject, fixed)
+	rest := make([]std.GoMixObject, 0)
+	positional := 0
+	for _, arg := range args {
+		if arg.Name != "" {
+			values[fn.ParamIndex(arg.Name)] = arg.Value
+			continue
+		}
+		if positional < fixed {
+			values[positional] = arg.Value
+		} else {
+			rest = append(rest, arg.Value)
 		}
-		result := e.callFunctionOnObject(methodName, inst, params...)
-		// fmt.Printf("DEBUG: Method call result type=%s\n", result.GetType())
-		return result
+		positional++
 	}
 
-	// look for builtin name
-	if ok := e.IsBuiltin(funcName); ok {
-		args := make([]std.GoMixObject, len(n.Arguments))
-		for i, arg := range n.Arguments {
-			args[i] = e.Eval(arg)
-			if IsError(args[i]) {
-				return args[i]
+	for i := 0; i < fixed; i++ {
+		if values[i] == nil {
+			oldScope := e.Scp
+			e.Scp = callScope
+			values[i] = e.Eval(fn.Default(i))
+			e.Scp = oldScope
+			if IsError(values[i]) {
+				return values[i]
 			}
 		}
-		rv := e.InvokeBuiltin(funcName, args...)
-		return rv
+		if err := e.bindParameter(fn, i, callScope, values[i]); err != nil {
+			return err
+		}
 	}
+	if fn.Rest {
+		arr := &std.Array{Elements: rest}
+		e.charge(arr)
+		return e.bindParameter(fn, fixed, callScope, arr)
+	}
+	return nil
+}
 
-	// lookup for function name
-	obj, ok := e.Scp.LookUp(funcName)
-	if !ok {
-		return e.createError(n.FunctionIdentifier.Token, "ERROR: function not found: (%s)", funcName)
+// bindParameter binds the value of the i-th parameter of fn in its call
+// scope, after checking it against the annotated type of the parameter.
+//
+// Returns:
+//   - An Error if the value does not conform to the type, otherwise nil
+func (e *Evaluator) bindParameter(fn *function.Function, i int, callScope *scope.Scope, val std.GoMixObject) std.GoMixObject {
+	param := fn.Params[i]
+	if typ := fn.ParamType(i); typ != nil {
+		conformed, ok := e.conform(val, typ)
+		if !ok {
+			return e.CreateError("ERROR: can't pass `%s` as parameter (%s) of type `%s` to (%s)", typeOf(val), param.Name, typ, functionFrameName(fn, ""))
+		}
+		val = conformed
+		callScope.Types[param.Name] = typ
 	}
-	if obj.GetType() != std.FunctionType {
-		return e.createError(n.FunctionIdentifier.Token, "ERROR: not a function: (%s)", funcName)
+	callScope.Bind(param.Name, val)
+	return nil
+}
+
+// callToken returns the token a call is reported at: the called name, the
+// member name of a method or package function call, or else the '('.
+func callToken(n *parser.CallExpressionNode) lexer.Token {
+	switch callee := n.Callee.(type) {
+	case *parser.IdentifierExpressionNode:
+		return callee.Token
+	case *parser.BinaryExpressionNode:
+		if ident, ok := callee.Right.(*parser.IdentifierExpressionNode); ok && callee.Operation.Type == lexer.DOT_OP {
+			return ident.Token
+		}
 	}
-	functionObject := obj.(*function.Function)
+	return n.LeftParen
+}
 
-	// Validate argument count
-	expectedArgs := len(functionObject.Params)
-	actualArgs := len(n.Arguments)
-	if actualArgs != expectedArgs {
-		return e.CreateError("ERROR: wrong number of arguments: expected %d, got %d", expectedArgs, actualArgs)
+// callName returns the name a function is called by in tracebacks: the called
+// name or member, or "" for other callees (whose call token is the '(').
+func callName(token lexer.Token) string {
+	if token.Type == lexer.LEFT_PAREN {
+		return ""
 	}
+	return token.Literal
+}
 
-	// Create a new scope with the function's captured scope as parent
-	var parentScope *scope.Scope
-	if functionObject.Scp != nil {
-		parentScope = functionObject.Scp
-	} else {
-		parentScope = e.Scp
+// runFunctionBody executes a function body in its call-site scope.
+//
+// With UseVM set, the body is compiled to bytecode (once per body) and run on
+// the VM; bodies the compiler cannot handle are tree-walked as usual.
+// Either way the result follows the tree walker's convention: a ReturnValue for
+// an explicit return, otherwise the value of the last statement.
+//
+// The call is recorded on the call stack under name, so that an error raised
+// in the body carries a traceback. The result is checked against the declared
+// return type of fn (see checkResult).
+//
+// Parameters:
+//   - fn: The called function
+//   - name: The name of the call in tracebacks (e.g., "area" or "Point.norm")
+//   - callSiteScope: The scope holding the bound parameters
+//
+// Returns:
+//   - std.GoMixObject: The raw body result (see above), or an Error
+func (e *Evaluator) runFunctionBody(fn *function.Function, name string, callSiteScope *scope.Scope) std.GoMixObject {
+	if fn.Generator {
+		return e.checkResult(fn, name, e.newGenerator(fn, name, callSiteScope))
+	}
+	if res := e.enterCall(); res != nil {
+		return res
 	}
-	callSiteScope := scope.NewScope(parentScope)
+	defer e.leaveCall()
+	e.pushFrame(name, fn.File)
+	return e.popFrame(e.checkResult(fn, name, e.runBody(fn.Body, callSiteScope)))
+}
 
-	for i, param := range functionObject.Params {
-		val := e.Eval(n.Arguments[i])
-		if IsError(val) {
-			return val
+// runBody runs a function body on the VM or the tree walker (see runFunctionBody).
+func (e *Evaluator) runBody(body *parser.BlockStatementNode, callSiteScope *scope.Scope) std.GoMixObject {
+	if e.UseVM {
+		if code := e.compileFunctionBody(body); code != nil {
+			return e.runBytecode(code, callSiteScope)
 		}
-		callSiteScope.Bind(param.Name, val)
 	}
 	oldScope := e.Scp
 	e.Scp = callSiteScope
-	result := e.Eval(functionObject.Body)
+	result := e.Eval(body)
 	e.Scp = oldScope
+	return result
+}
 
+// returnFromCall turns the raw result of a function body into the value of
+// the call expression.
+//
+// Parameters:
+//   - result: The result of runFunctionBody
+//   - callSiteScope: The scope the function body ran in
+//
+// Returns:
+//   - std.GoMixObject: The returned value
+func returnFromCall(result std.GoMixObject, callSiteScope *scope.Scope) std.GoMixObject {
 	// Unwrap return value if present
 	if retVal, isReturn := result.(*std.ReturnValue); isReturn {
 		returnVal := retVal.Value
@@ -162,7 +445,6 @@ func (e *Evaluator) evalCallExpression(n *parser.CallExpressionNode) std.GoMixOb
 		return returnVal
 	}
 	return result
-
 }
 
 // evalImportStatement evaluates an import statement to make a package available.
@@ -171,12 +453,30 @@ func (e *Evaluator) evalCallExpression(n *parser.CallExpressionNode) std.GoMixOb
 // 1. Looking up the package in the std.Packages registry
 // 2. Binding the package name to a special Package object in the current scope
 //
+// Names ending in ".gm" (e.g., import "lib/util.gm" as util;) are user modules:
+// they are loaded by importModule and bound under the alias, or under the file
+// name without its extension when no alias is given.
+//
 // Once imported, package functions can be called using the dot notation
 // (e.g., math.abs(), strings.upper(), etc.)
 //
 // Parameters:
 //   - n: An ImportStatementNode containing the package name to import
 func (e *Evaluator) evalImportStatement(n *parser.ImportStatementNode) std.GoMixObject {
+	// User module: load (or reuse) the module file and bind its namespace
+	if IsModuleImport(n.Name) {
+		pkg := e.importModule(n)
+		if IsError(pkg) {
+			return pkg
+		}
+		bindName := moduleBindName(n.Name)
+		if n.Alias != "" {
+			bindName = n.Alias
+		}
+		e.Scp.Bind(bindName, pkg)
+		return pkg
+	}
+
 	// Look up the package by name
 	pkg, exists := e.Imports[n.Name]
 	if !exists {
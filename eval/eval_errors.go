@@ -0,0 +1,133 @@
+/*
+File    : go-mix/eval/eval_errors.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"fmt"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/scope"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// evalTryStatement evaluates a try/catch/finally statement.
+//
+// This method runs the try block and, if it raises an error:
+// 1. Restores the scope that was active when the try statement started
+// 2. Binds the caught error (as an inspectable Error instance) to the catch parameter
+// 3. Runs the catch block in a new scope holding that binding
+//
+// The finally block always runs afterwards. If it raises an error, returns, breaks
+// or continues, that outcome replaces the outcome of the try/catch blocks; otherwise
+// the result of the try (or catch) block is propagated, including return values and
+// errors that were not caught.
+//
+// Parameters:
+//   - n: A TryStatementNode with the try block and optional catch and finally clauses
+//
+// Returns:
+//   - std.GoMixObject: The result of the try or catch block, or the error/return
+//     signal that should keep propagating
+//
+// Example:
+//
+//	try {
+//	    var n = to_int("abc");
+//	} catch (err) {
+//	    println(err.message, err.line, err.column);
+//	} finally {
+//	    println("done");
+//	}
+func (e *Evaluator) evalTryStatement(n *parser.TryStatementNode) std.GoMixObject {
+	savedScope := e.Scp
+
+	result := e.Eval(&n.TryBlock)
+	if IsLimitError(result) {
+		// Execution limits end the program; neither catch nor finally may run
+		e.Scp = savedScope
+		return result
+	}
+	if IsError(result) && n.CatchBlock != nil {
+		// Unwind any scopes left behind by the code that failed
+		e.Scp = savedScope
+
+		catchScope := scope.NewScope(savedScope)
+		if n.CatchParam != nil {
+			catchScope.Bind(n.CatchParam.Name, std.NewErrorInstance(result.(*std.Error)))
+		}
+		e.Scp = catchScope
+		result = e.Eval(n.CatchBlock)
+	}
+	e.Scp = savedScope
+
+	if n.FinallyBlock != nil {
+		finallyResult := e.Eval(n.FinallyBlock)
+		e.Scp = savedScope
+		if IsError(finallyResult) {
+			return finallyResult
+		}
+		switch finallyResult.GetType() {
+		case std.ReturnValueType, std.BreakType, std.ContinueType:
+			return finallyResult
+		}
+	}
+
+	return result
+}
+
+// evalThrowStatement evaluates a throw statement and raises an error.
+//
+// Throwing a caught error (an Error instance bound by a catch clause) re-raises it
+// with its original message and position. Any other value becomes a new error whose
+// message is the value's string form; the value itself is kept and is available
+// as err.value in a catch block.
+//
+// Parameters:
+//   - n: A ThrowStatementNode containing the expression to throw
+//
+// Returns:
+//   - std.GoMixObject: The raised Error object
+//
+// Example:
+//
+//	throw "invalid input";   // err.message == "invalid input"
+//	throw err;               // rethrow a caught error
+func (e *Evaluator) evalThrowStatement(n *parser.ThrowStatementNode) std.GoMixObject {
+	val := e.Eval(n.Expr)
+	if IsError(val) {
+		return val
+	}
+	if err, ok := std.ErrorFromInstance(val); ok {
+		return err
+	}
+	return &std.Error{
+		Message: fmt.Sprintf("[%d:%d] ERROR: %s", n.Token.Line, n.Token.Column, val.ToString()),
+		Line:    n.Token.Line,
+		Column:  n.Token.Column,
+		Value:   val,
+	}
+}
+
+// withPosition records the position of a call on an error raised by a builtin.
+//
+// Builtins create errors without any source position; this fills in the line and
+// column of the call so that a catch block can report where the error came from.
+// The error message is left unchanged. Non-error values are returned as-is.
+//
+// Parameters:
+//   - obj: The result of a builtin call
+//   - token: The token of the called function's name
+//
+// Returns:
+//   - std.GoMixObject: obj, with its position set if it is an error without one
+func (e *Evaluator) withPosition(obj std.GoMixObject, token lexer.Token) std.GoMixObject {
+	if err, ok := obj.(*std.Error); ok && err.Line == 0 {
+		err.Line = token.Line
+		err.Column = token.Column
+	}
+	return obj
+}
@@ -6,6 +6,10 @@ Contact : akashmaji(@iisc.ac.in)
 package eval
 
 import (
+	"math"
+	"math/big"
+	"strings"
+
 	"github.com/akashmaji946/go-mix/lexer"
 	"github.com/akashmaji946/go-mix/parser"
 	"github.com/akashmaji946/go-mix/std"
@@ -24,7 +28,8 @@ import (
 // - Array operations: Handle array literals, indexing, and slicing
 //
 // The evaluation process is recursive - complex expressions are broken down into
-// simpler sub-expressions that are evaluated in turn.
+// simpler sub-expressions that are evaluated in turn. Every node evaluated counts
+// as one step towards the evaluator's Limits (see eval_limits.go).
 //
 // Parameters:
 //   - n: The AST node to evaluate (can be any type implementing parser.Node)
@@ -38,10 +43,19 @@ import (
 //
 //	RootNode -> evalStatements -> Eval(each statement) -> specific eval methods
 func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
+	if root, ok := n.(*parser.RootNode); ok {
+		// A program starts a new run under the evaluator's limits
+		return e.RunLimited(func() std.GoMixObject {
+			if e.UseVM {
+				return e.runProgram(root)
+			}
+			return UnwrapReturnValue(e.evalStatements(root.Statements))
+		})
+	}
+	if res := e.step(); res != nil {
+		return res
+	}
 	switch n := n.(type) {
-	case *parser.RootNode:
-		result := e.evalStatements(n.Statements)
-		return UnwrapReturnValue(result)
 	case *parser.BooleanLiteralExpressionNode:
 		return n.Value
 	case *parser.IntegerLiteralExpressionNode:
@@ -50,6 +64,8 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 		return n.Value
 	case *parser.StringLiteralExpressionNode:
 		return n.Value
+	case *parser.InterpolatedStringExpressionNode:
+		return e.evalInterpolatedString(n)
 	case *parser.FloatLiteralExpressionNode:
 		return n.Value
 	case *parser.NilLiteralExpressionNode:
@@ -57,16 +73,20 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 	case *parser.BinaryExpressionNode:
 		return e.evalBinaryExpression(n)
 	case *parser.UnaryExpressionNode:
+		e.at(n.Operation)
 		return e.evalUnaryExpression(n)
 	case *parser.BooleanExpressionNode:
 		return e.evalBooleanExpression(n)
 	case *parser.ParenthesizedExpressionNode:
 		return e.Eval(n.Expr)
 	case *parser.IfExpressionNode:
+		e.at(n.IfToken)
 		return e.evalIfExpression(n)
 	case *parser.DeclarativeStatementNode:
+		e.at(n.VarToken)
 		return e.evalDeclarativeStatement(n)
 	case *parser.ReturnStatementNode:
+		e.at(n.ReturnToken)
 		return e.evalReturnStatement(n)
 	case *parser.BlockStatementNode:
 		return e.evalBlockStatement(n)
@@ -75,12 +95,16 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 	case *parser.FunctionStatementNode:
 		return e.RegisterFunction(n)
 	case *parser.CallExpressionNode:
+		e.at(callToken(n))
 		return e.evalCallExpression(n)
 	case *parser.AssignmentExpressionNode:
+		e.at(n.Operation)
 		return e.evalAssignmentExpression(n)
 	case *parser.ForLoopStatementNode:
+		e.at(n.ForToken)
 		return e.evalForLoop(n)
 	case *parser.WhileLoopStatementNode:
+		e.at(n.WhileToken)
 		return e.evalWhileLoop(n)
 	case *parser.ArrayExpressionNode:
 		return e.evalArrayExpression(n)
@@ -88,6 +112,8 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 		return e.evalMapExpression(n)
 	case *parser.SetExpressionNode:
 		return e.evalSetExpression(n)
+	case *parser.TupleExpressionNode:
+		return e.evalTupleExpression(n)
 	case *parser.IndexExpressionNode:
 		return e.evalIndexExpression(n)
 	case *parser.SliceExpressionNode:
@@ -95,10 +121,16 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 	case *parser.RangeExpressionNode:
 		return e.evalRangeExpression(n)
 	case *parser.ForeachLoopStatementNode:
+		e.at(n.ForeachToken)
 		return e.evalForeachLoop(n)
 	case *parser.StructDeclarationNode:
 		return e.evalStructDeclaration(n)
+	case *parser.InterfaceDeclarationNode:
+		return e.evalInterfaceDeclaration(n)
+	case *parser.SuperExpressionNode:
+		return e.createError(n.Token, "ERROR: super must be followed by a member access (super.method)")
 	case *parser.NewCallExpressionNode:
+		e.at(n.NewToken)
 		return e.evalNewCallExpression(n)
 	case *parser.BreakStatementNode:
 		return &std.Break{}
@@ -112,6 +144,20 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 		return e.evalEnumAccessExpression(n)
 	case *parser.SwitchStatementNode:
 		return e.evalSwitchStatement(*n)
+	case *parser.MatchExpressionNode:
+		e.at(n.Token)
+		return e.evalMatchExpression(n)
+	case *parser.TryStatementNode:
+		return e.evalTryStatement(n)
+	case *parser.ThrowStatementNode:
+		e.at(n.Token)
+		return e.evalThrowStatement(n)
+	case *parser.SpawnStatementNode:
+		e.at(n.Token)
+		return e.evalSpawnStatement(n)
+	case *parser.YieldStatementNode:
+		e.at(n.Token)
+		return e.evalYieldStatement(n)
 	default:
 		return &std.Nil{}
 	}
@@ -120,8 +166,12 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 // evaluateBinaryOp performs the actual computation for binary operations.
 //
 // This helper method handles arithmetic (+, -, *, /, %), bitwise (&, |, ^, <<, >>),
-// and string concatenation operations. It performs type checking and promotion
-// (int vs float) before executing the operation.
+// and string and bytes concatenation operations (a string and bytes do not
+// concatenate). It performs type checking and promotion
+// (int vs float) before executing the operation. A struct instance on the left
+// of an arithmetic operator calls its protocol method (__add__, __sub__, ...).
+// An int result that overflows is computed again as a bigint, and operands
+// that are bigints or decimals use the arithmetic of std/bignum.go.
 //
 // Parameters:
 //   - token: The operator token (for error reporting)
@@ -134,19 +184,62 @@ func (e *Evaluator) Eval(n parser.Node) std.GoMixObject {
 func (e *Evaluator) evaluateBinaryOp(token lexer.Token, opType lexer.TokenType, left, right std.GoMixObject) std.GoMixObject {
 	err := e.createError(token, "ERROR: operator (%s) not implemented for (%s) and (%s)", token.Literal, left.GetType(), right.GetType())
 
+	// Struct instances overload the operators with protocol methods
+	if res, ok := e.arithmeticProtocol(opType, left, right); ok {
+		return res
+	}
+
+	// Bytes and strings do not mix: bytes("a") + "b" is an error, not the
+	// concatenation of the printed bytes
+	if (left.GetType() == std.BytesType && right.GetType() == std.StringType) ||
+		(left.GetType() == std.StringType && right.GetType() == std.BytesType) {
+		return err
+	}
+
 	if opType == lexer.PLUS_OP {
 		if left.GetType() == std.StringType || right.GetType() == std.StringType {
-			return &std.String{Value: left.ToString() + right.ToString()}
+			leftStr, errObj := std.Stringify(e, left)
+			if errObj != nil {
+				return errObj
+			}
+			rightStr, errObj := std.Stringify(e, right)
+			if errObj != nil {
+				return errObj
+			}
+			res := &std.String{Value: leftStr + rightStr}
+			e.charge(res)
+			return res
 		}
 	}
 
 	if left.GetType() == std.StringType && right.GetType() == std.StringType {
 		if opType == lexer.PLUS_OP {
-			return &std.String{Value: left.(*std.String).Value + right.(*std.String).Value}
+			res := &std.String{Value: left.(*std.String).Value + right.(*std.String).Value}
+			e.charge(res)
+			return res
 		}
 		return err
 	}
 
+	if left.GetType() == std.BytesType && right.GetType() == std.BytesType {
+		if opType == lexer.PLUS_OP {
+			leftBytes, rightBytes := left.(*std.Bytes).Value, right.(*std.Bytes).Value
+			data := make([]byte, 0, len(leftBytes)+len(rightBytes))
+			res := &std.Bytes{Value: append(append(data, leftBytes...), rightBytes...)}
+			e.charge(res)
+			return res
+		}
+		return err
+	}
+
+	if res, ok := std.BigArithmetic(string(opType), left, right); ok {
+		if errObj, isErr := res.(*std.Error); isErr {
+			return e.createError(token, "%s", errObj.Message)
+		}
+		e.charge(res)
+		return res
+	}
+
 	if left.GetType() != std.IntegerType && left.GetType() != std.FloatType {
 		return err
 	}
@@ -157,6 +250,12 @@ func (e *Evaluator) evaluateBinaryOp(token lexer.Token, opType lexer.TokenType,
 	leftType := left.GetType()
 	rightType := right.GetType()
 
+	// An int operation that overflows gives a bigint
+	if leftType == std.IntegerType && rightType == std.IntegerType &&
+		std.IntOverflows(string(opType), left.(*std.Integer).Value, right.(*std.Integer).Value) {
+		return e.evaluateBinaryOp(token, opType, std.NewBigInt(left.(*std.Integer).Value), right)
+	}
+
 	switch opType {
 	case lexer.PLUS_OP:
 		if leftType == std.IntegerType && rightType == std.IntegerType {
@@ -175,11 +274,17 @@ func (e *Evaluator) evaluateBinaryOp(token lexer.Token, opType lexer.TokenType,
 		return &std.Float{Value: toFloat64(left) * toFloat64(right)}
 	case lexer.DIV_OP:
 		if leftType == std.IntegerType && rightType == std.IntegerType {
+			if right.(*std.Integer).Value == 0 {
+				return e.createError(token, "ERROR: division by zero")
+			}
 			return &std.Integer{Value: left.(*std.Integer).Value / right.(*std.Integer).Value}
 		}
 		return &std.Float{Value: toFloat64(left) / toFloat64(right)}
 	case lexer.MOD_OP:
 		if leftType == std.IntegerType && rightType == std.IntegerType {
+			if right.(*std.Integer).Value == 0 {
+				return e.createError(token, "ERROR: division by zero")
+			}
 			return &std.Integer{Value: left.(*std.Integer).Value % right.(*std.Integer).Value}
 		}
 		return err
@@ -217,7 +322,8 @@ func (e *Evaluator) evaluateBinaryOp(token lexer.Token, opType lexer.TokenType,
 // This method performs variable lookup by searching through the scope hierarchy:
 // 1. Checks the current scope for the identifier
 // 2. If not found, recursively searches parent scopes
-// 3. Returns the bound value if found, or an error if not found
+// 3. Falls back to the builtin of that name, so builtins can be used as values
+// 4. Returns the bound value if found, or an error if not found
 //
 // The scope chain lookup enables lexical scoping and closures, allowing inner
 // functions to access variables from outer scopes.
@@ -238,6 +344,10 @@ func (e *Evaluator) evalIdentifierExpression(n *parser.IdentifierExpressionNode)
 
 	val, ok := e.Scp.LookUp(n.Name)
 	if !ok {
+		// Builtins are values too (e.g., map_array(words, upper))
+		if builtin, ok := e.Builtins[n.Name]; ok {
+			return builtin
+		}
 		return e.createError(n.Token, "ERROR: identifier not found: (%s)", n.Name)
 	}
 	return val
@@ -282,6 +392,12 @@ func (e *Evaluator) evalIdentifierExpression(n *parser.IdentifierExpressionNode)
 //	10 % 3     // Returns Integer(1)
 //	5 & 3      // Returns Integer(1) - bitwise AND
 func (e *Evaluator) evalBinaryExpression(n *parser.BinaryExpressionNode) std.GoMixObject {
+	if super, ok := n.Left.(*parser.SuperExpressionNode); ok {
+		if ident, ok := n.Right.(*parser.IdentifierExpressionNode); ok && n.Operation.Type == lexer.DOT_OP {
+			e.at(n.Operation)
+			return e.evalSuperMember(super, ident)
+		}
+	}
 	left := e.Eval(n.Left)
 
 	if IsError(left) {
@@ -290,81 +406,75 @@ func (e *Evaluator) evalBinaryExpression(n *parser.BinaryExpressionNode) std.GoM
 
 	// we prioritize the dot (.) member access operator in the parser,
 	if n.Operation.Type == lexer.DOT_OP {
+		return e.evalMember(n, left)
+	}
 
-		if left.GetType() == std.StructType {
-			return e.evalStructMemberAccess(left.(*std.GoMixStruct), n.Right)
-		}
+	right := e.Eval(n.Right)
+	if IsError(right) {
+		return right
+	}
 
-		// Handle enum member access (e.g., Color.RED)
-		if left.GetType() == std.EnumType {
-			enumType := left.(*std.GoMixEnum)
-			ident, ok := n.Right.(*parser.IdentifierExpressionNode)
-			if !ok {
-				return e.CreateError("ERROR: enum member access must be an identifier")
-			}
-			memberValue, exists := enumType.Members[ident.Name]
-			if !exists {
-				return e.CreateError("ERROR: enum member '%s' not found in enum '%s'", ident.Name, enumType.Name)
-			}
-			return memberValue
-		}
-
-		// Handle package member access (e.g., math.abs or math.abs(...))
-		if left.GetType() == std.PackageType {
-			pkg := left.(*std.Package)
-
-			// If the right side is a call expression, invoke the package function
-			if callNode, ok := n.Right.(*parser.CallExpressionNode); ok {
-				funcName := callNode.FunctionIdentifier.Name
-				fn, exists := pkg.Functions[funcName]
-				if !exists {
-					return e.createError(callNode.FunctionIdentifier.Token, "ERROR: function '%s' not found in package '%s'", funcName, pkg.Name)
-				}
-
-				// Evaluate arguments
-				args := make([]std.GoMixObject, len(callNode.Arguments))
-				for i, arg := range callNode.Arguments {
-					args[i] = e.Eval(arg)
-					if IsError(args[i]) {
-						return args[i]
-					}
-				}
-
-				// Call the package function
-				return fn.Callback(e, e.Writer, args...)
-			}
+	e.at(n.Operation)
+	return e.evaluateBinaryOp(n.Operation, n.Operation.Type, left, right)
+}
 
-			// For non-call access, just validate the function exists
-			return e.evalPackageMemberAccess(pkg, n.Right)
-		}
+// evalMember evaluates member access (the dot operator) on an evaluated left operand.
+//
+// The members of struct types are static fields, those of enums are enum
+// members, those of packages are functions or module members, and those of
+// struct instances are fields or methods (bound to the instance).
+//
+// Parameters:
+//   - n: The BinaryExpressionNode of the dot operator
+//   - left: The value of the left operand
+//
+// Returns:
+//   - std.GoMixObject: The member value, or an Error if there is no such member
+func (e *Evaluator) evalMember(n *parser.BinaryExpressionNode, left std.GoMixObject) std.GoMixObject {
+	e.at(n.Operation)
 
-		if left.GetType() != std.ObjectType {
-			return e.CreateError("ERROR: member access operator (.) can only be used on struct instances, packages, or types, got (%s)", left.GetType())
-		}
-		structInstance := left.(*std.GoMixObjectInstance)
+	if left.GetType() == std.StructType {
+		return e.evalStructMemberAccess(left.(*std.GoMixStruct), n.Right)
+	}
 
-		// Handle Index Access on Field/Method (e.g. this.q[0])
-		if indexNode, ok := n.Right.(*parser.IndexExpressionNode); ok {
-			container := e.evalMemberAccess(structInstance, indexNode.Left)
-			if IsError(container) {
-				return container
-			}
-			index := e.Eval(indexNode.Index)
-			if IsError(index) {
-				return index
-			}
-			return e.getIndexValue(container, index)
-		}
+	// Handle enum member access (e.g., Color.RED)
+	if left.GetType() == std.EnumType {
+		enumType := left.(*std.GoMixEnum)
+		ident, ok := n.Right.(*parser.IdentifierExpressionNode)
+		if !ok {
+			return e.CreateError("ERROR: enum member access must be an identifier")
+		}
+		memberValue, exists := enumType.Members[ident.Name]
+		if !exists {
+			return e.CreateError("ERROR: enum member '%s' not found in enum '%s'", ident.Name, enumType.Name)
+		}
+		return memberValue
+	}
 
-		return e.evalMemberAccess(structInstance, n.Right)
+	// Handle package member access (e.g., math.abs)
+	if left.GetType() == std.PackageType {
+		return e.evalPackageMemberAccess(left.(*std.Package), n.Right)
 	}
 
-	right := e.Eval(n.Right)
-	if IsError(right) {
-		return right
+	if left.GetType() != std.ObjectType {
+		return e.CreateError("ERROR: member access operator (.) can only be used on struct instances, packages, or types, got (%s)", left.GetType())
 	}
+	structInstance := left.(*std.GoMixObjectInstance)
 
-	return e.evaluateBinaryOp(n.Operation, n.Operation.Type, left, right)
+	// Handle Index Access on Field/Method (e.g. this.q[0])
+	if indexNode, ok := n.Right.(*parser.IndexExpressionNode); ok {
+		container := e.evalMemberAccess(structInstance, indexNode.Left)
+		if IsError(container) {
+			return container
+		}
+		index := e.Eval(indexNode.Index)
+		if IsError(index) {
+			return index
+		}
+		return e.getIndexValue(container, index)
+	}
+
+	return e.evalMemberAccess(structInstance, n.Right)
 }
 
 // evalUnaryExpression evaluates unary prefix operations on a single operand.
@@ -404,10 +514,21 @@ func (e *Evaluator) evalUnaryExpression(n *parser.UnaryExpressionNode) std.GoMix
 	if IsError(right) {
 		return right
 	}
+	return e.unaryOp(n.Operation, right)
+}
 
-	err := e.createError(n.Operation, "ERROR: operator (%s) not implemented for (%s)", n.Operation.Literal, right.GetType())
+// unaryOp applies a prefix operator (!, ~, -, +) to an evaluated operand.
+//
+// Parameters:
+//   - op: The operator token (used for the operator type and error position)
+//   - right: The evaluated operand
+//
+// Returns:
+//   - std.GoMixObject: The result, or an Error if the operator does not apply to the operand
+func (e *Evaluator) unaryOp(op lexer.Token, right std.GoMixObject) std.GoMixObject {
+	err := e.createError(op, "ERROR: operator (%s) not implemented for (%s)", op.Literal, right.GetType())
 
-	switch n.Operation.Type {
+	switch op.Type {
 	case lexer.NOT_OP:
 		if right.GetType() != std.BooleanType {
 			return err
@@ -416,13 +537,21 @@ func (e *Evaluator) evalUnaryExpression(n *parser.UnaryExpressionNode) std.GoMix
 	case lexer.BIT_NOT_OP:
 		if right.GetType() == std.IntegerType {
 			return &std.Integer{Value: ^right.(*std.Integer).Value}
+		} else if right.GetType() == std.BigIntType {
+			return &std.BigInt{Value: new(big.Int).Not(right.(*std.BigInt).Value)}
 		}
 		return err
 	case lexer.MINUS_OP:
 		if right.GetType() == std.IntegerType {
+			// -MinInt64 does not fit in an int: promote it like binary overflow
+			if val := right.(*std.Integer).Value; val == math.MinInt64 {
+				return &std.BigInt{Value: new(big.Int).Neg(big.NewInt(val))}
+			}
 			return &std.Integer{Value: -right.(*std.Integer).Value}
 		} else if right.GetType() == std.FloatType {
 			return &std.Float{Value: -right.(*std.Float).Value}
+		} else if res, ok := std.NegateNumber(right); ok {
+			return res
 		}
 		return err
 	case lexer.PLUS_OP:
@@ -430,6 +559,8 @@ func (e *Evaluator) evalUnaryExpression(n *parser.UnaryExpressionNode) std.GoMix
 			return right
 		} else if right.GetType() == std.FloatType {
 			return right
+		} else if std.IsBigNumber(right) {
+			return right
 		}
 		return err
 	}
@@ -527,7 +658,50 @@ func (e *Evaluator) evalBooleanExpression(n *parser.BooleanExpressionNode) std.G
 	if IsError(right) {
 		return right
 	}
-	switch n.Operation.Type {
+	return e.compareValues(n.Operation, left, right)
+}
+
+// compareValues applies an equality or relational operator (==, !=, ===, !==,
+// <, >, <=, >=, instanceof) to two evaluated operands.
+//
+// Parameters:
+//   - op: The comparison operator token
+//   - left: The evaluated left operand
+//   - right: The evaluated right operand
+//
+// Returns:
+//   - std.GoMixObject: A Boolean with the comparison result (Nil for unknown
+//     operators), or an Error if the right operand of instanceof is not a type
+//     or a protocol method (__eq__, __lt__) failed
+func (e *Evaluator) compareValues(op lexer.Token, left, right std.GoMixObject) std.GoMixObject {
+	// Struct instances overload the comparisons with __eq__ and __lt__
+	if res, ok := e.equalProtocol(op, left, right); ok {
+		return res
+	}
+	if res, ok := e.compareProtocol(op, left, right); ok {
+		return res
+	}
+
+	// Bigints and decimals compare exactly with each other and with ints
+	// and floats (decimal("1.50") == 1.5)
+	if c, ok := std.CompareNumbers(left, right); ok {
+		switch op.Type {
+		case lexer.EQ_OP:
+			return &std.Boolean{Value: c == 0}
+		case lexer.NE_OP:
+			return &std.Boolean{Value: c != 0}
+		case lexer.GT_OP:
+			return &std.Boolean{Value: c > 0}
+		case lexer.LT_OP:
+			return &std.Boolean{Value: c < 0}
+		case lexer.GE_OP:
+			return &std.Boolean{Value: c >= 0}
+		case lexer.LE_OP:
+			return &std.Boolean{Value: c <= 0}
+		}
+	}
+
+	switch op.Type {
 	case lexer.EQ_OP:
 		return &std.Boolean{Value: left.ToString() == right.ToString()}
 	case lexer.NE_OP:
@@ -536,6 +710,8 @@ func (e *Evaluator) evalBooleanExpression(n *parser.BooleanExpressionNode) std.G
 		return &std.Boolean{Value: StrictEqual(left, right)}
 	case lexer.STRICT_NE_OP:
 		return &std.Boolean{Value: !StrictEqual(left, right)}
+	case lexer.INSTANCEOF_KEY:
+		return e.instanceOf(left, right)
 	case lexer.GT_OP:
 		if left.GetType() == std.IntegerType && right.GetType() == std.IntegerType {
 			return &std.Boolean{Value: left.(*std.Integer).Value > right.(*std.Integer).Value}
@@ -559,3 +735,46 @@ func (e *Evaluator) evalBooleanExpression(n *parser.BooleanExpressionNode) std.G
 	}
 	return &std.Nil{}
 }
+
+// evalInterpolatedString evaluates an interpolated string.
+//
+// Each embedded expression is evaluated in order and converted to a string
+// the way the + operator converts the operand of a string concatenation.
+//
+// Parameters:
+//   - n: The InterpolatedStringExpressionNode
+//
+// Returns:
+//   - objects.GoMixObject: The resulting String, or the first Error
+func (e *Evaluator) evalInterpolatedString(n *parser.InterpolatedStringExpressionNode) std.GoMixObject {
+	parts := make([]std.GoMixObject, 0, len(n.Texts)+len(n.Exprs))
+	for i, text := range n.Texts {
+		if text != "" {
+			parts = append(parts, &std.String{Value: text})
+		}
+		if i < len(n.Exprs) {
+			val := e.Eval(n.Exprs[i])
+			if IsError(val) {
+				return val
+			}
+			parts = append(parts, val)
+		}
+	}
+	return e.interpolate(parts)
+}
+
+// interpolate joins the values of the parts of an interpolated string:
+// its text and the values of its embedded expressions.
+func (e *Evaluator) interpolate(parts []std.GoMixObject) std.GoMixObject {
+	var b strings.Builder
+	for _, part := range parts {
+		str, err := std.Stringify(e, part)
+		if err != nil {
+			return err
+		}
+		b.WriteString(str)
+	}
+	res := &std.String{Value: b.String()}
+	e.charge(res)
+	return res
+}
@@ -0,0 +1,162 @@
+/*
+File    : go-mix/eval/eval_generators.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package eval - eval_generators.go
+// This file implements generator functions: functions whose body has a
+// yield statement. Calling one binds the arguments and returns a generator
+// (see std/generator.go) without running the body; the body runs as a
+// coroutine, up to the next yield each time the generator is asked for a
+// value.
+package eval
+
+import (
+	"runtime"
+
+	"github.com/akashmaji946/go-mix/function"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/scope"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// coroutine runs the body of a generator function on a Go goroutine of its
+// own, in turn with the code iterating over the generator: the consumer waits
+// while the body runs, and the body waits (in a yield statement) while the
+// consumer runs. The body therefore runs within the consumer's turn (see
+// scheduler), on an evaluator of its own whose call stack starts at the
+// generator function.
+type coroutine struct {
+	ev       *Evaluator             // The evaluator running the body
+	run      func() std.GoMixObject // Runs the body
+	resume   chan struct{}          // Asks the body for the next value; closed to abandon it
+	values   chan std.GoMixObject   // The yielded values; closed when the body ends
+	err      std.GoMixObject        // The error that ended the body, if any
+	started  bool
+	finished bool // Whether the body has ended
+	closing  bool // Whether the generator was closed: yield statements return
+}
+
+// newGenerator creates the generator returned by a call to a generator
+// function, whose arguments are bound in callSiteScope.
+//
+// Closing the generator (see std.Generator.Close) makes a body suspended in
+// a yield statement return, running its finally blocks. A generator that is
+// no longer reachable without having been closed is abandoned once it is
+// garbage collected: its goroutine ends without running any more Go-Mix code
+// (not even finally blocks).
+//
+// Parameters:
+//   - fn: The called generator function
+//   - name: The name of the call in tracebacks (see functionFrameName)
+//   - callSiteScope: The scope holding the bound parameters
+//
+// Returns:
+//   - *std.Generator: The generator running the body of fn
+func (e *Evaluator) newGenerator(fn *function.Function, name string, callSiteScope *scope.Scope) *std.Generator {
+	child := *e
+	// The body only sees its own scope chain: the caller's scope may hold the
+	// generator, which would keep an abandoned generator reachable from its
+	// own goroutine (so that it would never be collected)
+	child.Scp = callSiteScope
+	line, column := e.position()
+	child.trace = &callStack{root: "<generator>", file: e.File, line: line, column: column, depth: e.trace.depth}
+	co := &coroutine{
+		ev:     &child,
+		resume: make(chan struct{}),
+		values: make(chan std.GoMixObject),
+	}
+	child.gen = co
+	co.run = func() std.GoMixObject {
+		if res := child.enterCall(); res != nil {
+			return res
+		}
+		defer child.leaveCall()
+		child.pushFrame(name, fn.File)
+		return child.popFrame(child.runBody(fn.Body, callSiteScope))
+	}
+
+	gen := std.NewGenerator(name, co.next)
+	gen.OnClose(co.close)
+	runtime.SetFinalizer(gen, func(*std.Generator) { close(co.resume) })
+	return gen
+}
+
+// close ends the body early: a body suspended in a yield statement returns
+// from the generator function as if the yield were a return statement, and
+// so does any yield reached while it unwinds. It waits until the body ends.
+func (co *coroutine) close() {
+	if !co.started || co.finished {
+		return
+	}
+	co.closing = true
+	co.resume <- struct{}{}
+	for range co.values {
+	}
+	co.finished = true
+}
+
+// next runs the body up to its next yield statement and returns the value
+// it yielded, or nil once the body has ended (with the error that ended it,
+// if any).
+func (co *coroutine) next(rt std.Runtime) (std.GoMixObject, std.GoMixObject) {
+	if !co.started {
+		co.started = true
+		go co.start()
+	} else {
+		co.resume <- struct{}{}
+	}
+	value, ok := <-co.values
+	if !ok {
+		co.finished = true
+		return nil, co.err
+	}
+	return value, nil
+}
+
+// start runs the body on the coroutine's goroutine.
+func (co *coroutine) start() {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			co.err = co.ev.CreateError("ERROR: generator panicked: %v", recovered)
+		}
+		close(co.values)
+	}()
+	if res := co.run(); IsError(res) || IsLimitError(res) {
+		co.err = res
+	}
+}
+
+// evalYieldStatement evaluates a yield statement: it hands the value to the
+// code iterating over the generator and waits until the next value is asked
+// for.
+//
+// Parameters:
+//   - n: A YieldStatementNode holding the yielded expression
+//
+// Returns:
+//   - std.GoMixObject: Nil once resumed, a ReturnValue if the generator was
+//     closed, or an Error if the expression fails
+func (e *Evaluator) evalYieldStatement(n *parser.YieldStatementNode) std.GoMixObject {
+	if e.gen == nil {
+		return e.CreateError("ERROR: yield outside a generator")
+	}
+	value := e.Eval(n.Expr)
+	if IsError(value) {
+		return value
+	}
+	if !e.gen.closing {
+		e.gen.values <- value
+		if _, ok := <-e.gen.resume; !ok {
+			// The generator was abandoned: end the goroutine without running
+			// the rest of the body
+			runtime.Goexit()
+		}
+	}
+	if e.gen.closing {
+		// The generator was closed: return from the body
+		return &std.ReturnValue{Value: &std.Nil{}}
+	}
+	return &std.Nil{}
+}
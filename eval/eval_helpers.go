@@ -12,22 +12,49 @@ import (
 	"github.com/akashmaji946/go-mix/std"
 )
 
-// NamedParameter represents a parameter passed to a function or method call.
+// NamedParameter represents an argument passed to a function or method call.
 //
-// It encapsulates both the parameter name (from the function definition) and
-// the evaluated value passed as an argument. This structure is primarily used
-// during method invocation on objects to bind argument values to parameter names
-// in the method's execution scope.
+// It encapsulates both the parameter name (given at the call site, as in
+// f(b: 2)) and the evaluated value passed as an argument. Positional arguments
+// have no name; they come before the named ones and fill the parameters in
+// order (see bindArguments).
 //
 // Fields:
-//   - Name: The name of the parameter as defined in the function signature.
-//     Used for binding the value in the function's scope.
+//   - Name: The name of the parameter the argument is passed to, or "" for a
+//     positional argument.
 //   - Value: The evaluated runtime object passed as an argument.
 type NamedParameter struct {
-	Name  string          // The name of the parameter (e.g., "a", "b")
+	Name  string          // The name of the parameter (e.g., "a", "b"), or "" if positional
 	Value std.GoMixObject //  The value of the parameter, which can be any GoMixObject (e.g., Integer, String, Array)
 }
 
+// namedParameters pairs the evaluated arguments of a call with their names.
+//
+// Parameters:
+//   - args: The evaluated arguments
+//   - names: The names of the arguments ("" for positional ones), or nil if none is named
+func namedParameters(args []std.GoMixObject, names []string) []NamedParameter {
+	params := make([]NamedParameter, len(args))
+	for i, arg := range args {
+		params[i].Value = arg
+		if names != nil {
+			params[i].Name = names[i]
+		}
+	}
+	return params
+}
+
+// positionalCount returns the number of positional arguments among the argc
+// arguments of a call, whose names are given (nil if none is named).
+func positionalCount(argc int, names []string) int {
+	for i, name := range names {
+		if name != "" {
+			return i
+		}
+	}
+	return argc
+}
+
 // IsError checks if a GoMixObject represents an error condition.
 //
 // This helper function is used throughout the evaluator to detect error objects
@@ -299,12 +326,8 @@ func IndexOfDot(s string) int {
 
 // switchToFloat64 converts a GoMixObject to float64 for numeric comparisons.
 func switchToFloat64(obj std.GoMixObject) float64 {
-	if obj.GetType() == std.IntegerType {
-		return float64(obj.(*std.Integer).Value)
-	} else if obj.GetType() == std.FloatType {
-		return obj.(*std.Float).Value
-	}
-	return 0
+	f, _ := std.ToFloat64(obj)
+	return f
 }
 
 // StrictEqual checks if two objects are strictly equal (same value and type, or same reference).
@@ -317,10 +340,15 @@ func StrictEqual(a, b std.GoMixObject) bool {
 		return a.(*std.Integer).Value == b.(*std.Integer).Value
 	case std.FloatType:
 		return a.(*std.Float).Value == b.(*std.Float).Value
+	case std.BigIntType, std.DecimalType:
+		c, _ := std.CompareNumbers(a, b)
+		return c == 0
 	case std.BooleanType:
 		return a.(*std.Boolean).Value == b.(*std.Boolean).Value
 	case std.StringType:
 		return a.(*std.String).Value == b.(*std.String).Value
+	case std.BytesType:
+		return string(a.(*std.Bytes).Value) == string(b.(*std.Bytes).Value)
 	case std.CharType:
 		return a.(*std.Char).Value == b.(*std.Char).Value
 	case std.NilType:
@@ -346,6 +374,10 @@ func switchValuesEqual(left, right std.GoMixObject) bool {
 		return false
 	}
 
+	if c, ok := std.CompareNumbers(left, right); ok {
+		return c == 0
+	}
+
 	// Same type comparison
 	if left.GetType() != right.GetType() {
 		// Allow int/float comparison
@@ -395,5 +427,9 @@ func toFloat64(obj std.GoMixObject) float64 {
 	if obj.GetType() == std.IntegerType {
 		return float64(obj.(*std.Integer).Value)
 	}
+	if std.IsBigNumber(obj) {
+		f, _ := std.ToFloat64(obj)
+		return f
+	}
 	return obj.(*std.Float).Value
 }
@@ -0,0 +1,271 @@
+/*
+File    : go-mix/eval/eval_limits.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// Limits configures the execution limits of an evaluator.
+//
+// Limits apply to one program at a time: the step count, the allocation
+// budget and the timeout start afresh every time a program (a file, a REPL
+// input or a host call) starts running. Modules imported by a program count
+// towards the limits of that program.
+//
+// Fields:
+//   - MaxSteps: Maximum number of evaluation steps (0 for no limit). A step is
+//     one AST node for the tree walker and one instruction for the VM
+//   - MaxCallDepth: Maximum depth of nested function calls; 0 selects
+//     DefaultMaxCallDepth and a negative value disables the limit
+//   - Timeout: Wall-clock time a program may run (0 for no limit)
+//   - MaxAlloc: Approximate number of bytes of strings and collections a
+//     program may create over its lifetime (0 for no limit)
+type Limits struct {
+	MaxSteps     int64
+	MaxCallDepth int
+	Timeout      time.Duration
+	MaxAlloc     int64
+}
+
+// DefaultMaxCallDepth bounds recursion when no explicit call depth limit is
+// set, so that runaway recursion stops with an error instead of overflowing
+// the Go stack.
+const DefaultMaxCallDepth = 10000
+
+// DefaultLimits are the limits of every new evaluator (set by the CLI flags).
+var DefaultLimits Limits
+
+// limitCheckInterval is the number of steps between two checks of the
+// context, the deadline and the allocation budget.
+const limitCheckInterval = 1024
+
+// limitState tracks the resource usage of the running program. It is shared
+// by an evaluator and the evaluators of the modules it imports.
+type limitState struct {
+	limits   Limits
+	maxDepth int             // Effective call depth limit (0 for no limit)
+	ctx      context.Context // Cancellation context (nil for none)
+	deadline time.Time       // End of the timeout (zero for none)
+	steps    int64           // Steps taken by the running program
+	checkAt  int64           // Step count at which the limits are checked next
+	allocs   int64           // Approximate bytes allocated by the running program
+	running  int             // Nesting of RunLimited calls
+}
+
+// newLimitState creates the limit state of a new evaluator.
+func newLimitState(limits Limits) *limitState {
+	l := &limitState{}
+	l.set(limits)
+	return l
+}
+
+// set installs new limits.
+func (l *limitState) set(limits Limits) {
+	l.limits = limits
+	switch {
+	case limits.MaxCallDepth == 0:
+		l.maxDepth = DefaultMaxCallDepth
+	case limits.MaxCallDepth < 0:
+		l.maxDepth = 0
+	default:
+		l.maxDepth = limits.MaxCallDepth
+	}
+}
+
+// SetLimits configures the execution limits of the evaluator (see Limits).
+//
+// Parameters:
+//   - limits: The new limits
+//
+// Example:
+//
+//	ev.SetLimits(eval.Limits{MaxSteps: 1_000_000, Timeout: 2 * time.Second})
+func (e *Evaluator) SetLimits(limits Limits) {
+	e.limits.set(limits)
+}
+
+// GetLimits returns the execution limits of the evaluator.
+func (e *Evaluator) GetLimits() Limits {
+	return e.limits.limits
+}
+
+// SetContext attaches a context to the evaluator; the running program stops
+// with a LimitError shortly after the context is canceled or its deadline passes.
+//
+// Parameters:
+//   - ctx: The context, or nil to detach it
+func (e *Evaluator) SetContext(ctx context.Context) {
+	e.limits.ctx = ctx
+}
+
+// RunLimited runs fn as one program under the evaluator's limits.
+//
+// When no program is running yet, fn waits for its turn to run with the
+// program's goroutines (see eval_concurrency.go), and the step count, the
+// allocation budget, the call depth, the timeout and the call stack are reset
+// before it runs. Nested calls (e.g., a module evaluated while its importer
+// runs) and code run by goroutines keep counting towards the enclosing program.
+//
+// Parameters:
+//   - fn: The code to run
+//
+// Returns:
+//   - std.GoMixObject: The result of fn
+func (e *Evaluator) RunLimited(fn func() std.GoMixObject) std.GoMixObject {
+	if e.spawned {
+		return fn()
+	}
+	l := e.limits
+	if l.running == 0 {
+		e.sched.enterProgram()
+		defer e.sched.leaveProgram()
+		l.steps = 0
+		l.checkAt = 0
+		l.allocs = 0
+		l.deadline = time.Time{}
+		if l.limits.Timeout > 0 {
+			l.deadline = time.Now().Add(l.limits.Timeout)
+		}
+		e.trace.reset(e.File)
+	}
+	l.running++
+	defer func() { l.running-- }()
+	return fn()
+}
+
+// step counts one evaluation step and returns a LimitError when a limit has
+// been exceeded, or nil. Limits other than the step count are only checked
+// every limitCheckInterval steps. Every yieldInterval steps the other
+// goroutines get a turn.
+func (e *Evaluator) step() std.GoMixObject {
+	l := e.limits
+	l.steps++
+	if l.steps%yieldInterval == 0 {
+		e.sched.yield()
+	}
+	if l.steps < l.checkAt {
+		return nil
+	}
+	return e.checkLimits()
+}
+
+// checkLimits checks every limit and schedules the next check. Once a limit
+// is exceeded no check is scheduled, so that every later step fails too and
+// the error cannot be lost on the way up.
+func (e *Evaluator) checkLimits() std.GoMixObject {
+	l := e.limits
+	if max := l.limits.MaxSteps; max > 0 && l.steps > max {
+		return e.limitError(std.LimitSteps, "ERROR: step limit exceeded (%d steps)", max)
+	}
+	if err := e.checkCanceled(); err != nil {
+		return err
+	}
+	if max := l.limits.MaxAlloc; max > 0 && l.allocs > max {
+		return e.limitError(std.LimitAlloc, "ERROR: allocation limit exceeded (%d bytes)", max)
+	}
+
+	l.checkAt = l.steps + limitCheckInterval
+	if max := l.limits.MaxSteps; max > 0 && l.checkAt > max+1 {
+		l.checkAt = max + 1
+	}
+	return nil
+}
+
+// checkCanceled returns a LimitError when the context has been canceled or the
+// timeout has passed, or nil.
+func (e *Evaluator) checkCanceled() std.GoMixObject {
+	l := e.limits
+	if l.ctx != nil {
+		if err := l.ctx.Err(); err != nil {
+			return e.limitError(std.LimitCanceled, "ERROR: execution canceled: %v", err)
+		}
+	}
+	if !l.deadline.IsZero() && time.Now().After(l.deadline) {
+		return e.limitError(std.LimitTimeout, "ERROR: execution timed out after %s", l.limits.Timeout)
+	}
+	return nil
+}
+
+// enterCall records a function call and returns a LimitError when it would
+// exceed the call depth limit, or nil. Every successful enterCall must be
+// paired with leaveCall.
+func (e *Evaluator) enterCall() std.GoMixObject {
+	l := e.limits
+	if l.maxDepth > 0 && e.trace.depth >= l.maxDepth {
+		return e.limitError(std.LimitCallDepth, "ERROR: call depth limit exceeded (%d nested calls)", l.maxDepth)
+	}
+	e.trace.depth++
+	return nil
+}
+
+// leaveCall records the end of a function call.
+func (e *Evaluator) leaveCall() {
+	e.trace.depth--
+}
+
+// charge adds the approximate size of a newly created value to the
+// allocation budget. Exceeding the budget is reported by the next step.
+func (e *Evaluator) charge(obj std.GoMixObject) {
+	l := e.limits
+	if l.limits.MaxAlloc <= 0 {
+		return
+	}
+	l.allocs += objectSize(obj)
+	if l.allocs > l.limits.MaxAlloc {
+		l.checkAt = 0
+	}
+}
+
+// objectSize estimates the memory held directly by a value, without the
+// elements of collections (which are charged when they are created).
+func objectSize(obj std.GoMixObject) int64 {
+	switch o := obj.(type) {
+	case *std.String:
+		return 16 + int64(len(o.Value))
+	case *std.Array:
+		return 24 + 16*int64(len(o.Elements))
+	case *std.List:
+		return 24 + 16*int64(len(o.Elements))
+	case *std.Tuple:
+		return 24 + 16*int64(len(o.Elements))
+	case *std.Map:
+		return 48 + 64*int64(len(o.Keys))
+	case *std.Set:
+		return 48 + 48*int64(len(o.Keys))
+	case *std.Bytes:
+		return 24 + int64(len(o.Value))
+	case *std.BigInt:
+		return 16 + int64(o.Value.BitLen()/8)
+	case *std.Decimal:
+		return 24 + int64(o.Unscaled.BitLen()/8)
+	default:
+		return 16
+	}
+}
+
+// limitError creates a LimitError at the position being evaluated.
+func (e *Evaluator) limitError(limit string, format string, a ...interface{}) std.GoMixObject {
+	err := &std.LimitError{Limit: limit}
+	msg := fmt.Sprintf(format, a...)
+	if line, column := e.position(); line > 0 {
+		err.Line, err.Column = line, column
+		msg = fmt.Sprintf("[%d:%d] %s", err.Line, err.Column, msg)
+	}
+	err.Message = msg
+	return err
+}
+
+// IsLimitError reports whether obj is a LimitError. Such errors end the
+// program: they cannot be caught by try/catch.
+func IsLimitError(obj std.GoMixObject) bool {
+	_, ok := obj.(*std.LimitError)
+	return ok
+}
@@ -61,6 +61,8 @@ func (e *Evaluator) evalForLoop(n *parser.ForLoopStatementNode) std.GoMixObject
 	// Loop execution
 	var result std.GoMixObject = &std.Nil{}
 	for {
+		// Each iteration is charged to the loop (e.g., by a step limit)
+		e.at(n.ForToken)
 		// Evaluate condition if present
 		if n.Condition != nil {
 			condition := e.Eval(n.Condition)
@@ -183,6 +185,7 @@ func (e *Evaluator) evalWhileLoop(n *parser.WhileLoopStatementNode) std.GoMixObj
 	var result std.GoMixObject = &std.Nil{}
 
 	for {
+		e.at(n.WhileToken)
 		// Evaluate all conditions (they should be AND-ed together)
 		allTrue := true
 		for _, cond := range n.Conditions {
@@ -245,14 +248,14 @@ func (e *Evaluator) evalWhileLoop(n *parser.WhileLoopStatementNode) std.GoMixObj
 	return result
 }
 
-// evalForeachLoop evaluates foreach loop statements with support for ranges and arrays.
+// evalForeachLoop evaluates foreach loop statements over any iterable.
 //
 // This method implements foreach loops with the following features:
-// 1. Supports iteration over Range objects (e.g., foreach i in 2...10)
-// 2. Supports iteration over Array objects (e.g., foreach item in [1,2,3])
-// 3. Creates a loop scope for the entire foreach loop
-// 4. Creates a fresh iteration scope for each loop iteration
-// 5. Binds the iterator variable to the current value in each iteration
+// 1. Walks any iterable with the std iteration protocol (see std/iter.go)
+// 2. Creates a loop scope for the entire foreach loop
+// 3. Creates a fresh iteration scope for each loop iteration
+// 4. Binds the iterator variable to the current value in each iteration
+// 5. Binds the key variable, if given, to the current key or index
 // 6. Stops on error or return statement
 //
 // Scope management:
@@ -273,8 +276,8 @@ func (e *Evaluator) evalWhileLoop(n *parser.WhileLoopStatementNode) std.GoMixObj
 //	    print(i);  // Prints 2, 3, 4, 5
 //	}
 //
-//	foreach item in [10, 20, 30] {
-//	    print(item);  // Prints 10, 20, 30
+//	foreach k, v in map{"a": 1, "b": 2} {
+//	    print(k, v);  // Prints a 1, b 2
 //	}
 func (e *Evaluator) evalForeachLoop(n *parser.ForeachLoopStatementNode) std.GoMixObject {
 	// Evaluate the iterable expression
@@ -282,6 +285,14 @@ func (e *Evaluator) evalForeachLoop(n *parser.ForeachLoopStatementNode) std.GoMi
 	if IsError(iterable) {
 		return iterable
 	}
+	e.at(n.ForeachToken)
+	it := std.NewIterator(iterable)
+	if it == nil {
+		return e.CreateError("ERROR: foreach requires an `iterable`, got `%s`", iterable.GetType())
+	}
+	if gen := ownedGenerator(n, iterable); gen != nil {
+		defer gen.Close()
+	}
 
 	// Create a new scope for the entire foreach loop
 	loopScope := scope.NewScope(e.Scp)
@@ -290,212 +301,79 @@ func (e *Evaluator) evalForeachLoop(n *parser.ForeachLoopStatementNode) std.GoMi
 
 	var result std.GoMixObject = &std.Nil{}
 
-	// Handle different iterable types
-	switch iterable.GetType() {
-	case std.RangeType:
-		// Iterate over a range
-		rangeObj := iterable.(*std.Range)
-		start := rangeObj.Start
-		end := rangeObj.End
-
-		// Handle both ascending and descending ranges
-		if start <= end {
-			// Ascending range: iterate from start to end (inclusive)
-			for i := start; i <= end; i++ {
-				// Create a new scope for each iteration
-				iterationScope := scope.NewScope(loopScope)
-				e.Scp = iterationScope
-
-				// Bind the iterator variable to the current value
-				e.Scp.Bind(n.Iterator.Name, &std.Integer{Value: i})
-
-				// Execute loop body
-				result = e.Eval(&n.Body)
-
-				// Restore to loop scope after body execution
-				e.Scp = loopScope
-
-				if IsError(result) {
-					e.Scp = oldScope
-					return result
-				}
-
-				// Stop if we hit a return statement
-				if _, isReturn := result.(*std.ReturnValue); isReturn {
-					e.Scp = oldScope
-					return result
-				}
-
-				if result.GetType() == std.BreakType {
-					e.Scp = oldScope
-					return &std.Nil{}
-				}
-
-				if result.GetType() == std.ContinueType {
-					continue
-				}
-			}
-		} else {
-			// Descending range: iterate from start down to end (inclusive)
-			for i := start; i >= end; i-- {
-				// Create a new scope for each iteration
-				iterationScope := scope.NewScope(loopScope)
-				e.Scp = iterationScope
-
-				// Bind the iterator variable to the current value
-				e.Scp.Bind(n.Iterator.Name, &std.Integer{Value: i})
-
-				// Execute loop body
-				result = e.Eval(&n.Body)
-
-				// Restore to loop scope after body execution
-				e.Scp = loopScope
-
-				if IsError(result) {
-					e.Scp = oldScope
-					return result
-				}
-
-				// Stop if we hit a return statement
-				if _, isReturn := result.(*std.ReturnValue); isReturn {
-					e.Scp = oldScope
-					return result
-				}
-
-				if result.GetType() == std.BreakType {
-					e.Scp = oldScope
-					return &std.Nil{}
-				}
-
-				if result.GetType() == std.ContinueType {
-					continue
-				}
-			}
+	for {
+		e.at(n.ForeachToken)
+		key, value, err := it.Next(e)
+		if err != nil {
+			e.Scp = oldScope
+			return e.withPosition(err, n.ForeachToken)
 		}
-
-	case std.ArrayType:
-		// Iterate over an array
-		arrayObj := iterable.(*std.Array)
-
-		for _, elem := range arrayObj.Elements {
-			// Create a new scope for each iteration
-			iterationScope := scope.NewScope(loopScope)
-			e.Scp = iterationScope
-
-			// Bind the iterator variable to the current element
-			e.Scp.Bind(n.Iterator.Name, elem)
-
-			// Execute loop body
-			result = e.Eval(&n.Body)
-
-			// Restore to loop scope after body execution
-			e.Scp = loopScope
-
-			if IsError(result) {
-				e.Scp = oldScope
-				return result
-			}
-
-			// Stop if we hit a return statement
-			if _, isReturn := result.(*std.ReturnValue); isReturn {
-				e.Scp = oldScope
-				return result
-			}
-
-			if result.GetType() == std.BreakType {
-				e.Scp = oldScope
-				return &std.Nil{}
-			}
-
-			if result.GetType() == std.ContinueType {
-				continue
-			}
+		if value == nil {
+			break
 		}
 
-	case std.ListType:
-		// Iterate over a list
-		listObj := iterable.(*std.List)
-
-		for _, elem := range listObj.Elements {
-			// Create a new scope for each iteration
-			iterationScope := scope.NewScope(loopScope)
-			e.Scp = iterationScope
-
-			// Bind the iterator variable to the current element
-			e.Scp.Bind(n.Iterator.Name, elem)
-
-			// Execute loop body
-			result = e.Eval(&n.Body)
-
-			// Restore to loop scope after body execution
-			e.Scp = loopScope
-
-			if IsError(result) {
-				e.Scp = oldScope
-				return result
-			}
-
-			// Stop if we hit a return statement
-			if _, isReturn := result.(*std.ReturnValue); isReturn {
-				e.Scp = oldScope
-				return result
-			}
+		// Create a new scope for each iteration
+		iterationScope := scope.NewScope(loopScope)
+		e.Scp = iterationScope
 
-			if result.GetType() == std.BreakType {
+		// Bind the loop variables to the current element
+		if n.Key != nil {
+			e.Scp.Bind(n.Key.Name, key)
+		}
+		if n.Pattern != nil {
+			if err := e.bindPattern(n.Pattern, value); err != nil {
 				e.Scp = oldScope
-				return &std.Nil{}
-			}
-
-			if result.GetType() == std.ContinueType {
-				continue
+				return err
 			}
+		} else {
+			e.Scp.Bind(n.Iterator.Name, value)
 		}
 
-	case std.TupleType:
-		// Iterate over a tuple
-		tupleObj := iterable.(*std.Tuple)
-
-		for _, elem := range tupleObj.Elements {
-			// Create a new scope for each iteration
-			iterationScope := scope.NewScope(loopScope)
-			e.Scp = iterationScope
-
-			// Bind the iterator variable to the current element
-			e.Scp.Bind(n.Iterator.Name, elem)
-
-			// Execute loop body
-			result = e.Eval(&n.Body)
-
-			// Restore to loop scope after body execution
-			e.Scp = loopScope
+		// Execute loop body
+		result = e.Eval(&n.Body)
 
-			if IsError(result) {
-				e.Scp = oldScope
-				return result
-			}
+		// Restore to loop scope after body execution
+		e.Scp = loopScope
 
-			// Stop if we hit a return statement
-			if _, isReturn := result.(*std.ReturnValue); isReturn {
-				e.Scp = oldScope
-				return result
-			}
+		if IsError(result) {
+			e.Scp = oldScope
+			return result
+		}
 
-			if result.GetType() == std.BreakType {
-				e.Scp = oldScope
-				return &std.Nil{}
-			}
+		// Stop if we hit a return statement
+		if _, isReturn := result.(*std.ReturnValue); isReturn {
+			e.Scp = oldScope
+			return result
+		}
 
-			if result.GetType() == std.ContinueType {
-				continue
-			}
+		if result.GetType() == std.BreakType {
+			e.Scp = oldScope
+			return &std.Nil{}
 		}
 
-	default:
-		e.Scp = oldScope
-		return e.CreateError("ERROR: foreach requires an `iterable`, got `%s`", iterable.GetType())
+		if result.GetType() == std.ContinueType {
+			result = &std.Nil{}
+			continue
+		}
 	}
 
 	// Restore the original scope
 	e.Scp = oldScope
 	return result
 }
+
+// ownedGenerator returns the generator a foreach loop walks if the loop owns
+// it, or nil. The loop owns a generator returned by a call in its iterable
+// expression (foreach x in gen()): no other code can walk it, so the loop
+// closes it when it ends, even by break, return or error, instead of leaving
+// its body suspended.
+func ownedGenerator(n *parser.ForeachLoopStatementNode, iterable std.GoMixObject) *std.Generator {
+	gen, ok := iterable.(*std.Generator)
+	if !ok {
+		return nil
+	}
+	if _, isCall := n.Iterable.(*parser.CallExpressionNode); !isCall {
+		return nil
+	}
+	return gen
+}
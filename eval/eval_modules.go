@@ -0,0 +1,274 @@
+/*
+File    : go-mix/eval/eval_modules.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/function"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// ModuleExtension is the file extension that marks an import as a user module
+// (e.g., import "lib/util.gm" as util;) rather than a builtin package.
+const ModuleExtension = ".gm"
+
+// ModulePathEnv is the environment variable holding extra directories to search
+// for user modules. It uses the OS path list separator (':' on Unix, ';' on Windows).
+const ModulePathEnv = "GOMIX_PATH"
+
+// moduleLoader keeps track of user modules for one program run.
+// It is shared by the main evaluator and every module evaluator it spawns,
+// so that a module is evaluated only once no matter how many files import it.
+//
+// Fields:
+//   - cache: Loaded modules keyed by their absolute path
+//   - stack: Absolute paths of the modules currently being loaded, in import order.
+//     A path that is imported while it is still on the stack is a circular import.
+//   - located: The errors of failed modules that already name the file they
+//     were raised in (see inModule)
+type moduleLoader struct {
+	cache   map[string]*std.Package
+	stack   []string
+	located map[*std.Error]bool
+}
+
+// newModuleLoader creates an empty module loader.
+func newModuleLoader() *moduleLoader {
+	return &moduleLoader{
+		cache:   make(map[string]*std.Package),
+		stack:   make([]string, 0),
+		located: make(map[*std.Error]bool),
+	}
+}
+
+// inModule returns err, raised while loading the module at path, with the
+// file it was raised in before its position (e.g., "[lib/util.gm:3:12]
+// ERROR: division by zero"). An error that already names its file, raised in
+// a module imported by this one, is returned as it is: its traceback shows
+// the chain of imports that led to it.
+func (l *moduleLoader) inModule(path string, err *std.Error) *std.Error {
+	if l.located[err] {
+		return err
+	}
+	msg, position := err.Message, ""
+	if err.Line > 0 {
+		msg = strings.TrimPrefix(msg, fmt.Sprintf("[%d:%d] ", err.Line, err.Column))
+		position = fmt.Sprintf(":%d:%d", err.Line, err.Column)
+	}
+	located := &std.Error{
+		Message: fmt.Sprintf("[%s%s] %s", std.DisplayPath(path), position, msg),
+		Line:    err.Line,
+		Column:  err.Column,
+		Value:   err.Value,
+		Trace:   err.Trace,
+	}
+	l.located[located] = true
+	return located
+}
+
+// SetSourceFile records the path of the file being evaluated.
+//
+// Relative module imports are resolved against the directory of this file,
+// and the file itself takes part in circular import detection.
+// When no source file is set (e.g., in the REPL), imports are resolved
+// against the current working directory.
+//
+// Parameters:
+//   - path: The path of the Go-Mix source file being evaluated
+func (e *Evaluator) SetSourceFile(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	e.File = path
+}
+
+// IsModuleImport reports whether an import name refers to a user module file.
+func IsModuleImport(name string) bool {
+	return strings.HasSuffix(name, ModuleExtension)
+}
+
+// moduleBindName returns the default namespace name for a module import,
+// which is the file name without its directory and extension.
+//
+// Example:
+//
+//	moduleBindName("lib/util.gm") -> "util"
+func moduleBindName(name string) string {
+	return strings.TrimSuffix(filepath.Base(name), ModuleExtension)
+}
+
+// resolveModulePath finds the file for a module import.
+//
+// The lookup order is:
+// 1. Absolute paths are used as-is
+// 2. The directory of the importing file (or the working directory in the REPL)
+// 3. Each directory listed in GOMIX_PATH, in order
+//
+// Parameters:
+//   - name: The import name as written in the source (e.g., "lib/util.gm")
+//
+// Returns:
+//   - string: The absolute path of the module file
+//   - bool: false if the module could not be found
+func (e *Evaluator) resolveModulePath(name string) (string, bool) {
+	candidates := make([]string, 0)
+	if filepath.IsAbs(name) {
+		candidates = append(candidates, name)
+	} else {
+		baseDir := "."
+		if e.File != "" {
+			baseDir = filepath.Dir(e.File)
+		}
+		candidates = append(candidates, filepath.Join(baseDir, name))
+		for _, dir := range filepath.SplitList(os.Getenv(ModulePathEnv)) {
+			if dir != "" {
+				candidates = append(candidates, filepath.Join(dir, name))
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		abs, err := filepath.Abs(candidate)
+		if err != nil {
+			return candidate, true
+		}
+		return abs, true
+	}
+	return "", false
+}
+
+// importModule loads a user module and returns its namespace package.
+//
+// The module file is parsed and evaluated once, in its own global scope, by a
+// separate evaluator that shares this evaluator's output, input and module cache.
+// Later imports of the same file (from any module) reuse the cached namespace.
+//
+// Parameters:
+//   - n: The ImportStatementNode naming the module file
+//
+// Returns:
+//   - std.GoMixObject: The module's Package, or an Error if the module cannot be
+//     found, fails to parse, fails to evaluate, or is part of an import cycle
+//
+// Example:
+//
+//	import "lib/util.gm" as util;
+//	util.add(1, 2);
+func (e *Evaluator) importModule(n *parser.ImportStatementNode) std.GoMixObject {
+	path, found := e.resolveModulePath(n.Name)
+	if !found {
+		return e.createError(n.Token, "ERROR: module '%s' not found", n.Name)
+	}
+
+	if pkg, ok := e.modules.cache[path]; ok {
+		return pkg
+	}
+
+	// The entry file counts as loading for the whole run, so a module
+	// importing it back is reported as a cycle too
+	if len(e.modules.stack) == 0 && e.File != "" {
+		e.modules.stack = append(e.modules.stack, e.File)
+	}
+
+	// Detect cycles: the importing chain already contains this module
+	for i, loading := range e.modules.stack {
+		if loading == path {
+			cycle := make([]string, 0, len(e.modules.stack)-i+1)
+			for _, p := range e.modules.stack[i:] {
+				cycle = append(cycle, filepath.Base(p))
+			}
+			cycle = append(cycle, filepath.Base(path))
+			return e.createError(n.Token, "ERROR: circular import detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	if e.Perms != nil && !e.Perms.Allows(std.CapFSRead, path) {
+		return e.createError(n.Token, "ERROR: permission denied: importing module '%s' needs fs-read access to '%s'", n.Name, path)
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return e.createError(n.Token, "ERROR: could not read module '%s': %v", n.Name, err)
+	}
+
+	par := parser.NewParser(string(source))
+	root := par.Parse()
+	if par.HasErrors() {
+		return e.createError(n.Token, "ERROR: could not parse module '%s':\n%s", n.Name, strings.Join(par.GetErrors(), "\n"))
+	}
+
+	// Evaluate the module with its own evaluator so that its globals and
+	// struct types stay private to the module
+	modEv := NewEvaluator()
+	modEv.SetParser(par)
+	modEv.Writer = e.Writer
+	modEv.Reader = e.Reader
+	modEv.File = path
+	modEv.UseVM = e.UseVM
+	modEv.modules = e.modules
+	modEv.limits = e.limits
+	modEv.Perms = e.Perms
+	modEv.trace = e.trace
+	modEv.sched = e.sched
+	modEv.spawned = e.spawned
+
+	e.modules.stack = append(e.modules.stack, path)
+	e.at(n.Token)
+	e.pushFrame("<module>", path)
+	result := e.popFrame(modEv.Eval(root))
+	e.modules.stack = e.modules.stack[:len(e.modules.stack)-1]
+	if IsLimitError(result) {
+		return result
+	}
+	if IsError(result) {
+		return e.modules.inModule(path, result.(*std.Error))
+	}
+
+	pkg := modEv.exportModule(moduleBindName(n.Name))
+	e.modules.cache[path] = pkg
+	return pkg
+}
+
+// exportModule builds the namespace package for a module that has finished evaluating.
+//
+// The top-level functions, structs, enums, interfaces and constants of the module scope are
+// exported as members. A call such as util.add(b: 1, a: 2) calls the function
+// itself, like any other user-defined function (with defaults, named and rest
+// arguments), on the evaluator calling it (such as a spawned goroutine's) and
+// in the module's scope. Plain var/let globals stay private to the module.
+//
+// Parameters:
+//   - name: The module name used for the package
+//
+// Returns:
+//   - *std.Package: The module namespace
+func (e *Evaluator) exportModule(name string) *std.Package {
+	pkg := &std.Package{
+		Name:      name,
+		Functions: make(map[string]*std.Builtin),
+		Members:   make(map[string]std.GoMixObject),
+	}
+	for member, val := range e.Scp.Variables {
+		switch obj := val.(type) {
+		case *function.Function, *std.GoMixStruct, *std.GoMixEnum, *std.GoMixInterface:
+			pkg.Members[member] = obj
+		default:
+			if e.Scp.Consts[member] {
+				pkg.Members[member] = obj
+			}
+		}
+	}
+	return pkg
+}
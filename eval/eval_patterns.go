@@ -0,0 +1,407 @@
+/*
+File    : go-mix/eval/eval_patterns.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// evalTupleExpression evaluates a tuple literal, (a, b) or the values of
+// return a, b, to a tuple of the evaluated elements.
+//
+// Parameters:
+//   - n: The TupleExpressionNode
+//
+// Returns:
+//   - std.GoMixObject: The tuple, or the Error of the first failing element
+func (e *Evaluator) evalTupleExpression(n *parser.TupleExpressionNode) std.GoMixObject {
+	elements := make([]std.GoMixObject, len(n.Elements))
+	for i, elem := range n.Elements {
+		elements[i] = e.Eval(elem)
+		if IsError(elements[i]) {
+			return elements[i]
+		}
+	}
+	tuple := &std.Tuple{Elements: elements}
+	e.charge(tuple)
+	return tuple
+}
+
+// destructure takes apart a value with a destructuring pattern.
+//
+// Positional patterns, (a, b) and [a, b], take the elements of a tuple,
+// array, list or any other iterable; there must be as many as names, or at
+// least as many if the pattern has a rest name, which gets an array of the
+// others. Name patterns, {a, b}, take the entries of a map with those keys
+// (nil for missing keys), the rest name getting a map of the other entries,
+// or the fields of a struct instance.
+//
+// Parameters:
+//   - p: The pattern
+//   - val: The value to take apart
+//
+// Returns:
+//   - []std.GoMixObject: The values of the names of p.Bindings(), in order
+//   - std.GoMixObject: nil, or an Error if val does not fit the pattern
+func (e *Evaluator) destructure(p *parser.PatternNode, val std.GoMixObject) ([]std.GoMixObject, std.GoMixObject) {
+	values := make([]std.GoMixObject, 0, len(p.Names)+1)
+	if p.Open.Type != lexer.LEFT_BRACE {
+		if std.NewIterator(val) == nil {
+			return nil, e.createError(p.Open, "ERROR: cannot destructure `%s` with pattern %s", val.GetType(), p.Literal())
+		}
+		elements, err := std.Collect(e, val)
+		if err != nil {
+			return nil, err
+		}
+		if p.Rest == nil && len(elements) != len(p.Names) {
+			return nil, e.createError(p.Open, "ERROR: cannot destructure %d values into %d names", len(elements), len(p.Names))
+		}
+		if len(elements) < len(p.Names) {
+			return nil, e.createError(p.Open, "ERROR: cannot destructure %d values into at least %d names", len(elements), len(p.Names))
+		}
+		values = append(values, elements[:len(p.Names)]...)
+		if p.Rest != nil {
+			rest := &std.Array{Elements: append([]std.GoMixObject{}, elements[len(p.Names):]...)}
+			e.charge(rest)
+			values = append(values, rest)
+		}
+		return values, nil
+	}
+
+	switch obj := val.(type) {
+	case *std.Map:
+		taken := make(map[std.HashKey]bool, len(p.Names))
+		for _, name := range p.Names {
+			taken[std.StringKey(name.Name)] = true
+			if value, ok := obj.GetString(name.Name); ok {
+				values = append(values, value)
+			} else {
+				values = append(values, &std.Nil{})
+			}
+		}
+		if p.Rest != nil {
+			rest := restOfMap(obj, taken)
+			e.charge(rest)
+			values = append(values, rest)
+		}
+		return values, nil
+	case *std.GoMixObjectInstance:
+		if p.Rest != nil {
+			return nil, e.createError(p.Rest.Token, "ERROR: rest name (...%s) cannot take the fields of a struct instance", p.Rest.Name)
+		}
+		for _, name := range p.Names {
+			value := e.evalMemberAccess(obj, name)
+			if IsError(value) {
+				return nil, value
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+	return nil, e.createError(p.Open, "ERROR: cannot destructure `%s` with pattern %s", val.GetType(), p.Literal())
+}
+
+// declarePattern binds the names of a var, let or const declaration with a
+// destructuring pattern (var (q, r) = divmod(7, 2);) in the current scope.
+//
+// Parameters:
+//   - n: The DeclarativeStatementNode, whose Pattern is set
+//   - val: The evaluated initialization expression
+//
+// Returns:
+//   - std.GoMixObject: val on success, or an Error
+func (e *Evaluator) declarePattern(n *parser.DeclarativeStatementNode, val std.GoMixObject) std.GoMixObject {
+	values, err := e.destructure(n.Pattern, val)
+	if err != nil {
+		return err
+	}
+	for i, name := range n.Pattern.Bindings() {
+		if res := e.declareName(n.VarToken, name.Name, values[i]); IsError(res) {
+			return res
+		}
+	}
+	return val
+}
+
+// evalPatternAssignment assigns the parts of a value to the existing
+// variables named by a destructuring pattern ([a, b] = [b, a];), following
+// the rules of plain assignments for constants and let variables.
+//
+// Parameters:
+//   - p: The pattern on the left of the assignment
+//   - val: The evaluated right-hand side
+//
+// Returns:
+//   - std.GoMixObject: val on success, or an Error
+func (e *Evaluator) evalPatternAssignment(p *parser.PatternNode, val std.GoMixObject) std.GoMixObject {
+	values, err := e.destructure(p, val)
+	if err != nil {
+		return err
+	}
+	for i, name := range p.Bindings() {
+		if res := e.evalIdentifierAssignment(name, values[i]); IsError(res) {
+			return res
+		}
+	}
+	return val
+}
+
+// bindPattern binds the names of the destructuring pattern of a foreach head
+// to the parts of the current value, in the current (loop) scope.
+//
+// Returns:
+//   - An Error if the value does not fit the pattern, otherwise nil
+func (e *Evaluator) bindPattern(p *parser.PatternNode, val std.GoMixObject) std.GoMixObject {
+	values, err := e.destructure(p, val)
+	if err != nil {
+		return err
+	}
+	for i, name := range p.Bindings() {
+		e.Scp.Bind(name.Name, values[i])
+	}
+	return nil
+}
+
+// matchPattern reports whether val matches the pattern p of a match arm,
+// binding the names of p in the current scope along the way (the caller
+// discards the scope if val does not match).
+//
+// Parameters:
+//   - p: The pattern
+//   - val: The matched value
+//
+// Returns:
+//   - bool: Whether val matches p
+//   - std.GoMixObject: nil, or an Error if a value of p could not be evaluated
+func (e *Evaluator) matchPattern(p *parser.MatchPatternNode, val std.GoMixObject) (bool, std.GoMixObject) {
+	switch p.Kind {
+	case parser.WildcardPattern:
+		return true, nil
+	case parser.BindingPattern:
+		e.Scp.Bind(p.Name.Name, val)
+		return true, nil
+	case parser.ValuePattern:
+		want := e.Eval(p.Value)
+		if IsError(want) {
+			return false, want
+		}
+		if eq, ok, err := std.ProtocolEqual(e, val, want); ok {
+			return eq, err
+		}
+		return switchValuesEqual(val, want), nil
+	case parser.RangePattern:
+		return e.matchRange(p, val)
+	case parser.AlternativePattern:
+		for _, alt := range p.Elements {
+			if ok, err := e.matchPattern(alt, val); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	case parser.SequencePattern:
+		return e.matchSequence(p, val)
+	case parser.MapPattern:
+		return e.matchMap(p, val)
+	}
+	return e.matchStruct(p, val)
+}
+
+// matchRange matches the numbers, or the chars, between the bounds of a range
+// pattern, inclusive. Values of other types do not match.
+func (e *Evaluator) matchRange(p *parser.MatchPatternNode, val std.GoMixObject) (bool, std.GoMixObject) {
+	start := e.Eval(p.Value)
+	if IsError(start) {
+		return false, start
+	}
+	end := e.Eval(p.End)
+	if IsError(end) {
+		return false, end
+	}
+	if isNumber(start) && isNumber(end) {
+		if !isNumber(val) {
+			return false, nil
+		}
+		v := switchToFloat64(val)
+		return switchToFloat64(start) <= v && v <= switchToFloat64(end), nil
+	}
+	if start.GetType() == std.CharType && end.GetType() == std.CharType {
+		c, ok := val.(*std.Char)
+		return ok && start.(*std.Char).Value <= c.Value && c.Value <= end.(*std.Char).Value, nil
+	}
+	return false, e.createError(p.Token, "ERROR: bounds of range pattern %s must be numbers or chars, got (%s) and (%s)", p.Literal(), start.GetType(), end.GetType())
+}
+
+// isNumber reports whether obj is an int, a float, a bigint or a decimal.
+func isNumber(obj std.GoMixObject) bool {
+	_, ok := std.ToFloat64(obj)
+	return ok
+}
+
+// matchSequence matches the elements of an array, list or tuple one by one;
+// there must be as many as patterns, or at least as many if the pattern has a
+// rest name, which gets an array of the others.
+func (e *Evaluator) matchSequence(p *parser.MatchPatternNode, val std.GoMixObject) (bool, std.GoMixObject) {
+	var elements []std.GoMixObject
+	switch obj := val.(type) {
+	case *std.Array:
+		elements = obj.Elements
+	case *std.List:
+		elements = obj.Elements
+	case *std.Tuple:
+		elements = obj.Elements
+	default:
+		return false, nil
+	}
+	if len(elements) < len(p.Elements) || (p.Rest == nil && len(elements) != len(p.Elements)) {
+		return false, nil
+	}
+	for i, elem := range p.Elements {
+		if ok, err := e.matchPattern(elem, elements[i]); !ok || err != nil {
+			return false, err
+		}
+	}
+	if p.Rest != nil && p.Rest.Name != "_" {
+		rest := &std.Array{Elements: append([]std.GoMixObject{}, elements[len(p.Elements):]...)}
+		e.charge(rest)
+		e.Scp.Bind(p.Rest.Name, rest)
+	}
+	return true, nil
+}
+
+// matchMap matches a map having all the keys of the pattern, whose values
+// match their patterns; the rest name gets a map of the other entries.
+func (e *Evaluator) matchMap(p *parser.MatchPatternNode, val std.GoMixObject) (bool, std.GoMixObject) {
+	obj, ok := val.(*std.Map)
+	if !ok {
+		return false, nil
+	}
+	taken := make(map[std.HashKey]bool, len(p.Keys))
+	for i, key := range p.Keys {
+		value, ok := obj.GetString(key)
+		if !ok {
+			return false, nil
+		}
+		if ok, err := e.matchPattern(p.Elements[i], value); !ok || err != nil {
+			return false, err
+		}
+		taken[std.StringKey(key)] = true
+	}
+	if p.Rest != nil && p.Rest.Name != "_" {
+		rest := restOfMap(obj, taken)
+		e.charge(rest)
+		e.Scp.Bind(p.Rest.Name, rest)
+	}
+	return true, nil
+}
+
+// restOfMap returns a new map of the entries of m whose keys are not taken.
+func restOfMap(m *std.Map, taken map[std.HashKey]bool) *std.Map {
+	rest := std.NewMap()
+	for _, hk := range m.Keys {
+		if pair := m.Pairs[hk]; !taken[hk] {
+			rest.Set(hk, pair.Key, pair.Value)
+		}
+	}
+	return rest
+}
+
+// matchStruct matches an instance of the struct of the pattern (or of a
+// struct extending it), or of a struct implementing the interface of the
+// pattern, whose fields match their patterns.
+func (e *Evaluator) matchStruct(p *parser.MatchPatternNode, val std.GoMixObject) (bool, std.GoMixObject) {
+	var typ std.GoMixObject
+	if s, ok := e.lookUpStructType(p.Name.Name); ok {
+		typ = s
+	} else if iface, ok := e.lookUpInterface(p.Name.Name); ok {
+		typ = iface
+	} else {
+		return false, e.createError(p.Token, "ERROR: (%s) in a match pattern is not a struct or an interface", p.Name.Name)
+	}
+	if !e.instanceOf(val, typ).(*std.Boolean).Value {
+		return false, nil
+	}
+	inst := val.(*std.GoMixObjectInstance)
+	for i, field := range p.Keys {
+		value, ok := inst.InstanceFields[field]
+		if !ok {
+			if value, _, ok = inst.Struct.LookUpField(field); !ok {
+				return false, nil
+			}
+		}
+		if ok, err := e.matchPattern(p.Elements[i], value); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkExhaustive checks that a match expression whose patterns name members
+// of an enum covers all the members of the enum, unless an arm matches any
+// value. Arms with a guard cover nothing.
+//
+// Returns:
+//   - std.GoMixObject: nil, or an Error listing the missing members
+func (e *Evaluator) checkExhaustive(n *parser.MatchExpressionNode) std.GoMixObject {
+	enums := make([]*std.GoMixEnum, 0)
+	covered := make(map[*std.GoMixEnum]map[string]bool)
+	for _, arm := range n.Arms {
+		if arm.Guard != nil {
+			continue
+		}
+		if arm.Pattern.Irrefutable() {
+			return nil
+		}
+		for _, p := range arm.Pattern.ValuePatterns() {
+			access, ok := p.Value.(*parser.BinaryExpressionNode)
+			if !ok || access.Operation.Type != lexer.DOT_OP {
+				continue
+			}
+			left, isIdent := access.Left.(*parser.IdentifierExpressionNode)
+			member, isName := access.Right.(*parser.IdentifierExpressionNode)
+			if !isIdent || !isName {
+				continue
+			}
+			obj, _ := e.Scp.LookUp(left.Name)
+			enum, isEnum := obj.(*std.GoMixEnum)
+			if !isEnum {
+				continue
+			}
+			if covered[enum] == nil {
+				covered[enum] = make(map[string]bool)
+				enums = append(enums, enum)
+			}
+			covered[enum][member.Name] = true
+		}
+	}
+	for _, enum := range enums {
+		missing := make([]string, 0)
+		for name := range enum.Members {
+			if !covered[enum][name] {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		sort.Slice(missing, func(i, j int) bool {
+			a, b := enum.Members[missing[i]], enum.Members[missing[j]]
+			if a.ToString() != b.ToString() {
+				return switchToFloat64(a) < switchToFloat64(b)
+			}
+			return missing[i] < missing[j]
+		})
+		for i, name := range missing {
+			missing[i] = enum.Name + "." + name
+		}
+		return e.createError(n.Token, "ERROR: match over enum (%s) is not exhaustive: missing %s", enum.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
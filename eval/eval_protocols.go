@@ -0,0 +1,89 @@
+/*
+File    : go-mix/eval/eval_protocols.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package eval - eval_protocols.go
+// This file dispatches the operators to the protocol methods of struct
+// instances (see std/protocols.go), so user types like a Vector or a Money
+// struct can be added, compared and indexed like the builtin types.
+package eval
+
+import (
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// arithmeticProtocols maps the arithmetic operators to their protocol methods.
+var arithmeticProtocols = map[lexer.TokenType]string{
+	lexer.PLUS_OP:  "__add__",
+	lexer.MINUS_OP: "__sub__",
+	lexer.MUL_OP:   "__mul__",
+	lexer.DIV_OP:   "__div__",
+	lexer.MOD_OP:   "__mod__",
+}
+
+// arithmeticProtocol applies an arithmetic operator whose left operand is a
+// struct instance with the operator's protocol method (e.g., a + b calls
+// a.__add__(b)).
+//
+// Returns:
+//   - std.GoMixObject: The result of the method, or an Error
+//   - bool: false if the operator is not overloaded for left
+func (e *Evaluator) arithmeticProtocol(opType lexer.TokenType, left, right std.GoMixObject) (std.GoMixObject, bool) {
+	name, ok := arithmeticProtocols[opType]
+	if !ok {
+		return nil, false
+	}
+	return std.CallProtocol(e, left, name, right)
+}
+
+// compareProtocol applies a relational operator (<, >, <=, >=) with the
+// __lt__ method of either operand: a > b is b < a, a <= b is !(b < a) and
+// a >= b is !(a < b).
+//
+// Returns:
+//   - std.GoMixObject: A Boolean, or an Error
+//   - bool: false for other operators, or if neither operand has an __lt__ method
+func (e *Evaluator) compareProtocol(op lexer.Token, left, right std.GoMixObject) (std.GoMixObject, bool) {
+	a, b, negate := left, right, false
+	switch op.Type {
+	case lexer.LT_OP:
+	case lexer.GT_OP:
+		a, b = right, left
+	case lexer.LE_OP:
+		a, b, negate = right, left, true
+	case lexer.GE_OP:
+		negate = true
+	default:
+		return nil, false
+	}
+	less, ok, err := std.ProtocolLess(e, a, b)
+	if !ok {
+		return nil, false
+	}
+	if err != nil {
+		return err, true
+	}
+	return &std.Boolean{Value: less != negate}, true
+}
+
+// equalProtocol applies == or != with the __eq__ method of either operand.
+//
+// Returns:
+//   - std.GoMixObject: A Boolean, or an Error
+//   - bool: false for other operators, or if neither operand has an __eq__ method
+func (e *Evaluator) equalProtocol(op lexer.Token, left, right std.GoMixObject) (std.GoMixObject, bool) {
+	if op.Type != lexer.EQ_OP && op.Type != lexer.NE_OP {
+		return nil, false
+	}
+	eq, ok, err := std.ProtocolEqual(e, left, right)
+	if !ok {
+		return nil, false
+	}
+	if err != nil {
+		return err, true
+	}
+	return &std.Boolean{Value: eq == (op.Type == lexer.EQ_OP)}, true
+}
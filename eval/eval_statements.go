@@ -75,20 +75,57 @@ func (e *Evaluator) evalDeclarativeStatement(n *parser.DeclarativeStatementNode)
 	if IsError(val) {
 		return val
 	}
+	return e.declareValue(n, val)
+}
+
+// declareValue binds an already evaluated initial value for a var, const or let
+// declaration in the current scope.
+//
+// Parameters:
+//   - n: The DeclarativeStatementNode being executed
+//   - val: The evaluated initialization expression
+//
+// Returns:
+//   - std.GoMixObject: val on success, or an Error if the name is already
+//     declared or val does not conform to the annotated type
+func (e *Evaluator) declareValue(n *parser.DeclarativeStatementNode, val std.GoMixObject) std.GoMixObject {
+	if n.Pattern != nil {
+		return e.declarePattern(n, val)
+	}
+	val, ok := e.conform(val, n.VarType)
+	if !ok {
+		return e.createError(n.Identifier.Token, "ERROR: can't assign `%s` to variable (%s) of type `%s`", typeOf(val), n.Identifier.Name, n.VarType)
+	}
+	res := e.declareName(n.VarToken, n.Identifier.Name, val)
+	if n.VarType != nil && !IsError(res) {
+		e.Scp.Types[n.Identifier.Name] = n.VarType
+	}
+	return res
+}
 
+// declareName binds one name declared by var, const or let in the current scope.
+//
+// Parameters:
+//   - varToken: The declaration keyword token
+//   - name: The declared name
+//   - val: The initial value
+//
+// Returns:
+//   - std.GoMixObject: val on success, or an Error if the name is already declared
+func (e *Evaluator) declareName(varToken lexer.Token, name string, val std.GoMixObject) std.GoMixObject {
 	// redeclared?
-	_, has := e.Scp.Bind(n.Identifier.Name, val)
+	_, has := e.Scp.Bind(name, val)
 	if has {
-		return e.CreateError("ERROR: identifier redeclaration found: (%s)", n.Identifier.Name)
+		return e.CreateError("ERROR: identifier redeclaration found: (%s)", name)
 	}
 
-	if n.VarToken.Type == lexer.CONST_KEY {
-		e.Scp.Consts[n.Identifier.Name] = true
-	} else if n.VarToken.Type == lexer.LET_KEY {
-		e.Scp.LetVars[n.Identifier.Name] = true
-		e.Scp.LetTypes[n.Identifier.Name] = val.GetType()
+	if varToken.Type == lexer.CONST_KEY {
+		e.Scp.Consts[name] = true
+	} else if varToken.Type == lexer.LET_KEY {
+		e.Scp.LetVars[name] = true
+		e.Scp.LetTypes[name] = val.GetType()
 	}
-	e.Scp.Bind(n.Identifier.Name, val)
+	e.Scp.Bind(name, val)
 	return val
 }
 
@@ -16,9 +16,11 @@ import (
 // evalStructDeclaration evaluates a struct declaration statement.
 //
 // This method creates a new GoMixStruct type definition. It processes:
+// - Parent: Resolves the struct it extends, whose methods and static fields it inherits
 // - Fields: Evaluates initial values and registers them as static fields
 // - Methods: Creates Function objects and registers them
 // - Const/Let/Var modifiers: Records field properties
+// - Interfaces: Checks that the struct has every method of the interfaces it implements
 //
 // The resulting struct type is bound to its name in the current scope.
 //
@@ -33,18 +35,43 @@ func (e *Evaluator) evalStructDeclaration(n *parser.StructDeclarationNode) std.G
 		Name:        n.StructName.Name,
 		Methods:     make(map[string]std.FunctionInterface),
 		FieldNodes:  make([]interface{}, len(n.Fields)),
+		Fields:      make([]string, len(n.Fields)),
 		ClassFields: make(map[string]std.GoMixObject),
 		ConstFields: make(map[string]bool),
 		LetFields:   make(map[string]bool),
 		LetTypes:    make(map[string]std.GoMixType),
+		FieldTypes:  make(map[string]*std.TypeAnnotation),
+		Interfaces:  make([]*std.GoMixInterface, 0),
+	}
+
+	if n.Parent != nil {
+		parent, ok := e.lookUpStructType(n.Parent.Name)
+		if !ok {
+			return e.createError(n.Parent.Token, "ERROR: struct (%s) cannot extend (%s): no such struct", s.Name, n.Parent.Name)
+		}
+		s.Parent = parent
+	}
+	for _, name := range n.Interfaces {
+		iface, ok := e.lookUpInterface(name.Name)
+		if !ok {
+			return e.createError(name.Token, "ERROR: struct (%s) cannot implement (%s): no such interface", s.Name, name.Name)
+		}
+		s.Interfaces = append(s.Interfaces, iface)
 	}
 
 	for i, f := range n.Fields {
 		s.FieldNodes[i] = f
+		s.Fields[i] = f.Identifier.Name
 		val := e.Eval(f.Expr)
 		if IsError(val) {
 			return val
 		}
+		if f.VarType != nil {
+			s.FieldTypes[f.Identifier.Name] = f.VarType
+			if val = e.conformField(s, f.Identifier.Name, val); IsError(val) {
+				return val
+			}
+		}
 		s.ClassFields[f.Identifier.Name] = val
 		if f.VarToken.Type == lexer.CONST_KEY {
 			s.ConstFields[f.Identifier.Name] = true
@@ -56,27 +83,188 @@ func (e *Evaluator) evalStructDeclaration(n *parser.StructDeclarationNode) std.G
 
 	for _, m := range n.Methods {
 		method := &function.Function{
-			Name:   m.FuncName.Name,
-			Params: m.FuncParams,
-			Body:   &m.FuncBody,
-			Scp:    e.Scp, // Capture the current scope for closures
+			Name:       m.FuncName.Name,
+			Params:     m.FuncParams,
+			Defaults:   m.FuncDefaults,
+			Rest:       m.FuncRest,
+			ParamTypes: m.ParamTypes,
+			Result:     m.ReturnType,
+			Generator:  m.Generator,
+			Body:       &m.FuncBody,
+			Scp:        e.Scp, // Capture the current scope for closures
+			File:       e.File,
 		}
 		if err := s.Add(method); err != nil {
 			return e.CreateError("ERROR: struct method '%s' already defined", method.Name)
 		}
 	}
 
+	for _, iface := range s.Interfaces {
+		if err := e.checkImplements(s, iface); err != nil {
+			return err
+		}
+	}
+
 	e.Types[s.Name] = s
 	e.Scp.Bind(s.Name, s)
 	return s
 }
 
+// evalInterfaceDeclaration evaluates an interface declaration statement.
+//
+// The interface is bound to its name in the current scope; structs name it
+// after 'implements', and 'instanceof' tests whether a value implements it.
+//
+// Parameters:
+//   - n: The InterfaceDeclarationNode from the AST
+//
+// Returns:
+//   - objects.GoMixObject: The created GoMixInterface object
+func (e *Evaluator) evalInterfaceDeclaration(n *parser.InterfaceDeclarationNode) std.GoMixObject {
+	iface := &std.GoMixInterface{
+		Name:    n.InterfaceName.Name,
+		Methods: make([]std.InterfaceMethod, len(n.Methods)),
+	}
+	for i, m := range n.Methods {
+		params := make([]string, len(m.Params))
+		for j, param := range m.Params {
+			params[j] = param.Name
+		}
+		iface.Methods[i] = std.InterfaceMethod{Name: m.Name.Name, Params: params}
+	}
+	e.Scp.Bind(iface.Name, iface)
+	return iface
+}
+
+// checkImplements checks that a struct has (or inherits) every method of an
+// interface, and that each of them can be called with the arguments of the
+// interface method.
+//
+// Parameters:
+//   - s: The struct being declared
+//   - iface: An interface the struct declares it implements
+//
+// Returns:
+//   - An Error naming the first missing or mismatched method, otherwise nil
+func (e *Evaluator) checkImplements(s *std.GoMixStruct, iface *std.GoMixInterface) std.GoMixObject {
+	for _, m := range iface.Methods {
+		method, ok := s.GetMethod(m.Name)
+		if !ok {
+			return e.CreateError("ERROR: struct (%s) does not implement interface (%s): missing method (%s)", s.Name, iface.Name, m.Name)
+		}
+		fn, ok := method.(*function.Function)
+		if !ok {
+			continue
+		}
+		argc := len(m.Params)
+		if required, fixed := fn.Arity(); argc < required || (argc > fixed && !fn.Rest) {
+			return e.CreateError("ERROR: struct (%s) does not implement interface (%s): method (%s) must take %d arguments", s.Name, iface.Name, m.Name, argc)
+		}
+	}
+	return nil
+}
+
+// lookUpQualified resolves a name through the scope chain, optionally
+// qualified by the package of an imported module (e.g., "shapes.Shape").
+//
+// Returns:
+//   - std.GoMixObject: The value of the name, if found
+//   - bool: true if the name was found
+func (e *Evaluator) lookUpQualified(name string) (std.GoMixObject, bool) {
+	dotIdx := IndexOfDot(name)
+	if dotIdx <= 0 {
+		return e.Scp.LookUp(name)
+	}
+	pkgObj, found := e.Scp.LookUp(name[:dotIdx])
+	if !found {
+		return nil, false
+	}
+	pkg, isPkg := pkgObj.(*std.Package)
+	if !isPkg {
+		return nil, false
+	}
+	obj, ok := pkg.Members[name[dotIdx+1:]]
+	return obj, ok
+}
+
+// lookUpInterface resolves an interface through the scope chain, optionally
+// qualified by the package of an imported module (e.g., "shapes.Shape").
+//
+// Returns:
+//   - *std.GoMixInterface: The interface, if found
+//   - bool: true if the interface was found
+func (e *Evaluator) lookUpInterface(name string) (*std.GoMixInterface, bool) {
+	obj, ok := e.lookUpQualified(name)
+	if !ok {
+		return nil, false
+	}
+	iface, ok := obj.(*std.GoMixInterface)
+	return iface, ok
+}
+
+// instanceOf implements the instanceof operator: whether value is an
+// instance of the struct typ or of a struct extending it, or an instance of
+// a struct implementing the interface typ.
+//
+// Parameters:
+//   - value: The tested value (values other than struct instances are instances of nothing)
+//   - typ: A struct or an interface
+//
+// Returns:
+//   - std.GoMixObject: A Boolean, or an Error if typ is neither a struct nor an interface
+func (e *Evaluator) instanceOf(value, typ std.GoMixObject) std.GoMixObject {
+	inst, isInstance := value.(*std.GoMixObjectInstance)
+	switch t := typ.(type) {
+	case *std.GoMixStruct:
+		return &std.Boolean{Value: isInstance && inst.Struct.IsA(t)}
+	case *std.GoMixInterface:
+		return &std.Boolean{Value: isInstance && inst.Struct.Implements(t)}
+	}
+	return e.CreateError("ERROR: right operand of 'instanceof' must be a struct or an interface, got %s", typ.GetType())
+}
+
+// bindSuper makes 'super' available in the scope of a method declared by the
+// struct owner, if owner extends another struct: super.method(...) then calls
+// the method of the parent on the same instance.
+func bindSuper(scp *scope.Scope, owner *std.GoMixStruct) {
+	if owner != nil && owner.Parent != nil {
+		scp.Bind("super", owner.Parent)
+	}
+}
+
+// evalSuperMember evaluates super.name in a method: a method of the parent of
+// the struct declaring the method, bound to 'this', or a static field of the
+// parent.
+//
+// Parameters:
+//   - n: The SuperExpressionNode
+//   - ident: The member name
+//
+// Returns:
+//   - std.GoMixObject: The bound method or field value, or an Error
+func (e *Evaluator) evalSuperMember(n *parser.SuperExpressionNode, ident *parser.IdentifierExpressionNode) std.GoMixObject {
+	parentObj, ok := e.Scp.LookUp("super")
+	parent, isStruct := parentObj.(*std.GoMixStruct)
+	thisObj, _ := e.Scp.LookUp("this")
+	this, isInstance := thisObj.(*std.GoMixObjectInstance)
+	if !ok || !isStruct || !isInstance {
+		return e.createError(n.Token, "ERROR: super can only be used in the methods of a struct that extends another struct")
+	}
+	if _, _, found := parent.LookUpMethod(ident.Name); found {
+		return &std.BoundMethod{Receiver: this, Name: ident.Name, Struct: parent}
+	}
+	if val, _, found := parent.LookUpField(ident.Name); found {
+		return val
+	}
+	return e.createError(ident.Token, "ERROR: method (%s) not found in parent struct (%s)", ident.Name, parent.Name)
+}
+
 // evalNewCallExpression evaluates a 'new' expression to instantiate a struct.
 //
 // This method handles object creation:
 // 1. Looks up the struct type
 // 2. Creates a new instance
-// 3. Calls the constructor ('init' method) if it exists
+// 3. Calls the constructor ('init' method, possibly inherited) if it exists
 //
 // Parameters:
 //   - n: The NewCallExpressionNode
@@ -86,6 +274,9 @@ func (e *Evaluator) evalStructDeclaration(n *parser.StructDeclarationNode) std.G
 func (e *Evaluator) evalNewCallExpression(n *parser.NewCallExpressionNode) std.GoMixObject {
 	// Look up the struct type by name
 	s, exists := e.Types[n.StructName.Name]
+	if !exists {
+		s, exists = e.lookUpStructType(n.StructName.Name)
+	}
 	if !exists {
 		return e.CreateError("ERROR: struct type '%s' not defined", n.StructName.Name)
 	}
@@ -93,7 +284,7 @@ func (e *Evaluator) evalNewCallExpression(n *parser.NewCallExpressionNode) std.G
 	inst := std.NewStructInstance(s)
 
 	// Initialize fields from struct definition
-	initMethod, hasInit := s.GetConstructor()
+	initMethod, owner, hasInit := s.LookUpMethod("init")
 	if hasInit {
 		// Cast to Function to access Body and Params directly
 		fn, ok := initMethod.(*function.Function)
@@ -101,62 +292,86 @@ func (e *Evaluator) evalNewCallExpression(n *parser.NewCallExpressionNode) std.G
 			return e.CreateError("ERROR: constructor method is not a valid function")
 		}
 
-		if len(n.Arguments) != len(fn.Params) {
-			return e.CreateError("ERROR: constructor for struct '%s' expects %d arguments, got %d", s.Name, len(fn.Params), len(n.Arguments))
+		argc := len(n.Arguments)
+		err := e.checkArguments(fn, argc, n.ArgNames, func(expected string) std.GoMixObject {
+			return e.CreateError("ERROR: constructor for struct '%s' expects %s arguments, got %d", s.Name, expected, argc)
+		})
+		if err != nil {
+			return err
 		}
 
-		// Save the current scope before creating a new one
-		oldScope := e.Scp
-
-		// Create a new scope for the constructor call
-		constructorScope := scope.NewScope(e.Scp)
+		// Create a new scope for the constructor call, parented on the scope
+		// the struct was declared in so that module globals stay visible
+		parentScope := e.Scp
+		if fn.Scp != nil {
+			parentScope = fn.Scp
+		}
+		constructorScope := scope.NewScope(parentScope)
 		constructorScope.Bind("this", inst) // Set 'this' to the new instance
+		bindSuper(constructorScope, owner)
 
 		// Evaluate the constructor with the given arguments
+		args := make([]std.GoMixObject, argc)
 		for i, arg := range n.Arguments {
-			argValue := e.Eval(arg)
-			if IsError(argValue) {
-				e.Scp = oldScope
-				return argValue
+			args[i] = e.Eval(arg)
+			if IsError(args[i]) {
+				return args[i]
 			}
-			constructorScope.Bind(fn.Params[i].Name, argValue)
 		}
-
-		// Switch to the constructor scope
-		e.Scp = constructorScope
+		if err := e.bindArguments(fn, constructorScope, namedParameters(args, n.ArgNames)); err != nil {
+			return err
+		}
 
 		// Execute the constructor body
-		result := e.Eval(fn.Body)
+		e.at(n.NewToken)
+		result := e.runFunctionBody(fn, s.Name+"."+fn.Name, constructorScope)
 		if IsError(result) {
-			e.Scp = oldScope
 			return result
 		}
-
-		// Restore the original scope
-		e.Scp = oldScope
 	}
 	return inst
 }
 
+// lookUpStructType resolves a struct type through the scope chain.
+//
+// This covers struct types that are not registered in e.Types, such as
+// structs exported by an imported module (new util.Point(...)) or structs
+// of a module whose methods are running on behalf of another evaluator.
+//
+// Parameters:
+//   - name: The struct name, optionally qualified by a package (e.g., "util.Point")
+//
+// Returns:
+//   - *std.GoMixStruct: The struct type, if found
+//   - bool: true if the struct type was found
+func (e *Evaluator) lookUpStructType(name string) (*std.GoMixStruct, bool) {
+	obj, ok := e.lookUpQualified(name)
+	if !ok {
+		return nil, false
+	}
+	s, ok := obj.(*std.GoMixStruct)
+	return s, ok
+}
+
 // callFunctionOnObject invokes a method on a struct instance.
 //
 // This method handles the mechanics of method dispatch:
-// 1. Looks up the method in the struct definition
+// 1. Looks up the method along the method resolution order (the struct, then its parents)
 // 2. Creates a new scope for the method execution
-// 3. Binds 'this' to the instance and 'self' to the struct type
-// 4. Binds arguments to parameters
+// 3. Binds 'this' to the instance, 'self' to the struct type and 'super' to the parent of the declaring struct
+// 4. Binds arguments to parameters (see bindArguments)
 // 5. Evaluates the method body
 //
 // Parameters:
-//   - name: The name of the method to call
-//   - obj: The struct instance on which the method is called
-//   - args: The arguments to pass to the method
+//   - method: The method to call and the instance on which it is called
+//   - args: The arguments to pass to the method, checked by checkArguments
 //
 // Returns:
 //   - objects.GoMixObject: The return value of the method
-func (e *Evaluator) callFunctionOnObject(name string, obj *std.GoMixObjectInstance, args ...NamedParameter) std.GoMixObject {
+func (e *Evaluator) callFunctionOnObject(method *std.BoundMethod, args ...NamedParameter) std.GoMixObject {
+	name, obj := method.Name, method.Receiver
 
-	initMethodInterface, exists := obj.Struct.Methods[name]
+	initMethodInterface, owner, exists := method.LookUp()
 	if !exists {
 		return e.CreateError("ERROR: method (%s) not found in struct (%s)", name, obj.Struct.GetName())
 	}
@@ -166,27 +381,67 @@ func (e *Evaluator) callFunctionOnObject(name string, obj *std.GoMixObjectInstan
 		return e.CreateError("ERROR: method (%s) not found in struct (%s)", name, obj.Struct.GetName())
 	}
 
-	// Create a new scope for the method call with the struct instance's scope as parent
-	methodScope := scope.NewScope(e.Scp)
+	// Create a new scope for the method call with the struct's declaring scope as parent
+	parentScope := e.Scp
+	if initMethod.Scp != nil {
+		parentScope = initMethod.Scp
+	}
+	methodScope := scope.NewScope(parentScope)
 
 	// Bind the struct instance to a special variable (e.g., "self") in the method scope
 	methodScope.Bind("this", obj)
 	methodScope.Bind("self", obj.Struct)
-	for _, arg := range args {
-		methodScope.Bind(arg.Name, arg.Value)
+	bindSuper(methodScope, owner)
+	if err := e.bindArguments(initMethod, methodScope, args); err != nil {
+		return err
 	}
 
-	// Save the current scope and switch to the method scope for evaluation
-	oldScope := e.Scp
-	e.Scp = methodScope
-	res := e.Eval(initMethod.Body)
-	e.Scp = oldScope
+	res := e.runFunctionBody(initMethod, owner.GetName()+"."+name, methodScope)
 	if res.GetType() == std.ErrorType {
 		return res
 	}
 	return UnwrapReturnValue(res)
 }
 
+// CallMethod calls a method of a struct instance with positional arguments.
+// This implements the std.Runtime interface.
+func (e *Evaluator) CallMethod(obj *std.GoMixObjectInstance, name string, args ...std.GoMixObject) std.GoMixObject {
+	method := &std.BoundMethod{Receiver: obj, Name: name}
+	if err := e.checkArgumentCount(method, len(args), nil); err != nil {
+		return err
+	}
+	return e.callFunctionOnObject(method, namedParameters(args, nil)...)
+}
+
+// SetField assigns a field of a struct instance, checking the annotated type
+// of the field like a member assignment (obj.name = val) does.
+// This implements the std.Runtime interface.
+func (e *Evaluator) SetField(obj *std.GoMixObjectInstance, name string, val std.GoMixObject) std.GoMixObject {
+	if val = e.conformField(obj.Struct, name, val); IsError(val) {
+		return val
+	}
+	obj.InstanceFields[name] = val
+	return nil
+}
+
+// LookUpType resolves the name of a struct, interface or enum type, which may
+// be qualified by a package (e.g., "util.Point").
+// This implements the std.Runtime interface.
+func (e *Evaluator) LookUpType(name string) (std.GoMixObject, bool) {
+	if s, ok := e.Types[name]; ok {
+		return s, true
+	}
+	obj, ok := e.lookUpQualified(name)
+	if !ok {
+		return nil, false
+	}
+	switch obj.(type) {
+	case *std.GoMixStruct, *std.GoMixInterface, *std.GoMixEnum:
+		return obj, true
+	}
+	return nil, false
+}
+
 // evalEnumDeclaration evaluates an enum declaration statement.
 //
 // This method processes enum declarations by:
@@ -6,15 +6,31 @@ Contact : akashmaji(@iisc.ac.in)
 package eval
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/akashmaji946/go-mix/parser"
 	"github.com/akashmaji946/go-mix/std"
 )
 
+// useVM runs the whole suite on the bytecode VM: go test ./eval -vm
+var useVM = flag.Bool("vm", false, "run the evaluator tests on the bytecode VM")
+
+// TestMain selects the execution backend for every evaluator created by the tests
+func TestMain(m *testing.M) {
+	flag.Parse()
+	VMByDefault = *useVM
+	os.Exit(m.Run())
+}
+
 // TestEvaluator_Ints verifies integer literal evaluation and arithmetic operations
 func TestEvaluator_Ints(t *testing.T) {
 	tests := []struct {
@@ -177,6 +193,45 @@ func TestEvaluator_Strings(t *testing.T) {
 	}
 }
 
+// TestEvaluator_StringInterpolation verifies interpolated strings, raw strings and unicode escapes
+func TestEvaluator_StringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var name = "go"; "Hello ${name}!"`, "Hello go!"},
+		{`var items = [1, 2, 3]; "${length(items)} items: ${items}"`, "3 items: [1, 2, 3]"},
+		{`struct U { func init(n) { this.name = n; } } var u = new U("ann"); "${u.name}, ${1 + 2 * 3}, ${true}, ${nil}"`, "ann, 7, true, nil"},
+		{`"outer ${"inner ${1 + 1}"} ${map{"k": 'v'}["k"]}"`, "outer inner 2 v"},
+		{`"${""}" + "\${x}"`, "${x}"},
+		{"`C:\\dir\n${x}`", "C:\\dir\n${x}"},
+		{`"caf\u{e9} \x41 \u{1F600}"`, "café A 😀"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("%s: parser errors: %v", tt.input, p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		if result.GetType() != std.StringType {
+			t.Fatalf("%s: expected %s, got %s", tt.input, std.StringType, result.ToString())
+		}
+		if result.(*std.String).Value != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, result.(*std.String).Value)
+		}
+	}
+
+	p := parser.NewParser(`"value: ${missing}"`)
+	rootNode := p.Parse()
+	evaluator := NewEvaluator()
+	evaluator.SetParser(p)
+	AssertError(t, evaluator.Eval(rootNode), "ERROR: identifier not found: (missing)")
+}
+
 // TestEvaluator_IntExpr verifies integer expressions including arithmetic, bitwise, and unary operations
 func TestEvaluator_IntExpr(t *testing.T) {
 	tests := []struct {
@@ -611,6 +666,189 @@ func TestEvaluator_FuncArgErr(t *testing.T) {
 	}
 }
 
+// TestEvaluator_CallExpressions verifies calls on callees other than names:
+// returned closures, indexed values, members, function literals, and
+// builtins, package functions and methods used as values
+func TestEvaluator_CallExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`func makeAdder(a) { return func(b) { return a + b; }; } println(makeAdder(1)(2));`, "3\n"},
+		{`var handlers = map{"up": func(s) { return upper(s); }}; println(handlers["up"]("req"));`, "REQ\n"},
+		{`var fs = [func() { return 1; }, func() { return 2; }]; println(fs[1]());`, "2\n"},
+		{`struct In { func hi() { return "hi"; } } struct Out { func init() { this.inner = new In(); } }
+println(new Out().inner.hi());`, "hi\n"},
+		{`println((func(x) { return x * x; })(3));`, "9\n"},
+		{`println(map_array(["a", "b"], upper));`, "[A, B]\n"},
+		{`var f = length; println(f([1, 2, 3]), f);`, "3 builtin length\n"},
+		{`import math; println(map_array([-1, 2], math.abs));`, "[1, 2]\n"},
+		{`struct P { func init(v) { this.v = v; } func add(x) { return x + this.v; } }
+var p = new P(10); var add = p.add; println(add(1), add, map_array([1, 2], p.add));`, "11 method P.add [11, 12]\n"},
+		{`struct S { func init() { this.f = func(x) { return x + 1; }; } } println(new S().f(1));`, "2\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`var x = 1; x(2)`, "ERROR: not a function: (x)"},
+		{`[1, 2][0]()`, "ERROR: not a function: ([1,2][0])"},
+		{`func f() { return 1; } f()(2)`, "ERROR: not a function: (f())"},
+		{`nowhere(1)`, "ERROR: function not found: (nowhere)"},
+		{`struct P { func m(a) { return a; } } new P().m()`, "ERROR: wrong number of arguments for method (m): expected 1, got 0"},
+		{`struct P { var x = 1; } new P().y()`, "ERROR: method (y) does not exist in struct (P)"},
+		{`import math; math.nope(1)`, "ERROR: function 'nope' not found in package 'math'"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_FunctionParameters verifies default and rest parameters and named arguments
+func TestEvaluator_FunctionParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`func f(a, b = 10) { return a + b; } println(f(1), f(1, 2));`, "11 3\n"},
+		{`func f(a, b = 10) { return a - b; } println(f(b: 2, a: 1), f(5, b: 1));`, "-1 4\n"},
+		{`func f(a, b = a * 2) { return b; } println(f(3));`, "6\n"},
+		{`func sum(...nums) { var t = 0; foreach n in nums { t += n; } return t; } println(sum(), sum(1, 2, 3));`, "0 6\n"},
+		{`func f(x, ...more) { return more; } println(f(1), f(1, 2, 3));`, "[] [2, 3]\n"},
+		{`var greet = func(name = "world") { return "hello " + name; }; println(greet(), greet(name: "go"));`, "hello world hello go\n"},
+		{`println(map_array([1, 2], func(x, y = 10) { return x + y; }));`, "[11, 12]\n"},
+		{`struct P { func init(x = 0, y = 0) { this.x = x; this.y = y; } func moved(dx = 1, dy = 1) { return [this.x + dx, this.y + dy]; } }
+var p = new P(y: 3); println(p.x, p.y, p.moved(dy: 5), new P(1, 2).moved());`, "0 3 [1, 8] [2, 3]\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`func f(a, b = 1) {} f()`, "ERROR: wrong number of arguments: expected 1 to 2, got 0"},
+		{`func f(a, b = 1) {} f(1, 2, 3)`, "ERROR: wrong number of arguments: expected 1 to 2, got 3"},
+		{`func f(a, ...r) {} f()`, "ERROR: wrong number of arguments: expected at least 1, got 0"},
+		{`func f(a) {} f(b: 1)`, "ERROR: unknown argument (b) in call to (f)"},
+		{`func f(a) {} f(1, a: 2)`, "ERROR: argument (a) given more than once in call to (f)"},
+		{`func f(a, b) {} f(b: 1)`, "ERROR: missing argument (a) in call to (f)"},
+		{`println(x: 1)`, "ERROR: builtin (println) does not take named arguments"},
+		{`struct S { func init(a, b = 2) {} } new S()`, "ERROR: constructor for struct 'S' expects 1 to 2 arguments, got 0"},
+		{`struct S { func m(a) {} } new S().m(c: 1)`, "ERROR: unknown argument (c) in call to (m)"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_Destructuring verifies multiple return values and destructuring declarations and assignments
+func TestEvaluator_Destructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`func divmod(a, b) { return a / b, a % b; } var (q, r) = divmod(7, 2); println(q, r, divmod(9, 4));`, "3 1 tuple(2, 1)\n"},
+		{`let [first, ...rest] = [1, 2, 3]; println(first, rest);`, "1 [2, 3]\n"},
+		{`var [a, b] = list(1, 2); var (c) = "x"; println(a, b, c);`, "1 2 x\n"},
+		{`var {name, age} = map{"name": "ann", "age": 30}; println(name, age);`, "ann 30\n"},
+		{`const {city, ...others} = map{"a": 1, "city": "x", "b": 2}; println(city, others);`, "x map{a: 1, b: 2}\n"},
+		{`var {missing} = map{}; println(missing);`, "nil\n"},
+		{`var a = 1; var b = 2; (a, b) = (b, a); println(a, b);`, "2 1\n"},
+		{`var x = 0; var y = 0; [x, ...y] = 1...3; println(x, y);`, "1 [2, 3]\n"},
+		{`struct P { func init(x, y) { this.x = x; this.y = y; } } var {x, y} = new P(1, 2); println(x, y);`, "1 2\n"},
+		{`var sums = []; foreach [x, y] in [[1, 2], [3, 4]] { push(sums, x + y); } println(sums);`, "[3, 7]\n"},
+		{`foreach i, (k, v) in [(1, 2)] { println(i, k, v); }`, "0 1 2\n"},
+		{`var ns = []; foreach {n} in [map{"n": 1}, map{"n": 2}] { push(ns, n); } println(ns);`, "[1, 2]\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`var (a, b) = (1, 2, 3);`, "ERROR: cannot destructure 3 values into 2 names"},
+		{`var [a, b, ...c] = [1];`, "ERROR: cannot destructure 1 values into at least 2 names"},
+		{`var (a, b) = 5;`, "ERROR: cannot destructure `int` with pattern (a,b)"},
+		{`var {a} = [1];`, "ERROR: cannot destructure `array` with pattern {a}"},
+		{`struct P { var x = 1; } var {x, ...r} = new P();`, "ERROR: rest name (...r) cannot take the fields of a struct instance"},
+		{`const (a, b) = (1, 2); a = 3;`, "ERROR: can't assign to constant (a)"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
 // TestEvaluator_Let verifies let keyword variable declaration evaluation
 func TestEvaluator_Let(t *testing.T) {
 	tests := []struct {
@@ -1104,8 +1342,12 @@ func TestEvaluator_ForeachError(t *testing.T) {
 			"ERROR: foreach requires an `iterable`, got `int`",
 		},
 		{
-			`foreach i in "hello" { }`,
-			"ERROR: foreach requires an `iterable`, got `string`",
+			`foreach i in 1.5 { }`,
+			"ERROR: foreach requires an `iterable`, got `float`",
+		},
+		{
+			`struct P { var x = 0; } foreach i in new P() { }`,
+			"ERROR: foreach requires an `iterable`, got `object`",
 		},
 		{
 			`foreach i in true { }`,
@@ -1269,8 +1511,8 @@ func TestEvaluator_SetInsert(t *testing.T) {
 	}
 
 	setObj := result.(*std.Set)
-	if len(setObj.Values) != 3 {
-		t.Errorf("expected 3 values, got %d", len(setObj.Values))
+	if setObj.Len() != 3 {
+		t.Errorf("expected 3 values, got %d", setObj.Len())
 	}
 }
 
@@ -1441,6 +1683,270 @@ func TestEvaluator_MapSetErrors(t *testing.T) {
 	}
 }
 
+// TestEvaluator_HashableKeys verifies that map keys and set elements keep
+// their types, and the keys that can be hashed
+func TestEvaluator_HashableKeys(t *testing.T) {
+	decls := `
+enum Color { RED, GREEN }
+struct Point {
+    func init(x, y) { this.x = x; this.y = y; }
+    func __hash__() { return (this.x, this.y); }
+}
+struct Plain { var v = 1; }
+struct Self { func __hash__() { return this; } }
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var m = map{1: "a", "1": "b"}; println(length(m), m[1], m["1"]);`, "2 a b\n"},
+		{`var m = map{1: "a", 1.5: "b", 'c': "c", true: "d", nil: "e"}; println(m[1], m[1.5], m['c'], m[true], m[nil], m[2]);`, "a b c d e nil\n"},
+		{`var m = map{1: "a", "k": "b"}; foreach k in keys_map(m) { print(typeof(k), ""); }`, "int string "},
+		{`var m = map{2: "a"}; foreach k, v in m { println(typeof(k)); }`, "int\n"},
+		{`var m = map{3: "x"}; println(typeof(enumerate_map(m)[0][0]));`, "int\n"},
+		{`var m = map{}; insert_map(m, 1, "a"); insert_map(m, "1", "b"); m[1] = "c"; println(m);`, "map{1: c, 1: b}\n"},
+		{`var m = map{1: "a", "1": "b"}; println(remove_map(m, 1), contain_map(m, 1), contain_map(m, "1"));`, "a false true\n"},
+		{`var m = map{"a": 1, 2: 2, "c": 3}; m[2] = 20; println(keys_map(m), values_map(m));`, "[a, 2, c] [1, 20, 3]\n"},
+		{`var g = map{}; g[(0, 1)] = "door"; g[(0, 1)] = "open"; println(length(g), g[(0, 1)], g[(1, 0)]);`, "1 open nil\n"},
+		{`var m = map{(1, (2, "a")): "nested"}; println(m[(1, (2, "a"))], m[(1, (2, 'a'))]);`, "nested nil\n"},
+		{`var m = map{Color.RED: "red", Color: "enum"}; println(m[Color.RED], m[0], m[Color]);`, "red red enum\n"},
+		{`var s = set{new Point(1, 2), new Point(1, 2), new Point(2, 1)}; println(length(s), contains_set(s, new Point(2, 1)));`, "2 true\n"},
+		{`var p = new Point(1, 2); var m = map{p: "p"}; println(m[new Point(1, 2)], keys_map(m)[0] === p);`, "p true\n"},
+		{`var s = set{1, "1", 2, 2}; println(length(s), contains_set(s, "2"));`, "3 false\n"},
+		{`var s = set{1, "a"}; foreach v in s { print(typeof(v), ""); } println(typeof(values_set(s)[0]));`, "int string int\n"},
+		{`var s = make_set(1, 1, (1, 2)); insert_set(s, (1, 2)); println(s, array(s));`, "set{1, tuple(1, 2)} [1, tuple(1, 2)]\n"},
+		{`var m: map<string, int> = map{"a": 1}; var s: set<int> = set{1, 2}; println(m, s);`, "map{a: 1} set{1, 2}\n"},
+		{`var {a, ...rest} = map{"a": 1, 2: "b"}; println(a, rest, typeof(keys_map(rest)[0]));`, "1 map{2: b} int\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(decls + tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`map{[1]: 2};`, "ERROR: unhashable type 'array': map keys and set elements must be"},
+		{`set{map{}};`, "ERROR: unhashable type 'map'"},
+		{`var m = map{}; m[[1]] = 2;`, "ERROR: unhashable type 'array'"},
+		{`var m = map{}; m[list(1)];`, "ERROR: unhashable type 'list'"},
+		{`map{(1, [2]): 3};`, "ERROR: unhashable type 'array'"},
+		{`set{new Plain()};`, "ERROR: unhashable type 'Plain': struct (Plain) does not declare __hash__"},
+		{`set{new Self()};`, "ERROR: __hash__ of struct (Self) must return a hashable value, got object"},
+		{`insert_map(map{}, [1], 2);`, "ERROR: unhashable type 'array'"},
+		{`contain_map(map{}, set{});`, "ERROR: unhashable type 'set'"},
+		{`make_set(1, [2]);`, "ERROR: unhashable type 'array'"},
+		{`contains_set(set{}, func() {});`, "ERROR: unhashable type 'func'"},
+		{`var m: map<string, int> = map{1: 1};`, "ERROR: can't assign `map` to variable (m) of type `map<string, int>`"},
+		{`var s: set<int> = set{"a"};`, "ERROR: can't assign `set` to variable (s) of type `set<int>`"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(decls + tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+
+	// an unhashable key is reported at the key, on both backends
+	positionTests := []struct {
+		input    string
+		expected string
+	}{
+		{"var x = 1;\nvar m = map{\"a\": 1,  [1]: 2};", "[2:23] ERROR: unhashable type 'array'"},
+		{"var s = set{1,\n   [2]};", "[2:5] ERROR: unhashable type 'array'"},
+		{"var m = map{};\n  m[[1]] = 2;", "[2:5] ERROR: unhashable type 'array'"},
+	}
+	for _, vm := range []bool{false, true} {
+		for _, tt := range positionTests {
+			p := parser.NewParser(tt.input)
+			rootNode := p.Parse()
+			if p.HasErrors() {
+				t.Fatalf("parser errors: %v", p.GetErrors())
+			}
+			evaluator := NewEvaluator()
+			evaluator.SetParser(p)
+			evaluator.UseVM = vm
+			result := evaluator.Eval(rootNode)
+			if err, ok := result.(*std.Error); !ok || !strings.HasPrefix(err.Message, tt.expected) {
+				t.Errorf("vm=%v %q: expected an error starting with %q, got %s", vm, tt.input, tt.expected, result.ToString())
+			}
+		}
+	}
+}
+
+// TestEvaluator_BigNumbers verifies bigints (int overflow, n literals) and
+// decimals in arithmetic, comparisons, conversions, printf and the math package
+func TestEvaluator_BigNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var max = 9223372036854775807; println(max + 1, typeof(max + 1), typeof(max));`, "9223372036854775808 bigint int\n"},
+		{`println(-9223372036854775807 - 2, 9223372036854775807 * 2, 3037000500 * 3037000500);`, "-9223372036854775809 18446744073709551614 9223372037000250000\n"},
+		{`println(1 << 70, (-9223372036854775807 - 1) / -1);`, "1180591620717411303424 9223372036854775808\n"},
+		{`println(9223372036854775808, typeof(9223372036854775808), 18446744073709551615, typeof(0xFFFFFFFFFFFFFFFF));`, "9223372036854775808 bigint 18446744073709551615 bigint\n"},
+		{`println(-9223372036854775808, typeof(-9223372036854775808), -(-9223372036854775807 - 1), typeof(-(-9223372036854775807 - 1)));`, "-9223372036854775808 int 9223372036854775808 bigint\n"},
+		{`var min = -9223372036854775807 - 1; println(-min, typeof(-min), -(min + 1), typeof(-(min + 1)));`, "9223372036854775808 bigint 9223372036854775807 int\n"},
+		{`func fact(n) { if (n <= 1) { return 1; } return n * fact(n - 1); } println(fact(25), typeof(fact(20)));`, "15511210043330985984000000 int\n"},
+		{`println(123n, typeof(123n), 0xFFn, typeof(100000000000000000000), 100000000000000000000);`, "123 bigint 255 bigint 100000000000000000000\n"},
+		{`println(7n / 2, -7n % 2, 5n & 3, 5n | 2, 5n ^ 1, 1n << 64, (1n << 64) >> 63, -5n, ~5n, +5n);`, "3 -1 1 7 4 18446744073709551616 2 -5 -6 5\n"},
+		{`println(2n + 0.5, typeof(1n + 1), 10n == 10, 10n != 10, 10n > 9.5, 10n < 11, 10n === 10, 10n === 10n);`, "2.500000 bigint true false true true false true\n"},
+		{`var d = decimal("19.99"); println(d, typeof(d), d * 3, d + 1, d - decimal("0.009"), -d);`, "19.99 decimal 59.97 20.99 19.981 -19.99\n"},
+		{`println(decimal("10.00") / 3, decimal(1, 4) / 3, decimal("2.00") / 3, decimal("-2.00") / 3, decimal("7.5") % 2);`, "3.33 0.3333 0.67 -0.67 1.5\n"},
+		{`println(decimal("0.1") + decimal("0.2") === decimal("0.3"), 0.1 + 0.2 === 0.3);`, "true false\n"},
+		{`println(decimal("1.50") == decimal("1.5"), decimal("1.5") == 1.5, decimal(2) == 2, decimal("1.5") < 2n, decimal("1.5") > 1.25);`, "true true true true true\n"},
+		{`println(decimal(5, 2), decimal(2.675, 2), decimal(0.1), decimal("-0.005", 2), decimal(12n), decimal("1_000.5"));`, "5.00 2.68 0.1 -0.01 12 1000.5\n"},
+		{`println(to_int(12n), to_float(decimal("2.5")), to_int(decimal("-3.99")), to_bool(0n), to_bool(decimal("0.01")));`, "12 2.500000 -3 false true\n"},
+		{`println(bigint("123456789012345678901234567890"), bigint(42), typeof(bigint(42)), bigint(2.5e20), bigint(decimal("-7.9")));`, "123456789012345678901234567890 42 bigint 250000000000000000000 -7\n"},
+		{`printf("%d %x %.2f %v %8.3f|%-6.1f|%06.2f\n", 1n << 70, 255n, decimal("19.995"), decimal("1.50"), decimal("2.5"), decimal("2.25"), decimal("-1.5"));`, "1180591620717411303424 ff 20.00 1.50    2.500|2.3   |-01.50\n"},
+		{`println(sprintf("%s|%5d|%.1f", decimal("0.10"), 42n, 10n));`, "0.10|   42|10.0\n"},
+		{`println(abs(-3n), abs(decimal("-2.50")), abs(-9223372036854775807 - 1), min(3n, 2), max(decimal("1.1"), 1));`, "3 2.50 9223372036854775808 2 1.1\n"},
+		{`println(floor(decimal("-2.5")), ceil(decimal("2.1")), floor(decimal("3.00")), round(decimal("2.675"), 2), round(decimal("-2.5")));`, "-3 3 3 2.68 -3\n"},
+		{`println(pow(2n, 100), pow(decimal("1.05"), 2), sqrt(16n), pow(2, 3));`, "1267650600228229401496703205376 1.10 4.000000 8.000000\n"},
+		{`var m = map{1n: "a", decimal("1.5"): "b"}; println(m[1n], m[decimal("1.50")], m[1]);`, "a b nil\n"},
+		{`println(sort([3n, 1, decimal("2.5"), 0.5]));`, "[0.500000, 1, 2.5, 3]\n"},
+		{`var x: int = 9223372036854775807 * 2; println(x);`, "18446744073709551614\n"},
+		{`switch (10n) { case 10: println("ten"); break; default: println("other"); }`, "ten\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`decimal("1.5") + 0.5;`, "ERROR: operator (+) cannot mix decimal and float: convert the float with decimal(x)"},
+		{`1n / 0;`, "ERROR: division by zero"},
+		{`decimal("1.5") % decimal("0.0");`, "ERROR: division by zero"},
+		{`1n << -1;`, "ERROR: shift count must be an int from 0 to"},
+		{`1.5 & 1n;`, "ERROR: operator (&) not implemented for (float) and (bigint)"},
+		{`decimal("1.5") << 1;`, "ERROR: operator (<<) not implemented for (decimal) and (int)"},
+		{`~decimal("1");`, "ERROR: operator (~) not implemented for (decimal)"},
+		{`to_int(1n << 64);`, "ERROR: bigint 18446744073709551616 is too large for an int"},
+		{`decimal("abc");`, "ERROR: could not convert string to decimal"},
+		{`decimal(1, -1);`, "ERROR: scale of a decimal must be an int from 0 to 1000, got -1"},
+		{`bigint("12x");`, "ERROR: could not convert string to bigint"},
+		{`pow(2n, -1);`, "ERROR: exponent of a bigint in `pow` must be a non-negative int"},
+		{`var x: bigint = 1;`, "ERROR: can't assign `int` to variable (x) of type `bigint`"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_Bytes verifies the bytes type: indexing, slicing, concatenation,
+// conversions, pack/unpack and the "bytes" result mode of crypto builtins.
+func TestEvaluator_Bytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var b = bytes("hi") + bytes([0, 255]); println(b, length(b), typeof(b), b[1], b[-1], b[1:3]);`, "b\"hi\\x00\\xff\" 4 bytes 105 255 b\"i\\x00\"\n"},
+		{`println(decode_bytes(bytes("héllo")[0:3]), bytes(3), bytes('A'));`, "hé b\"\\x00\\x00\\x00\" b\"A\"\n"},
+		{`var p = pack("<HI", 258, 1); println(p, size_pack(">q2d"), unpack("<HI", p));`, "b\"\\x02\\x01\\x01\\x00\\x00\\x00\" 24 tuple(258, 1)\n"},
+		{`println(unpack(">f", pack(">f", 1.5)), unpack("!b", pack("b", -2)), unpack(">Q", bytes([255, 255, 255, 255, 255, 255, 255, 255])));`, "tuple(1.500000) tuple(-2) tuple(18446744073709551615)\n"},
+		{`var (tag, n) = unpack(">BxH", pack(">BxH", 7, 513)); println(tag, n, unpack("B", bytes([9, 8]), 1));`, "7 513 tuple(8)\n"},
+		{`println(join_bytes([bytes("a"), bytes("b")], bytes(",")), index_bytes(bytes("hello"), bytes("ll")), index_bytes(bytes("a"), bytes("z")));`, "b\"a,b\" 2 -1\n"},
+		{`var s = 0; foreach i, v in bytes("AB") { s = s + i + v; } println(s, array(bytes("AB")));`, "132 [65, 66]\n"},
+		{`println(bytes("a") == bytes("a"), bytes("a") === bytes("a"), bytes("a") === "a", map{bytes("k"): 1}[bytes("k")]);`, "true true false 1\n"},
+		{`import crypto; println(crypto.hex_encode(bytes([1, 171])), crypto.hex_decode("01ab", "bytes"), crypto.base64_decode("aGk=", "bytes"), length(crypto.md5("x", "bytes")), crypto.md5(bytes("x")) == crypto.md5("x"));`, "01ab b\"\\x01\\xab\" b\"hi\" 16 true\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`pack("B", 256);`, "ERROR: `pack` format 'B' requires 0 <= value <= 255, got 256"},
+		{`pack("BB", 1);`, "ERROR: pack format \"BB\" expects 2 values, got 1"},
+		{`pack("z", 1);`, "ERROR: invalid format for `pack`: unknown type code 'z'"},
+		{`unpack(">I", bytes(2));`, "ERROR: unpack format \">I\" needs 4 bytes, got 2"},
+		{`unpack("B", bytes(1), 2);`, "ERROR: offset of `unpack` must be an int from 0 to 1, got 2"},
+		{`bytes([300]);`, "ERROR: elements of bytes must be ints from 0 to 255, got 300"},
+		{`decode_bytes(bytes([255]));`, "ERROR: bytes are not valid UTF-8"},
+		{`bytes("a") - bytes("b");`, "ERROR: operator (-) not implemented for (bytes) and (bytes)"},
+		{`bytes("a") + "b";`, "ERROR: operator (+) not implemented for (bytes) and (string)"},
+		{`"a" + bytes("b");`, "ERROR: operator (+) not implemented for (string) and (bytes)"},
+		{`var b = bytes("a"); b += "b";`, "ERROR: operator (+) not implemented for (bytes) and (string)"},
+		{`bytes("a")[5];`, "ERROR: index out of bounds: index 5, length 1"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
 // TestEvaluator_ListInsert verifies insert_list function with various indices
 func TestEvaluator_ListInsert(t *testing.T) {
 	tests := []struct {
@@ -1974,35 +2480,141 @@ func TestEvaluator_ForeachTuple(t *testing.T) {
 	}
 }
 
-// TestEvaluator_ListNested verifies nested list operations
-func TestEvaluator_ListNested(t *testing.T) {
-	src := `var matrix = list(list(1, 2), list(3, 4)); matrix[0][1]`
-	p := parser.NewParser(src)
-	rootNode := p.Parse()
-	evaluator := NewEvaluator()
-	evaluator.SetParser(p)
-	result := evaluator.Eval(rootNode)
-	AssertInteger(t, result, 2)
-}
+// TestEvaluator_ForeachIterables verifies foreach loops over strings, maps and
+// sets, and with a key (index) variable
+func TestEvaluator_ForeachIterables(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var s = ""; foreach ch in "abc" { s = ch + s; } s`, "cba"},
+		{`var s = ""; foreach i, ch in "xy" { s += to_string(i) + ch; } s`, "0x1y"},
+		{`var s = ""; foreach k, v in map{"a": 1, "b": 2} { s += k + to_string(v); } s`, "a1b2"},
+		{`var s = ""; foreach k in map{"a": 1, "b": 2} { s += to_string(k); } s`, "12"},
+		{`var s = ""; foreach i, x in [10, 20] { s += to_string(i) + ":" + to_string(x) + " "; } s`, "0:10 1:20 "},
+		{`var s = ""; foreach v in set{"p", "q"} { s += v; } s`, "pq"},
+		{`var s = ""; foreach i, v in 3...1 { s += to_string(i) + to_string(v); } s`, "031221"},
+	}
 
-// TestEvaluator_TupleNested verifies nested tuple operations
-func TestEvaluator_TupleNested(t *testing.T) {
-	src := `var nested = tuple(tuple(1, 2), tuple(3, 4)); nested[1][0]`
-	p := parser.NewParser(src)
-	rootNode := p.Parse()
-	evaluator := NewEvaluator()
-	evaluator.SetParser(p)
-	result := evaluator.Eval(rootNode)
-	AssertInteger(t, result, 3)
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertString(t, result, tt.expected)
+	}
 }
 
-// TestEvaluator_ListMixed verifies lists with mixed types
-func TestEvaluator_ListMixed(t *testing.T) {
-	src := `var l = list(1, "hello", true); length(l)`
-	p := parser.NewParser(src)
-	rootNode := p.Parse()
-	evaluator := NewEvaluator()
-	evaluator.SetParser(p)
+// TestEvaluator_ForeachStructIterator verifies the iter()/next() protocol of
+// struct instances
+func TestEvaluator_ForeachStructIterator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		// next() yields values until it returns nil
+		{`struct C { var n = 0; func next() { if (this.n == 3) { return nil; } this.n = this.n + 1; return this.n; } }
+		  var sum = 0; foreach x in new C() { sum += x; } sum`, 6},
+		// iter() may return any iterable
+		{`struct B { var items = [4, 5]; func iter() { return this.items; } }
+		  var sum = 0; foreach x in new B() { sum += x; } sum`, 9},
+		// iter() may return an instance with next(), here the instance itself
+		{`struct R { var i = 0; func iter() { this.i = 0; return this; } func next() { if (this.i == 2) { return nil; } this.i = this.i + 1; return this.i; } }
+		  var r = new R(); var sum = 0; foreach x in r { sum += x; } foreach i, x in r { sum += i * 10; } sum`, 13},
+		// break stops calling next()
+		{`struct C { var n = 0; func next() { this.n = this.n + 1; return this.n; } }
+		  var c = new C(); foreach x in c { if (x == 5) { break; } } c.n`, 5},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertInteger(t, result, tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`struct B { func iter() { return 5; } } foreach x in new B() { }`, "ERROR: iter() of struct (B) must return an iterable, got 'int'"},
+		{`struct P { } struct B { func iter() { return new P(); } } foreach x in new B() { }`, "ERROR: iter() of struct (B) returned an instance of (P), which has no next() method"},
+		{`struct C { func next() { return 1 / 0; } } foreach x in new C() { }`, "division by zero"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_IterableBuiltins verifies that the higher-order array and list
+// builtins accept any iterable
+func TestEvaluator_IterableBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`length(map_array(list(1, 2, 3), func(x) { return x; }))`, 3},
+		{`reduce_array(1...4, func(acc, x) { return acc + x; }, 0)`, 10},
+		{`length(filter_list(map{"a": 1, "b": 2}, func(v) { return v > 1; }))`, 1},
+		{`reduce_list(tuple(1, 2, 3), func(acc, x) { return acc * x; })`, 6},
+		{`struct B { func iter() { return [1, 2, 3]; } } find_list(new B(), func(x) { return x > 1; })`, 2},
+		{`length(to_list("abc"))`, 3},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertInteger(t, result, tt.expected)
+	}
+
+	p := parser.NewParser(`map_array(5, func(x) { return x; })`)
+	rootNode := p.Parse()
+	evaluator := NewEvaluator()
+	evaluator.SetParser(p)
+	AssertError(t, evaluator.Eval(rootNode), "ERROR: first argument to `map_array` must be an iterable, got 'int'")
+}
+
+// TestEvaluator_ListNested verifies nested list operations
+func TestEvaluator_ListNested(t *testing.T) {
+	src := `var matrix = list(list(1, 2), list(3, 4)); matrix[0][1]`
+	p := parser.NewParser(src)
+	rootNode := p.Parse()
+	evaluator := NewEvaluator()
+	evaluator.SetParser(p)
+	result := evaluator.Eval(rootNode)
+	AssertInteger(t, result, 2)
+}
+
+// TestEvaluator_TupleNested verifies nested tuple operations
+func TestEvaluator_TupleNested(t *testing.T) {
+	src := `var nested = tuple(tuple(1, 2), tuple(3, 4)); nested[1][0]`
+	p := parser.NewParser(src)
+	rootNode := p.Parse()
+	evaluator := NewEvaluator()
+	evaluator.SetParser(p)
+	result := evaluator.Eval(rootNode)
+	AssertInteger(t, result, 3)
+}
+
+// TestEvaluator_ListMixed verifies lists with mixed types
+func TestEvaluator_ListMixed(t *testing.T) {
+	src := `var l = list(1, "hello", true); length(l)`
+	p := parser.NewParser(src)
+	rootNode := p.Parse()
+	evaluator := NewEvaluator()
+	evaluator.SetParser(p)
 	result := evaluator.Eval(rootNode)
 	AssertInteger(t, result, 3)
 }
@@ -2568,6 +3180,562 @@ func TestEvaluator_StructFibonacciRecursive(t *testing.T) {
 	AssertInteger(t, result, 8)
 }
 
+// TestEvaluator_StructInheritance verifies extends, super calls, interfaces and instanceof
+func TestEvaluator_StructInheritance(t *testing.T) {
+	decls := `
+interface Speaker { speak(); }
+interface Named { name(); }
+struct Animal implements Named {
+    var count = 0;
+    func init(name) { this.n = name; Animal.count += 1; }
+    func name() { return this.n; }
+    func speak() { return "..."; }
+    func describe() { return this.name() + ": " + this.speak(); }
+}
+struct Dog extends Animal implements Speaker {
+    func init(name) { super.init(name); this.tricks = []; }
+    func speak() { return "woof " + super.speak(); }
+}
+struct Puppy extends Dog {
+    func speak() { return super.speak() + "!"; }
+}
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var d = new Dog("rex"); println(d.describe(), d.tricks);`, "rex: woof ... []\n"},
+		{`var p = new Puppy("bit"); println(p.describe());`, "bit: woof ...!\n"},
+		{`var f = new Puppy("bit").speak; println(f());`, "woof ...!\n"},
+		{`var d = new Dog("a"); var p = new Puppy("b"); println(Animal.count, Dog.count, Puppy.count);`, "2 2 2\n"},
+		{`Puppy.count = 5; println(Animal.count);`, "5\n"},
+		{`var p = new Puppy("b"); println(p instanceof Puppy, p instanceof Dog, p instanceof Animal);`, "true true true\n"},
+		{`var d = new Dog("a"); println(d instanceof Puppy, new Animal("x") instanceof Dog, 5 instanceof Dog);`, "false false false\n"},
+		{`var d = new Dog("a"); println(d instanceof Speaker, d instanceof Named, new Animal("x") instanceof Speaker);`, "true true false\n"},
+		{`println(typeof(new Puppy("b")), typeof(new Animal("a")));`, "object object\n"},
+		{`println(typeof(Dog), typeof(Speaker));`, "struct interface\n"},
+		{`struct Plain {} println(typeof(new Plain()), typeof(Plain));`, "object struct\n"},
+		{`println(hierarchy(new Puppy("b")));`, "Puppy < Dog < Animal implements Speaker, Named\n"},
+		{`struct Plain {} println(hierarchy(Dog), hierarchy(new Plain()), hierarchy(Animal));`, "Dog < Animal implements Speaker, Named Plain Animal implements Named\n"},
+		{`struct Quiet extends Dog { func speak() { return "..."; } } println(new Quiet("q") instanceof Speaker);`, "true\n"},
+		{`var items = [new Dog("a"), 1, new Animal("b")]; var n = 0; foreach x in items { if (x instanceof Animal) { n += 1; } } println(n);`, "2\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(decls + tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`struct Cat implements Speaker { func meow() {} }`, "ERROR: struct (Cat) does not implement interface (Speaker): missing method (speak)"},
+		{`struct Cat implements Speaker { func speak(loud) {} }`, "ERROR: struct (Cat) does not implement interface (Speaker): method (speak) must take 0 arguments"},
+		{`struct Cat extends Lion {}`, "ERROR: struct (Cat) cannot extend (Lion): no such struct"},
+		{`struct Cat implements Animal {}`, "ERROR: struct (Cat) cannot implement (Animal): no such interface"},
+		{`struct Cat { func speak() { return super.speak(); } } new Cat().speak()`, "ERROR: super can only be used in the methods of a struct that extends another struct"},
+		{`struct Cat extends Animal { func speak() { return super.purr(); } } new Cat("c").speak()`, "ERROR: method (purr) not found in parent struct (Animal)"},
+		{`new Dog("a") instanceof 5`, "ERROR: right operand of 'instanceof' must be a struct or an interface, got int"},
+		{`hierarchy(5)`, "ERROR: argument to `hierarchy` must be a struct or an object, got 'int'"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(decls + tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_OperatorOverloading verifies the protocol methods of structs
+// (__add__, __eq__, __lt__, __index__, __setindex__, __len__, __str__, __hash__)
+func TestEvaluator_OperatorOverloading(t *testing.T) {
+	decls := `
+struct Money {
+    func init(cents) { this.cents = cents; }
+    func __add__(other) { return new Money(this.cents + other.cents); }
+    func __sub__(other) { return new Money(this.cents - other.cents); }
+    func __mul__(k) { return new Money(this.cents * k); }
+    func __eq__(other) { return other instanceof Money && this.cents == other.cents; }
+    func __lt__(other) { return this.cents < other.cents; }
+    func __str__() { return this.cents + "c"; }
+    func __hash__() { return this.cents; }
+}
+struct Grid {
+    func init(n) { this.cells = []; for (var i = 0; i < n; i = i + 1) { push(this.cells, 0); } }
+    func __index__(i) { return this.cells[i]; }
+    func __setindex__(i, v) { this.cells[i] = v; }
+    func __len__() { return length(this.cells); }
+}
+struct Euro extends Money {}
+struct Plain { func init(v) { this.v = v; } }
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`println(new Money(150) + new Money(275));`, "425c\n"},
+		{`println(new Money(500) - new Money(150), new Money(120) * 3);`, "350c 360c\n"},
+		{`var m = new Money(100); m += new Money(5); println(m);`, "105c\n"},
+		{`println(new Money(5) == new Money(5), new Money(5) != new Money(5), new Money(5) == 5);`, "true false false\n"},
+		{`var m = new Money(5); println(m === m, m === new Money(5));`, "true false\n"},
+		{`var a = new Money(1); var b = new Money(2); println(a < b, a > b, a <= b, a >= b, b <= b, b >= b);`, "true false true false true true\n"},
+		{`println("total: " + new Money(250), "${new Money(199)}", to_string(new Money(7)));`, "total: 250c 199c 7c\n"},
+		{`println([new Money(300), new Money(100)], list(new Money(1)), tuple(new Money(2)));`, "[300c, 100c] list(1c) tuple(2c)\n"},
+		{`println(map{"a": new Money(3)}, set{new Money(4)}, [map{new Money(1): set{new Money(5)}}]);`, "map{a: 3c} set{4c} [map{1c: set{5c}}]\n"},
+		{`var m = map{"total": new Money(9)}; println("m = " + m, "${m}", to_string(m));`, "m = map{total: 9c} map{total: 9c} map{total: 9c}\n"},
+		{`println(sort([new Money(300), new Money(100), new Money(200)]));`, "[100c, 200c, 300c]\n"},
+		{`println(sorted([new Money(300), new Money(100), new Money(200)], true));`, "[300c, 200c, 100c]\n"},
+		{`var g = new Grid(3); g[1] = 7; g[2] += 4; println(g[1], g[2], g[-3], length(g), size(g));`, "7 4 0 3 3\n"},
+		{`println(new Euro(120) + new Euro(5), new Euro(3) < new Euro(4));`, "125c true\n"},
+		{`var p = new Plain(1); println(p == p, length([p]));`, "true 1\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(decls + tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`new Plain(1) + new Plain(2)`, "ERROR: operator (+) not implemented for (object) and (object)"},
+		{`5 * new Money(1)`, "ERROR: operator (*) not implemented for (int) and (object)"},
+		{`new Plain(1)[0]`, "ERROR: index operator not supported for type 'object'"},
+		{`length(new Plain(1))`, "argument to `length` not supported, got 'object'"},
+		{`struct Bad { func __str__() { return 1; } } println(new Bad());`, "ERROR: __str__ of struct (Bad) must return string, got int"},
+		{`struct Bad { func __str__() { return 1; } } println(map{"k": new Bad()});`, "ERROR: __str__ of struct (Bad) must return string, got int"},
+		{`struct Bad { func __len__() { return "1"; } } length(new Bad())`, "ERROR: __len__ of struct (Bad) must return int, got string"},
+		{`struct Bad { func __lt__(o) { return nil; } } sort([new Bad(), new Bad()])`, "ERROR: __lt__ of struct (Bad) must return bool, got nil"},
+		{`struct Bad { func __eq__() { return true; } } new Bad() == 1`, "ERROR: wrong number of arguments"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(decls + tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_Match verifies match expressions: the pattern kinds, guards,
+// bindings and the exhaustiveness of matches over enums
+func TestEvaluator_Match(t *testing.T) {
+	decls := `
+enum Color { RED, GREEN, BLUE }
+struct Point { func init(x, y) { this.x = x; this.y = y; } }
+struct Point3 extends Point { func init(x, y, z) { super.init(x, y); this.z = z; } }
+struct Money {
+    func init(cents) { this.cents = cents; }
+    func __eq__(other) { return other instanceof Money && this.cents == other.cents; }
+}
+func classify(v) {
+    return match (v) {
+        0 => "zero",
+        1...9 if v % 2 == 0 => "even digit",
+        1...9 => "odd digit",
+        'a'...'z' => "lower",
+        "yes" | "y" => "affirmative",
+        nil => "nil",
+        [] => "empty",
+        [x] => "one " + x,
+        [first, ...rest] => "first " + first + " of " + length(rest) + " more",
+        {name, ...others} => "named " + name + " with " + others,
+        Point{x: 0, y: 0} => "origin",
+        Point3{z} => "3d at z " + z,
+        Point{x, y} if x == y => "diagonal " + x,
+        Point{x, y} => "point " + x + "," + y,
+        other => "other " + other
+    };
+}
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`println(classify(0), classify(4), classify(7), classify(12));`, "zero even digit odd digit other 12\n"},
+		{`println(classify('q'), classify("y"), classify("yes"), classify(nil));`, "lower affirmative affirmative nil\n"},
+		{`println(classify([]), classify([5]), classify([1, 2, 3]), classify(list(1, 2)), classify(tuple(7)));`, "empty one 5 first 1 of 2 more first 1 of 1 more one 7\n"},
+		{`println(classify(map{"name": "ann", "age": 30}), classify(map{"age": 30}));`, "named ann with map{age: 30} other map{age: 30}\n"},
+		{`println(classify(new Point(0, 0)), classify(new Point(2, 2)), classify(new Point(1, 2)), classify(new Point3(1, 2, 3)));`, "origin diagonal 2 point 1,2 3d at z 3\n"},
+		{`var c = Color.GREEN; println(match (c) { Color.RED => "r", Color.GREEN | Color.BLUE => "gb" });`, "gb\n"},
+		{`println(match (Color.BLUE) { Color.RED => 1, _ => 2 });`, "2\n"},
+		{`func name(c) { return match (c) { Color.RED => "red", Color.GREEN => "green" }; } println(name(Color.RED));`, "red\n"},
+		{`println(match (new Money(5)) { Money{cents: 1} => "one", new Money(5) => "five", _ => "?" });`, "five\n"},
+		{`var r = match ((1, "a")) { (1, s) => { var up = upper(s); up + up; } _ => "" }; println(r);`, "AA\n"},
+		{`var x = "outer"; match (1) { x => x }; println(x);`, "outer\n"},
+		{`func f(n) { match (n) { 0 => { return "early"; } _ => 0 }; return "late"; } println(f(0), f(1));`, "early late\n"},
+		{`var s = 0; foreach v in [1, 2, 3, 4] { s += match (v) { 1 | 3 => 10, _ => 1 }; } println(s);`, "22\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(decls + tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (Color.BLUE) { Color.RED => 1 }`, "ERROR: match over enum (Color) is not exhaustive: missing Color.GREEN, Color.BLUE"},
+		{`match (Color.GREEN) { Color.RED => 1, Color.GREEN if false => 2, Color.BLUE => 3 }`, "ERROR: match over enum (Color) is not exhaustive: missing Color.GREEN"},
+		{`match (5) { 1 => "one", 2 => "two" }`, "ERROR: no match arm matches value (5)"},
+		{`match (5) { n if n => 1 }`, "ERROR: match guard must be (bool), got (int)"},
+		{`match (5) { "a"..."z" => 1 }`, "ERROR: bounds of range pattern a...z must be numbers or chars, got (string) and (string)"},
+		{`match (5) { Nope{x} => 1 }`, "ERROR: (Nope) in a match pattern is not a struct or an interface"},
+		{`match (5) { missing.value => 1, _ => 2 }`, "ERROR: identifier not found: (missing)"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(decls + tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_TypeAnnotations verifies that the annotations of variables,
+// parameters, return values and struct fields are enforced at runtime
+func TestEvaluator_TypeAnnotations(t *testing.T) {
+	decls := `
+enum Color { RED, GREEN }
+interface Shape { area(); }
+struct Square implements Shape {
+    var side: float = 1;
+    func init(side: float) { this.side = side; }
+    func area(): float { return this.side * this.side; }
+}
+struct Cube extends Square { func init(side: float) { super.init(side); } }
+func scale(w: float, h: float): float { return w * h; }
+func total(...nums: array<int>): int { var s = 0; foreach n in nums { s += n; } return s; }
+func pick(flag: bool): int | nil { if (flag) { return 1; } return nil; }
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`var x: float = 2; println(x, typeof(x));`, "2.000000 float\n"},
+		{`var x: float = 2.5; x = 3; x += 1; println(x);`, "4.000000\n"},
+		{`let n: int = 1; n = 2; println(n);`, "2\n"},
+		{`var names: array<string> = ["a", "b"]; names = []; println(names);`, "[]\n"},
+		{`var m: map<string, array<int>> = map{"a": [1, 2]}; println(m);`, "map{a: [1, 2]}\n"},
+		{`var t: tuple<int, string> = (1, "a"); println(t);`, "tuple(1, a)\n"},
+		{`var v: int | nil = nil; v = 5; println(v);`, "5\n"},
+		{`var a: any = 1; a = "s"; println(a);`, "s\n"},
+		{`println(scale(3, 4), typeof(scale(3, 4)));`, "12.000000 float\n"},
+		{`println(total(), total(1, 2, 3));`, "0 6\n"},
+		{`println(pick(true), pick(false));`, "1 nil\n"},
+		{`var s: Shape = new Cube(2); var q: Square = s; println(q.area());`, "4.000000\n"},
+		{`var c: Color = Color.GREEN; println(c == Color.GREEN);`, "true\n"},
+		{`var sq = new Square(1); sq.side = 3; println(sq.side);`, "3.000000\n"},
+		{`var f = func(s: string): string { return s + "!"; }; println(f("hi"));`, "hi!\n"},
+		{`var x: int = 1; func shadow() { var x = "inner"; x = "still"; return x; } println(shadow(), x);`, "still 1\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(decls + tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`var x: int = "s";`, "ERROR: can't assign `string` to variable (x) of type `int`"},
+		{`var x: int = 1; x = 2.5;`, "ERROR: can't assign `float` to variable (x) of type `int`"},
+		{`var x: int = 1; x += 0.5;`, "ERROR: can't assign `float` to variable (x) of type `int`"},
+		{`var names: array<string> = ["a", 1];`, "ERROR: can't assign `array` to variable (names) of type `array<string>`"},
+		{`var m: map<string, int> = map{"a": "b"};`, "ERROR: can't assign `map` to variable (m) of type `map<string, int>`"},
+		{`var t: tuple<int, string> = (1, 2);`, "ERROR: can't assign `tuple` to variable (t) of type `tuple<int, string>`"},
+		{`var v: int | nil = "s";`, "ERROR: can't assign `string` to variable (v) of type `int | nil`"},
+		{`scale("a", 1);`, "ERROR: can't pass `string` as parameter (w) of type `float` to (scale)"},
+		{`total(1, "b");`, "ERROR: can't pass `array` as parameter (nums) of type `array<int>` to (total)"},
+		{`func bad(): int { return "s"; } bad();`, "ERROR: function (bad) must return `int`, got `string`"},
+		{`func none(): int { } none();`, "ERROR: function (none) must return `int`, got `nil`"},
+		{`var sq = new Square(1); sq.side = "wide";`, "ERROR: can't assign `string` to field (side) of type `float` in struct (Square)"},
+		{`new Square("wide");`, "ERROR: can't pass `string` as parameter (side) of type `float` to (init)"},
+		{`var s: Square = 1;`, "ERROR: can't assign `int` to variable (s) of type `Square`"},
+		{`struct Other {} var s: Shape = new Other();`, "ERROR: can't assign `Other` to variable (s) of type `Shape`"},
+		{`var c: Color = 5;`, "ERROR: can't assign `int` to variable (c) of type `Color`"},
+		{`var u: Unknown = 1;`, "ERROR: can't assign `int` to variable (u) of type `Unknown`"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(decls + tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_Generators verifies generator functions, which run up to each
+// yield as their values are asked for, and the lazy package
+func TestEvaluator_Generators(t *testing.T) {
+	decls := `
+import lazy;
+func naturals() { var n = 0; while (true) { yield n; n += 1; } }
+func squares(limit) { foreach i in range(1, limit) { yield i * i; } return 99; }
+func bad() { yield 1; throw "boom"; }
+struct Tree {
+    func init(v, kids) { this.v = v; this.kids = kids; }
+    func walk() { yield this.v; foreach k in this.kids { foreach x in k.walk() { yield x; } } }
+}
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`foreach s in squares(3) { print(s, ""); }`, "1 4 9 "},
+		{`println(array(squares(4)), to_list(squares(2)));`, "[1, 4, 9, 16] list(1, 4)\n"},
+		{`var g = squares(2); println(g, typeof(g));`, "generator squares generator\n"},
+		{`var log = []; func noisy() { push(log, "run"); yield 1; } var g = noisy(); println(log);`, "[]\n"},
+		{`var g = naturals(); foreach x in g { if (x == 2) { break; } } foreach x in g { println(x); break; }`, "3\n"},
+		{`var g = squares(1); println(array(g), array(g));`, "[1] []\n"},
+		{`foreach i, v in squares(2) { print(i, v, ""); }`, "0 1 1 4 "},
+		{`try { foreach v in bad() { println(v); } } catch (e) { println(e.message); }`, "1\nboom\n"},
+		{`var t = new Tree(1, [new Tree(2, []), new Tree(3, [new Tree(4, [])])]); println(array(t.walk()));`, "[1, 2, 3, 4]\n"},
+		{`var fib = func() { var a = 0; var b = 1; while (true) { yield a; var c = a + b; a = b; b = c; } }; println(array(lazy.take(fib(), 8)));`, "[0, 1, 1, 2, 3, 5, 8, 13]\n"},
+		{`func nums(): generator { yield 1; } var g: generator = nums(); println(array(g));`, "[1]\n"},
+		{`println(array(lazy.take(naturals(), 3)), array(lazy.take([1, 2], 5)));`, "[0, 1, 2] [1, 2]\n"},
+		{`println(array(lazy.skip(squares(4), 2)), array(lazy.skip([1], 3)));`, "[9, 16] []\n"},
+		{`println(array(lazy.zip(naturals(), "ab", [true, false, true])));`, "[tuple(0, a, true), tuple(1, b, false)]\n"},
+		{`println(array(lazy.chain([1, 2], squares(2), range(7, 8))));`, "[1, 2, 1, 4, 7, 8]\n"},
+		{`println(array(lazy.enumerate("ab")), array(lazy.enumerate(["x"], 1)));`, "[tuple(0, a), tuple(1, b)] [tuple(1, x)]\n"},
+		{`println(array(lazy.window(range(1, 4), 2)), array(lazy.window(range(1, 7), 2, 3)));`, "[[1, 2], [2, 3], [3, 4]] [[1, 2], [4, 5]]\n"},
+		{`println(map_array(lazy.take(naturals(), 3), func(x) { return x * 10; }));`, "[0, 10, 20]\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(decls + tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`foreach v in bad() { }`, "boom"},
+		{`func gen(): int { yield 1; } gen();`, "ERROR: function (gen) must return `int`, got `generator`"},
+		{`lazy.take(naturals());`, "ERROR: lazy.take expects 2 arguments (iterable, n)"},
+		{`lazy.take(5, 2);`, "ERROR: first argument to `lazy.take` must be an iterable, got 'int'"},
+		{`lazy.skip([1], -1);`, "ERROR: second argument to `lazy.skip` must be an integer >= 0, got '-1'"},
+		{`lazy.zip([1]);`, "ERROR: lazy.zip expects at least 2 arguments (iterables)"},
+		{`lazy.zip([1], 2);`, "ERROR: second argument to `lazy.zip` must be an iterable, got 'int'"},
+		{`lazy.window([1], 0);`, "ERROR: second argument to `lazy.window` must be an integer >= 1, got '0'"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(decls + tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_GeneratorClose verifies that a foreach loop over a generator
+// it created closes it when it ends early (by break, return or error): the
+// body returns from its yield, running its finally blocks, and its goroutine
+// ends instead of staying suspended
+func TestEvaluator_GeneratorClose(t *testing.T) {
+	src := `
+var closed = 0;
+func naturals() {
+    var n = 0;
+    try {
+        while (true) { yield n; n += 1; }
+    } finally {
+        closed += 1;
+    }
+}
+func first() { foreach x in naturals() { return x; } }
+for (var i = 0; i < 50; i = i + 1) {
+    foreach x in naturals() { if (x == 2) { break; } }
+    first();
+    try { foreach x in naturals() { throw "stop"; } } catch (e) { }
+}
+var g = naturals();
+foreach x in g { if (x == 1) { break; } }
+foreach x in g { print(x, ""); break; }
+println(closed);
+`
+	for _, vm := range []bool{false, true} {
+		before := runtime.NumGoroutine()
+		p := parser.NewParser(src)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		ev.UseVM = vm
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("vm=%v: unexpected error: %s", vm, result.ToString())
+		}
+		// a generator held by a variable is left where it stopped
+		if out.String() != "2 150\n" {
+			t.Errorf("vm=%v: expected output %q, got %q", vm, "2 150\n", out.String())
+		}
+		// only the goroutine of g may remain (the ended ones may take a moment to exit)
+		after := runtime.NumGoroutine()
+		for deadline := time.Now().Add(time.Second); after > before+1 && time.Now().Before(deadline); after = runtime.NumGoroutine() {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if after > before+1 {
+			t.Errorf("vm=%v: %d goroutines before, %d after: generator bodies leaked", vm, before, after)
+		}
+	}
+}
+
+// TestEvaluator_GeneratorAbandoned verifies that the goroutines of generators
+// that are no longer reachable end once they are garbage collected, and that
+// the lazy combinators close the generators they read from
+func TestEvaluator_GeneratorAbandoned(t *testing.T) {
+	src := `
+import lazy;
+var closed = 0;
+func naturals() {
+    var n = 0;
+    try {
+        while (true) { yield n; n += 1; }
+    } finally {
+        closed += 1;
+    }
+}
+for (var i = 0; i < 200; i = i + 1) {
+    var g = naturals();
+    foreach x in g { break; }
+}
+var total = 0;
+foreach x in lazy.take(naturals(), 3) { total += x; }
+foreach p in lazy.zip([1, 2], naturals()) { total += p[1]; }
+var c = lazy.chain(naturals(), [1]);
+foreach x in lazy.take(c, 2) { total += x; }
+println(total, closed);
+`
+	for _, vm := range []bool{false, true} {
+		before := runtime.NumGoroutine()
+		p := parser.NewParser(src)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		ev.UseVM = vm
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("vm=%v: unexpected error: %s", vm, result.ToString())
+		}
+		// the abandoned generators end without running their finally blocks
+		if out.String() != "5 3\n" {
+			t.Errorf("vm=%v: expected output %q, got %q", vm, "5 3\n", out.String())
+		}
+		after := runtime.NumGoroutine()
+		for deadline := time.Now().Add(2 * time.Second); after > before && time.Now().Before(deadline); after = runtime.NumGoroutine() {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+		if after > before {
+			t.Errorf("vm=%v: %d goroutines before, %d after GC: abandoned generators leaked", vm, before, after)
+		}
+	}
+}
+
 // TestEvaluator_StringFunctions verifies evaluation of string builtin functions
 func TestEvaluator_StringFunctions(t *testing.T) {
 	tests := []struct {
@@ -2924,17 +4092,244 @@ func TestEvaluator_JSONEncode(t *testing.T) {
 	evaluator := NewEvaluator()
 	evaluator.SetParser(p)
 	result := evaluator.Eval(rootNode)
-	// JSON map keys are sorted alphabetically by Go's json.Marshal
+	// JSON objects keep the insertion order of the keys
 	expected := `{"a":1,"b":[true,null]}`
 	if result.ToString() != expected {
 		t.Errorf("expected %s, got %s", expected, result.ToString())
 	}
 }
 
-// TestEvaluator_ImportStatement verifies the import statement evaluation
-func TestEvaluator_ImportStatement(t *testing.T) {
-	input := `import math;`
-	p := parser.NewParser(input)
+// TestEvaluator_JSONPackage verifies ordered encoding and decoding, indented
+// output, struct instances and the positions of JSON syntax errors.
+func TestEvaluator_JSONPackage(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import json; println(json.stringify_json(map{"zeta": 1, "alpha": [1, 2.0], "t": tuple(1, 'c'), "s": set{3, 1}, "l": list(nil), 7: 1n << 70, "d": decimal("19.90"), "b": bytes("hi")}));`,
+			`{"zeta":1,"alpha":[1,2.0],"t":[1,"c"],"s":[3,1],"l":[null],"7":1180591620717411303424,"d":19.90,"b":"aGk="}` + "\n"},
+		{`import json; println(json.stringify_json(map{"b": [1], "a": map{}}, 2));`, "{\n  \"b\": [\n    1\n  ],\n  \"a\": {}\n}\n"},
+		{`import json; println(json.stringify_json(map{"b": 1, "a": 2}, map{"sort_keys": true, "indent": "\t"}));`, "{\n\t\"a\": 2,\n\t\"b\": 1\n}\n"},
+		{`import json; var v = json.parse_json("{\"z\": [1, 2.0, 1e3, 123456789012345678901234567890], \"a\": null}"); println(v, typeof(v["z"][1]), typeof(v["z"][3]));`,
+			"map{z: [1, 2.000000, 1000.000000, 123456789012345678901234567890], a: nil} float bigint\n"},
+		{`import json; enum Color { RED, GREEN = 5, BLUE } println(json.stringify_json(Color), json.stringify_json([Color.BLUE]));`, `{"RED":0,"GREEN":5,"BLUE":6} [6]` + "\n"},
+		{`import json; struct P { var x = 0; var y = 0; const K = 1; func init(x) { this.x = x; this.tag = "t"; } } println(json.stringify_json(new P(3)));`, `{"x":3,"y":0,"tag":"t"}` + "\n"},
+		{`import json; struct P { var x = 0; var y = 0; } var p = json.parse_json("{\"y\": 4, \"z\": true}", P); println(typeof(p), p.x, p.y, p.z);`, "object 0 4 true\n"},
+		{`import json; struct P { var x = 0; } struct L { var a: P | nil = nil; var ps: array<P> = []; var s: set<int> = set{}; var d: decimal = decimal(0); } var l = json.parse_json("{\"a\": {\"x\": 1}, \"ps\": [{\"x\": 2}], \"s\": [1, 1], \"d\": 2.5}", "L"); println(l.a.x, l.ps[0].x, l.s, l.d, typeof(l.d));`,
+			"1 2 set{1} 2.5 decimal\n"},
+		{`import json; struct P { var x = 0; } println(length(json.parse_json("[{\"x\": 1}, {\"x\": 2}]", P)));`, "2\n"},
+		{`import json; struct M { var c = 150; func __json__() { return [this.c / 100, this.c % 100]; } } println(json.stringify_json(map{"m": new M()}));`, `{"m":[1,50]}` + "\n"},
+		{`import json; println(json.stringify_json("q\"b\\\n<&>"), json.stringify_json(1...3), json.stringify_json(100.0), json.stringify_json(1e21));`, `"q\"b\\\n<&>" [1,2,3] 100.0 1e+21` + "\n"},
+		{`println(json_string_to_map(map_to_json_string(map{"b": 1, "a": 2})));`, "map{b: 1, a: 2}\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"import json; json.parse_json(\"{\\\"a\\\": 1,\\n \\\"b\\\" 2}\");", "ERROR: invalid JSON at line 2, column 6: invalid character '2' after object key"},
+		{`import json; json.parse_json("[1, 2");`, "ERROR: invalid JSON at line 1, column 5: unexpected end of JSON input"},
+		{`import json; json.parse_json("1 2");`, "ERROR: invalid JSON at line 1, column 3: invalid character '2' after top-level value"},
+		{`import json; json.stringify_json(println);`, "ERROR: cannot encode func as JSON"},
+		{`import json; var a = [1]; push(a, a); json.stringify_json(a);`, "ERROR: cannot encode array containing itself as JSON"},
+		{`import json; json.stringify_json(1, map{"pad": 1});`, "ERROR: unknown option \"pad\" of `stringify_json`"},
+		{`import json; json.stringify_json(0.0 / 0.0);`, "ERROR: cannot encode float NaN as JSON"},
+		{`import json; struct P { var x: int = 0; } json.parse_json("{\"x\": \"s\"}", P);`, "ERROR: can't assign `string` to field (x) of type `int` in struct (P)"},
+		{`import json; struct P { var x = 0; } json.parse_json("[1]", P);`, "ERROR: cannot decode JSON number into struct (P)"},
+		{`import json; json.parse_json("[1]", "Nope");`, "ERROR: struct type 'Nope' not defined"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_JSONFiles verifies write_json, read_json and the lazy
+// line-delimited reader, whose errors give the line in the file.
+func TestEvaluator_JSONFiles(t *testing.T) {
+	dir := t.TempDir()
+	lines := filepath.Join(dir, "events.jsonl")
+	if err := os.WriteFile(lines, []byte("{\"n\": 1}\n\n{\"n\": 2}\r\n{\"n\" 3}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := fmt.Sprintf(`
+		import json;
+		json.write_json(%[1]q, map{"k": [1, 2]}, 2);
+		println(read_file(%[1]q), json.read_json(%[1]q));
+		foreach i, ev in json.lines_json(%[2]q) {
+			println(i, ev["n"]);
+			if (i == 1) { break; }
+		}
+		foreach ev in json.lines_json(%[2]q) { println(ev["n"]); }
+	`, filepath.Join(dir, "out.json"), lines)
+	p := parser.NewParser(input)
+	rootNode := p.Parse()
+	var out strings.Builder
+	evaluator := NewEvaluator()
+	evaluator.SetParser(p)
+	evaluator.SetWriter(&out)
+	result := evaluator.Eval(rootNode)
+	AssertError(t, result, fmt.Sprintf("ERROR: invalid JSON at line 4, column 6 of %s: invalid character '3' after object key", lines))
+	expected := "{\n  \"k\": [\n    1,\n    2\n  ]\n}\n map{k: [1, 2]}\n0 1\n1 2\n1\n2\n"
+	if out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+}
+
+// TestEvaluator_DataCodecs verifies the csv, toml and yaml packages: ordered
+// maps both ways, their options and the positions of syntax errors.
+func TestEvaluator_DataCodecs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import csv; println(csv.parse_csv("a,b\n1,\"x,\"\"y\"\"\"\n"));`, "[[a, b], [1, x,\"y\"]]\n"},
+		{`import csv; var r = csv.parse_csv("n,v\nx,1.5\ny,true\n", map{"header": true, "types": true}); println(r, typeof(r[0]["v"]));`,
+			"[map{n: x, v: 1.500000}, map{n: y, v: true}] float\n"},
+		{`import csv; println(csv.parse_csv("a;b\n# skip\n1; 2\n", map{"delimiter": ';', "comment": "#", "trim_space": true}));`, "[[a, b], [1, 2]]\n"},
+		{`import csv; print(csv.stringify_csv([["a", "b"], [1, "x,y"], [2.5, "q\"q"], [nil, true], [" s", ""]]));`,
+			"a,b\n1,\"x,y\"\n2.5,\"q\"\"q\"\n,true\n\" s\",\n"},
+		{`import csv; print(csv.stringify_csv([map{"id": 1, "ok": true}, map{"ok": false}]));`, "id,ok\n1,true\n,false\n"},
+		{`import csv; print(csv.stringify_csv([map{"id": 1, "ok": true}], map{"header": false, "delimiter": "\t", "quote_all": true}));`, "\"1\"\t\"true\"\n"},
+		{`import csv; print(csv.stringify_csv(list([1, 2]), map{"header": ["x", "y"], "crlf": true}), csv.stringify_csv([[""]]));`, "x,y\r\n1,2\r\n \"\"\n"},
+		{`import csv; struct P { var x = 1; var y = 2; } print(csv.stringify_csv([new P()]));`, "x,y\n1,2\n"},
+		{"import toml; println(toml.parse_toml(`t = \"a\\tb\"\nn = [0xff, 0o7, 0b11, 1_000, -3]\nf = [1.5, -inf]\nd = 1979-05-27T07:32:00Z\na.b = 'lit'\ni = { x = 1 }\n[s.t]\nk = true\n[[p]]\nn = 1\n[[p]]\nn = 2\n`));",
+			"map{t: a\tb, n: [255, 7, 3, 1000, -3], f: [1.500000, -Inf], d: 1979-05-27T07:32:00Z, a: map{b: lit}, i: map{x: 1}, s: map{t: map{k: true}}, p: [map{n: 1}, map{n: 2}]}\n"},
+		{"import toml; println(toml.parse_toml(`s = \"\"\"\nx \\\n   y\"\"\"\nbig = 9223372036854775808`));", "map{s: x y, big: 9223372036854775808}\n"},
+		{`import toml; print(toml.stringify_toml(map{"name": "app", "db": map{"port": 5432, "pool": map{"max": 4}}, "x y": [map{"a": 1}, 2], "only": map{"t": map{}}, "users": [map{"n": "a"}, map{"n": "b"}], "f": 2.0}));`,
+			"name = \"app\"\n\"x y\" = [{a = 1}, 2]\nf = 2.0\n\n[db]\nport = 5432\n\n[db.pool]\nmax = 4\n\n[only.t]\n\n[[users]]\nn = \"a\"\n\n[[users]]\nn = \"b\"\n"},
+		{"import yaml; var v = yaml.parse_yaml(`b: &b {port: 80, tls: no}\nprod:\n  <<: *b\n  port: 443\n  big: 123456789012345678901234\n  none: ~\n  bin: !!binary aGk=\n  when: 2001-12-14\n  list: [1, \"2\", 3.5]\n`); println(v[\"prod\"]);",
+			"map{port: 443, tls: no, big: 123456789012345678901234, none: nil, bin: b\"hi\", when: 2001-12-14, list: [1, 2, 3.500000]}\n"},
+		{`import yaml; print(yaml.stringify_yaml(map{"s": ["true", "1", "", "a: b"], "n": [1, 2.0, nil, 1n << 70], "m": map{}}));`,
+			"s:\n  - \"true\"\n  - \"1\"\n  - \"\"\n  - 'a: b'\nn:\n  - 1\n  - 2.0\n  - null\n  - !!int 1180591620717411303424\nm: {}\n"},
+		{`import yaml; print(yaml.stringify_yaml(map{"a": [1]}, map{"indent": 4}));`, "a:\n    - 1\n"},
+		{`import yaml; var v = map{"k": [1, "x"], "big": 1n << 70}; println(yaml.parse_yaml(yaml.stringify_yaml(v)) == v, yaml.parse_yaml(""));`, "true nil\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		if result := ev.Eval(root); IsError(result) {
+			t.Fatalf("%s: unexpected error: %s", tt.input, result.ToString())
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected output %q, got %q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`import csv; csv.parse_csv("a,\"b\n");`, "ERROR: invalid CSV at line 1, column 6: extraneous or missing \" in quoted-field"},
+		{`import csv; csv.parse_csv("a,b\n1\n", map{"header": true});`, "ERROR: invalid CSV at line 2: the row has 1 fields, the header has 2"},
+		{`import csv; csv.parse_csv("a,a\n", map{"header": true});`, "ERROR: duplicate column \"a\" in the CSV header"},
+		{`import csv; csv.parse_csv("a", map{"delim": ","});`, "ERROR: unknown option \"delim\" of `parse_csv`"},
+		{`import csv; csv.parse_csv("a", map{"delimiter": ",,"});`, "ERROR: option \"delimiter\" of `parse_csv` must be a single character"},
+		{`import csv; csv.stringify_csv([1]);`, "ERROR: row 1 of `stringify_csv` must be an array or a map, got 'int'"},
+		{`import csv; csv.stringify_csv([[[1]]]);`, "ERROR: field 1 of row 1 of `stringify_csv` must be a string, a number, a bool or nil, got 'array'"},
+		{`import csv; csv.stringify_csv([map{"a": 1}, map{"b": 2}]);`, "ERROR: row 2 of `stringify_csv` has the key \"b\", which is not a column"},
+		{"import toml; toml.parse_toml(`a = 1\na = 2`);", "ERROR: invalid TOML at line 2, column 1: key a is already defined"},
+		{"import toml; toml.parse_toml(`[a]\nx = 1\n[a]`);", "ERROR: invalid TOML at line 3, column 1: table a is already defined"},
+		{"import toml; toml.parse_toml(`a = {x = 1}\n[a.y]`);", "ERROR: invalid TOML at line 2, column 1: inline table a cannot be extended"},
+		{"import toml; toml.parse_toml(`a = [1]\n[[a]]`);", "ERROR: invalid TOML at line 2, column 1: key a is already defined"},
+		{`import toml; toml.parse_toml("a = 1 2");`, "ERROR: invalid TOML at line 1, column 7: expected the end of the line, got '2'"},
+		{`import toml; toml.parse_toml("a = 01");`, "ERROR: invalid TOML at line 1, column 5: invalid value \"01\""},
+		{`import toml; toml.parse_toml("a = \"x");`, "ERROR: invalid TOML at line 1, column 6: unterminated string"},
+		{`import toml; toml.stringify_toml([1]);`, "ERROR: argument to `stringify_toml` must be a map (a TOML document is a table), got 'array'"},
+		{`import toml; toml.stringify_toml(map{"a": map{"b": [1, nil]}});`, "ERROR: cannot encode nil as TOML (key a.b)"},
+		{`import toml; toml.stringify_toml(map{"n": 1n << 70});`, "ERROR: cannot encode bigint 1180591620717411303424 as TOML (key n)"},
+		{`import yaml; yaml.parse_yaml("a: [1, 2");`, "ERROR: invalid YAML at line 1: did not find expected ',' or ']'"},
+		{`import yaml; yaml.parse_yaml("a: 1\na: 2");`, "ERROR: invalid YAML at line 2, column 1: \"a\" is already defined"},
+		{`import yaml; yaml.parse_yaml("a: &x [*x]");`, "ERROR: invalid YAML at line 1, column 8: alias *x contains itself"},
+		{`import yaml; yaml.parse_yaml("? [1]\n: x");`, "ERROR: invalid YAML at line 1, column 3: a mapping key must be a scalar"},
+		{`import yaml; yaml.stringify_yaml(1, map{"indent": 1});`, "ERROR: option \"indent\" of `stringify_yaml` must be a number of spaces from 2 to 9, got '1'"},
+		{`import yaml; yaml.stringify_yaml(println);`, "ERROR: cannot encode func as YAML"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.NewParser(tt.input)
+		rootNode := p.Parse()
+		if p.HasErrors() {
+			t.Fatalf("parser errors: %v", p.GetErrors())
+		}
+		evaluator := NewEvaluator()
+		evaluator.SetParser(p)
+		result := evaluator.Eval(rootNode)
+		AssertError(t, result, tt.expected)
+	}
+}
+
+// TestEvaluator_DataCodecFiles verifies that the csv, toml and yaml packages
+// write files that read back, and give the file in the positions of errors.
+func TestEvaluator_DataCodecFiles(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.toml")
+	if err := os.WriteFile(bad, []byte("[server]\nport = 80\nport = 81\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := fmt.Sprintf(`
+		import csv; import toml; import yaml;
+		var rows = [map{"name": "a", "n": 1}, map{"name": "b, c", "n": 2}];
+		csv.write_csv(%[1]q, rows);
+		println(csv.read_csv(%[1]q, map{"header": true, "types": true}) == rows);
+		toml.write_toml(%[2]q, map{"title": "t", "server": map{"port": 80}});
+		println(toml.read_toml(%[2]q));
+		yaml.write_yaml(%[3]q, rows);
+		println(yaml.read_yaml(%[3]q) == rows);
+		toml.read_toml(%[4]q);
+	`, filepath.Join(dir, "out.csv"), filepath.Join(dir, "out.toml"), filepath.Join(dir, "out.yaml"), bad)
+	p := parser.NewParser(input)
+	rootNode := p.Parse()
+	var out strings.Builder
+	evaluator := NewEvaluator()
+	evaluator.SetParser(p)
+	evaluator.SetWriter(&out)
+	result := evaluator.Eval(rootNode)
+	AssertError(t, result, fmt.Sprintf("ERROR: invalid TOML at line 3, column 1 of %s: key port is already defined", bad))
+	expected := "true\nmap{title: t, server: map{port: 80}}\ntrue\n"
+	if out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+}
+
+// TestEvaluator_ImportStatement verifies the import statement evaluation
+func TestEvaluator_ImportStatement(t *testing.T) {
+	input := `import math;`
+	p := parser.NewParser(input)
 	rootNode := p.Parse()
 	evaluator := NewEvaluator()
 	evaluator.SetParser(p)
@@ -4338,3 +5733,963 @@ func TestEvalSwitchStatements(t *testing.T) {
 		})
 	}
 }
+
+// writeModuleFiles writes Go-Mix source files into dir for module import tests
+func writeModuleFiles(t *testing.T, dir string, files map[string]string) {
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("could not create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("could not write module file: %v", err)
+		}
+	}
+}
+
+// evalModuleProgram evaluates the file main.gm in dir and returns the result and output
+func evalModuleProgram(t *testing.T, dir string) (std.GoMixObject, string) {
+	mainPath := filepath.Join(dir, "main.gm")
+	src, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("could not read main.gm: %v", err)
+	}
+	p := parser.NewParser(string(src))
+	root := p.Parse()
+	if p.HasErrors() {
+		t.Fatalf("parser errors: %v", p.GetErrors())
+	}
+	var out strings.Builder
+	ev := NewEvaluator()
+	ev.SetParser(p)
+	ev.SetWriter(&out)
+	ev.SetSourceFile(mainPath)
+	return ev.Eval(root), out.String()
+}
+
+// TestEvaluator_ImportModule verifies importing a user-written .gm file as a module
+func TestEvaluator_ImportModule(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"lib/util.gm": `
+const LIMIT = 10;
+var hidden = 42;
+enum Level { LOW, HIGH }
+struct Point {
+	func init(x, y) { this.x = x; this.y = y; }
+	func sum() { return add(this.x, this.y); }
+}
+func add(a, b) { return a + b; }
+func clamp(n) { if (n > LIMIT) { return LIMIT; } return n; }
+`,
+		"main.gm": `
+import "lib/util.gm" as util;
+import "lib/util.gm";
+println(util.add(2, 3));
+println(util.clamp(99));
+println(util.LIMIT);
+println(util.Level.HIGH);
+var p = new util.Point(4, 5);
+println(p.sum());
+println(is_same_ref(util, util));
+util.add(1, 1) + util.add(0, 1);
+`,
+	})
+
+	result, out := evalModuleProgram(t, dir)
+	if IsError(result) {
+		t.Fatalf("unexpected error: %s", result.ToString())
+	}
+	if result.ToString() != "3" {
+		t.Errorf("expected 3, got %s", result.ToString())
+	}
+	expected := "5\n10\n10\n1\n9\ntrue\n"
+	if out != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out)
+	}
+}
+
+// TestEvaluator_ImportModuleErrors verifies error reporting for module imports
+func TestEvaluator_ImportModuleErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		errorMessage string
+	}{
+		{
+			name:         "missing module",
+			files:        map[string]string{"main.gm": `import "nope.gm";`},
+			errorMessage: "module 'nope.gm' not found",
+		},
+		{
+			name: "private var",
+			files: map[string]string{
+				"m.gm":    `var hidden = 1;`,
+				"main.gm": `import "m.gm"; m.hidden;`,
+			},
+			errorMessage: "member 'hidden' not found in package 'm'",
+		},
+		{
+			name: "circular import",
+			files: map[string]string{
+				"a.gm":    `import "b.gm"; func fa() { return 1; }`,
+				"b.gm":    `import "a.gm"; func fb() { return 2; }`,
+				"main.gm": `import "a.gm"; a.fa();`,
+			},
+			errorMessage: "circular import detected: a.gm -> b.gm -> a.gm",
+		},
+		{
+			name: "import of entry file",
+			files: map[string]string{
+				"a.gm":    `import "main.gm";`,
+				"main.gm": `import "a.gm";`,
+			},
+			errorMessage: "circular import detected: main.gm -> a.gm -> main.gm",
+		},
+		{
+			name: "module parse error",
+			files: map[string]string{
+				"bad.gm":  `var = ;`,
+				"main.gm": `import "bad.gm";`,
+			},
+			errorMessage: "could not parse module 'bad.gm'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeModuleFiles(t, dir, tt.files)
+			result, _ := evalModuleProgram(t, dir)
+			if !IsError(result) {
+				t.Fatalf("expected an error, got %s", result.ToObject())
+			}
+			if !strings.Contains(result.ToString(), tt.errorMessage) {
+				t.Errorf("expected error message to contain '%s', got '%s'", tt.errorMessage, result.ToString())
+			}
+		})
+	}
+}
+
+// TestEvaluator_ImportModuleNestedError verifies that an error in a module
+// imported by another module is reported once, with the file it was raised
+// in, and that the traceback shows the chain of imports
+func TestEvaluator_ImportModuleNestedError(t *testing.T) {
+	for _, useVM := range []bool{false, true} {
+		dir := t.TempDir()
+		writeModuleFiles(t, dir, map[string]string{
+			"lib/b.gm": "var y = 2;\n\nvar z = y / 0;\n",
+			"a.gm":     "var x = 1;\nimport \"lib/b.gm\";\n",
+			"main.gm":  "println(0);\nimport \"a.gm\";\n",
+		})
+		mainPath := filepath.Join(dir, "main.gm")
+		src, _ := os.ReadFile(mainPath)
+		p := parser.NewParser(string(src))
+		root := p.Parse()
+		var out strings.Builder
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&out)
+		ev.SetSourceFile(mainPath)
+		ev.UseVM = useVM
+		result := ev.Eval(root)
+		if !IsError(result) {
+			t.Fatalf("vm=%v: expected an error, got %s", useVM, result.ToObject())
+		}
+		expected := "[" + filepath.Join(dir, "lib", "b.gm") + ":3:12] ERROR: division by zero"
+		if result.ToString() != expected {
+			t.Errorf("vm=%v: expected error %q, got %q", useVM, expected, result.ToString())
+		}
+		trace := std.Traceback(result)
+		want := []string{"<module> (" + filepath.Join(dir, "lib", "b.gm") + ":3)", "<module> (" + filepath.Join(dir, "a.gm") + ":2)", "<main> (" + mainPath + ":2)"}
+		last := -1
+		for _, frame := range want {
+			i := strings.Index(trace, frame)
+			if i <= last {
+				t.Errorf("vm=%v: expected frame %q after the previous ones in traceback:\n%s", useVM, frame, trace)
+				break
+			}
+			last = i
+		}
+	}
+}
+
+// TestEvaluator_ImportModuleOnce verifies a module shared by several importers is evaluated once
+func TestEvaluator_ImportModuleOnce(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"shared.gm": `println("loading shared"); func id(x) { return x; }`,
+		"a.gm":      `import "shared.gm"; func fa() { return shared.id(1); }`,
+		"b.gm":      `import "shared.gm"; func fb() { return shared.id(2); }`,
+		"main.gm":   `import "a.gm"; import "b.gm"; a.fa() + b.fb();`,
+	})
+
+	result, out := evalModuleProgram(t, dir)
+	if IsError(result) {
+		t.Fatalf("unexpected error: %s", result.ToString())
+	}
+	if result.ToString() != "3" {
+		t.Errorf("expected 3, got %s", result.ToString())
+	}
+	if out != "loading shared\n" {
+		t.Errorf("expected module to be evaluated once, got output %q", out)
+	}
+}
+
+// TestEvaluator_ImportModuleSpawn verifies that a module function runs on the
+// evaluator calling it: spawned, it reports its error with the goroutine's
+// call stack
+func TestEvaluator_ImportModuleSpawn(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"lib.gm": "func boom(n) {\n    return n / 0;\n}\n",
+		"main.gm": `import "lib.gm";
+var done = make_chan();
+func after() { sleep(5); close_chan(done); }
+spawn lib.boom(1);
+spawn after();
+recv_chan(done);
+println("main goes on");
+`,
+	})
+
+	result, out := evalModuleProgram(t, dir)
+	if IsError(result) {
+		t.Fatalf("unexpected error: %s", result.ToString())
+	}
+	expected := "[2:15] ERROR: division by zero\nTraceback (most recent call first):\n  at boom (lib.gm:2)\n  at <spawn> (main.gm:4)\nmain goes on\n"
+	if out = strings.ReplaceAll(out, dir+string(filepath.Separator), ""); out != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out)
+	}
+}
+
+// TestEvaluator_ImportModuleArguments verifies that module functions take
+// default, named and rest arguments like local functions
+func TestEvaluator_ImportModuleArguments(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"util.gm": "func sub(a, b = 10) { return a - b; }\nfunc count(first, ...rest) { return first + length(rest); }\n",
+		"main.gm": `import "util.gm";
+println(util.sub(b: 1, a: 5), util.sub(15), util.count(1, 2, 3));
+var f = util.sub;
+println(f(b: 2, a: 3));
+util.sub(c: 1);
+`,
+	})
+
+	result, out := evalModuleProgram(t, dir)
+	if out != "4 5 3\n1\n" {
+		t.Errorf("wrong output. expected=%q, got=%q", "4 5 3\n1\n", out)
+	}
+	if !IsError(result) || !strings.Contains(result.ToString(), "(c)") {
+		t.Errorf("expected an error for the unknown argument c, got %s", result.ToString())
+	}
+}
+
+// TestEvaluator_ImportModuleSearchPath verifies modules are found through GOMIX_PATH
+func TestEvaluator_ImportModuleSearchPath(t *testing.T) {
+	dir := t.TempDir()
+	libDir := t.TempDir()
+	writeModuleFiles(t, libDir, map[string]string{
+		"strutil.gm": `func twice(s) { return s + s; }`,
+	})
+	writeModuleFiles(t, dir, map[string]string{
+		"main.gm": `import "strutil.gm" as su; su.twice("ab");`,
+	})
+	t.Setenv(ModulePathEnv, libDir)
+
+	result, _ := evalModuleProgram(t, dir)
+	if IsError(result) {
+		t.Fatalf("unexpected error: %s", result.ToString())
+	}
+	if result.ToString() != "abab" {
+		t.Errorf("expected abab, got %s", result.ToString())
+	}
+}
+
+// TestEvaluator_TryCatchFinally verifies try/catch/finally and throw
+func TestEvaluator_TryCatchFinally(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "catch thrown string",
+			input:    `try { throw "boom"; } catch (e) { println(e.message); }`,
+			expected: "boom\n",
+		},
+		{
+			name:     "thrown value kept",
+			input:    `try { throw 42; } catch (e) { println(e.value + 1, typeof(e.value)); }`,
+			expected: "43 int\n",
+		},
+		{
+			name: "throw from nested function",
+			input: `
+func check(n) { if (n < 0) { throw "negative"; } return n; }
+func run() { return check(-1) + 1; }
+try { run(); println("unreachable"); } catch (e) { println("caught", e.message); }
+`,
+			expected: "caught negative\n",
+		},
+		{
+			name:     "runtime error from builtin",
+			input:    `try { to_int("abc"); } catch (e) { println(e.line > 0, e.column > 0); }`,
+			expected: "true true\n",
+		},
+		{
+			name:     "division by zero",
+			input:    `try { var x = 10 / 0; } catch (e) { println(e.message); }`,
+			expected: "division by zero\n",
+		},
+		{
+			name:     "finally runs after success",
+			input:    `try { println("try"); } catch (e) { println("catch"); } finally { println("finally"); }`,
+			expected: "try\nfinally\n",
+		},
+		{
+			name:     "finally runs after catch",
+			input:    `try { throw "x"; } catch (e) { println("catch"); } finally { println("finally"); }`,
+			expected: "catch\nfinally\n",
+		},
+		{
+			name:     "catch without parameter",
+			input:    `try { throw "x"; } catch { println("handled"); }`,
+			expected: "handled\n",
+		},
+		{
+			name:     "finally runs on return",
+			input:    `func f() { try { return 1; } finally { println("cleanup"); } } println(f());`,
+			expected: "cleanup\n1\n",
+		},
+		{
+			name:     "rethrow keeps message",
+			input:    `try { try { throw "inner"; } catch (e) { throw e; } } catch (e2) { println(e2.message); }`,
+			expected: "inner\n",
+		},
+		{
+			name: "scope restored inside loop",
+			input: `
+var total = 0;
+for (var i = 0; i < 4; i = i + 1) {
+	try { if (i == 2) { throw i; } total = total + i; } catch (e) { total = total + 10; }
+}
+println(total);
+`,
+			expected: "14\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(tt.input)
+			root := p.Parse()
+			if p.HasErrors() {
+				t.Fatalf("parser errors: %v", p.GetErrors())
+			}
+			var out strings.Builder
+			ev := NewEvaluator()
+			ev.SetParser(p)
+			ev.SetWriter(&out)
+			result := ev.Eval(root)
+			if IsError(result) {
+				t.Fatalf("unexpected error: %s", result.ToString())
+			}
+			if out.String() != tt.expected {
+				t.Errorf("wrong output. expected=%q, got=%q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+// TestEvaluator_ThrowUncaught verifies uncaught and finally-only errors keep propagating
+func TestEvaluator_ThrowUncaught(t *testing.T) {
+	tests := []struct {
+		input        string
+		errorMessage string
+	}{
+		{`throw "fatal";`, "ERROR: fatal"},
+		{`try { throw "kept"; } finally { println("x"); }`, "ERROR: kept"},
+		{`try { throw "a"; } catch (e) { throw "b"; }`, "ERROR: b"},
+		{`try { 1; } finally { throw "from finally"; }`, "ERROR: from finally"},
+	}
+
+	for _, tt := range tests {
+		p := parser.NewParser(tt.input)
+		root := p.Parse()
+		ev := NewEvaluator()
+		ev.SetParser(p)
+		ev.SetWriter(&strings.Builder{})
+		result := ev.Eval(root)
+		if !IsError(result) {
+			t.Errorf("for input '%s': expected error, got %s", tt.input, result.ToObject())
+			continue
+		}
+		if !strings.Contains(result.ToString(), tt.errorMessage) {
+			t.Errorf("for input '%s': expected error containing '%s', got '%s'", tt.input, tt.errorMessage, result.ToString())
+		}
+	}
+}
+
+// TestEvaluator_Limits verifies that every execution limit stops runaway programs with a LimitError
+func TestEvaluator_Limits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		input  string
+		limits Limits
+		ctx    context.Context
+		limit  string
+	}{
+		{"steps", "while (true) {}", Limits{MaxSteps: 10000}, nil, std.LimitSteps},
+		{"steps in function", "func spin() { for (;;) {} } spin();", Limits{MaxSteps: 10000}, nil, std.LimitSteps},
+		{"timeout", "var i = 0; while (true) { i = i + 1; }", Limits{Timeout: 20 * time.Millisecond}, nil, std.LimitTimeout},
+		{"call depth", "func f(n) { return f(n + 1); } f(0);", Limits{MaxCallDepth: 50}, nil, std.LimitCallDepth},
+		{"default call depth", "func f(n) { return 1 + f(n + 1); } f(0);", Limits{}, nil, std.LimitCallDepth},
+		{"allocation", `var s = ""; while (true) { s = s + "0123456789"; }`, Limits{MaxAlloc: 1 << 20}, nil, std.LimitAlloc},
+		{"allocation by arrays", "for (;;) { var a = [1, 2, 3, 4, 5, 6, 7, 8]; }", Limits{MaxAlloc: 1 << 16}, nil, std.LimitAlloc},
+		{"canceled", "while (true) {}", Limits{}, canceled, std.LimitCanceled},
+		{"not caught", "while (true) { try { var x = 1; } catch (e) {} }", Limits{MaxSteps: 5000}, nil, std.LimitSteps},
+		{"catch does not run", "try { while (true) {} } catch (e) { println(\"caught\"); }", Limits{MaxSteps: 5000}, nil, std.LimitSteps},
+		{"builtin callback", "func spin(a, b) { while (true) {} } csort_array([1, 2, 3], spin);", Limits{MaxSteps: 5000}, nil, std.LimitSteps},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(tt.input)
+			root := p.Parse()
+			if p.HasErrors() {
+				t.Fatalf("parser errors: %v", p.GetErrors())
+			}
+			var out strings.Builder
+			ev := NewEvaluator()
+			ev.SetParser(p)
+			ev.SetWriter(&out)
+			ev.SetLimits(tt.limits)
+			ev.SetContext(tt.ctx)
+			result := ev.Eval(root)
+			limitErr, ok := result.(*std.LimitError)
+			if !ok {
+				t.Fatalf("expected a LimitError, got %s", result.ToObject())
+			}
+			if limitErr.Limit != tt.limit {
+				t.Errorf("expected limit %q, got %q (%s)", tt.limit, limitErr.Limit, limitErr.Message)
+			}
+			if !IsError(result) {
+				t.Errorf("a LimitError must be an error")
+			}
+			if out.String() != "" {
+				t.Errorf("unexpected output: %q", out.String())
+			}
+		})
+	}
+}
+
+// TestEvaluator_LimitPositions verifies that a limit reports the loop it stopped,
+// not the end of the source, on both the tree walker and the VM
+func TestEvaluator_LimitPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		limits Limits
+		frames []string // "function:line", innermost first; nil for no traceback
+	}{
+		{"while", "var a = 1;\nvar i = 0;\nwhile (true) { }\n\n", Limits{MaxSteps: 1000}, nil},
+		{"for", "var a = 1;\n\nfor (;;) { }\n\n", Limits{MaxSteps: 1000}, nil},
+		{"foreach", "var a = 1;\n\nforeach x in 1...100000000 { }\n\n", Limits{Timeout: 20 * time.Millisecond}, nil},
+		{"in function", "var a = 1;\nfunc spin() {\n    while (true) { }\n}\nspin();\n\n", Limits{MaxSteps: 1000}, []string{"spin:3", "<main>:5"}},
+	}
+
+	for _, vm := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s vm=%v", tt.name, vm), func(t *testing.T) {
+				p := parser.NewParser(tt.input)
+				root := p.Parse()
+				if p.HasErrors() {
+					t.Fatalf("parser errors: %v", p.GetErrors())
+				}
+				ev := NewEvaluator()
+				ev.UseVM = vm
+				ev.SetParser(p)
+				ev.SetLimits(tt.limits)
+				limitErr, ok := ev.Eval(root).(*std.LimitError)
+				if !ok {
+					t.Fatalf("expected a LimitError")
+				}
+				if limitErr.Line != 3 || !strings.HasPrefix(limitErr.Message, "[3:") {
+					t.Errorf("expected the error on line 3, got %s", limitErr.Message)
+				}
+				var frames []string
+				for _, frame := range limitErr.Trace {
+					frames = append(frames, fmt.Sprintf("%s:%d", frame.Function, frame.Line))
+				}
+				if strings.Join(frames, " ") != strings.Join(tt.frames, " ") {
+					t.Errorf("expected frames %v, got %v", tt.frames, frames)
+				}
+			})
+		}
+	}
+}
+
+// TestEvaluator_LimitsPerProgram verifies that limits apply to each program separately
+func TestEvaluator_LimitsPerProgram(t *testing.T) {
+	ev := NewEvaluator()
+	ev.SetLimits(Limits{MaxSteps: 2000})
+	run := func(input string) std.GoMixObject {
+		p := parser.NewParser(input)
+		ev.SetParser(p)
+		return ev.Eval(p.Parse())
+	}
+
+	if res := run("var n = 0; while (true) { n = n + 1; }"); !IsLimitError(res) {
+		t.Fatalf("expected a LimitError, got %s", res.ToObject())
+	}
+	// the variable survives and the next program gets a fresh step budget
+	res := run("n > 10")
+	if IsError(res) {
+		t.Fatalf("unexpected error: %s", res.ToString())
+	}
+	AssertBoolean(t, res, true)
+
+	run("var sum = 0;")
+	for i := 0; i < 3; i++ {
+		if res := run("sum = 0; for (var i = 0; i < 50; i = i + 1) { sum = sum + i; } sum"); IsError(res) {
+			t.Fatalf("run %d: unexpected error: %s", i, res.ToString())
+		}
+	}
+}
+
+// TestEvaluator_Permissions verifies that host-access builtins respect the granted capabilities
+func TestEvaluator_Permissions(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{"data.txt": "inside"})
+	writeModuleFiles(t, other, map[string]string{"data.txt": "outside"})
+	inside := filepath.ToSlash(filepath.Join(dir, "data.txt"))
+	outside := filepath.ToSlash(filepath.Join(other, "data.txt"))
+
+	perms := std.Restricted()
+	perms.Grant(std.CapFSRead, dir)
+	perms.Grant(std.CapFSWrite, filepath.Join(dir, "out"))
+
+	tests := []struct {
+		input    string
+		expected string // expected result, or the start of the expected error
+		isError  bool
+	}{
+		{fmt.Sprintf(`read_file("%s")`, inside), "inside", false},
+		{fmt.Sprintf(`file_exists("%s")`, inside), "true", false},
+		{fmt.Sprintf(`read_file("%s")`, outside), "ERROR: permission denied: `read_file` needs fs-read access", true},
+		{fmt.Sprintf(`read_file("%s/../%s/data.txt")`, dir, filepath.Base(other)), "ERROR: permission denied", true},
+		{fmt.Sprintf(`write_file("%s", "x")`, inside), "ERROR: permission denied: `write_file` needs fs-write access", true},
+		{fmt.Sprintf(`mkdir("%s/out/sub")`, dir), "nil", false},
+		{fmt.Sprintf(`copy_file("%s", "%s/out/copy.txt")`, inside, dir), "nil", false},
+		{fmt.Sprintf(`copy_file("%s", "%s/out/copy.txt")`, outside, dir), "ERROR: permission denied: `copy_file` needs fs-read access", true},
+		{`getenv("HOME")`, "ERROR: permission denied: `getenv` needs the env capability", true},
+		{`exec("echo", "hi")`, "ERROR: permission denied: `exec` needs the process capability", true},
+		{`exit(3)`, "ERROR: permission denied: `exit` needs the process capability", true},
+		{`get_http("http://127.0.0.1:1/")`, "ERROR: permission denied: `get_http` needs the network capability", true},
+		{`try { getenv("HOME"); } catch (e) { "caught" }`, "caught", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := parser.NewParser(tt.input)
+			root := p.Parse()
+			if p.HasErrors() {
+				t.Fatalf("parser errors: %v", p.GetErrors())
+			}
+			ev := NewEvaluator()
+			ev.SetParser(p)
+			ev.SetPermissions(perms)
+			result := ev.Eval(root)
+			if IsError(result) != tt.isError {
+				t.Fatalf("expected error=%v, got %s", tt.isError, result.ToObject())
+			}
+			if !strings.HasPrefix(result.ToString(), tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, result.ToString())
+			}
+		})
+	}
+}
+
+// TestEvaluator_PermissionsModules verifies that importing a module needs fs-read access to it
+func TestEvaluator_PermissionsModules(t *testing.T) {
+	dir := t.TempDir()
+	libDir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"local.gm": `func one() { return 1; }`,
+		"main.gm":  `import "local.gm"; import "shared.gm"; local.one() + shared.two();`,
+	})
+	writeModuleFiles(t, libDir, map[string]string{
+		"shared.gm": `func two() { return getenv("TWO"); }`,
+	})
+	t.Setenv(ModulePathEnv, libDir)
+	t.Setenv("TWO", "2")
+
+	run := func(perms *std.Permissions) std.GoMixObject {
+		saved := DefaultPermissions
+		DefaultPermissions = perms
+		defer func() { DefaultPermissions = saved }()
+		result, _ := evalModuleProgram(t, dir)
+		return result
+	}
+
+	perms := std.Restricted()
+	perms.Grant(std.CapFSRead, dir)
+	result := run(perms)
+	if !IsError(result) || !strings.Contains(result.ToString(), "importing module 'shared.gm' needs fs-read access") {
+		t.Errorf("expected a permission error for shared.gm, got %s", result.ToObject())
+	}
+
+	// modules run with the permissions of the importing program
+	perms.Grant(std.CapFSRead, libDir)
+	result = run(perms)
+	if !IsError(result) || !strings.Contains(result.ToString(), "`getenv` needs the env capability") {
+		t.Errorf("expected a permission error from the module, got %s", result.ToObject())
+	}
+}
+
+// TestEvaluator_Traceback verifies that errors leaving a function carry the call stack
+func TestEvaluator_Traceback(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"shapes.gm": `struct Box {
+    func init(w) { this.w = w; }
+    func area(h) {
+        return this.w * h + missing;
+    }
+}
+func total(b) {
+    return b.area(2);
+}`,
+		"main.gm": `import "shapes.gm";
+func run() {
+    var b = new shapes.Box(3);
+    return shapes.total(b);
+}
+var ok = 1;
+run();`,
+	})
+
+	result, _ := evalModuleProgram(t, dir)
+	err, isErr := result.(*std.Error)
+	if !isErr {
+		t.Fatalf("expected an error, got %s", result.ToObject())
+	}
+	if err.Line != 4 {
+		t.Errorf("expected the error on line 4, got %d (%s)", err.Line, err.Message)
+	}
+
+	expected := []struct {
+		function string
+		file     string
+		line     int
+	}{
+		{"Box.area", "shapes.gm", 4},
+		{"total", "shapes.gm", 8},
+		{"run", "main.gm", 4},
+		{"<main>", "main.gm", 7},
+	}
+	if len(err.Trace) != len(expected) {
+		t.Fatalf("expected %d frames, got %v", len(expected), err.Trace)
+	}
+	for i, want := range expected {
+		got := err.Trace[i]
+		if got.Function != want.function || filepath.Base(got.File) != want.file || got.Line != want.line {
+			t.Errorf("frame %d: expected %s (%s:%d), got %s (%s:%d)", i, want.function, want.file, want.line, got.Function, filepath.Base(got.File), got.Line)
+		}
+	}
+	if tb := std.Traceback(result); !strings.HasPrefix(tb, "Traceback (most recent call first):\n  at Box.area (") {
+		t.Errorf("unexpected traceback:\n%s", tb)
+	}
+}
+
+// TestEvaluator_TracebackPositions verifies error positions and tracebacks for programs from strings
+func TestEvaluator_TracebackPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		frames []string // "function:line", innermost first; nil for no traceback
+	}{
+		{"top level", "var a = 1;\nvar b = a / 0;\nvar c = 2;", 2, nil},
+		{"nested calls", "func inner(x) {\n    return x / 0;\n}\nfunc outer() {\n    return inner(1);\n}\nouter();\nvar z = 1;", 2, []string{"inner:2", "outer:5", "<main>:7"}},
+		{"anonymous", "var f = func(x) {\n    return x + true;\n};\nf(1);", 2, []string{"f:2", "<main>:4"}},
+		{"builtin callback", "func boom(x) {\n    throw \"bad\";\n}\nmap_array([1], boom);", 2, []string{"boom:2", "<main>:4"}},
+		{"caught and rethrown", "func g() {\n    try { return 1 / 0; } catch (e) { throw \"again\"; }\n}\ng();", 2, []string{"g:2", "<main>:4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(tt.input)
+			root := p.Parse()
+			if p.HasErrors() {
+				t.Fatalf("parser errors: %v", p.GetErrors())
+			}
+			ev := NewEvaluator()
+			ev.SetParser(p)
+			result := ev.Eval(root)
+			err, ok := result.(*std.Error)
+			if !ok {
+				t.Fatalf("expected an error, got %s", result.ToObject())
+			}
+			if err.Line != tt.line {
+				t.Errorf("expected the error on line %d, got %s", tt.line, err.Message)
+			}
+			var frames []string
+			for _, frame := range err.Trace {
+				frames = append(frames, fmt.Sprintf("%s:%d", frame.Function, frame.Line))
+			}
+			if strings.Join(frames, " ") != strings.Join(tt.frames, " ") {
+				t.Errorf("expected frames %v, got %v", tt.frames, frames)
+			}
+		})
+	}
+}
+
+// TestEvaluator_OperationPositions verifies that index, slice, range and
+// foreach errors inside functions give the position of the failing expression
+// and the right traceback, on both the tree walker and the VM
+func TestEvaluator_OperationPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		prefix string   // "[line:column]" of the error
+		frames []string // "function:line", innermost first
+	}{
+		{"index", "func inner(a) {\n    return a[5];\n}\nfunc outer() {\n    var x = 1;\n    return inner([1, 2]);\n}\nouter();", "[2:14]", []string{"inner:2", "outer:6", "<main>:8"}},
+		{"index assignment", "func inner(a) {\n    a[9] = 1;\n}\ninner([1]);", "[2:7]", []string{"inner:2", "<main>:4"}},
+		{"compound index assignment", "func inner(a) {\n    a[9] += 1;\n}\ninner([1]);", "[2:7]", []string{"inner:2", "<main>:4"}},
+		{"slice", "func inner(a) {\n    var b = a[1:\"x\"];\n}\ninner([1]);", "[2:15]", []string{"inner:2", "<main>:4"}},
+		{"range", "func inner(a) {\n    var r = 1...a;\n}\ninner(\"s\");", "[2:17]", []string{"inner:2", "<main>:4"}},
+		{"foreach", "func inner(a) {\n    foreach x in a { }\n}\ninner(5);", "[2:13]", []string{"inner:2", "<main>:4"}},
+	}
+
+	for _, vm := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s vm=%v", tt.name, vm), func(t *testing.T) {
+				p := parser.NewParser(tt.input)
+				root := p.Parse()
+				if p.HasErrors() {
+					t.Fatalf("parser errors: %v", p.GetErrors())
+				}
+				ev := NewEvaluator()
+				ev.UseVM = vm
+				ev.SetParser(p)
+				result := ev.Eval(root)
+				err, ok := result.(*std.Error)
+				if !ok {
+					t.Fatalf("expected an error, got %s", result.ToObject())
+				}
+				if !strings.HasPrefix(err.Message, tt.prefix+" ") {
+					t.Errorf("expected the error at %s, got %s", tt.prefix, err.Message)
+				}
+				var frames []string
+				for _, frame := range err.Trace {
+					frames = append(frames, fmt.Sprintf("%s:%d", frame.Function, frame.Line))
+				}
+				if strings.Join(frames, " ") != strings.Join(tt.frames, " ") {
+					t.Errorf("expected frames %v, got %v", tt.frames, frames)
+				}
+			})
+		}
+	}
+}
+
+// TestEvaluator_StatementPositions verifies that errors raised by statements
+// themselves (rather than by one of their operations) have the same position
+// and traceback on the VM as on the tree walker
+func TestEvaluator_StatementPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		prefix string // "[line:column]" of the error on the tree walker
+	}{
+		{"redeclaration in a function", "func f() {\n  var x = 0;\n  var y = 1; var y = 2;\n}\n\n  f();\n", "[3:18]"},
+		{"redeclaration at top level", "var z = 1;\nvar z = 2;\n\n", "[2:5]"},
+		{"typed declaration", "func f() {\n    var n: int = 1;\n    var s: int = \"x\";\n}\nf();", "[3:11]"},
+		{"delegated statement", "func f(c) {\n    var a = 1;\n    switch (c) { case 1: var b = 1; var b = 2; }\n}\nf(1);", "[3:41]"},
+		{"function redeclaration", "var q = 1;\nfunc g() { }\nfunc g() { }\n", "[3:6]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages, traces [2]string
+			for i, vm := range []bool{false, true} {
+				p := parser.NewParser(tt.input)
+				root := p.Parse()
+				if p.HasErrors() {
+					t.Fatalf("parser errors: %v", p.GetErrors())
+				}
+				ev := NewEvaluator()
+				ev.UseVM = vm
+				ev.SetParser(p)
+				result := ev.Eval(root)
+				err, ok := result.(*std.Error)
+				if !ok {
+					t.Fatalf("vm=%v: expected an error, got %s", vm, result.ToObject())
+				}
+				messages[i], traces[i] = err.Message, std.Traceback(err)
+			}
+			if !strings.HasPrefix(messages[0], tt.prefix+" ") {
+				t.Errorf("expected the error at %s, got %s", tt.prefix, messages[0])
+			}
+			if messages[1] != messages[0] || traces[1] != traces[0] {
+				t.Errorf("the VM reports\n%s\n%s\nthe tree walker reports\n%s\n%s", messages[1], traces[1], messages[0], traces[0])
+			}
+		})
+	}
+}
+
+// TestEvaluator_Spawn verifies goroutines, channels, mutexes and wait groups
+func TestEvaluator_Spawn(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"unbuffered", `var ch = make_chan(); spawn send_chan(ch, "hi"); println(recv_chan(ch));`, "hi\n"},
+		{"arguments are evaluated at the spawn", `
+var ch = make_chan(3);
+func put(x) { send_chan(ch, x); }
+for (var i = 1; i <= 3; i = i + 1) { spawn put(i * 10); }
+var sum = 0;
+foreach k in 1...3 { sum = sum + recv_chan(ch); }
+println(sum);`, "60\n"},
+		{"workers and foreach over a channel", `
+func worker(jobs, results, wg) {
+    foreach j in jobs { send_chan(results, j * j); }
+    done_waitgroup(wg);
+}
+var jobs = make_chan(2);
+var results = make_chan(10);
+var wg = make_waitgroup();
+add_waitgroup(wg, 3);
+foreach w in 1...3 { spawn worker(jobs, results, wg); }
+foreach n in 1...4 { send_chan(jobs, n); }
+close_chan(jobs);
+wait_waitgroup(wg);
+close_chan(results);
+var total = 0;
+foreach r in results { total = total + r; }
+println(total, size_chan(results), recv_chan(results));`, "30 0 nil\n"},
+		{"mutex", `
+var m = make_mutex();
+var count = 0;
+var wg = make_waitgroup();
+func inc() {
+    for (var i = 0; i < 300; i = i + 1) { lock_mutex(m); count = count + 1; unlock_mutex(m); }
+    done_waitgroup(wg);
+}
+add_waitgroup(wg, 4);
+foreach w in 1...4 { spawn inc(); }
+wait_waitgroup(wg);
+println(count);`, "1200\n"},
+		{"method and package calls", `
+import "sync";
+struct Counter {
+    func init() { this.n = 0; }
+    func run(ch, k) { this.n = this.n + k; sync.send_chan(ch, this.n); }
+}
+var c = new Counter();
+var ch = make_chan();
+spawn c.run(ch, 5);
+println(recv_chan(ch));
+spawn sync.send_chan(ch, 7);
+println(sync.recv_chan(ch));`, "5\n7\n"},
+		{"select", `
+var a = make_chan();
+var b = make_chan(1);
+send_chan(b, 42);
+println(select_chan([a, b]));
+println(select_chan([a, b], 0));
+println(select_chan([a], 10));
+close_chan(a);
+println(select_chan([a, b]));`, "[1, 42]\n[-1, nil]\n[-1, nil]\n[0, nil]\n"},
+		{"goroutine errors are reported", `
+func boom() { return 1 / 0; }
+var done = make_chan();
+func after() { sleep(5); close_chan(done); }
+spawn boom();
+spawn after();
+recv_chan(done);
+println("main goes on");`, "[2:25] ERROR: division by zero\nTraceback (most recent call first):\n  at boom (line 2)\n  at <spawn> (line 5)\nmain goes on\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(tt.input)
+			root := p.Parse()
+			if p.HasErrors() {
+				t.Fatalf("parser errors: %v", p.GetErrors())
+			}
+			var out strings.Builder
+			ev := NewEvaluator()
+			ev.SetParser(p)
+			ev.SetWriter(&out)
+			if result := ev.Eval(root); IsError(result) {
+				t.Fatalf("unexpected error: %s", result.ToString())
+			}
+			if out.String() != tt.expected {
+				t.Errorf("expected output %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+// TestEvaluator_SpawnErrors verifies deadlock detection and misuse of channels and sync objects
+func TestEvaluator_SpawnErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"receive with no sender", "var ch = make_chan(); recv_chan(ch);", "deadlock: all goroutines are waiting"},
+		{"all goroutines wait", "var a = make_chan(); var b = make_chan(); spawn recv_chan(a); recv_chan(b);", "deadlock"},
+		{"unreceived unbuffered send", "var ch = make_chan(); send_chan(ch, 1);", "deadlock"},
+		{"send on closed channel", "var ch = make_chan(1); close_chan(ch); send_chan(ch, 1);", "send on closed channel"},
+		{"double close", "var ch = make_chan(); close_chan(ch); close_chan(ch);", "close of closed channel"},
+		{"unlock of unlocked mutex", "unlock_mutex(make_mutex());", "unlock of unlocked mutex"},
+		{"negative waitgroup", "done_waitgroup(make_waitgroup());", "negative waitgroup counter"},
+		{"spawn of an unknown function", "spawn nowhere(1);", "function not found: (nowhere)"},
+		{"spawn of a non-function", "var x = 1; spawn x();", "not a function: (x)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewParser(tt.input)
+			root := p.Parse()
+			if p.HasErrors() {
+				t.Fatalf("parser errors: %v", p.GetErrors())
+			}
+			var out strings.Builder
+			ev := NewEvaluator()
+			ev.SetParser(p)
+			ev.SetWriter(&out)
+			result := ev.Eval(root)
+			if !IsError(result) || !strings.Contains(result.ToString(), tt.err) {
+				t.Errorf("expected an error containing %q, got %s", tt.err, result.ToObject())
+			}
+		})
+	}
+}
+
+// TestEvaluator_SpawnLimits verifies that waiting goroutines stop when the program times out
+func TestEvaluator_SpawnLimits(t *testing.T) {
+	p := parser.NewParser("func spin() { while (true) {} } spawn spin(); var ch = make_chan(); spawn sleep(1000); recv_chan(ch);")
+	root := p.Parse()
+	var out strings.Builder
+	ev := NewEvaluator()
+	ev.SetParser(p)
+	ev.SetWriter(&out)
+	ev.SetLimits(Limits{Timeout: 30 * time.Millisecond})
+	result := ev.Eval(root)
+	limitErr, ok := result.(*std.LimitError)
+	if !ok || limitErr.Limit != std.LimitTimeout {
+		t.Fatalf("expected a timeout, got %s", result.ToObject())
+	}
+}
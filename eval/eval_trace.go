@@ -0,0 +1,142 @@
+/*
+File    : go-mix/eval/eval_trace.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"github.com/akashmaji946/go-mix/function"
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// callFrame is one active call of the running program: a function, a method
+// or the body of an imported module.
+type callFrame struct {
+	name   string // Function name, "Struct.method" or "<module>"
+	file   string // Source file of the called code ("" if not read from a file)
+	line   int    // Position of the call in the caller, restored when the call returns
+	column int
+}
+
+// callStack tracks the active calls of the running program (or goroutine)
+// and the position of the code being evaluated. It is shared by an evaluator
+// and the evaluators of the modules it imports, so that a traceback spans
+// module boundaries.
+type callStack struct {
+	frames []callFrame
+	root   string // Name of the outermost frame ("<main>" or "<spawn>")
+	file   string // Source file of the program (the outermost frame)
+	line   int    // Position of the node being evaluated (0 if unknown)
+	column int
+	depth  int // Current call depth, checked against the call depth limit
+}
+
+// reset prepares the call stack for a new program run from file.
+func (c *callStack) reset(file string) {
+	c.frames = c.frames[:0]
+	c.file = file
+	c.line, c.column = 0, 0
+	c.depth = 0
+}
+
+// at records the position of the code being evaluated. Errors created with
+// CreateError are reported at this position, and calls remember it as their
+// call site.
+func (e *Evaluator) at(token lexer.Token) {
+	e.trace.line, e.trace.column = token.Line, token.Column
+}
+
+// position returns the position of the code being evaluated (0, 0 before
+// any position is known).
+func (e *Evaluator) position() (int, int) {
+	return e.trace.line, e.trace.column
+}
+
+// pushFrame records the start of a call made at the current position.
+//
+// Parameters:
+//   - name: The name reported in tracebacks
+//   - file: The source file of the called code
+func (e *Evaluator) pushFrame(name, file string) {
+	e.trace.frames = append(e.trace.frames, callFrame{name: name, file: file, line: e.trace.line, column: e.trace.column})
+}
+
+// popFrame records the end of the innermost call and restores the position of
+// its call site (see unwindFrames).
+//
+// Parameters:
+//   - result: The result of the call
+//
+// Returns:
+//   - std.GoMixObject: result
+func (e *Evaluator) popFrame(result std.GoMixObject) std.GoMixObject {
+	return e.unwindFrames(result, len(e.trace.frames)-1)
+}
+
+// unwindFrames ends the innermost calls until n frames are left, restoring
+// the position of the outermost call site that is removed. If the calls
+// failed, the call stack is first attached to the error (unless a call
+// deeper down already did so).
+//
+// Parameters:
+//   - result: The result of the innermost call
+//   - n: The number of frames to keep
+//
+// Returns:
+//   - std.GoMixObject: result
+func (e *Evaluator) unwindFrames(result std.GoMixObject, n int) std.GoMixObject {
+	if n >= len(e.trace.frames) {
+		return result
+	}
+	if err := errorBase(result); err != nil && err.Trace == nil {
+		line := err.Line
+		if line == 0 {
+			line = e.trace.line
+		}
+		err.Trace = e.traceback(line)
+	}
+	frame := e.trace.frames[n]
+	e.trace.line, e.trace.column = frame.line, frame.column
+	e.trace.frames = e.trace.frames[:n]
+	return result
+}
+
+// traceback returns the active calls, innermost first, for an error raised at
+// line in the innermost call. Each frame reports the line it had reached: the
+// error line for the innermost frame and the call site of the next frame for
+// the others. The program (or the spawn of a goroutine) is the last frame.
+func (e *Evaluator) traceback(line int) []std.Frame {
+	frames := e.trace.frames
+	trace := make([]std.Frame, 0, len(frames)+1)
+	for i := len(frames) - 1; i >= 0; i-- {
+		trace = append(trace, std.Frame{Function: frames[i].name, File: frames[i].file, Line: line})
+		line = frames[i].line
+	}
+	return append(trace, std.Frame{Function: e.trace.root, File: e.trace.file, Line: line})
+}
+
+// functionFrameName returns the name of a function call in tracebacks:
+// the function's declared name, else the name it was called by.
+func functionFrameName(fn *function.Function, calledAs string) string {
+	switch {
+	case fn.Name != "":
+		return fn.Name
+	case calledAs != "":
+		return calledAs
+	default:
+		return "<anonymous>"
+	}
+}
+
+// errorBase returns the Error of an *std.Error or *std.LimitError, or nil.
+func errorBase(obj std.GoMixObject) *std.Error {
+	switch err := obj.(type) {
+	case *std.Error:
+		return err
+	case *std.LimitError:
+		return &err.Error
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+/*
+File    : go-mix/eval/eval_types.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package eval - eval_types.go
+// This file enforces the type annotations of variables, parameters, return
+// values and struct fields at runtime (see std/annotations.go). The checker
+// package verifies them before execution where it can; the evaluator checks
+// every value as it is assigned, whether or not the checker was run.
+package eval
+
+import (
+	"github.com/akashmaji946/go-mix/function"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// conform checks that val conforms to the annotated type typ.
+//
+// An int conforms to float; when it is stored in a float variable (not as an
+// element of a collection) it is converted, so that the variable does hold a
+// float.
+//
+// Returns:
+//   - std.GoMixObject: val, converted to float if needed
+//   - bool: false if val does not conform to typ
+func (e *Evaluator) conform(val std.GoMixObject, typ *std.TypeAnnotation) (std.GoMixObject, bool) {
+	if typ == nil {
+		return val, true
+	}
+	if !e.conforms(val, typ) {
+		return val, false
+	}
+	if i, isInt := val.(*std.Integer); isInt && !acceptsInt(typ) {
+		return &std.Float{Value: float64(i.Value)}, true
+	}
+	return val, true
+}
+
+// conforms reports whether val is a value of the annotated type typ.
+//
+// The elements of an annotated array, list, tuple or set and the keys and
+// values of an annotated map are checked too.
+// Names that are not builtin types must resolve to a struct (whose instances
+// and the instances of the structs extending it conform), an interface (the
+// instances of the structs implementing it) or an enum (its member values).
+func (e *Evaluator) conforms(val std.GoMixObject, typ *std.TypeAnnotation) bool {
+	if typ.IsUnion() {
+		for _, alt := range typ.Args {
+			if e.conforms(val, alt) {
+				return true
+			}
+		}
+		return false
+	}
+	switch typ.Name {
+	case std.AnyType:
+		return true
+	case string(std.FloatType):
+		return val.GetType() == std.FloatType || val.GetType() == std.IntegerType
+	case string(std.IntegerType):
+		// An int operation that overflows gives a bigint
+		return val.GetType() == std.IntegerType || val.GetType() == std.BigIntType
+	case string(std.ArrayType), string(std.ListType):
+		if string(val.GetType()) != typ.Name {
+			return false
+		}
+		return typ.Arg(0) == nil || e.allConform(elementsOf(val), typ.Arg(0))
+	case string(std.SetType):
+		set, ok := val.(*std.Set)
+		if !ok {
+			return false
+		}
+		return typ.Arg(0) == nil || e.allConform(set.Values(), typ.Arg(0))
+	case string(std.TupleType):
+		tuple, ok := val.(*std.Tuple)
+		if !ok {
+			return false
+		}
+		if len(typ.Args) == 0 {
+			return true
+		}
+		if len(tuple.Elements) != len(typ.Args) {
+			return false
+		}
+		for i, elem := range tuple.Elements {
+			if !e.conforms(elem, typ.Args[i]) {
+				return false
+			}
+		}
+		return true
+	case string(std.MapType):
+		m, ok := val.(*std.Map)
+		if !ok {
+			return false
+		}
+		if typ.Arg(1) == nil {
+			return true
+		}
+		for _, pair := range m.Entries() {
+			if !e.conforms(pair.Key, typ.Arg(0)) || !e.conforms(pair.Value, typ.Arg(1)) {
+				return false
+			}
+		}
+		return true
+	}
+	if typ.IsBuiltin() {
+		return string(val.GetType()) == typ.Name
+	}
+
+	declared, ok := e.lookUpQualified(typ.Name)
+	if !ok {
+		return false
+	}
+	switch t := declared.(type) {
+	case *std.GoMixStruct, *std.GoMixInterface:
+		res, isBool := e.instanceOf(val, t).(*std.Boolean)
+		return isBool && res.Value
+	case *std.GoMixEnum:
+		for _, member := range t.Members {
+			if member.GetType() == val.GetType() && member.ToString() == val.ToString() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allConform reports whether every element conforms to typ.
+func (e *Evaluator) allConform(elements []std.GoMixObject, typ *std.TypeAnnotation) bool {
+	for _, elem := range elements {
+		if !e.conforms(elem, typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// elementsOf returns the elements of an array or a list.
+func elementsOf(val std.GoMixObject) []std.GoMixObject {
+	switch v := val.(type) {
+	case *std.Array:
+		return v.Elements
+	case *std.List:
+		return v.Elements
+	}
+	return nil
+}
+
+// acceptsInt reports whether an int is a value of typ without conversion.
+func acceptsInt(typ *std.TypeAnnotation) bool {
+	if typ.IsUnion() {
+		for _, alt := range typ.Args {
+			if acceptsInt(alt) {
+				return true
+			}
+		}
+		return false
+	}
+	return typ.Name == std.AnyType || typ.Name == string(std.IntegerType) || !typ.IsBuiltin()
+}
+
+// typeOf describes the type of val in type errors: the struct name of an
+// instance, otherwise its type (e.g., "int").
+func typeOf(val std.GoMixObject) string {
+	if inst, ok := val.(*std.GoMixObjectInstance); ok && inst.Struct != nil {
+		return inst.Struct.GetName()
+	}
+	return string(val.GetType())
+}
+
+// checkResult checks the value a function body returned against the
+// declared return type of fn.
+//
+// Parameters:
+//   - fn: The called function
+//   - name: The name of the call (see functionFrameName)
+//   - result: The raw result of the body (a ReturnValue for an explicit return)
+//
+// Returns:
+//   - std.GoMixObject: result, with the returned value converted if needed
+//     (see conform), or an Error if it does not conform
+func (e *Evaluator) checkResult(fn *function.Function, name string, result std.GoMixObject) std.GoMixObject {
+	if fn.Result == nil || IsError(result) {
+		return result
+	}
+	val, ok := e.conform(UnwrapReturnValue(result), fn.Result)
+	if !ok {
+		return e.CreateError("ERROR: function (%s) must return `%s`, got `%s`", name, fn.Result, typeOf(UnwrapReturnValue(result)))
+	}
+	if _, isReturn := result.(*std.ReturnValue); isReturn {
+		return &std.ReturnValue{Value: val}
+	}
+	return val
+}
+
+// conformField checks a value assigned to a field of an instance of s, or to
+// a static field of s, against the annotated type of the field.
+//
+// Returns:
+//   - std.GoMixObject: val, converted if needed (see conform), or an Error
+//     if it does not conform
+func (e *Evaluator) conformField(s *std.GoMixStruct, name string, val std.GoMixObject) std.GoMixObject {
+	typ := s.LookUpFieldType(name)
+	conformed, ok := e.conform(val, typ)
+	if !ok {
+		return e.CreateError("ERROR: can't assign `%s` to field (%s) of type `%s` in struct (%s)", typeOf(val), name, typ, s.Name)
+	}
+	return conformed
+}
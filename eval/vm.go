@@ -0,0 +1,629 @@
+/*
+File    : go-mix/eval/vm.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"github.com/akashmaji946/go-mix/function"
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/scope"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// VMByDefault makes NewEvaluator return evaluators with UseVM set.
+// The --vm command line flag turns it on for the whole run, including the
+// evaluators of imported modules.
+var VMByDefault = false
+
+// vmFrame is the activation record of one compiled function call.
+//
+// Fields:
+//   - code: The bytecode being executed
+//   - ip: The offset of the next instruction
+//   - base: The stack height when the frame was entered
+//   - callScope: The scope the function body runs in (used for returned closures)
+//   - caller: The scope to restore when the frame returns
+type vmFrame struct {
+	code      *Bytecode
+	ip        int
+	base      int
+	callScope *scope.Scope
+	caller    *scope.Scope
+	fn        *function.Function // The called function (nil for the outermost frame)
+	name      string             // The name of the call (see functionFrameName)
+}
+
+// frameResult checks the result of a frame against the declared return type
+// of its function (see checkResult). The result of the outermost frame is
+// checked by runFunctionBody.
+func (e *Evaluator) frameResult(f *vmFrame, result std.GoMixObject) std.GoMixObject {
+	if f.fn == nil {
+		return result
+	}
+	return e.checkResult(f.fn, f.name, result)
+}
+
+// vmIterator walks the elements of a foreach iterable.
+// It is created by OpIterInit and lives on the stack for the duration of the loop.
+type vmIterator struct {
+	it  std.Iterator
+	gen *std.Generator // The generator the loop owns and closes when it ends (see ownedGenerator)
+}
+
+// closeIterators closes the generators owned by the foreach loops whose
+// iterators are in values, when the loops end by a return or an error.
+func closeIterators(values []std.GoMixObject) {
+	for _, obj := range values {
+		if it, ok := obj.(*vmIterator); ok && it.gen != nil {
+			it.gen.Close()
+		}
+	}
+}
+
+// GetType returns the iterator type.
+func (it *vmIterator) GetType() std.GoMixType { return "iterator" }
+
+// ToString returns a placeholder, iterators are internal to the VM.
+func (it *vmIterator) ToString() string { return "iterator" }
+
+// ToObject returns a placeholder, iterators are internal to the VM.
+func (it *vmIterator) ToObject() string { return "<iterator>" }
+
+// runProgram runs a program on the VM, falling back to the tree walker for
+// programs the compiler cannot translate.
+func (e *Evaluator) runProgram(root *parser.RootNode) std.GoMixObject {
+	code, err := e.Compile(root)
+	if err != nil {
+		return UnwrapReturnValue(e.evalStatements(root.Statements))
+	}
+	return UnwrapReturnValue(e.runBytecode(code, e.Scp))
+}
+
+// runBytecode executes compiled code in the given scope.
+//
+// The result follows the tree walker's convention for statement lists: a
+// ReturnValue if the code executed a return statement, an Error if evaluation
+// failed, otherwise the value of the last statement.
+//
+// Parameters:
+//   - code: The compiled program or function body
+//   - scp: The scope to run in (the global scope or a call-site scope)
+//
+// Returns:
+//   - std.GoMixObject: The result of the code
+func (e *Evaluator) runBytecode(code *Bytecode, scp *scope.Scope) std.GoMixObject {
+	saved := e.Scp
+	e.Scp = scp
+	result := e.execute(code)
+	e.Scp = saved
+	return result
+}
+
+// execute is the VM's fetch-decode-execute loop.
+//
+// Values are kept on an operand stack. Calls of compiled functions push a frame
+// instead of recursing; e.Scp always holds the scope of the running code so
+// that the evaluator's helpers (and OpEval) see the right variables.
+func (e *Evaluator) execute(code *Bytecode) (result std.GoMixObject) {
+	stack := make([]std.GoMixObject, 0, 32)
+	frames := make([]vmFrame, 1, 8)
+	frames[0] = vmFrame{code: code, callScope: e.Scp, caller: e.Scp}
+	f := &frames[0]
+
+	push := func(obj std.GoMixObject) {
+		stack = append(stack, obj)
+	}
+	pop := func() std.GoMixObject {
+		obj := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return obj
+	}
+	// frames pushed by compiled calls count towards the call depth (and sit on
+	// the call stack) until they return; an error unwinds them all at once
+	baseDepth, baseFrames := e.trace.depth, len(e.trace.frames)
+	defer func() {
+		closeIterators(stack)
+		e.trace.depth = baseDepth
+		e.unwindFrames(result, baseFrames)
+	}()
+
+	// leave pops the current frame and pushes its result for the caller.
+	// Returns false if the frame was the outermost one.
+	leave := func(result std.GoMixObject) bool {
+		if len(frames) == 1 {
+			return false
+		}
+		e.leaveCall()
+		e.popFrame(result)
+		result = returnFromCall(result, f.callScope)
+		closeIterators(stack[f.base:])
+		stack = stack[:f.base]
+		e.Scp = f.caller
+		frames = frames[:len(frames)-1]
+		f = &frames[len(frames)-1]
+		push(result)
+		return true
+	}
+
+	for {
+		if res := e.step(); res != nil {
+			return res
+		}
+		ins := f.code.Instructions
+		op := Opcode(ins[f.ip])
+		ip := f.ip + 1
+
+		switch op {
+		case OpConstant:
+			push(f.code.Constants[readU16(ins, ip)])
+			ip += 2
+
+		case OpNil:
+			push(&std.Nil{})
+
+		case OpPop:
+			stack = stack[:len(stack)-1]
+
+		case OpSwap:
+			n := len(stack)
+			stack[n-1], stack[n-2] = stack[n-2], stack[n-1]
+
+		case OpGetName:
+			name := f.code.Names[readU16(ins, ip)]
+			val, ok := e.Scp.LookUp(name)
+			if !ok {
+				builtin, isBuiltin := e.Builtins[name]
+				if !isBuiltin {
+					return e.createError(f.code.Tokens[readU16(ins, ip+2)], "ERROR: identifier not found: (%s)", name)
+				}
+				val = builtin
+			}
+			push(val)
+			ip += 4
+
+		case OpDeclare:
+			n := f.code.Nodes[readU16(ins, ip)].(*parser.DeclarativeStatementNode)
+			if res := e.declareValue(n, stack[len(stack)-1]); IsError(res) {
+				return res
+			}
+			ip += 2
+
+		case OpAssignName:
+			ident := f.code.Nodes[readU16(ins, ip)].(*parser.IdentifierExpressionNode)
+			res := e.evalIdentifierAssignment(ident, stack[len(stack)-1])
+			if IsError(res) {
+				return res
+			}
+			stack[len(stack)-1] = res
+			ip += 2
+
+		case OpCompoundName:
+			n := f.code.Nodes[readU16(ins, ip)].(*parser.AssignmentExpressionNode)
+			ident := n.Left.(*parser.IdentifierExpressionNode)
+			binOpType, _ := compoundOperator(n.Operation.Type)
+			left := e.evalIdentifierExpression(ident)
+			if IsError(left) {
+				return left
+			}
+			newVal := e.evaluateBinaryOp(n.Operation, binOpType, left, stack[len(stack)-1])
+			if IsError(newVal) {
+				return newVal
+			}
+			res := e.evalIdentifierAssignment(ident, newVal)
+			if IsError(res) {
+				return res
+			}
+			stack[len(stack)-1] = res
+			ip += 2
+
+		case OpSetIndex:
+			index, container, right := pop(), pop(), pop()
+			e.at(f.code.Tokens[readU16(ins, ip)])
+			res := e.assignIndex(container, index, right)
+			if IsError(res) {
+				return res
+			}
+			push(res)
+			ip += 2
+
+		case OpCompoundIndex:
+			n := f.code.Nodes[readU16(ins, ip)].(*parser.AssignmentExpressionNode)
+			binOpType, _ := compoundOperator(n.Operation.Type)
+			index, container, right := pop(), pop(), pop()
+			e.at(n.Left.(*parser.IndexExpressionNode).Token)
+			res := e.compoundIndexAssign(n.Operation, binOpType, container, index, right)
+			if IsError(res) {
+				return res
+			}
+			push(res)
+			ip += 2
+
+		case OpBinary:
+			tok := f.code.Tokens[readU16(ins, ip)]
+			right, left := pop(), pop()
+			res := vmIntegerOp(tok.Type, left, right)
+			if res == nil {
+				e.at(tok)
+				res = e.evaluateBinaryOp(tok, tok.Type, left, right)
+				if IsError(res) {
+					return res
+				}
+			}
+			push(res)
+			ip += 2
+
+		case OpCompare:
+			tok := f.code.Tokens[readU16(ins, ip)]
+			right, left := pop(), pop()
+			res := vmIntegerOp(tok.Type, left, right)
+			if res == nil {
+				e.at(tok)
+				res = e.compareValues(tok, left, right)
+				if IsError(res) {
+					return res
+				}
+			}
+			push(res)
+			ip += 2
+
+		case OpUnary:
+			tok := f.code.Tokens[readU16(ins, ip)]
+			e.at(tok)
+			res := e.unaryOp(tok, pop())
+			if IsError(res) {
+				return res
+			}
+			push(res)
+			ip += 2
+
+		case OpLogical:
+			tok := f.code.Tokens[readU16(ins, ip)]
+			left, ok := stack[len(stack)-1].(*std.Boolean)
+			if !ok {
+				return e.createError(tok, "ERROR: left operand of '%s' must be a boolean, got %s", tok.Type, stack[len(stack)-1].GetType())
+			}
+			// && stops at false, || stops at true
+			if left.Value == (tok.Type == lexer.OR_OP) {
+				ip = readU16(ins, ip+2)
+				break
+			}
+			stack = stack[:len(stack)-1]
+			ip += 4
+
+		case OpLogicalRight:
+			tok := f.code.Tokens[readU16(ins, ip)]
+			if stack[len(stack)-1].GetType() != std.BooleanType {
+				return e.createError(tok, "ERROR: right operand of '%s' must be a boolean, got %s", tok.Type, stack[len(stack)-1].GetType())
+			}
+			ip += 2
+
+		case OpJump:
+			ip = readU16(ins, ip)
+
+		case OpTest:
+			cond, ok := pop().(*std.Boolean)
+			if !ok {
+				switch ins[ip] {
+				case testFor:
+					return e.CreateError("ERROR: for loop condition must be (bool)")
+				case testWhile:
+					return e.CreateError("ERROR: while loop condition must be (bool)")
+				default:
+					return e.CreateError("ERROR: conditional expression must be (bool)")
+				}
+			}
+			if !cond.Value {
+				ip = readU16(ins, ip+1)
+				break
+			}
+			ip += 3
+
+		case OpPushScope:
+			e.Scp = scope.NewScope(e.Scp)
+
+		case OpPopScope:
+			e.Scp = e.Scp.Parent
+
+		case OpLoadCallee:
+			callee := e.resolveCallee(f.code.Nodes[readU16(ins, ip)].(*parser.CallExpressionNode))
+			if IsError(callee) {
+				return callee
+			}
+			push(callee)
+			ip += 2
+
+		case OpCall:
+			argc := int(ins[ip])
+			tok := f.code.Tokens[readU16(ins, ip+1)]
+			ip += 3
+			e.at(tok)
+			calleeIdx := len(stack) - argc - 1
+			args := make([]std.GoMixObject, argc)
+			copy(args, stack[calleeIdx+1:])
+			callee := stack[calleeIdx]
+			stack = stack[:calleeIdx]
+
+			switch fn := callee.(type) {
+			case *std.Builtin:
+				res := e.withPosition(fn.Callback(e, e.Writer, args...), tok)
+				e.charge(res)
+				if IsError(res) {
+					return res
+				}
+				push(res)
+			case *function.Function:
+				parent := e.Scp
+				if fn.Scp != nil {
+					parent = fn.Scp
+				}
+				callScope := scope.NewScope(parent)
+				if err := e.bindArguments(fn, callScope, namedParameters(args, nil)); err != nil {
+					return err
+				}
+				body := e.compileFunctionBody(fn.Body)
+				if body == nil || fn.Generator {
+					res := returnFromCall(e.runFunctionBody(fn, functionFrameName(fn, callName(tok)), callScope), callScope)
+					if IsError(res) {
+						return res
+					}
+					push(res)
+					break
+				}
+				if res := e.enterCall(); res != nil {
+					return res
+				}
+				name := functionFrameName(fn, callName(tok))
+				e.pushFrame(name, fn.File)
+				f.ip = ip
+				frames = append(frames, vmFrame{code: body, base: len(stack), callScope: callScope, caller: e.Scp, fn: fn, name: name})
+				f = &frames[len(frames)-1]
+				e.Scp = callScope
+				continue
+			case *std.BoundMethod:
+				res := e.callValue(fn, "", tok, args, nil)
+				if IsError(res) {
+					return res
+				}
+				push(res)
+			}
+
+		case OpReturn:
+			res := e.frameResult(f, &std.ReturnValue{Value: pop()})
+			if IsError(res) || !leave(res) {
+				return res
+			}
+			continue
+
+		case OpEnd:
+			res := e.frameResult(f, pop())
+			if IsError(res) || !leave(res) {
+				return res
+			}
+			continue
+
+		case OpArray:
+			n := readU16(ins, ip)
+			elements := make([]std.GoMixObject, n)
+			copy(elements, stack[len(stack)-n:])
+			stack = stack[:len(stack)-n]
+			arr := &std.Array{Elements: elements}
+			e.charge(arr)
+			push(arr)
+			ip += 2
+
+		case OpMap:
+			n := readU16(ins, ip)
+			keys := make([]std.GoMixObject, n)
+			values := make([]std.GoMixObject, n)
+			start := len(stack) - 2*n
+			for i := 0; i < n; i++ {
+				keys[i] = stack[start+2*i]
+				values[i] = stack[start+2*i+1]
+			}
+			stack = stack[:start]
+			m := e.newMapObject(f.code.Nodes[readU16(ins, ip+2)].(*parser.MapExpressionNode), keys, values)
+			if IsError(m) {
+				return m
+			}
+			e.charge(m)
+			push(m)
+			ip += 4
+
+		case OpTuple:
+			n := readU16(ins, ip)
+			elements := make([]std.GoMixObject, n)
+			copy(elements, stack[len(stack)-n:])
+			stack = stack[:len(stack)-n]
+			tuple := &std.Tuple{Elements: elements}
+			e.charge(tuple)
+			push(tuple)
+			ip += 2
+
+		case OpInterpolate:
+			n := readU16(ins, ip)
+			parts := stack[len(stack)-n:]
+			str := e.interpolate(parts)
+			stack = stack[:len(stack)-n]
+			push(str)
+			ip += 2
+
+		case OpSet:
+			n := readU16(ins, ip)
+			elems := make([]std.GoMixObject, n)
+			copy(elems, stack[len(stack)-n:])
+			stack = stack[:len(stack)-n]
+			set := e.newSetObject(f.code.Nodes[readU16(ins, ip+2)].(*parser.SetExpressionNode), elems)
+			if IsError(set) {
+				return set
+			}
+			e.charge(set)
+			push(set)
+			ip += 4
+
+		case OpRange:
+			end, start := pop(), pop()
+			e.at(f.code.Tokens[readU16(ins, ip)])
+			res := e.makeRange(start, end)
+			if IsError(res) {
+				return res
+			}
+			push(res)
+			ip += 2
+
+		case OpIndex:
+			index, left := pop(), pop()
+			e.at(f.code.Tokens[readU16(ins, ip)])
+			res := e.indexValue(left, index)
+			if IsError(res) {
+				return res
+			}
+			push(res)
+			ip += 2
+
+		case OpSlice:
+			flags := ins[ip]
+			var start, end std.GoMixObject
+			if flags&2 != 0 {
+				end = pop()
+			}
+			if flags&1 != 0 {
+				start = pop()
+			}
+			e.at(f.code.Tokens[readU16(ins, ip+1)])
+			res := e.sliceValue(pop(), start, end)
+			if IsError(res) {
+				return res
+			}
+			push(res)
+			ip += 3
+
+		case OpIterInit:
+			iterable := pop()
+			n := f.code.Nodes[readU16(ins, ip)].(*parser.ForeachLoopStatementNode)
+			e.at(n.ForeachToken)
+			it := std.NewIterator(iterable)
+			if it == nil {
+				return e.CreateError("ERROR: foreach requires an `iterable`, got `%s`", iterable.GetType())
+			}
+			push(&vmIterator{it: it, gen: ownedGenerator(n, iterable)})
+			ip += 2
+
+		case OpIterNext:
+			it := stack[len(stack)-2].(*vmIterator)
+			key, value, err := it.it.Next(e)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				ip = readU16(ins, ip)
+				break
+			}
+			push(key)
+			push(value)
+			ip += 2
+
+		case OpBindIter:
+			e.Scp.Bind(f.code.Names[readU16(ins, ip)], pop())
+			ip += 2
+
+		case OpBindPattern:
+			if err := e.bindPattern(f.code.Nodes[readU16(ins, ip)].(*parser.PatternNode), pop()); err != nil {
+				return err
+			}
+			ip += 2
+
+		case OpIterEnd:
+			n := len(stack)
+			if it := stack[n-2].(*vmIterator); it.gen != nil {
+				it.gen.Close()
+			}
+			stack[n-2] = stack[n-1]
+			stack = stack[:n-1]
+
+		case OpSetResult:
+			val := pop()
+			stack[len(stack)-1] = val
+
+		case OpEval:
+			res := e.Eval(f.code.Nodes[readU16(ins, ip)])
+			if IsError(res) {
+				return res
+			}
+			if _, isReturn := res.(*std.ReturnValue); isReturn {
+				if !leave(res) {
+					return res
+				}
+				continue
+			}
+			push(res)
+			ip += 2
+
+		case OpPosition:
+			e.at(f.code.Tokens[readU16(ins, ip)])
+			ip += 2
+
+		case OpSignal:
+			switch stack[len(stack)-1].GetType() {
+			case std.BreakType:
+				stack = stack[:len(stack)-1]
+				ip = readU16(ins, ip)
+			case std.ContinueType:
+				stack = stack[:len(stack)-1]
+				ip = readU16(ins, ip+2)
+			default:
+				ip += 4
+			}
+
+		default:
+			return e.CreateError("ERROR: unknown opcode %d", op)
+		}
+
+		f.ip = ip
+	}
+}
+
+// vmIntegerOp is the VM's fast path for arithmetic and comparisons of two integers.
+// It returns nil when the operands or the operator need the general helpers
+// (evaluateBinaryOp, compareValues), e.g. for floats, strings, division or
+// results that overflow an int.
+func vmIntegerOp(op lexer.TokenType, left, right std.GoMixObject) std.GoMixObject {
+	l, ok := left.(*std.Integer)
+	if !ok {
+		return nil
+	}
+	r, ok := right.(*std.Integer)
+	if !ok {
+		return nil
+	}
+	switch op {
+	case lexer.PLUS_OP, lexer.MINUS_OP, lexer.MUL_OP:
+		if std.IntOverflows(string(op), l.Value, r.Value) {
+			// evaluateBinaryOp promotes the result to a bigint
+			return nil
+		}
+	}
+	switch op {
+	case lexer.PLUS_OP:
+		return &std.Integer{Value: l.Value + r.Value}
+	case lexer.MINUS_OP:
+		return &std.Integer{Value: l.Value - r.Value}
+	case lexer.MUL_OP:
+		return &std.Integer{Value: l.Value * r.Value}
+	case lexer.LT_OP:
+		return &std.Boolean{Value: l.Value < r.Value}
+	case lexer.LE_OP:
+		return &std.Boolean{Value: l.Value <= r.Value}
+	case lexer.GT_OP:
+		return &std.Boolean{Value: l.Value > r.Value}
+	case lexer.GE_OP:
+		return &std.Boolean{Value: l.Value >= r.Value}
+	case lexer.EQ_OP, lexer.STRICT_EQ_OP:
+		return &std.Boolean{Value: l.Value == r.Value}
+	case lexer.NE_OP, lexer.STRICT_NE_OP:
+		return &std.Boolean{Value: l.Value != r.Value}
+	}
+	return nil
+}
@@ -0,0 +1,871 @@
+/*
+File    : go-mix/eval/vm_compiler.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// maxOperand is the largest value a 2-byte operand can hold.
+const maxOperand = 1<<16 - 1
+
+// compiler translates AST nodes into Bytecode for the VM.
+//
+// Variables live in the same scope chain as in the tree walker, and the VM calls
+// the evaluator's value-level helpers (evaluateBinaryOp, indexValue, assignIndex, ...),
+// so compiled and tree-walked code behave identically and can be mixed freely.
+// Constructs without a dedicated instruction (structs, enums, switch, try/catch,
+// imports, ...) are compiled to OpEval, which hands the node to the tree walker.
+//
+// Fields:
+//   - e: The evaluator the code is compiled for (used to resolve builtins)
+//   - code: The bytecode being produced
+//   - depth: The number of values on the operand stack at the current instruction
+//   - scopes: The number of scopes opened by the code at the current instruction
+//   - loops: The enclosing loops, innermost last
+type compiler struct {
+	e      *Evaluator
+	code   *Bytecode
+	depth  int
+	scopes int
+	loops  []*loopContext
+}
+
+// loopContext records what break and continue need to unwind to inside a loop.
+//
+// Fields:
+//   - depth: The stack depth with the loop result on top
+//   - scopes: The number of open scopes outside the per-iteration scope
+//   - breaks: Operand offsets of jumps to patch with the loop exit
+//   - continues: Operand offsets of jumps to patch with the next-iteration target
+type loopContext struct {
+	depth     int
+	scopes    int
+	breaks    []int
+	continues []int
+}
+
+// compileError reports a construct the compiler cannot translate.
+// Code that fails to compile is run by the tree walker instead.
+type compileError struct {
+	msg string
+}
+
+// Error implements the error interface.
+func (err *compileError) Error() string {
+	return err.msg
+}
+
+// newCompiler creates a compiler producing an empty Bytecode.
+func newCompiler(e *Evaluator) *compiler {
+	return &compiler{
+		e:    e,
+		code: &Bytecode{},
+	}
+}
+
+// Compile translates a program into bytecode for the VM.
+//
+// Parameters:
+//   - root: The RootNode of a parsed program
+//
+// Returns:
+//   - *Bytecode: The compiled program
+//   - error: A non-nil error if the program uses a construct the compiler cannot
+//     translate (e.g., break outside of a loop); such programs can still be run
+//     with the tree walker
+//
+// Example:
+//
+//	code, err := ev.Compile(par.Parse())
+//	if err == nil {
+//	    fmt.Print(code) // disassembly
+//	}
+func (e *Evaluator) Compile(root *parser.RootNode) (*Bytecode, error) {
+	c := newCompiler(e)
+	if err := c.compileStatements(root.Statements); err != nil {
+		return nil, err
+	}
+	c.emit(OpEnd)
+	return c.code, nil
+}
+
+// compileFunctionBody returns the bytecode for a function body, compiling it on
+// first use. Returns nil if the body cannot be compiled.
+func (e *Evaluator) compileFunctionBody(body *parser.BlockStatementNode) *Bytecode {
+	if code, ok := e.vmCode[body]; ok {
+		return code
+	}
+	c := newCompiler(e)
+	var code *Bytecode
+	if err := c.compileStatements(body.Statements); err == nil {
+		c.emit(OpEnd)
+		code = c.code
+	}
+	e.vmCode[body] = code
+	return code
+}
+
+// emit appends an instruction and updates the tracked stack depth.
+// Returns the offset of the instruction.
+func (c *compiler) emit(op Opcode, operands ...int) int {
+	pos := len(c.code.Instructions)
+	c.code.Instructions = append(c.code.Instructions, byte(op))
+	for i, width := range opDefinitions[op].OperandWidths {
+		if width == 2 {
+			c.code.Instructions = binary.BigEndian.AppendUint16(c.code.Instructions, uint16(operands[i]))
+		} else {
+			c.code.Instructions = append(c.code.Instructions, byte(operands[i]))
+		}
+	}
+	c.depth += stackEffect(op, operands)
+	return pos
+}
+
+// stackEffect returns how many values an instruction adds to the stack
+// (negative if it removes values) when execution falls through to the next instruction.
+func stackEffect(op Opcode, operands []int) int {
+	switch op {
+	case OpConstant, OpNil, OpGetName, OpLoadCallee, OpEval:
+		return 1
+	case OpIterNext:
+		return 2
+	case OpPop, OpBinary, OpCompare, OpLogical, OpTest, OpReturn, OpRange, OpIndex, OpBindIter, OpBindPattern, OpSetResult, OpSignal, OpIterEnd:
+		return -1
+	case OpSetIndex, OpCompoundIndex:
+		return -2
+	case OpCall:
+		return -operands[0]
+	case OpArray, OpSet, OpTuple, OpInterpolate:
+		return 1 - operands[0]
+	case OpMap:
+		return 1 - 2*operands[0]
+	case OpSlice:
+		n := 0
+		if operands[0]&1 != 0 {
+			n++
+		}
+		if operands[0]&2 != 0 {
+			n++
+		}
+		return -n
+	}
+	return 0
+}
+
+// patchJump points the jump operand at offset to the current end of the code.
+func (c *compiler) patchJump(offset int) {
+	binary.BigEndian.PutUint16(c.code.Instructions[offset:], uint16(len(c.code.Instructions)))
+}
+
+// here returns the offset of the next instruction, for backward jumps.
+func (c *compiler) here() int {
+	return len(c.code.Instructions)
+}
+
+// checkSize fails compilation when the code or a table outgrows 2-byte operands.
+func (c *compiler) checkSize() error {
+	b := c.code
+	if len(b.Instructions) > maxOperand || len(b.Constants) > maxOperand ||
+		len(b.Names) > maxOperand || len(b.Tokens) > maxOperand || len(b.Nodes) > maxOperand {
+		return &compileError{msg: "code too large for the VM"}
+	}
+	return nil
+}
+
+// addConstant adds a literal to the constant table and returns its index.
+func (c *compiler) addConstant(obj std.GoMixObject) int {
+	c.code.Constants = append(c.code.Constants, obj)
+	return len(c.code.Constants) - 1
+}
+
+// addName adds an identifier name to the name table and returns its index.
+func (c *compiler) addName(name string) int {
+	for i, n := range c.code.Names {
+		if n == name {
+			return i
+		}
+	}
+	c.code.Names = append(c.code.Names, name)
+	return len(c.code.Names) - 1
+}
+
+// addToken adds a source token to the token table and returns its index.
+func (c *compiler) addToken(tok lexer.Token) int {
+	c.code.Tokens = append(c.code.Tokens, tok)
+	return len(c.code.Tokens) - 1
+}
+
+// addNode adds an AST node to the node table and returns its index.
+func (c *compiler) addNode(n parser.Node) int {
+	c.code.Nodes = append(c.code.Nodes, n)
+	return len(c.code.Nodes) - 1
+}
+
+// compileStatements compiles a statement list so that it leaves exactly one
+// value on the stack: the value of the last statement, or nil if there is none.
+func (c *compiler) compileStatements(stmts []parser.StatementNode) error {
+	if len(stmts) == 0 {
+		c.emit(OpNil)
+		return nil
+	}
+	for i, stmt := range stmts {
+		if tok, ok := positionToken(stmt); ok {
+			c.emit(OpPosition, c.addToken(tok))
+		}
+		if err := c.compileNode(stmt); err != nil {
+			return err
+		}
+		if i < len(stmts)-1 {
+			c.emit(OpPop)
+		}
+	}
+	return c.checkSize()
+}
+
+// positionToken returns the token the tree walker records as the current
+// position when it starts evaluating a statement (see Evaluator.Eval), so that
+// errors raised by the statement itself (e.g., a redeclaration) report the
+// same position on both backends.
+func positionToken(node parser.Node) (lexer.Token, bool) {
+	switch n := node.(type) {
+	case *parser.UnaryExpressionNode:
+		return n.Operation, true
+	case *parser.IfExpressionNode:
+		return n.IfToken, true
+	case *parser.DeclarativeStatementNode:
+		return n.VarToken, true
+	case *parser.ReturnStatementNode:
+		return n.ReturnToken, true
+	case *parser.CallExpressionNode:
+		return callToken(n), true
+	case *parser.AssignmentExpressionNode:
+		return n.Operation, true
+	case *parser.ForLoopStatementNode:
+		return n.ForToken, true
+	case *parser.WhileLoopStatementNode:
+		return n.WhileToken, true
+	case *parser.ForeachLoopStatementNode:
+		return n.ForeachToken, true
+	case *parser.NewCallExpressionNode:
+		return n.NewToken, true
+	case *parser.MatchExpressionNode:
+		return n.Token, true
+	case *parser.ThrowStatementNode:
+		return n.Token, true
+	case *parser.SpawnStatementNode:
+		return n.Token, true
+	case *parser.YieldStatementNode:
+		return n.Token, true
+	}
+	return lexer.Token{}, false
+}
+
+// compileNode compiles a statement or expression so that it leaves its value
+// on the stack.
+func (c *compiler) compileNode(node parser.Node) error {
+	switch n := node.(type) {
+	case nil:
+		c.emit(OpNil)
+	case *parser.BooleanLiteralExpressionNode:
+		c.emit(OpConstant, c.addConstant(n.Value))
+	case *parser.IntegerLiteralExpressionNode:
+		c.emit(OpConstant, c.addConstant(n.Value))
+	case *parser.CharLiteralExpressionNode:
+		c.emit(OpConstant, c.addConstant(n.Value))
+	case *parser.StringLiteralExpressionNode:
+		c.emit(OpConstant, c.addConstant(n.Value))
+	case *parser.FloatLiteralExpressionNode:
+		c.emit(OpConstant, c.addConstant(n.Value))
+	case *parser.NilLiteralExpressionNode:
+		c.emit(OpNil)
+	case *parser.ParenthesizedExpressionNode:
+		return c.compileNode(n.Expr)
+	case *parser.IdentifierExpressionNode:
+		c.emit(OpGetName, c.addName(n.Name), c.addToken(n.Token))
+	case *parser.BinaryExpressionNode:
+		return c.compileBinary(n)
+	case *parser.UnaryExpressionNode:
+		if err := c.compileNode(n.Right); err != nil {
+			return err
+		}
+		c.emit(OpUnary, c.addToken(n.Operation))
+	case *parser.BooleanExpressionNode:
+		return c.compileBoolean(n)
+	case *parser.BlockStatementNode:
+		return c.compileStatements(n.Statements)
+	case *parser.IfExpressionNode:
+		return c.compileIf(n)
+	case *parser.DeclarativeStatementNode:
+		if err := c.compileNode(n.Expr); err != nil {
+			return err
+		}
+		c.emit(OpDeclare, c.addNode(n))
+	case *parser.AssignmentExpressionNode:
+		return c.compileAssignment(n)
+	case *parser.ReturnStatementNode:
+		if err := c.compileNode(n.Expr); err != nil {
+			return err
+		}
+		c.emit(OpReturn)
+		// Code after a return is unreachable, but keeps the one-value-per-statement shape
+		c.depth++
+	case *parser.CallExpressionNode:
+		return c.compileCall(n)
+	case *parser.ArrayExpressionNode:
+		for _, elem := range n.Elements {
+			if err := c.compileNode(elem); err != nil {
+				return err
+			}
+		}
+		c.emit(OpArray, len(n.Elements))
+	case *parser.TupleExpressionNode:
+		for _, elem := range n.Elements {
+			if err := c.compileNode(elem); err != nil {
+				return err
+			}
+		}
+		c.emit(OpTuple, len(n.Elements))
+	case *parser.InterpolatedStringExpressionNode:
+		count := 0
+		for i, text := range n.Texts {
+			if text != "" {
+				c.emit(OpConstant, c.addConstant(&std.String{Value: text}))
+				count++
+			}
+			if i < len(n.Exprs) {
+				if err := c.compileNode(n.Exprs[i]); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		c.emit(OpInterpolate, count)
+	case *parser.MapExpressionNode:
+		for i := range n.Keys {
+			if err := c.compileNode(n.Keys[i]); err != nil {
+				return err
+			}
+			if err := c.compileNode(n.Values[i]); err != nil {
+				return err
+			}
+		}
+		c.emit(OpMap, len(n.Keys), c.addNode(n))
+	case *parser.SetExpressionNode:
+		for _, elem := range n.Elements {
+			if err := c.compileNode(elem); err != nil {
+				return err
+			}
+		}
+		c.emit(OpSet, len(n.Elements), c.addNode(n))
+	case *parser.RangeExpressionNode:
+		if err := c.compileNode(n.Start); err != nil {
+			return err
+		}
+		if err := c.compileNode(n.End); err != nil {
+			return err
+		}
+		c.emit(OpRange, c.addToken(n.Token))
+	case *parser.IndexExpressionNode:
+		if err := c.compileNode(n.Left); err != nil {
+			return err
+		}
+		if err := c.compileNode(n.Index); err != nil {
+			return err
+		}
+		c.emit(OpIndex, c.addToken(n.Token))
+	case *parser.SliceExpressionNode:
+		return c.compileSlice(n)
+	case *parser.ForLoopStatementNode:
+		return c.compileForLoop(n)
+	case *parser.WhileLoopStatementNode:
+		return c.compileWhileLoop(n)
+	case *parser.ForeachLoopStatementNode:
+		return c.compileForeachLoop(n)
+	case *parser.BreakStatementNode:
+		return c.compileLoopExit(true)
+	case *parser.ContinueStatementNode:
+		return c.compileLoopExit(false)
+	default:
+		// Functions, structs, enums, imports, switch, match and try/catch
+		// are evaluated by the tree walker in the current scope
+		c.emit(OpEval, c.addNode(n))
+		c.compileSignalCheck()
+	}
+	return nil
+}
+
+// compileBinary compiles arithmetic and bitwise operators.
+// Member access (obj.field, pkg.fn(...)) is left to the tree walker.
+func (c *compiler) compileBinary(n *parser.BinaryExpressionNode) error {
+	if n.Operation.Type == lexer.DOT_OP {
+		c.emit(OpEval, c.addNode(n))
+		return nil
+	}
+	if err := c.compileNode(n.Left); err != nil {
+		return err
+	}
+	if err := c.compileNode(n.Right); err != nil {
+		return err
+	}
+	c.emit(OpBinary, c.addToken(n.Operation))
+	return nil
+}
+
+// compileBoolean compiles comparisons and the short-circuiting && and || operators.
+func (c *compiler) compileBoolean(n *parser.BooleanExpressionNode) error {
+	if err := c.compileNode(n.Left); err != nil {
+		return err
+	}
+	if n.Operation.Type == lexer.AND_OP || n.Operation.Type == lexer.OR_OP {
+		tok := c.addToken(n.Operation)
+		jump := c.emit(OpLogical, tok, 0)
+		if err := c.compileNode(n.Right); err != nil {
+			return err
+		}
+		c.emit(OpLogicalRight, tok)
+		c.patchJump(jump + 3)
+		return nil
+	}
+	if err := c.compileNode(n.Right); err != nil {
+		return err
+	}
+	c.emit(OpCompare, c.addToken(n.Operation))
+	return nil
+}
+
+// compileIf compiles an if/else expression; its value is the value of the branch taken.
+func (c *compiler) compileIf(n *parser.IfExpressionNode) error {
+	if err := c.compileNode(n.Condition); err != nil {
+		return err
+	}
+	jumpElse := c.emit(OpTest, int(testIf), 0)
+	if err := c.compileStatements(n.ThenBlock.Statements); err != nil {
+		return err
+	}
+	jumpEnd := c.emit(OpJump, 0)
+	c.depth--
+	c.patchJump(jumpElse + 2)
+	if err := c.compileStatements(n.ElseBlock.Statements); err != nil {
+		return err
+	}
+	c.patchJump(jumpEnd + 1)
+	return nil
+}
+
+// compileAssignment compiles =, +=, -=, ... on identifiers and indexed elements.
+// Assignments to struct members are left to the tree walker.
+func (c *compiler) compileAssignment(n *parser.AssignmentExpressionNode) error {
+	compound := n.Operation.Type != lexer.ASSIGN_OP
+	if compound {
+		if _, ok := compoundOperator(n.Operation.Type); !ok {
+			c.emit(OpEval, c.addNode(n))
+			return nil
+		}
+	}
+
+	switch left := n.Left.(type) {
+	case *parser.IdentifierExpressionNode:
+		if err := c.compileNode(n.Right); err != nil {
+			return err
+		}
+		if compound {
+			c.emit(OpCompoundName, c.addNode(n))
+		} else {
+			c.emit(OpAssignName, c.addNode(left))
+		}
+	case *parser.IndexExpressionNode:
+		if err := c.compileNode(n.Right); err != nil {
+			return err
+		}
+		if err := c.compileNode(left.Left); err != nil {
+			return err
+		}
+		if err := c.compileNode(left.Index); err != nil {
+			return err
+		}
+		if compound {
+			c.emit(OpCompoundIndex, c.addNode(n))
+		} else {
+			c.emit(OpSetIndex, c.addToken(left.Token))
+		}
+	default:
+		c.emit(OpEval, c.addNode(n))
+	}
+	return nil
+}
+
+// compileCall compiles calls of builtins, user functions, package functions and methods.
+// Builtins called by name are constants; any other callee (a function in scope,
+// obj.method, makeAdder(1), ...) is resolved by the tree walker with OpLoadCallee.
+// Calls with named arguments are left to the tree walker.
+func (c *compiler) compileCall(n *parser.CallExpressionNode) error {
+	argc := len(n.Arguments)
+	if argc > 255 {
+		return &compileError{msg: fmt.Sprintf("too many arguments in call to %s", n.Callee.Literal())}
+	}
+	if n.ArgNames != nil {
+		// Named arguments are matched to the parameters by the tree walker
+		c.emit(OpEval, c.addNode(n))
+		return nil
+	}
+	tok := c.addToken(callToken(n))
+
+	if ident, ok := n.Callee.(*parser.IdentifierExpressionNode); ok && c.e.IsBuiltin(ident.Name) {
+		c.emit(OpConstant, c.addConstant(c.e.Builtins[ident.Name]))
+	} else {
+		c.emit(OpLoadCallee, c.addNode(n))
+	}
+
+	for _, arg := range n.Arguments {
+		if err := c.compileNode(arg); err != nil {
+			return err
+		}
+	}
+	c.emit(OpCall, argc, tok)
+	return nil
+}
+
+// compileSlice compiles container[start:end] with optional bounds.
+func (c *compiler) compileSlice(n *parser.SliceExpressionNode) error {
+	if err := c.compileNode(n.Left); err != nil {
+		return err
+	}
+	flags := 0
+	if n.Start != nil {
+		if err := c.compileNode(n.Start); err != nil {
+			return err
+		}
+		flags |= 1
+	}
+	if n.End != nil {
+		if err := c.compileNode(n.End); err != nil {
+			return err
+		}
+		flags |= 2
+	}
+	c.emit(OpSlice, flags, c.addToken(n.Token))
+	return nil
+}
+
+// compileForLoop compiles for (init; cond; update) { body }.
+//
+// Layout:
+//
+//	OpPushScope              loop scope
+//	<initializers>
+//	OpNil                    loop result
+//	cond: <condition> OpTest end
+//	[OpPushScope]            iteration scope
+//	<body> OpSetResult
+//	[OpPopScope]
+//	next: <updates>
+//	OpJump cond
+//	end: OpPopScope
+func (c *compiler) compileForLoop(n *parser.ForLoopStatementNode) error {
+	c.emit(OpPushScope)
+	c.scopes++
+	for _, init := range n.Initializers {
+		if err := c.compileNode(init); err != nil {
+			return err
+		}
+		c.emit(OpPop)
+	}
+	c.emit(OpNil)
+
+	loop := c.pushLoop()
+	condStart := c.here()
+	// every iteration (the back-edge jumps here) is charged to the loop
+	c.emit(OpPosition, c.addToken(n.ForToken))
+	jumpEnd := -1
+	if n.Condition != nil {
+		if err := c.compileNode(n.Condition); err != nil {
+			return err
+		}
+		jumpEnd = c.emit(OpTest, int(testFor), 0)
+	}
+	if err := c.compileLoopBody(&n.Body); err != nil {
+		return err
+	}
+
+	for _, offset := range loop.continues {
+		binary.BigEndian.PutUint16(c.code.Instructions[offset:], uint16(c.here()))
+	}
+	for _, update := range n.Updates {
+		if err := c.compileNode(update); err != nil {
+			return err
+		}
+		c.emit(OpPop)
+	}
+	c.emit(OpJump, condStart)
+	c.popLoop(loop)
+	if jumpEnd >= 0 {
+		c.patchJump(jumpEnd + 2)
+	}
+
+	c.emit(OpPopScope)
+	c.scopes--
+	return nil
+}
+
+// compileWhileLoop compiles while (cond, ...) { body }. The loop runs while all
+// conditions are true; they are tested in order.
+func (c *compiler) compileWhileLoop(n *parser.WhileLoopStatementNode) error {
+	c.emit(OpPushScope)
+	c.scopes++
+	c.emit(OpNil)
+
+	loop := c.pushLoop()
+	condStart := c.here()
+	c.emit(OpPosition, c.addToken(n.WhileToken))
+	jumpsEnd := make([]int, 0, len(n.Conditions))
+	for _, cond := range n.Conditions {
+		if err := c.compileNode(cond); err != nil {
+			return err
+		}
+		jumpsEnd = append(jumpsEnd, c.emit(OpTest, int(testWhile), 0))
+	}
+	if err := c.compileLoopBody(&n.Body); err != nil {
+		return err
+	}
+
+	for _, offset := range loop.continues {
+		binary.BigEndian.PutUint16(c.code.Instructions[offset:], uint16(condStart))
+	}
+	c.emit(OpJump, condStart)
+	c.popLoop(loop)
+	for _, jump := range jumpsEnd {
+		c.patchJump(jump + 2)
+	}
+
+	c.emit(OpPopScope)
+	c.scopes--
+	return nil
+}
+
+// compileForeachLoop compiles foreach item in iterable { body } and
+// foreach key, item in iterable { body }.
+//
+// The iterator sits on the stack below the loop result while the loop runs.
+// When the body declares nothing, the loop variable is rebound in the loop
+// scope instead of a fresh scope per iteration.
+func (c *compiler) compileForeachLoop(n *parser.ForeachLoopStatementNode) error {
+	if err := c.compileNode(n.Iterable); err != nil {
+		return err
+	}
+	c.emit(OpIterInit, c.addNode(n))
+	c.emit(OpPushScope)
+	c.scopes++
+	c.emit(OpNil)
+
+	loop := c.pushLoop()
+	next := c.here()
+	c.emit(OpPosition, c.addToken(n.ForeachToken))
+	jumpEnd := c.emit(OpIterNext, 0)
+	perIteration := mayBind(&n.Body)
+	if perIteration {
+		c.emit(OpPushScope)
+		c.scopes++
+	}
+	if n.Pattern != nil {
+		c.emit(OpBindPattern, c.addNode(n.Pattern))
+	} else {
+		c.emit(OpBindIter, c.addName(n.Iterator.Name))
+	}
+	if n.Key != nil {
+		c.emit(OpBindIter, c.addName(n.Key.Name))
+	} else {
+		c.emit(OpPop)
+	}
+	if err := c.compileStatements(n.Body.Statements); err != nil {
+		return err
+	}
+	c.emit(OpSetResult)
+	if perIteration {
+		c.emit(OpPopScope)
+		c.scopes--
+	}
+	c.emit(OpJump, next)
+
+	for _, offset := range loop.continues {
+		binary.BigEndian.PutUint16(c.code.Instructions[offset:], uint16(next))
+	}
+	c.popLoop(loop)
+	c.patchJump(jumpEnd + 1)
+
+	c.emit(OpPopScope)
+	c.scopes--
+	c.emit(OpIterEnd)
+	return nil
+}
+
+// compileLoopBody compiles the body of a for or while loop and stores its
+// value as the loop result. A scope is opened per iteration only if the body
+// can declare names in it.
+func (c *compiler) compileLoopBody(body *parser.BlockStatementNode) error {
+	perIteration := mayBind(body)
+	if perIteration {
+		c.emit(OpPushScope)
+		c.scopes++
+	}
+	if err := c.compileStatements(body.Statements); err != nil {
+		return err
+	}
+	c.emit(OpSetResult)
+	if perIteration {
+		c.emit(OpPopScope)
+		c.scopes--
+	}
+	return nil
+}
+
+// pushLoop starts a loop whose result is on top of the stack.
+func (c *compiler) pushLoop() *loopContext {
+	loop := &loopContext{depth: c.depth, scopes: c.scopes}
+	c.loops = append(c.loops, loop)
+	return loop
+}
+
+// popLoop ends the innermost loop; pending breaks jump to the current offset.
+func (c *compiler) popLoop(loop *loopContext) {
+	for _, offset := range loop.breaks {
+		binary.BigEndian.PutUint16(c.code.Instructions[offset:], uint16(c.here()))
+	}
+	c.loops = c.loops[:len(c.loops)-1]
+	c.depth = loop.depth
+}
+
+// compileLoopExit compiles break (isBreak) or continue: it drops the values and
+// scopes opened since the loop started, resets the loop result to nil and
+// jumps to the loop exit or the next iteration.
+func (c *compiler) compileLoopExit(isBreak bool) error {
+	if len(c.loops) == 0 {
+		return &compileError{msg: "break or continue outside of a loop"}
+	}
+	loop := c.loops[len(c.loops)-1]
+	depth := c.depth
+	for i := depth; i > loop.depth; i-- {
+		c.emit(OpPop)
+	}
+	for i := c.scopes; i > loop.scopes; i-- {
+		c.emit(OpPopScope)
+	}
+	c.emit(OpPop)
+	c.emit(OpNil)
+	jump := c.emit(OpJump, 0)
+	if isBreak {
+		loop.breaks = append(loop.breaks, jump+1)
+	} else {
+		loop.continues = append(loop.continues, jump+1)
+	}
+	// Code after break/continue is unreachable; it is compiled as a statement with a value
+	c.depth = depth + 1
+	return nil
+}
+
+// compileSignalCheck follows an OpEval inside a loop: a break or continue that
+// ends the delegated statement (e.g., a break in a try block) exits or
+// continues the enclosing compiled loop.
+func (c *compiler) compileSignalCheck() {
+	if len(c.loops) == 0 {
+		return
+	}
+	signal := c.emit(OpSignal, 0, 0)
+	c.depth++
+	skip := c.emit(OpJump, 0)
+
+	depth := c.depth
+	c.patchJump(signal + 1)
+	c.depth = depth - 1
+	_ = c.compileLoopExit(true)
+	binary.BigEndian.PutUint16(c.code.Instructions[signal+3:], uint16(c.here()))
+	c.depth = depth - 1
+	_ = c.compileLoopExit(false)
+
+	c.patchJump(skip + 1)
+	c.depth = depth
+}
+
+// mayBind reports whether running node can declare names in the current scope.
+//
+// Loops open a scope per iteration only when their body may declare something,
+// as the tree walker does for every iteration; otherwise the scope would stay
+// empty and skipping it is not observable. Nodes that are evaluated by the tree
+// walker are assumed to declare names.
+func mayBind(node parser.Node) bool {
+	switch n := node.(type) {
+	case nil, *parser.BooleanLiteralExpressionNode, *parser.IntegerLiteralExpressionNode,
+		*parser.CharLiteralExpressionNode, *parser.StringLiteralExpressionNode,
+		*parser.FloatLiteralExpressionNode, *parser.NilLiteralExpressionNode,
+		*parser.IdentifierExpressionNode, *parser.SuperExpressionNode, *parser.BreakStatementNode, *parser.ContinueStatementNode:
+		return false
+	case *parser.ParenthesizedExpressionNode:
+		return mayBind(n.Expr)
+	case *parser.BinaryExpressionNode:
+		return mayBind(n.Left) || mayBind(n.Right)
+	case *parser.UnaryExpressionNode:
+		return mayBind(n.Right)
+	case *parser.BooleanExpressionNode:
+		return mayBind(n.Left) || mayBind(n.Right)
+	case *parser.BlockStatementNode:
+		for _, stmt := range n.Statements {
+			if mayBind(stmt) {
+				return true
+			}
+		}
+		return false
+	case *parser.IfExpressionNode:
+		return mayBind(n.Condition) || mayBind(&n.ThenBlock) || mayBind(&n.ElseBlock)
+	case *parser.AssignmentExpressionNode:
+		switch n.Left.(type) {
+		case *parser.IdentifierExpressionNode, *parser.IndexExpressionNode:
+			return mayBind(n.Left) || mayBind(n.Right)
+		}
+		return true
+	case *parser.ReturnStatementNode:
+		return mayBind(n.Expr)
+	case *parser.CallExpressionNode:
+		// Called functions and methods run in scopes of their own
+		return mayBind(n.Callee) || anyMayBind(n.Arguments)
+	case *parser.ArrayExpressionNode:
+		return anyMayBind(n.Elements)
+	case *parser.TupleExpressionNode:
+		return anyMayBind(n.Elements)
+	case *parser.InterpolatedStringExpressionNode:
+		return anyMayBind(n.Exprs)
+	case *parser.SetExpressionNode:
+		return anyMayBind(n.Elements)
+	case *parser.MapExpressionNode:
+		return anyMayBind(n.Keys) || anyMayBind(n.Values)
+	case *parser.RangeExpressionNode:
+		return mayBind(n.Start) || mayBind(n.End)
+	case *parser.IndexExpressionNode:
+		return mayBind(n.Left) || mayBind(n.Index)
+	case *parser.SliceExpressionNode:
+		return mayBind(n.Left) || mayBind(n.Start) || mayBind(n.End)
+	case *parser.ForeachLoopStatementNode:
+		// Loops declare into scopes of their own; only the iterable is evaluated in ours
+		return mayBind(n.Iterable)
+	case *parser.ForLoopStatementNode, *parser.WhileLoopStatementNode:
+		return false
+	}
+	return true
+}
+
+// anyMayBind reports whether any of the expressions may declare names.
+func anyMayBind(nodes []parser.ExpressionNode) bool {
+	for _, node := range nodes {
+		if mayBind(node) {
+			return true
+		}
+	}
+	return false
+}
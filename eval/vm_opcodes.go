@@ -0,0 +1,221 @@
+/*
+File    : go-mix/eval/vm_opcodes.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package eval
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// Opcode is a single bytecode instruction of the Go-Mix VM.
+//
+// An instruction is one opcode byte followed by its operands, which are
+// 1-byte (u8) or 2-byte big-endian (u16) unsigned integers. Operands index the
+// tables of the Bytecode they belong to (constants, names, tokens, nodes) or
+// hold jump targets, which are absolute offsets into the instruction stream.
+type Opcode byte
+
+const (
+	// OpConstant pushes Constants[u16]
+	OpConstant Opcode = iota
+	// OpNil pushes nil
+	OpNil
+	// OpPop discards the top of the stack
+	OpPop
+	// OpSwap exchanges the two topmost values
+	OpSwap
+	// OpGetName pushes the value of variable Names[u16] (token u16 for errors)
+	OpGetName
+	// OpDeclare binds the top of the stack as declared by Nodes[u16] (var/let/const); the value stays
+	OpDeclare
+	// OpAssignName assigns the top of the stack to the identifier Nodes[u16]; the value stays
+	OpAssignName
+	// OpCompoundName applies the compound assignment Nodes[u16] (x += right) with right on the stack
+	OpCompoundName
+	// OpSetIndex pops right, container, index and stores container[index] = right (at Tokens[u16])
+	OpSetIndex
+	// OpCompoundIndex pops right, container, index and applies the compound assignment Nodes[u16]
+	OpCompoundIndex
+	// OpBinary pops two operands and applies the arithmetic/bitwise operator Tokens[u16]
+	OpBinary
+	// OpCompare pops two operands and applies the comparison operator Tokens[u16]
+	OpCompare
+	// OpUnary applies the prefix operator Tokens[u16] to the top of the stack
+	OpUnary
+	// OpLogical checks the left operand of && or || (Tokens[u16]) and short-circuits to u16
+	OpLogical
+	// OpLogicalRight checks that the right operand of && or || (Tokens[u16]) is a boolean
+	OpLogicalRight
+	// OpJump jumps to u16
+	OpJump
+	// OpTest pops a condition of kind u8 and jumps to u16 when it is false
+	OpTest
+	// OpPushScope enters a new scope nested in the current one
+	OpPushScope
+	// OpPopScope returns to the parent of the current scope
+	OpPopScope
+	// OpLoadCallee pushes the callee of the call Nodes[u16], resolved by the tree walker,
+	// after checking that it is a function taking the call's arguments
+	OpLoadCallee
+	// OpCall calls the function below its u8 arguments (token u16 for errors)
+	OpCall
+	// OpReturn returns the top of the stack from the current function (return statement)
+	OpReturn
+	// OpEnd finishes the current chunk with the top of the stack as its value
+	OpEnd
+	// OpArray builds an array from the top u16 values
+	OpArray
+	// OpMap builds a map from the top u16 key/value pairs of the literal Nodes[u16]
+	OpMap
+	// OpSet builds a set from the top u16 values of the literal Nodes[u16]
+	OpSet
+	// OpTuple builds a tuple from the top u16 values
+	OpTuple
+	// OpInterpolate joins the top u16 values (the parts of an interpolated string) into a string
+	OpInterpolate
+	// OpRange pops start and end and pushes a range (at Tokens[u16])
+	OpRange
+	// OpIndex pops a container and an index and pushes container[index] (at Tokens[u16])
+	OpIndex
+	// OpSlice pops a container and the bounds present in flags u8 (1: start, 2: end) and pushes the slice (at Tokens[u16])
+	OpSlice
+	// OpIterInit replaces the iterable on top of the stack with an iterator over it, for the foreach loop Nodes[u16]
+	OpIterInit
+	// OpIterNext pushes the key and the value of the next element of the iterator below the loop result, or jumps to u16 when done
+	OpIterNext
+	// OpIterEnd drops the iterator below the loop result, closing a generator the loop owns
+	OpIterEnd
+	// OpBindIter pops the current element and binds it to Names[u16] in the current scope
+	OpBindIter
+	// OpBindPattern pops the current element and binds the names of the pattern Nodes[u16] to its parts
+	OpBindPattern
+	// OpSetResult pops the top of the stack and stores it as the new loop result below it
+	OpSetResult
+	// OpEval evaluates Nodes[u16] with the tree walker and pushes the result
+	OpEval
+	// OpSignal pops a break (continue) signal left by OpEval and jumps to u16 (second u16)
+	OpSignal
+	// OpPosition records Tokens[u16] as the current source position (for errors and tracebacks)
+	OpPosition
+)
+
+// opDefinition describes the name and operand widths of an opcode.
+type opDefinition struct {
+	Name          string
+	OperandWidths []int
+}
+
+// opDefinitions maps every opcode to its definition.
+var opDefinitions = map[Opcode]*opDefinition{
+	OpConstant:      {"OpConstant", []int{2}},
+	OpNil:           {"OpNil", []int{}},
+	OpPop:           {"OpPop", []int{}},
+	OpSwap:          {"OpSwap", []int{}},
+	OpGetName:       {"OpGetName", []int{2, 2}},
+	OpDeclare:       {"OpDeclare", []int{2}},
+	OpAssignName:    {"OpAssignName", []int{2}},
+	OpCompoundName:  {"OpCompoundName", []int{2}},
+	OpSetIndex:      {"OpSetIndex", []int{2}},
+	OpCompoundIndex: {"OpCompoundIndex", []int{2}},
+	OpBinary:        {"OpBinary", []int{2}},
+	OpCompare:       {"OpCompare", []int{2}},
+	OpUnary:         {"OpUnary", []int{2}},
+	OpLogical:       {"OpLogical", []int{2, 2}},
+	OpLogicalRight:  {"OpLogicalRight", []int{2}},
+	OpJump:          {"OpJump", []int{2}},
+	OpTest:          {"OpTest", []int{1, 2}},
+	OpPushScope:     {"OpPushScope", []int{}},
+	OpPopScope:      {"OpPopScope", []int{}},
+	OpLoadCallee:    {"OpLoadCallee", []int{2}},
+	OpCall:          {"OpCall", []int{1, 2}},
+	OpReturn:        {"OpReturn", []int{}},
+	OpEnd:           {"OpEnd", []int{}},
+	OpArray:         {"OpArray", []int{2}},
+	OpMap:           {"OpMap", []int{2, 2}},
+	OpSet:           {"OpSet", []int{2, 2}},
+	OpTuple:         {"OpTuple", []int{2}},
+	OpInterpolate:   {"OpInterpolate", []int{2}},
+	OpRange:         {"OpRange", []int{2}},
+	OpIndex:         {"OpIndex", []int{2}},
+	OpSlice:         {"OpSlice", []int{1, 2}},
+	OpIterInit:      {"OpIterInit", []int{2}},
+	OpIterNext:      {"OpIterNext", []int{2}},
+	OpIterEnd:       {"OpIterEnd", []int{}},
+	OpBindIter:      {"OpBindIter", []int{2}},
+	OpBindPattern:   {"OpBindPattern", []int{2}},
+	OpSetResult:     {"OpSetResult", []int{}},
+	OpEval:          {"OpEval", []int{2}},
+	OpSignal:        {"OpSignal", []int{2, 2}},
+	OpPosition:      {"OpPosition", []int{2}},
+}
+
+// Condition kinds for OpTest; they select the error message for non-boolean conditions.
+const (
+	testIf byte = iota
+	testFor
+	testWhile
+)
+
+// Bytecode is a compiled chunk of Go-Mix code: the top level of a program or
+// the body of one function.
+//
+// Fields:
+//   - Instructions: The encoded instruction stream
+//   - Constants: Literal values and builtin functions referenced by OpConstant
+//   - Names: Identifier names referenced by name operands
+//   - Tokens: Source tokens used for operators and error positions
+//   - Nodes: AST nodes used by instructions that share the evaluator's helpers,
+//     and by OpEval for constructs that are delegated to the tree walker
+type Bytecode struct {
+	Instructions []byte
+	Constants    []std.GoMixObject
+	Names        []string
+	Tokens       []lexer.Token
+	Nodes        []parser.Node
+}
+
+// readU16 decodes the 2-byte operand at ins[offset].
+func readU16(ins []byte, offset int) int {
+	return int(binary.BigEndian.Uint16(ins[offset:]))
+}
+
+// String disassembles the bytecode into one instruction per line.
+//
+// Example:
+//
+//	0000 OpConstant 0
+//	0003 OpDeclare 0
+//	0006 OpEnd
+func (b *Bytecode) String() string {
+	var out strings.Builder
+	ins := b.Instructions
+	for ip := 0; ip < len(ins); {
+		def, ok := opDefinitions[Opcode(ins[ip])]
+		if !ok {
+			fmt.Fprintf(&out, "%04d ERROR: unknown opcode %d\n", ip, ins[ip])
+			ip++
+			continue
+		}
+		fmt.Fprintf(&out, "%04d %s", ip, def.Name)
+		offset := ip + 1
+		for _, width := range def.OperandWidths {
+			if width == 2 {
+				fmt.Fprintf(&out, " %d", readU16(ins, offset))
+			} else {
+				fmt.Fprintf(&out, " %d", ins[offset])
+			}
+			offset += width
+		}
+		out.WriteString("\n")
+		ip = offset
+	}
+	return out.String()
+}
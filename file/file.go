@@ -10,17 +10,21 @@ Contact : akashmaji(@iisc.ac.in)
 package file
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/akashmaji946/go-mix/std"
 )
 
 // FileObject represents an open file handle in Go-Mix.
+// A file opened in a binary mode ("rb", "wb", ...) reads bytes with fread.
 type FileObject struct {
 	Handle *os.File
 	Path   string
+	Binary bool
 }
 
 // GetType returns the GoMixType of the object ("file").
@@ -32,13 +36,42 @@ func (f *FileObject) ToString() string { return fmt.Sprintf("<file: %s>", f.Path
 // ToObject returns a detailed representation of the file handle.
 func (f *FileObject) ToObject() string { return f.ToString() }
 
+// Iter returns an iterator over the lines of the file, from the current
+// position to the end, without their line endings. It makes file handles
+// iterable with foreach: foreach i, line in f { ... }
+func (f *FileObject) Iter() std.Iterator {
+	return &lineIterator{reader: bufio.NewReader(f.Handle)}
+}
+
+// lineIterator yields the lines of a file.
+// It reads ahead, so the file position after a loop that stopped early is
+// past the last line yielded.
+type lineIterator struct {
+	reader *bufio.Reader
+	index  int64
+}
+
+// Next implements std.Iterator.
+func (it *lineIterator) Next(rt std.Runtime) (std.GoMixObject, std.GoMixObject, std.GoMixObject) {
+	line, err := it.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, nil, createError("ERROR: read failed: %v", err)
+	}
+	if line == "" && err == io.EOF {
+		return nil, nil, nil
+	}
+	it.index++
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	return &std.Integer{Value: it.index - 1}, &std.String{Value: line}, nil
+}
+
 var fileMethods = []*std.Builtin{
-	{Name: "fopen", Callback: fopen},   // Opens a file and returns a handle
-	{Name: "fclose", Callback: fclose}, // Closes an open file handle
-	{Name: "fread", Callback: fread},   // Reads N bytes from a file handle
-	{Name: "fwrite", Callback: fwrite}, // Writes a string to a file handle
-	{Name: "fseek", Callback: fseek},   // Moves the file cursor
-	{Name: "ftell", Callback: ftell},   // Returns the current cursor position
+	{Name: "fopen", Callback: fopen, Signature: "fopen(path, mode) -> file"},          // Opens a file and returns a handle
+	{Name: "fclose", Callback: fclose, Signature: "fclose(file) -> nil"},              // Closes an open file handle
+	{Name: "fread", Callback: fread, Signature: "fread(file, num_bytes) -> string"},   // Reads N bytes from a file handle
+	{Name: "fwrite", Callback: fwrite, Signature: "fwrite(file, content) -> int"},     // Writes a string or bytes to a file handle
+	{Name: "fseek", Callback: fseek, Signature: "fseek(file, offset, whence) -> int"}, // Moves the file cursor
+	{Name: "ftell", Callback: ftell, Signature: "ftell(file) -> int"},                 // Returns the current cursor position
 }
 
 // init registers the file methods as global builtins.
@@ -55,10 +88,13 @@ func createError(format string, a ...interface{}) *std.Error {
 //
 // Syntax: fopen(path, mode)
 // Modes: "r" (read), "w" (write/truncate), "a" (append), "r+" (read/write)
+// A "b" in the mode ("rb", "wb", "r+b", ...) opens the file in binary mode,
+// where fread returns bytes instead of a string.
 //
 // Example:
 //
 //	var f = fopen("test.txt", "r");
+//	var img = fopen("logo.png", "rb");
 func fopen(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: fopen expects 2 arguments (path, mode)")
@@ -66,6 +102,10 @@ func fopen(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMixO
 
 	path := args[0].ToString()
 	mode := args[1].ToString()
+	binary := strings.Contains(mode, "b")
+	if binary {
+		mode = strings.Replace(mode, "b", "", 1)
+	}
 
 	var flag int
 	switch mode {
@@ -80,7 +120,17 @@ func fopen(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMixO
 	case "w+":
 		flag = os.O_RDWR | os.O_CREATE | os.O_TRUNC
 	default:
-		return createError("ERROR: invalid file mode '%s'", mode)
+		return createError("ERROR: invalid file mode '%s'", args[1].ToString())
+	}
+	if mode != "w" && mode != "a" {
+		if err := std.CheckCapability(rt, "fopen", std.CapFSRead, path); err != nil {
+			return err
+		}
+	}
+	if mode != "r" {
+		if err := std.CheckCapability(rt, "fopen", std.CapFSWrite, path); err != nil {
+			return err
+		}
 	}
 
 	handle, err := os.OpenFile(path, flag, 0644)
@@ -88,7 +138,7 @@ func fopen(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMixO
 		return createError("ERROR: could not open file '%s': %v", path, err)
 	}
 
-	return &FileObject{Handle: handle, Path: path}
+	return &FileObject{Handle: handle, Path: path, Binary: binary}
 }
 
 // fclose closes an open file handle.
@@ -109,7 +159,8 @@ func fclose(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMix
 	return &std.Nil{}
 }
 
-// fread reads a specified number of bytes from the file handle.
+// fread reads a specified number of bytes from the file handle, as a string,
+// or as bytes for a file opened in binary mode.
 //
 // Syntax: fread(file_handle, num_bytes)
 func fread(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMixObject {
@@ -132,12 +183,12 @@ func fread(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMixO
 		return createError("ERROR: read failed: %v", err)
 	}
 
-	return &std.String{Value: string(buf[:n])}
+	return std.NewData(f.Binary, buf[:n])
 }
 
-// fwrite writes a string to the file handle.
+// fwrite writes a string or bytes to the file handle.
 //
-// Syntax: fwrite(file_handle, content_string)
+// Syntax: fwrite(file_handle, content)
 func fwrite(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: fwrite expects 2 arguments (handle, content)")
@@ -147,9 +198,9 @@ func fwrite(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) std.GoMix
 	}
 
 	f := args[0].(*FileObject)
-	content := args[1].ToString()
+	content := std.BytesOf(args[1])
 
-	n, err := f.Handle.WriteString(content)
+	n, err := f.Handle.Write(content)
 	if err != nil {
 		return createError("ERROR: write failed: %v", err)
 	}
@@ -0,0 +1,212 @@
+/*
+File    : go-mix/formatter/formatter.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+/*
+Package formatter prints Go-Mix source code in its canonical layout.
+
+The source is parsed into the parser's AST, which is then printed back with:
+- 4-space indentation and the opening brace on the line of its statement
+- A semicolon after every simple statement, none after braced statements
+- Single spaces around binary operators and after commas
+- Parenthesized conditions (if, switch, while, for)
+- At most one blank line between statements, where the source had some
+
+Comments are not part of the AST: the lexer keeps them as trivia and the
+formatter places each one back by position: before the statement or token
+that follows it, or at the end of the line it trailed (a literal or a call
+with a line comment inside is printed one item per line for this). The
+output re-parses to the same AST as the input.
+*/
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+)
+
+// indentUnit is the indentation of one nesting level
+const indentUnit = "    "
+
+// Format parses src and returns it in the canonical layout.
+//
+// Parameters:
+//   - src: The Go-Mix source code to format
+//
+// Returns:
+//   - string: The formatted source, ending with a newline
+//   - error: The parse errors, if src does not parse (nothing is formatted)
+func Format(src string) (string, error) {
+	par := parser.NewParser(src)
+	root := par.Parse()
+	if par.HasErrors() {
+		return "", fmt.Errorf("%s", strings.Join(par.GetErrors(), "\n"))
+	}
+
+	p := &printer{spans: par.Spans, comments: par.Lex.Comments, bol: true, fresh: true}
+	p.statements(root.Statements, lexer.Token{})
+	p.leading(0)
+	return p.buf.String(), nil
+}
+
+// printer accumulates the formatted output.
+// Comments are consumed in source order as the statements around them
+// are printed; the ones left at the end are printed last.
+type printer struct {
+	buf      strings.Builder
+	indent   int                                  // current nesting level
+	bol      bool                                 // at the beginning of a line (indentation pending)
+	fresh    bool                                 // nothing printed yet in the current block
+	last     int                                  // last source line printed (for blank lines)
+	spans    map[parser.StatementNode]parser.Span // source lines of the statements
+	comments []lexer.Comment                      // comments not printed yet
+}
+
+// write appends s to the current line, indenting it first if needed.
+func (p *printer) write(s string) {
+	if p.bol {
+		p.buf.WriteString(strings.Repeat(indentUnit, p.indent))
+		p.bol = false
+	}
+	p.buf.WriteString(s)
+}
+
+// newline ends the current line.
+func (p *printer) newline() {
+	p.buf.WriteString("\n")
+	p.bol = true
+}
+
+// space keeps one blank line before an item that starts at the given
+// source line, if the source had blank lines before it.
+func (p *printer) space(line int) {
+	if !p.fresh && p.last > 0 && line > p.last+1 {
+		p.newline()
+	}
+	p.fresh = false
+}
+
+// seen records that the source up to the given line has been printed.
+func (p *printer) seen(line int) {
+	if line > p.last {
+		p.last = line
+	}
+}
+
+// leading prints, each on its own line, the comments that start before
+// the given source line (all the remaining comments if line is 0).
+func (p *printer) leading(line int) {
+	p.leadingBefore(line, 0)
+}
+
+// leadingBefore is leading for a position inside a line, such as the
+// column of a closing brace.
+func (p *printer) leadingBefore(line, column int) {
+	for len(p.comments) > 0 && (line == 0 || before(p.comments[0], line, column)) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		if !p.bol {
+			p.newline()
+		}
+		p.space(c.Line)
+		p.write(commentText(c))
+		p.newline()
+		p.seen(c.EndLine)
+	}
+}
+
+// trailing ends the line of an item whose last source line is endLine.
+// Comments that started on that line stay at the end of it, unless they
+// follow the start of the next item (at nextLine, nextColumn); the ones left
+// inside the item (e.g. between the operands of a long expression) follow it.
+func (p *printer) trailing(endLine, nextLine, nextColumn int) {
+	inner := make([]lexer.Comment, 0)
+	for len(p.comments) > 0 && endLine > 0 && p.comments[0].Line <= endLine {
+		c := p.comments[0]
+		if c.Line < endLine {
+			inner = append(inner, c)
+		} else if nextLine == endLine && !before(c, nextLine, nextColumn) {
+			break
+		} else {
+			p.write(" " + commentText(c))
+			p.seen(c.EndLine)
+		}
+		p.comments = p.comments[1:]
+	}
+	p.newline()
+	for _, c := range inner {
+		p.write(commentText(c))
+		p.newline()
+	}
+	p.seen(endLine)
+}
+
+// breakLine ends the line of an opening brace or of an element of a literal.
+// The comments that followed code on that line in the source stay at the
+// end of it, as long as they come before the next item (at line, column).
+func (p *printer) breakLine(line, column int) {
+	for len(p.comments) > 0 && p.comments[0].Trailing && before(p.comments[0], line, column) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		p.write(" " + commentText(c))
+		p.seen(c.EndLine)
+	}
+	p.newline()
+}
+
+// inline prints the block comments that start before the given source line
+// and column and fit on one line, each followed by a space, where the next
+// item is printed (e.g. [1, /* two */ 2]). Other comments are left to the
+// end of the line or of the statement.
+func (p *printer) inline(line, column int) {
+	for len(p.comments) > 0 && before(p.comments[0], line, column) {
+		c := p.comments[0]
+		if isLineComment(c) || c.EndLine > c.Line {
+			return
+		}
+		p.comments = p.comments[1:]
+		p.write(commentText(c) + " ")
+		p.seen(c.EndLine)
+	}
+}
+
+// hasCommentBefore reports whether a comment not printed yet starts before
+// the given source line and column.
+func (p *printer) hasCommentBefore(line, column int) bool {
+	return len(p.comments) > 0 && before(p.comments[0], line, column)
+}
+
+// hasLineCommentBefore reports whether a // comment, or a block comment
+// spanning several lines, not printed yet starts before the given source
+// line and column.
+func (p *printer) hasLineCommentBefore(line, column int) bool {
+	for _, c := range p.comments {
+		if !before(c, line, column) {
+			return false
+		}
+		if isLineComment(c) || c.EndLine > c.Line {
+			return true
+		}
+	}
+	return false
+}
+
+// before reports whether a comment starts before the given line and column.
+func before(c lexer.Comment, line, column int) bool {
+	return c.Line < line || (c.Line == line && c.Column < column)
+}
+
+// commentText is the text of a comment as it is printed.
+func commentText(c lexer.Comment) string {
+	return strings.TrimRight(c.Text, " \t\r")
+}
+
+// isLineComment reports whether a comment is a // comment, which ends its line.
+func isLineComment(c lexer.Comment) bool {
+	return strings.HasPrefix(c.Text, "//")
+}
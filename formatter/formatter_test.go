@@ -0,0 +1,345 @@
+/*
+File    : go-mix/formatter/formatter_test.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+package formatter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// parse returns the AST and the comments of src, or nil if it does not parse
+func parse(src string) (*parser.RootNode, []lexer.Comment) {
+	par := parser.NewParser(src)
+	root := par.Parse()
+	if par.HasErrors() {
+		return nil, nil
+	}
+	return root, par.Lex.Comments
+}
+
+// commentPlaces returns, for each comment of src, its text and the number
+// of tokens before it, leaving out the punctuation the formatter adds or
+// drops (semicolons, commas and parentheses). A comment kept in place has
+// the same entry in the source and in the formatted output.
+func commentPlaces(src string) []string {
+	lex := lexer.NewLexer(src)
+	tokens := lex.ConsumeTokens()
+	places := make([]string, 0, len(lex.Comments))
+	for _, c := range lex.Comments {
+		count := 0
+		for _, tok := range tokens {
+			switch tok.Type {
+			case lexer.SEMICOLON_DELIM, lexer.COMMA_DELIM, lexer.LEFT_PAREN, lexer.RIGHT_PAREN:
+				continue
+			}
+			if tok.Line < c.Line || (tok.Line == c.Line && tok.Column < c.Column) {
+				count++
+			}
+		}
+		places = append(places, fmt.Sprintf("%d %s", count, commentText(c)))
+	}
+	return places
+}
+
+// sameAST reports whether two ASTs are identical, ignoring source positions
+// and the values the parser folds at parse time. It returns the path of the
+// first difference.
+func sameAST(a, b reflect.Value, path string) (bool, string) {
+	if a.Kind() != b.Kind() {
+		return false, path
+	}
+	switch a.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil(), path
+		}
+		if a.Kind() == reflect.Interface && a.Elem().Type() != b.Elem().Type() {
+			return false, path
+		}
+		return sameAST(a.Elem(), b.Elem(), path)
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false, path
+		}
+		for i := 0; i < a.Len(); i++ {
+			if ok, at := sameAST(a.Index(i), b.Index(i), path+"["+string(rune('0'+i%10))+"]"); !ok {
+				return false, at
+			}
+		}
+		return true, path
+	case reflect.Struct:
+		if a.Type() == reflect.TypeOf(lexer.Token{}) {
+			ta, tb := a.Interface().(lexer.Token), b.Interface().(lexer.Token)
+			return ta.Type == tb.Type && ta.Literal == tb.Literal, path
+		}
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).Type == reflect.TypeOf((*std.GoMixObject)(nil)).Elem() {
+				continue
+			}
+			if ok, at := sameAST(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name); !ok {
+				return false, at
+			}
+		}
+		return true, path
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface()), path
+}
+
+// TestFormat_Samples formats every sample program that parses and checks
+// that the output re-parses to the same AST, keeps every comment and is
+// left unchanged by a second pass.
+func TestFormat_Samples(t *testing.T) {
+	files, _ := filepath.Glob("../samples/*/*.gm")
+	nested, _ := filepath.Glob("../samples/*/*/*.gm")
+	files = append(files, nested...)
+	if len(files) == 0 {
+		t.Fatal("no samples found")
+	}
+
+	formatted := 0
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, comments := parse(string(src))
+		if root == nil {
+			continue // samples that demonstrate parse errors
+		}
+		out, err := Format(string(src))
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		formatted++
+
+		again, againComments := parse(out)
+		if again == nil {
+			t.Errorf("%s: formatted output does not parse:\n%s", file, out)
+			continue
+		}
+		if ok, at := sameAST(reflect.ValueOf(root), reflect.ValueOf(again), "root"); !ok {
+			t.Errorf("%s: formatted output parses to a different AST (at %s):\n%s", file, at, out)
+			continue
+		}
+		if len(comments) != len(againComments) {
+			t.Errorf("%s: expected %d comments, got %d", file, len(comments), len(againComments))
+		} else {
+			for i := range comments {
+				if commentText(comments[i]) != againComments[i].Text {
+					t.Errorf("%s: comment %q became %q", file, comments[i].Text, againComments[i].Text)
+				}
+			}
+		}
+		if want, got := commentPlaces(string(src)), commentPlaces(out); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: comments moved: %q became %q", file, want, got)
+		}
+		if twice, _ := Format(out); twice != out {
+			t.Errorf("%s: formatting is not idempotent:\n%s\n---\n%s", file, out, twice)
+		}
+	}
+	if formatted < len(files)/2 {
+		t.Errorf("only %d of %d samples were formatted", formatted, len(files))
+	}
+}
+
+// TestFormat_Layout verifies the canonical layout of a messy program
+func TestFormat_Layout(t *testing.T) {
+	src := `// header comment
+import math as m;
+var  x=1;let s = "a\"b\n" ;  // trailing
+func add(a,b){return a+b}
+func area(w:float,h : float):float{return w*h}
+let names:array< string >=[];var v:int|nil=nil;
+func evens(){foreach n in range(0,4){yield  n*2;}}
+struct Point { var x = 0; func norm() { return m.abs(this.x); } var y = 0 }
+
+
+enum Color { RED, GREEN = 5 }
+if(x>0){x+=1;}else if (x < 0) { x = -x } else { println('\t') }
+for (var i = 0, j = 2; i < j; i = i + 1) { continue; }
+switch x { case 1: println("one"); default:
+  println("other") }
+try { throw "e" } catch (e) {
+  /* ignored */ }
+var k = match(x){0=>"z", // zero
+  [a,...r] if a>0 => a,
+  _ => { x; } };
+var arr = [1,
+  2];
+`
+	want := `// header comment
+import math as m;
+var x = 1;
+let s = "a\"b\n"; // trailing
+func add(a, b) {
+    return a + b;
+}
+func area(w: float, h: float): float {
+    return w * h;
+}
+let names: array<string> = [];
+var v: int | nil = nil;
+func evens() {
+    foreach n in range(0, 4) {
+        yield n * 2;
+    }
+}
+struct Point {
+    var x = 0;
+    func norm() {
+        return m.abs(this.x);
+    }
+    var y = 0;
+}
+
+enum Color { RED, GREEN = 5 }
+if (x > 0) {
+    x += 1;
+} else if (x < 0) {
+    x = -x;
+} else {
+    println('\t');
+}
+for (var i = 0, j = 2; i < j; i = i + 1) {
+    continue;
+}
+switch (x) {
+    case 1:
+        println("one");
+    default:
+        println("other");
+}
+try {
+    throw "e";
+} catch (e) {
+    /* ignored */
+}
+var k = match (x) {
+    0 => "z", // zero
+    [a, ...r] if a > 0 => a,
+    _ => {
+        x;
+    },
+};
+var arr = [
+    1,
+    2
+];
+`
+	got, err := Format(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("unexpected layout:\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+// TestFormat_TrailingComments verifies that comments after an opening brace
+// or an element of a literal stay on their line, and that a second pass
+// leaves the output unchanged
+func TestFormat_TrailingComments(t *testing.T) {
+	src := `var m = map{"a": 1, // a
+ "b": 2};
+var arr = [ // first
+    1, // one
+
+    // standalone
+    2 // two
+];
+func f() { // opens
+    return 1;
+}
+`
+	want := `var m = map{
+    "a": 1, // a
+    "b": 2
+};
+var arr = [ // first
+    1, // one
+
+    // standalone
+    2 // two
+];
+func f() { // opens
+    return 1;
+}
+`
+	got, err := Format(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("unexpected layout:\n%s\n--- want ---\n%s", got, want)
+	}
+	if twice, _ := Format(got); twice != got {
+		t.Errorf("formatting is not idempotent:\n%s\n---\n%s", got, twice)
+	}
+}
+
+// TestFormat_CommentPlaces verifies that comments inside a statement stay
+// next to the token they came before or after, and that formatting twice
+// gives the same output
+func TestFormat_CommentPlaces(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"var a = [1, /* two */ 2, 3];\n", "var a = [1, /* two */ 2, 3];\n"},
+		{"var x = /* c */ 5 +/* d */6;\n", "var x = /* c */ 5 + /* d */ 6;\n"},
+		{
+			"if (a) {\n    println(1);\n} // after if\nelse {\n    println(2);\n}\n",
+			"if (a) {\n    println(1);\n} // after if\nelse {\n    println(2);\n}\n",
+		},
+		{
+			"if (a) { println(1); } /* b */ else if (b) { println(2); }\n// c\nelse { println(3); }\n",
+			"if (a) {\n    println(1);\n} /* b */ else if (b) {\n    println(2);\n}\n// c\nelse {\n    println(3);\n}\n",
+		},
+		{
+			"var user = tuple(1001,  // id\n    \"ann\");\n",
+			"var user = tuple(\n    1001, // id\n    \"ann\"\n);\n",
+		},
+		{
+			"var p = new Point(1, 2 // y\n);\n",
+			"var p = new Point(\n    1,\n    2 // y\n);\n",
+		},
+		{"var b = [1, 2 // last\n];\n", "var b = [\n    1,\n    2 // last\n];\n"},
+	}
+
+	for _, tt := range tests {
+		got, err := Format(tt.src)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.src, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: unexpected layout:\n%s\n--- want ---\n%s", tt.src, got, tt.want)
+		}
+		if want, places := commentPlaces(tt.src), commentPlaces(got); !reflect.DeepEqual(want, places) {
+			t.Errorf("%q: comments moved: %q became %q", tt.src, want, places)
+		}
+		if twice, _ := Format(got); twice != got {
+			t.Errorf("%q: formatting is not idempotent:\n%s\n---\n%s", tt.src, got, twice)
+		}
+	}
+}
+
+// TestFormat_Errors verifies that sources that do not parse are rejected
+func TestFormat_Errors(t *testing.T) {
+	_, err := Format("var = 1;")
+	if err == nil || !strings.Contains(err.Error(), "PARSER ERROR") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+}
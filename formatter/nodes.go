@@ -0,0 +1,960 @@
+/*
+File    : go-mix/formatter/nodes.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+)
+
+// statements prints a list of statements, one per line, with the comments
+// and blank lines that precede them. close is the brace that ends the list
+// (zero if there is none).
+func (p *printer) statements(stmts []parser.StatementNode, close lexer.Token) {
+	for i, stmt := range stmts {
+		span := p.spans[stmt]
+		p.leading(span.Line)
+		p.space(span.Line)
+		p.statement(stmt)
+		if p.needsSemicolon(stmt, stmts[i+1:]) {
+			p.write(";")
+		}
+		if i+1 < len(stmts) {
+			p.trailing(span.EndLine, p.spans[stmts[i+1]].Line, 0)
+		} else {
+			p.trailing(span.EndLine, close.Line, close.Column)
+		}
+	}
+}
+
+// needsSemicolon reports whether stmt is terminated by a semicolon.
+// Braced statements are not, except an enum declaration (which is parsed
+// as an expression) when the next statement could otherwise continue it.
+func (p *printer) needsSemicolon(stmt parser.StatementNode, rest []parser.StatementNode) bool {
+	switch n := stmt.(type) {
+	case *parser.BlockStatementNode, *parser.IfExpressionNode, *parser.ForLoopStatementNode,
+		*parser.WhileLoopStatementNode, *parser.ForeachLoopStatementNode, *parser.StructDeclarationNode,
+		*parser.InterfaceDeclarationNode, *parser.SwitchStatementNode, *parser.TryStatementNode:
+		return false
+	case *parser.FunctionStatementNode:
+		return n.FuncName.Name == ""
+	case *parser.EnumDeclarationNode:
+		if len(rest) == 0 {
+			return false
+		}
+		next := &printer{spans: p.spans}
+		next.statement(rest[0])
+		text := next.buf.String()
+		return text != "" && strings.ContainsRune("([-+!~.", rune(text[0]))
+	}
+	return true
+}
+
+// statement prints a statement (without its terminating semicolon).
+func (p *printer) statement(stmt parser.StatementNode) {
+	switch n := stmt.(type) {
+	case *parser.DeclarativeStatementNode:
+		p.write(n.VarToken.Literal + " ")
+		p.declared(n)
+		p.expr(n.Expr)
+	case *parser.BlockStatementNode:
+		p.block(n)
+	case *parser.ForLoopStatementNode:
+		p.forLoop(n)
+	case *parser.WhileLoopStatementNode:
+		p.write("while (")
+		p.exprList(n.Conditions)
+		p.write(") ")
+		p.block(&n.Body)
+	case *parser.ForeachLoopStatementNode:
+		p.write("foreach ")
+		if n.Key != nil {
+			p.write(n.Key.Name + ", ")
+		}
+		if n.Pattern != nil {
+			p.pattern(n.Pattern)
+		} else {
+			p.write(n.Iterator.Name)
+		}
+		p.write(" in ")
+		p.expr(n.Iterable)
+		p.write(" ")
+		p.block(&n.Body)
+	case *parser.StructDeclarationNode:
+		p.structDecl(n)
+	case *parser.InterfaceDeclarationNode:
+		p.interfaceDecl(n)
+	case *parser.BreakStatementNode:
+		p.write("break")
+	case *parser.ContinueStatementNode:
+		p.write("continue")
+	case *parser.ImportStatementNode:
+		p.importStmt(n)
+	case *parser.SwitchStatementNode:
+		p.switchStmt(n)
+	case *parser.TryStatementNode:
+		p.tryStmt(n)
+	case *parser.ThrowStatementNode:
+		p.write("throw ")
+		p.expr(n.Expr)
+	case *parser.SpawnStatementNode:
+		p.write("spawn ")
+		p.expr(n.Call)
+	case *parser.YieldStatementNode:
+		p.write("yield ")
+		p.expr(n.Expr)
+	case parser.ExpressionNode:
+		p.expr(n)
+	}
+}
+
+// block prints a braced block. An empty block is printed as {}.
+func (p *printer) block(b *parser.BlockStatementNode) {
+	closeLine := b.RBrace.Line
+	if len(b.Statements) == 0 && !p.hasCommentBefore(closeLine, b.RBrace.Column) {
+		p.write("{}")
+		return
+	}
+	p.write("{")
+	if len(b.Statements) > 0 {
+		p.breakLine(p.spans[b.Statements[0]].Line, 0)
+	} else {
+		p.breakLine(closeLine, b.RBrace.Column)
+	}
+	p.indent++
+	p.fresh = true
+	p.statements(b.Statements, b.RBrace)
+	p.leadingBefore(closeLine, b.RBrace.Column)
+	p.indent--
+	p.write("}")
+	p.seen(closeLine)
+}
+
+// forLoop prints for (initializers; condition; updates) { ... }.
+// Declared initializers share their keyword: var i = 0, j = 10.
+func (p *printer) forLoop(n *parser.ForLoopStatementNode) {
+	p.write("for (")
+	for i, init := range n.Initializers {
+		if i > 0 {
+			p.write(", ")
+		}
+		if decl, ok := init.(*parser.DeclarativeStatementNode); ok {
+			if i == 0 {
+				p.write(decl.VarToken.Literal + " ")
+			}
+			p.declared(decl)
+			p.expr(decl.Expr)
+		} else {
+			p.statement(init)
+		}
+	}
+	p.write(";")
+	if n.Condition != nil {
+		p.write(" ")
+		p.expr(n.Condition)
+	}
+	p.write(";")
+	if len(n.Updates) > 0 {
+		p.write(" ")
+		p.exprList(n.Updates)
+	}
+	p.write(") ")
+	p.block(&n.Body)
+}
+
+// structDecl prints a struct with its fields and methods in source order.
+func (p *printer) structDecl(n *parser.StructDeclarationNode) {
+	members := make([]parser.StatementNode, 0, len(n.Fields)+len(n.Methods))
+	for _, field := range n.Fields {
+		members = append(members, field)
+	}
+	for _, method := range n.Methods {
+		members = append(members, method)
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := memberToken(members[i]), memberToken(members[j])
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+
+	closeLine := p.spans[n].EndLine
+	p.write(n.Header() + " ")
+	if len(members) == 0 && !p.hasCommentBefore(closeLine, 0) {
+		p.write("{}")
+		return
+	}
+	p.write("{")
+	p.newline()
+	p.indent++
+	p.fresh = true
+	for i, member := range members {
+		span := p.spans[member]
+		p.leading(span.Line)
+		p.space(span.Line)
+		if field, ok := member.(*parser.DeclarativeStatementNode); ok {
+			p.statement(field)
+			p.write(";")
+		} else {
+			p.function(member.(*parser.FunctionStatementNode))
+		}
+		next := 0
+		if i+1 < len(members) {
+			next = p.spans[members[i+1]].Line
+		}
+		p.trailing(span.EndLine, next, 0)
+	}
+	p.leading(closeLine)
+	p.indent--
+	p.write("}")
+}
+
+// interfaceDecl prints an interface, one method signature per line.
+func (p *printer) interfaceDecl(n *parser.InterfaceDeclarationNode) {
+	closeLine := p.spans[n].EndLine
+	p.write("interface " + n.InterfaceName.Name + " ")
+	if len(n.Methods) == 0 && !p.hasCommentBefore(closeLine, 0) {
+		p.write("{}")
+		return
+	}
+	p.write("{")
+	p.newline()
+	p.indent++
+	p.fresh = true
+	for i, m := range n.Methods {
+		line := m.Name.Token.Line
+		p.leading(line)
+		p.space(line)
+		p.write(m.Literal() + ";")
+		next := 0
+		if i+1 < len(n.Methods) {
+			next = n.Methods[i+1].Name.Token.Line
+		}
+		p.trailing(line, next, 0)
+	}
+	p.leading(closeLine)
+	p.indent--
+	p.write("}")
+}
+
+// memberToken returns the first token of a struct field or method.
+func memberToken(member parser.StatementNode) lexer.Token {
+	if field, ok := member.(*parser.DeclarativeStatementNode); ok {
+		return field.VarToken
+	}
+	return member.(*parser.FunctionStatementNode).FuncToken
+}
+
+// importStmt prints import name [as alias]. Names that are not plain
+// identifiers are quoted.
+func (p *printer) importStmt(n *parser.ImportStatementNode) {
+	name := n.Name
+	if !isIdentifier(name) {
+		name = strconv.Quote(name)
+	}
+	p.write("import " + name)
+	if n.Alias != "" {
+		p.write(" as " + n.Alias)
+	}
+}
+
+// switchStmt prints a switch with its case and default clauses in source order.
+func (p *printer) switchStmt(n *parser.SwitchStatementNode) {
+	type clause struct {
+		token lexer.Token
+		value parser.ExpressionNode // nil for the default clause
+		body  *parser.BlockStatementNode
+	}
+	clauses := make([]clause, 0, len(n.Cases)+1)
+	for i := range n.Cases {
+		clauses = append(clauses, clause{n.Cases[i].Token, n.Cases[i].Value, &n.Cases[i].Body})
+	}
+	if n.Default != nil {
+		clauses = append(clauses, clause{n.Default.Token, nil, &n.Default.Body})
+	}
+	sort.SliceStable(clauses, func(i, j int) bool {
+		return clauses[i].token.Line < clauses[j].token.Line
+	})
+
+	p.write("switch (")
+	p.expr(n.Expression)
+	p.write(") {")
+	p.newline()
+	p.indent++
+	p.fresh = true
+	for i, c := range clauses {
+		p.leading(c.token.Line)
+		p.space(c.token.Line)
+		if c.value != nil {
+			p.write("case ")
+			p.expr(c.value)
+			p.write(":")
+		} else {
+			p.write("default:")
+		}
+		next := 0
+		if len(c.body.Statements) > 0 {
+			next = p.spans[c.body.Statements[0]].Line
+		} else if i+1 < len(clauses) {
+			next = clauses[i+1].token.Line
+		}
+		p.trailing(c.token.Line, next, 0)
+		p.indent++
+		p.fresh = true
+		p.statements(c.body.Statements, lexer.Token{})
+		p.indent--
+	}
+	p.leading(p.spans[n].EndLine)
+	p.indent--
+	p.write("}")
+}
+
+// tryStmt prints try { ... } catch (e) { ... } finally { ... }.
+func (p *printer) tryStmt(n *parser.TryStatementNode) {
+	p.write("try ")
+	p.block(&n.TryBlock)
+	if n.CatchBlock != nil {
+		p.write(" catch ")
+		if n.CatchParam != nil {
+			p.write("(" + n.CatchParam.Name + ") ")
+		}
+		p.block(n.CatchBlock)
+	}
+	if n.FinallyBlock != nil {
+		p.write(" finally ")
+		p.block(n.FinallyBlock)
+	}
+}
+
+// function prints a named function or an anonymous function literal.
+func (p *printer) function(n *parser.FunctionStatementNode) {
+	p.write("func")
+	if n.FuncName.Name != "" {
+		p.write(" " + n.FuncName.Name)
+	}
+	p.write("(")
+	for i, param := range n.FuncParams {
+		if i > 0 {
+			p.write(", ")
+		}
+		if n.FuncRest && i == len(n.FuncParams)-1 {
+			p.write("...")
+		}
+		p.write(param.Name)
+		if typ := n.ParamType(i); typ != nil {
+			p.write(": " + typ.String())
+		}
+		if def := n.Default(i); def != nil {
+			p.write(" = ")
+			p.expr(def)
+		}
+	}
+	p.write(")")
+	if n.ReturnType != nil {
+		p.write(": " + n.ReturnType.String())
+	}
+	p.write(" ")
+	p.block(&n.FuncBody)
+}
+
+// ifExpr prints an if statement; an else block holding only an if
+// statement is printed as an else-if chain.
+func (p *printer) ifExpr(n *parser.IfExpressionNode) {
+	p.write("if ")
+	p.expr(n.Condition)
+	p.write(" ")
+	p.block(&n.ThenBlock)
+	elseBlock := &n.ElseBlock
+	if elseBlock.RBrace.Type == "" {
+		if len(elseBlock.Statements) == 1 {
+			if nested, ok := elseBlock.Statements[0].(*parser.IfExpressionNode); ok {
+				p.elseKeyword(n.ElseToken)
+				p.ifExpr(nested)
+				return
+			}
+		}
+		if len(elseBlock.Statements) == 0 {
+			return
+		}
+	}
+	p.elseKeyword(n.ElseToken)
+	p.block(elseBlock)
+}
+
+// elseKeyword prints the else of an if statement after the comments found
+// between the closing brace and the else in the source. A comment that
+// trailed the brace stays on its line, with the else on the next one.
+func (p *printer) elseKeyword(token lexer.Token) {
+	for token.Line > 0 && p.hasCommentBefore(token.Line, token.Column) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		if !c.Trailing && !p.bol {
+			p.newline()
+		}
+		if !p.bol {
+			p.write(" ")
+		}
+		p.write(commentText(c))
+		if isLineComment(c) || c.EndLine > c.Line {
+			p.newline()
+		}
+		p.seen(c.EndLine)
+	}
+	if p.bol {
+		p.write("else ")
+	} else {
+		p.write(" else ")
+	}
+}
+
+// matchExpr prints a match expression with one arm per line, each followed
+// by a comma.
+func (p *printer) matchExpr(n *parser.MatchExpressionNode) {
+	p.write("match ")
+	p.expr(n.Subject)
+	p.write(" {")
+	p.newline()
+	p.indent++
+	p.fresh = true
+	for i, arm := range n.Arms {
+		span := p.spans[arm.Body]
+		p.leading(span.Line)
+		p.space(span.Line)
+		p.matchPattern(arm.Pattern)
+		if arm.Guard != nil {
+			p.write(" if ")
+			p.expr(arm.Guard)
+		}
+		p.write(" => ")
+		p.expr(arm.Body)
+		p.write(",")
+		if i+1 < len(n.Arms) {
+			p.trailing(span.EndLine, p.spans[n.Arms[i+1].Body].Line, 0)
+		} else {
+			p.trailing(span.EndLine, n.RBrace.Line, n.RBrace.Column)
+		}
+	}
+	p.leadingBefore(n.RBrace.Line, n.RBrace.Column)
+	p.indent--
+	p.write("}")
+	p.seen(n.RBrace.Line)
+}
+
+// matchPattern prints the pattern of a match arm, such as Point{x, y: 0}.
+func (p *printer) matchPattern(n *parser.MatchPatternNode) {
+	switch n.Kind {
+	case parser.WildcardPattern:
+		p.write("_")
+		return
+	case parser.BindingPattern:
+		p.write(n.Name.Name)
+		return
+	case parser.ValuePattern:
+		p.expr(n.Value)
+		return
+	case parser.RangePattern:
+		p.expr(n.Value)
+		p.write("...")
+		p.expr(n.End)
+		return
+	case parser.AlternativePattern:
+		for i, alt := range n.Elements {
+			if i > 0 {
+				p.write(" | ")
+			}
+			p.matchPattern(alt)
+		}
+		return
+	}
+	open, close := "{", "}"
+	switch {
+	case n.Kind == parser.StructPattern:
+		p.write(n.Name.Name)
+	case n.Token.Type == lexer.LEFT_BRACKET:
+		open, close = "[", "]"
+	case n.Token.Type == lexer.LEFT_PAREN:
+		open, close = "(", ")"
+	}
+	p.write(open)
+	for i, elem := range n.Elements {
+		if i > 0 {
+			p.write(", ")
+		}
+		if n.Kind != parser.SequencePattern {
+			key := n.Keys[i]
+			if elem.Kind == parser.BindingPattern && elem.Name.Name == key {
+				p.write(key)
+				continue
+			}
+			if !isIdentifier(key) {
+				key = quote(key, '"')
+			}
+			p.write(key + ": ")
+		}
+		p.matchPattern(elem)
+	}
+	if n.Rest != nil {
+		if len(n.Elements) > 0 {
+			p.write(", ")
+		}
+		p.write("..." + n.Rest.Name)
+	} else if open == "(" && len(n.Elements) == 1 {
+		p.write(",")
+	}
+	p.write(close)
+}
+
+// expr prints an expression. Parentheses are part of the AST, so the
+// expression is printed exactly as it was grouped in the source.
+func (p *printer) expr(node parser.ExpressionNode) {
+	if token := firstToken(node); token.Line > 0 {
+		p.inline(token.Line, token.Column)
+	}
+	switch n := node.(type) {
+	case *parser.IntegerLiteralExpressionNode:
+		p.write(n.Token.Literal)
+	case *parser.FloatLiteralExpressionNode:
+		p.write(n.Token.Literal)
+	case *parser.BooleanLiteralExpressionNode:
+		p.write(n.Token.Literal)
+	case *parser.NilLiteralExpressionNode:
+		p.write("nil")
+	case *parser.StringLiteralExpressionNode:
+		if n.Token.Type == lexer.RAW_STRING_LIT {
+			p.write("`" + n.Token.Literal + "`")
+		} else {
+			p.write(quote(n.Token.Literal, '"'))
+		}
+	case *parser.InterpolatedStringExpressionNode:
+		p.write(`"` + escape(n.Texts[0], '"'))
+		for i, expr := range n.Exprs {
+			p.write("${")
+			p.expr(expr)
+			p.write("}" + escape(n.Texts[i+1], '"'))
+		}
+		p.write(`"`)
+	case *parser.CharLiteralExpressionNode:
+		p.write(quote(n.Token.Literal, '\''))
+	case *parser.IdentifierExpressionNode:
+		p.write(n.Name)
+	case *parser.SuperExpressionNode:
+		p.write("super")
+	case *parser.ParenthesizedExpressionNode:
+		p.write("(")
+		p.expr(n.Expr)
+		p.write(")")
+	case *parser.UnaryExpressionNode:
+		p.write(n.Operation.Literal)
+		p.expr(n.Right)
+	case *parser.BinaryExpressionNode:
+		p.expr(n.Left)
+		if n.Operation.Type == lexer.DOT_OP {
+			p.write(".")
+		} else {
+			p.write(" " + n.Operation.Literal + " ")
+		}
+		p.expr(n.Right)
+	case *parser.BooleanExpressionNode:
+		p.expr(n.Left)
+		p.write(" " + n.Operation.Literal + " ")
+		p.expr(n.Right)
+	case *parser.AssignmentExpressionNode:
+		p.assignment(n)
+	case *parser.ReturnStatementNode:
+		p.write("return")
+		if nl, ok := n.Expr.(*parser.NilLiteralExpressionNode); !ok || nl.Token.Line != 0 {
+			p.write(" ")
+			p.expr(n.Expr)
+		}
+	case *parser.CallExpressionNode:
+		p.expr(n.Callee)
+		p.arguments(n.Arguments, n.ArgNames, n.RightParen)
+	case *parser.NewCallExpressionNode:
+		p.write("new " + n.StructName.Name)
+		p.arguments(n.Arguments, n.ArgNames, n.RightParen)
+	case *parser.IndexExpressionNode:
+		p.expr(n.Left)
+		p.write("[")
+		p.expr(n.Index)
+		p.write("]")
+	case *parser.SliceExpressionNode:
+		p.expr(n.Left)
+		p.write("[")
+		if n.Start != nil {
+			p.expr(n.Start)
+		}
+		p.write(":")
+		if n.End != nil {
+			p.expr(n.End)
+		}
+		p.write("]")
+	case *parser.RangeExpressionNode:
+		p.expr(n.Start)
+		p.write("...")
+		p.expr(n.End)
+	case *parser.TupleExpressionNode:
+		if n.LeftParen.Type == "" {
+			p.exprList(n.Elements)
+		} else {
+			p.write("(")
+			p.exprList(n.Elements)
+			p.write(")")
+		}
+	case *parser.PatternNode:
+		p.pattern(n)
+	case *parser.ArrayExpressionNode:
+		p.elements("[", "]", n.Elements, nil, n.RBracket)
+	case *parser.MapExpressionNode:
+		p.elements("map{", "}", n.Keys, n.Values, n.RBrace)
+	case *parser.SetExpressionNode:
+		p.elements("set{", "}", n.Elements, nil, n.RBrace)
+	case *parser.FunctionStatementNode:
+		p.function(n)
+	case *parser.IfExpressionNode:
+		p.ifExpr(n)
+	case *parser.MatchExpressionNode:
+		p.matchExpr(n)
+	case *parser.EnumDeclarationNode:
+		p.enumDecl(n)
+	case *parser.EnumAccessExpressionNode:
+		p.write(n.EnumName.Name + "." + n.MemberName.Name)
+	case *parser.BlockStatementNode:
+		p.block(n)
+	}
+}
+
+// assignment prints an assignment. The parser turns x += e into
+// x = x + e with the same identifier node on both sides, which is how
+// the compound form is recognized and printed back.
+func (p *printer) assignment(n *parser.AssignmentExpressionNode) {
+	if bin, ok := n.Right.(*parser.BinaryExpressionNode); ok && n.Operation.Type == lexer.ASSIGN_OP && bin.Left == n.Left {
+		p.expr(n.Left)
+		p.write(" " + bin.Operation.Literal + "= ")
+		p.expr(bin.Right)
+		return
+	}
+	p.expr(n.Left)
+	p.write(" " + n.Operation.Literal + " ")
+	p.expr(n.Right)
+}
+
+// declared prints the name (or the pattern) a declaration binds, up to its value.
+func (p *printer) declared(n *parser.DeclarativeStatementNode) {
+	if n.Pattern != nil {
+		p.pattern(n.Pattern)
+	} else {
+		p.write(n.Identifier.Name)
+	}
+	if n.VarType != nil {
+		p.write(": " + n.VarType.String())
+	}
+	p.write(" = ")
+}
+
+// pattern prints a destructuring pattern, such as [first, ...rest].
+func (p *printer) pattern(n *parser.PatternNode) {
+	p.write(n.Open.Literal)
+	for i, name := range n.Bindings() {
+		if i > 0 {
+			p.write(", ")
+		}
+		if name == n.Rest {
+			p.write("...")
+		}
+		p.write(name.Name)
+	}
+	p.write(n.Close())
+}
+
+// exprList prints comma-separated expressions.
+func (p *printer) exprList(exprs []parser.ExpressionNode) {
+	for i, e := range exprs {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expr(e)
+	}
+}
+
+// arguments prints the parenthesized arguments of a call, with the names of
+// the named ones (rparen is the closing parenthesis). Arguments with a line
+// comment among them are printed one per line, each keeping its comment.
+func (p *printer) arguments(args []parser.ExpressionNode, names []string, rparen lexer.Token) {
+	argument := func(i int) {
+		if i < len(names) && names[i] != "" {
+			p.write(names[i] + ": ")
+		}
+		p.expr(args[i])
+	}
+	p.items("(", ")", args, argument, rparen, p.hasLineCommentBefore(rparen.Line, rparen.Column))
+}
+
+// elements prints the elements of an array, map or set literal (values
+// holds the map values, nil otherwise; rbrace is the closing bracket). A
+// literal that spanned several lines in the source, or that holds a line
+// comment, is printed with one element per line, each keeping the comment
+// that trailed it.
+func (p *printer) elements(open, close string, keys, values []parser.ExpressionNode, rbrace lexer.Token) {
+	element := func(i int) {
+		p.expr(keys[i])
+		if values != nil {
+			p.write(": ")
+			p.expr(values[i])
+		}
+	}
+	multiline := len(keys) >= 2 && firstLine(keys[0]) != firstLine(keys[len(keys)-1])
+	p.items(open, close, keys, element, rbrace, multiline || p.hasLineCommentBefore(rbrace.Line, rbrace.Column))
+}
+
+// items prints comma-separated items between open and close, whose source
+// token is end. print prints the i-th item, which starts at the first token
+// of items[i]. The items go on one line, or one per line if multiline is set.
+func (p *printer) items(open, close string, items []parser.ExpressionNode, print func(i int), end lexer.Token, multiline bool) {
+	p.write(open)
+	if !multiline {
+		for i := range items {
+			if i > 0 {
+				p.write(", ")
+			}
+			print(i)
+		}
+		p.write(close)
+		return
+	}
+
+	if len(items) > 0 {
+		p.breakLine(firstLine(items[0]), 0)
+	} else {
+		p.breakLine(end.Line, end.Column)
+	}
+	p.indent++
+	p.fresh = true
+	for i := range items {
+		line := firstLine(items[i])
+		if line > 0 {
+			p.leading(line)
+			p.fresh = false
+			p.seen(line)
+		}
+		print(i)
+		if i < len(items)-1 {
+			p.write(",")
+			p.breakLine(firstLine(items[i+1]), 0)
+		} else {
+			p.breakLine(end.Line, end.Column)
+		}
+	}
+	p.leadingBefore(end.Line, end.Column)
+	p.indent--
+	p.write(close)
+	p.fresh = false
+	p.seen(end.Line)
+}
+
+// enumDecl prints an enum. Only the values written in the source are
+// printed; an enum that spanned several lines keeps one member per line.
+func (p *printer) enumDecl(n *parser.EnumDeclarationNode) {
+	p.write("enum " + n.EnumName.Name + " ")
+	member := func(m *parser.EnumMemberNode) {
+		p.write(m.Name)
+		if m.ValueToken.Type != "" {
+			p.write(" = " + m.ValueToken.Literal)
+		}
+	}
+	multiline := false
+	for _, m := range n.Members {
+		multiline = multiline || m.Token.Line != n.EnumToken.Line
+	}
+	if len(n.Members) == 0 {
+		p.write("{}")
+		return
+	}
+	if !multiline {
+		p.write("{ ")
+		for i, m := range n.Members {
+			if i > 0 {
+				p.write(", ")
+			}
+			member(m)
+		}
+		p.write(" }")
+		return
+	}
+
+	p.write("{")
+	p.newline()
+	p.indent++
+	p.fresh = true
+	for i, m := range n.Members {
+		p.leading(m.Token.Line)
+		p.space(m.Token.Line)
+		member(m)
+		if i < len(n.Members)-1 {
+			p.write(",")
+		}
+		next := 0
+		if i+1 < len(n.Members) {
+			next = n.Members[i+1].Token.Line
+		}
+		p.trailing(m.Token.Line, next, 0)
+	}
+	if span, ok := p.spans[n]; ok {
+		p.leading(span.EndLine)
+	}
+	p.indent--
+	p.write("}")
+}
+
+// firstLine returns the source line of the first token of an expression,
+// or 0 if it is not known.
+func firstLine(node parser.ExpressionNode) int {
+	return firstToken(node).Line
+}
+
+// firstToken returns the first token of an expression (the first element of
+// a collection literal), or a zero token if it is not known.
+func firstToken(node parser.ExpressionNode) lexer.Token {
+	switch n := node.(type) {
+	case *parser.IntegerLiteralExpressionNode:
+		return n.Token
+	case *parser.FloatLiteralExpressionNode:
+		return n.Token
+	case *parser.BooleanLiteralExpressionNode:
+		return n.Token
+	case *parser.NilLiteralExpressionNode:
+		return n.Token
+	case *parser.StringLiteralExpressionNode:
+		return n.Token
+	case *parser.InterpolatedStringExpressionNode:
+		return n.Token
+	case *parser.CharLiteralExpressionNode:
+		return n.Token
+	case *parser.IdentifierExpressionNode:
+		return n.Token
+	case *parser.SuperExpressionNode:
+		return n.Token
+	case *parser.CallExpressionNode:
+		return firstToken(n.Callee)
+	case *parser.NewCallExpressionNode:
+		return n.NewToken
+	case *parser.UnaryExpressionNode:
+		return n.Operation
+	case *parser.ParenthesizedExpressionNode:
+		return firstToken(n.Expr)
+	case *parser.BinaryExpressionNode:
+		return firstToken(n.Left)
+	case *parser.BooleanExpressionNode:
+		return firstToken(n.Left)
+	case *parser.AssignmentExpressionNode:
+		return firstToken(n.Left)
+	case *parser.IndexExpressionNode:
+		return firstToken(n.Left)
+	case *parser.SliceExpressionNode:
+		return firstToken(n.Left)
+	case *parser.RangeExpressionNode:
+		return firstToken(n.Start)
+	case *parser.TupleExpressionNode:
+		if n.LeftParen.Type != "" {
+			return n.LeftParen
+		}
+		return firstToken(n.Elements[0])
+	case *parser.PatternNode:
+		return n.Open
+	case *parser.ArrayExpressionNode:
+		if len(n.Elements) > 0 {
+			return firstToken(n.Elements[0])
+		}
+	case *parser.MapExpressionNode:
+		if len(n.Keys) > 0 {
+			return firstToken(n.Keys[0])
+		}
+	case *parser.SetExpressionNode:
+		if len(n.Elements) > 0 {
+			return firstToken(n.Elements[0])
+		}
+	case *parser.FunctionStatementNode:
+		return n.FuncToken
+	case *parser.IfExpressionNode:
+		return n.IfToken
+	case *parser.MatchExpressionNode:
+		return n.Token
+	case *parser.EnumDeclarationNode:
+		return n.EnumToken
+	case *parser.ReturnStatementNode:
+		return n.ReturnToken
+	}
+	return lexer.Token{}
+}
+
+// quote prints a string (or char) literal, escaping what the lexer unescaped.
+func quote(s string, delim byte) string {
+	return string(delim) + escape(s, delim) + string(delim)
+}
+
+// escape writes the escape sequences of the content of a string (or char)
+// literal. In a string, the $ of a ${ that is not interpolated is escaped.
+// Other characters outside ASCII are written as they are.
+func escape(s string, delim byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\v':
+			b.WriteString(`\v`)
+		case 0:
+			b.WriteString(`\0`)
+		case delim:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '$':
+			if delim == '"' && i+1 < len(s) && s[i+1] == '{' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		default:
+			// Control characters and bytes that are not UTF-8 are written as \xHH
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if c < ' ' || c == 0x7f || (r == utf8.RuneError && size == 1) {
+				fmt.Fprintf(&b, `\x%02x`, c)
+			} else {
+				b.WriteString(s[i : i+size])
+				i += size - 1
+			}
+		}
+	}
+	return b.String()
+}
+
+// isIdentifier reports whether name can be written as a bare identifier.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	if _, keyword := lexer.KEYWORDS_MAP[name]; keyword {
+		return false
+	}
+	for i, c := range name {
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -25,15 +25,95 @@ import (
 //     function is called.
 //   - Body: A block statement node containing the function's executable
 //     statements. This is evaluated when the function is invoked.
+//   - Defaults: The default values of the parameters, parallel to Params
+//     (nil where a parameter has none). They are evaluated at call time,
+//     in the scope of the call, when no argument is given for them.
+//   - Rest: Whether the last parameter collects the remaining arguments
+//     into an array (func sum(...nums)).
+//   - ParamTypes: The declared types of the parameters, parallel to Params
+//     (nil where a parameter is not annotated), checked when the function
+//     is called.
+//   - Result: The declared return type (nil if not annotated), checked when
+//     the function returns.
 //   - Scp: A pointer to the scope in which the function was defined.
 //     This enables closure behavior, allowing the function to access
 //     variables from its enclosing scope even after that scope has
 //     finished executing.
+//   - File: The source file the function was declared in, reported in
+//     the tracebacks of runtime errors ("" if not read from a file).
 type Function struct {
-	Name   string                             // Name of the function
-	Params []*parser.IdentifierExpressionNode // Function parameter names
-	Body   *parser.BlockStatementNode         // Function body (statements to execute)
-	Scp    *scope.Scope                       // Captured scope for closures
+	Name       string                             // Name of the function
+	Params     []*parser.IdentifierExpressionNode // Function parameter names
+	Defaults   []parser.ExpressionNode            // Default parameter values (nil where there is none)
+	Rest       bool                               // Whether the last parameter is a rest parameter
+	ParamTypes []*std.TypeAnnotation              // Declared parameter types (nil where there is none)
+	Result     *std.TypeAnnotation                // Declared return type (nil if not annotated)
+	Generator  bool                               // Whether calls return a generator running the body (see eval_generators.go)
+	Body       *parser.BlockStatementNode         // Function body (statements to execute)
+	Scp        *scope.Scope                       // Captured scope for closures
+	File       string                             // Declaring source file (for tracebacks)
+}
+
+// Default returns the default value of the i-th parameter, or nil if it has none.
+func (f *Function) Default(i int) parser.ExpressionNode {
+	if i < len(f.Defaults) {
+		return f.Defaults[i]
+	}
+	return nil
+}
+
+// ParamType returns the declared type of the i-th parameter, or nil if it has none.
+func (f *Function) ParamType(i int) *std.TypeAnnotation {
+	if i < len(f.ParamTypes) {
+		return f.ParamTypes[i]
+	}
+	return nil
+}
+
+// ParamIndex returns the position of the parameter called name, or -1 if
+// there is none.
+func (f *Function) ParamIndex(name string) int {
+	for i, param := range f.Params {
+		if param.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Arity returns the number of arguments the function accepts.
+//
+// Returns:
+//   - int: The number of parameters without a default value (and not rest)
+//   - int: The number of parameters that are not rest, which is the most
+//     arguments accepted unless the function has a rest parameter
+func (f *Function) Arity() (required, fixed int) {
+	fixed = len(f.Params)
+	if f.Rest {
+		fixed--
+	}
+	for required < fixed && f.Default(required) == nil {
+		required++
+	}
+	return required, fixed
+}
+
+// paramList returns the parameters as written in the declaration, separated by commas.
+func (f *Function) paramList() string {
+	args := ""
+	for i, param := range f.Params {
+		if i > 0 {
+			args += "," // Add comma between parameters
+		}
+		if f.Rest && i == len(f.Params)-1 {
+			args += "..."
+		}
+		args += param.Name
+		if def := f.Default(i); def != nil {
+			args += "=" + def.Literal()
+		}
+	}
+	return args
 }
 
 // GetName returns the name of the function.
@@ -66,14 +146,7 @@ func (f *Function) GetType() std.GoMixType {
 // Returns:
 //   - string: A formatted string representation of the function
 func (f *Function) ToString() string {
-	// Build a comma-separated list of parameter names
-	args := ""
-	for i, param := range f.Params {
-		if i > 0 {
-			args += "," // Add comma between parameters
-		}
-		args += param.Name
-	}
+	args := f.paramList()
 	body := f.Body.Literal() // Get a string representation of the function body
 	// Return the formatted function representation
 	return fmt.Sprintf("func %s(%s) %s", f.Name, args, body)
@@ -93,14 +166,7 @@ func (f *Function) ToString() string {
 // Returns:
 //   - string: A detailed string representation including name and parameters
 func (f *Function) ToObject() string {
-	// Build a comma-separated list of parameter names
-	args := ""
-	for i, param := range f.Params {
-		if i > 0 {
-			args += "," // Add comma between parameters
-		}
-		args += param.Name
-	}
+	args := f.paramList()
 	body := f.Body.Literal() // Get a string representation of the function body
 	// Return the formatted function representation
 	return fmt.Sprintf("<func %s(%s) %s>", f.Name, args, body)
@@ -0,0 +1,558 @@
+/*
+File    : go-mix/gomix/convert.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package gomix
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// objectType is the reflect type of std.GoMixObject, used to pass script
+// objects through to Go parameters of that type unconverted.
+var objectType = reflect.TypeOf((*std.GoMixObject)(nil)).Elem()
+
+// errorType is the reflect type of the error interface.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// bigIntType and bigRatType are the Go types of bigints and decimals.
+var (
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+	bigRatType = reflect.TypeOf((*big.Rat)(nil))
+)
+
+// ToObject converts a Go value into a Go-Mix object using reflection.
+//
+// Conversions:
+//   - nil, nil pointers and nil interfaces -> nil
+//   - bool -> bool; signed and unsigned integers -> int (bigint for uint64
+//     values too large for an int); float32/64 -> float; *big.Int -> bigint;
+//     *big.Rat -> decimal (for fractions with a finite decimal expansion)
+//   - string -> string; []byte -> bytes
+//   - other slices and arrays -> array
+//   - maps -> map, with keys converted to their string form and sorted
+//   - structs -> map of the exported fields (the tag `gomix:"name"` renames
+//     a field, `gomix:"-"` skips it)
+//   - pointers and interfaces -> the value they point to
+//   - values that already implement std.GoMixObject are returned unchanged
+//
+// Functions must be exposed with VM.RegisterFunc instead.
+//
+// Parameters:
+//   - value: The Go value
+//
+// Returns:
+//   - std.GoMixObject: The converted object
+//   - error: An error for values that cannot be represented (e.g., channels)
+func ToObject(value interface{}) (std.GoMixObject, error) {
+	if value == nil {
+		return &std.Nil{}, nil
+	}
+	return valueToObject(reflect.ValueOf(value))
+}
+
+// valueToObject implements ToObject on reflect values.
+func valueToObject(rv reflect.Value) (std.GoMixObject, error) {
+	if rv.CanInterface() {
+		if obj, ok := rv.Interface().(std.GoMixObject); ok {
+			return obj, nil
+		}
+		if n, ok := rv.Interface().(*big.Int); ok && n != nil {
+			return &std.BigInt{Value: new(big.Int).Set(n)}, nil
+		}
+		if r, ok := rv.Interface().(*big.Rat); ok && r != nil {
+			return ratToDecimal(r)
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return &std.Boolean{Value: rv.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &std.Integer{Value: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return &std.BigInt{Value: new(big.Int).SetUint64(rv.Uint())}, nil
+		}
+		return &std.Integer{Value: int64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &std.Float{Value: rv.Float()}, nil
+	case reflect.String:
+		return &std.String{Value: rv.String()}, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return &std.Bytes{Value: append([]byte{}, rv.Bytes()...)}, nil
+		}
+		elements := make([]std.GoMixObject, rv.Len())
+		for i := range elements {
+			elem, err := valueToObject(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = elem
+		}
+		return &std.Array{Elements: elements}, nil
+	case reflect.Map:
+		pairs := make([]*std.MapPair, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := valueToObject(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			val, err := valueToObject(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, &std.MapPair{Key: key, Value: val})
+		}
+		// Go map order is random; sorting keeps scripts deterministic
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key.ToString() < pairs[j].Key.ToString() })
+		m := std.NewMap()
+		for _, pair := range pairs {
+			hk, err := std.Hash(nil, pair.Key)
+			if err != nil {
+				return nil, fmt.Errorf("gomix: cannot convert %s: %s", rv.Type(), err.ToString())
+			}
+			m.Set(hk, pair.Key, pair.Value)
+		}
+		return m, nil
+	case reflect.Struct:
+		return structToObject(rv)
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return &std.Nil{}, nil
+		}
+		return valueToObject(rv.Elem())
+	case reflect.Func:
+		return nil, fmt.Errorf("gomix: cannot convert %s, use RegisterFunc for functions", rv.Type())
+	default:
+		return nil, fmt.Errorf("gomix: cannot convert %s to a Go-Mix object", rv.Type())
+	}
+}
+
+// ratToDecimal converts a fraction into a decimal. Only fractions whose
+// denominator has no prime factors other than 2 and 5 are exact decimals.
+func ratToDecimal(r *big.Rat) (std.GoMixObject, error) {
+	denom := new(big.Int).Set(r.Denom())
+	scale := 0
+	for _, p := range []int64{2, 5} {
+		count := 0
+		for factor := big.NewInt(p); new(big.Int).Rem(denom, factor).Sign() == 0; count++ {
+			denom.Quo(denom, factor)
+		}
+		scale = max(scale, count)
+	}
+	dec, ok := std.ParseDecimal(r.FloatString(scale))
+	if denom.Cmp(big.NewInt(1)) != 0 || !ok {
+		return nil, fmt.Errorf("gomix: cannot convert %s to a decimal exactly", r.RatString())
+	}
+	return dec, nil
+}
+
+// fieldName returns the name of a struct field in Go-Mix (its gomix tag, if
+// any), and whether the field is converted at all.
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	name := field.Name
+	if tag, ok := field.Tag.Lookup("gomix"); ok {
+		if tag == "-" {
+			return "", false
+		}
+		if tag != "" {
+			name = tag
+		}
+	}
+	return name, true
+}
+
+// structToObject converts the exported fields of a struct into a map.
+func structToObject(rv reflect.Value) (std.GoMixObject, error) {
+	m := std.NewMap()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		val, err := valueToObject(rv.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		m.SetString(name, val)
+	}
+	return m, nil
+}
+
+// FromObject converts a Go-Mix object into a plain Go value.
+//
+// Conversions:
+//   - int -> int64; float -> float64; char -> rune; string -> string; bool -> bool
+//   - bigint -> *big.Int; decimal -> *big.Rat (its exact value)
+//   - bytes -> []byte
+//   - nil -> nil
+//   - array, list and tuple -> []interface{}
+//   - map -> map[string]interface{} if all its keys are strings, otherwise
+//     map[interface{}]interface{} with the keys converted too (keys Go cannot
+//     compare, such as tuples, are kept as Go-Mix objects)
+//   - set -> []interface{}
+//   - anything else (functions, struct instances, ranges, ...) is returned
+//     unchanged as a std.GoMixObject, so it can be passed back to the VM
+//
+// Parameters:
+//   - obj: The Go-Mix object
+//
+// Returns:
+//   - interface{}: The converted Go value
+func FromObject(obj std.GoMixObject) interface{} {
+	switch o := obj.(type) {
+	case nil, *std.Nil:
+		return nil
+	case *std.Integer:
+		return o.Value
+	case *std.Float:
+		return o.Value
+	case *std.BigInt:
+		return new(big.Int).Set(o.Value)
+	case *std.Decimal:
+		return o.Rat()
+	case *std.Char:
+		return o.Value
+	case *std.String:
+		return o.Value
+	case *std.Bytes:
+		return append([]byte{}, o.Value...)
+	case *std.Boolean:
+		return o.Value
+	case *std.Array:
+		return elementsFromObjects(o.Elements)
+	case *std.List:
+		return elementsFromObjects(o.Elements)
+	case *std.Tuple:
+		return elementsFromObjects(o.Elements)
+	case *std.Map:
+		return mapFromObject(o)
+	case *std.Set:
+		return elementsFromObjects(o.Values())
+	default:
+		return obj
+	}
+}
+
+// mapFromObject converts a map, keeping the types of its keys unless they are
+// all strings.
+func mapFromObject(m *std.Map) interface{} {
+	stringKeys := true
+	for _, pair := range m.Pairs {
+		if pair.Key.GetType() != std.StringType {
+			stringKeys = false
+			break
+		}
+	}
+	if stringKeys {
+		res := make(map[string]interface{}, m.Len())
+		for _, pair := range m.Pairs {
+			res[pair.Key.ToString()] = FromObject(pair.Value)
+		}
+		return res
+	}
+	res := make(map[interface{}]interface{}, m.Len())
+	for _, pair := range m.Pairs {
+		key := FromObject(pair.Key)
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			key = pair.Key
+		}
+		res[key] = FromObject(pair.Value)
+	}
+	return res
+}
+
+// elementsFromObjects converts the elements of a sequence.
+func elementsFromObjects(elements []std.GoMixObject) []interface{} {
+	res := make([]interface{}, len(elements))
+	for i, elem := range elements {
+		res[i] = FromObject(elem)
+	}
+	return res
+}
+
+// objectToType converts a Go-Mix object into a Go value of type t, for the
+// arguments of registered functions. Integers convert to any numeric type
+// that holds them, bigints to *big.Int, decimals to *big.Rat, sequences and
+// maps convert element by element (map keys too), and maps and struct
+// instances convert to structs field by field.
+func objectToType(obj std.GoMixObject, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		if val := FromObject(obj); val != nil {
+			return reflect.ValueOf(val), nil
+		}
+		return reflect.Zero(t), nil
+	}
+	if reflect.TypeOf(obj).AssignableTo(t) {
+		return reflect.ValueOf(obj), nil
+	}
+	if t == objectType {
+		return reflect.ValueOf(&obj).Elem(), nil
+	}
+	if obj.GetType() == std.NilType {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot use nil as %s", t)
+	}
+
+	switch t {
+	case bigIntType:
+		switch o := obj.(type) {
+		case *std.Integer:
+			return reflect.ValueOf(big.NewInt(o.Value)), nil
+		case *std.BigInt:
+			return reflect.ValueOf(new(big.Int).Set(o.Value)), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.GetType(), t)
+	case bigRatType:
+		switch o := obj.(type) {
+		case *std.Integer:
+			return reflect.ValueOf(new(big.Rat).SetInt64(o.Value)), nil
+		case *std.BigInt:
+			return reflect.ValueOf(new(big.Rat).SetInt(o.Value)), nil
+		case *std.Decimal:
+			return reflect.ValueOf(o.Rat()), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.GetType(), t)
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		val := reflect.ValueOf(FromObject(obj))
+		if val.Type().Implements(t) {
+			return val.Convert(t), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch o := obj.(type) {
+		case *std.Integer:
+			n = o.Value
+		case *std.Char:
+			n = int64(o.Value)
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.GetType(), t)
+		}
+		if reflect.Zero(t).OverflowInt(n) {
+			return reflect.Value{}, fmt.Errorf("integer %d overflows %s", n, t)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if o, ok := obj.(*std.Integer); ok {
+			if o.Value < 0 || reflect.Zero(t).OverflowUint(uint64(o.Value)) {
+				return reflect.Value{}, fmt.Errorf("integer %d overflows %s", o.Value, t)
+			}
+			return reflect.ValueOf(uint64(o.Value)).Convert(t), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch o := obj.(type) {
+		case *std.Float:
+			return reflect.ValueOf(o.Value).Convert(t), nil
+		case *std.Integer:
+			return reflect.ValueOf(float64(o.Value)).Convert(t), nil
+		}
+	case reflect.String:
+		switch o := obj.(type) {
+		case *std.String:
+			return reflect.ValueOf(o.Value).Convert(t), nil
+		case *std.Char:
+			return reflect.ValueOf(string(o.Value)).Convert(t), nil
+		}
+	case reflect.Bool:
+		if o, ok := obj.(*std.Boolean); ok {
+			return reflect.ValueOf(o.Value).Convert(t), nil
+		}
+	case reflect.Slice:
+		if b, ok := obj.(*std.Bytes); ok && t.Elem().Kind() == reflect.Uint8 {
+			return reflect.ValueOf(append([]byte{}, b.Value...)).Convert(t), nil
+		}
+		var elements []std.GoMixObject
+		switch o := obj.(type) {
+		case *std.Array:
+			elements = o.Elements
+		case *std.List:
+			elements = o.Elements
+		case *std.Tuple:
+			elements = o.Elements
+		case *std.Set:
+			elements = o.Values()
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.GetType(), t)
+		}
+		res := reflect.MakeSlice(t, len(elements), len(elements))
+		for i, elem := range elements {
+			val, err := objectToType(elem, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			res.Index(i).Set(val)
+		}
+		return res, nil
+	case reflect.Map:
+		m, ok := obj.(*std.Map)
+		if !ok {
+			break
+		}
+		res := reflect.MakeMapWithSize(t, m.Len())
+		for _, pair := range m.Pairs {
+			key, err := mapKeyToType(pair.Key, t.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val, err := objectToType(pair.Value, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			res.SetMapIndex(key, val)
+		}
+		return res, nil
+	case reflect.Struct:
+		return objectToStruct(obj, t)
+	case reflect.Pointer:
+		if t.Elem().Kind() != reflect.Struct {
+			break
+		}
+		val, err := objectToStruct(obj, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(val)
+		return ptr, nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.GetType(), t)
+}
+
+// mapKeyToType converts a map key: any key converts to a string key (by its
+// string form), other keys convert like values.
+func mapKeyToType(key std.GoMixObject, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(key.ToString()).Convert(t), nil
+	}
+	val, err := objectToType(key, t)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("map key: %v", err)
+	}
+	return val, nil
+}
+
+// objectToStruct converts a map (such as one made by ToObject from a struct)
+// or a struct instance into a struct of type t, by field name (see ToObject
+// for the gomix tag). Fields the object does not have keep their zero value.
+func objectToStruct(obj std.GoMixObject, t reflect.Type) (reflect.Value, error) {
+	var lookUp func(name string) (std.GoMixObject, bool)
+	switch o := obj.(type) {
+	case *std.Map:
+		lookUp = o.GetString
+	case *std.GoMixObjectInstance:
+		lookUp = func(name string) (std.GoMixObject, bool) {
+			val, ok := o.InstanceFields[name]
+			return val, ok
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.GetType(), t)
+	}
+	res := reflect.New(t).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		val, found := lookUp(name)
+		if !found {
+			continue
+		}
+		fv, err := objectToType(val, t.Field(i).Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("field %s: %v", name, err)
+		}
+		res.Field(i).Set(fv)
+	}
+	return res, nil
+}
+
+// wrapFunc adapts a Go function into a builtin (see VM.RegisterFunc).
+func wrapFunc(name string, fn interface{}) (*std.Builtin, error) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return nil, fmt.Errorf("gomix: RegisterFunc(%s): expected a function, got %T", name, fn)
+	}
+	ft := fv.Type()
+	numIn := ft.NumIn()
+
+	callback := func(rt std.Runtime, writer io.Writer, args ...std.GoMixObject) (result std.GoMixObject) {
+		// a panicking host function fails the call instead of the whole program
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				result = &std.Error{Message: fmt.Sprintf("ERROR: %s: %v", name, recovered)}
+			}
+		}()
+
+		if ft.IsVariadic() {
+			if len(args) < numIn-1 {
+				return &std.Error{Message: fmt.Sprintf("ERROR: wrong number of arguments: expected at least %d, got %d", numIn-1, len(args))}
+			}
+		} else if len(args) != numIn {
+			return &std.Error{Message: fmt.Sprintf("ERROR: wrong number of arguments: expected %d, got %d", numIn, len(args))}
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			var paramType reflect.Type
+			if ft.IsVariadic() && i >= numIn-1 {
+				paramType = ft.In(numIn - 1).Elem()
+			} else {
+				paramType = ft.In(i)
+			}
+			val, err := objectToType(arg, paramType)
+			if err != nil {
+				return &std.Error{Message: fmt.Sprintf("ERROR: argument %d to `%s`: %v", i+1, name, err)}
+			}
+			in[i] = val
+		}
+		return resultsToObject(name, fv.Call(in))
+	}
+	return &std.Builtin{Name: name, Callback: callback}, nil
+}
+
+// resultsToObject converts the results of a registered function call.
+func resultsToObject(name string, out []reflect.Value) std.GoMixObject {
+	if n := len(out); n > 0 && out[n-1].Type() == errorType {
+		if !out[n-1].IsNil() {
+			return &std.Error{Message: fmt.Sprintf("ERROR: %s: %v", name, out[n-1].Interface())}
+		}
+		out = out[:n-1]
+	}
+	objs := make([]std.GoMixObject, len(out))
+	for i, val := range out {
+		obj, err := valueToObject(val)
+		if err != nil {
+			return &std.Error{Message: fmt.Sprintf("ERROR: result of `%s`: %v", name, err)}
+		}
+		objs[i] = obj
+	}
+	switch len(objs) {
+	case 0:
+		return &std.Nil{}
+	case 1:
+		return objs[0]
+	default:
+		return &std.Tuple{Elements: objs}
+	}
+}
@@ -0,0 +1,349 @@
+/*
+File    : go-mix/gomix/gomix.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package gomix is the embedding API of the Go-Mix interpreter.
+//
+// It wraps the parser and evaluator behind a small VM type so that Go
+// applications can run Go-Mix scripts, exchange values with them and expose
+// Go functions to them, without wiring the interpreter packages by hand.
+//
+// Example:
+//
+//	vm := gomix.NewVM()
+//	vm.Set("limit", 10)
+//	vm.RegisterFunc("double", func(x int) int { return 2 * x })
+//	if _, err := vm.RunString(`func area(w, h) { return w * h; }`); err != nil {
+//	    log.Fatal(err)
+//	}
+//	res, err := vm.Call("area", 3, 4) // res == int64(12)
+package gomix
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/eval"
+	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// VM is an embedded Go-Mix interpreter.
+//
+// All scripts run by the same VM share one global scope, so variables and
+// functions defined by RunString or RunFile stay visible to later runs and
+// to Get and Call. A VM is not safe for concurrent use.
+type VM struct {
+	ev *eval.Evaluator // Evaluator holding the global scope, builtins and packages
+}
+
+// Error is a Go-Mix runtime error returned to the host, either raised by
+// the interpreter or thrown (and not caught) by the script.
+//
+// Fields:
+//   - Message: The full error message, including the [line:column] prefix
+//   - Line, Column: Source position of the error (0 if unknown)
+//   - Value: The thrown value converted with FromObject, for errors raised
+//     by 'throw' (nil otherwise)
+//   - Trace: The script's call stack, innermost call first (nil if the error
+//     was raised outside any function; see std.Traceback for formatting)
+type Error struct {
+	Message string
+	Line    int
+	Column  int
+	Value   interface{}
+	Trace   []std.Frame
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// LimitError is returned when a script exceeds one of the VM's execution
+// limits (see SetLimits) or its context is canceled (see SetContext).
+//
+// Fields:
+//   - Limit: The limit that stopped the script (std.LimitSteps, std.LimitTimeout, ...)
+//   - Message: The full error message, including the [line:column] prefix
+//   - Line, Column: Source position where the script was stopped
+//   - Trace: The script's call stack when it was stopped (see Error)
+type LimitError struct {
+	Limit   string
+	Message string
+	Line    int
+	Column  int
+	Trace   []std.Frame
+}
+
+// Error returns the error message.
+func (e *LimitError) Error() string {
+	return e.Message
+}
+
+// ParseError reports the syntax errors of a script; nothing is executed
+// when a script fails to parse.
+type ParseError struct {
+	Errors []string // Every error collected by the parser, in source order
+}
+
+// Error returns all parse errors, one per line.
+func (e *ParseError) Error() string {
+	return strings.Join(e.Errors, "\n")
+}
+
+// NewVM creates an interpreter with a fresh global scope, all builtins and
+// standard packages registered, and output going to os.Stdout.
+//
+// Returns:
+//   - *VM: A VM ready to run scripts
+func NewVM() *VM {
+	ev := eval.NewEvaluator()
+	// Errors raised by Call before any script has run take their position from the parser
+	ev.SetParser(parser.NewParser(""))
+	return &VM{ev: ev}
+}
+
+// Evaluator returns the underlying evaluator, for hosts that need access
+// beyond this API (e.g., switching the execution backend with UseVM).
+func (vm *VM) Evaluator() *eval.Evaluator {
+	return vm.ev
+}
+
+// SetWriter redirects the output of print, println and friends.
+func (vm *VM) SetWriter(w io.Writer) {
+	vm.ev.SetWriter(w)
+}
+
+// SetReader sets the input source of the script's input builtins.
+func (vm *VM) SetReader(r io.Reader) {
+	vm.ev.SetReader(r)
+}
+
+// SetLimits configures the execution limits of the VM. Each RunString,
+// RunFile and Call runs under fresh limits; exceeding one returns a *LimitError.
+//
+// Example:
+//
+//	vm.SetLimits(eval.Limits{MaxSteps: 1_000_000, Timeout: time.Second})
+func (vm *VM) SetLimits(limits eval.Limits) {
+	vm.ev.SetLimits(limits)
+}
+
+// SetContext attaches a context to the VM: canceling it stops the running
+// script with a *LimitError.
+func (vm *VM) SetContext(ctx context.Context) {
+	vm.ev.SetContext(ctx)
+}
+
+// SetPermissions sets the capabilities granted to scripts. A new VM grants
+// every capability; builtins whose capability is not granted fail with an *Error.
+//
+// Example:
+//
+//	perms := std.Restricted()             // no file, process, env or network access
+//	perms.Grant(std.CapFSRead, "./data")  // except reading files below ./data
+//	vm.SetPermissions(perms)
+func (vm *VM) SetPermissions(perms *std.Permissions) {
+	vm.ev.SetPermissions(perms)
+}
+
+// RunString parses and executes Go-Mix source code in the VM's global scope.
+//
+// Parameters:
+//   - src: The Go-Mix source code
+//
+// Returns:
+//   - interface{}: The value of the last statement, converted with FromObject
+//   - error: A *ParseError for syntax errors, an *Error for runtime errors,
+//     or a *LimitError when an execution limit is exceeded
+//
+// Example:
+//
+//	res, err := vm.RunString("var x = 20; x + 22") // res == int64(42)
+func (vm *VM) RunString(src string) (result interface{}, err error) {
+	defer recoverPanic(&err)
+
+	par := parser.NewParser(src)
+	root := par.Parse()
+	if par.HasErrors() {
+		return nil, &ParseError{Errors: par.GetErrors()}
+	}
+	if root == nil {
+		return nil, &ParseError{Errors: []string{"invalid syntax"}}
+	}
+	vm.ev.SetParser(par)
+	return vm.result(vm.ev.Eval(root))
+}
+
+// RunFile reads and executes a Go-Mix source file. Module imports in the
+// file are resolved relative to its directory.
+//
+// Parameters:
+//   - path: Path of the .gm file
+//
+// Returns:
+//   - interface{}: The value of the last statement, converted with FromObject
+//   - error: A file read error, a *ParseError, an *Error or a *LimitError
+func (vm *VM) RunFile(path string) (interface{}, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vm.ev.SetSourceFile(path)
+	return vm.RunString(string(src))
+}
+
+// Set binds a Go value to a global variable, converting it with ToObject.
+// An existing variable of the same name is overwritten, unless it is a constant.
+//
+// Parameters:
+//   - name: The variable name
+//   - value: Any value accepted by ToObject
+//
+// Returns:
+//   - error: A conversion error, or an error if name is a constant
+//
+// Example:
+//
+//	vm.Set("config", map[string]interface{}{"debug": true})
+func (vm *VM) Set(name string, value interface{}) error {
+	obj, err := ToObject(value)
+	if err != nil {
+		return err
+	}
+	if vm.ev.Scp.IsConstant(name) {
+		return fmt.Errorf("gomix: cannot assign to constant: (%s)", name)
+	}
+	vm.ev.Scp.Bind(name, obj)
+	return nil
+}
+
+// Get returns the value of a global variable, converted with FromObject.
+//
+// Parameters:
+//   - name: The variable name
+//
+// Returns:
+//   - interface{}: The converted value
+//   - error: An error if the variable is not defined
+func (vm *VM) Get(name string) (interface{}, error) {
+	obj, ok := vm.ev.Scp.LookUp(name)
+	if !ok {
+		return nil, fmt.Errorf("gomix: variable not found: (%s)", name)
+	}
+	return FromObject(obj), nil
+}
+
+// RegisterFunc exposes a Go function to scripts as a builtin named name.
+//
+// Arguments are converted from Go-Mix objects to the function's parameter
+// types (see FromObject), and variadic functions are supported. The results
+// are converted back with ToObject: no result gives nil, one result is
+// returned as is and several results are returned as a tuple. A trailing
+// error result is not returned to the script; when it is non-nil the call
+// fails with its message, prefixed with the [line:column] of the call in the
+// returned *Error. Parameters of type std.GoMixObject receive the script's
+// objects unconverted.
+//
+// Parameters:
+//   - name: The builtin name used by scripts
+//   - fn: A Go function
+//
+// Returns:
+//   - error: An error if fn is not a function
+//
+// Example:
+//
+//	vm.RegisterFunc("greet", func(name string) string { return "hi " + name })
+//	vm.RunString(`println(greet("go"))`) // hi go
+func (vm *VM) RegisterFunc(name string, fn interface{}) error {
+	builtin, err := wrapFunc(name, fn)
+	if err != nil {
+		return err
+	}
+	vm.ev.Builtins[name] = builtin
+	return nil
+}
+
+// Call calls a Go-Mix function defined in the global scope, or a builtin,
+// with Go arguments converted by ToObject.
+//
+// Parameters:
+//   - fnName: The name of the function
+//   - args: The arguments
+//
+// Returns:
+//   - interface{}: The function's return value, converted with FromObject
+//   - error: A conversion error, an error if fnName is not a function,
+//     or an *Error or *LimitError raised by the call
+//
+// Example:
+//
+//	vm.RunString("func add(a, b) { return a + b; }")
+//	sum, err := vm.Call("add", 1, 2) // sum == int64(3)
+func (vm *VM) Call(fnName string, args ...interface{}) (result interface{}, err error) {
+	defer recoverPanic(&err)
+
+	objs := make([]std.GoMixObject, len(args))
+	for i, arg := range args {
+		obj, err := ToObject(arg)
+		if err != nil {
+			return nil, fmt.Errorf("gomix: argument %d: %v", i+1, err)
+		}
+		objs[i] = obj
+	}
+
+	// builtins take precedence, as in call expressions
+	if vm.ev.IsBuiltin(fnName) {
+		return vm.result(vm.ev.RunLimited(func() std.GoMixObject {
+			return vm.ev.InvokeBuiltin(fnName, objs...)
+		}))
+	}
+	fn, ok := vm.ev.Scp.LookUp(fnName)
+	if !ok {
+		return nil, fmt.Errorf("gomix: function not found: (%s)", fnName)
+	}
+	if fn.GetType() != std.FunctionType {
+		return nil, fmt.Errorf("gomix: not a function: (%s)", fnName)
+	}
+	return vm.result(vm.ev.RunLimited(func() std.GoMixObject {
+		return vm.ev.CallFunction(fn, objs...)
+	}))
+}
+
+// result converts the outcome of an evaluation into a Go value and error.
+func (vm *VM) result(obj std.GoMixObject) (interface{}, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	if limitErr, ok := obj.(*std.LimitError); ok {
+		return nil, &LimitError{Limit: limitErr.Limit, Message: limitErr.Message, Line: limitErr.Line, Column: limitErr.Column, Trace: limitErr.Trace}
+	}
+	if errObj, ok := obj.(*std.Error); ok {
+		message := errObj.Message
+		// builtins (including registered functions) leave the prefix to the caller
+		if errObj.Line > 0 && !strings.HasPrefix(message, "[") {
+			message = fmt.Sprintf("[%d:%d] %s", errObj.Line, errObj.Column, message)
+		}
+		err := &Error{Message: message, Line: errObj.Line, Column: errObj.Column, Trace: errObj.Trace}
+		if errObj.Value != nil {
+			err.Value = FromObject(errObj.Value)
+		}
+		return nil, err
+	}
+	return FromObject(obj), nil
+}
+
+// recoverPanic turns an interpreter panic into an error, so that a faulty
+// script cannot crash its host.
+func recoverPanic(err *error) {
+	if recovered := recover(); recovered != nil {
+		*err = &Error{Message: fmt.Sprintf("[RUNTIME ERROR] %v", recovered)}
+	}
+}
@@ -0,0 +1,497 @@
+/*
+File    : go-mix/gomix/gomix_test.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package gomix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akashmaji946/go-mix/eval"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// TestVM_RunString verifies that scripts run in a shared global scope and return their last value
+func TestVM_RunString(t *testing.T) {
+	vm := NewVM()
+	if _, err := vm.RunString("var x = 20;"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := vm.RunString("x + 22")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != int64(42) {
+		t.Errorf("expected 42, got %v", res)
+	}
+}
+
+// TestVM_Errors verifies that parse and runtime errors are returned as typed Go errors
+func TestVM_Errors(t *testing.T) {
+	vm := NewVM()
+
+	_, err := vm.RunString("var = ;")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || len(parseErr.Errors) == 0 {
+		t.Errorf("expected a *ParseError, got %v", err)
+	}
+
+	_, err = vm.RunString("var a = 1;\nvar b = a + undefined_name;")
+	var runErr *Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an *Error, got %v", err)
+	}
+	if runErr.Line != 2 || !strings.Contains(runErr.Message, "undefined_name") {
+		t.Errorf("unexpected error: %+v", runErr)
+	}
+
+	_, err = vm.RunString(`throw "boom";`)
+	if !errors.As(err, &runErr) || runErr.Value != "boom" {
+		t.Errorf("expected thrown value 'boom', got %v", err)
+	}
+}
+
+// TestVM_SetGet verifies conversion of Go values into and out of the VM
+func TestVM_SetGet(t *testing.T) {
+	type point struct {
+		X, Y   int
+		Label  string `gomix:"label"`
+		hidden bool
+	}
+
+	vm := NewVM()
+	values := map[string]interface{}{
+		"n":     7,
+		"f":     1.5,
+		"s":     "go",
+		"b":     true,
+		"arr":   []int{1, 2, 3},
+		"m":     map[string]float64{"pi": 3.14},
+		"ids":   map[int]string{2: "b", 1: "a"},
+		"p":     &point{X: 1, Y: 2, Label: "origin"},
+		"empty": nil,
+		"huge":  uint64(math.MaxUint64),
+		"big":   new(big.Int).Lsh(big.NewInt(1), 70),
+		"data":  []byte{0xCA, 0xFE},
+	}
+	for name, value := range values {
+		if err := vm.Set(name, value); err != nil {
+			t.Fatalf("Set(%s): %v", name, err)
+		}
+	}
+
+	res, err := vm.RunString(`n * 2 + length(arr) + p["X"] + p["Y"]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != int64(20) {
+		t.Errorf("expected 20, got %v", res)
+	}
+
+	// Go map keys keep their types
+	res, err = vm.RunString(`ids[1] + typeof(keys_map(ids)[0]) + to_string(ids["1"])`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != "aintnil" {
+		t.Errorf("expected aintnil, got %v", res)
+	}
+
+	// Bigints and decimals convert to *big.Int and *big.Rat
+	res, err = vm.RunString(`typeof(huge) + to_string(big + 1)`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != "bigint1180591620717411303425" {
+		t.Errorf("expected bigint1180591620717411303425, got %v", res)
+	}
+	if res, _ := vm.RunString(`big * 2`); res.(*big.Int).String() != "2361183241434822606848" {
+		t.Errorf("expected *big.Int 2361183241434822606848, got %v", res)
+	}
+	if res, _ := vm.RunString(`decimal("0.25")`); res.(*big.Rat).String() != "1/4" {
+		t.Errorf("expected *big.Rat 1/4, got %v", res)
+	}
+
+	// []byte converts to bytes and back
+	res, err = vm.RunString(`typeof(data) + to_string(data[0])`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != "bytes202" {
+		t.Errorf("expected bytes202, got %v", res)
+	}
+
+	tests := []struct {
+		name     string
+		expected interface{}
+	}{
+		{"n", int64(7)},
+		{"data", []byte{0xCA, 0xFE}},
+		{"f", 1.5},
+		{"s", "go"},
+		{"b", true},
+		{"arr", []interface{}{int64(1), int64(2), int64(3)}},
+		{"m", map[string]interface{}{"pi": 3.14}},
+		{"ids", map[interface{}]interface{}{int64(1): "a", int64(2): "b"}},
+		{"p", map[string]interface{}{"X": int64(1), "Y": int64(2), "label": "origin"}},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		got, err := vm.Get(tt.name)
+		if err != nil {
+			t.Errorf("Get(%s): %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Get(%s): expected %#v, got %#v", tt.name, tt.expected, got)
+		}
+	}
+
+	// keys keep their types; keys Go cannot compare stay Go-Mix objects
+	if _, err := vm.RunString(`var keyed = map{1: "i", 'c': "c", tuple(1, 2): "t"};`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := vm.Get("keyed")
+	keyed, ok := got.(map[interface{}]interface{})
+	if !ok || len(keyed) != 3 || keyed[int64(1)] != "i" || keyed['c'] != "c" {
+		t.Errorf("Get(keyed): unexpected %#v", got)
+	}
+	for key, val := range keyed {
+		if tuple, ok := key.(*std.Tuple); ok && (val != "t" || tuple.ToString() != "tuple(1, 2)") {
+			t.Errorf("Get(keyed): unexpected tuple key %s: %v", tuple.ToString(), val)
+		}
+	}
+
+	if _, err := vm.Get("missing"); err == nil {
+		t.Errorf("expected an error for an undefined variable")
+	}
+	if err := vm.Set("ch", make(chan int)); err == nil {
+		t.Errorf("expected an error for an unsupported type")
+	}
+	if _, err := vm.RunString("const LIMIT = 1;"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := vm.Set("LIMIT", 2); err == nil {
+		t.Errorf("expected an error when setting a constant")
+	}
+}
+
+// TestVM_RegisterFunc verifies argument and result conversion for Go functions called by scripts
+func TestVM_RegisterFunc(t *testing.T) {
+	var out bytes.Buffer
+	vm := NewVM()
+	vm.SetWriter(&out)
+
+	funcs := map[string]interface{}{
+		"add":   func(a, b int) int { return a + b },
+		"scale": func(x float64, by int32) float64 { return x * float64(by) },
+		"join":  func(sep string, parts ...string) string { return strings.Join(parts, sep) },
+		"sum": func(xs []int64) (total int64) {
+			for _, x := range xs {
+				total += x
+			}
+			return
+		},
+		"split": func(s string) (string, string) { return s[:1], s[1:] },
+		"fail":  func(msg string) (int, error) { return 0, errors.New(msg) },
+		"kind":  func(obj std.GoMixObject) string { return string(obj.GetType()) },
+		"log":   func(v interface{}) { out.WriteString("log:" + reflect.TypeOf(v).String() + "\n") },
+		"twice": func(n *big.Int) *big.Int { return new(big.Int).Lsh(n, 1) },
+		"half":  func(r *big.Rat) *big.Rat { return new(big.Rat).Quo(r, big.NewRat(2, 1)) },
+		"third": func(r *big.Rat) *big.Rat { return new(big.Rat).Quo(r, big.NewRat(3, 1)) },
+	}
+	for name, fn := range funcs {
+		if err := vm.RegisterFunc(name, fn); err != nil {
+			t.Fatalf("RegisterFunc(%s): %v", name, err)
+		}
+	}
+	if err := vm.RegisterFunc("bad", 42); err == nil {
+		t.Errorf("expected an error when registering a non-function")
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"add(2, 3)", int64(5)},
+		{"scale(1.5, 4)", 6.0},
+		{"scale(2, 3)", 6.0},
+		{`join("-", "a", "b", "c")`, "a-b-c"},
+		{`join(",")`, ""},
+		{"sum([1, 2, 3, 4])", int64(10)},
+		{`split("go")`, []interface{}{"g", "o"}},
+		{"kind([1])", "array"},
+		{"func sq(x) { return x * x; } add(sq(3), 1)", int64(10)},
+		{"to_string(twice(9223372036854775807))", "18446744073709551614"},
+		{"to_string(twice(5))", "10"},
+		{`typeof(half(decimal("0.25"))) + to_string(half(decimal("0.25")))`, "decimal0.125"},
+		{"to_string(half(3))", "1.5"},
+	}
+	for _, tt := range tests {
+		res, err := vm.RunString(tt.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(res, tt.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tt.input, tt.expected, res)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`fail("disk full")`, "ERROR: fail: disk full"},
+		{"add(1)", "ERROR: wrong number of arguments: expected 2, got 1"},
+		{`add(1, "x")`, "ERROR: argument 2 to `add`: cannot use string as int"},
+		{"scale(1.0, 5000000000)", "overflows int32"},
+		{"third(1)", "cannot convert 1/3 to a decimal exactly"},
+		{`twice("x")`, "cannot use string as *big.Int"},
+	}
+	for _, tt := range errorTests {
+		_, err := vm.RunString(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+
+	// Errors of registered functions carry the position of the call
+	_, err := vm.RunString("var x = 1;\nvar y = fail(\"disk full\");")
+	var runErr *Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an *Error, got %v", err)
+	}
+	prefix := fmt.Sprintf("[%d:%d] ERROR: fail: disk full", runErr.Line, runErr.Column)
+	if runErr.Line != 2 || runErr.Message != prefix {
+		t.Errorf("expected a positioned error on line 2, got %+v", runErr)
+	}
+
+	if _, err := vm.RunString(`log(1); log("s"); log([1])`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "log:int64\nlog:string\nlog:[]interface {}\n"
+	if out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+}
+
+// TestVM_RegisterFuncStructs verifies that maps and struct instances convert
+// to struct parameters, and maps to maps with typed keys
+func TestVM_RegisterFuncStructs(t *testing.T) {
+	type point struct {
+		X, Y  int
+		Label string `gomix:"label"`
+	}
+	vm := NewVM()
+	funcs := map[string]interface{}{
+		"norm1": func(p point) int { return p.X + p.Y },
+		"tag":   func(p *point) string { return p.Label },
+		"move":  func(p point, dx int) point { p.X += dx; return p },
+		"pick":  func(m map[int]string, k int) string { return m[k] },
+		"count": func(m map[rune]int) int { return len(m) },
+	}
+	for name, fn := range funcs {
+		if err := vm.RegisterFunc(name, fn); err != nil {
+			t.Fatalf("RegisterFunc(%s): %v", name, err)
+		}
+	}
+	if err := vm.Set("p", point{X: 3, Y: 4, Label: "a"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"norm1(p)", int64(7)},
+		{"tag(p)", "a"},
+		{`norm1(map{"X": 1})`, int64(1)},
+		{`norm1(move(p, 10))`, int64(17)},
+		{`struct Pt { var X = 0; var Y = 0; func init(x, y) { this.X = x; this.Y = y; } } norm1(new Pt(5, 6))`, int64(11)},
+		{`pick(map{1: "one", 2: "two"}, 2)`, "two"},
+		{`count(map{'a': 1, 'b': 2})`, int64(2)},
+	}
+	for _, tt := range tests {
+		res, err := vm.RunString(tt.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(res, tt.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tt.input, tt.expected, res)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`norm1(map{"X": "x"})`, "field X: cannot use string as int"},
+		{`pick(map{"a": "one"}, 1)`, "map key: cannot use string as int"},
+		{"norm1(5)", "cannot use int as gomix.point"},
+	}
+	for _, tt := range errorTests {
+		_, err := vm.RunString(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+// TestVM_Call verifies calling script functions and builtins from Go
+func TestVM_Call(t *testing.T) {
+	vm := NewVM()
+	if _, err := vm.RunString(`
+		func area(w, h) { return w * h; }
+		func greet(names) {
+			var out = "";
+			foreach name in names { out = out + "hi " + name + ";"; }
+			return out;
+		}
+		var notFunc = 1;
+	`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := vm.Call("area", 3, 4)
+	if err != nil || res != int64(12) {
+		t.Errorf("area: expected 12, got %v (%v)", res, err)
+	}
+	res, err = vm.Call("greet", []string{"a", "b"})
+	if err != nil || res != "hi a;hi b;" {
+		t.Errorf("greet: unexpected result %v (%v)", res, err)
+	}
+	res, err = vm.Call("length", []int{1, 2, 3})
+	if err != nil || res != int64(3) {
+		t.Errorf("length: expected 3, got %v (%v)", res, err)
+	}
+
+	if _, err := vm.Call("area", 1); err == nil {
+		t.Errorf("expected a wrong number of arguments error")
+	}
+	if _, err := vm.Call("missing"); err == nil {
+		t.Errorf("expected an error for a missing function")
+	}
+	if _, err := vm.Call("notFunc"); err == nil {
+		t.Errorf("expected an error for a non-function")
+	}
+}
+
+// TestVM_RunFile verifies running a file whose module imports resolve relative to it
+func TestVM_RunFile(t *testing.T) {
+	dir := t.TempDir()
+	lib := "func twice(x) { return 2 * x; }"
+	main := `import "lib.gm"; var answer = lib.twice(21);`
+	if err := os.WriteFile(filepath.Join(dir, "lib.gm"), []byte(lib), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.gm"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vm := NewVM()
+	if _, err := vm.RunFile(filepath.Join(dir, "main.gm")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res, _ := vm.Get("answer"); res != int64(42) {
+		t.Errorf("expected 42, got %v", res)
+	}
+	if _, err := vm.RunFile(filepath.Join(dir, "missing.gm")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+// TestVM_Limits verifies that runaway scripts and calls stop with a *LimitError
+func TestVM_Limits(t *testing.T) {
+	vm := NewVM()
+	vm.SetLimits(eval.Limits{MaxSteps: 10000, Timeout: time.Second})
+
+	var limitErr *LimitError
+	_, err := vm.RunString("while (true) {}")
+	if !errors.As(err, &limitErr) || limitErr.Limit != std.LimitSteps {
+		t.Errorf("expected a step LimitError, got %v", err)
+	}
+
+	if _, err := vm.RunString("func spin() { while (true) {} } func ok() { return 1; }"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = vm.Call("spin")
+	if !errors.As(err, &limitErr) {
+		t.Errorf("expected a LimitError from Call, got %v", err)
+	}
+	if res, err := vm.Call("ok"); err != nil || res != int64(1) {
+		t.Errorf("expected 1 after a failed call, got %v (%v)", res, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	vm.SetLimits(eval.Limits{})
+	vm.SetContext(ctx)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = vm.RunString("while (true) {}")
+	if !errors.As(err, &limitErr) || limitErr.Limit != std.LimitCanceled {
+		t.Errorf("expected a canceled LimitError, got %v", err)
+	}
+}
+
+// TestVM_Permissions verifies that a restricted VM denies host access to scripts
+func TestVM_Permissions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "in.txt"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vm := NewVM()
+	perms := std.Restricted()
+	perms.Grant(std.CapFSRead, dir)
+	vm.SetPermissions(perms)
+	vm.Set("dir", dir)
+
+	res, err := vm.RunString(`read_file(dir + "/in.txt")`)
+	if err != nil || res != "data" {
+		t.Errorf("expected data, got %v (%v)", res, err)
+	}
+	var runErr *Error
+	_, err = vm.RunString(`write_file(dir + "/out.txt", "x")`)
+	if !errors.As(err, &runErr) || !strings.Contains(runErr.Message, "permission denied") {
+		t.Errorf("expected a permission error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.txt")); err == nil {
+		t.Errorf("denied write_file created the file")
+	}
+	if _, err := vm.Call("getenv", "HOME"); err == nil {
+		t.Errorf("expected a permission error from Call")
+	}
+}
+
+// TestVM_ErrorTrace verifies that runtime errors returned to the host carry the script's call stack
+func TestVM_ErrorTrace(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.RunString("func check(x) {\n    if (x < 0) { throw \"negative\"; }\n    return x;\n}\nfunc run() { return check(-1); }")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = vm.Call("run")
+	var runErr *Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an *Error, got %v", err)
+	}
+	var names []string
+	for _, frame := range runErr.Trace {
+		names = append(names, frame.Function)
+	}
+	if strings.Join(names, ",") != "check,run,<main>" {
+		t.Errorf("unexpected trace: %v", runErr.Trace)
+	}
+}
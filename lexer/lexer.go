@@ -26,13 +26,17 @@ package lexer
 //   - SrcLength: The total length of the source string
 //   - Line: The current line number in the source (1-indexed)
 //   - Column: The current column number in the source (1-indexed)
+//   - Comments: The comments skipped so far, in source order
+//   - Errors: The errors found in malformed tokens so far, in source order
 type Lexer struct {
-	Src       string // Entire source code in plain text format
-	Current   byte   // Current character being examined
-	Position  int    // Current position of pointer in the source code
-	SrcLength int    // Length of source string
-	Line      int    // Line number in source (1-indexed)
-	Column    int    // Column number in source (1-indexed)
+	Src       string    // Entire source code in plain text format
+	Current   byte      // Current character being examined
+	Position  int       // Current position of pointer in the source code
+	SrcLength int       // Length of source string
+	Line      int       // Line number in source (1-indexed)
+	Column    int       // Column number in source (1-indexed)
+	Comments  []Comment // Comments skipped between tokens (kept as trivia)
+	Errors    []string  // Errors of the malformed tokens read (e.g., an invalid escape sequence)
 }
 
 // NewLexer creates and initializes a new Lexer for the given source code.
@@ -73,7 +77,8 @@ func NewLexer(src string) Lexer {
 //   - Compound assignment operators (+=, -=, *=, etc.)
 //   - Structural symbols (parentheses, braces, brackets)
 //   - Delimiters (comma, semicolon, colon)
-//   - String literals (with escape sequence support)
+//   - String literals (with escape sequence support and ${...} interpolation)
+//   - Raw string literals (in backticks)
 //   - Numeric literals (integers and floats)
 //   - Identifiers and keywords
 //
@@ -93,8 +98,11 @@ func (lex *Lexer) NextToken() Token {
 	// Match the current character to determine token type
 	switch lex.Current {
 	case '=':
-		// Could be '=' (assignment) or '==' (equality)
-		if lex.Peek() == '=' {
+		// Could be '=' (assignment), '==' (equality) or '=>' (match arm)
+		if lex.Peek() == '>' {
+			lex.Advance()
+			token = NewTokenWithMetadata(ARROW_DELIM, "=>", lex.Line, lex.Column)
+		} else if lex.Peek() == '=' {
 			lex.Advance()
 			if lex.Peek() == '=' {
 				lex.Advance()
@@ -266,6 +274,9 @@ func (lex *Lexer) NextToken() Token {
 	case '"':
 		// String literal - delegate to specialized handler
 		return readStringLiteral(lex)
+	case '`':
+		// Raw string literal - delegate to specialized handler
+		return readRawString(lex)
 	case '\'':
 		// Character literal - delegate to specialized handler
 		return readCharLiteral(lex)
@@ -364,12 +375,14 @@ func (lex *Lexer) IgnoreWhitespacesAndComments() {
 // SkipSingleLineComment skips over a single-line comment (// ...).
 // It advances the lexer until a newline or end of file is reached.
 // The newline itself is not consumed, allowing line tracking to work correctly.
+// The comment is recorded in Comments.
 //
 // Example:
 //
 //	Source: "// this is a comment\nvar x"
 //	After skip: lexer is positioned at '\n'
 func (lex *Lexer) SkipSingleLineComment() {
+	start, column := lex.Position, lex.Column
 	// Skip the '//' characters
 	lex.Advance()
 	lex.Advance()
@@ -378,17 +391,22 @@ func (lex *Lexer) SkipSingleLineComment() {
 	for lex.Current != '\n' && lex.Current != 0 {
 		lex.Advance()
 	}
+	lex.Comments = append(lex.Comments, Comment{
+		Text: lex.Src[start:lex.Position], Line: lex.Line, Column: column, EndLine: lex.Line, Trailing: lex.followsCode(start),
+	})
 }
 
 // SkipMultiLineComment skips over a multi-line comment (/* ... */).
 // It advances the lexer until the closing */ is found or end of file is reached.
 // Line numbers are tracked correctly even within multi-line comments.
+// The comment is recorded in Comments.
 //
 // Example:
 //
 //	Source: "/* comment\nspanning lines */var x"
 //	After skip: lexer is positioned after '*/'
 func (lex *Lexer) SkipMultiLineComment() {
+	start, line, column := lex.Position, lex.Line, lex.Column
 	// Skip the '/*' characters
 	lex.Advance()
 	lex.Advance()
@@ -401,8 +419,26 @@ func (lex *Lexer) SkipMultiLineComment() {
 			lex.Advance()
 			break
 		}
+		if lex.Current == '\n' {
+			lex.Line++
+			lex.Column = 1
+		}
 		lex.Advance()
 	}
+	lex.Comments = append(lex.Comments, Comment{
+		Text: lex.Src[start:lex.Position], Line: line, Column: column, EndLine: lex.Line, Trailing: lex.followsCode(start),
+	})
+}
+
+// followsCode reports whether anything but whitespace precedes the given
+// position on its line, i.e. whether a comment starting there trails code.
+func (lex *Lexer) followsCode(position int) bool {
+	for i := position - 1; i >= 0 && lex.Src[i] != '\n'; i-- {
+		if !isWhitespace(lex.Src[i]) {
+			return true
+		}
+	}
+	return false
 }
 
 // ConsumeTokens tokenizes the entire source code and returns all tokens.
@@ -201,6 +201,18 @@ func TestNewLexer_ConsumeTokens(t *testing.T) {
 				NewToken(FLOAT_LIT, "12E-2"),
 			},
 		},
+		// Bigint literals end in n; a following identifier is not a suffix
+		{
+			Input: `123n 0xFFn 1.5 n 7 nums`,
+			ExpectedTokens: []Token{
+				NewToken(INT_LIT, "123n"),
+				NewToken(INT_LIT, "0xFFn"),
+				NewToken(FLOAT_LIT, "1.5"),
+				NewToken(IDENTIFIER_ID, "n"),
+				NewToken(INT_LIT, "7"),
+				NewToken(IDENTIFIER_ID, "nums"),
+			},
+		},
 		// Test escape sequences in string literals
 		{
 			Input: `"hello\nworld"`,
@@ -253,6 +265,49 @@ func TestNewLexer_ConsumeTokens(t *testing.T) {
 				NewToken(NIL_LIT, "nil"),
 			},
 		},
+		{
+			Input: `try catch finally throw trying`,
+			ExpectedTokens: []Token{
+				NewToken(TRY_KEY, "try"),
+				NewToken(CATCH_KEY, "catch"),
+				NewToken(FINALLY_KEY, "finally"),
+				NewToken(THROW_KEY, "throw"),
+				NewToken(IDENTIFIER_ID, "trying"),
+			},
+		},
+		{
+			Input: `yield yielded`,
+			ExpectedTokens: []Token{
+				NewToken(YIELD_KEY, "yield"),
+				NewToken(IDENTIFIER_ID, "yielded"),
+			},
+		},
+		{
+			Input: `spawn spawned`,
+			ExpectedTokens: []Token{
+				NewToken(SPAWN_KEY, "spawn"),
+				NewToken(IDENTIFIER_ID, "spawned"),
+			},
+		},
+		{
+			Input: `match (x) { 1 => a, _ => b } matches`,
+			ExpectedTokens: []Token{
+				NewToken(MATCH_KEY, "match"),
+				NewToken(LEFT_PAREN, "("),
+				NewToken(IDENTIFIER_ID, "x"),
+				NewToken(RIGHT_PAREN, ")"),
+				NewToken(LEFT_BRACE, "{"),
+				NewToken(INT_LIT, "1"),
+				NewToken(ARROW_DELIM, "=>"),
+				NewToken(IDENTIFIER_ID, "a"),
+				NewToken(COMMA_DELIM, ","),
+				NewToken(IDENTIFIER_ID, "_"),
+				NewToken(ARROW_DELIM, "=>"),
+				NewToken(IDENTIFIER_ID, "b"),
+				NewToken(RIGHT_BRACE, "}"),
+				NewToken(IDENTIFIER_ID, "matches"),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -299,3 +354,96 @@ func TestNewLexer_Import(t *testing.T) {
 		}
 	}
 }
+
+// string literal tests: escapes, raw strings and interpolation
+func TestNewLexer_Strings(t *testing.T) {
+	tests := []TestConsumeToken{
+		{
+			Input:          `"caf\u{e9} \x41\u{1F600} \${x}"`,
+			ExpectedTokens: []Token{NewToken(STRING_LIT, "café A😀 ${x}")},
+		},
+		{
+			Input:          "`C:\\new\n${x}`",
+			ExpectedTokens: []Token{NewToken(RAW_STRING_LIT, "C:\\new\n${x}")},
+		},
+		{
+			Input:          `'\u{e9}' 'é' '\x41'`,
+			ExpectedTokens: []Token{NewToken(CHAR_LIT, "é"), NewToken(CHAR_LIT, "é"), NewToken(CHAR_LIT, "A")},
+		},
+		{
+			Input:          `"Hi ${user.name}, ${f("}")}!" + 1`,
+			ExpectedTokens: []Token{NewToken(INTERP_STRING_LIT, `Hi ${user.name}, ${f("}")}!`), NewToken(PLUS_OP, "+"), NewToken(INT_LIT, "1")},
+		},
+		{
+			Input:          `"${x" ` + "`abc",
+			ExpectedTokens: []Token{NewToken(INVALID_TYPE, "")},
+		},
+	}
+	for _, test := range tests {
+		lex := NewLexer(test.Input)
+		gotTokens := lex.ConsumeTokens()
+		assert.GreaterOrEqual(t, len(gotTokens), len(test.ExpectedTokens), test.Input)
+		for i, token := range test.ExpectedTokens {
+			assert.Equal(t, token.Type, gotTokens[i].Type, test.Input)
+			assert.Equal(t, token.Literal, gotTokens[i].Literal, test.Input)
+		}
+	}
+
+	// Invalid escape sequences are reported with their position, and the
+	// literal is read to its end
+	escapes := []struct {
+		input    string
+		expected []string
+	}{
+		{`"a\qb" 1`, []string{"[1:3] PARSER ERROR: invalid escape sequence: \\q"}},
+		{`"\u{110000}" "\xZ1"`, []string{
+			"[1:2] PARSER ERROR: invalid escape sequence: \\u{110000}",
+			"[1:15] PARSER ERROR: invalid escape sequence: \\xZ",
+		}},
+		{`'\w' "\u{zz}"`, []string{
+			"[1:2] PARSER ERROR: invalid escape sequence: \\w",
+			"[1:7] PARSER ERROR: invalid escape sequence: \\u{z",
+		}},
+		{`"${f("\q")}"`, nil},
+	}
+	for _, test := range escapes {
+		lex := NewLexer(test.input)
+		tokens := lex.ConsumeTokens()
+		if len(test.expected) == 0 {
+			assert.Empty(t, lex.Errors, test.input)
+		} else {
+			assert.Equal(t, test.expected, lex.Errors, test.input)
+		}
+		for _, token := range tokens {
+			assert.NotEqual(t, INVALID_TYPE, token.Type, test.input)
+		}
+	}
+
+	// The parts of an interpolated string, positioned in the source
+	lex := NewLexer("x = \"a\\t${b + 1}${c}\";")
+	lex.NextToken()
+	lex.NextToken()
+	tok := lex.NextToken()
+	assert.Equal(t, INTERP_STRING_LIT, tok.Type)
+	assert.Equal(t, []StringPart{
+		{Text: "a\t", Line: 1, Column: 6},
+		{Text: "b + 1", IsExpr: true, Line: 1, Column: 11},
+		{Text: "c", IsExpr: true, Line: 1, Column: 19},
+	}, SplitInterpolatedString(tok))
+}
+
+// comment trivia tests
+func TestNewLexer_Comments(t *testing.T) {
+	input := "// header\nvar x = 1; // trailing\n/* block\n   spanning lines */\nvar y = 2;"
+	lex := NewLexer(input)
+	tokens := lex.ConsumeTokens()
+
+	assert.Equal(t, 10, len(tokens))
+	assert.Equal(t, []Comment{
+		{Text: "// header", Line: 1, Column: 1, EndLine: 1},
+		{Text: "// trailing", Line: 2, Column: 13, EndLine: 2, Trailing: true},
+		{Text: "/* block\n   spanning lines */", Line: 3, Column: 2, EndLine: 4},
+	}, lex.Comments)
+	// lines after a multi-line comment are still counted correctly
+	assert.Equal(t, 5, tokens[5].Line)
+}
@@ -7,8 +7,10 @@ package lexer
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // isDigitASCII reports whether c is an ASCII decimal digit ('0'..'9').
@@ -105,12 +107,19 @@ func isSpecial(c byte) bool {
 //   - \": double quote
 //   - \': single quote
 //   - \0: null character
+//   - \$: dollar sign (\${ is not interpolated)
+//   - \xHH: the byte with hexadecimal value HH
+//   - \u{H...}: the UTF-8 encoding of the Unicode code point H... (1 to 6 hex digits)
+//
+// A string containing ${expression} is an interpolated string: the token
+// keeps its source and SplitInterpolatedString splits it into parts.
 //
 // Parameters:
 //   - lex: Pointer to the lexer instance
 //
 // Returns:
-//   - Token: A STRING_LIT token with the string content, or INVALID_TYPE on error
+//   - Token: A STRING_LIT token with the string content, an INTERP_STRING_LIT
+//     token with the source between the quotes, or INVALID_TYPE on error
 //
 // Example:
 //
@@ -119,77 +128,291 @@ func isSpecial(c byte) bool {
 func readStringLiteral(lex *Lexer) Token {
 	if lex.Current != '"' {
 		// Error: expected opening quote
-		fmt.Errorf("[%d:%d] LEXER ERROR: malformed string literal", lex.Line, lex.Column)
 		return NewTokenWithMetadata(INVALID_TYPE, "", lex.Line, lex.Column)
 	}
+	line, column, start := lex.Line, lex.Column, lex.Position
+
+	parts, ok := readStringParts(lex)
+	if !ok {
+		return NewTokenWithMetadata(INVALID_TYPE, "", line, column)
+	}
+	if len(parts) == 1 && !parts[0].IsExpr {
+		return NewTokenWithMetadata(STRING_LIT, parts[0].Text, lex.Line, lex.Column)
+	}
+	// An interpolated string is positioned at its opening quote, where
+	// SplitInterpolatedString starts reading it again
+	return NewTokenWithMetadata(INTERP_STRING_LIT, lex.Src[start+1:lex.Position-1], line, column)
+}
+
+// readStringParts reads a string literal, from its opening quote up to and
+// including its closing one, into its text and ${expression} parts.
+// A string without expressions has a single text part.
+//
+// An invalid escape sequence is added to the errors of the lexer, and the
+// string is read up to its closing quote all the same.
+//
+// Returns:
+//   - []StringPart: The parts of the string
+//   - bool: false if the string is unterminated
+func readStringParts(lex *Lexer) ([]StringPart, bool) {
 	lex.Advance() // Consume opening quote
 
+	parts := make([]StringPart, 0, 1)
 	var builder strings.Builder
+	line, column := lex.Line, lex.Column
 
 	// Read characters until closing quote
 	for lex.Current != '"' {
-		// Check for unterminated string (EOF or actual newline in source)
+		// Check for unterminated string (EOF)
 		if lex.Current == 0 {
-			fmt.Errorf("[%d:%d] LEXER ERROR: string literal not terminated - unexpected EOF", lex.Line, lex.Column)
-			return NewTokenWithMetadata(INVALID_TYPE, "", lex.Line, lex.Column)
+			return nil, false
 		}
 
 		// Handle escape sequences
 		if lex.Current == '\\' {
-			lex.Advance() // Consume the backslash
-			escapedChar, valid := escapeChar(lex.Current)
-			if !valid {
-				fmt.Errorf("[%d:%d] LEXER ERROR: invalid escape sequence: \\%c", lex.Line, lex.Column, lex.Current)
-				return NewTokenWithMetadata(INVALID_TYPE, "", lex.Line, lex.Column)
+			readEscape(lex, &builder, false)
+			continue
+		}
+
+		// Handle an embedded expression: ${expression}
+		if lex.Current == '$' && lex.Peek() == '{' {
+			if builder.Len() > 0 {
+				parts = append(parts, StringPart{Text: builder.String(), Line: line, Column: column})
+				builder.Reset()
 			}
-			builder.WriteByte(escapedChar)
-			lex.Advance()
+			lex.Advance() // Consume '$'
+			lex.Advance() // Consume '{'
+			part := StringPart{IsExpr: true, Line: lex.Line, Column: lex.Column}
+			start := lex.Position
+			if !skipInterpolation(lex) {
+				return nil, false
+			}
+			part.Text = lex.Src[start:lex.Position]
+			parts = append(parts, part)
+			lex.Advance() // Consume '}'
+			line, column = lex.Line, lex.Column
 			continue
 		}
 
 		// Regular character - add to string
+		if lex.Current == '\n' {
+			lex.Line++
+			lex.Column = 1
+		}
 		builder.WriteByte(lex.Current)
 		lex.Advance()
 	}
 
 	lex.Advance() // Consume closing quote
-	return NewTokenWithMetadata(STRING_LIT, builder.String(), lex.Line, lex.Column)
+	if builder.Len() > 0 || len(parts) == 0 {
+		parts = append(parts, StringPart{Text: builder.String(), Line: line, Column: column})
+	}
+	return parts, true
+}
+
+// skipInterpolation skips the expression of a ${...} in a string literal,
+// up to (not including) its closing brace. Nested braces, and the string
+// and character literals in the expression, are skipped as a whole.
+//
+// Returns:
+//   - bool: false if the expression or a literal in it is unterminated
+func skipInterpolation(lex *Lexer) bool {
+	depth := 0
+	for {
+		switch lex.Current {
+		case 0:
+			return false
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return true
+			}
+			depth--
+		case '"':
+			// The errors of literals in the expression are found again
+			// when the expression is parsed
+			errors := len(lex.Errors)
+			_, ok := readStringParts(lex)
+			lex.Errors = lex.Errors[:errors]
+			if !ok {
+				return false
+			}
+			continue
+		case '`':
+			if readRawString(lex).Type == INVALID_TYPE {
+				return false
+			}
+			continue
+		case '\'':
+			errors := len(lex.Errors)
+			invalid := readCharLiteral(lex).Type == INVALID_TYPE
+			lex.Errors = lex.Errors[:errors]
+			if invalid {
+				return false
+			}
+			continue
+		case '\n':
+			lex.Line++
+			lex.Column = 1
+		}
+		lex.Advance()
+	}
+}
+
+// SplitInterpolatedString splits an INTERP_STRING_LIT token into its text
+// parts (with their escape sequences processed) and the source of its
+// ${expression} parts, positioned in the source for error reporting.
+//
+// Example:
+//
+//	Source: "Hi ${user.name}!"
+//	Returns: [{Text: "Hi "}, {Text: "user.name", IsExpr: true}, {Text: "!"}]
+func SplitInterpolatedString(tok Token) []StringPart {
+	lex := NewLexer("\"" + tok.Literal + "\"")
+	lex.Line, lex.Column = tok.Line, tok.Column
+	parts, _ := readStringParts(&lex)
+	return parts
+}
+
+// readRawString reads and tokenizes a raw string literal enclosed in
+// backticks. A raw string can span several lines and its content is taken
+// as it is: backslashes do not start escape sequences and ${ is not
+// interpolated. Carriage returns are dropped, as in Go.
+//
+// Example:
+//
+//	Source: `C:\new\dir`
+//	Returns: Token{Type: RAW_STRING_LIT, Literal: "C:\\new\\dir"}
+func readRawString(lex *Lexer) Token {
+	lex.Advance() // Consume opening backtick
+	start := lex.Position
+	for lex.Current != '`' {
+		if lex.Current == 0 {
+			// Error: unterminated raw string
+			return NewTokenWithMetadata(INVALID_TYPE, "", lex.Line, lex.Column)
+		}
+		if lex.Current == '\n' {
+			lex.Line++
+			lex.Column = 1
+		}
+		lex.Advance()
+	}
+	literal := strings.ReplaceAll(lex.Src[start:lex.Position], "\r", "")
+	lex.Advance() // Consume closing backtick
+	return NewTokenWithMetadata(RAW_STRING_LIT, literal, lex.Line, lex.Column)
 }
 
 // readCharLiteral reads and tokenizes a character literal from the source.
-// It handles escape sequences like '\n', '\t', etc.
+// It handles escape sequences like '\n', '\t', '\u{e9}', etc., and characters
+// outside ASCII written as they are ('é').
 // Character literals must be enclosed in single quotes (').
 func readCharLiteral(lex *Lexer) Token {
 	line, col := lex.Line, lex.Column
 	lex.Advance() // Consume opening quote
 
-	var charVal byte
+	var builder strings.Builder
 
 	// Handle empty char literal error
 	if lex.Current == '\'' {
 		return NewTokenWithMetadata(INVALID_TYPE, "", lex.Line, lex.Column)
 	}
 
-	// Handle escape sequences
+	// Handle escape sequences; an invalid one is added to the errors of the
+	// lexer, and the rest of the literal is skipped
 	if lex.Current == '\\' {
-		lex.Advance() // Consume backslash
-		escaped, valid := escapeChar(lex.Current)
-		if !valid {
-			return NewTokenWithMetadata(INVALID_TYPE, string(lex.Current), lex.Line, lex.Column)
+		if !readEscape(lex, &builder, true) {
+			for lex.Current != '\'' && lex.Current != '\n' && lex.Current != 0 {
+				lex.Advance()
+			}
 		}
-		charVal = escaped
 	} else {
-		charVal = lex.Current
+		// A character can take several bytes in UTF-8
+		_, size := utf8.DecodeRuneInString(lex.Src[lex.Position:])
+		for i := 0; i < size; i++ {
+			builder.WriteByte(lex.Current)
+			lex.Advance()
+		}
 	}
-	lex.Advance()
 
 	if lex.Current != '\'' {
 		// Error: expected closing quote
-		return NewTokenWithMetadata(INVALID_TYPE, string(charVal), lex.Line, lex.Column)
+		return NewTokenWithMetadata(INVALID_TYPE, builder.String(), lex.Line, lex.Column)
 	}
 	lex.Advance() // Consume closing quote
 
-	return NewTokenWithMetadata(CHAR_LIT, string(charVal), line, col)
+	return NewTokenWithMetadata(CHAR_LIT, builder.String(), line, col)
+}
+
+// readEscape reads an escape sequence, from its backslash up to and
+// including its last character, and writes its value to builder.
+// Besides the escapes of escapeChar, it reads:
+//   - \xHH: the byte HH (the character U+00HH in a character literal)
+//   - \u{H...}: the UTF-8 encoding of the Unicode code point H...
+//
+// Parameters:
+//   - lex: Pointer to the lexer instance, at the backslash
+//   - builder: The builder to write the value to
+//   - char: Whether the escape is in a character literal
+//
+// Returns:
+//   - bool: true if the escape sequence is valid, false otherwise (the
+//     error, with the sequence and its position, is added to lex.Errors and
+//     the lexer is left at the character that made the sequence invalid)
+func readEscape(lex *Lexer, builder *strings.Builder, char bool) bool {
+	line, column, start := lex.Line, lex.Column, lex.Position
+	invalid := func() bool {
+		end := lex.Position
+		if lex.Current != 0 && lex.Current != '\n' && lex.Current != '"' && lex.Current != '\'' {
+			end++
+		}
+		lex.Errors = append(lex.Errors, fmt.Sprintf("[%d:%d] PARSER ERROR: invalid escape sequence: %s",
+			line, column, lex.Src[start:end]))
+		return false
+	}
+	lex.Advance() // Consume the backslash
+	switch lex.Current {
+	case 'x':
+		for i := 0; i < 2; i++ {
+			lex.Advance()
+			if !isHexDigitASCII(lex.Current) {
+				return invalid()
+			}
+		}
+		value, _ := strconv.ParseUint(lex.Src[lex.Position-1:lex.Position+1], 16, 8)
+		if char {
+			builder.WriteRune(rune(value))
+		} else {
+			builder.WriteByte(byte(value))
+		}
+	case 'u':
+		lex.Advance() // Consume 'u'
+		if lex.Current != '{' {
+			return invalid()
+		}
+		digitsStart := lex.Position + 1
+		lex.Advance()
+		for isHexDigitASCII(lex.Current) {
+			lex.Advance()
+		}
+		digits := lex.Src[digitsStart:lex.Position]
+		if lex.Current != '}' || len(digits) == 0 || len(digits) > 6 {
+			return invalid()
+		}
+		value, _ := strconv.ParseUint(digits, 16, 32)
+		if !utf8.ValidRune(rune(value)) {
+			return invalid()
+		}
+		builder.WriteRune(rune(value))
+	default:
+		escaped, valid := escapeChar(lex.Current)
+		if !valid {
+			return invalid()
+		}
+		builder.WriteByte(escaped)
+	}
+	lex.Advance() // Consume the last character of the escape
+	return true
 }
 
 // escapeChar converts an escape sequence character to its actual byte value.
@@ -226,6 +449,8 @@ func escapeChar(c byte) (byte, bool) {
 		return '\'', true // Single quote
 	case '0':
 		return 0, true // Null character
+	case '$':
+		return '$', true // Dollar sign
 	default:
 		return 0, false // Invalid escape sequence
 	}
@@ -240,6 +465,7 @@ func escapeChar(c byte) (byte, bool) {
 //   - Scientific notation: 1e9, 1.4e9, 12E-2
 //   - Hexadecimal integers: 0x16
 //   - Octal integers: 0777
+//   - Bigint integers: 123n, 0xFFn (an INT_LIT whose literal ends in n)
 //
 // Parameters:
 //   - lex: Pointer to the lexer instance
@@ -256,7 +482,6 @@ func readNumber(lex *Lexer) Token {
 
 	// Ensure we start with a digit
 	if lex.Current < '0' || lex.Current > '9' {
-		fmt.Errorf("[%d:%d] LEXER ERROR: malformed number literal", lex.Line, lex.Column)
 		return NewTokenWithMetadata(INVALID_TYPE, "", lex.Line, lex.Column)
 	}
 
@@ -271,6 +496,7 @@ func readNumber(lex *Lexer) Token {
 			for i < n && isHexDigitASCII(src[i]) {
 				i++
 			}
+			i = skipBigIntSuffix(src, i)
 			lex.Column += i - start
 			lex.Position = i
 			if i >= n {
@@ -329,6 +555,10 @@ func readNumber(lex *Lexer) Token {
 		break
 	}
 
+	if !hasDot && !hasExp {
+		i = skipBigIntSuffix(src, i)
+	}
+
 	lex.Column += i - start
 	lex.Position = i
 	if i >= n {
@@ -345,6 +575,15 @@ func readNumber(lex *Lexer) Token {
 	return NewTokenWithMetadata(tokenType, src[start:i], lex.Line, lex.Column)
 }
 
+// skipBigIntSuffix returns the position after the n suffix of a bigint literal
+// (123n) if the digits ending at i have one, or i otherwise.
+func skipBigIntSuffix(src string, i int) int {
+	if i < len(src) && src[i] == 'n' && (i+1 == len(src) || !(isAlphanumeric(src[i+1]) || src[i+1] == '_')) {
+		return i + 1
+	}
+	return i
+}
+
 // readIdentifier reads and tokenizes an identifier or keyword from the source.
 // Identifiers can be variable names, function names, or language keywords.
 //
@@ -373,7 +612,6 @@ func readIdentifier(lex *Lexer) Token {
 	if isAlpha(lex.Current) || lex.Current == '_' {
 		lex.Advance()
 	} else {
-		fmt.Errorf("[%d:%d] LEXER ERROR: malformed identifier", lex.Line, lex.Column)
 		return NewTokenWithMetadata(INVALID_TYPE, "", lex.Line, lex.Column)
 	}
 
@@ -93,13 +93,23 @@ const (
 	SWITCH_KEY   TokenType = "switch"   // Switch statement keyword
 	CASE_KEY     TokenType = "case"     // Case clause keyword
 	DEFAULT_KEY  TokenType = "default"  // Default clause keyword
+	TRY_KEY      TokenType = "try"      // Try block keyword
+	CATCH_KEY    TokenType = "catch"    // Catch clause keyword
+	FINALLY_KEY  TokenType = "finally"  // Finally clause keyword
+	THROW_KEY    TokenType = "throw"    // Throw statement keyword
+	SPAWN_KEY    TokenType = "spawn"    // Spawn statement keyword (runs a call on a goroutine)
+	MATCH_KEY    TokenType = "match"    // Match expression keyword (pattern matching)
+	YIELD_KEY    TokenType = "yield"    // Yield statement keyword (generator functions)
 
 	// Data Structure Literals
-	ARRAY_KEY  TokenType = "array"  // Array literal keyword
-	MAP_KEY    TokenType = "map"    // Map literal keyword
-	SET_KEY    TokenType = "set"    // Set literal keyword
-	STRUCT_KEY TokenType = "struct" // Struct declaration keyword
-	ENUM_KEY   TokenType = "enum"   // Enum declaration keyword
+	ARRAY_KEY      TokenType = "array"      // Array literal keyword
+	MAP_KEY        TokenType = "map"        // Map literal keyword
+	SET_KEY        TokenType = "set"        // Set literal keyword
+	STRUCT_KEY     TokenType = "struct"     // Struct declaration keyword
+	ENUM_KEY       TokenType = "enum"       // Enum declaration keyword
+	INTERFACE_KEY  TokenType = "interface"  // Interface declaration keyword
+	EXTENDS_KEY    TokenType = "extends"    // Parent struct of a struct declaration
+	IMPLEMENTS_KEY TokenType = "implements" // Interfaces of a struct declaration
 
 	// Identifiers
 	// Token types for user-defined names and character classes
@@ -109,12 +119,14 @@ const (
 
 	// Literals
 	// Token types for literal values in the source code
-	INT_LIT    TokenType = "IntLiteral"    // Integer literal (e.g., 42, -10)
-	FLOAT_LIT  TokenType = "FloatLiteral"  // Floating-point literal (e.g., 3.14, -0.5)
-	CHAR_LIT   TokenType = "CharLiteral"   // Character literal (e.g., 'a', '\n')
-	STRING_LIT TokenType = "StringLiteral" // String literal (e.g., "hello")
-	BOOL_LIT   TokenType = "BoolLiteral"   // Boolean literal (true or false)
-	NIL_LIT    TokenType = "NilLiteral"    // Nil/null literal
+	INT_LIT           TokenType = "IntLiteral"                // Integer literal (e.g., 42, -10)
+	FLOAT_LIT         TokenType = "FloatLiteral"              // Floating-point literal (e.g., 3.14, -0.5)
+	CHAR_LIT          TokenType = "CharLiteral"               // Character literal (e.g., 'a', '\n')
+	STRING_LIT        TokenType = "StringLiteral"             // String literal (e.g., "hello")
+	RAW_STRING_LIT    TokenType = "RawStringLiteral"          // Raw string literal (e.g., `C:\dir`), no escape sequences
+	INTERP_STRING_LIT TokenType = "InterpolatedStringLiteral" // Interpolated string literal (e.g., "Hi ${name}")
+	BOOL_LIT          TokenType = "BoolLiteral"               // Boolean literal (true or false)
+	NIL_LIT           TokenType = "NilLiteral"                // Nil/null literal
 
 	// Structural Tokens
 	// Brackets and braces for grouping and scoping
@@ -127,17 +139,22 @@ const (
 
 	// Delimiters
 	// Punctuation for separating elements
-	COMMA_DELIM     TokenType = "," // Comma - separates parameters, array elements
-	SEMICOLON_DELIM TokenType = ";" // Semicolon - statement terminator
-	COLON_DELIM     TokenType = ":" // Colon - used in slicing operations
+	COMMA_DELIM     TokenType = ","  // Comma - separates parameters, array elements
+	SEMICOLON_DELIM TokenType = ";"  // Semicolon - statement terminator
+	COLON_DELIM     TokenType = ":"  // Colon - used in slicing operations
+	ARROW_DELIM     TokenType = "=>" // Arrow - separates a match pattern from its result
 
 	// Range Operator
 	RANGE_OP TokenType = "..." // Range operator - creates inclusive ranges (e.g., 2...5)
 
 	// Object member access operator
-	DOT_OP   TokenType = "."    // Dot operator - access struct fields and methods
-	THIS_KEY TokenType = "this" // 'this' keyword for referring to the current struct instance member
-	SELF_KEY TokenType = "self" // 'self' keyword for referring to the current class member
+	DOT_OP    TokenType = "."     // Dot operator - access struct fields and methods
+	THIS_KEY  TokenType = "this"  // 'this' keyword for referring to the current struct instance member
+	SELF_KEY  TokenType = "self"  // 'self' keyword for referring to the current class member
+	SUPER_KEY TokenType = "super" // 'super' keyword for calling the methods of the parent struct
+
+	// Type test operator
+	INSTANCEOF_KEY TokenType = "instanceof" // Tests whether a value is an instance of a struct or interface
 
 )
 
@@ -153,34 +170,46 @@ const (
 //	When the lexer encounters an identifier-like token, it checks this map
 //	to determine if it's a keyword or a user-defined identifier.
 var KEYWORDS_MAP = map[string]TokenType{
-	"func":     FUNC_KEY,     // Function declaration
-	"new":      NEW_KEY,      // Object creation
-	"return":   RETURN_KEY,   // Return from function
-	"var":      VAR_KEY,      // Mutable variable
-	"let":      LET_KEY,      // Type-locked variable
-	"const":    CONST_KEY,    // Immutable constant
-	"true":     TRUE_KEY,     // Boolean true
-	"false":    FALSE_KEY,    // Boolean false
-	"if":       IF_KEY,       // Conditional if
-	"else":     ELSE_KEY,     // Conditional else
-	"while":    WHILE_KEY,    // While loop
-	"for":      FOR_KEY,      // For loop
-	"foreach":  FOREACH_KEY,  // Foreach loop
-	"in":       IN_KEY,       // In keyword for foreach
-	"break":    BREAK_KEY,    // Break from loop
-	"continue": CONTINUE_KEY, // Continue to next iteration
-	"array":    ARRAY_KEY,    // Array literal
-	"struct":   STRUCT_KEY,   // Struct declaration
-	"enum":     ENUM_KEY,     // Enum declaration
-	"map":      MAP_KEY,      // Map literal
-	"set":      SET_KEY,      // Set literal
-	"nil":      NIL_LIT,      // Nil/null value
-	"this":     THIS_KEY,     // 'this' keyword
-	"self":     SELF_KEY,     // 'self' keyword
-	"import":   IMPORT_KEY,   // Import package keyword
-	"switch":   SWITCH_KEY,   // Switch statement keyword
-	"case":     CASE_KEY,     // Case clause keyword
-	"default":  DEFAULT_KEY,  // Default clause keyword
+	"func":       FUNC_KEY,       // Function declaration
+	"new":        NEW_KEY,        // Object creation
+	"return":     RETURN_KEY,     // Return from function
+	"var":        VAR_KEY,        // Mutable variable
+	"let":        LET_KEY,        // Type-locked variable
+	"const":      CONST_KEY,      // Immutable constant
+	"true":       TRUE_KEY,       // Boolean true
+	"false":      FALSE_KEY,      // Boolean false
+	"if":         IF_KEY,         // Conditional if
+	"else":       ELSE_KEY,       // Conditional else
+	"while":      WHILE_KEY,      // While loop
+	"for":        FOR_KEY,        // For loop
+	"foreach":    FOREACH_KEY,    // Foreach loop
+	"in":         IN_KEY,         // In keyword for foreach
+	"break":      BREAK_KEY,      // Break from loop
+	"continue":   CONTINUE_KEY,   // Continue to next iteration
+	"array":      ARRAY_KEY,      // Array literal
+	"struct":     STRUCT_KEY,     // Struct declaration
+	"enum":       ENUM_KEY,       // Enum declaration
+	"interface":  INTERFACE_KEY,  // Interface declaration
+	"extends":    EXTENDS_KEY,    // Parent struct
+	"implements": IMPLEMENTS_KEY, // Implemented interfaces
+	"map":        MAP_KEY,        // Map literal
+	"set":        SET_KEY,        // Set literal
+	"nil":        NIL_LIT,        // Nil/null value
+	"this":       THIS_KEY,       // 'this' keyword
+	"self":       SELF_KEY,       // 'self' keyword
+	"super":      SUPER_KEY,      // 'super' keyword
+	"instanceof": INSTANCEOF_KEY, // Type test operator
+	"import":     IMPORT_KEY,     // Import package keyword
+	"switch":     SWITCH_KEY,     // Switch statement keyword
+	"case":       CASE_KEY,       // Case clause keyword
+	"default":    DEFAULT_KEY,    // Default clause keyword
+	"try":        TRY_KEY,        // Try block keyword
+	"catch":      CATCH_KEY,      // Catch clause keyword
+	"finally":    FINALLY_KEY,    // Finally clause keyword
+	"throw":      THROW_KEY,      // Throw statement keyword
+	"spawn":      SPAWN_KEY,      // Spawn statement keyword
+	"match":      MATCH_KEY,      // Match expression keyword
+	"yield":      YIELD_KEY,      // Yield statement keyword
 }
 
 // Token represents a single lexical token in the Go-Mix source code.
@@ -204,6 +233,33 @@ type Token struct {
 	Column  int       // Column number in source file (1-indexed)
 }
 
+// Comment is a comment found between tokens. The lexer does not turn
+// comments into tokens, but keeps them as trivia so that tools such as the
+// formatter can put them back into the source they print.
+//
+// Fields:
+//   - Text: The comment including its delimiters (// ... or /* ... */)
+//   - Line: The line number where the comment starts (1-indexed)
+//   - Column: The column number where the comment starts (1-indexed)
+//   - EndLine: The line number where the comment ends (1-indexed)
+//   - Trailing: Whether the comment follows code on its line
+type Comment struct {
+	Text     string // The comment text, delimiters included
+	Line     int    // Line number where the comment starts
+	Column   int    // Column number where the comment starts
+	EndLine  int    // Line number where the comment ends
+	Trailing bool   // The comment follows code on its line (e.g. x = 1; // note)
+}
+
+// StringPart is a piece of an interpolated string literal: either text, or
+// the source of an expression embedded with ${...}.
+type StringPart struct {
+	Text   string // The text (escape sequences processed), or the source of the expression
+	IsExpr bool   // Whether Text is the source of an expression
+	Line   int    // Line number where the part starts
+	Column int    // Column number where the part starts
+}
+
 // NewToken creates a new Token with the specified type and literal value.
 // This is a basic constructor that does not set line/column metadata.
 // Use NewTokenWithMetadata if position information is needed.
@@ -0,0 +1,530 @@
+/*
+File    : go-mix/lsp/analysis.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package lsp
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/parser"
+)
+
+// span is a range of zero-based lines (both inclusive) in which a name is visible.
+type span struct {
+	from, to int
+}
+
+// wholeDocument is the span of a top-level name.
+var wholeDocument = span{0, math.MaxInt}
+
+// size returns the number of lines of the span minus one.
+func (s span) size() int {
+	return s.to - s.from
+}
+
+// symbol is a name defined by a document: a function, struct, enum or
+// variable (including parameters, loop variables and caught errors).
+type symbol struct {
+	Name    string
+	Kind    int       // The LSP SymbolKind
+	Detail  string    // The declaration shown on hover (e.g., "func add(a, b)")
+	Range   Range     // Where the name is declared
+	Scope   span      // Where the name is visible
+	Members []*symbol // The fields and methods of a struct or the members of an enum
+}
+
+// document is an open Go-Mix source file and what the server learned from
+// parsing it.
+type document struct {
+	uri     string
+	text    string
+	lines   []string
+	errors  []string          // The parse errors (see parser.Parser.GetErrors)
+	tokens  []lexer.Token     // The tokens of the text
+	tokenAt map[[2]int]int    // Index in tokens by the line and column the lexer reports
+	defs    []*symbol         // Every name the document defines, in source order
+	outline []*symbol         // The top-level definitions
+	imports map[string]string // Bound name (e.g., "m" for "import math as m") to package name
+}
+
+// analyze parses a document and collects its definitions.
+//
+// A document that does not parse still yields the definitions of the
+// statements the parser recovered, so that navigation keeps working while the
+// user types.
+//
+// Parameters:
+//   - uri: The URI of the document
+//   - text: The source code of the document
+//
+// Returns:
+//   - *document: The analyzed document
+func analyze(uri, text string) *document {
+	d := &document{
+		uri:     uri,
+		text:    text,
+		lines:   strings.Split(text, "\n"),
+		tokenAt: make(map[[2]int]int),
+		imports: make(map[string]string),
+	}
+	lex := lexer.NewLexer(text)
+	d.tokens = lex.ConsumeTokens()
+	for i, tok := range d.tokens {
+		d.tokenAt[[2]int{tok.Line, tok.Column}] = i
+	}
+
+	d.parse()
+	return d
+}
+
+// parse parses the document and collects its definitions (see analyze).
+func (d *document) parse() {
+	defer func() {
+		// The parser is not written for incomplete input everywhere: keep what
+		// was collected so far rather than taking the server down.
+		if recovered := recover(); recovered != nil {
+			d.errors = append(d.errors, fmt.Sprintf("PARSER ERROR: %v", recovered))
+		}
+	}()
+	par := parser.NewParser(d.text)
+	root := par.Parse()
+	d.errors = par.GetErrors()
+	d.collect(root.Statements, wholeDocument, true)
+}
+
+// collect records the definitions of a list of statements.
+//
+// Parameters:
+//   - stmts: The statements
+//   - scope: The span in which the names they define are visible
+//   - top: Whether the statements are at the top level of the document
+func (d *document) collect(stmts []parser.StatementNode, scope span, top bool) {
+	for _, stmt := range stmts {
+		d.collectStatement(stmt, scope, top)
+	}
+}
+
+// collectStatement records the definitions of a statement and of the
+// statements nested in it (see collect).
+func (d *document) collectStatement(stmt parser.StatementNode, scope span, top bool) {
+	switch n := stmt.(type) {
+	case *parser.DeclarativeStatementNode:
+		if n.Pattern != nil {
+			for _, name := range n.Pattern.Bindings() {
+				d.define(d.newSymbol(name.Token, name.Name, symbolVariable, n.VarToken.Literal+" "+name.Name, scope), top)
+			}
+			break
+		}
+		d.define(d.variable(n, symbolVariable, scope), top)
+		switch expr := n.Expr.(type) {
+		case *parser.FunctionStatementNode:
+			d.function(expr, symbolFunction)
+		case *parser.MatchExpressionNode:
+			d.collectStatement(expr, scope, false)
+		}
+
+	case *parser.ReturnStatementNode:
+		if m, ok := n.Expr.(*parser.MatchExpressionNode); ok {
+			d.collectStatement(m, scope, false)
+		}
+
+	case *parser.FunctionStatementNode:
+		sym := d.function(n, symbolFunction)
+		if n.FuncName.Name != "" {
+			sym.Scope = scope
+			d.define(sym, top)
+		}
+
+	case *parser.StructDeclarationNode:
+		sym := d.newSymbol(n.StructName.Token, n.StructName.Name, symbolStruct, n.Header(), scope)
+		for _, field := range n.Fields {
+			sym.Members = append(sym.Members, d.variable(field, symbolField, scope))
+		}
+		for _, method := range n.Methods {
+			sym.Members = append(sym.Members, d.function(method, symbolMethod))
+		}
+		d.define(sym, top)
+
+	case *parser.InterfaceDeclarationNode:
+		sym := d.newSymbol(n.InterfaceName.Token, n.InterfaceName.Name, symbolInterface, "interface "+n.InterfaceName.Name, scope)
+		for _, method := range n.Methods {
+			sym.Members = append(sym.Members, d.newSymbol(method.Name.Token, method.Name.Name, symbolMethod, method.Literal(), scope))
+		}
+		d.define(sym, top)
+
+	case *parser.EnumDeclarationNode:
+		sym := d.newSymbol(n.EnumName.Token, n.EnumName.Name, symbolEnum, "enum "+n.EnumName.Name, scope)
+		for _, member := range n.Members {
+			detail := n.EnumName.Name + "." + member.Name
+			if member.Value != nil {
+				detail += " = " + member.Value.ToString()
+			}
+			sym.Members = append(sym.Members, d.newSymbol(member.Token, member.Name, symbolEnumMember, detail, scope))
+		}
+		d.define(sym, top)
+
+	case *parser.ImportStatementNode:
+		name := n.Name
+		if n.Alias != "" {
+			name = n.Alias
+		}
+		d.imports[name] = n.Name
+
+	case *parser.BlockStatementNode:
+		d.collect(n.Statements, scope, false)
+
+	case *parser.IfExpressionNode:
+		d.collect(n.ThenBlock.Statements, scope, false)
+		d.collect(n.ElseBlock.Statements, scope, false)
+
+	case *parser.ForLoopStatementNode:
+		loop := d.blockAfter(n.ForToken)
+		d.collect(n.Initializers, loop, false)
+		d.collect(n.Body.Statements, loop, false)
+
+	case *parser.WhileLoopStatementNode:
+		d.collect(n.Body.Statements, scope, false)
+
+	case *parser.ForeachLoopStatementNode:
+		loop := d.blockAfter(n.ForeachToken)
+		if n.Key != nil {
+			d.defs = append(d.defs, d.newSymbol(n.Key.Token, n.Key.Name, symbolVariable, "var "+n.Key.Name, loop))
+		}
+		if n.Pattern != nil {
+			for _, name := range n.Pattern.Bindings() {
+				d.defs = append(d.defs, d.newSymbol(name.Token, name.Name, symbolVariable, "var "+name.Name, loop))
+			}
+		} else {
+			d.defs = append(d.defs, d.newSymbol(n.Iterator.Token, n.Iterator.Name, symbolVariable, "var "+n.Iterator.Name, loop))
+		}
+		d.collect(n.Body.Statements, loop, false)
+
+	case *parser.SwitchStatementNode:
+		for _, c := range n.Cases {
+			d.collect(c.Body.Statements, scope, false)
+		}
+		if n.Default != nil {
+			d.collect(n.Default.Body.Statements, scope, false)
+		}
+
+	case *parser.MatchExpressionNode:
+		arms := d.blockAfter(n.Token)
+		for _, arm := range n.Arms {
+			for _, name := range arm.Pattern.Bindings() {
+				d.defs = append(d.defs, d.newSymbol(name.Token, name.Name, symbolVariable, "var "+name.Name, arms))
+			}
+			if block, ok := arm.Body.(*parser.BlockStatementNode); ok {
+				d.collect(block.Statements, arms, false)
+			}
+		}
+
+	case *parser.TryStatementNode:
+		d.collect(n.TryBlock.Statements, scope, false)
+		if n.CatchBlock != nil {
+			catch := scope
+			if n.CatchParam != nil {
+				catch = d.blockAfter(n.CatchParam.Token)
+				d.defs = append(d.defs, d.newSymbol(n.CatchParam.Token, n.CatchParam.Name, symbolVariable, "var "+n.CatchParam.Name, catch))
+			}
+			d.collect(n.CatchBlock.Statements, catch, false)
+		}
+		if n.FinallyBlock != nil {
+			d.collect(n.FinallyBlock.Statements, scope, false)
+		}
+	}
+}
+
+// define records a definition that is visible by its name.
+func (d *document) define(sym *symbol, top bool) {
+	d.defs = append(d.defs, sym)
+	if top {
+		d.outline = append(d.outline, sym)
+	}
+}
+
+// variable returns the symbol of a var, let or const declaration (or of a
+// struct field) visible in the given scope.
+func (d *document) variable(n *parser.DeclarativeStatementNode, kind int, scope span) *symbol {
+	if n.VarToken.Type == lexer.CONST_KEY && kind == symbolVariable {
+		kind = symbolConstant
+	}
+	keyword := n.VarToken.Literal
+	if keyword == "" {
+		keyword = "var"
+	}
+	detail := keyword + " " + n.Identifier.Name
+	if n.VarType != nil {
+		detail += ": " + n.VarType.String()
+	}
+	return d.newSymbol(n.Identifier.Token, n.Identifier.Name, kind, detail, scope)
+}
+
+// function returns the symbol of a function or method and records the
+// parameters and local definitions of its body, which are visible in the body.
+// The caller decides where the function's own name is visible.
+func (d *document) function(n *parser.FunctionStatementNode, kind int) *symbol {
+	params := make([]string, len(n.FuncParams))
+	for i, param := range n.FuncParams {
+		params[i] = param.Name
+		if typ := n.ParamType(i); typ != nil {
+			params[i] += ": " + typ.String()
+		}
+		if n.FuncRest && i == len(n.FuncParams)-1 {
+			params[i] = "..." + params[i]
+		} else if def := n.Default(i); def != nil {
+			params[i] += " = " + def.Literal()
+		}
+	}
+	name := n.FuncName.Name
+	detail := "func " + name + "(" + strings.Join(params, ", ") + ")"
+	if n.ReturnType != nil {
+		detail += ": " + n.ReturnType.String()
+	}
+
+	anchor := n.FuncToken
+	if name != "" {
+		anchor = n.FuncName.Token
+	}
+	body := d.blockAfter(anchor)
+	for i, param := range n.FuncParams {
+		detail := "param " + param.Name
+		if typ := n.ParamType(i); typ != nil {
+			detail += ": " + typ.String()
+		}
+		d.defs = append(d.defs, d.newSymbol(param.Token, param.Name, symbolVariable, detail, body))
+	}
+	d.collect(n.FuncBody.Statements, body, false)
+	return d.newSymbol(n.FuncName.Token, name, kind, detail, wholeDocument)
+}
+
+// newSymbol creates a symbol declared by the given token.
+func (d *document) newSymbol(tok lexer.Token, name string, kind int, detail string, scope span) *symbol {
+	return &symbol{Name: name, Kind: kind, Detail: detail, Range: d.locate(tok, name), Scope: scope}
+}
+
+// blockAfter returns the lines from a token to the end of the first block
+// that follows it (e.g., from a function name to the closing brace of its
+// body). An unterminated block extends to the end of the document.
+func (d *document) blockAfter(tok lexer.Token) span {
+	s := span{tok.Line - 1, math.MaxInt}
+	i, found := d.tokenAt[[2]int{tok.Line, tok.Column}]
+	if !found {
+		return s
+	}
+	depth := 0
+	for ; i < len(d.tokens); i++ {
+		switch d.tokens[i].Type {
+		case lexer.LEFT_BRACE:
+			depth++
+		case lexer.RIGHT_BRACE:
+			depth--
+			if depth == 0 {
+				s.to = d.tokens[i].Line - 1
+				return s
+			}
+		}
+	}
+	return s
+}
+
+// locate returns the range of a name declared by a token.
+//
+// The lexer reports the column just past the end of a token, and counts
+// columns from 2 after a newline, so the name is looked up on the token's
+// line and the occurrence closest to the reported column is taken.
+func (d *document) locate(tok lexer.Token, name string) Range {
+	line := tok.Line - 1
+	if line < 0 || line >= len(d.lines) {
+		return Range{}
+	}
+	text := d.lines[line]
+	guess := tok.Column - 1 - len(name)
+	start := -1
+	for offset := 0; name != ""; {
+		idx := strings.Index(text[offset:], name)
+		if idx < 0 {
+			break
+		}
+		idx += offset
+		end := idx + len(name)
+		whole := (idx == 0 || !isIdentByte(text[idx-1])) && (end == len(text) || !isIdentByte(text[end]))
+		if whole && (start < 0 || abs(idx-guess) < abs(start-guess)) {
+			start = idx
+		}
+		offset = end
+	}
+	if start < 0 {
+		start = min(max(guess, 0), len(text))
+		return Range{Start: d.position(line, start), End: d.position(line, start)}
+	}
+	return Range{Start: d.position(line, start), End: d.position(line, start+len(name))}
+}
+
+// errorPattern matches the position prefix of a parse error ("[3:14] PARSER ERROR: ...").
+var errorPattern = regexp.MustCompile(`^\[(\d+):(\d+)\]\s*(.*)$`)
+
+// diagnostics converts the parse errors of the document into diagnostics.
+// Errors without a position are reported on the first line.
+func (d *document) diagnostics() []Diagnostic {
+	diags := make([]Diagnostic, 0, len(d.errors))
+	for _, msg := range d.errors {
+		pos := Position{}
+		if m := errorPattern.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			column, _ := strconv.Atoi(m[2])
+			if line-1 < len(d.lines) {
+				pos = d.position(max(line-1, 0), min(max(column-1, 0), len(d.lines[max(line-1, 0)])))
+			}
+			msg = m[3]
+		}
+		diags = append(diags, Diagnostic{
+			Range:    Range{Start: pos, End: pos},
+			Severity: severityError,
+			Source:   "go-mix",
+			Message:  msg,
+		})
+	}
+	return diags
+}
+
+// lookup finds the definition a name refers to on a given line: the
+// innermost definition visible there, and among those of the same scope the
+// last one before the line (or the first one, e.g., for a function called
+// before it is declared).
+func (d *document) lookup(name string, line int) *symbol {
+	var best *symbol
+	for _, sym := range d.defs {
+		if sym.Name != name || line < sym.Scope.from || line > sym.Scope.to {
+			continue
+		}
+		if best == nil || sym.Scope.size() < best.Scope.size() ||
+			(sym.Scope == best.Scope && sym.Range.Start.Line <= line) {
+			best = sym
+		}
+	}
+	return best
+}
+
+// member finds the definition of owner.name: a member of the enum owner, or
+// otherwise a field or method of any struct of the document (the type of an
+// object is not known before it runs).
+func (d *document) member(owner, name string, line int) *symbol {
+	if enum := d.lookup(owner, line); enum != nil && enum.Kind == symbolEnum {
+		return findMember(enum, name)
+	}
+	for _, sym := range d.defs {
+		if sym.Kind == symbolStruct {
+			if m := findMember(sym, name); m != nil {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// findMember returns the member of a struct or enum with the given name, or nil.
+func findMember(sym *symbol, name string) *symbol {
+	for _, m := range sym.Members {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// visible returns the definitions visible on a line, one per name.
+func (d *document) visible(line int) []*symbol {
+	seen := make(map[string]bool)
+	syms := make([]*symbol, 0)
+	for _, sym := range d.defs {
+		if seen[sym.Name] || line < sym.Scope.from || line > sym.Scope.to {
+			continue
+		}
+		seen[sym.Name] = true
+		syms = append(syms, d.lookup(sym.Name, line))
+	}
+	return syms
+}
+
+// wordAt returns the identifier at a position and the identifier before the
+// dot that precedes it, if any (e.g., "math" and "abs" in "math.abs").
+// A position just past the end of an identifier is on it.
+//
+// Returns:
+//   - owner: The identifier before the dot, or ""
+//   - word: The identifier, or "" if there is none at pos
+//   - r: The range of word
+func (d *document) wordAt(pos Position) (owner, word string, r Range) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return "", "", Range{}
+	}
+	text := d.lines[pos.Line]
+	at := d.offset(pos)
+	start, end := at, at
+	for start > 0 && isIdentByte(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isIdentByte(text[end]) {
+		end++
+	}
+	if start == end {
+		return "", "", Range{}
+	}
+	word = text[start:end]
+	if start > 0 && text[start-1] == '.' {
+		owner = identBefore(text, start-1)
+	}
+	return owner, word, Range{Start: d.position(pos.Line, start), End: d.position(pos.Line, end)}
+}
+
+// identBefore returns the identifier that ends at byte offset end of text.
+func identBefore(text string, end int) string {
+	start := end
+	for start > 0 && isIdentByte(text[start-1]) {
+		start--
+	}
+	return text[start:end]
+}
+
+// isIdentByte reports whether c can be part of an identifier.
+func isIdentByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// position converts a byte offset on a line into an LSP position, whose
+// character offsets count UTF-16 code units.
+func (d *document) position(line, offset int) Position {
+	return Position{Line: line, Character: len(utf16.Encode([]rune(d.lines[line][:offset])))}
+}
+
+// offset converts an LSP position into a byte offset on its line.
+func (d *document) offset(pos Position) int {
+	text := d.lines[pos.Line]
+	units := 0
+	for i, r := range text {
+		if units >= pos.Character {
+			return i
+		}
+		units++
+		if r > 0xFFFF {
+			units++ // encoded as a surrogate pair
+		}
+	}
+	return len(text)
+}
+
+// abs returns the absolute value of an int.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
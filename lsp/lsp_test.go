@@ -0,0 +1,275 @@
+/*
+File    : go-mix/lsp/lsp_test.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	_ "github.com/akashmaji946/go-mix/file" // registers fopen, fread, ...
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// testSource is the document used by the tests below
+const testSource = `import math as m;
+enum Color { RED, GREEN }
+struct Point {
+    var x = 0;
+    func norm() { return m.sqrt(this.x * this.x); }
+}
+func area(w, h) {
+    var result = w * h;
+    return result;
+}
+var total = area(2, 3);
+println(Color.GREEN, m.abs(total));
+`
+
+// session runs a server on the given client messages and returns the
+// messages it sent back, in order.
+func session(t *testing.T, requests ...map[string]interface{}) []map[string]interface{} {
+	t.Helper()
+	var in bytes.Buffer
+	for _, req := range requests {
+		body, _ := json.Marshal(req)
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	var out bytes.Buffer
+	if err := NewServer(&in, &out).Run(); err != nil {
+		t.Fatalf("server failed: %v", err)
+	}
+
+	replies := make([]map[string]interface{}, 0)
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readFrame(r)
+		if err == io.EOF {
+			return replies
+		}
+		var decoded map[string]interface{}
+		if err == nil {
+			err = json.Unmarshal(body, &decoded)
+		}
+		if err != nil {
+			t.Fatalf("bad message from server: %v", err)
+		}
+		replies = append(replies, decoded)
+	}
+}
+
+// openDoc is the didOpen notification of a document
+func openDoc(uri, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0", "method": "textDocument/didOpen",
+		"params": map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri, "text": text, "version": 1}},
+	}
+}
+
+// atPosition is a request on a position of a document
+func atPosition(id int, method, uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0", "id": id, "method": method,
+		"params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"position":     map[string]interface{}{"line": line, "character": character},
+		},
+	}
+}
+
+// TestServer_Initialize verifies the capabilities and the shutdown handshake
+func TestServer_Initialize(t *testing.T) {
+	replies := session(t,
+		map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "textDocument/formatting", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "id": 3, "method": "shutdown"},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "exit"},
+	)
+	if len(replies) != 3 {
+		t.Fatalf("expected 3 replies, got %d: %v", len(replies), replies)
+	}
+	caps := replies[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	for _, capability := range []string{"definitionProvider", "hoverProvider", "documentSymbolProvider", "completionProvider"} {
+		if caps[capability] == nil {
+			t.Errorf("missing capability %s", capability)
+		}
+	}
+	if replies[1]["error"] == nil {
+		t.Errorf("expected an error for an unsupported method, got %v", replies[1])
+	}
+	if result, ok := replies[2]["result"]; !ok || result != nil {
+		t.Errorf("expected a null shutdown result, got %v", replies[2])
+	}
+}
+
+// TestServer_Diagnostics verifies that parse errors are published with their position
+func TestServer_Diagnostics(t *testing.T) {
+	replies := session(t,
+		openDoc("file:///ok.gm", testSource),
+		openDoc("file:///bad.gm", "var a = 1;\nvar = 2;\n"),
+	)
+	if len(replies) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(replies))
+	}
+	ok := replies[0]["params"].(map[string]interface{})
+	if diags := ok["diagnostics"].([]interface{}); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+	bad := replies[1]["params"].(map[string]interface{})
+	diags := bad["diagnostics"].([]interface{})
+	if len(diags) == 0 {
+		t.Fatalf("expected diagnostics for a bad document")
+	}
+	first := diags[0].(map[string]interface{})
+	line := first["range"].(map[string]interface{})["start"].(map[string]interface{})["line"]
+	if line != float64(1) || !strings.Contains(first["message"].(string), "PARSER ERROR") {
+		t.Errorf("unexpected diagnostic: %v", first)
+	}
+}
+
+// TestServer_Definition verifies go-to-definition for functions, variables, parameters, enums and members
+func TestServer_Definition(t *testing.T) {
+	tests := []struct {
+		line, character int // position of the reference
+		wantLine        int // line of the definition (-1 for none)
+		wantCharacter   int
+	}{
+		{10, 13, 6, 5},  // area -> func area
+		{11, 27, 10, 4}, // total -> var total
+		{8, 12, 7, 8},   // result -> var result
+		{7, 21, 6, 13},  // h -> parameter h
+		{11, 10, 1, 5},  // Color -> enum Color
+		{11, 16, 1, 18}, // Color.GREEN -> member GREEN
+		{4, 37, 3, 8},   // this.x -> field x
+		{11, 2, -1, 0},  // println is a builtin
+		{11, 23, -1, 0}, // m.abs is a package function
+	}
+	for _, tt := range tests {
+		replies := session(t, openDoc("file:///a.gm", testSource), atPosition(1, "textDocument/definition", "file:///a.gm", tt.line, tt.character))
+		result := replies[1]["result"]
+		if tt.wantLine < 0 {
+			if result != nil {
+				t.Errorf("%d:%d: expected no definition, got %v", tt.line, tt.character, result)
+			}
+			continue
+		}
+		loc, ok := result.(map[string]interface{})
+		if !ok {
+			t.Errorf("%d:%d: expected a location, got %v", tt.line, tt.character, result)
+			continue
+		}
+		start := loc["range"].(map[string]interface{})["start"].(map[string]interface{})
+		if start["line"] != float64(tt.wantLine) || start["character"] != float64(tt.wantCharacter) {
+			t.Errorf("%d:%d: expected %d:%d, got %v", tt.line, tt.character, tt.wantLine, tt.wantCharacter, start)
+		}
+	}
+}
+
+// TestServer_Hover verifies hover on user definitions, builtins and package functions
+func TestServer_Hover(t *testing.T) {
+	tests := []struct {
+		line, character int
+		want            string
+	}{
+		{10, 13, "func area(w, h)"},
+		{11, 2, "println("},
+		{11, 23, "m.abs(n) -> int"},
+		{11, 23, "package `math`"},
+		{0, 7, "Package `math`"},
+	}
+	for _, tt := range tests {
+		replies := session(t, openDoc("file:///a.gm", testSource), atPosition(1, "textDocument/hover", "file:///a.gm", tt.line, tt.character))
+		hover, ok := replies[1]["result"].(map[string]interface{})
+		if !ok {
+			t.Errorf("%d:%d: expected a hover, got %v", tt.line, tt.character, replies[1])
+			continue
+		}
+		value := hover["contents"].(map[string]interface{})["value"].(string)
+		if !strings.Contains(value, tt.want) {
+			t.Errorf("%d:%d: expected hover to contain %q, got %q", tt.line, tt.character, tt.want, value)
+		}
+	}
+}
+
+// TestBuiltinSignatures verifies that every registered builtin, global or in a
+// package, has a signature for hover and completion
+func TestBuiltinSignatures(t *testing.T) {
+	check := func(where string, b *std.Builtin) {
+		if !strings.HasPrefix(b.Signature, b.Name+"(") {
+			t.Errorf("%s %q: expected a signature starting with %q, got %q", where, b.Name, b.Name+"(", b.Signature)
+		}
+	}
+	for _, b := range std.Builtins {
+		check("builtin", b)
+	}
+	for name, pkg := range std.Packages {
+		for _, b := range pkg.Functions {
+			check("package "+name+":", b)
+		}
+	}
+}
+
+// TestServer_Completion verifies completion of package members, enum members and names in scope
+func TestServer_Completion(t *testing.T) {
+	labels := func(source string, line, character int) map[string]bool {
+		replies := session(t, openDoc("file:///a.gm", source), atPosition(1, "textDocument/completion", "file:///a.gm", line, character))
+		found := make(map[string]bool)
+		for _, item := range replies[1]["result"].([]interface{}) {
+			found[item.(map[string]interface{})["label"].(string)] = true
+		}
+		return found
+	}
+
+	members := labels("import math;\nmath.", 1, 5)
+	if !members["abs"] || !members["sqrt"] || members["println"] {
+		t.Errorf("unexpected completion after 'math.': %v", members)
+	}
+	aliased := labels(testSource+"m.sq", 12, 4)
+	if !aliased["sqrt"] {
+		t.Errorf("expected sqrt after 'm.', got %v", aliased)
+	}
+	enum := labels(testSource+"Color.", 12, 6)
+	if len(enum) != 2 || !enum["RED"] || !enum["GREEN"] {
+		t.Errorf("expected the members of Color, got %v", enum)
+	}
+	names := labels(testSource, 8, 4)
+	for _, want := range []string{"result", "w", "area", "total", "Point", "println", "foreach"} {
+		if !names[want] {
+			t.Errorf("expected %q in completion, got none", want)
+		}
+	}
+	outside := labels(testSource, 11, 0)
+	if outside["result"] || outside["w"] {
+		t.Errorf("locals of area should not be proposed outside of it")
+	}
+}
+
+// TestServer_DocumentSymbols verifies the outline of a document
+func TestServer_DocumentSymbols(t *testing.T) {
+	replies := session(t, openDoc("file:///a.gm", testSource), map[string]interface{}{
+		"jsonrpc": "2.0", "id": 1, "method": "textDocument/documentSymbol",
+		"params": map[string]interface{}{"textDocument": map[string]interface{}{"uri": "file:///a.gm"}},
+	})
+	got := make([]string, 0)
+	for _, item := range replies[1]["result"].([]interface{}) {
+		sym := item.(map[string]interface{})
+		entry := fmt.Sprintf("%s:%v", sym["name"], sym["kind"])
+		children, _ := sym["children"].([]interface{})
+		for _, child := range children {
+			entry += " " + child.(map[string]interface{})["name"].(string)
+		}
+		got = append(got, entry)
+	}
+	want := []string{"Color:10 RED GREEN", "Point:23 x norm", "area:12", "total:13"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,229 @@
+/*
+File    : go-mix/lsp/protocol.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// message is a JSON-RPC 2.0 request, notification or response.
+// Requests carry an ID and a Method, notifications only a Method and
+// responses only an ID (and a Result, which may be null, or an Error).
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+// responseError is the error of a failed request.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes used by the server
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+)
+
+// readMessage reads one message framed by a Content-Length header.
+//
+// Returns:
+//   - *message: The decoded message
+//   - error: io.EOF at the end of the stream, or a framing or decoding error
+func readMessage(r *bufio.Reader) (*message, error) {
+	body, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// readFrame reads the body of one message framed by a Content-Length header.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeMessage writes one message framed by a Content-Length header.
+func writeMessage(w io.Writer, msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// Position is a zero-based line and character offset in a document.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of a document; End is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a given document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// Diagnostic is a problem reported for a document.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// severityError is the diagnostic severity of a parse error.
+const severityError = 1
+
+// Symbol kinds (a subset of the LSP SymbolKind enumeration)
+const (
+	symbolMethod     = 6
+	symbolField      = 8
+	symbolEnum       = 10
+	symbolInterface  = 11
+	symbolFunction   = 12
+	symbolVariable   = 13
+	symbolConstant   = 14
+	symbolEnumMember = 22
+	symbolStruct     = 23
+)
+
+// DocumentSymbol is an entry of the outline of a document.
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           int              `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// Completion item kinds (a subset of the LSP CompletionItemKind enumeration)
+const (
+	completionFunction   = 3
+	completionVariable   = 6
+	completionInterface  = 8
+	completionModule     = 9
+	completionEnum       = 13
+	completionKeyword    = 14
+	completionEnumMember = 20
+	completionStruct     = 22
+)
+
+// CompletionItem is a proposal of the completion request.
+type CompletionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// MarkupContent is formatted text (here always Markdown).
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Hover is the result of the hover request.
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// textDocumentItem is a document opened by the client.
+type textDocumentItem struct {
+	URI     string `json:"uri"`
+	Text    string `json:"text"`
+	Version int    `json:"version"`
+}
+
+// textDocumentIdentifier names a document.
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// textDocumentPositionParams are the parameters of the definition, hover and
+// completion requests.
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// didOpenParams are the parameters of textDocument/didOpen.
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+// didChangeParams are the parameters of textDocument/didChange. The server
+// asks for full synchronization, so each change holds the whole text.
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// documentParams are the parameters of textDocument/didClose and
+// textDocument/documentSymbol.
+type documentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// publishDiagnosticsParams are the parameters of textDocument/publishDiagnostics.
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
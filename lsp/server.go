@@ -0,0 +1,392 @@
+/*
+File    : go-mix/lsp/server.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+/*
+Package lsp implements a Language Server Protocol server for Go-Mix.
+It speaks JSON-RPC over a pair of streams (stdin and stdout for `go-mix lsp`)
+and reuses the lexer and parser of the interpreter to provide:
+- Parse diagnostics, published whenever a document is opened or changed
+- Go-to-definition for functions, structs, enums and variables
+- Hover with the declaration of a name or the signature of a builtin
+- Completion of package members (after "math.") and of names in scope
+- Document symbols (the outline of a file)
+
+Documents are never run: everything the server knows comes from parsing.
+*/
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// Server is a Go-Mix language server connected to a client.
+// It handles one message at a time, in the order they arrive.
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]*document    // Open documents by URI
+	builtins map[string]*std.Builtin // The builtin functions, by name
+	shutdown bool                    // Whether the client asked the server to shut down
+}
+
+// NewServer creates a language server that reads requests from in and
+// writes responses and notifications to out.
+//
+// Parameters:
+//   - in: The stream of client messages (e.g., os.Stdin)
+//   - out: The stream to the client (e.g., os.Stdout)
+//
+// Returns:
+//   - *Server: A server ready to Run
+func NewServer(in io.Reader, out io.Writer) *Server {
+	builtins := make(map[string]*std.Builtin, len(std.Builtins))
+	for _, b := range std.Builtins {
+		builtins[b.Name] = b
+	}
+	return &Server{
+		in:       bufio.NewReader(in),
+		out:      out,
+		docs:     make(map[string]*document),
+		builtins: builtins,
+	}
+}
+
+// Run serves client messages until the client sends "exit" or closes the
+// input stream.
+//
+// Returns:
+//   - error: nil on a clean exit, or the error that stopped the server
+//     (including an "exit" that was not preceded by "shutdown")
+func (s *Server) Run() error {
+	for {
+		msg, err := readMessage(s.in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				null := json.RawMessage("null")
+				if err := s.send(&message{ID: &null, Error: &responseError{Code: codeParseError, Message: err.Error()}}); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit before shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification to its handler and answers
+// requests.
+func (s *Server) handle(msg *message) error {
+	var result interface{}
+	var err error
+	switch msg.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       1, // full text on every change
+				"definitionProvider":     true,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+				"completionProvider":     map[string]interface{}{"triggerCharacters": []string{"."}},
+			},
+			"serverInfo": map[string]string{"name": "go-mix"},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			return s.open(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			last := params.ContentChanges[len(params.ContentChanges)-1]
+			return s.open(params.TextDocument.URI, last.Text)
+		}
+	case "textDocument/didClose":
+		var params documentParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			delete(s.docs, params.TextDocument.URI)
+			return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+		}
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = s.definition(params)
+		}
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = s.hover(params)
+		}
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = s.completion(params)
+		}
+	case "textDocument/documentSymbol":
+		var params documentParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = s.documentSymbols(params.TextDocument.URI)
+		}
+	default:
+		if msg.ID != nil {
+			return s.send(&message{ID: msg.ID, Error: &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method}})
+		}
+		return nil // notifications the server does not need (e.g., "initialized")
+	}
+
+	if msg.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return s.send(&message{ID: msg.ID, Error: &responseError{Code: codeInvalidParams, Message: err.Error()}})
+	}
+	return s.reply(msg.ID, result)
+}
+
+// open analyzes the text of a document and publishes its diagnostics.
+func (s *Server) open(uri, text string) error {
+	doc := analyze(uri, text)
+	s.docs[uri] = doc
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: doc.diagnostics()})
+}
+
+// reply sends the result of a request. A nil result is sent as null.
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(body)
+	return s.send(&message{ID: id, Result: &raw})
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.send(&message{Method: method, Params: body})
+}
+
+// send writes a message to the client.
+func (s *Server) send(msg *message) error {
+	return writeMessage(s.out, msg)
+}
+
+// definition answers textDocument/definition: the declaration of the name
+// under the cursor, or nil for builtins and unknown names.
+func (s *Server) definition(params textDocumentPositionParams) *Location {
+	doc, sym := s.symbolAt(params)
+	if sym == nil {
+		return nil
+	}
+	return &Location{URI: doc.uri, Range: sym.Range}
+}
+
+// hover answers textDocument/hover with the declaration of the name under
+// the cursor, or the signature of the builtin or package function it names.
+func (s *Server) hover(params textDocumentPositionParams) *Hover {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	owner, word, r := doc.wordAt(params.Position)
+	if word == "" {
+		return nil
+	}
+
+	var text string
+	if pkg := s.packageOf(doc, owner); pkg != nil {
+		fn, exists := pkg.Functions[word]
+		if !exists {
+			return nil
+		}
+		text = codeBlock(owner+"."+signature(fn)) + "\nFunction of package `" + pkg.Name + "`."
+	} else if _, sym := s.symbolAt(params); sym != nil {
+		text = codeBlock(sym.Detail)
+	} else if b := s.builtins[word]; owner == "" && b != nil {
+		text = codeBlock(signature(b)) + "\nBuiltin function."
+	} else if pkg := s.packageOf(doc, word); owner == "" && pkg != nil {
+		text = codeBlock("import "+pkg.Name) + "\nPackage `" + pkg.Name + "`."
+	} else {
+		return nil
+	}
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: text}, Range: &r}
+}
+
+// completion answers textDocument/completion. After "name." it proposes the
+// functions of the package or the members of the enum bound to name;
+// elsewhere, the names in scope, the builtins and the keywords.
+func (s *Server) completion(params textDocumentPositionParams) []CompletionItem {
+	items := make([]CompletionItem, 0)
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok || params.Position.Line >= len(doc.lines) {
+		return items
+	}
+	line := params.Position.Line
+	before := doc.lines[line][:doc.offset(params.Position)]
+	prefix := strings.TrimRightFunc(before, func(r rune) bool { return r < 128 && isIdentByte(byte(r)) })
+
+	if strings.HasSuffix(prefix, ".") {
+		owner := identBefore(prefix, len(prefix)-1)
+		if pkg := s.packageOf(doc, owner); pkg != nil {
+			for name, fn := range pkg.Functions {
+				items = append(items, CompletionItem{Label: name, Kind: completionFunction, Detail: signature(fn)})
+			}
+		} else if enum := doc.lookup(owner, line); enum != nil && enum.Kind == symbolEnum {
+			for _, m := range enum.Members {
+				items = append(items, CompletionItem{Label: m.Name, Kind: completionEnumMember, Detail: m.Detail})
+			}
+		}
+		sortItems(items)
+		return items
+	}
+
+	seen := make(map[string]bool)
+	for _, sym := range doc.visible(line) {
+		seen[sym.Name] = true
+		items = append(items, CompletionItem{Label: sym.Name, Kind: completionKind(sym.Kind), Detail: sym.Detail})
+	}
+	for name := range doc.imports {
+		if !seen[name] {
+			seen[name] = true
+			items = append(items, CompletionItem{Label: name, Kind: completionModule, Detail: "import " + doc.imports[name]})
+		}
+	}
+	for name, b := range s.builtins {
+		if !seen[name] {
+			seen[name] = true
+			items = append(items, CompletionItem{Label: name, Kind: completionFunction, Detail: signature(b)})
+		}
+	}
+	for keyword := range lexer.KEYWORDS_MAP {
+		if !seen[keyword] {
+			items = append(items, CompletionItem{Label: keyword, Kind: completionKeyword})
+		}
+	}
+	sortItems(items)
+	return items
+}
+
+// documentSymbols answers textDocument/documentSymbol with the top-level
+// definitions of a document; structs and enums list their members.
+func (s *Server) documentSymbols(uri string) []DocumentSymbol {
+	syms := make([]DocumentSymbol, 0)
+	doc, ok := s.docs[uri]
+	if !ok {
+		return syms
+	}
+	for _, sym := range doc.outline {
+		syms = append(syms, outlineOf(sym))
+	}
+	return syms
+}
+
+// outlineOf converts a symbol and its members into a DocumentSymbol.
+func outlineOf(sym *symbol) DocumentSymbol {
+	ds := DocumentSymbol{Name: sym.Name, Detail: sym.Detail, Kind: sym.Kind, Range: sym.Range, SelectionRange: sym.Range}
+	for _, m := range sym.Members {
+		ds.Children = append(ds.Children, outlineOf(m))
+	}
+	return ds
+}
+
+// symbolAt returns the document of a request and the definition of the name
+// at its position (nil if it is not defined by the document).
+func (s *Server) symbolAt(params textDocumentPositionParams) (*document, *symbol) {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	owner, word, _ := doc.wordAt(params.Position)
+	switch {
+	case word == "":
+		return doc, nil
+	case owner == "":
+		return doc, doc.lookup(word, params.Position.Line)
+	case s.packageOf(doc, owner) != nil:
+		return doc, nil
+	default:
+		return doc, doc.member(owner, word, params.Position.Line)
+	}
+}
+
+// packageOf returns the builtin package a name refers to in a document: the
+// package it was imported as (e.g., "m" after "import math as m"), or the
+// package of that name. It returns nil for other names.
+func (s *Server) packageOf(doc *document, name string) *std.Package {
+	if name == "" {
+		return nil
+	}
+	if imported, ok := doc.imports[name]; ok {
+		return std.Packages[imported]
+	}
+	if doc.lookup(name, 0) != nil {
+		return nil // shadowed by a definition of the document
+	}
+	return std.Packages[name]
+}
+
+// signature returns the documented signature of a builtin, as registered
+// with it, or name(...) for a builtin registered without one.
+func signature(b *std.Builtin) string {
+	if b.Signature != "" {
+		return b.Signature
+	}
+	return b.Name + "(...)"
+}
+
+// codeBlock formats Go-Mix code for a Markdown hover.
+func codeBlock(code string) string {
+	return "```gomix\n" + code + "\n```"
+}
+
+// completionKind maps a symbol kind to the matching completion item kind.
+func completionKind(kind int) int {
+	switch kind {
+	case symbolFunction, symbolMethod:
+		return completionFunction
+	case symbolStruct:
+		return completionStruct
+	case symbolInterface:
+		return completionInterface
+	case symbolEnum:
+		return completionEnum
+	case symbolEnumMember:
+		return completionEnumMember
+	default:
+		return completionVariable
+	}
+}
+
+// sortItems orders completion items by label, so that responses are stable.
+func sortItems(items []CompletionItem) {
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+}
@@ -16,11 +16,18 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/akashmaji946/go-mix/checker"
 	"github.com/akashmaji946/go-mix/eval"
 	_ "github.com/akashmaji946/go-mix/file"
+	"github.com/akashmaji946/go-mix/formatter"
+	"github.com/akashmaji946/go-mix/lsp"
 	"github.com/akashmaji946/go-mix/parser"
 	"github.com/akashmaji946/go-mix/repl"
+	"github.com/akashmaji946/go-mix/std"
 	"github.com/fatih/color"
 )
 
@@ -75,10 +82,18 @@ var (
 //	go-mix <filename>   - Execute the specified Go-Mix source file
 //	go-mix --help       - Display help information
 //	go-mix --version    - Display version information
+//	go-mix lsp          - Run the language server over stdin/stdout
+//	go-mix fmt [-w] <files...> - Print files in the canonical layout (-w rewrites them)
+//	go-mix check <files...> - Verify the type annotations of files without running them
+//	go-mix --vm ...     - Run on the bytecode VM instead of the tree walker
+//	go-mix --timeout=D ... - Run under execution limits (also --max-steps, --max-depth, --max-alloc)
+//	go-mix --sandbox --allow-fs-read=./data ... - Run with restricted host access
 //
 // The function delegates to either runFile() for file execution
 // or starts the REPL for interactive programming.
 func main() {
+	parseRuntimeFlags()
+
 	// Check if a flag argument is provided
 	if len(os.Args) > 1 {
 		arg := os.Args[1]
@@ -105,6 +120,25 @@ func main() {
 			startServer(port)
 			return // Exit after starting the server
 		}
+
+		// LSP mode: serve an editor over stdin/stdout
+		if arg == "lsp" {
+			if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+				redColor.Fprintf(os.Stderr, "[LSP ERROR] %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Format mode: print (or rewrite) files in the canonical layout
+		if arg == "fmt" {
+			os.Exit(formatFiles(os.Args[2:]))
+		}
+
+		// Check mode: verify type annotations without running the files
+		if arg == "check" {
+			os.Exit(checkFiles(os.Args[2:]))
+		}
 		// File mode: read and run a file
 		fileName := arg
 		runFile(fileName)
@@ -117,6 +151,119 @@ func main() {
 	}
 }
 
+// parseRuntimeFlags consumes the interpreter options that precede the mode
+// argument (e.g., go-mix --vm file.gm) and removes them from os.Args, so that
+// the rest of main and the script's args() only see the remaining arguments.
+//
+// Supported options:
+//   - --vm: Execute programs on the bytecode VM
+//   - --max-steps=N: Stop programs after N evaluation steps
+//   - --max-depth=N: Limit the depth of nested function calls
+//   - --max-alloc=N: Limit the (approximate) bytes a program may allocate
+//   - --timeout=D: Stop programs after the duration D (e.g., 500ms, 10s)
+//   - --sandbox: Start from the restricted profile (no capability granted)
+//   - --allow-<cap>[=paths], --deny-<cap>, --allow-all: Grant or revoke capabilities
+func parseRuntimeFlags() {
+	rest := []string{os.Args[0]}
+	sandbox := false
+	var permFlags []func(*std.Permissions)
+	i := 1
+	for ; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if arg == "--vm" {
+			eval.VMByDefault = true
+			continue
+		}
+		if arg == "--sandbox" {
+			sandbox = true
+			continue
+		}
+		if apply, ok := parsePermissionFlag(arg); ok {
+			permFlags = append(permFlags, apply)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue || !parseLimitFlag(name, value) {
+			break
+		}
+	}
+	os.Args = append(rest, os.Args[i:]...)
+
+	// server sessions run untrusted input, so they start from the sandbox profile
+	perms := std.AllowAll()
+	if sandbox || (len(os.Args) > 1 && os.Args[1] == "server") {
+		perms = std.Restricted()
+	}
+	for _, apply := range permFlags {
+		apply(perms)
+	}
+	eval.DefaultPermissions = perms
+}
+
+// parsePermissionFlag parses a capability option: --allow-all, --allow-<cap>,
+// --allow-<cap>=path[,path...] (file system capabilities only) or --deny-<cap>.
+// Returns the change to apply to the permissions, and false if arg is not a
+// capability option; exits on unknown capabilities.
+func parsePermissionFlag(arg string) (func(*std.Permissions), bool) {
+	if arg == "--allow-all" {
+		return func(p *std.Permissions) {
+			for _, c := range std.Capabilities {
+				p.Grant(c)
+			}
+		}, true
+	}
+	name, value, hasValue := strings.Cut(arg, "=")
+	deny := strings.HasPrefix(name, "--deny-")
+	if !deny && !strings.HasPrefix(name, "--allow-") {
+		return nil, false
+	}
+	capName := strings.TrimPrefix(strings.TrimPrefix(name, "--deny-"), "--allow-")
+	c, ok := std.ParseCapability(capName)
+	if !ok {
+		redColor.Fprintf(os.Stderr, "[USAGE ERROR] Unknown capability in %s (expected one of fs-read, fs-write, process, env, net)\n", name)
+		os.Exit(1)
+	}
+	if deny {
+		if hasValue {
+			redColor.Fprintf(os.Stderr, "[USAGE ERROR] %s does not take a value\n", name)
+			os.Exit(1)
+		}
+		return func(p *std.Permissions) { p.Deny(c) }, true
+	}
+	if !hasValue {
+		return func(p *std.Permissions) { p.Grant(c) }, true
+	}
+	if c != std.CapFSRead && c != std.CapFSWrite {
+		redColor.Fprintf(os.Stderr, "[USAGE ERROR] %s cannot be limited to paths\n", name)
+		os.Exit(1)
+	}
+	paths := strings.Split(value, ",")
+	return func(p *std.Permissions) { p.Grant(c, paths...) }, true
+}
+
+// parseLimitFlag applies an execution limit option to eval.DefaultLimits.
+// Returns false if name is not a limit option; exits on invalid values.
+func parseLimitFlag(name, value string) bool {
+	var err error
+	switch name {
+	case "--max-steps":
+		eval.DefaultLimits.MaxSteps, err = strconv.ParseInt(value, 10, 64)
+	case "--max-depth":
+		eval.DefaultLimits.MaxCallDepth, err = strconv.Atoi(value)
+	case "--max-alloc":
+		eval.DefaultLimits.MaxAlloc, err = strconv.ParseInt(value, 10, 64)
+	case "--timeout":
+		eval.DefaultLimits.Timeout, err = time.ParseDuration(value)
+	default:
+		return false
+	}
+	if err != nil {
+		redColor.Fprintf(os.Stderr, "[USAGE ERROR] Invalid value for %s: %s\n", name, value)
+		os.Exit(1)
+	}
+	return true
+}
+
 // showHelp displays the help information for the Go-Mix interpreter
 func showHelp() {
 	cyanColor.Println("Go-Mix - An Interpreted Programming Language")
@@ -125,9 +272,24 @@ func showHelp() {
 	yellowColor.Println("  go-mix                    Start interactive REPL mode")
 	yellowColor.Println("  go-mix <path-to-file>     Execute a Go-Mix file (.gm)")
 	yellowColor.Println("  go-mix server <port>      Start REPL server on specified port")
+	yellowColor.Println("  go-mix lsp                Start the language server (LSP over stdin/stdout)")
+	yellowColor.Println("  go-mix fmt [-w] <files>   Format files (print them, or rewrite them with -w)")
+	yellowColor.Println("  go-mix check <files>      Verify the type annotations of files without running them")
 	yellowColor.Println("  go-mix --help             Display this help message")
 	yellowColor.Println("  go-mix --version          Display version information")
 	cyanColor.Println("")
+	cyanColor.Println("OPTIONS:")
+	yellowColor.Println("  --vm                      Run on the bytecode VM (e.g., go-mix --vm file.gm)")
+	yellowColor.Println("  --max-steps=N             Stop a program after N evaluation steps")
+	yellowColor.Println("  --max-depth=N             Limit nested function calls (default 10000)")
+	yellowColor.Println("  --max-alloc=N             Limit the bytes a program may allocate (approximate)")
+	yellowColor.Println("  --timeout=D               Stop a program after D (e.g., 10s; server default 30s)")
+	yellowColor.Println("  --sandbox                 Deny all host access (default in server mode)")
+	yellowColor.Println("  --allow-<cap>[=PATHS]     Grant a capability: fs-read, fs-write, process, env, net")
+	yellowColor.Println("                            (fs-read/fs-write may be limited to comma-separated paths)")
+	yellowColor.Println("  --deny-<cap>              Revoke a capability (e.g., --deny-net)")
+	yellowColor.Println("  --allow-all               Grant every capability")
+	cyanColor.Println("")
 	cyanColor.Println("REPL COMMANDS:")
 	yellowColor.Println("  /exit                     Exit the REPL")
 	yellowColor.Println("  /scope                    Show current scope and variables")
@@ -136,6 +298,8 @@ func showHelp() {
 	yellowColor.Println("  go-mix                    # Start REPL")
 	yellowColor.Println("  go-mix samples/algo/05_factorial.gm")
 	yellowColor.Println("  go-mix server 8080        # Start REPL server on port 8080")
+	yellowColor.Println("  go-mix fmt -w main.gm     # Format main.gm in place")
+	yellowColor.Println("  go-mix check main.gm      # Type-check main.gm")
 	cyanColor.Println("")
 	cyanColor.Println("For more information, visit: https://github.com/akashmaji946/go-mix")
 }
@@ -178,17 +342,115 @@ func runFile(fileName string) {
 	// fmt.Println(LINE)
 
 	// Execute the source code with panic recovery to handle runtime errors gracefully
-	executeFileWithRecovery(source)
+	executeFileWithRecovery(source, fileName)
+}
+
+// formatFiles formats Go-Mix source files (go-mix fmt [-w] <files...>).
+// Each file is printed to stdout in the canonical layout, or rewritten in
+// place when -w is given. Files that do not parse are reported and left
+// unchanged.
+//
+// Parameters:
+//
+//	args - The arguments after "fmt": an optional -w followed by file names
+//
+// Returns:
+//
+//	The exit status: 0 if every file was formatted, 1 otherwise
+func formatFiles(args []string) int {
+	write := len(args) > 0 && args[0] == "-w"
+	if write {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		redColor.Fprintf(os.Stderr, "[USAGE ERROR] Missing files to format. Usage: go-mix fmt [-w] <files...>\n")
+		return 1
+	}
+
+	status := 0
+	for _, fileName := range args {
+		fileContent, err := os.ReadFile(fileName)
+		if err != nil {
+			redColor.Fprintf(os.Stderr, "[FILE ERROR] Could not read file '%s': %v\n", fileName, err)
+			status = 1
+			continue
+		}
+		formatted, err := formatter.Format(string(fileContent))
+		if err != nil {
+			redColor.Fprintf(os.Stderr, "[FMT ERROR] %s:\n%v\n", fileName, err)
+			status = 1
+			continue
+		}
+		if !write {
+			fmt.Print(formatted)
+			continue
+		}
+		if formatted == string(fileContent) {
+			continue
+		}
+		if err := os.WriteFile(fileName, []byte(formatted), 0644); err != nil {
+			redColor.Fprintf(os.Stderr, "[FILE ERROR] Could not write file '%s': %v\n", fileName, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// checkFiles verifies the type annotations of Go-Mix source files
+// (go-mix check <files...>) without running them. Parse errors and type
+// errors are reported to stderr.
+//
+// Parameters:
+//
+//	args - The file names after "check"
+//
+// Returns:
+//
+//	The exit status: 0 if every file passed the check, 1 otherwise
+func checkFiles(args []string) int {
+	if len(args) == 0 {
+		redColor.Fprintf(os.Stderr, "[USAGE ERROR] Missing files to check. Usage: go-mix check <files...>\n")
+		return 1
+	}
+
+	status := 0
+	for _, fileName := range args {
+		fileContent, err := os.ReadFile(fileName)
+		if err != nil {
+			redColor.Fprintf(os.Stderr, "[FILE ERROR] Could not read file '%s': %v\n", fileName, err)
+			status = 1
+			continue
+		}
+		par := parser.NewParser(string(fileContent))
+		rootNode := par.Parse()
+		if par.HasErrors() {
+			for _, err := range par.GetErrors() {
+				redColor.Fprintf(os.Stderr, "[PARSE ERROR] %s: %s\n", fileName, err)
+			}
+			status = 1
+			continue
+		}
+		for _, err := range checker.Check(rootNode) {
+			redColor.Fprintf(os.Stderr, "[CHECK ERROR] %s: %s\n", fileName, err)
+			status = 1
+		}
+	}
+	return status
 }
 
 // startServer initializes and runs the Go-Mix REPL server.
 // It listens on the specified port for incoming TCP connections.
 // Each connection is handled in a separate goroutine, providing a dedicated REPL session.
+// Unless --timeout is given, every input of a session may run for at most
+// serverTimeout, so that a runaway script cannot hold a session forever.
 //
 // Parameters:
 //
 //	port - The network port to listen on (e.g., "8080")
 func startServer(port string) {
+	if eval.DefaultLimits.Timeout == 0 {
+		eval.DefaultLimits.Timeout = serverTimeout
+	}
 	listener, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		redColor.Fprintf(os.Stderr, "[SERVER ERROR] Failed to start server on port %s: %v\n", port, err)
@@ -207,6 +469,9 @@ func startServer(port string) {
 	}
 }
 
+// serverTimeout is the default time limit of each input in a REPL server session.
+const serverTimeout = 30 * time.Second
+
 // handleClient manages a single client connection for the REPL server.
 // It creates a new REPL instance and starts it, using the network connection
 // as both the input reader and output writer.
@@ -228,14 +493,15 @@ func handleClient(conn net.Conn) {
 //
 // Parameters:
 //
-//	source - The Go-Mix source code as a string
+//	source   - The Go-Mix source code as a string
+//	fileName - Path of the source file, used to resolve module imports
 //
 // Error Handling:
 //   - Panics: Caught by defer/recover, displayed as runtime errors
 //   - Parse errors: Collected and displayed, then exit
 //   - Evaluation errors: Displayed in red, then exit
 //   - Success: Result displayed in yellow (if not nil)
-func executeFileWithRecovery(source string) {
+func executeFileWithRecovery(source string, fileName string) {
 	// Recover from any panics that might occur during parsing or evaluation
 	// This prevents the interpreter from crashing and provides user-friendly error messages
 	defer func() {
@@ -272,15 +538,17 @@ func executeFileWithRecovery(source string) {
 	// Create evaluator and execute the AST
 	// The evaluator walks the AST and executes the program
 	evaluator := eval.NewEvaluator()
-	evaluator.SetParser(par) // Link parser for access to environment and error handling
+	evaluator.SetParser(par)          // Link parser for access to environment and error handling
+	evaluator.SetSourceFile(fileName) // Resolve module imports relative to this file
 	result := evaluator.Eval(rootNode)
 
 	// Display result if any (and not nil)
 	// Only display non-nil results to avoid cluttering output
 	if result != nil {
 		if result.GetType() == "error" {
-			// Evaluation produced an error object - display and exit
+			// Evaluation produced an error object - display it with its traceback and exit
 			redColor.Fprintf(os.Stderr, "%s\n", result.ToString())
+			redColor.Fprint(os.Stderr, std.Traceback(result))
 			os.Exit(1)
 		} else {
 			// Successful evaluation - display result in yellow
@@ -113,6 +113,9 @@ func (p *PrintingVisitor) VisitDeclarativeStatementNode(node parser.DeclarativeS
 	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s](%s => %v)\n", "Declaration",
 		node.Literal(), node.Literal(), node.Value.ToObject()))
 	p.Indent += INDENT_SIZE
+	if node.Pattern != nil {
+		node.Pattern.Accept(p)
+	}
 	node.Expr.Accept(p)
 	p.Indent -= INDENT_SIZE
 }
@@ -190,6 +193,17 @@ func (p *PrintingVisitor) VisitStringLiteralExpressionNode(node parser.StringLit
 		node.Literal(), node.Literal(), node.Value.ToObject()))
 }
 
+// VisitInterpolatedStringExpressionNode visits an interpolated string node and prints all embedded expressions
+func (p *PrintingVisitor) VisitInterpolatedStringExpressionNode(node parser.InterpolatedStringExpressionNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [\"%s\"]\n", "Interp", node.Literal()))
+	p.Indent += INDENT_SIZE
+	for _, expr := range node.Exprs {
+		expr.Accept(p)
+	}
+	p.Indent -= INDENT_SIZE
+}
+
 // VisitFloatLiteralExpressionNode visits a float literal node and prints its value
 func (p *PrintingVisitor) VisitFloatLiteralExpressionNode(node parser.FloatLiteralExpressionNode) {
 	p.indent()
@@ -211,8 +225,11 @@ func (p *PrintingVisitor) VisitFunctionStatementNode(node parser.FunctionStateme
 		node.Literal(), node.Literal(), node.Value.ToObject()))
 	p.Indent += INDENT_SIZE
 	node.FuncName.Accept(p)
-	for _, param := range node.FuncParams {
+	for i, param := range node.FuncParams {
 		param.Accept(p)
+		if def := node.Default(i); def != nil {
+			def.Accept(p)
+		}
 	}
 	node.FuncBody.Accept(p)
 	p.Indent -= INDENT_SIZE
@@ -224,7 +241,7 @@ func (p *PrintingVisitor) VisitCallExpressionNode(node parser.CallExpressionNode
 	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s] (%s => %v)\n", "Call",
 		node.Literal(), node.Literal(), node.Value.ToObject()))
 	p.Indent += INDENT_SIZE
-	node.FunctionIdentifier.Accept(p)
+	node.Callee.Accept(p)
 	for _, arg := range node.Arguments {
 		arg.Accept(p)
 	}
@@ -319,7 +336,14 @@ func (p *PrintingVisitor) VisitForeachLoopStatementNode(node parser.ForeachLoopS
 	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s] (%s => %v)\n", "Foreach",
 		node.Literal(), node.Literal(), node.Value.ToString()))
 	p.Indent += INDENT_SIZE
-	node.Iterator.Accept(p)
+	if node.Key != nil {
+		node.Key.Accept(p)
+	}
+	if node.Pattern != nil {
+		node.Pattern.Accept(p)
+	} else {
+		node.Iterator.Accept(p)
+	}
 	node.Iterable.Accept(p)
 	node.Body.Accept(p)
 	p.Indent -= INDENT_SIZE
@@ -350,6 +374,29 @@ func (p *PrintingVisitor) VisitSetExpressionNode(node parser.SetExpressionNode)
 	p.Indent -= INDENT_SIZE
 }
 
+// VisitTupleExpressionNode visits a tuple literal node and prints all elements
+func (p *PrintingVisitor) VisitTupleExpressionNode(node parser.TupleExpressionNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s] (%s => %v)\n", "Tuple",
+		node.Literal(), node.Literal(), node.Value))
+	p.Indent += INDENT_SIZE
+	for _, elem := range node.Elements {
+		elem.Accept(p)
+	}
+	p.Indent -= INDENT_SIZE
+}
+
+// VisitPatternNode visits a destructuring pattern node and prints the names it binds
+func (p *PrintingVisitor) VisitPatternNode(node parser.PatternNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s]\n", "Pattern", node.Literal()))
+	p.Indent += INDENT_SIZE
+	for _, name := range node.Bindings() {
+		name.Accept(p)
+	}
+	p.Indent -= INDENT_SIZE
+}
+
 // VisitStructDeclarationNode visits a struct declaration node and prints the struct details
 func (p *PrintingVisitor) VisitStructDeclarationNode(node parser.StructDeclarationNode) {
 	p.indent()
@@ -365,6 +412,25 @@ func (p *PrintingVisitor) VisitStructDeclarationNode(node parser.StructDeclarati
 	p.Indent -= INDENT_SIZE
 }
 
+// VisitInterfaceDeclarationNode visits an interface declaration node and prints its method signatures
+func (p *PrintingVisitor) VisitInterfaceDeclarationNode(node parser.InterfaceDeclarationNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %15s Node [%s] (%s => %v)\n", "interface",
+		node.Literal(), node.Literal(), node.Value.ToObject()))
+	p.Indent += INDENT_SIZE
+	for _, method := range node.Methods {
+		p.indent()
+		p.Buf.WriteString(fmt.Sprintf("Method: %s\n", method.Literal()))
+	}
+	p.Indent -= INDENT_SIZE
+}
+
+// VisitSuperExpressionNode visits a super expression node
+func (p *PrintingVisitor) VisitSuperExpressionNode(node parser.SuperExpressionNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s]\n", "Super", node.Literal()))
+}
+
 // VisitNewCallExpressionNode visits a struct instantiation node and prints the instantiation details
 func (p *PrintingVisitor) VisitNewCallExpressionNode(node parser.NewCallExpressionNode) {
 	p.indent()
@@ -418,6 +484,25 @@ func (p *PrintingVisitor) VisitSwitchStatementNode(node parser.SwitchStatementNo
 	p.Indent -= INDENT_SIZE
 }
 
+// VisitMatchExpressionNode visits a match expression node and prints its arms
+func (p *PrintingVisitor) VisitMatchExpressionNode(node parser.MatchExpressionNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s]\n", "Match", node.Literal()))
+	p.Indent += INDENT_SIZE
+	node.Subject.Accept(p)
+	for _, arm := range node.Arms {
+		p.indent()
+		p.Buf.WriteString(fmt.Sprintf("Arm: %s\n", arm.Pattern.Literal()))
+		p.Indent += INDENT_SIZE
+		if arm.Guard != nil {
+			arm.Guard.Accept(p)
+		}
+		arm.Body.Accept(p)
+		p.Indent -= INDENT_SIZE
+	}
+	p.Indent -= INDENT_SIZE
+}
+
 // String returns the accumulated formatted output as a string
 func (p *PrintingVisitor) String() string {
 	return p.Buf.String()
@@ -446,3 +531,57 @@ func (p *PrintingVisitor) VisitEnumAccessExpressionNode(node parser.EnumAccessEx
 	node.MemberName.Accept(p)
 	p.Indent -= INDENT_SIZE
 }
+
+// VisitTryStatementNode visits a try statement node and prints its try, catch, and finally blocks
+func (p *PrintingVisitor) VisitTryStatementNode(node parser.TryStatementNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s]\n", "Try", node.Token.Literal))
+	p.Indent += INDENT_SIZE
+	node.TryBlock.Accept(p)
+	if node.CatchBlock != nil {
+		p.indent()
+		if node.CatchParam != nil {
+			p.Buf.WriteString(fmt.Sprintf("Catch (%s):\n", node.CatchParam.Name))
+		} else {
+			p.Buf.WriteString("Catch:\n")
+		}
+		p.Indent += INDENT_SIZE
+		node.CatchBlock.Accept(p)
+		p.Indent -= INDENT_SIZE
+	}
+	if node.FinallyBlock != nil {
+		p.indent()
+		p.Buf.WriteString("Finally:\n")
+		p.Indent += INDENT_SIZE
+		node.FinallyBlock.Accept(p)
+		p.Indent -= INDENT_SIZE
+	}
+	p.Indent -= INDENT_SIZE
+}
+
+// VisitThrowStatementNode visits a throw statement node and prints the thrown expression
+func (p *PrintingVisitor) VisitThrowStatementNode(node parser.ThrowStatementNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s]\n", "Throw", node.Literal()))
+	p.Indent += INDENT_SIZE
+	node.Expr.Accept(p)
+	p.Indent -= INDENT_SIZE
+}
+
+// VisitSpawnStatementNode visits a spawn statement node and prints the spawned call
+func (p *PrintingVisitor) VisitSpawnStatementNode(node parser.SpawnStatementNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s]\n", "Spawn", node.Literal()))
+	p.Indent += INDENT_SIZE
+	node.Call.Accept(p)
+	p.Indent -= INDENT_SIZE
+}
+
+// VisitYieldStatementNode visits a yield statement node and prints the yielded expression
+func (p *PrintingVisitor) VisitYieldStatementNode(node parser.YieldStatementNode) {
+	p.indent()
+	p.Buf.WriteString(fmt.Sprintf("Visiting %10s Node [%s]\n", "Yield", node.Literal()))
+	p.Indent += INDENT_SIZE
+	node.Expr.Accept(p)
+	p.Indent -= INDENT_SIZE
+}
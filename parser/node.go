@@ -21,12 +21,13 @@ type NodeVisitor interface {
 	VisitRootNode(node RootNode) // Entry point for visiting the entire program
 
 	// Literal value visitors - handle primitive data types
-	VisitIntegerLiteralExpressionNode(node IntegerLiteralExpressionNode) // Integer literals: 42, -15, 0
-	VisitBooleanLiteralExpressionNode(node BooleanLiteralExpressionNode) // Boolean literals: true, false
-	VisitCharLiteralExpressionNode(node CharLiteralExpressionNode)       // Char literals: 'a'
-	VisitFloatLiteralExpressionNode(node FloatLiteralExpressionNode)     // Float literals: 3.14, -2.5
-	VisitStringLiteralExpressionNode(node StringLiteralExpressionNode)   // String literals: "hello", 'world'
-	VisitNilLiteralExpressionNode(node NilLiteralExpressionNode)         // Nil/null literal
+	VisitIntegerLiteralExpressionNode(node IntegerLiteralExpressionNode)         // Integer literals: 42, -15, 0
+	VisitBooleanLiteralExpressionNode(node BooleanLiteralExpressionNode)         // Boolean literals: true, false
+	VisitCharLiteralExpressionNode(node CharLiteralExpressionNode)               // Char literals: 'a'
+	VisitFloatLiteralExpressionNode(node FloatLiteralExpressionNode)             // Float literals: 3.14, -2.5
+	VisitStringLiteralExpressionNode(node StringLiteralExpressionNode)           // String literals: "hello", 'world'
+	VisitInterpolatedStringExpressionNode(node InterpolatedStringExpressionNode) // Interpolated strings: "Hi ${name}"
+	VisitNilLiteralExpressionNode(node NilLiteralExpressionNode)                 // Nil/null literal
 
 	// Expression visitors - handle operations and computations
 	VisitBinaryExpressionNode(node BinaryExpressionNode)               // Binary operations: +, -, *, /, %
@@ -45,6 +46,8 @@ type NodeVisitor interface {
 	VisitIfExpressionNode(node IfExpressionNode) // If-else conditionals: if (cond) { ... } else { ... }
 	// Switch statement visitor
 	VisitSwitchStatementNode(node SwitchStatementNode) // Switch statements: switch (expr) { case x: ... default: ... }
+	// Match expression visitor
+	VisitMatchExpressionNode(node MatchExpressionNode) // Match expressions: match (expr) { pattern if guard => result, ... }
 
 	// Function-related visitors
 	// Function statement visitor
@@ -66,6 +69,8 @@ type NodeVisitor interface {
 	// Map and set visitors
 	VisitMapExpressionNode(node MapExpressionNode) // Map literals: map{key: value}
 	VisitSetExpressionNode(node SetExpressionNode) // Set literals: set{1, 2, 3}
+	// Tuple visitor
+	VisitTupleExpressionNode(node TupleExpressionNode) // Tuple literals: (a, b) or return a, b
 	// Indexing and slicing visitors
 	VisitIndexExpressionNode(node IndexExpressionNode) // Array indexing: arr[0], arr[-1]
 	VisitSliceExpressionNode(node SliceExpressionNode) // Array slicing: arr[1:3], arr[:5], arr[2:]
@@ -75,6 +80,10 @@ type NodeVisitor interface {
 	// Structures and related visitors
 	// Struct declarartion visitors
 	VisitStructDeclarationNode(node StructDeclarationNode) // Struct definitions: struct Name { method1, method2, ... }
+	// Interface declaration visitor
+	VisitInterfaceDeclarationNode(node InterfaceDeclarationNode) // Interface definitions: interface Name { method1(); method2(a); }
+	// Parent struct access visitor
+	VisitSuperExpressionNode(node SuperExpressionNode) // Parent struct methods: super.method(args)
 	// Struct instantiation visitor
 	VisitNewCallExpressionNode(node NewCallExpressionNode) // Struct instantiation: new Name(args)
 	// Enum statement visitor
@@ -86,9 +95,21 @@ type NodeVisitor interface {
 	VisitContinueStatementNode(node ContinueStatementNode) // continue
 	// Return statement visitor
 	VisitReturnStatementNode(node ReturnStatementNode) // Return statements: return expr
+	// Destructuring pattern visitor
+	VisitPatternNode(node PatternNode) // Destructuring patterns: (q, r), [first, ...rest], {name, age}
 	// Import statement
 	VisitImportStatementNode(node ImportStatementNode) // import package
 
+	// Error handling visitors
+	VisitTryStatementNode(node TryStatementNode)     // try { ... } catch (err) { ... } finally { ... }
+	VisitThrowStatementNode(node ThrowStatementNode) // throw expr
+
+	// Concurrency visitors
+	VisitSpawnStatementNode(node SpawnStatementNode) // spawn worker(args)
+
+	// Generator visitors
+	VisitYieldStatementNode(node YieldStatementNode) // yield expr
+
 }
 
 // Node: base interface for all nodes of the AST
@@ -319,13 +340,21 @@ func (node *ParenthesizedExpressionNode) Expression() {
 // Example: var x = 10 or let name = "John"
 type DeclarativeStatementNode struct {
 	VarToken   lexer.Token              // The declaration keyword token (var/let)
-	Identifier IdentifierExpressionNode // The variable identifier being declared
+	Identifier IdentifierExpressionNode // The variable identifier being declared (empty for a pattern)
+	Pattern    *PatternNode             // The destructuring pattern being declared, nil for a single name
+	VarType    *std.TypeAnnotation      // The declared type (var x: int = 0), nil if not annotated
 	Expr       ExpressionNode           // The initialization expression
 	Value      std.GoMixObject          // The assigned value
 }
 
 // DeclarativeStatementNode.Literal(): string represenation of the node
 func (node *DeclarativeStatementNode) Literal() string {
+	if node.Pattern != nil {
+		return node.VarToken.Literal + " " + node.Pattern.Literal() + " = " + node.Expr.Literal()
+	}
+	if node.VarType != nil {
+		return node.VarToken.Literal + " " + node.Identifier.Name + ": " + node.VarType.String() + " = " + node.Expr.Literal()
+	}
 	return node.VarToken.Literal + " " + node.Identifier.Name + " = " + node.Expr.Literal()
 }
 
@@ -378,7 +407,7 @@ func (node *IdentifierExpressionNode) Expression() {
 // Example: return x + 5 or return "result"
 type ReturnStatementNode struct {
 	ReturnToken lexer.Token     // The 'return' keyword token
-	Expr        ExpressionNode  // The expression to return
+	Expr        ExpressionNode  // The expression to return (a TupleExpressionNode for return a, b)
 	Value       std.GoMixObject // The evaluated return value
 }
 
@@ -407,6 +436,7 @@ func (node *ReturnStatementNode) Expression() {
 type BlockStatementNode struct {
 	Statements []StatementNode // List of statements in the block
 	Value      std.GoMixObject // Value of the last expression in the block
+	RBrace     lexer.Token     // The closing '}' token (zero for blocks without braces)
 }
 
 // BlockStatementNode.Literal(): string represenation of the node
@@ -492,6 +522,7 @@ type IfExpressionNode struct {
 	ConditionValue std.GoMixObject    // Evaluated condition result
 	ThenBlock      BlockStatementNode // Block to execute if condition is true
 	ElseBlock      BlockStatementNode // Block to execute if condition is false (optional)
+	ElseToken      lexer.Token        // The 'else' keyword token (zero if there is no else)
 }
 
 // IfExpressionNode.Literal(): string represenation of the node
@@ -568,6 +599,39 @@ func (node *StringLiteralExpressionNode) Expression() {
 
 }
 
+// InterpolatedStringExpressionNode: represents a string literal with embedded expressions
+// Example: "Hello ${user.name}, you have ${len(items)} items"
+type InterpolatedStringExpressionNode struct {
+	Token lexer.Token      // The interpolated string token (at its opening quote), with the Literal() of the node
+	Texts []string         // The text around the embedded expressions: one more than Exprs, possibly empty
+	Exprs []ExpressionNode // The embedded expressions, in order
+	Value std.GoMixObject  // The string object value
+}
+
+// InterpolatedString.Literal(): string represenation of the node
+func (node *InterpolatedStringExpressionNode) Literal() string {
+	res := node.Texts[0]
+	for i, expr := range node.Exprs {
+		res += "${" + expr.Literal() + "}" + node.Texts[i+1]
+	}
+	return res
+}
+
+// InterpolatedString.Accept(): accepts a visitor (eg PrintVisitor)
+func (node *InterpolatedStringExpressionNode) Accept(visitor NodeVisitor) {
+	visitor.VisitInterpolatedStringExpressionNode(*node)
+}
+
+// InterpolatedString.Statement(): every expression is also a statement
+func (node *InterpolatedStringExpressionNode) Statement() {
+
+}
+
+// InterpolatedString.Expression(): every expression node is a node
+func (node *InterpolatedStringExpressionNode) Expression() {
+
+}
+
 // NilLiteralExpressionNode: represents a nil/null literal value
 // Used to represent the absence of a value or uninitialized state
 type NilLiteralExpressionNode struct {
@@ -598,24 +662,59 @@ func (node *NilLiteralExpressionNode) Expression() {
 // FunctionStatementNode: represents a function definition statement
 // Example: func add(x, y) { return x + y; }
 type FunctionStatementNode struct {
-	FuncToken  lexer.Token                 // The 'func' keyword token
-	FuncName   IdentifierExpressionNode    // The function name identifier
-	FuncParams []*IdentifierExpressionNode // List of parameter identifiers
-	FuncBody   BlockStatementNode          // The function body block
-	Value      std.GoMixObject             // The function object value
+	FuncToken    lexer.Token                 // The 'func' keyword token
+	FuncName     IdentifierExpressionNode    // The function name identifier
+	FuncParams   []*IdentifierExpressionNode // List of parameter identifiers
+	FuncDefaults []ExpressionNode            // Default values, parallel to FuncParams (nil where there is none)
+	FuncRest     bool                        // Whether the last parameter collects the remaining arguments (...rest)
+	ParamTypes   []*std.TypeAnnotation       // Declared parameter types, parallel to FuncParams (nil where there is none)
+	ReturnType   *std.TypeAnnotation         // The declared return type, nil if not annotated
+	FuncBody     BlockStatementNode          // The function body block
+	Generator    bool                        // Whether the body yields values (a generator function)
+	Value        std.GoMixObject             // The function object value
+}
+
+// FunctionStatementNode.Default(): the default value of the i-th parameter, or nil
+func (node *FunctionStatementNode) Default(i int) ExpressionNode {
+	if i < len(node.FuncDefaults) {
+		return node.FuncDefaults[i]
+	}
+	return nil
+}
+
+// FunctionStatementNode.ParamType(): the declared type of the i-th parameter, or nil
+func (node *FunctionStatementNode) ParamType(i int) *std.TypeAnnotation {
+	if i < len(node.ParamTypes) {
+		return node.ParamTypes[i]
+	}
+	return nil
 }
 
 // FunctionStatementNode.Literal(): string represenation of the node
 func (node *FunctionStatementNode) Literal() string {
 
 	funcParams := ""
-	for _, param := range node.FuncParams {
-		funcParams += param.Literal() + ","
+	for i, param := range node.FuncParams {
+		if node.FuncRest && i == len(node.FuncParams)-1 {
+			funcParams += "..."
+		}
+		funcParams += param.Literal()
+		if typ := node.ParamType(i); typ != nil {
+			funcParams += ": " + typ.String()
+		}
+		if def := node.Default(i); def != nil {
+			funcParams += "=" + def.Literal()
+		}
+		funcParams += ","
 	}
 	if len(funcParams) > 0 {
 		funcParams = funcParams[:len(funcParams)-1]
 	}
-	return node.FuncToken.Literal + " " + node.FuncName.Literal() + " (" + funcParams + ") " + node.FuncBody.Literal()
+	result := ""
+	if node.ReturnType != nil {
+		result = ": " + node.ReturnType.String()
+	}
+	return node.FuncToken.Literal + " " + node.FuncName.Literal() + " (" + funcParams + ")" + result + " " + node.FuncBody.Literal()
 }
 
 // FunctionStatementNode.Accept(): accepts a visitor (eg PrintVisitor)
@@ -645,23 +744,35 @@ func NewFunctionStatementNode() *FunctionStatementNode {
 }
 
 // CallExpressionNode: represents a function call expression
-// Example: myFunc(arg1, arg2) or print("hello")
+// Example: myFunc(arg1, arg2), print("hello"), makeAdder(1)(2) or obj.method()
 type CallExpressionNode struct {
-	FunctionIdentifier IdentifierExpressionNode // The function name being called
-	Arguments          []ExpressionNode         // List of argument expressions
-	Value              std.GoMixObject          // Return value from the function
+	Callee     ExpressionNode   // The expression evaluating to the function (a name, a member, a call, ...)
+	LeftParen  lexer.Token      // The '(' token opening the arguments
+	Arguments  []ExpressionNode // List of argument expressions
+	ArgNames   []string         // Names of the arguments (f(b: 2)), parallel to Arguments; nil if none is named
+	Value      std.GoMixObject  // Return value from the function
+	RightParen lexer.Token      // The ')' token closing the arguments
 }
 
 // CallExpressionNode.Literal(): string represenation of the node
 func (node *CallExpressionNode) Literal() string {
 	args := ""
-	for _, arg := range node.Arguments {
-		args += arg.Literal() + ","
+	for i, arg := range node.Arguments {
+		args += argumentLiteral(node.ArgNames, i, arg) + ","
 	}
 	if len(args) > 0 {
 		args = args[:len(args)-1]
 	}
-	return node.FunctionIdentifier.Literal() + "(" + args + ")"
+	return node.Callee.Literal() + "(" + args + ")"
+}
+
+// argumentLiteral: string representation of the i-th argument of a call,
+// prefixed with its name if it is a named argument
+func argumentLiteral(names []string, i int, arg ExpressionNode) string {
+	if i < len(names) && names[i] != "" {
+		return names[i] + ":" + arg.Literal()
+	}
+	return arg.Literal()
 }
 
 // CallExpressionNode.Accept(): accepts a visitor (eg PrintVisitor)
@@ -760,9 +871,11 @@ func (node *WhileLoopStatementNode) Statement() {
 // ArrayExpressionNode: represents an array literal expression
 // Example: [1, 2, 3] or ["a", "b", "c"]
 type ArrayExpressionNode struct {
+	Token    lexer.Token              // The opening '[' token
 	Name     IdentifierExpressionNode // Optional array identifier
 	Elements []ExpressionNode         // List of element expressions
 	Value    std.GoMixObject          // The array object value
+	RBracket lexer.Token              // The closing ']' token
 }
 
 // ArrayExpressionNode.Literal()
@@ -796,6 +909,7 @@ func (node *ArrayExpressionNode) Expression() {
 // IndexExpressionNode: represents array indexing operation
 // Example: arr[0], myArray[i], list[-1] (negative indexing supported)
 type IndexExpressionNode struct {
+	Token lexer.Token     // The '[' token
 	Left  ExpressionNode  // The array or indexable expression
 	Index ExpressionNode  // The index expression (can be negative)
 	Value std.GoMixObject // The element value at the index
@@ -824,6 +938,7 @@ func (node *IndexExpressionNode) Expression() {
 // SliceExpressionNode: represents array slicing operation
 // Example: arr[1:3], arr[:5], arr[2:] (Python-style slicing)
 type SliceExpressionNode struct {
+	Token lexer.Token     // The '[' token
 	Left  ExpressionNode  // The array or indexable expression
 	Start ExpressionNode  // The start index (can be nil for arr[:end])
 	End   ExpressionNode  // The end index (can be nil for arr[start:])
@@ -862,6 +977,7 @@ func (node *SliceExpressionNode) Expression() {
 // RangeExpressionNode: represents a range expression with inclusive bounds
 // Example: 2...5 creates a range from 2 to 5 (inclusive)
 type RangeExpressionNode struct {
+	Token lexer.Token     // The '...' token
 	Start ExpressionNode  // The start expression of the range
 	End   ExpressionNode  // The end expression of the range (inclusive)
 	Value std.GoMixObject // The Range object value
@@ -888,18 +1004,27 @@ func (node *RangeExpressionNode) Expression() {
 }
 
 // ForeachLoopStatementNode: represents a foreach loop statement
-// Example: foreach i in 2...10 { body } or foreach item in array { body }
+// Example: foreach i in 2...10 { body } or foreach k, v in myMap { body }
 type ForeachLoopStatementNode struct {
-	ForeachToken lexer.Token              // The 'foreach' keyword token
-	Iterator     IdentifierExpressionNode // The loop variable (e.g., 'i' or 'item')
-	Iterable     ExpressionNode           // The range or array to iterate over
-	Body         BlockStatementNode       // The loop body
-	Value        std.GoMixObject          // The result value
+	ForeachToken lexer.Token               // The 'foreach' keyword token
+	Key          *IdentifierExpressionNode // The key (index) variable, nil if not given (e.g., 'k')
+	Iterator     IdentifierExpressionNode  // The loop variable (e.g., 'i', 'item' or 'v'), empty for a pattern
+	Pattern      *PatternNode              // The pattern destructuring each value (e.g., '[x, y]'), nil if not given
+	Iterable     ExpressionNode            // The value to iterate over
+	Body         BlockStatementNode        // The loop body
+	Value        std.GoMixObject           // The result value
 }
 
 // ForeachLoopStatementNode.Literal()
 func (node *ForeachLoopStatementNode) Literal() string {
-	return "foreach " + node.Iterator.Name + " in " + node.Iterable.Literal() + " " + node.Body.Literal()
+	iterator := node.Iterator.Name
+	if node.Pattern != nil {
+		iterator = node.Pattern.Literal()
+	}
+	if node.Key != nil {
+		return "foreach " + node.Key.Name + ", " + iterator + " in " + node.Iterable.Literal() + " " + node.Body.Literal()
+	}
+	return "foreach " + iterator + " in " + node.Iterable.Literal() + " " + node.Body.Literal()
 }
 
 // ForeachLoopStatementNode.Accept()
@@ -915,9 +1040,11 @@ func (node *ForeachLoopStatementNode) Statement() {
 // MapExpressionNode: represents a map literal expression
 // Example: map{10: 20, 20: 30} or map{"name": "John", "age": 25}
 type MapExpressionNode struct {
+	Token  lexer.Token      // The 'map' keyword token
 	Keys   []ExpressionNode // List of key expressions
 	Values []ExpressionNode // List of value expressions (parallel to Keys)
 	Value  std.GoMixObject  // The map object value
+	RBrace lexer.Token      // The closing '}' token
 }
 
 // MapExpressionNode.Literal()
@@ -951,8 +1078,10 @@ func (node *MapExpressionNode) Expression() {
 // SetExpressionNode: represents a set literal expression
 // Example: set{1, 2, 3} or set{"a", "b", "c"}
 type SetExpressionNode struct {
+	Token    lexer.Token      // The 'set' keyword token
 	Elements []ExpressionNode // List of element expressions (duplicates will be removed)
 	Value    std.GoMixObject  // The set object value
+	RBrace   lexer.Token      // The closing '}' token
 }
 
 // SetExpressionNode.Literal()
@@ -988,14 +1117,33 @@ func (node *SetExpressionNode) Expression() {
 type StructDeclarationNode struct {
 	StructToken lexer.Token                 // The 'struct' keyword token
 	StructName  IdentifierExpressionNode    // The struct name identifier
+	Parent      *IdentifierExpressionNode   // The struct it extends (nil if none); may be qualified (util.Animal)
+	Interfaces  []*IdentifierExpressionNode // The interfaces it declares it implements
 	Fields      []*DeclarativeStatementNode // List of field declarations
 	Methods     []*FunctionStatementNode    // List of method definitions (function statements)
 	Value       std.GoMixObject             // The struct type object value
 }
 
+// StructDeclarationNode.Header(): the declaration up to the body, e.g. "struct Dog extends Animal implements Pet"
+func (node *StructDeclarationNode) Header() string {
+	res := node.StructToken.Literal + " " + node.StructName.Name
+	if node.Parent != nil {
+		res += " extends " + node.Parent.Name
+	}
+	for i, iface := range node.Interfaces {
+		if i == 0 {
+			res += " implements "
+		} else {
+			res += ", "
+		}
+		res += iface.Name
+	}
+	return res
+}
+
 // StructDeclarationNode.Literal()
 func (node *StructDeclarationNode) Literal() string {
-	res := node.StructToken.Literal + " " + node.StructName.Name + " {"
+	res := node.Header() + " {"
 	for _, field := range node.Fields {
 		res += field.Literal() + "; "
 	}
@@ -1021,13 +1169,99 @@ func (node *StructDeclarationNode) Expression() {
 
 }
 
+// InterfaceMethodNode: represents a method signature of an interface
+// Example: area() or resize(width, height)
+type InterfaceMethodNode struct {
+	Name   IdentifierExpressionNode    // The method name identifier
+	Params []*IdentifierExpressionNode // The parameter names
+}
+
+// InterfaceMethodNode.Literal()
+func (node *InterfaceMethodNode) Literal() string {
+	params := ""
+	for i, param := range node.Params {
+		if i > 0 {
+			params += ", "
+		}
+		params += param.Name
+	}
+	return node.Name.Name + "(" + params + ")"
+}
+
+// InterfaceDeclarationNode: represents an interface definition statement
+// Example: interface Shape { area(); perimeter(); }
+// Structs declare the interfaces they implement (struct Circle implements Shape { ... }),
+// and must then have every method of the interface.
+type InterfaceDeclarationNode struct {
+	InterfaceToken lexer.Token              // The 'interface' keyword token
+	InterfaceName  IdentifierExpressionNode // The interface name identifier
+	Methods        []*InterfaceMethodNode   // The method signatures
+	Value          std.GoMixObject          // The interface object value
+}
+
+// InterfaceDeclarationNode.Literal()
+func (node *InterfaceDeclarationNode) Literal() string {
+	res := node.InterfaceToken.Literal + " " + node.InterfaceName.Name + " {"
+	for _, method := range node.Methods {
+		res += method.Literal() + "; "
+	}
+	res += "}"
+	return res
+}
+
+// InterfaceDeclarationNode.Accept()
+func (node *InterfaceDeclarationNode) Accept(visitor NodeVisitor) {
+	visitor.VisitInterfaceDeclarationNode(*node)
+}
+
+// InterfaceDeclarationNode.Statement()
+func (node *InterfaceDeclarationNode) Statement() {
+
+}
+
+// InterfaceDeclarationNode.Expression()
+func (node *InterfaceDeclarationNode) Expression() {
+
+}
+
+// SuperExpressionNode: represents the 'super' keyword, the left operand of a
+// member access that looks the member up from the parent of the struct whose
+// method is running
+// Example: super.speak() calls the speak() method of the parent struct on 'this'
+type SuperExpressionNode struct {
+	Token lexer.Token     // The 'super' keyword token
+	Value std.GoMixObject // Always Nil (resolved at runtime)
+}
+
+// SuperExpressionNode.Literal()
+func (node *SuperExpressionNode) Literal() string {
+	return node.Token.Literal
+}
+
+// SuperExpressionNode.Accept()
+func (node *SuperExpressionNode) Accept(visitor NodeVisitor) {
+	visitor.VisitSuperExpressionNode(*node)
+}
+
+// SuperExpressionNode.Statement()
+func (node *SuperExpressionNode) Statement() {
+
+}
+
+// SuperExpressionNode.Expression()
+func (node *SuperExpressionNode) Expression() {
+
+}
+
 // NewCallExpressionNode: represents a struct instantiation expression
 // Example: new Person("Alice", 30) creates a new instance of the Person struct
 type NewCallExpressionNode struct {
 	NewToken   lexer.Token              // The 'new' keyword token
 	StructName IdentifierExpressionNode // The struct name being instantiated
 	Arguments  []ExpressionNode         // List of argument expressions for the constructor
+	ArgNames   []string                 // Names of the arguments, parallel to Arguments; nil if none is named
 	Value      std.GoMixObject          // The new struct instance object value
+	RightParen lexer.Token              // The ')' token closing the arguments
 }
 
 // NewCallExpressionNode.Literal()
@@ -1037,7 +1271,7 @@ func (node *NewCallExpressionNode) Literal() string {
 		if i > 0 {
 			args += ","
 		}
-		args += arg.Literal()
+		args += argumentLiteral(node.ArgNames, i, arg)
 	}
 	return node.NewToken.Literal + " " + node.StructName.Name + "(" + args + ")"
 }
@@ -1144,9 +1378,10 @@ type EnumDeclarationNode struct {
 // EnumMemberNode represents a single enum member
 // Example: RED or RED = 1
 type EnumMemberNode struct {
-	Name  string          // The member name
-	Value std.GoMixObject // The member value (auto-assigned or explicit)
-	Token lexer.Token     // The token for this member
+	Name       string          // The member name
+	Value      std.GoMixObject // The member value (auto-assigned or explicit)
+	Token      lexer.Token     // The token for this member
+	ValueToken lexer.Token     // The explicit value token (zero when auto-assigned)
 }
 
 // EnumMemberNode.Literal returns string representation of the enum member
@@ -1308,3 +1543,410 @@ func (ssn SwitchStatementNode) Statement() {}
 func (ssn SwitchStatementNode) Accept(visitor NodeVisitor) {
 	visitor.VisitSwitchStatementNode(ssn)
 }
+
+// TryStatementNode represents a try/catch/finally statement.
+// At least one of the catch or finally clauses is present.
+// Example: try { risky(); } catch (err) { println(err.message); } finally { cleanup(); }
+type TryStatementNode struct {
+	// Token is the "try" keyword token for error reporting.
+	Token lexer.Token
+
+	// TryBlock is the block of statements that may raise an error.
+	TryBlock BlockStatementNode
+
+	// CatchParam is the identifier bound to the caught error (nil for "catch { ... }").
+	CatchParam *IdentifierExpressionNode
+
+	// CatchBlock is the block run when the try block raises an error (nil if there is no catch clause).
+	CatchBlock *BlockStatementNode
+
+	// FinallyBlock is the block that always runs after the try and catch blocks (nil if absent).
+	FinallyBlock *BlockStatementNode
+
+	// Value stores the result of evaluating the try statement.
+	Value std.GoMixObject
+}
+
+// TryStatementNode.Literal(): string represenation of the node
+func (node *TryStatementNode) Literal() string {
+	res := "try " + node.TryBlock.Literal()
+	if node.CatchBlock != nil {
+		res += " catch "
+		if node.CatchParam != nil {
+			res += "(" + node.CatchParam.Name + ") "
+		}
+		res += node.CatchBlock.Literal()
+	}
+	if node.FinallyBlock != nil {
+		res += " finally " + node.FinallyBlock.Literal()
+	}
+	return res
+}
+
+// TryStatementNode.Accept(): accepts a visitor
+func (node *TryStatementNode) Accept(visitor NodeVisitor) {
+	visitor.VisitTryStatementNode(*node)
+}
+
+// TryStatementNode.Statement()
+func (node *TryStatementNode) Statement() {}
+
+// ThrowStatementNode represents a throw statement that raises an error.
+// Example: throw "invalid input" or throw err
+type ThrowStatementNode struct {
+	Token lexer.Token     // The 'throw' keyword token
+	Expr  ExpressionNode  // The expression whose value is thrown
+	Value std.GoMixObject // The evaluated thrown value (if known at parse time)
+}
+
+// ThrowStatementNode.Literal(): string represenation of the node
+func (node *ThrowStatementNode) Literal() string {
+	return node.Token.Literal + " " + node.Expr.Literal()
+}
+
+// ThrowStatementNode.Accept(): accepts a visitor
+func (node *ThrowStatementNode) Accept(visitor NodeVisitor) {
+	visitor.VisitThrowStatementNode(*node)
+}
+
+// ThrowStatementNode.Statement()
+func (node *ThrowStatementNode) Statement() {}
+
+// SpawnStatementNode represents a spawn statement that runs a call on a new goroutine.
+// The call is a function call, a builtin call, a method call or a package function call;
+// its callee and arguments are evaluated before the goroutine starts.
+// Example: spawn worker(i, results) or spawn counter.run(ch)
+type SpawnStatementNode struct {
+	Token lexer.Token    // The 'spawn' keyword token
+	Call  ExpressionNode // The call to run (a CallExpressionNode)
+}
+
+// SpawnStatementNode.Literal(): string represenation of the node
+func (node *SpawnStatementNode) Literal() string {
+	return node.Token.Literal + " " + node.Call.Literal()
+}
+
+// SpawnStatementNode.Accept(): accepts a visitor
+func (node *SpawnStatementNode) Accept(visitor NodeVisitor) {
+	visitor.VisitSpawnStatementNode(*node)
+}
+
+// SpawnStatementNode.Statement()
+func (node *SpawnStatementNode) Statement() {}
+
+// YieldStatementNode represents a yield statement, which hands a value to
+// the caller iterating over a generator and suspends the generator until the
+// next value is asked for.
+// Example: yield i * i
+type YieldStatementNode struct {
+	Token lexer.Token    // The 'yield' keyword token
+	Expr  ExpressionNode // The expression whose value is yielded
+}
+
+// YieldStatementNode.Literal(): string represenation of the node
+func (node *YieldStatementNode) Literal() string {
+	return node.Token.Literal + " " + node.Expr.Literal()
+}
+
+// YieldStatementNode.Accept(): accepts a visitor
+func (node *YieldStatementNode) Accept(visitor NodeVisitor) {
+	visitor.VisitYieldStatementNode(*node)
+}
+
+// YieldStatementNode.Statement()
+func (node *YieldStatementNode) Statement() {}
+
+// TupleExpressionNode: represents a tuple literal, written in parentheses or
+// as the values of a return statement
+// Example: (q, r) or return q, r
+type TupleExpressionNode struct {
+	LeftParen lexer.Token      // The '(' token (zero for the values of a return statement)
+	Elements  []ExpressionNode // List of element expressions
+	Value     std.GoMixObject  // The tuple object value
+}
+
+// TupleExpressionNode.Literal()
+func (node *TupleExpressionNode) Literal() string {
+	res := ""
+	for i, elem := range node.Elements {
+		if i > 0 {
+			res += ","
+		}
+		res += elem.Literal()
+	}
+	if node.LeftParen.Type == "" {
+		return res
+	}
+	return "(" + res + ")"
+}
+
+// TupleExpressionNode.Accept()
+func (node *TupleExpressionNode) Accept(visitor NodeVisitor) {
+	visitor.VisitTupleExpressionNode(*node)
+}
+
+// TupleExpressionNode.Statement()
+func (node *TupleExpressionNode) Statement() {
+
+}
+
+// TupleExpressionNode.Expression()
+func (node *TupleExpressionNode) Expression() {
+
+}
+
+// PatternNode: represents a destructuring pattern, binding the parts of a
+// value to names in a declaration, an assignment or a foreach head
+// Example: (q, r), [first, ...rest] or {name, age}
+type PatternNode struct {
+	Open  lexer.Token                 // '(' or '[' to bind elements by position, '{' to bind map keys or struct fields by name
+	Names []*IdentifierExpressionNode // The names bound, in order
+	Rest  *IdentifierExpressionNode   // The name bound to what the other names leave (...rest), nil if none
+}
+
+// PatternNode.Bindings(): all the names the pattern binds, the rest last
+func (node *PatternNode) Bindings() []*IdentifierExpressionNode {
+	if node.Rest == nil {
+		return node.Names
+	}
+	return append(node.Names[:len(node.Names):len(node.Names)], node.Rest)
+}
+
+// PatternNode.Literal()
+func (node *PatternNode) Literal() string {
+	res := ""
+	for i, name := range node.Names {
+		if i > 0 {
+			res += ","
+		}
+		res += name.Name
+	}
+	if node.Rest != nil {
+		if len(node.Names) > 0 {
+			res += ","
+		}
+		res += "..." + node.Rest.Name
+	}
+	return node.Open.Literal + res + node.Close()
+}
+
+// PatternNode.Close(): the bracket closing the pattern
+func (node *PatternNode) Close() string {
+	return string(closingBracket(node.Open.Type))
+}
+
+// PatternNode.Accept()
+func (node *PatternNode) Accept(visitor NodeVisitor) {
+	visitor.VisitPatternNode(*node)
+}
+
+// PatternNode.Statement()
+func (node *PatternNode) Statement() {
+
+}
+
+// PatternNode.Expression()
+func (node *PatternNode) Expression() {
+
+}
+
+// MatchExpressionNode: represents a match expression, whose value is the
+// result of the first arm whose pattern matches the subject (and whose guard,
+// if any, holds)
+// Example: match (shape) { Circle{r} => 3.14 * r * r, [w, h] if w == h => w * w, _ => 0 }
+type MatchExpressionNode struct {
+	Token   lexer.Token     // The 'match' keyword token
+	Subject ExpressionNode  // The value matched, in parentheses
+	Arms    []*MatchArmNode // The arms, tried in order
+	RBrace  lexer.Token     // The closing brace
+	Value   std.GoMixObject // The value of the expression (known at runtime)
+}
+
+// MatchExpressionNode.Literal()
+func (node *MatchExpressionNode) Literal() string {
+	res := "match " + node.Subject.Literal() + " {"
+	for i, arm := range node.Arms {
+		if i > 0 {
+			res += ","
+		}
+		res += " " + arm.Literal()
+	}
+	return res + " }"
+}
+
+// MatchExpressionNode.Accept()
+func (node *MatchExpressionNode) Accept(visitor NodeVisitor) {
+	visitor.VisitMatchExpressionNode(*node)
+}
+
+// MatchExpressionNode.Statement()
+func (node *MatchExpressionNode) Statement() {
+
+}
+
+// MatchExpressionNode.Expression()
+func (node *MatchExpressionNode) Expression() {
+
+}
+
+// MatchArmNode: represents an arm of a match expression, pattern [if guard] => body
+type MatchArmNode struct {
+	Pattern *MatchPatternNode // The pattern the subject must match
+	Guard   ExpressionNode    // The condition after 'if', nil if none
+	Body    ExpressionNode    // The result: an expression or a block (*BlockStatementNode)
+}
+
+// MatchArmNode.Literal()
+func (node *MatchArmNode) Literal() string {
+	res := node.Pattern.Literal()
+	if node.Guard != nil {
+		res += " if " + node.Guard.Literal()
+	}
+	return res + " => " + node.Body.Literal()
+}
+
+// MatchPatternKind: the kind of a match pattern
+type MatchPatternKind int
+
+const (
+	WildcardPattern    MatchPatternKind = iota // _ matches anything
+	BindingPattern                             // name matches anything, binding it to the name
+	ValuePattern                               // 42, "text", nil, Color.RED match equal values
+	RangePattern                               // 1...5 matches the numbers (or chars) between the bounds, inclusive
+	SequencePattern                            // [a, b, ...rest] or (a, b) match arrays, lists and tuples element by element
+	MapPattern                                 // {key, "other": p, ...rest} matches maps having the keys
+	StructPattern                              // Point{x, y: 0} matches instances of a struct (or of an interface)
+	AlternativePattern                         // p1 | p2 matches what any of the patterns matches
+)
+
+// MatchPatternNode: represents a pattern of a match arm
+type MatchPatternNode struct {
+	Token    lexer.Token               // The first token of the pattern ('[' or '(' for sequences)
+	Kind     MatchPatternKind          // What the pattern matches
+	Name     *IdentifierExpressionNode // BindingPattern: the name bound; StructPattern: the (qualified) struct name
+	Value    ExpressionNode            // ValuePattern: the value; RangePattern: the start
+	End      ExpressionNode            // RangePattern: the end
+	Keys     []string                  // MapPattern, StructPattern: the key or field matched by each element
+	Elements []*MatchPatternNode       // The sub-patterns (the alternatives of an AlternativePattern)
+	Rest     *IdentifierExpressionNode // SequencePattern, MapPattern: the name bound to the rest (...rest), nil if none
+}
+
+// MatchPatternNode.Bindings(): the names the pattern binds, in order
+// (for alternatives, the names of the first one, which all bind)
+func (node *MatchPatternNode) Bindings() []*IdentifierExpressionNode {
+	names := make([]*IdentifierExpressionNode, 0)
+	switch node.Kind {
+	case BindingPattern:
+		names = append(names, node.Name)
+	case AlternativePattern:
+		names = append(names, node.Elements[0].Bindings()...)
+	default:
+		for _, elem := range node.Elements {
+			names = append(names, elem.Bindings()...)
+		}
+		if node.Rest != nil && node.Rest.Name != "_" {
+			names = append(names, node.Rest)
+		}
+	}
+	return names
+}
+
+// MatchPatternNode.Irrefutable(): whether the pattern matches any value
+func (node *MatchPatternNode) Irrefutable() bool {
+	switch node.Kind {
+	case WildcardPattern, BindingPattern:
+		return true
+	case AlternativePattern:
+		for _, alt := range node.Elements {
+			if alt.Irrefutable() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchPatternNode.ValuePatterns(): the value patterns matched against the
+// whole value (those of the alternatives included)
+func (node *MatchPatternNode) ValuePatterns() []*MatchPatternNode {
+	switch node.Kind {
+	case ValuePattern:
+		return []*MatchPatternNode{node}
+	case AlternativePattern:
+		patterns := make([]*MatchPatternNode, 0)
+		for _, alt := range node.Elements {
+			patterns = append(patterns, alt.ValuePatterns()...)
+		}
+		return patterns
+	}
+	return nil
+}
+
+// MatchPatternNode.Literal()
+func (node *MatchPatternNode) Literal() string {
+	switch node.Kind {
+	case WildcardPattern:
+		return "_"
+	case BindingPattern:
+		return node.Name.Name
+	case ValuePattern:
+		return node.Value.Literal()
+	case RangePattern:
+		return node.Value.Literal() + "..." + node.End.Literal()
+	case AlternativePattern:
+		res := ""
+		for i, alt := range node.Elements {
+			if i > 0 {
+				res += " | "
+			}
+			res += alt.Literal()
+		}
+		return res
+	}
+	res := ""
+	if node.Kind == StructPattern {
+		res = node.Name.Name
+	}
+	open := node.Token.Literal
+	if node.Kind != SequencePattern {
+		open = "{"
+	}
+	res += open
+	for i, elem := range node.Elements {
+		if i > 0 {
+			res += ", "
+		}
+		if node.Kind != SequencePattern {
+			if elem.Kind == BindingPattern && elem.Name.Name == node.Keys[i] {
+				res += node.Keys[i]
+				continue
+			}
+			res += patternKey(node.Keys[i]) + ": "
+		}
+		res += elem.Literal()
+	}
+	if node.Rest != nil {
+		if len(node.Elements) > 0 {
+			res += ", "
+		}
+		res += "..." + node.Rest.Name
+	} else if open == "(" && len(node.Elements) == 1 {
+		res += "," // (p,) is a tuple of one element, (p) a grouped pattern
+	}
+	return res + string(closingBracket(lexer.TokenType(open)))
+}
+
+// patternKey returns a key of a map pattern as written in the source: bare if
+// it is a name, quoted otherwise (e.g., "first name").
+func patternKey(key string) string {
+	if key == "" || lexer.KEYWORDS_MAP[key] != "" {
+		return strconv.Quote(key)
+	}
+	for i, c := range key {
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return strconv.Quote(key)
+		}
+	}
+	return key
+}
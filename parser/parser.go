@@ -65,6 +65,24 @@ type Parser struct {
 	// Collect parsing errors instead of panicking
 	// This allows reporting multiple errors in a single parse
 	Errors []string
+
+	// Source lines covered by each parsed statement (and struct member)
+	// Tools such as the formatter use them to place comments
+	Spans map[StatementNode]Span
+
+	// The number of errors of the lexer already added to Errors
+	lexErrors int
+
+	// The function whose body is being parsed (nil outside functions)
+	// A yield statement makes it a generator function
+	function *FunctionStatementNode
+}
+
+// Span is the range of source lines covered by a statement, from the line
+// of its first token to the line of its last token (both 1-indexed).
+type Span struct {
+	Line    int // Line of the first token
+	EndLine int // Line of the last token
 }
 
 // NewParser creates and initializes a new Parser instance.
@@ -113,6 +131,7 @@ func (par *Parser) init() {
 	par.LetVars = make(map[string]bool)
 	par.LetTypes = make(map[string]std.GoMixType)
 	par.Errors = make([]string, 0)
+	par.Spans = make(map[StatementNode]Span)
 
 	// Register unary/prefix parsing functions
 	// These handle tokens that can start an expression
@@ -136,8 +155,11 @@ func (par *Parser) init() {
 	// Boolean literals: true, false
 	par.registerUnaryFuncs(par.parseBooleanLiteral, lexer.TRUE_KEY, lexer.FALSE_KEY)
 
-	// String literals: "hello"
-	par.registerUnaryFuncs(par.parseStringLiteral, lexer.STRING_LIT)
+	// String literals: "hello", `raw`
+	par.registerUnaryFuncs(par.parseStringLiteral, lexer.STRING_LIT, lexer.RAW_STRING_LIT)
+
+	// Interpolated string literals: "Hi ${name}"
+	par.registerUnaryFuncs(par.parseInterpolatedString, lexer.INTERP_STRING_LIT)
 
 	// Nil literal: nil
 	par.registerUnaryFuncs(par.parseNilLiteral, lexer.NIL_LIT)
@@ -157,11 +179,14 @@ func (par *Parser) init() {
 	// Control flow: if statements
 	par.registerUnaryFuncs(par.parseIfStatement, lexer.IF_KEY)
 
+	// Match expressions: match (value) { pattern => result, ... }
+	par.registerUnaryFuncs(par.parseMatchExpression, lexer.MATCH_KEY)
+
 	// Function expressions: func(params) { body }
 	par.registerUnaryFuncs(par.parseFunctionAssignment, lexer.FUNC_KEY)
 
-	// Boolean/comparison operators: &&, ||, <, >, <=, >=, ==, !=
-	par.registerBinaryFuncs(par.parseBooleanExpression, lexer.AND_OP, lexer.OR_OP, lexer.GT_OP, lexer.LT_OP, lexer.GE_OP, lexer.LE_OP, lexer.EQ_OP, lexer.NE_OP, lexer.STRICT_EQ_OP, lexer.STRICT_NE_OP)
+	// Boolean/comparison operators: &&, ||, <, >, <=, >=, ==, !=, instanceof
+	par.registerBinaryFuncs(par.parseBooleanExpression, lexer.AND_OP, lexer.OR_OP, lexer.GT_OP, lexer.LT_OP, lexer.GE_OP, lexer.LE_OP, lexer.EQ_OP, lexer.NE_OP, lexer.STRICT_EQ_OP, lexer.STRICT_NE_OP, lexer.INSTANCEOF_KEY)
 
 	// Assignment operators: =, +=, -=, *=, /=, %=, &=, |=, ^=, <<=, >>=
 	par.registerBinaryFuncs(par.parseAssignmentExpression, lexer.ASSIGN_OP, lexer.PLUS_ASSIGN, lexer.MINUS_ASSIGN, lexer.MUL_ASSIGN, lexer.DIV_ASSIGN, lexer.MOD_ASSIGN,
@@ -188,9 +213,15 @@ func (par *Parser) init() {
 	// enum keyword for enum declarations: enum Name { MEMBER1, MEMBER2 }
 	par.registerUnaryFuncs(par.parseEnumDeclaration, lexer.ENUM_KEY)
 
+	// super keyword for the methods of the parent struct: super.method(args)
+	par.registerUnaryFuncs(par.parseSuperExpression, lexer.SUPER_KEY)
+
 	// memebr access operator: obj.field or obj.method()
 	par.registerBinaryFuncs(par.parseMemberAccess, lexer.DOT_OP)
 
+	// Call operator on any callee: f(x), makeAdder(1)(2), obj.method()
+	par.registerBinaryFuncs(par.parseCallExpression, lexer.LEFT_PAREN)
+
 	// Prime the token lookahead by advancing twice
 	// After this, CurrToken and NextToken are both valid
 	par.advance()
@@ -207,6 +238,9 @@ func (par *Parser) init() {
 func (par *Parser) advance() {
 	par.CurrToken = par.NextToken
 	par.NextToken = par.Lex.NextToken()
+	for ; par.lexErrors < len(par.Lex.Errors); par.lexErrors++ {
+		par.addError(par.Lex.Errors[par.lexErrors])
+	}
 }
 
 // expectAdvance checks if the next token matches the expected type,
@@ -408,7 +442,20 @@ func (par *Parser) parseInternal(currPrecedence int) ExpressionNode {
 //   - For loops
 //   - While loops
 //   - Expression statements (any expression followed by semicolon)
+//
+// The lines covered by the statement are recorded in Spans.
 func (par *Parser) parseStatement() StatementNode {
+	line := par.CurrToken.Line
+	stmt := par.parseStatementNode()
+	if stmt != nil {
+		par.Spans[stmt] = Span{Line: line, EndLine: par.CurrToken.Line}
+	}
+	return stmt
+}
+
+// parseStatementNode dispatches on the current token to the parser of the
+// statement it starts.
+func (par *Parser) parseStatementNode() StatementNode {
 	switch par.CurrToken.Type {
 
 	// ignore semicolons
@@ -426,8 +473,19 @@ func (par *Parser) parseStatement() StatementNode {
 		return par.parseDeclarativeStatement()
 
 	// {.....}
+	// {name, age} = person; // destructuring assignment
 	case lexer.LEFT_BRACE:
+		if par.isPatternAssignment() {
+			return par.parsePatternAssignment()
+		}
 		return par.parseBlockStatement()
+
+	// (a, b) = (b, a); [first, ...rest] = items; // destructuring assignment
+	case lexer.LEFT_PAREN, lexer.LEFT_BRACKET:
+		if par.isPatternAssignment() {
+			return par.parsePatternAssignment()
+		}
+		return par.parseExpression()
 	// if (condition) { ... } [ [else if () {...} ] [else if () {...} ]... [else { ... }] ]
 	case lexer.IF_KEY:
 		return par.parseIfStatement()
@@ -452,6 +510,10 @@ func (par *Parser) parseStatement() StatementNode {
 	case lexer.STRUCT_KEY:
 		return par.parseStructDeclaration()
 
+	// interface InterfaceName { method1(); method2(params); }
+	case lexer.INTERFACE_KEY:
+		return par.parseInterfaceDeclaration()
+
 	// break;
 	case lexer.BREAK_KEY:
 		return par.parseBreakStatement()
@@ -468,6 +530,22 @@ func (par *Parser) parseStatement() StatementNode {
 	case lexer.SWITCH_KEY:
 		return par.parseSwitchStatement()
 
+	// try { ... } catch (err) { ... } finally { ... }
+	case lexer.TRY_KEY:
+		return par.parseTryStatement()
+
+	// throw expr;
+	case lexer.THROW_KEY:
+		return par.parseThrowStatement()
+
+	// spawn worker(args);
+	case lexer.SPAWN_KEY:
+		return par.parseSpawnStatement()
+
+	// yield expr;
+	case lexer.YIELD_KEY:
+		return par.parseYieldStatement()
+
 	default:
 		return par.parseExpression()
 	}
@@ -31,7 +31,7 @@ import (
 //	["hello", "world"]
 //	[1 + 2, 3 * 4, func() { return 5; }()]
 func (par *Parser) parseArrayExpressionNode() ExpressionNode {
-	arrayNode := &ArrayExpressionNode{}
+	arrayNode := &ArrayExpressionNode{Token: par.CurrToken}
 	arrayElements := make([]ExpressionNode, 0)
 	arrayNode.Elements = arrayElements
 
@@ -41,6 +41,7 @@ func (par *Parser) parseArrayExpressionNode() ExpressionNode {
 	}
 	par.advance()
 	if par.CurrToken.Type == lexer.RIGHT_BRACKET {
+		arrayNode.RBracket = par.CurrToken
 		return arrayNode
 	}
 	for par.CurrToken.Type != lexer.RIGHT_BRACKET {
@@ -62,6 +63,7 @@ func (par *Parser) parseArrayExpressionNode() ExpressionNode {
 			par.advance()
 		}
 	}
+	arrayNode.RBracket = par.CurrToken
 	return arrayNode
 }
 
@@ -89,12 +91,14 @@ func (par *Parser) parseArrayExpressionNode() ExpressionNode {
 //	arr[:]      - Copy entire array
 func (par *Parser) parseIndexExpression(left ExpressionNode) ExpressionNode {
 	// current token is [
+	bracket := par.CurrToken
 	par.advance() // move past [
 
 	// Check for empty slice [:] or [:end]
 	if par.CurrToken.Type == lexer.COLON_DELIM {
 		// This is a slice with no start: arr[:end] or arr[:]
 		sliceNode := &SliceExpressionNode{
+			Token: bracket,
 			Left:  left,
 			Start: nil,
 		}
@@ -127,6 +131,7 @@ func (par *Parser) parseIndexExpression(left ExpressionNode) ExpressionNode {
 	if par.NextToken.Type == lexer.COLON_DELIM {
 		// This is a slice: arr[start:end] or arr[start:]
 		sliceNode := &SliceExpressionNode{
+			Token: bracket,
 			Left:  left,
 			Start: firstExpr,
 		}
@@ -157,6 +162,7 @@ func (par *Parser) parseIndexExpression(left ExpressionNode) ExpressionNode {
 
 	// This is a regular index expression
 	indexNode := &IndexExpressionNode{
+		Token: bracket,
 		Left:  left,
 		Index: firstExpr,
 	}
@@ -189,6 +195,7 @@ func (par *Parser) parseIndexExpression(left ExpressionNode) ExpressionNode {
 //	x...y    - Range from x to y (inclusive)
 func (par *Parser) parseRangeExpression(left ExpressionNode) ExpressionNode {
 	// Current token is RANGE_OP (...)
+	rangeToken := par.CurrToken
 	par.advance() // Move past ...
 
 	// Parse the right operand (end of range)
@@ -212,6 +219,7 @@ func (par *Parser) parseRangeExpression(left ExpressionNode) ExpressionNode {
 	}
 
 	return &RangeExpressionNode{
+		Token: rangeToken,
 		Start: left,
 		End:   right,
 		Value: rangeVal,
@@ -237,6 +245,7 @@ func (par *Parser) parseRangeExpression(left ExpressionNode) ExpressionNode {
 //	map{1: "one", 2: "two", 3: "three"}
 func (par *Parser) parseMapLiteral() ExpressionNode {
 	mapNode := &MapExpressionNode{
+		Token:  par.CurrToken,
 		Keys:   make([]ExpressionNode, 0),
 		Values: make([]ExpressionNode, 0),
 	}
@@ -250,6 +259,7 @@ func (par *Parser) parseMapLiteral() ExpressionNode {
 	// Check for empty map
 	if par.NextToken.Type == lexer.RIGHT_BRACE {
 		par.advance() // Move to }
+		mapNode.RBrace = par.CurrToken
 		return mapNode
 	}
 
@@ -296,25 +306,37 @@ func (par *Parser) parseMapLiteral() ExpressionNode {
 		}
 	}
 
+	mapNode.RBrace = par.CurrToken
 	return mapNode
 }
 
 // parseMapKeyword dispatches between map literals and function calls.
+// Before '(' the keyword names a function, e.g. map(...).
 func (par *Parser) parseMapKeyword() ExpressionNode {
 	if par.NextToken.Type == lexer.LEFT_PAREN {
-		return par.parseCallExpression()
+		return par.parseKeywordCallee()
 	}
 	return par.parseMapLiteral()
 }
 
 // parseSetKeyword dispatches between set literals and function calls.
+// Before '(' the keyword names a function, e.g. set(...).
 func (par *Parser) parseSetKeyword() ExpressionNode {
 	if par.NextToken.Type == lexer.LEFT_PAREN {
-		return par.parseCallExpression()
+		return par.parseKeywordCallee()
 	}
 	return par.parseSetLiteral()
 }
 
+// parseKeywordCallee parses a keyword used as the name of a called function.
+func (par *Parser) parseKeywordCallee() ExpressionNode {
+	return &IdentifierExpressionNode{
+		Token: par.CurrToken,
+		Name:  par.CurrToken.Literal,
+		Value: &std.Nil{},
+	}
+}
+
 // parseSetLiteral parses set literal expressions.
 // Set literals use the syntax: set{value1, value2, value3, ...}
 // Sets automatically remove duplicates and maintain unique values.
@@ -335,6 +357,7 @@ func (par *Parser) parseSetKeyword() ExpressionNode {
 //	set{1, 2, 2, 3}  // Duplicates will be removed during evaluation
 func (par *Parser) parseSetLiteral() ExpressionNode {
 	setNode := &SetExpressionNode{
+		Token:    par.CurrToken,
 		Elements: make([]ExpressionNode, 0),
 	}
 
@@ -347,6 +370,7 @@ func (par *Parser) parseSetLiteral() ExpressionNode {
 	// Check for empty set
 	if par.NextToken.Type == lexer.RIGHT_BRACE {
 		par.advance() // Move to }
+		setNode.RBrace = par.CurrToken
 		return setNode
 	}
 
@@ -380,5 +404,6 @@ func (par *Parser) parseSetLiteral() ExpressionNode {
 		}
 	}
 
+	setNode.RBrace = par.CurrToken
 	return setNode
 }
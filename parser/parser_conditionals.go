@@ -7,6 +7,8 @@ Contact : akashmaji(@iisc.ac.in)
 package parser
 
 import (
+	"fmt"
+
 	"github.com/akashmaji946/go-mix/lexer"
 	"github.com/akashmaji946/go-mix/std"
 )
@@ -46,6 +48,7 @@ func (par *Parser) parseIfStatement() ExpressionNode {
 	ifNode.ThenBlock = *par.parseBlockStatement()
 	if par.NextToken.Type == lexer.ELSE_KEY {
 		par.advance() // consume closing brace of if block
+		ifNode.ElseToken = par.CurrToken
 		par.advance() // consume else
 		if par.CurrToken.Type == lexer.IF_KEY {
 			// else if case
@@ -324,3 +327,99 @@ func (par *Parser) parseCaseBody() BlockStatementNode {
 	}
 	return body
 }
+
+// parseMatchExpression parses a match expression, whose value is the result
+// of the first arm whose pattern matches the value in parentheses.
+//
+// Syntax:
+//
+//	match (expression) { pattern => result, pattern if guard => { block }, ... }
+//
+// Returns:
+//
+//	A MatchExpressionNode, or nil on a syntax error
+//
+// The arms are separated by commas, optional after a block. The names bound
+// by the pattern of an arm are visible in its guard and its result.
+//
+// Examples:
+//
+//	match (n) { 0 => "zero", 1...9 => "digit", _ => "big" }
+//	match (point) { (0, 0) => "origin", (x, 0) | (0, x) => "axis", _ => "plane" }
+func (par *Parser) parseMatchExpression() ExpressionNode {
+	node := &MatchExpressionNode{Token: par.CurrToken, Arms: make([]*MatchArmNode, 0), Value: &std.Nil{}}
+	if !par.expectAdvance(lexer.LEFT_PAREN) {
+		return nil
+	}
+	node.Subject = par.parseParenthesizedExpression()
+	if node.Subject == nil {
+		return nil
+	}
+	if !par.expectAdvance(lexer.LEFT_BRACE) {
+		return nil
+	}
+	for par.NextToken.Type != lexer.RIGHT_BRACE {
+		par.advance()
+		arm := par.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		node.Arms = append(node.Arms, arm)
+		if par.NextToken.Type == lexer.COMMA_DELIM {
+			par.advance() // Consume comma
+			continue
+		}
+		if _, isBlock := arm.Body.(*BlockStatementNode); !isBlock {
+			break
+		}
+	}
+	if !par.expectAdvance(lexer.RIGHT_BRACE) {
+		return nil
+	}
+	node.RBrace = par.CurrToken
+	if len(node.Arms) == 0 {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: match expression without arms",
+			node.Token.Line, node.Token.Column))
+		return nil
+	}
+	return node
+}
+
+// parseMatchArm parses an arm of a match expression: pattern [if guard] =>
+// result. The lines of the arm are recorded in Spans, under its result.
+func (par *Parser) parseMatchArm() *MatchArmNode {
+	line := par.CurrToken.Line
+	pattern := par.parseMatchPattern()
+	if pattern == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, name := range pattern.Bindings() {
+		if seen[name.Name] {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: name (%s) is bound more than once in a pattern",
+				name.Token.Line, name.Token.Column, name.Name))
+			return nil
+		}
+		seen[name.Name] = true
+		delete(par.Env, name.Name) // only known when the program runs
+	}
+	arm := &MatchArmNode{Pattern: pattern}
+	if par.NextToken.Type == lexer.IF_KEY {
+		par.advance() // Consume 'if'
+		par.advance()
+		if arm.Guard = par.parseExpression(); arm.Guard == nil {
+			return nil
+		}
+	}
+	if !par.expectAdvance(lexer.ARROW_DELIM) {
+		return nil
+	}
+	par.advance()
+	if par.CurrToken.Type == lexer.LEFT_BRACE {
+		arm.Body = par.parseBlockStatement()
+	} else if arm.Body = par.parseExpression(); arm.Body == nil {
+		return nil
+	}
+	par.Spans[arm.Body] = Span{Line: line, EndLine: par.CurrToken.Line}
+	return arm
+}
@@ -19,6 +19,7 @@ import (
 // Syntax:
 //
 //	return expression;
+//	return expression1, expression2, ...;  (returns a tuple)
 //
 // Returns:
 //
@@ -30,6 +31,7 @@ import (
 //		return 42;
 //		return x + y;
 //		return func() { return 5; }();
+//		return quotient, remainder;
 func (par *Parser) parseReturnStatement() ExpressionNode {
 	returnToken := par.CurrToken
 	par.advance()
@@ -47,6 +49,13 @@ func (par *Parser) parseReturnStatement() ExpressionNode {
 	if expr == nil {
 		return nil
 	}
+	// Several values are returned as a tuple
+	if par.NextToken.Type == lexer.COMMA_DELIM {
+		expr = par.parseTupleElements(&TupleExpressionNode{Elements: []ExpressionNode{expr}, Value: &std.Nil{}})
+		if expr == nil {
+			return nil
+		}
+	}
 	// evaluating the expression
 	val := parseEval(par, expr)
 	return &ReturnStatementNode{
@@ -111,3 +120,160 @@ func (par *Parser) parseImportStatement() StatementNode {
 		Alias: alias,
 	}
 }
+
+// parseTryStatement parses a try/catch/finally statement.
+//
+// Syntax:
+//
+//	try { ... } catch (err) { ... }
+//	try { ... } catch { ... }
+//	try { ... } finally { ... }
+//	try { ... } catch (err) { ... } finally { ... }
+//
+// The catch parameter is optional; at least one of catch or finally is required.
+func (par *Parser) parseTryStatement() StatementNode {
+	tryNode := &TryStatementNode{
+		Token: par.CurrToken,
+		Value: &std.Nil{},
+	}
+
+	// Parse the try block
+	if !par.expectAdvance(lexer.LEFT_BRACE) {
+		return nil
+	}
+	tryNode.TryBlock = *par.parseBlockStatement()
+
+	// Optional catch clause
+	if par.NextToken.Type == lexer.CATCH_KEY {
+		par.advance() // move to 'catch'
+		if par.NextToken.Type == lexer.LEFT_PAREN {
+			par.advance() // move to '('
+			if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+				return nil
+			}
+			tryNode.CatchParam = &IdentifierExpressionNode{
+				Token: par.CurrToken,
+				Name:  par.CurrToken.Literal,
+				Value: &std.Nil{},
+				Type:  "var",
+			}
+			if !par.expectAdvance(lexer.RIGHT_PAREN) {
+				return nil
+			}
+		}
+		if !par.expectAdvance(lexer.LEFT_BRACE) {
+			return nil
+		}
+		tryNode.CatchBlock = par.parseBlockStatement()
+	}
+
+	// Optional finally clause
+	if par.NextToken.Type == lexer.FINALLY_KEY {
+		par.advance() // move to 'finally'
+		if !par.expectAdvance(lexer.LEFT_BRACE) {
+			return nil
+		}
+		tryNode.FinallyBlock = par.parseBlockStatement()
+	}
+
+	if tryNode.CatchBlock == nil && tryNode.FinallyBlock == nil {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: expected 'catch' or 'finally' after try block, got %s",
+			par.NextToken.Line, par.NextToken.Column, par.NextToken.Type))
+		return nil
+	}
+
+	return tryNode
+}
+
+// parseThrowStatement parses a throw statement.
+//
+// Syntax:
+//
+//	throw "something went wrong";
+//	throw err;
+func (par *Parser) parseThrowStatement() StatementNode {
+	throwToken := par.CurrToken
+	par.advance()
+
+	if par.CurrToken.Type == lexer.SEMICOLON_DELIM || par.CurrToken.Type == lexer.EOF_TYPE {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: expected expression after 'throw'",
+			throwToken.Line, throwToken.Column))
+		return nil
+	}
+
+	expr := par.parseExpression()
+	if expr == nil {
+		return nil
+	}
+	return &ThrowStatementNode{
+		Token: throwToken,
+		Expr:  expr,
+		Value: parseEval(par, expr),
+	}
+}
+
+// parseYieldStatement parses a yield statement, which makes the function
+// it is in a generator function.
+//
+// Syntax:
+//
+//	yield expr;
+//
+// Returns:
+//
+//	A YieldStatementNode, or nil on a syntax error (including a yield
+//	outside a function)
+func (par *Parser) parseYieldStatement() StatementNode {
+	yieldToken := par.CurrToken
+	if par.function == nil {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: yield outside a function",
+			yieldToken.Line, yieldToken.Column))
+		return nil
+	}
+	par.advance()
+
+	if par.CurrToken.Type == lexer.SEMICOLON_DELIM || par.CurrToken.Type == lexer.EOF_TYPE {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: expected expression after 'yield'",
+			yieldToken.Line, yieldToken.Column))
+		return nil
+	}
+
+	expr := par.parseExpression()
+	if expr == nil {
+		return nil
+	}
+	par.function.Generator = true
+	return &YieldStatementNode{
+		Token: yieldToken,
+		Expr:  expr,
+	}
+}
+
+// parseSpawnStatement parses a spawn statement.
+//
+// Syntax:
+//
+//	spawn worker(1, results);
+//	spawn counter.run(ch);
+//	spawn sync.wait_waitgroup(wg);
+//
+// The spawned expression must be a call: a function, builtin or package
+// function call, or a method call on an object.
+func (par *Parser) parseSpawnStatement() StatementNode {
+	spawnToken := par.CurrToken
+	par.advance()
+
+	expr := par.parseExpression()
+	if expr == nil {
+		return nil
+	}
+	if _, isCall := expr.(*CallExpressionNode); !isCall {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: expected a function call after 'spawn', got %s",
+			spawnToken.Line, spawnToken.Column, expr.Literal()))
+		return nil
+	}
+	return &SpawnStatementNode{
+		Token: spawnToken,
+		Call:  expr,
+	}
+}
@@ -7,6 +7,10 @@ Contact : akashmaji(@iisc.ac.in)
 package parser
 
 import (
+	"math"
+	"math/big"
+	"strings"
+
 	"github.com/akashmaji946/go-mix/lexer"
 	"github.com/akashmaji946/go-mix/std"
 )
@@ -31,23 +35,35 @@ func (par *Parser) parseExpression() ExpressionNode {
 // Syntax:
 //
 //	(expression)
+//	(expression1, expression2, ...)  (tuple literal)
 //
 // Returns:
 //
-//	A ParenthesizedExpressionNode containing the inner expression
+//	A ParenthesizedExpressionNode containing the inner expression,
+//	or a TupleExpressionNode for a tuple literal
 //
 // Examples:
 //
 //	(5 + 3) * 2  - Parentheses force addition before multiplication
 //	(a && b) || c
+//	(b, a)       - A tuple of two elements
 func (par *Parser) parseParenthesizedExpression() ExpressionNode {
 	// we are already at the LEFT_PAREN, so just advance
+	leftParen := par.CurrToken
 	par.advance()
 	paren := &ParenthesizedExpressionNode{}
 	paren.Expr = par.parseExpression()
 	if paren.Expr == nil {
 		return nil
 	}
+	// (a, b, ...) is a tuple literal
+	if par.NextToken.Type == lexer.COMMA_DELIM {
+		tuple := par.parseTupleElements(&TupleExpressionNode{LeftParen: leftParen, Elements: []ExpressionNode{paren.Expr}, Value: &std.Nil{}})
+		if tuple == nil || !par.expectAdvance(lexer.RIGHT_PAREN) {
+			return nil
+		}
+		return tuple
+	}
 	paren.Value = parseEval(par, paren.Expr)
 	if !par.expectAdvance(lexer.RIGHT_PAREN) {
 		return nil
@@ -56,6 +72,25 @@ func (par *Parser) parseParenthesizedExpression() ExpressionNode {
 	return paren
 }
 
+// parseTupleElements parses the elements of a tuple after its first one,
+// each preceded by a comma.
+//
+// Returns:
+//
+//	The tuple with its elements, or nil on a syntax error
+func (par *Parser) parseTupleElements(tuple *TupleExpressionNode) ExpressionNode {
+	for par.NextToken.Type == lexer.COMMA_DELIM {
+		par.advance() // Consume comma
+		par.advance()
+		elem := par.parseExpression()
+		if elem == nil {
+			return nil
+		}
+		tuple.Elements = append(tuple.Elements, elem)
+	}
+	return tuple
+}
+
 // parseBinaryExpression parses binary (infix) expressions.
 // Binary expressions have the form: left operator right
 //
@@ -170,6 +205,11 @@ func (par *Parser) parseUnaryExpression() ExpressionNode {
 	if right == nil {
 		return nil
 	}
+	if op.Type == lexer.MINUS_OP {
+		if lit := minIntLiteral(op, right); lit != nil {
+			return lit
+		}
+	}
 
 	rVal := parseEval(par, right)
 	var val std.GoMixObject = &std.Nil{}
@@ -207,27 +247,44 @@ func (par *Parser) parseUnaryExpression() ExpressionNode {
 	}
 }
 
-// parseIdentifierExpression parses identifier expressions.
-// An identifier can be either a variable reference or a function call.
+// minIntLiteral folds -9223372036854775808 into an int literal: its digits
+// alone are too large for an int, so they are read as a bigint.
 //
 // Returns:
 //
-//	Either an IdentifierExpressionNode or a CallExpressionNode
+//	An IntegerLiteralExpressionNode holding math.MinInt64 if right is the
+//	plain literal 9223372036854775808 (nil otherwise)
+func minIntLiteral(op lexer.Token, right ExpressionNode) ExpressionNode {
+	lit, ok := right.(*IntegerLiteralExpressionNode)
+	if !ok || strings.HasSuffix(lit.Token.Literal, "n") {
+		return nil
+	}
+	val, ok := lit.Value.(*std.BigInt)
+	if !ok || !new(big.Int).Neg(val.Value).IsInt64() {
+		return nil
+	}
+	token := op
+	token.Type = lit.Token.Type
+	token.Literal = "-" + lit.Token.Literal
+	return &IntegerLiteralExpressionNode{
+		Token: token,
+		Value: &std.Integer{Value: math.MinInt64},
+	}
+}
+
+// parseIdentifierExpression parses identifier expressions.
+// An identifier refers to a variable, a function or a builtin; a call such as
+// myFunc() is parsed by parseCallExpression with the identifier as the callee.
+//
+// Returns:
 //
-// The function checks the next token to determine if this is a function call
-// (next token is '(') or a simple variable reference.
+//	An IdentifierExpressionNode
 //
 // Examples:
 //
 //	x          - Variable reference
-//	myFunc()   - Function call
+//	myFunc     - Function reference (the callee of myFunc())
 func (par *Parser) parseIdentifierExpression() ExpressionNode {
-
-	// may be an identifier expression or a function call expression
-	if par.NextToken.Type == lexer.LEFT_PAREN {
-		return par.parseCallExpression()
-	}
-
 	varToken := par.CurrToken
 
 	// get the value from the environment
@@ -274,13 +331,14 @@ func (par *Parser) parseIdentifierExpression() ExpressionNode {
 //
 //	Comparison: <, >, <=, >=, ==, !=
 //	Logical: &&, ||
+//	Type test: instanceof (always evaluated at runtime)
 //
 // The function handles mixed-type comparisons (int/float) and
 // truthiness for logical operators.
 //
 // Examples:
 //
-//	5 < 10, x >= y, a == b, true && false
+//	5 < 10, x >= y, a == b, true && false, pet instanceof Animal
 func (par *Parser) parseBooleanExpression(left ExpressionNode) ExpressionNode {
 	op := par.CurrToken
 	par.advance()
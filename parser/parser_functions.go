@@ -7,6 +7,8 @@ Contact : akashmaji(@iisc.ac.in)
 package parser
 
 import (
+	"fmt"
+
 	"github.com/akashmaji946/go-mix/lexer"
 	"github.com/akashmaji946/go-mix/std"
 )
@@ -16,6 +18,8 @@ import (
 // Syntax:
 //
 //	func functionName(param1, param2, ...) { body }
+//	func functionName(param1, param2 = default, ...rest) { body }
+//	func functionName(param1: type, ...): type { body }
 //
 // Returns:
 //
@@ -27,7 +31,9 @@ import (
 // Examples:
 //
 //	func add(a, b) { return a + b; }
-//	func greet() { println("Hello!"); }
+//	func greet(name = "world") { println("Hello " + name); }
+//	func sum(...nums) { return reduce_array(nums, func(a, b) { return a + b; }, 0); }
+//	func area(w: float, h: float): float { return w * h; }
 func (par *Parser) parseFunctionStatement() StatementNode {
 	funcNode := NewFunctionStatementNode()
 	funcNode.FuncToken = par.CurrToken
@@ -46,95 +52,200 @@ func (par *Parser) parseFunctionStatement() StatementNode {
 	if !par.expectAdvance(lexer.LEFT_PAREN) {
 		return nil
 	}
+	if !par.parseFunctionParameters(funcNode) {
+		return nil
+	}
+	returnType, ok := par.parseOptionalType()
+	if !ok {
+		return nil
+	}
+	funcNode.ReturnType = returnType
+
+	if !par.parseFunctionBody(funcNode) {
+		return nil
+	}
+	return funcNode
+}
+
+// parseFunctionBody parses the body of a function, from the token before
+// its '{'. A function whose body (outside the functions nested in it) has a
+// yield statement is a generator function.
+//
+// Returns:
+//
+//	true if the body was parsed, false on a syntax error
+func (par *Parser) parseFunctionBody(funcNode *FunctionStatementNode) bool {
+	if !par.expectAdvance(lexer.LEFT_BRACE) {
+		return false
+	}
+	outer := par.function
+	par.function = funcNode
+	funcNode.FuncBody = *par.parseBlockStatement()
+	par.function = outer
+	funcNode.Value = funcNode.FuncBody.Value
+	return true
+}
 
-	// Handle empty parameters case
-	if par.NextToken.Type != lexer.RIGHT_PAREN {
-		// First parameter
+// parseFunctionParameters parses the parameter list of a function, from the
+// token after '(' up to and including ')'.
+//
+// Syntax:
+//
+//	(a, b)         plain parameters
+//	(a, b = 10)    b defaults to 10 when no argument is given for it
+//	(a, ...rest)   rest collects the remaining arguments into an array
+//	(a: int, b: string = "x", ...rest: array<int>)   annotated parameters
+//
+// Parameters with a default value must follow the ones without, and the rest
+// parameter must be the last one.
+//
+// Returns:
+//
+//	true if the parameter list was parsed, false on a syntax error
+func (par *Parser) parseFunctionParameters(funcNode *FunctionStatementNode) bool {
+	for par.NextToken.Type != lexer.RIGHT_PAREN {
+		rest := par.NextToken.Type == lexer.RANGE_OP
+		if rest {
+			par.advance() // Consume '...'
+		}
 		if !par.expectAdvance(lexer.IDENTIFIER_ID) {
-			return nil
+			return false
 		}
-		funcNode.FuncParams = append(funcNode.FuncParams, &IdentifierExpressionNode{
+		param := &IdentifierExpressionNode{
 			Token: par.CurrToken,
 			Name:  par.CurrToken.Literal,
 			Value: &std.Nil{}, // Default value for identifier
-		})
+		}
+		paramType, ok := par.parseOptionalType()
+		if !ok {
+			return false
+		}
 
-		// Subsequent parameters
-		for par.NextToken.Type == lexer.COMMA_DELIM {
-			par.advance() // Consume comma
-			if !par.expectAdvance(lexer.IDENTIFIER_ID) {
-				return nil
+		var defaultValue ExpressionNode
+		if !rest && par.NextToken.Type == lexer.ASSIGN_OP {
+			par.advance() // Consume '='
+			par.advance()
+			defaultValue = par.parseExpression()
+			if defaultValue == nil {
+				return false
 			}
-			funcNode.FuncParams = append(funcNode.FuncParams, &IdentifierExpressionNode{
-				Token: par.CurrToken,
-				Name:  par.CurrToken.Literal,
-				Value: &std.Nil{}, // Default value for identifier
-			})
+		} else if !rest && len(funcNode.FuncParams) > 0 && funcNode.Default(len(funcNode.FuncParams)-1) != nil {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: parameter (%s) without a default value follows a parameter with one",
+				param.Token.Line, param.Token.Column, param.Name))
+			return false
 		}
-	}
-	if !par.expectAdvance(lexer.RIGHT_PAREN) {
-		return nil
-	}
+		funcNode.FuncParams = append(funcNode.FuncParams, param)
+		funcNode.FuncDefaults = append(funcNode.FuncDefaults, defaultValue)
+		funcNode.ParamTypes = append(funcNode.ParamTypes, paramType)
 
-	if !par.expectAdvance(lexer.LEFT_BRACE) {
-		return nil
+		if rest {
+			funcNode.FuncRest = true
+			if par.NextToken.Type != lexer.RIGHT_PAREN {
+				par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: rest parameter (...%s) must be the last parameter",
+					param.Token.Line, param.Token.Column, param.Name))
+				return false
+			}
+			break
+		}
+		if par.NextToken.Type != lexer.COMMA_DELIM {
+			break
+		}
+		par.advance() // Consume comma
 	}
-	funcNode.FuncBody = *par.parseBlockStatement()
-	funcNode.Value = funcNode.FuncBody.Value
-	return funcNode
+	return par.expectAdvance(lexer.RIGHT_PAREN)
 }
 
 // parseCallExpression parses function call expressions.
+// It is registered as the infix function of '(' so that any expression can
+// be called: a name, a member, an index, the result of another call or a
+// function literal.
 //
 // Syntax:
 //
-//	functionName(arg1, arg2, ...)
-//	functionName()  (no arguments)
+//	callee(arg1, arg2, ...)
+//	callee()  (no arguments)
+//
+// Parameters:
+//
+//	callee - The already-parsed expression being called
 //
 // Returns:
 //
-//	A CallExpressionNode containing the function name and arguments
+//	A CallExpressionNode containing the callee and arguments
 //
 // Examples:
 //
 //	print("Hello")
 //	add(5, 3)
-//	factorial(10)
-func (par *Parser) parseCallExpression() ExpressionNode {
+//	makeAdder(1)(2)
+//	handlers[key](req)
+//	obj.field.method()
+//	(func(x) { return x * x; })(3)
+func (par *Parser) parseCallExpression(callee ExpressionNode) ExpressionNode {
 	callNode := &CallExpressionNode{
-		Value: &std.Nil{},
-	}
-	callNode.FunctionIdentifier = IdentifierExpressionNode{
-		Token: par.CurrToken,
-		Name:  par.CurrToken.Literal,
-		Value: &std.Nil{}, // Default value for identifier
+		Callee:    callee,
+		LeftParen: par.CurrToken,
+		Value:     &std.Nil{},
 	}
 
-	if !par.expectAdvance(lexer.LEFT_PAREN) {
+	args, names, ok := par.parseArguments()
+	if !ok {
 		return nil
 	}
-	// if there are arguments, parse them
-	if par.NextToken.Type != lexer.RIGHT_PAREN {
+	callNode.Arguments, callNode.ArgNames = args, names
+	callNode.RightParen = par.CurrToken
+	return callNode
+}
+
+// parseArguments parses the arguments of a call, from the token after '(' up
+// to and including ')'. An argument written as `name: value` is passed to the
+// parameter called name; named arguments must follow the positional ones.
+//
+// Returns:
+//
+//	The argument expressions
+//	The argument names, parallel to the arguments ("" for a positional one),
+//	or nil if no argument is named
+//	false on a syntax error
+func (par *Parser) parseArguments() ([]ExpressionNode, []string, bool) {
+	var args []ExpressionNode
+	var names []string
+	for par.NextToken.Type != lexer.RIGHT_PAREN {
 		par.advance()
-		for {
-			arg := par.parseExpression()
-			if arg == nil {
-				return nil
+		name := ""
+		if par.CurrToken.Type == lexer.IDENTIFIER_ID && par.NextToken.Type == lexer.COLON_DELIM {
+			name = par.CurrToken.Literal
+			for _, prev := range names {
+				if prev == name {
+					par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: argument (%s) is passed more than once",
+						par.CurrToken.Line, par.CurrToken.Column, name))
+					return nil, nil, false
+				}
 			}
-			callNode.Arguments = append(callNode.Arguments, arg)
-			if par.NextToken.Type == lexer.COMMA_DELIM {
-				par.advance()
-				par.advance()
-			} else {
-				break
+			if names == nil {
+				names = make([]string, len(args))
 			}
+			par.advance() // Consume the name
+			par.advance() // Consume ':'
+		} else if names != nil {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: positional argument follows a named argument",
+				par.CurrToken.Line, par.CurrToken.Column))
+			return nil, nil, false
 		}
+		arg := par.parseExpression()
+		if arg == nil {
+			return nil, nil, false
+		}
+		args = append(args, arg)
+		if names != nil {
+			names = append(names, name)
+		}
+		if par.NextToken.Type != lexer.COMMA_DELIM {
+			break
+		}
+		par.advance() // Consume comma
 	}
-
-	if !par.expectAdvance(lexer.RIGHT_PAREN) {
-		return nil
-	}
-	return callNode
+	return args, names, par.expectAdvance(lexer.RIGHT_PAREN)
 }
 
 // parseFunctionAssignment parses anonymous function expressions.
@@ -143,6 +254,7 @@ func (par *Parser) parseCallExpression() ExpressionNode {
 // Syntax:
 //
 //	func(param1, param2, ...) { body }
+//	func(param1: type, ...): type { body }
 //
 // Returns:
 //
@@ -158,40 +270,17 @@ func (par *Parser) parseFunctionAssignment() ExpressionNode {
 	if !par.expectAdvance(lexer.LEFT_PAREN) {
 		return nil
 	}
-
-	// Handle empty parameters case
-	if par.NextToken.Type != lexer.RIGHT_PAREN {
-		// First parameter
-		if !par.expectAdvance(lexer.IDENTIFIER_ID) {
-			return nil
-		}
-		funcNode.FuncParams = append(funcNode.FuncParams, &IdentifierExpressionNode{
-			Token: par.CurrToken,
-			Name:  par.CurrToken.Literal,
-			Value: &std.Nil{}, // Default value for identifier
-		})
-
-		// Subsequent parameters
-		for par.NextToken.Type == lexer.COMMA_DELIM {
-			par.advance() // Consume comma
-			if !par.expectAdvance(lexer.IDENTIFIER_ID) {
-				return nil
-			}
-			funcNode.FuncParams = append(funcNode.FuncParams, &IdentifierExpressionNode{
-				Token: par.CurrToken,
-				Name:  par.CurrToken.Literal,
-				Value: &std.Nil{}, // Default value for identifier
-			})
-		}
+	if !par.parseFunctionParameters(funcNode) {
+		return nil
 	}
-	if !par.expectAdvance(lexer.RIGHT_PAREN) {
+	returnType, ok := par.parseOptionalType()
+	if !ok {
 		return nil
 	}
+	funcNode.ReturnType = returnType
 
-	if !par.expectAdvance(lexer.LEFT_BRACE) {
+	if !par.parseFunctionBody(funcNode) {
 		return nil
 	}
-	funcNode.FuncBody = *par.parseBlockStatement()
-	funcNode.Value = funcNode.FuncBody.Value
 	return funcNode
 }
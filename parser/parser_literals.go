@@ -7,8 +7,11 @@ Contact : akashmaji(@iisc.ac.in)
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/akashmaji946/go-mix/lexer"
 	"github.com/akashmaji946/go-mix/std"
@@ -20,28 +23,30 @@ import (
 //
 // Returns:
 //
-//	An IntegerLiteralExpressionNode with the parsed value
+//	An IntegerLiteralExpressionNode with the parsed value: an Integer, or a
+//	BigInt for literals with an n suffix and literals too large for an int
 //
 // Special handling:
-//   - Uses ParseUint for values that overflow ParseInt (like -9223372036854775808)
+//   - Literals past 9223372036854775807 are bigints (parseUnaryExpression
+//     folds -9223372036854775808 back into an int)
 //   - Reports errors for invalid number formats
 //
 // Examples:
 //
-//	42, -17, 0, 9223372036854775807
+//	42, -17, 0, 9223372036854775807, 123n, 100000000000000000000
 func (par *Parser) parseNumberLiteral() ExpressionNode {
 	token := par.CurrToken
+	if digits, isBig := strings.CutSuffix(token.Literal, "n"); isBig {
+		return par.parseBigIntLiteral(token, digits)
+	}
 	val, err := strconv.ParseInt(token.Literal, 0, 64)
-	if err != nil {
-		uVal, uErr := strconv.ParseUint(token.Literal, 0, 64)
-		if uErr == nil {
-			val = int64(uVal)
-		} else {
-			msg := fmt.Sprintf("[%d:%d] PARSER ERROR: could not parse number literal: %s",
-				token.Line, token.Column, token.Literal)
-			par.addError(msg)
-			return nil
-		}
+	if errors.Is(err, strconv.ErrRange) {
+		return par.parseBigIntLiteral(token, token.Literal)
+	} else if err != nil {
+		msg := fmt.Sprintf("[%d:%d] PARSER ERROR: could not parse number literal: %s",
+			token.Line, token.Column, token.Literal)
+		par.addError(msg)
+		return nil
 	}
 	return &IntegerLiteralExpressionNode{
 		Token: token,
@@ -49,6 +54,22 @@ func (par *Parser) parseNumberLiteral() ExpressionNode {
 	}
 }
 
+// parseBigIntLiteral parses the digits of a bigint literal (123n), or of an
+// integer literal too large for an int.
+func (par *Parser) parseBigIntLiteral(token lexer.Token, digits string) ExpressionNode {
+	val, ok := new(big.Int).SetString(digits, 0)
+	if !ok {
+		msg := fmt.Sprintf("[%d:%d] PARSER ERROR: could not parse number literal: %s",
+			token.Line, token.Column, token.Literal)
+		par.addError(msg)
+		return nil
+	}
+	return &IntegerLiteralExpressionNode{
+		Token: token,
+		Value: &std.BigInt{Value: val},
+	}
+}
+
 // parseCharLiteral parses character literal expressions.
 func (par *Parser) parseCharLiteral() ExpressionNode {
 	token := par.CurrToken
@@ -121,6 +142,56 @@ func (par *Parser) parseStringLiteral() ExpressionNode {
 	}
 }
 
+// parseInterpolatedString parses interpolated string expressions.
+// The lexer splits the string into its text and the source of its embedded
+// expressions; each expression is parsed here with its own lexer, positioned
+// where the expression is in the source.
+//
+// Returns:
+//
+//	An InterpolatedStringExpressionNode, or nil on a syntax error
+//
+// Examples:
+//
+//	"Hello ${user.name}, you have ${len(items)} items"
+func (par *Parser) parseInterpolatedString() ExpressionNode {
+	node := &InterpolatedStringExpressionNode{Token: par.CurrToken, Texts: []string{""}, Value: &std.Nil{}}
+	lex, curr, next, lexErrors := par.Lex, par.CurrToken, par.NextToken, par.lexErrors
+	defer func() {
+		par.Lex, par.CurrToken, par.NextToken, par.lexErrors = lex, curr, next, lexErrors
+	}()
+
+	for _, part := range lexer.SplitInterpolatedString(node.Token) {
+		if !part.IsExpr {
+			node.Texts[len(node.Texts)-1] = part.Text
+			continue
+		}
+		par.Lex, par.lexErrors = lexer.NewLexer(part.Text), 0
+		par.Lex.Line, par.Lex.Column = part.Line, part.Column
+		par.NextToken = par.Lex.NextToken()
+		par.advance()
+		if par.CurrToken.Type == lexer.EOF_TYPE {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: empty expression in interpolated string", part.Line, part.Column))
+			return nil
+		}
+		expr := par.parseExpression()
+		if expr == nil {
+			return nil
+		}
+		if par.NextToken.Type != lexer.EOF_TYPE {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: unexpected token (%s) in interpolated string",
+				par.NextToken.Line, par.NextToken.Column, par.NextToken.Literal))
+			return nil
+		}
+		node.Exprs = append(node.Exprs, expr)
+		node.Texts = append(node.Texts, "")
+	}
+	// The token keeps the canonical text of the string rather than its
+	// source, which depends on the layout of the embedded expressions
+	node.Token.Literal = node.Literal()
+	return node
+}
+
 // parseNilLiteral parses nil literal expressions.
 // Nil represents the absence of a value.
 //
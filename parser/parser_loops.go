@@ -284,11 +284,16 @@ func (par *Parser) parseWhileLoop() StatementNode {
 }
 
 // parseForeachLoop parses foreach loop statements.
-// Foreach loops iterate over ranges or arrays.
+// Foreach loops iterate over ranges, collections, strings, channels, files
+// and iterable struct instances. With two identifiers, the first one is bound
+// to the key (index) of each element and the second one to its value.
 //
 // Syntax:
 //
 //	foreach identifier in iterable { body }
+//	foreach key, value in iterable { body }
+//	foreach [a, b] in iterable { body }      (destructures each value, see parsePattern)
+//	foreach key, {a, b} in iterable { body }
 //
 // Returns:
 //
@@ -299,17 +304,27 @@ func (par *Parser) parseWhileLoop() StatementNode {
 //	foreach i in 2...10 { print(i); }
 //	foreach item in array { print(item); }
 //	foreach x in myRange { body }
+//	foreach k, v in myMap { body }
+//	foreach i, (x, y) in points { body }
 func (par *Parser) parseForeachLoop() StatementNode {
 	foreachToken := par.CurrToken
 
-	// Expect iterator identifier
-	if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+	// Expect iterator identifier or pattern
+	var iterator IdentifierExpressionNode
+	pattern, ok := par.parseForeachVariable(&iterator)
+	if !ok {
 		return nil
 	}
-	iterator := IdentifierExpressionNode{
-		Token: par.CurrToken,
-		Name:  par.CurrToken.Literal,
-		Value: &std.Nil{},
+
+	// An optional second variable makes the first one the key
+	var key *IdentifierExpressionNode
+	if pattern == nil && par.NextToken.Type == lexer.COMMA_DELIM {
+		par.advance()
+		first := iterator
+		key = &first
+		if pattern, ok = par.parseForeachVariable(&iterator); !ok {
+			return nil
+		}
 	}
 
 	// Expect 'in' keyword
@@ -334,9 +349,35 @@ func (par *Parser) parseForeachLoop() StatementNode {
 
 	return &ForeachLoopStatementNode{
 		ForeachToken: foreachToken,
+		Key:          key,
 		Iterator:     iterator,
+		Pattern:      pattern,
 		Iterable:     iterable,
 		Body:         *body,
 		Value:        &std.Nil{},
 	}
 }
+
+// parseForeachVariable parses a loop variable of a foreach head: a name,
+// stored in ident, or a destructuring pattern, which is returned.
+//
+// Returns:
+//
+//	The pattern, or nil for a name
+//	false on a syntax error
+func (par *Parser) parseForeachVariable(ident *IdentifierExpressionNode) (*PatternNode, bool) {
+	if isPatternOpen(par.NextToken.Type) {
+		par.advance()
+		pattern := par.parsePattern()
+		return pattern, pattern != nil
+	}
+	if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+		return nil, false
+	}
+	*ident = IdentifierExpressionNode{
+		Token: par.CurrToken,
+		Name:  par.CurrToken.Literal,
+		Value: &std.Nil{},
+	}
+	return nil, true
+}
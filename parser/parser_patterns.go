@@ -0,0 +1,376 @@
+/*
+File    : go-mix/parser/parser_patterns.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// isPatternOpen reports whether a token opens a destructuring pattern.
+func isPatternOpen(tok lexer.TokenType) bool {
+	return tok == lexer.LEFT_PAREN || tok == lexer.LEFT_BRACKET || tok == lexer.LEFT_BRACE
+}
+
+// closingBracket returns the bracket closing the given opening bracket.
+func closingBracket(open lexer.TokenType) lexer.TokenType {
+	switch open {
+	case lexer.LEFT_PAREN:
+		return lexer.RIGHT_PAREN
+	case lexer.LEFT_BRACKET:
+		return lexer.RIGHT_BRACKET
+	}
+	return lexer.RIGHT_BRACE
+}
+
+// parsePattern parses a destructuring pattern, from its opening bracket up to
+// and including the closing one.
+//
+// Syntax:
+//
+//	(name1, name2, ...)           elements of a tuple, array or other iterable
+//	[name1, name2, ...rest]       the same; rest gets the remaining elements
+//	{key1, key2, ...rest}         map entries or struct fields, by name
+//
+// Returns:
+//
+//	A PatternNode, or nil on a syntax error
+func (par *Parser) parsePattern() *PatternNode {
+	pattern := &PatternNode{Open: par.CurrToken}
+	closing := closingBracket(par.CurrToken.Type)
+	for par.NextToken.Type != closing {
+		rest := par.NextToken.Type == lexer.RANGE_OP
+		if rest {
+			par.advance() // Consume '...'
+		}
+		if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+			return nil
+		}
+		name := &IdentifierExpressionNode{
+			Token: par.CurrToken,
+			Name:  par.CurrToken.Literal,
+			Value: &std.Nil{}, // Default value for identifier
+		}
+		if rest {
+			pattern.Rest = name
+			if par.NextToken.Type != closing {
+				par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: rest element (...%s) must be the last one in a pattern",
+					name.Token.Line, name.Token.Column, name.Name))
+				return nil
+			}
+			break
+		}
+		pattern.Names = append(pattern.Names, name)
+		if par.NextToken.Type != lexer.COMMA_DELIM {
+			break
+		}
+		par.advance() // Consume comma
+	}
+	if !par.expectAdvance(closing) {
+		return nil
+	}
+	if len(pattern.Names) == 0 && pattern.Rest == nil {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: empty destructuring pattern",
+			pattern.Open.Line, pattern.Open.Column))
+		return nil
+	}
+	return pattern
+}
+
+// isPatternAssignment reports whether the statement starting at the current
+// '(', '[' or '{' assigns to a destructuring pattern, as in [a, b] = [b, a].
+// The tokens are scanned on a copy of the lexer, so nothing is consumed.
+func (par *Parser) isPatternAssignment() bool {
+	lex := par.Lex
+	lex.Comments = nil // keep the comments of the real lexer untouched
+	closing := closingBracket(par.CurrToken.Type)
+	tok := par.NextToken
+	for {
+		if tok.Type == lexer.RANGE_OP {
+			tok = lex.NextToken()
+		}
+		if tok.Type != lexer.IDENTIFIER_ID {
+			return false
+		}
+		tok = lex.NextToken()
+		if tok.Type == closing {
+			return lex.NextToken().Type == lexer.ASSIGN_OP
+		}
+		if tok.Type != lexer.COMMA_DELIM {
+			return false
+		}
+		tok = lex.NextToken()
+	}
+}
+
+// parsePatternAssignment parses an assignment to a destructuring pattern.
+//
+// Syntax:
+//
+//	(name1, name2) = expression
+//	[first, ...rest] = expression
+//	{key1, key2} = expression
+//
+// Returns:
+//
+//	An AssignmentExpressionNode whose left side is a PatternNode
+//
+// Examples:
+//
+//	(a, b) = (b, a)
+//	[head, ...tail] = items
+//	{name, age} = person
+func (par *Parser) parsePatternAssignment() ExpressionNode {
+	pattern := par.parsePattern()
+	if pattern == nil {
+		return nil
+	}
+	if !par.expectAdvance(lexer.ASSIGN_OP) {
+		return nil
+	}
+	op := par.CurrToken
+	par.advance()
+	right := par.parseExpression()
+	if right == nil {
+		return nil
+	}
+	par.forgetPatternValues(pattern)
+	return &AssignmentExpressionNode{
+		Operation: op,
+		Left:      pattern,
+		Right:     right,
+		Value:     &std.Nil{},
+	}
+}
+
+// forgetPatternValues drops the parse-time values of the names a pattern
+// binds, since they are only known when the program runs.
+func (par *Parser) forgetPatternValues(pattern *PatternNode) {
+	for _, name := range pattern.Bindings() {
+		delete(par.Env, name.Name)
+	}
+}
+
+// parseMatchPattern parses the pattern of a match arm, from its first token
+// up to and including its last one: a pattern, or alternatives separated by
+// '|', which must all bind the same names.
+//
+// Syntax:
+//
+//	_                             anything
+//	name                          anything, bound to name
+//	42, "text", nil, Color.RED    an equal value
+//	1...5, 'a'...'z'              a number (or char) between the bounds, inclusive
+//	[p1, p2, ...rest]             an array, list or tuple, element by element
+//	(p1, p2)                      the same
+//	{key, "other": p, ...rest}    a map having the keys
+//	Point{x, y: p}                an instance of a struct (or of an interface)
+//	p1 | p2                       what any of the patterns matches
+//
+// Returns:
+//
+//	A MatchPatternNode, or nil on a syntax error
+func (par *Parser) parseMatchPattern() *MatchPatternNode {
+	first := par.parseMatchPrimary()
+	if first == nil || par.NextToken.Type != lexer.BIT_OR_OP {
+		return first
+	}
+	pattern := &MatchPatternNode{Token: first.Token, Kind: AlternativePattern, Elements: []*MatchPatternNode{first}}
+	names := bindingNames(first)
+	for par.NextToken.Type == lexer.BIT_OR_OP {
+		par.advance() // Consume '|'
+		par.advance()
+		alt := par.parseMatchPrimary()
+		if alt == nil {
+			return nil
+		}
+		if bindingNames(alt) != names {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: the alternatives of a pattern must bind the same names",
+				alt.Token.Line, alt.Token.Column))
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, alt)
+	}
+	return pattern
+}
+
+// bindingNames returns the names a pattern binds, sorted and joined, so the
+// bindings of two patterns can be compared.
+func bindingNames(pattern *MatchPatternNode) string {
+	names := make([]string, 0)
+	for _, name := range pattern.Bindings() {
+		names = append(names, name.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// parseMatchPrimary parses a pattern other than alternatives.
+func (par *Parser) parseMatchPrimary() *MatchPatternNode {
+	switch par.CurrToken.Type {
+	case lexer.IDENTIFIER_ID:
+		switch par.NextToken.Type {
+		case lexer.DOT_OP, lexer.RANGE_OP:
+			// Color.RED, pkg.Point{...} or lo...hi: parsed as a value below
+		case lexer.LEFT_BRACE:
+			name := &IdentifierExpressionNode{Token: par.CurrToken, Name: par.CurrToken.Literal, Value: &std.Nil{}}
+			par.advance() // Consume the struct name
+			return par.parseFieldsPattern(&MatchPatternNode{Token: name.Token, Kind: StructPattern, Name: name})
+		default:
+			if par.CurrToken.Literal == "_" {
+				return &MatchPatternNode{Token: par.CurrToken, Kind: WildcardPattern}
+			}
+			name := &IdentifierExpressionNode{Token: par.CurrToken, Name: par.CurrToken.Literal, Value: &std.Nil{}}
+			return &MatchPatternNode{Token: par.CurrToken, Kind: BindingPattern, Name: name}
+		}
+	case lexer.LEFT_BRACKET, lexer.LEFT_PAREN:
+		return par.parseSequencePattern()
+	case lexer.LEFT_BRACE:
+		return par.parseFieldsPattern(&MatchPatternNode{Token: par.CurrToken, Kind: MapPattern})
+	}
+
+	// A value, parsed above the precedence of '...' and '|'
+	pattern := &MatchPatternNode{Token: par.CurrToken, Kind: ValuePattern}
+	pattern.Value = par.parseInternal(SHIFT_PRIORITY)
+	if pattern.Value == nil {
+		return nil
+	}
+	if name, ok := qualifiedName(pattern.Value); ok && par.NextToken.Type == lexer.LEFT_BRACE {
+		par.advance() // Consume the struct name
+		ident := &IdentifierExpressionNode{Token: pattern.Token, Name: name, Value: &std.Nil{}}
+		return par.parseFieldsPattern(&MatchPatternNode{Token: pattern.Token, Kind: StructPattern, Name: ident})
+	}
+	if par.NextToken.Type == lexer.RANGE_OP {
+		par.advance() // Consume '...'
+		par.advance()
+		pattern.Kind = RangePattern
+		pattern.End = par.parseInternal(SHIFT_PRIORITY)
+		if pattern.End == nil {
+			return nil
+		}
+	}
+	return pattern
+}
+
+// qualifiedName returns the name of a struct written as Name or pkg.Name.
+func qualifiedName(expr ExpressionNode) (string, bool) {
+	switch n := expr.(type) {
+	case *IdentifierExpressionNode:
+		return n.Name, true
+	case *BinaryExpressionNode:
+		left, isIdent := n.Left.(*IdentifierExpressionNode)
+		right, isName := n.Right.(*IdentifierExpressionNode)
+		if n.Operation.Type == lexer.DOT_OP && isIdent && isName {
+			return left.Name + "." + right.Name, true
+		}
+	}
+	return "", false
+}
+
+// parseSequencePattern parses [p1, p2, ...rest] or (p1, p2, ...rest). A
+// single pattern in parentheses without a comma is only grouped.
+func (par *Parser) parseSequencePattern() *MatchPatternNode {
+	pattern := &MatchPatternNode{Token: par.CurrToken, Kind: SequencePattern, Elements: make([]*MatchPatternNode, 0)}
+	closing := closingBracket(par.CurrToken.Type)
+	comma := false
+	for par.NextToken.Type != closing {
+		par.advance()
+		if par.CurrToken.Type == lexer.RANGE_OP {
+			if pattern.Rest = par.parseRestName(closing); pattern.Rest == nil {
+				return nil
+			}
+			break
+		}
+		elem := par.parseMatchPattern()
+		if elem == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, elem)
+		if par.NextToken.Type != lexer.COMMA_DELIM {
+			break
+		}
+		par.advance() // Consume comma
+		comma = true
+	}
+	if !par.expectAdvance(closing) {
+		return nil
+	}
+	if pattern.Token.Type == lexer.LEFT_PAREN && !comma && pattern.Rest == nil {
+		if len(pattern.Elements) == 0 {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: empty destructuring pattern",
+				pattern.Token.Line, pattern.Token.Column))
+			return nil
+		}
+		return pattern.Elements[0]
+	}
+	return pattern
+}
+
+// parseFieldsPattern parses the entries of a map pattern {key, "key": p,
+// ...rest} or of a struct pattern Name{field, field: p}, from the '{' up to
+// and including the '}'. A key alone binds the value to its name.
+func (par *Parser) parseFieldsPattern(pattern *MatchPatternNode) *MatchPatternNode {
+	pattern.Keys = make([]string, 0)
+	pattern.Elements = make([]*MatchPatternNode, 0)
+	for par.NextToken.Type != lexer.RIGHT_BRACE {
+		par.advance()
+		if par.CurrToken.Type == lexer.RANGE_OP && pattern.Kind == MapPattern {
+			if pattern.Rest = par.parseRestName(lexer.RIGHT_BRACE); pattern.Rest == nil {
+				return nil
+			}
+			break
+		}
+		key := par.CurrToken
+		if key.Type != lexer.IDENTIFIER_ID && (key.Type != lexer.STRING_LIT || pattern.Kind == StructPattern) {
+			par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: expected a key in a pattern, got %s",
+				key.Line, key.Column, key.Literal))
+			return nil
+		}
+		var elem *MatchPatternNode
+		if par.NextToken.Type == lexer.COLON_DELIM || key.Type == lexer.STRING_LIT {
+			if !par.expectAdvance(lexer.COLON_DELIM) {
+				return nil
+			}
+			par.advance()
+			if elem = par.parseMatchPattern(); elem == nil {
+				return nil
+			}
+		} else {
+			name := &IdentifierExpressionNode{Token: key, Name: key.Literal, Value: &std.Nil{}}
+			elem = &MatchPatternNode{Token: key, Kind: BindingPattern, Name: name}
+		}
+		pattern.Keys = append(pattern.Keys, key.Literal)
+		pattern.Elements = append(pattern.Elements, elem)
+		if par.NextToken.Type != lexer.COMMA_DELIM {
+			break
+		}
+		par.advance() // Consume comma
+	}
+	if !par.expectAdvance(lexer.RIGHT_BRACE) {
+		return nil
+	}
+	return pattern
+}
+
+// parseRestName parses the name after the '...' of a rest element, which
+// must be the last one before closing.
+func (par *Parser) parseRestName(closing lexer.TokenType) *IdentifierExpressionNode {
+	if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+		return nil
+	}
+	name := &IdentifierExpressionNode{Token: par.CurrToken, Name: par.CurrToken.Literal, Value: &std.Nil{}}
+	if par.NextToken.Type != closing {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: rest element (...%s) must be the last one in a pattern",
+			name.Token.Line, name.Token.Column, name.Name))
+		return nil
+	}
+	return name
+}
@@ -61,8 +61,8 @@ const (
 	// Example: a == b, a != b
 	EQUALITY_PRIORITY = 90
 
-	// Relational operators: < > <= >=
-	// Example: a < b, a >= b
+	// Relational operators: < > <= >= instanceof
+	// Example: a < b, a >= b, pet instanceof Animal
 	RELATIONAL_PRIORITY = 100
 
 	// Range operator: ...
@@ -89,8 +89,8 @@ const (
 	// Example: obj.field, obj.method()
 	MEMBER_ACCESS_PRIORITY = 145
 
-	// Parentheses (highest precedence for grouping)
-	// Example: (a + b) * c
+	// Parentheses (highest precedence for grouping) and calls
+	// Example: (a + b) * c, f(x), obj.method()
 	PAREN_PRIORITY = 150
 
 	// Index/Call operators (highest precedence for postfix operations)
@@ -140,8 +140,8 @@ func getPrecedence(token *lexer.Token) int {
 	case lexer.BIT_LEFT_OP, lexer.BIT_RIGHT_OP:
 		return SHIFT_PRIORITY
 
-	// Relational: < > <= >=
-	case lexer.GT_OP, lexer.LT_OP, lexer.GE_OP, lexer.LE_OP:
+	// Relational: < > <= >= instanceof
+	case lexer.GT_OP, lexer.LT_OP, lexer.GE_OP, lexer.LE_OP, lexer.INSTANCEOF_KEY:
 		return RELATIONAL_PRIORITY
 
 	// Range: ...
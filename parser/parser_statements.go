@@ -19,6 +19,8 @@ import (
 //	var identifier = expression;
 //	let identifier = expression;   (statically typed)
 //	const identifier = expression; (immutable)
+//	var identifier: type = expression; (annotated, see parseTypeAnnotation)
+//	var (name1, name2) = expression; (destructuring, see parsePattern)
 //
 // Returns:
 //
@@ -34,8 +36,14 @@ import (
 //	var x = 10;
 //	let name = "Alice";
 //	const PI = 3.14159;
+//	let names: array<string> = [];
+//	var (q, r) = divmod(7, 2);
+//	let [first, ...rest] = arr;
 func (par *Parser) parseDeclarativeStatement() StatementNode {
 	varToken := par.CurrToken
+	if isPatternOpen(par.NextToken.Type) {
+		return par.parsePatternDeclaration(varToken)
+	}
 	if !par.expectAdvance(lexer.IDENTIFIER_ID) {
 		return nil
 	}
@@ -50,6 +58,10 @@ func (par *Parser) parseDeclarativeStatement() StatementNode {
 		isLet = true
 		par.LetVars[identifier.Literal] = true
 	}
+	varType, ok := par.parseOptionalType()
+	if !ok {
+		return nil
+	}
 	if !par.expectAdvance(lexer.ASSIGN_OP) {
 		return nil
 	}
@@ -73,11 +85,56 @@ func (par *Parser) parseDeclarativeStatement() StatementNode {
 	return &DeclarativeStatementNode{
 		VarToken:   varToken,
 		Identifier: IdentifierExpressionNode{Token: identifier, Name: identifier.Literal, Value: val, Type: typ, IsLet: isLet},
+		VarType:    varType,
 		Expr:       expr,
 		Value:      val,
 	}
 }
 
+// parsePatternDeclaration parses a var, let or const declaration of the names
+// of a destructuring pattern, from the token after the keyword.
+//
+// Examples:
+//
+//	var (q, r) = divmod(7, 2);
+//	let [first, ...rest] = arr;
+//	const {name, age} = person;
+func (par *Parser) parsePatternDeclaration(varToken lexer.Token) StatementNode {
+	par.advance()
+	pattern := par.parsePattern()
+	if pattern == nil {
+		return nil
+	}
+	for _, name := range pattern.Bindings() {
+		if varToken.Type == lexer.CONST_KEY {
+			name.Type = "const"
+			par.Consts[name.Name] = true
+		} else if varToken.Type == lexer.LET_KEY {
+			name.Type = "let"
+			name.IsLet = true
+			par.LetVars[name.Name] = true
+		} else {
+			name.Type = "var"
+		}
+	}
+	if !par.expectAdvance(lexer.ASSIGN_OP) {
+		return nil
+	}
+	par.advance()
+	expr := par.parseExpression()
+	if expr == nil {
+		return nil
+	}
+	par.forgetPatternValues(pattern)
+
+	return &DeclarativeStatementNode{
+		VarToken: varToken,
+		Pattern:  pattern,
+		Expr:     expr,
+		Value:    parseEval(par, expr),
+	}
+}
+
 // parseBlockStatement parses block statements (code blocks).
 // A block is a sequence of statements enclosed in curly braces.
 //
@@ -107,6 +164,7 @@ func (par *Parser) parseBlockStatement() *BlockStatementNode {
 		}
 		par.advance()
 	}
+	block.RBrace = par.CurrToken
 
 	// computes the value of the block node
 	// by evaluating the last statement
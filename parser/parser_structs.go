@@ -17,6 +17,14 @@ import (
 // parseStructDeclaration parses struct declarations.
 //
 // Syntax:
+//
+//	struct Name { fields and methods }
+//	struct Name extends Parent implements Interface1, Interface2 { fields and methods }
+//
+// Examples:
+//
+//	struct Point { var x = 0; var y = 0; func init(x, y) { this.x = x; this.y = y; } }
+//	struct Dog extends Animal implements Pet { func speak() { return super.speak() + "!"; } }
 func (par *Parser) parseStructDeclaration() StatementNode {
 	structToken := par.CurrToken
 
@@ -30,6 +38,32 @@ func (par *Parser) parseStructDeclaration() StatementNode {
 		Value: &std.Nil{},
 	}
 
+	// Optional parent struct: extends Animal (or extends util.Animal)
+	var parent *IdentifierExpressionNode
+	if par.NextToken.Type == lexer.EXTENDS_KEY {
+		par.advance()
+		if parent = par.parseTypeName(); parent == nil {
+			return nil
+		}
+	}
+
+	// Optional interfaces: implements Shape, Named
+	interfaces := make([]*IdentifierExpressionNode, 0)
+	if par.NextToken.Type == lexer.IMPLEMENTS_KEY {
+		par.advance()
+		for {
+			iface := par.parseTypeName()
+			if iface == nil {
+				return nil
+			}
+			interfaces = append(interfaces, iface)
+			if par.NextToken.Type != lexer.COMMA_DELIM {
+				break
+			}
+			par.advance() // Consume comma
+		}
+	}
+
 	// Expect opening brace for struct body
 	if !par.expectAdvance(lexer.LEFT_BRACE) {
 		return nil
@@ -40,17 +74,26 @@ func (par *Parser) parseStructDeclaration() StatementNode {
 	fields := make([]*DeclarativeStatementNode, 0)
 	for par.NextToken.Type != lexer.RIGHT_BRACE {
 		par.advance()
+		line := par.CurrToken.Line
 		if par.CurrToken.Type == lexer.FUNC_KEY {
 			method := par.parseFunctionStatement()
 			if method == nil {
 				return nil
 			}
+			par.Spans[method] = Span{Line: line, EndLine: par.CurrToken.Line}
 			methods = append(methods, method.(*FunctionStatementNode))
 		} else if par.CurrToken.Type == lexer.VAR_KEY || par.CurrToken.Type == lexer.LET_KEY || par.CurrToken.Type == lexer.CONST_KEY {
+			if isPatternOpen(par.NextToken.Type) {
+				msg := fmt.Sprintf("[%d:%d] PARSER ERROR: struct fields cannot be declared with a destructuring pattern",
+					par.NextToken.Line, par.NextToken.Column)
+				par.addError(msg)
+				return nil
+			}
 			stmt := par.parseDeclarativeStatement()
 			if stmt == nil {
 				return nil
 			}
+			par.Spans[stmt] = Span{Line: line, EndLine: par.CurrToken.Line}
 			fields = append(fields, stmt.(*DeclarativeStatementNode))
 			// Optional semicolon
 			if par.NextToken.Type == lexer.SEMICOLON_DELIM {
@@ -72,12 +115,146 @@ func (par *Parser) parseStructDeclaration() StatementNode {
 	return &StructDeclarationNode{
 		StructToken: structToken,
 		StructName:  structName,
+		Parent:      parent,
+		Interfaces:  interfaces,
 		Methods:     methods,
 		Fields:      fields,
 		Value:       &std.Nil{},
 	}
 }
 
+// parseTypeName parses the name of a struct or interface after 'extends',
+// 'implements' or a comma, optionally qualified by a module (util.Animal).
+//
+// Returns:
+//
+//	The identifier with the (qualified) name, or nil on a syntax error
+func (par *Parser) parseTypeName() *IdentifierExpressionNode {
+	if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+		return nil
+	}
+	name := &IdentifierExpressionNode{
+		Token: par.CurrToken,
+		Name:  par.CurrToken.Literal,
+		Value: &std.Nil{},
+	}
+	if par.NextToken.Type == lexer.DOT_OP {
+		par.advance()
+		if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+			return nil
+		}
+		name.Name += "." + par.CurrToken.Literal
+	}
+	return name
+}
+
+// parseInterfaceDeclaration parses interface declarations.
+//
+// Syntax:
+//
+//	interface Name { method1(); method2(param1, param2); }
+//
+// The semicolons after the method signatures are optional.
+//
+// Returns:
+//
+//	An InterfaceDeclarationNode
+//
+// Examples:
+//
+//	interface Shape { area(); perimeter(); }
+//	interface Resizable { resize(width, height); }
+func (par *Parser) parseInterfaceDeclaration() StatementNode {
+	node := &InterfaceDeclarationNode{
+		InterfaceToken: par.CurrToken,
+		Methods:        make([]*InterfaceMethodNode, 0),
+		Value:          &std.Nil{},
+	}
+	if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+		return nil
+	}
+	node.InterfaceName = IdentifierExpressionNode{
+		Token: par.CurrToken,
+		Name:  par.CurrToken.Literal,
+		Value: &std.Nil{},
+	}
+	if !par.expectAdvance(lexer.LEFT_BRACE) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for par.NextToken.Type != lexer.RIGHT_BRACE {
+		if par.NextToken.Type == lexer.SEMICOLON_DELIM {
+			par.advance()
+			continue
+		}
+		if par.NextToken.Type != lexer.IDENTIFIER_ID {
+			msg := fmt.Sprintf("[%d:%d] PARSER ERROR: expected method signature in interface body, got %s",
+				par.NextToken.Line, par.NextToken.Column, par.NextToken.Type)
+			par.addError(msg)
+			return nil
+		}
+		par.advance()
+		method := &InterfaceMethodNode{
+			Name: IdentifierExpressionNode{
+				Token: par.CurrToken,
+				Name:  par.CurrToken.Literal,
+				Value: &std.Nil{},
+			},
+			Params: make([]*IdentifierExpressionNode, 0),
+		}
+		if seen[method.Name.Name] {
+			msg := fmt.Sprintf("[%d:%d] PARSER ERROR: method (%s) already declared in interface (%s)",
+				par.CurrToken.Line, par.CurrToken.Column, method.Name.Name, node.InterfaceName.Name)
+			par.addError(msg)
+			return nil
+		}
+		seen[method.Name.Name] = true
+		if !par.expectAdvance(lexer.LEFT_PAREN) {
+			return nil
+		}
+		for par.NextToken.Type != lexer.RIGHT_PAREN {
+			if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+				return nil
+			}
+			method.Params = append(method.Params, &IdentifierExpressionNode{
+				Token: par.CurrToken,
+				Name:  par.CurrToken.Literal,
+				Value: &std.Nil{},
+			})
+			if par.NextToken.Type != lexer.COMMA_DELIM {
+				break
+			}
+			par.advance() // Consume comma
+		}
+		if !par.expectAdvance(lexer.RIGHT_PAREN) {
+			return nil
+		}
+		node.Methods = append(node.Methods, method)
+	}
+
+	if !par.expectAdvance(lexer.RIGHT_BRACE) {
+		return nil
+	}
+	return node
+}
+
+// parseSuperExpression parses the 'super' keyword, which must be followed by
+// a member access: super.method(args) calls the method of the parent struct.
+//
+// Returns:
+//
+//	A SuperExpressionNode, the left operand of the member access
+func (par *Parser) parseSuperExpression() ExpressionNode {
+	if par.NextToken.Type != lexer.DOT_OP {
+		msg := fmt.Sprintf("[%d:%d] PARSER ERROR: expected '.' after super, got %s",
+			par.NextToken.Line, par.NextToken.Column, par.NextToken.Type)
+		par.addError(msg)
+		return nil
+	}
+	return &SuperExpressionNode{Token: par.CurrToken, Value: &std.Nil{}}
+}
+
 // parseNewCallExpression parses expressions for creating new instances of structs.
 //
 // Syntax:
@@ -107,36 +284,30 @@ func (par *Parser) parseNewCallExpression() ExpressionNode {
 		Name:  par.CurrToken.Literal,
 		Value: &std.Nil{}, // Default value for identifier
 	}
-
-	if !par.expectAdvance(lexer.LEFT_PAREN) {
-		return nil
-	}
-	// if there are arguments, parse them
-	if par.NextToken.Type != lexer.RIGHT_PAREN {
+	// Qualified struct name from an imported module: new util.Point(...)
+	if par.NextToken.Type == lexer.DOT_OP {
 		par.advance()
-		for {
-			arg := par.parseExpression()
-			if arg == nil {
-				return nil
-			}
-			newCallNode.Arguments = append(newCallNode.Arguments, arg)
-			if par.NextToken.Type == lexer.COMMA_DELIM {
-				par.advance()
-				par.advance()
-			} else {
-				break
-			}
+		if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+			return nil
 		}
+		newCallNode.StructName.Name += "." + par.CurrToken.Literal
 	}
 
-	if !par.expectAdvance(lexer.RIGHT_PAREN) {
+	if !par.expectAdvance(lexer.LEFT_PAREN) {
 		return nil
 	}
+	args, names, ok := par.parseArguments()
+	if !ok {
+		return nil
+	}
+	newCallNode.Arguments, newCallNode.ArgNames = args, names
+	newCallNode.RightParen = par.CurrToken
 	return newCallNode
 }
 
 // parseMemberAccess parses member access expressions (dot notation).
-// It handles both field access (obj.field) and method calls (obj.method()).
+// It handles field access (obj.field) and method references (obj.method);
+// a method call obj.method() is a call whose callee is the member access.
 // This function is specialized to allow keywords (like 'set', 'map', 'array')
 // to be used as member names, which parseBinaryExpression would otherwise reject
 // or misinterpret as start of a new expression.
@@ -157,15 +328,10 @@ func (par *Parser) parseMemberAccess(left ExpressionNode) ExpressionNode {
 		return nil
 	}
 
-	var right ExpressionNode
-	if par.NextToken.Type == lexer.LEFT_PAREN {
-		right = par.parseCallExpression()
-	} else {
-		right = &IdentifierExpressionNode{
-			Token: par.CurrToken,
-			Name:  par.CurrToken.Literal,
-			Value: &std.Nil{},
-		}
+	right := &IdentifierExpressionNode{
+		Token: par.CurrToken,
+		Name:  par.CurrToken.Literal,
+		Value: &std.Nil{},
 	}
 
 	return &BinaryExpressionNode{
@@ -238,6 +404,7 @@ func (par *Parser) parseEnumDeclaration() ExpressionNode {
 		memberName := par.CurrToken.Literal
 		memberToken := par.CurrToken
 		var memberValue std.GoMixObject
+		var valueToken lexer.Token
 
 		// Check for explicit value assignment
 		if par.NextToken.Type == lexer.ASSIGN_OP {
@@ -254,6 +421,7 @@ func (par *Parser) parseEnumDeclaration() ExpressionNode {
 					return nil
 				}
 				memberValue = &std.Integer{Value: val}
+				valueToken = par.CurrToken
 				autoValue = val + 1 // Next auto value continues from this
 			} else {
 				msg := fmt.Sprintf("[%d:%d] PARSER ERROR: enum member value must be an integer, got %s",
@@ -269,9 +437,10 @@ func (par *Parser) parseEnumDeclaration() ExpressionNode {
 
 		// Create member node
 		member := &EnumMemberNode{
-			Name:  memberName,
-			Value: memberValue,
-			Token: memberToken,
+			Name:       memberName,
+			Value:      memberValue,
+			Token:      memberToken,
+			ValueToken: valueToken,
 		}
 		members = append(members, member)
 
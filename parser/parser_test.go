@@ -6,6 +6,7 @@ Contact : akashmaji(@iisc.ac.in)
 package parser
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -64,6 +65,53 @@ func TestParser_OctalIntLiteral(t *testing.T) {
 	assert.Equal(t, &std.Integer{Value: 511}, exp.Value)
 }
 
+func TestParser_BigIntLiteral(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`123n`, "123"},
+		{`0xFFn`, "255"},
+		{`100000000000000000000`, "100000000000000000000"},
+		{`9223372036854775808`, "9223372036854775808"},
+		{`18446744073709551615`, "18446744073709551615"},
+		{`0x8000000000000000`, "9223372036854775808"},
+	}
+	for _, tt := range tests {
+		par := NewParser(tt.src)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), tt.src)
+		assert.Equal(t, 1, len(root.Statements))
+
+		exp, ok := root.Statements[0].(*IntegerLiteralExpressionNode)
+		assert.True(t, ok)
+		assert.Equal(t, tt.src, exp.Literal())
+		if bigObj, ok := exp.Value.(*std.BigInt); ok {
+			assert.Equal(t, tt.expected, bigObj.Value.String())
+		} else {
+			t.Errorf("Expected std.BigInt, got %T", exp.Value)
+		}
+	}
+}
+
+func TestParser_MinIntLiteral(t *testing.T) {
+	par := NewParser(`-9223372036854775808`)
+	root := par.Parse()
+	assert.False(t, par.HasErrors())
+	assert.Equal(t, 1, len(root.Statements))
+
+	exp, ok := root.Statements[0].(*IntegerLiteralExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, "-9223372036854775808", exp.Literal())
+	assert.Equal(t, &std.Integer{Value: math.MinInt64}, exp.Value)
+
+	// Only the plain literal is folded; a bigint literal stays a bigint
+	par = NewParser(`-9223372036854775808n`)
+	root = par.Parse()
+	_, ok = root.Statements[0].(*UnaryExpressionNode)
+	assert.True(t, ok)
+}
+
 func TestParser_ScientificFloatLiteral(t *testing.T) {
 	src := `1.4e3`
 	par := NewParser(src)
@@ -1920,6 +1968,73 @@ func TestParser_Str(t *testing.T) {
 	assert.Equal(t, `hello;there;boy;123;`, root.Literal())
 }
 
+// TestParser_InterpolatedStr verifies parsing of interpolated and raw string literals
+func TestParser_InterpolatedStr(t *testing.T) {
+	src := `"${a} and ${b}"`
+	root := NewParser(src).Parse()
+	assert.NotNil(t, root)
+
+	testingVisitor := &TestingVisitor{
+		ExpectedNodes: []Node{
+			&InterpolatedStringExpressionNode{},
+			&IdentifierExpressionNode{Name: "a"},
+			&IdentifierExpressionNode{Name: "b"},
+		},
+		Ptr: 0,
+		T:   t,
+	}
+	root.Accept(testingVisitor)
+
+	root = NewParser(`"Hello ${user.name}, you have ${len(items) + 1} items"`).Parse()
+	node, ok := root.Statements[0].(*InterpolatedStringExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Hello ", ", you have ", " items"}, node.Texts)
+	assert.Equal(t, 2, len(node.Exprs))
+	assert.Equal(t, `Hello ${user.name}, you have ${len(items)+1} items`, node.Literal())
+
+	// An embedded string literal is an expression, not text
+	root = NewParser(`"${"a"}${x}"`).Parse()
+	node = root.Statements[0].(*InterpolatedStringExpressionNode)
+	assert.Equal(t, []string{"", "", ""}, node.Texts)
+	_, ok = node.Exprs[0].(*StringLiteralExpressionNode)
+	assert.True(t, ok)
+
+	// A raw string is a plain string literal
+	root = NewParser("`a\\n${b}`").Parse()
+	str, ok := root.Statements[0].(*StringLiteralExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, &std.String{Value: "a\\n${b}"}, str.Value)
+
+	errorTests := []struct {
+		src string
+		err string
+	}{
+		{`"a ${} b"`, "[1:6] PARSER ERROR: empty expression in interpolated string"},
+		{`"a ${1 2} b"`, "unexpected token (2) in interpolated string"},
+	}
+	for _, tt := range errorTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.True(t, par.HasErrors(), tt.src)
+		assert.Contains(t, par.GetErrors()[0], tt.err)
+	}
+
+	// An invalid escape sequence is the only error reported
+	escapeTests := []struct {
+		src string
+		err string
+	}{
+		{`println("a\q", 1);`, "[1:11] PARSER ERROR: invalid escape sequence: \\q"},
+		{`var c = '\x4g';`, "[1:10] PARSER ERROR: invalid escape sequence: \\x4g"},
+		{`var s = "n = ${"\q"}";`, "[1:17] PARSER ERROR: invalid escape sequence: \\q"},
+	}
+	for _, tt := range escapeTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.Equal(t, []string{tt.err}, par.GetErrors(), tt.src)
+	}
+}
+
 // TestParser_Func verifies parsing of simple function declarations
 func TestParser_Func(t *testing.T) {
 	src := `func foo() {  }`
@@ -2056,7 +2171,7 @@ func TestParser_FuncCallArgs(t *testing.T) {
 	testingVisitor := &TestingVisitor{
 		ExpectedNodes: []Node{
 			&CallExpressionNode{
-				FunctionIdentifier: IdentifierExpressionNode{Name: "foo"},
+				Callee: &IdentifierExpressionNode{Name: "foo"},
 			},
 			&IntegerLiteralExpressionNode{Value: &std.Integer{Value: 1}},
 			&IntegerLiteralExpressionNode{Value: &std.Integer{Value: 2}},
@@ -2095,7 +2210,7 @@ func TestParser_FuncCallSimple(t *testing.T) {
 			},
 			&IntegerLiteralExpressionNode{Value: &std.Integer{Value: 2}},
 			&CallExpressionNode{
-				FunctionIdentifier: IdentifierExpressionNode{Name: "foo"},
+				Callee: &IdentifierExpressionNode{Name: "foo"},
 			},
 			&IdentifierExpressionNode{Name: "a"},
 			&IdentifierExpressionNode{Name: "b"},
@@ -2123,7 +2238,7 @@ func TestParser_FuncCallExpr(t *testing.T) {
 				Identifier: IdentifierExpressionNode{Name: "a"},
 			},
 			&CallExpressionNode{
-				FunctionIdentifier: IdentifierExpressionNode{Name: "foo"},
+				Callee: &IdentifierExpressionNode{Name: "foo"},
 			},
 			&IntegerLiteralExpressionNode{Value: &std.Integer{Value: 1}},
 			&IntegerLiteralExpressionNode{Value: &std.Integer{Value: 2}},
@@ -3160,7 +3275,7 @@ func TestParser_ArrayFunc(t *testing.T) {
 	// Check third statement: b()
 	callExpr, ok := rootNode.Statements[2].(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "b", callExpr.FunctionIdentifier.Name)
+	assert.Equal(t, "b", callExpr.Callee.Literal())
 }
 
 // TestParser_ArrayIndex verifies parsing of array index access expressions
@@ -3390,6 +3505,30 @@ func TestParser_ForeachNested(t *testing.T) {
 	assert.Equal(t, 1, len(innerForeach.Body.Statements))
 }
 
+// TestParser_ForeachKeyValue verifies parsing of foreach loops with a key and a value
+func TestParser_ForeachKeyValue(t *testing.T) {
+	src := `foreach k, v in m { }`
+	par := NewParser(src)
+	root := par.Parse()
+	assert.False(t, par.HasErrors())
+
+	foreachStmt, ok := root.Statements[0].(*ForeachLoopStatementNode)
+	assert.True(t, ok)
+	assert.NotNil(t, foreachStmt.Key)
+	assert.Equal(t, "k", foreachStmt.Key.Name)
+	assert.Equal(t, "v", foreachStmt.Iterator.Name)
+	assert.Equal(t, "foreach k, v in m {}", foreachStmt.Literal())
+
+	// A single identifier has no key
+	root = NewParser(`foreach v in m { }`).Parse()
+	assert.Nil(t, root.Statements[0].(*ForeachLoopStatementNode).Key)
+
+	// The second identifier is required after the comma
+	par = NewParser(`foreach k, in m { }`)
+	par.Parse()
+	assert.True(t, par.HasErrors())
+}
+
 // TestParser_RangeLiteral verifies range expression literal representation
 func TestParser_RangeLiteral(t *testing.T) {
 	src := `var x = 5...15`
@@ -3434,7 +3573,7 @@ func TestParser_ListCall(t *testing.T) {
 		// Check the statement is a call expression
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, "list", callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, "list", callExpr.Callee.Literal())
 		assert.Equal(t, tt.expectedLen, len(callExpr.Arguments))
 	}
 }
@@ -3458,7 +3597,7 @@ func TestParser_TupleCall(t *testing.T) {
 		// Check the statement is a call expression
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, "tuple", callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, "tuple", callExpr.Callee.Literal())
 		assert.Equal(t, tt.expectedLen, len(callExpr.Arguments))
 	}
 }
@@ -3478,7 +3617,7 @@ func TestParser_ListVar(t *testing.T) {
 	// Check the expression is a call to list()
 	callExpr, ok := declStmt.Expr.(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "list", callExpr.FunctionIdentifier.Name)
+	assert.Equal(t, "list", callExpr.Callee.Literal())
 	assert.Equal(t, 3, len(callExpr.Arguments))
 }
 
@@ -3497,7 +3636,7 @@ func TestParser_TupleVar(t *testing.T) {
 	// Check the expression is a call to tuple()
 	callExpr, ok := declStmt.Expr.(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "tuple", callExpr.FunctionIdentifier.Name)
+	assert.Equal(t, "tuple", callExpr.Callee.Literal())
 	assert.Equal(t, 3, len(callExpr.Arguments))
 }
 
@@ -3525,7 +3664,7 @@ func TestParser_ListFunctions(t *testing.T) {
 		// Check the statement is a call expression
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, tt.funcName, callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, tt.funcName, callExpr.Callee.Literal())
 		assert.Equal(t, tt.argCount, len(callExpr.Arguments))
 	}
 }
@@ -3550,7 +3689,7 @@ func TestParser_TupleFunctions(t *testing.T) {
 		// Check the statement is a call expression
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, tt.funcName, callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, tt.funcName, callExpr.Callee.Literal())
 		assert.Equal(t, tt.argCount, len(callExpr.Arguments))
 	}
 }
@@ -3658,7 +3797,7 @@ func TestParser_ForeachList(t *testing.T) {
 	// Check iterable is a call to list()
 	callExpr, ok := foreachStmt.Iterable.(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "list", callExpr.FunctionIdentifier.Name)
+	assert.Equal(t, "list", callExpr.Callee.Literal())
 }
 
 // TestParser_ForeachTuple verifies parsing of foreach loops with tuples
@@ -3676,7 +3815,7 @@ func TestParser_ForeachTuple(t *testing.T) {
 	// Check iterable is a call to tuple()
 	callExpr, ok := foreachStmt.Iterable.(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "tuple", callExpr.FunctionIdentifier.Name)
+	assert.Equal(t, "tuple", callExpr.Callee.Literal())
 }
 
 // TestParser_ListNested verifies parsing of nested lists
@@ -3694,13 +3833,13 @@ func TestParser_ListNested(t *testing.T) {
 	// Check the expression is a call to list()
 	outerCall, ok := declStmt.Expr.(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "list", outerCall.FunctionIdentifier.Name)
+	assert.Equal(t, "list", outerCall.Callee.Literal())
 	assert.Equal(t, 2, len(outerCall.Arguments))
 
 	// Check first argument is also a list() call
 	innerCall1, ok := outerCall.Arguments[0].(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "list", innerCall1.FunctionIdentifier.Name)
+	assert.Equal(t, "list", innerCall1.Callee.Literal())
 }
 
 // TestParser_TupleNested verifies parsing of nested tuples
@@ -3718,13 +3857,13 @@ func TestParser_TupleNested(t *testing.T) {
 	// Check the expression is a call to tuple()
 	outerCall, ok := declStmt.Expr.(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "tuple", outerCall.FunctionIdentifier.Name)
+	assert.Equal(t, "tuple", outerCall.Callee.Literal())
 	assert.Equal(t, 2, len(outerCall.Arguments))
 
 	// Check first argument is also a tuple() call
 	innerCall1, ok := outerCall.Arguments[0].(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "tuple", innerCall1.FunctionIdentifier.Name)
+	assert.Equal(t, "tuple", innerCall1.Callee.Literal())
 }
 
 // TestParser_ListIndexAssignment verifies parsing of list index assignment
@@ -3750,7 +3889,7 @@ func TestParser_ListMixed(t *testing.T) {
 	// Check the statement is a call expression
 	callExpr, ok := root.Statements[0].(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "list", callExpr.FunctionIdentifier.Name)
+	assert.Equal(t, "list", callExpr.Callee.Literal())
 	assert.Equal(t, 4, len(callExpr.Arguments))
 
 	// Check argument types
@@ -3777,7 +3916,7 @@ func TestParser_TupleMixed(t *testing.T) {
 	// Check the statement is a call expression
 	callExpr, ok := root.Statements[0].(*CallExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "tuple", callExpr.FunctionIdentifier.Name)
+	assert.Equal(t, "tuple", callExpr.Callee.Literal())
 	assert.Equal(t, 4, len(callExpr.Arguments))
 
 	// Check argument types
@@ -3817,7 +3956,7 @@ func TestParser_ListTupleNewBuiltins(t *testing.T) {
 		// Check the statement is a call expression
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, tt.funcName, callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, tt.funcName, callExpr.Callee.Literal())
 		assert.Equal(t, tt.argCount, len(callExpr.Arguments))
 	}
 }
@@ -3977,6 +4116,19 @@ func TestParser_ParseNewCall(t *testing.T) {
 				&StringLiteralExpressionNode{Value: &std.String{Value: "foo"}},
 			},
 		},
+		{
+			Expr: `var p = new util.Point(1)`,
+			Expected: []Node{
+				&DeclarativeStatementNode{
+					VarToken:   lexer.Token{Literal: "var"},
+					Identifier: IdentifierExpressionNode{Name: "p"},
+				},
+				&NewCallExpressionNode{
+					StructName: IdentifierExpressionNode{Name: "util.Point"},
+				},
+				&IntegerLiteralExpressionNode{Value: &std.Integer{Value: 1}},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -4076,13 +4228,414 @@ func TestParser_MethodCall(t *testing.T) {
 	assert.False(t, par.HasErrors())
 
 	assert.Equal(t, 1, len(root.Statements))
-	binExpr, ok := root.Statements[0].(*BinaryExpressionNode)
+	callExpr, ok := root.Statements[0].(*CallExpressionNode)
 	assert.True(t, ok)
+	assert.Equal(t, 2, len(callExpr.Arguments))
 
-	right, ok := binExpr.Right.(*CallExpressionNode)
+	binExpr, ok := callExpr.Callee.(*BinaryExpressionNode)
 	assert.True(t, ok)
-	assert.Equal(t, "method", right.FunctionIdentifier.Name)
-	assert.Equal(t, 2, len(right.Arguments))
+	assert.Equal(t, lexer.DOT_OP, binExpr.Operation.Type)
+
+	right, ok := binExpr.Right.(*IdentifierExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, "method", right.Name)
+}
+
+// TestParser_CallOnExpressions verifies parsing of calls whose callee is any expression
+func TestParser_CallOnExpressions(t *testing.T) {
+	tests := []struct {
+		src     string
+		callee  string
+		argc    int
+		literal string
+	}{
+		{`makeAdder(1)(2)`, "makeAdder(1)", 1, "makeAdder(1)(2)"},
+		{`handlers[key](req)`, "handlers[key]", 1, "handlers[key](req)"},
+		{`obj.field.method()`, "obj.field.method", 0, "obj.field.method()"},
+		{`(func(x) { return x; })(3)`, "(func  (x) {return x;})", 1, "(func  (x) {return x;})(3)"},
+		{`map(1)`, "map", 1, "map(1)"},
+	}
+
+	for _, tt := range tests {
+		par := NewParser(tt.src)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), tt.src)
+		assert.Equal(t, 1, len(root.Statements))
+
+		callExpr, ok := root.Statements[0].(*CallExpressionNode)
+		assert.True(t, ok, tt.src)
+		assert.Equal(t, tt.callee, callExpr.Callee.Literal())
+		assert.Equal(t, tt.argc, len(callExpr.Arguments))
+		assert.Equal(t, tt.literal, callExpr.Literal())
+	}
+
+	// Calls bind tighter than prefix operators and member access
+	root := NewParser(`-f(1)`).Parse()
+	unary, ok := root.Statements[0].(*UnaryExpressionNode)
+	assert.True(t, ok)
+	_, ok = unary.Right.(*CallExpressionNode)
+	assert.True(t, ok)
+
+	root = NewParser(`obj.get().val`).Parse()
+	member, ok := root.Statements[0].(*BinaryExpressionNode)
+	assert.True(t, ok)
+	_, ok = member.Left.(*CallExpressionNode)
+	assert.True(t, ok)
+}
+
+// TestParser_FunctionParameters verifies parsing of default and rest parameters and of named arguments
+func TestParser_FunctionParameters(t *testing.T) {
+	tests := []struct {
+		src      string
+		params   []string
+		defaults []string
+		rest     bool
+		literal  string
+	}{
+		{`func f(a, b = 10) {}`, []string{"a", "b"}, []string{"", "10"}, false, "func f (a,b=10) {}"},
+		{`func f(...nums) {}`, []string{"nums"}, []string{""}, true, "func f (...nums) {}"},
+		{`func f(a, b = a * 2, ...more) {}`, []string{"a", "b", "more"}, []string{"", "a*2", ""}, true, "func f (a,b=a*2,...more) {}"},
+		{`var f = func(p = "hi") {};`, []string{"p"}, []string{"hi"}, false, "func  (p=hi) {}"},
+	}
+
+	for _, tt := range tests {
+		par := NewParser(tt.src)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), tt.src)
+
+		var fn *FunctionStatementNode
+		switch stmt := root.Statements[0].(type) {
+		case *FunctionStatementNode:
+			fn = stmt
+		case *DeclarativeStatementNode:
+			fn = stmt.Expr.(*FunctionStatementNode)
+		}
+		assert.NotNil(t, fn, tt.src)
+		assert.Equal(t, len(tt.params), len(fn.FuncParams))
+		for i, name := range tt.params {
+			assert.Equal(t, name, fn.FuncParams[i].Name)
+			if tt.defaults[i] == "" {
+				assert.Nil(t, fn.Default(i), tt.src)
+			} else {
+				assert.Equal(t, tt.defaults[i], fn.Default(i).Literal(), tt.src)
+			}
+		}
+		assert.Equal(t, tt.rest, fn.FuncRest)
+		assert.Equal(t, tt.literal, fn.Literal())
+	}
+
+	// Named arguments of calls and of constructors
+	root := NewParser(`f(1, b: 2, c: x)`).Parse()
+	callExpr, ok := root.Statements[0].(*CallExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"", "b", "c"}, callExpr.ArgNames)
+	assert.Equal(t, "f(1,b:2,c:x)", callExpr.Literal())
+
+	root = NewParser(`new Point(y: 3)`).Parse()
+	newExpr, ok := root.Statements[0].(*NewCallExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"y"}, newExpr.ArgNames)
+
+	root = NewParser(`f(1, 2)`).Parse()
+	assert.Nil(t, root.Statements[0].(*CallExpressionNode).ArgNames)
+
+	errorTests := []struct {
+		src string
+		err string
+	}{
+		{`func f(a = 1, b) {}`, "parameter (b) without a default value follows a parameter with one"},
+		{`func f(...a, b) {}`, "rest parameter (...a) must be the last parameter"},
+		{`f(a: 1, 2)`, "positional argument follows a named argument"},
+		{`f(a: 1, a: 2)`, "argument (a) is passed more than once"},
+	}
+	for _, tt := range errorTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.True(t, par.HasErrors(), tt.src)
+		assert.Contains(t, par.GetErrors()[0], tt.err)
+	}
+}
+
+// TestParser_Destructuring verifies parsing of multiple return values and of destructuring patterns
+func TestParser_Destructuring(t *testing.T) {
+	tests := []struct {
+		src     string
+		literal string
+	}{
+		{`var (q, r) = divmod(7, 2);`, "var (q,r) = divmod(7,2)"},
+		{`let [first, ...rest] = arr;`, "let [first,...rest] = arr"},
+		{`const {name, age} = person;`, "const {name,age} = person"},
+		{`(a, b) = (b, a)`, "(a,b) = (b,a)"},
+		{`{x, ...others} = m`, "{x,...others} = m"},
+		{`[a] = arr`, "[a] = arr"},
+		{`foreach i, (k, v) in pairs {}`, "foreach i, (k,v) in pairs {}"},
+		{`func f() { return 1, 2; }`, "func f () {return 1,2;}"},
+	}
+
+	for _, tt := range tests {
+		par := NewParser(tt.src)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), tt.src)
+		assert.Equal(t, 1, len(root.Statements), tt.src)
+		assert.Equal(t, tt.literal, root.Statements[0].Literal(), tt.src)
+	}
+
+	// The names of a pattern are declared with the keyword of the declaration
+	root := NewParser(`let [a, ...b] = [1, 2];`).Parse()
+	decl, ok := root.Statements[0].(*DeclarativeStatementNode)
+	assert.True(t, ok)
+	assert.Equal(t, lexer.LEFT_BRACKET, decl.Pattern.Open.Type)
+	assert.Equal(t, 1, len(decl.Pattern.Names))
+	assert.Equal(t, "b", decl.Pattern.Rest.Name)
+	for _, name := range decl.Pattern.Bindings() {
+		assert.True(t, name.IsLet)
+	}
+
+	// A parenthesized list is a tuple, a parenthesized value is not
+	root = NewParser(`(1, "two", 3.0)`).Parse()
+	tuple, ok := root.Statements[0].(*TupleExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(tuple.Elements))
+	root = NewParser(`(1)`).Parse()
+	_, ok = root.Statements[0].(*ParenthesizedExpressionNode)
+	assert.True(t, ok)
+
+	// A brace that does not start an assignment is still a block
+	root = NewParser(`{a, b}`).Parse()
+	_, ok = root.Statements[0].(*BlockStatementNode)
+	assert.True(t, ok)
+
+	errorTests := []struct {
+		src string
+		err string
+	}{
+		{`var [...a, b] = arr;`, "rest element (...a) must be the last one in a pattern"},
+		{`var () = t;`, "empty destructuring pattern"},
+		{`var (a, 1) = t;`, "expected Identifier"},
+		{`struct S { var (a, b) = (1, 2); }`, "struct fields cannot be declared with a destructuring pattern"},
+	}
+	for _, tt := range errorTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.True(t, par.HasErrors(), tt.src)
+		assert.Contains(t, par.GetErrors()[0], tt.err)
+	}
+}
+
+// TestParser_Inheritance verifies parsing of extends, implements, interfaces, super and instanceof
+func TestParser_Inheritance(t *testing.T) {
+	tests := []struct {
+		src     string
+		literal string
+	}{
+		{`struct Dog extends Animal { }`, "struct Dog extends Animal {}"},
+		{`struct Sq extends geo.Shape implements Area, Named { }`, "struct Sq extends geo.Shape implements Area, Named {}"},
+		{`interface Shape { area(); scale(x, y) }`, "interface Shape {area(); scale(x, y); }"},
+		{`interface Empty {}`, "interface Empty {}"},
+		{`super.speak()`, "super.speak()"},
+	}
+
+	for _, tt := range tests {
+		par := NewParser(tt.src)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), tt.src)
+		assert.Equal(t, 1, len(root.Statements), tt.src)
+		assert.Equal(t, tt.literal, root.Statements[0].Literal(), tt.src)
+	}
+
+	root := NewParser(`struct Dog extends Animal implements Pet { func speak() { return super.speak(); } }`).Parse()
+	decl, ok := root.Statements[0].(*StructDeclarationNode)
+	assert.True(t, ok)
+	assert.Equal(t, "Animal", decl.Parent.Name)
+	assert.Equal(t, 1, len(decl.Interfaces))
+	assert.Equal(t, "Pet", decl.Interfaces[0].Name)
+
+	// instanceof binds like the relational operators, tighter than ==
+	root = NewParser(`d instanceof Dog == true`).Parse()
+	eq, ok := root.Statements[0].(*BooleanExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, lexer.EQ_OP, eq.Operation.Type)
+	test, ok := eq.Left.(*BooleanExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, lexer.INSTANCEOF_KEY, test.Operation.Type)
+
+	errorTests := []struct {
+		src string
+		err string
+	}{
+		{`var s = super;`, "expected '.' after super"},
+		{`interface Shape { var x = 1; }`, "expected method signature in interface body"},
+		{`interface Shape { area(); area(); }`, "method (area) already declared in interface (Shape)"},
+		{`struct Dog extends { }`, "expected Identifier"},
+	}
+	for _, tt := range errorTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.True(t, par.HasErrors(), tt.src)
+		assert.Contains(t, par.GetErrors()[0], tt.err)
+	}
+}
+
+// TestParser_TypeAnnotations verifies parsing of the type annotations of
+// variables, parameters, return values and struct fields
+func TestParser_TypeAnnotations(t *testing.T) {
+	tests := []struct {
+		src     string
+		literal string
+	}{
+		{`var x: int = 1;`, `var x: int = 1`},
+		{`let names: array<string> = [];`, `let names: array<string> = []`},
+		{`var m: map<string, array<int>> = map{};`, `var m: map<string, array<int>> = map{}`},
+		{`var v: int | nil = nil;`, `var v: int | nil = nil`},
+		{`var a: array<int>=[];`, `var a: array<int> = []`},
+		{`var a: array<array<int>>=[];`, `var a: array<array<int>> = []`},
+		{`var p: geo.Point = nil;`, `var p: geo.Point = nil`},
+		{`var t: tuple<int, string | nil> = nil;`, `var t: tuple<int, string | nil> = nil`},
+		{`func area(w: float, h: float): float { return w * h; }`, `func area (w: float,h: float): float {return w*h;}`},
+		{`func f(a, b: int = 2, ...rest: array<int>) { }`, `func f (a,b: int=2,...rest: array<int>) {}`},
+		{`var g = func(s: string): string { return s; };`, `var g = func  (s: string): string {return s;}`},
+	}
+
+	for _, tt := range tests {
+		par := NewParser(tt.src)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), tt.src, par.GetErrors())
+		assert.Equal(t, 1, len(root.Statements), tt.src)
+		assert.Equal(t, tt.literal, root.Statements[0].Literal(), tt.src)
+	}
+
+	// The annotations are attached to the declarations
+	root := NewParser(`struct P { var x: float = 0; func at(i, j: int): P | nil { return nil; } }`).Parse()
+	decl, ok := root.Statements[0].(*StructDeclarationNode)
+	assert.True(t, ok)
+	assert.Equal(t, "float", decl.Fields[0].VarType.String())
+	method := decl.Methods[0]
+	assert.Nil(t, method.ParamType(0))
+	assert.Equal(t, "int", method.ParamType(1).String())
+	assert.True(t, method.ReturnType.IsUnion())
+	assert.Equal(t, "P | nil", method.ReturnType.String())
+
+	errorTests := []struct {
+		src string
+		err string
+	}{
+		{`var x: = 1;`, "expected a type, got ="},
+		{`var x: int<string> = 1;`, "type (int) takes no type arguments"},
+		{`var x: map<string> = 1;`, "type (map) takes 2 type argument(s), got 1"},
+		{`var x: array<int = 1;`, "expected >, got ="},
+		{`func f(a: ) { }`, "expected a type, got )"},
+	}
+	for _, tt := range errorTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.True(t, par.HasErrors(), tt.src)
+		assert.Contains(t, par.GetErrors()[0], tt.err, tt.src)
+	}
+}
+
+// TestParser_Yield verifies parsing of yield statements and the marking of
+// the functions holding them as generators
+func TestParser_Yield(t *testing.T) {
+	root := NewParser(`func count(n) { for (var i = 0; i < n; i = i + 1) { yield i * 2; } }`).Parse()
+	fn, ok := root.Statements[0].(*FunctionStatementNode)
+	assert.True(t, ok)
+	assert.True(t, fn.Generator)
+	loop := fn.FuncBody.Statements[0].(*ForLoopStatementNode)
+	yield, ok := loop.Body.Statements[0].(*YieldStatementNode)
+	assert.True(t, ok)
+	assert.Equal(t, "yield i*2", yield.Literal())
+
+	// Only the innermost function holding the yield is a generator
+	root = NewParser(`func outer() { var g = func() { yield 1; }; return g; }`).Parse()
+	outer := root.Statements[0].(*FunctionStatementNode)
+	assert.False(t, outer.Generator)
+	inner := outer.FuncBody.Statements[0].(*DeclarativeStatementNode).Expr.(*FunctionStatementNode)
+	assert.True(t, inner.Generator)
+
+	root = NewParser(`struct Tree { func walk() { yield this; } func size() { return 1; } }`).Parse()
+	decl := root.Statements[0].(*StructDeclarationNode)
+	assert.True(t, decl.Methods[0].Generator)
+	assert.False(t, decl.Methods[1].Generator)
+
+	errorTests := []struct {
+		src string
+		err string
+	}{
+		{`yield 1;`, "yield outside a function"},
+		{`func f() { yield; }`, "expected expression after 'yield'"},
+	}
+	for _, tt := range errorTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.True(t, par.HasErrors(), tt.src)
+		assert.Contains(t, par.GetErrors()[0], tt.err, tt.src)
+	}
+}
+
+// TestParser_Match verifies parsing of match expressions, their patterns and guards
+func TestParser_Match(t *testing.T) {
+	tests := []struct {
+		src     string
+		literal string
+	}{
+		{`match (x) { 0 => "zero", _ => "other" }`, `match (x) { 0 => zero, _ => other }`},
+		{`match (n) { 1...5 if n > 2 => a, -1 | 0 => b, }`, `match (n) { 1...5 if n>2 => a, -1 | 0 => b }`},
+		{`match (c) { Color.RED => 1, Color.GREEN => 2 }`, `match (c) { Color.RED => 1, Color.GREEN => 2 }`},
+		{`match (v) { [x, y, ...rest] => x, (a, _) => a, (only,) => only, ((z)) => z }`, `match (v) { [x, y, ...rest] => x, (a, _) => a, (only,) => only, z => z }`},
+		{`match (m) { {name, "full name": f, ...others} => name }`, `match (m) { {name, "full name": f, ...others} => name }`},
+		{`match (p) { Point{x, y: 0} => x, geo.Point{x: 0 | 1} => 0 }`, `match (p) { Point{x, y: 0} => x, geo.Point{x: 0 | 1} => 0 }`},
+		{`match (v) { x => { x; } _ => 0 }`, `match (v) { x => {x;}, _ => 0 }`},
+		{`var r = match (a, b) { (0, 0) => 0, _ => 1 };`, `var r = match (a,b) { (0, 0) => 0, _ => 1 }`},
+	}
+
+	for _, tt := range tests {
+		par := NewParser(tt.src)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), tt.src, par.GetErrors())
+		assert.Equal(t, 1, len(root.Statements), tt.src)
+		assert.Equal(t, tt.literal, root.Statements[0].Literal(), tt.src)
+	}
+
+	// The patterns are classified by kind, and bind their names in order
+	root := NewParser(`match (v) { [a, {k: b}, Point{c}, ...d] if a > 0 => 1, 2...3 | [_, _] => 2 }`).Parse()
+	match, ok := root.Statements[0].(*MatchExpressionNode)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(match.Arms))
+	first := match.Arms[0].Pattern
+	assert.Equal(t, SequencePattern, first.Kind)
+	assert.Equal(t, MapPattern, first.Elements[1].Kind)
+	assert.Equal(t, StructPattern, first.Elements[2].Kind)
+	assert.NotNil(t, match.Arms[0].Guard)
+	names := make([]string, 0)
+	for _, name := range first.Bindings() {
+		names = append(names, name.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, names)
+	second := match.Arms[1].Pattern
+	assert.Equal(t, AlternativePattern, second.Kind)
+	assert.Equal(t, RangePattern, second.Elements[0].Kind)
+	assert.Nil(t, match.Arms[1].Guard)
+
+	errorTests := []struct {
+		src string
+		err string
+	}{
+		{`match (x) { }`, "match expression without arms"},
+		{`match x { _ => 1 }`, "expected (, got Identifier"},
+		{`match (x) { 1 => a 2 => b }`, "expected }, got IntLiteral"},
+		{`match (x) { [a, a] => a }`, "name (a) is bound more than once in a pattern"},
+		{`match (x) { [a, ...r, b] => a }`, "rest element (...r) must be the last one in a pattern"},
+		{`match (x) { [a, 1] | [1, b] => 0 }`, "the alternatives of a pattern must bind the same names"},
+		{`match (x) { Point{...r} => 0 }`, "expected a key in a pattern, got ..."},
+		{`match (x) { () => 0 }`, "empty destructuring pattern"},
+		{`match (x) { _ -> 0 }`, "expected =>, got -"},
+	}
+	for _, tt := range errorTests {
+		par := NewParser(tt.src)
+		par.Parse()
+		assert.True(t, par.HasErrors(), tt.src)
+		assert.Contains(t, par.GetErrors()[0], tt.err, tt.src)
+	}
 }
 
 // TestParser_StructFields verifies parsing of struct with const, let, var fields
@@ -4309,7 +4862,7 @@ func TestParser_StructArrayFieldPush(t *testing.T) {
 			&FunctionStatementNode{FuncName: IdentifierExpressionNode{Name: "add"}},
 			&IdentifierExpressionNode{Name: "x"},
 			&BlockStatementNode{},
-			&CallExpressionNode{FunctionIdentifier: IdentifierExpressionNode{Name: "push"}},
+			&CallExpressionNode{Callee: &IdentifierExpressionNode{Name: "push"}},
 			&IdentifierExpressionNode{Name: "self"},
 			&BinaryExpressionNode{Operation: lexer.Token{Type: lexer.DOT_OP, Literal: "."}},
 			&IdentifierExpressionNode{Name: "arr"},
@@ -4332,9 +4885,11 @@ func TestParser_StructRecursiveMethod(t *testing.T) {
 			&StructDeclarationNode{StructName: IdentifierExpressionNode{Name: "S"}},
 			&FunctionStatementNode{FuncName: IdentifierExpressionNode{Name: "f"}},
 			&BlockStatementNode{},
-			&IdentifierExpressionNode{Name: "this"},
-			&BinaryExpressionNode{Operation: lexer.Token{Type: lexer.DOT_OP, Literal: "."}},
-			&CallExpressionNode{FunctionIdentifier: IdentifierExpressionNode{Name: "f"}},
+			&CallExpressionNode{Callee: &BinaryExpressionNode{
+				Left:      &IdentifierExpressionNode{Name: "this"},
+				Operation: lexer.Token{Type: lexer.DOT_OP, Literal: "."},
+				Right:     &IdentifierExpressionNode{Name: "f"},
+			}},
 		},
 		Ptr: 0,
 		T:   t,
@@ -4513,9 +5068,11 @@ func TestParser_StructMethodChainedCall(t *testing.T) {
 
 	testingVisitor := &TestingVisitor{
 		ExpectedNodes: []Node{
-			&IdentifierExpressionNode{Name: "obj"},
-			&BinaryExpressionNode{Operation: lexer.Token{Type: lexer.DOT_OP, Literal: "."}},
-			&CallExpressionNode{FunctionIdentifier: IdentifierExpressionNode{Name: "get"}},
+			&CallExpressionNode{Callee: &BinaryExpressionNode{
+				Left:      &IdentifierExpressionNode{Name: "obj"},
+				Operation: lexer.Token{Type: lexer.DOT_OP, Literal: "."},
+				Right:     &IdentifierExpressionNode{Name: "get"},
+			}},
 			&BinaryExpressionNode{Operation: lexer.Token{Type: lexer.DOT_OP, Literal: "."}},
 			&IdentifierExpressionNode{Name: "val"},
 		},
@@ -4600,7 +5157,7 @@ func TestParser_StringFunctions(t *testing.T) {
 
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, tt.funcName, callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, tt.funcName, callExpr.Callee.Literal())
 		assert.Equal(t, tt.argCount, len(callExpr.Arguments))
 	}
 }
@@ -4626,7 +5183,7 @@ func TestParser_TimeFunctions(t *testing.T) {
 
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, tt.funcName, callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, tt.funcName, callExpr.Callee.Literal())
 		assert.Equal(t, tt.argCount, len(callExpr.Arguments))
 	}
 }
@@ -4652,7 +5209,7 @@ func TestParser_FormatFunctions(t *testing.T) {
 
 		callExpr, ok := root.Statements[0].(*CallExpressionNode)
 		assert.True(t, ok)
-		assert.Equal(t, tt.funcName, callExpr.FunctionIdentifier.Name)
+		assert.Equal(t, tt.funcName, callExpr.Callee.Literal())
 		assert.Equal(t, tt.argCount, len(callExpr.Arguments))
 	}
 }
@@ -5289,3 +5846,118 @@ func TestParseSwitchInLoop(t *testing.T) {
 
 	assert.Equal(t, 1, len(switchNode.Cases), "Switch in loop should have 1 case")
 }
+
+// TestParser_TryCatchFinally verifies parsing of try/catch/finally statements
+func TestParser_TryCatchFinally(t *testing.T) {
+	tests := []struct {
+		Input        string
+		CatchParam   string
+		HasCatch     bool
+		HasFinally   bool
+		TryStmts     int
+		CatchStmts   int
+		FinallyStmts int
+	}{
+		{`try { a(); } catch (err) { b(); }`, "err", true, false, 1, 1, 0},
+		{`try { a(); b(); } catch { c(); }`, "", true, false, 2, 1, 0},
+		{`try { a(); } finally { b(); c(); }`, "", false, true, 1, 0, 2},
+		{`try { } catch (e) { } finally { }`, "e", true, true, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		par := NewParser(tt.Input)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), "input: %s, errors: %v", tt.Input, par.GetErrors())
+		assert.Equal(t, 1, len(root.Statements))
+
+		tryNode, ok := root.Statements[0].(*TryStatementNode)
+		if !assert.True(t, ok, "input: %s", tt.Input) {
+			continue
+		}
+		assert.Equal(t, tt.TryStmts, len(tryNode.TryBlock.Statements))
+		assert.Equal(t, tt.HasCatch, tryNode.CatchBlock != nil)
+		assert.Equal(t, tt.HasFinally, tryNode.FinallyBlock != nil)
+		if tt.HasCatch {
+			assert.Equal(t, tt.CatchStmts, len(tryNode.CatchBlock.Statements))
+		}
+		if tt.HasFinally {
+			assert.Equal(t, tt.FinallyStmts, len(tryNode.FinallyBlock.Statements))
+		}
+		if tt.CatchParam != "" {
+			assert.NotNil(t, tryNode.CatchParam)
+			assert.Equal(t, tt.CatchParam, tryNode.CatchParam.Name)
+		} else {
+			assert.Nil(t, tryNode.CatchParam)
+		}
+	}
+}
+
+// TestParser_Throw verifies parsing of throw statements
+func TestParser_Throw(t *testing.T) {
+	par := NewParser(`throw "bad input";`)
+	root := par.Parse()
+	assert.False(t, par.HasErrors())
+	assert.Equal(t, 1, len(root.Statements))
+
+	throwNode, ok := root.Statements[0].(*ThrowStatementNode)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, `throw bad input`, throwNode.Literal())
+		testingVisitor := &TestingVisitor{
+			ExpectedNodes: []Node{
+				&ThrowStatementNode{},
+				&StringLiteralExpressionNode{Value: &std.String{Value: "bad input"}},
+			},
+			Ptr: 0,
+			T:   t,
+		}
+		root.Accept(testingVisitor)
+	}
+}
+
+// TestParser_ParseErrorTryThrow verifies error handling for malformed try and throw statements
+func TestParser_ParseErrorTryThrow(t *testing.T) {
+	tests := []string{
+		`try { a(); }`,
+		`try a(); catch (e) { }`,
+		`try { } catch (1) { }`,
+		`try { } catch (e { }`,
+		`try { } finally`,
+		`throw;`,
+	}
+	for _, test := range tests {
+		parser := NewParser(test)
+		rootNode := parser.Parse()
+		assert.NotNil(t, rootNode)
+		assert.True(t, parser.HasErrors(), "expected errors for input: %s", test)
+	}
+}
+
+// TestParser_Spawn verifies parsing of spawn statements
+func TestParser_Spawn(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Literal string
+	}{
+		{`spawn worker(1, ch);`, `spawn worker(1,ch)`},
+		{`spawn c.run();`, `spawn c.run()`},
+		{`spawn sync.wait_waitgroup(wg);`, `spawn sync.wait_waitgroup(wg)`},
+	}
+	for _, tt := range tests {
+		par := NewParser(tt.Input)
+		root := par.Parse()
+		assert.False(t, par.HasErrors(), "input: %s, errors: %v", tt.Input, par.GetErrors())
+		assert.Equal(t, 1, len(root.Statements))
+
+		spawnNode, ok := root.Statements[0].(*SpawnStatementNode)
+		if assert.True(t, ok, "input: %s", tt.Input) {
+			assert.Equal(t, tt.Literal, spawnNode.Literal())
+		}
+	}
+
+	for _, input := range []string{`spawn;`, `spawn 1 + 2;`, `spawn worker;`, `spawn c.field;`} {
+		par := NewParser(input)
+		par.Parse()
+		assert.True(t, par.HasErrors(), "expected errors for input: %s", input)
+	}
+}
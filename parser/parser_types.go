@@ -0,0 +1,143 @@
+/*
+File    : go-mix/parser/parser_types.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+package parser
+
+import (
+	"fmt"
+
+	"github.com/akashmaji946/go-mix/lexer"
+	"github.com/akashmaji946/go-mix/std"
+)
+
+// typeNameTokens are the keywords that are also type names.
+var typeNameTokens = map[lexer.TokenType]bool{
+	lexer.ARRAY_KEY:     true,
+	lexer.MAP_KEY:       true,
+	lexer.SET_KEY:       true,
+	lexer.FUNC_KEY:      true,
+	lexer.NIL_LIT:       true,
+	lexer.STRUCT_KEY:    true,
+	lexer.ENUM_KEY:      true,
+	lexer.INTERFACE_KEY: true,
+}
+
+// parseTypeAnnotation parses the type after the ':' of an annotation, from
+// the token after the ':'. The parser is left on the last token of the type.
+//
+// Syntax:
+//
+//	int                   a builtin type (or any)
+//	Point, util.Point     a struct, an interface or an enum
+//	array<string>         a generic builtin with its element types
+//	map<string, int>
+//	int | nil             a union of types
+//
+// Returns:
+//
+//	The type, or nil on a syntax error
+func (par *Parser) parseTypeAnnotation() *std.TypeAnnotation {
+	typ := par.parseTypeTerm()
+	if typ == nil || par.NextToken.Type != lexer.BIT_OR_OP {
+		return typ
+	}
+	union := &std.TypeAnnotation{Args: []*std.TypeAnnotation{typ}}
+	for par.NextToken.Type == lexer.BIT_OR_OP {
+		par.advance() // Consume '|'
+		alt := par.parseTypeTerm()
+		if alt == nil {
+			return nil
+		}
+		union.Args = append(union.Args, alt)
+	}
+	return union
+}
+
+// parseTypeTerm parses a type that is not a union, from the token after the
+// ':', the '|' or the '<' before it.
+func (par *Parser) parseTypeTerm() *std.TypeAnnotation {
+	par.advance()
+	tok := par.CurrToken
+	if tok.Type != lexer.IDENTIFIER_ID && !typeNameTokens[tok.Type] {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: expected a type, got %s",
+			tok.Line, tok.Column, tok.Type))
+		return nil
+	}
+	typ := &std.TypeAnnotation{Name: tok.Literal}
+	if tok.Type == lexer.IDENTIFIER_ID && par.NextToken.Type == lexer.DOT_OP {
+		par.advance() // Consume '.'
+		if !par.expectAdvance(lexer.IDENTIFIER_ID) {
+			return nil
+		}
+		typ.Name += "." + par.CurrToken.Literal
+	}
+	if par.NextToken.Type != lexer.LT_OP {
+		return typ
+	}
+
+	arity, generic := std.GenericArity[typ.Name]
+	if !generic {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: type (%s) takes no type arguments",
+			tok.Line, tok.Column, typ.Name))
+		return nil
+	}
+	par.advance() // Consume '<'
+	for {
+		arg := par.parseTypeAnnotation()
+		if arg == nil {
+			return nil
+		}
+		typ.Args = append(typ.Args, arg)
+		if par.NextToken.Type != lexer.COMMA_DELIM {
+			break
+		}
+		par.advance() // Consume comma
+	}
+	if !par.closeTypeArguments() {
+		return nil
+	}
+	if arity > 0 && len(typ.Args) != arity {
+		par.addError(fmt.Sprintf("[%d:%d] PARSER ERROR: type (%s) takes %d type argument(s), got %d",
+			tok.Line, tok.Column, typ.Name, arity, len(typ.Args)))
+		return nil
+	}
+	return typ
+}
+
+// closeTypeArguments consumes the '>' closing a list of type arguments. A
+// token that starts with that '>' (the '>>' closing two nested lists, as in
+// array<array<int>>, or the '>=' before an initial value) is split: its first
+// '>' is consumed and the rest is left as the next token.
+func (par *Parser) closeTypeArguments() bool {
+	rest, split := map[lexer.TokenType]lexer.TokenType{
+		lexer.BIT_RIGHT_OP:     lexer.GT_OP,
+		lexer.GE_OP:            lexer.ASSIGN_OP,
+		lexer.BIT_RIGHT_ASSIGN: lexer.GE_OP,
+	}[par.NextToken.Type]
+	if !split {
+		return par.expectAdvance(lexer.GT_OP)
+	}
+	next := par.NextToken
+	par.CurrToken = lexer.Token{Type: lexer.GT_OP, Literal: ">", Line: next.Line, Column: next.Column}
+	par.NextToken = lexer.Token{Type: rest, Literal: string(rest), Line: next.Line, Column: next.Column + 1}
+	return true
+}
+
+// parseOptionalType parses the annotation of a declaration, a parameter or a
+// return value if the next token is a ':'.
+//
+// Returns:
+//
+//	The type, or nil if there is no annotation
+//	false on a syntax error
+func (par *Parser) parseOptionalType() (*std.TypeAnnotation, bool) {
+	if par.NextToken.Type != lexer.COLON_DELIM {
+		return nil, true
+	}
+	par.advance() // Consume ':'
+	typ := par.parseTypeAnnotation()
+	return typ, typ != nil
+}
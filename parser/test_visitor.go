@@ -148,6 +148,9 @@ func (v *TestingVisitor) VisitDeclarativeStatementNode(node DeclarativeStatement
 	assert.Equal(v.T, node.Identifier.Name, currNode.Identifier.Name)
 	v.Ptr++
 
+	if node.Pattern != nil {
+		node.Pattern.Accept(v)
+	}
 	node.Expr.Accept(v)
 }
 
@@ -308,13 +311,16 @@ func (v *TestingVisitor) VisitFunctionStatementNode(node FunctionStatementNode)
 	assert.Equal(v.T, node.FuncName.Literal(), curr.(*FunctionStatementNode).FuncName.Literal())
 	v.Ptr++
 
-	for _, param := range node.FuncParams {
+	for i, param := range node.FuncParams {
 		param.Accept(v)
+		if def := node.Default(i); def != nil {
+			def.Accept(v)
+		}
 	}
 	node.FuncBody.Accept(v)
 }
 
-// VisitCallExpressionNode visits a function call expression node and asserts the function identifier matches expected
+// VisitCallExpressionNode visits a function call expression node and asserts the callee matches expected
 func (v *TestingVisitor) VisitCallExpressionNode(node CallExpressionNode) {
 	// Check bounds before accessing ExpectedNodes
 	if v.Ptr >= len(v.ExpectedNodes) {
@@ -324,7 +330,7 @@ func (v *TestingVisitor) VisitCallExpressionNode(node CallExpressionNode) {
 	curr := v.ExpectedNodes[v.Ptr]
 	_, ok := curr.(*CallExpressionNode)
 	assert.True(v.T, ok)
-	assert.Equal(v.T, node.FunctionIdentifier.Literal(), curr.(*CallExpressionNode).FunctionIdentifier.Literal())
+	assert.Equal(v.T, node.Callee.Literal(), curr.(*CallExpressionNode).Callee.Literal())
 	v.Ptr++
 
 	for _, arg := range node.Arguments {
@@ -463,6 +469,9 @@ func (v *TestingVisitor) VisitForeachLoopStatementNode(node ForeachLoopStatement
 	assert.Equal(v.T, node.Iterator.Name, curr.(*ForeachLoopStatementNode).Iterator.Name)
 	v.Ptr++
 
+	if node.Pattern != nil {
+		node.Pattern.Accept(v)
+	}
 	node.Iterable.Accept(v)
 	node.Body.Accept(v)
 }
@@ -504,6 +513,56 @@ func (v *TestingVisitor) VisitSetExpressionNode(node SetExpressionNode) {
 	}
 }
 
+// VisitInterpolatedStringExpressionNode visits an interpolated string node and recursively visits all embedded expressions
+func (v *TestingVisitor) VisitInterpolatedStringExpressionNode(node InterpolatedStringExpressionNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	_, ok := curr.(*InterpolatedStringExpressionNode)
+	assert.True(v.T, ok)
+	v.Ptr++
+
+	for _, expr := range node.Exprs {
+		expr.Accept(v)
+	}
+}
+
+// VisitTupleExpressionNode visits a tuple literal node and recursively visits all elements
+func (v *TestingVisitor) VisitTupleExpressionNode(node TupleExpressionNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	_, ok := curr.(*TupleExpressionNode)
+	assert.True(v.T, ok)
+	v.Ptr++
+
+	for _, elem := range node.Elements {
+		elem.Accept(v)
+	}
+}
+
+// VisitPatternNode visits a destructuring pattern node and asserts the names it binds match expected
+func (v *TestingVisitor) VisitPatternNode(node PatternNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	exp, ok := curr.(*PatternNode)
+	assert.True(v.T, ok)
+	if ok {
+		assert.Equal(v.T, exp.Literal(), node.Literal())
+	}
+	v.Ptr++
+}
+
 // VisitStructDeclarationNode visits a struct declaration node and asserts the struct name matches expected, then visits all methods
 func (v *TestingVisitor) VisitStructDeclarationNode(node StructDeclarationNode) {
 	// Check bounds before accessing ExpectedNodes
@@ -526,6 +585,35 @@ func (v *TestingVisitor) VisitStructDeclarationNode(node StructDeclarationNode)
 	}
 }
 
+// VisitInterfaceDeclarationNode visits an interface declaration node and asserts the interface matches expected
+func (v *TestingVisitor) VisitInterfaceDeclarationNode(node InterfaceDeclarationNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	exp, ok := curr.(*InterfaceDeclarationNode)
+	assert.True(v.T, ok)
+	if ok {
+		assert.Equal(v.T, exp.Literal(), node.Literal())
+	}
+	v.Ptr++
+}
+
+// VisitSuperExpressionNode visits a super expression node
+func (v *TestingVisitor) VisitSuperExpressionNode(node SuperExpressionNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	_, ok := curr.(*SuperExpressionNode)
+	assert.True(v.T, ok)
+	v.Ptr++
+}
+
 // VisitNewCallExpressionNode visits a struct instantiation node and asserts the struct name matches expected, then visits all arguments
 func (v *TestingVisitor) VisitNewCallExpressionNode(node NewCallExpressionNode) {
 	// Check bounds before accessing ExpectedNodes
@@ -602,6 +690,30 @@ func (v *TestingVisitor) VisitSwitchStatementNode(node SwitchStatementNode) {
 	}
 }
 
+// VisitMatchExpressionNode visits a match expression node and recursively visits the subject, guards and results
+func (v *TestingVisitor) VisitMatchExpressionNode(node MatchExpressionNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	_, ok := curr.(*MatchExpressionNode)
+	assert.True(v.T, ok)
+	v.Ptr++
+
+	// Visit the subject
+	node.Subject.Accept(v)
+
+	// Visit the guard and the result of each arm
+	for _, arm := range node.Arms {
+		if arm.Guard != nil {
+			arm.Guard.Accept(v)
+		}
+		arm.Body.Accept(v)
+	}
+}
+
 // String returns the string representation of the visitor (empty string)
 func (v *TestingVisitor) String() string {
 	return ""
@@ -625,3 +737,76 @@ func (v *TestingVisitor) VisitEnumDeclarationNode(node EnumDeclarationNode) {
 	}
 
 }
+
+// VisitTryStatementNode visits a try statement node and recursively visits its try, catch, and finally blocks
+func (v *TestingVisitor) VisitTryStatementNode(node TryStatementNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	expected, ok := curr.(*TryStatementNode)
+	assert.True(v.T, ok)
+	if ok {
+		assert.Equal(v.T, expected.CatchBlock != nil, node.CatchBlock != nil)
+		assert.Equal(v.T, expected.FinallyBlock != nil, node.FinallyBlock != nil)
+		if expected.CatchParam != nil && node.CatchParam != nil {
+			assert.Equal(v.T, expected.CatchParam.Name, node.CatchParam.Name)
+		}
+	}
+	v.Ptr++
+
+	node.TryBlock.Accept(v)
+	if node.CatchBlock != nil {
+		node.CatchBlock.Accept(v)
+	}
+	if node.FinallyBlock != nil {
+		node.FinallyBlock.Accept(v)
+	}
+}
+
+// VisitThrowStatementNode visits a throw statement node and then its thrown expression
+func (v *TestingVisitor) VisitThrowStatementNode(node ThrowStatementNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	_, ok := curr.(*ThrowStatementNode)
+	assert.True(v.T, ok)
+	v.Ptr++
+
+	node.Expr.Accept(v)
+}
+
+// VisitYieldStatementNode visits a yield statement node and then its expression
+func (v *TestingVisitor) VisitYieldStatementNode(node YieldStatementNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	_, ok := curr.(*YieldStatementNode)
+	assert.True(v.T, ok)
+	v.Ptr++
+
+	node.Expr.Accept(v)
+}
+
+// VisitSpawnStatementNode visits a spawn statement node and then its call
+func (v *TestingVisitor) VisitSpawnStatementNode(node SpawnStatementNode) {
+	// Check bounds before accessing ExpectedNodes
+	if v.Ptr >= len(v.ExpectedNodes) {
+		return
+	}
+	// assert on type
+	curr := v.ExpectedNodes[v.Ptr]
+	_, ok := curr.(*SpawnStatementNode)
+	assert.True(v.T, ok)
+	v.Ptr++
+
+	node.Call.Accept(v)
+}
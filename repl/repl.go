@@ -26,6 +26,7 @@ import (
 
 	"github.com/akashmaji946/go-mix/eval"
 	"github.com/akashmaji946/go-mix/parser"
+	"github.com/akashmaji946/go-mix/std"
 	"github.com/chzyer/readline"
 	"github.com/fatih/color"
 )
@@ -304,8 +305,11 @@ func (r *Repl) executeWithRecovery(writer io.Writer, line string, evaluator *eva
 	// Display the result if it's not nil
 	if result != nil {
 		if result.GetType() == "error" {
-			// Evaluation produced an error - display in red
+			// Evaluation produced an error - display in red, with its traceback
 			fmt.Fprintf(writer, "%s\n", redColor.Sprintf("%s", result.ToString()))
+			if traceback := std.Traceback(result); traceback != "" {
+				fmt.Fprint(writer, redColor.Sprint(traceback))
+			}
 		} else {
 			// Successful evaluation - display result in yellow
 			// Note: nil results are still printed (unlike file mode)
@@ -325,7 +329,8 @@ func (r *Repl) executeWithRecovery(writer io.Writer, line string, evaluator *eva
 // printScope displays the current scope of the evaluator.
 // This function prints all variables in the scope chain and all registered types.
 // It allows users to inspect what variables are currently defined and what types
-// are available in the current execution context.
+// are available in the current execution context. Structs that extend another
+// struct or implement interfaces are shown with their hierarchy (see std.TypeName).
 //
 // Parameters:
 //
@@ -336,12 +341,12 @@ func printScope(writer io.Writer, evaluator *eval.Evaluator) {
 	scope := evaluator.Scp
 	for cur := scope; cur != nil; cur = cur.Parent {
 		for k, v := range cur.Variables {
-			fmt.Fprintf(writer, "%s => %v\n", k, v.GetType())
+			fmt.Fprintf(writer, "%s => %v\n", k, std.TypeName(v))
 		}
 	}
 	fmt.Fprintf(writer, "==== Types     ====\n")
 	for _, t := range evaluator.Types {
-		fmt.Fprintf(writer, "%s => %v\n", t.Name, t.GetType())
+		fmt.Fprintf(writer, "%s => %v\n", t.Name, std.TypeName(t))
 	}
 }
 
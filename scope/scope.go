@@ -31,6 +31,10 @@ type Scope struct {
 	// LetTypes stores the declared types of 'let' variables for type checking
 	LetTypes map[string]std.GoMixType
 
+	// Types stores the annotated types of variables (var x: int = 0) and
+	// parameters, which every value assigned to them must conform to
+	Types map[string]*std.TypeAnnotation
+
 	// Parent points to the enclosing scope, forming a scope chain
 	// nil indicates this is the global (root) scope
 	Parent *Scope
@@ -64,6 +68,7 @@ func NewScope(parent *Scope) *Scope {
 		Consts:    make(map[string]bool),
 		LetVars:   make(map[string]bool),
 		LetTypes:  make(map[string]std.GoMixType),
+		Types:     make(map[string]*std.TypeAnnotation),
 		Parent:    parent,
 	}
 }
@@ -293,6 +298,38 @@ func (s *Scope) GetLetType(varName string) (std.GoMixType, bool) {
 	return "", false
 }
 
+// DeclaredType retrieves the annotated type of a variable from the scope that
+// defines it.
+//
+// Unlike GetLetType, the search stops at the first scope binding the name, so
+// that an unannotated variable shadowing an annotated one is not typed.
+// The method is safe to call even if Types map is nil (lazy initialization).
+//
+// Parameters:
+//   - varName: The name of the variable whose type to retrieve
+//
+// Returns:
+//   - *std.TypeAnnotation: The annotated type, or nil if the variable is
+//     not annotated (or not found)
+//
+// Example:
+//
+//	var x: int = 10;                 // Records the annotation int
+//	typ := scope.DeclaredType("x")   // Returns int
+//	x = "ten";                       // Type check: "ten" does not conform to int
+func (s *Scope) DeclaredType(varName string) *std.TypeAnnotation {
+	if s.Types == nil {
+		s.Types = make(map[string]*std.TypeAnnotation)
+	}
+	if _, ok := s.Variables[varName]; ok {
+		return s.Types[varName]
+	}
+	if s.Parent != nil {
+		return s.Parent.DeclaredType(varName)
+	}
+	return nil
+}
+
 // Copy creates a shallow copy of this scope for closure capture.
 //
 // This method is essential for implementing closures correctly. When a function
@@ -333,6 +370,7 @@ func (s *Scope) Copy() *Scope {
 		Consts:    make(map[string]bool),
 		LetVars:   make(map[string]bool),
 		LetTypes:  make(map[string]std.GoMixType),
+		Types:     make(map[string]*std.TypeAnnotation),
 		Parent:    s.Parent,
 	}
 	// Copy variables
@@ -351,5 +389,9 @@ func (s *Scope) Copy() *Scope {
 	for k, v := range s.LetTypes {
 		newScope.LetTypes[k] = v
 	}
+	// Copy annotated types
+	for k, v := range s.Types {
+		newScope.Types[k] = v
+	}
 	return newScope
 }
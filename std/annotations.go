@@ -0,0 +1,111 @@
+/*
+File    : go-mix/std/annotations.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - annotations.go
+// This file defines the type annotations of variables, parameters, return
+// values and struct fields (var x: int = 0; func area(w: float): float).
+package std
+
+import "strings"
+
+// TypeAnnotation is a declared type.
+//
+// A named type is a builtin type (int, string, array, ...), any, or the name
+// of a struct, interface or enum (possibly qualified by a module, as in
+// util.Point). The generic builtins take their element types as arguments:
+// array<int>, list<T>, set<T>, map<K, V> and tuple<A, B, ...>.
+// A union (int | nil) has no name; its alternatives are the arguments.
+//
+// Examples:
+//
+//	int                    {Name: "int"}
+//	array<string>          {Name: "array", Args: [string]}
+//	map<string, int>       {Name: "map", Args: [string, int]}
+//	Point | nil            {Name: "", Args: [Point, nil]}
+type TypeAnnotation struct {
+	Name string            // The type name ("" for a union)
+	Args []*TypeAnnotation // The type arguments, or the alternatives of a union
+}
+
+// AnyType is the name of the type every value conforms to.
+const AnyType = "any"
+
+// GenericArity gives the number of type arguments the generic builtin types
+// take (-1 for any positive number). The other types take none.
+var GenericArity = map[string]int{
+	string(ArrayType): 1,
+	string(ListType):  1,
+	string(SetType):   1,
+	string(MapType):   2,
+	string(TupleType): -1,
+}
+
+// BuiltinTypeNames is the set of the builtin type names an annotation may use.
+var BuiltinTypeNames = map[string]bool{
+	AnyType:               true,
+	string(IntegerType):   true,
+	string(FloatType):     true,
+	string(BigIntType):    true,
+	string(DecimalType):   true,
+	string(CharType):      true,
+	string(StringType):    true,
+	string(BytesType):     true,
+	string(BooleanType):   true,
+	string(NilType):       true,
+	string(FunctionType):  true,
+	string(ArrayType):     true,
+	string(RangeType):     true,
+	string(MapType):       true,
+	string(SetType):       true,
+	string(ListType):      true,
+	string(TupleType):     true,
+	string(StructType):    true,
+	string(ObjectType):    true,
+	string(FileType):      true,
+	string(EnumType):      true,
+	string(InterfaceType): true,
+	string(ChanType):      true,
+	string(MutexType):     true,
+	string(WaitGroupType): true,
+	string(GeneratorType): true,
+}
+
+// IsUnion reports whether the annotation is a union of alternatives.
+func (t *TypeAnnotation) IsUnion() bool {
+	return t.Name == ""
+}
+
+// IsBuiltin reports whether the annotation names a builtin type.
+func (t *TypeAnnotation) IsBuiltin() bool {
+	return BuiltinTypeNames[t.Name]
+}
+
+// Arg returns the i-th type argument, or nil if there is none (as for a
+// plain array, whose elements may be of any type).
+func (t *TypeAnnotation) Arg(i int) *TypeAnnotation {
+	if i < len(t.Args) {
+		return t.Args[i]
+	}
+	return nil
+}
+
+// String returns the annotation as written in the source (e.g., "map<string, int>").
+func (t *TypeAnnotation) String() string {
+	if t == nil {
+		return AnyType
+	}
+	args := make([]string, len(t.Args))
+	for i, arg := range t.Args {
+		args[i] = arg.String()
+	}
+	if t.IsUnion() {
+		return strings.Join(args, " | ")
+	}
+	if len(args) == 0 {
+		return t.Name
+	}
+	return t.Name + "<" + strings.Join(args, ", ") + ">"
+}
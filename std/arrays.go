@@ -18,34 +18,34 @@ import (
 // Each Builtin has a name (the method name) and a callback function that implements the behavior.
 // These are appended to the global Builtins slice during package initialization.
 var arrayMethods = []*Builtin{
-	{Name: "make_array", Callback: arrayFunc},
-
-	{Name: "push_array", Callback: pushArray},       // Adds an element to the end of the array
-	{Name: "pop_array", Callback: popArray},         // Removes and returns the last element of the array
-	{Name: "shift_array", Callback: shiftArray},     // Removes and returns the first element of the array
-	{Name: "unshift_array", Callback: unshiftArray}, // Adds an element to the beginning of the array
-	{Name: "sort_array", Callback: sortArray},       // Sorts the elements of the array in-place
-	{Name: "sorted_array", Callback: sortedArray},   // Returns a new sorted array
-	{Name: "clone_array", Callback: cloneArray},     // Returns a shallow copy of the array
-	{Name: "csort_array", Callback: csortArray},     // Custom sort for an array using a comparator
-	{Name: "csorted_array", Callback: csortedArray}, // Returns a new sorted array using a comparator
-
-	{Name: "find_array", Callback: findArray},   // Finds the first element matching a predicate
-	{Name: "some_array", Callback: someArray},   // Checks if at least one element matches
-	{Name: "every_array", Callback: everyArray}, // Checks if all elements match
-
-	{Name: "reverse_array", Callback: reverseArray},   // Returns a new reversed array
-	{Name: "contains_array", Callback: containsArray}, // Checks if a value exists in the array
-	{Name: "replace_array", Callback: replaceArray},   // Returns the index of the first occurrence of a value, or -1 if not found
-	{Name: "index_array", Callback: indexArray},       // Returns the index of the first occurrence of a value, or -1 if not found
-
-	{Name: "to_array", Callback: toArray},         // Converts list/tuple to array
-	{Name: "map_array", Callback: mapArray},       // Applies a function to each element
-	{Name: "filter_array", Callback: filterArray}, // Filters elements based on a predicate
-	{Name: "reduce_array", Callback: reduceArray}, // Accumulates a value across an array
-
-	{Name: "size_array", Callback: sizeArray},   // Returns the number of elements in an array
-	{Name: "length_array", Callback: sizeArray}, // Checks if a value exists in the array
+	{Name: "make_array", Callback: arrayFunc, Signature: "make_array(...) -> array"},
+
+	{Name: "push_array", Callback: pushArray, Signature: "push_array(arr, elem) -> array"},                // Adds an element to the end of the array
+	{Name: "pop_array", Callback: popArray, Signature: "pop_array(arr) -> any"},                           // Removes and returns the last element of the array
+	{Name: "shift_array", Callback: shiftArray, Signature: "shift_array(arr) -> any"},                     // Removes and returns the first element of the array
+	{Name: "unshift_array", Callback: unshiftArray, Signature: "unshift_array(arr, elem) -> array"},       // Adds an element to the beginning of the array
+	{Name: "sort_array", Callback: sortArray, Signature: "sort_array(arr, [reverse]) -> array"},           // Sorts the elements of the array in-place
+	{Name: "sorted_array", Callback: sortedArray, Signature: "sorted_array(arr, [reverse]) -> array"},     // Returns a new sorted array
+	{Name: "clone_array", Callback: cloneArray, Signature: "clone_array(arr) -> array"},                   // Returns a shallow copy of the array
+	{Name: "csort_array", Callback: csortArray, Signature: "csort_array(arr, comparator) -> array"},       // Custom sort for an array using a comparator
+	{Name: "csorted_array", Callback: csortedArray, Signature: "csorted_array(arr, comparator) -> array"}, // Returns a new sorted array using a comparator
+
+	{Name: "find_array", Callback: findArray, Signature: "find_array(arr, func) -> any"},     // Finds the first element matching a predicate
+	{Name: "some_array", Callback: someArray, Signature: "some_array(arr, func) -> bool"},    // Checks if at least one element matches
+	{Name: "every_array", Callback: everyArray, Signature: "every_array(arr, func) -> bool"}, // Checks if all elements match
+
+	{Name: "reverse_array", Callback: reverseArray, Signature: "reverse_array(arr) -> array"},                 // Returns a new reversed array
+	{Name: "contains_array", Callback: containsArray, Signature: "contains_array(arr, value) -> bool"},        // Checks if a value exists in the array
+	{Name: "replace_array", Callback: replaceArray, Signature: "replace_array(arr, old_val, new_val) -> int"}, // Returns the index of the first occurrence of a value, or -1 if not found
+	{Name: "index_array", Callback: indexArray, Signature: "index_array(arr, value) -> int"},                  // Returns the index of the first occurrence of a value, or -1 if not found
+
+	{Name: "to_array", Callback: toArray, Signature: "to_array(iterable) -> array"},                     // Converts list/tuple to array
+	{Name: "map_array", Callback: mapArray, Signature: "map_array(arr, func) -> array"},                 // Applies a function to each element
+	{Name: "filter_array", Callback: filterArray, Signature: "filter_array(arr, func) -> array"},        // Filters elements based on a predicate
+	{Name: "reduce_array", Callback: reduceArray, Signature: "reduce_array(arr, func, initial) -> any"}, // Accumulates a value across an array
+
+	{Name: "size_array", Callback: sizeArray, Signature: "size_array(arr) -> int"},     // Returns the number of elements in an array
+	{Name: "length_array", Callback: sizeArray, Signature: "length_array(arr) -> int"}, // Checks if a value exists in the array
 
 }
 
@@ -95,6 +95,8 @@ func init() {
 //	array(tuple(1, 2, 3))      -> [1, 2, 3]
 //	array(set{1, 2, 3})        -> [1, 2, 3]
 //	array(map{"a": 1, "b": 2}) -> [1, 2]
+//	array(lazy.take(gen, 2))   -> [first two values of gen]
+//	array(bytes("AB"))         -> [65, 66]
 //	array(42)                  -> [42]
 func arrayFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	// Handle 0 arguments: return empty array
@@ -139,20 +141,15 @@ func arrayFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	case MapType:
 		// Convert map values to array (in key insertion order)
 		m := arg.(*Map)
-		elements := make([]GoMixObject, len(m.Keys))
-		for i, key := range m.Keys {
-			elements[i] = m.Pairs[key]
+		elements := make([]GoMixObject, m.Len())
+		for i, pair := range m.Entries() {
+			elements[i] = pair.Value
 		}
 		return &Array{Elements: elements}
 
 	case SetType:
 		// Convert set values to array (in insertion order)
-		s := arg.(*Set)
-		elements := make([]GoMixObject, len(s.Values))
-		for i, val := range s.Values {
-			elements[i] = &String{Value: val}
-		}
-		return &Array{Elements: elements}
+		return &Array{Elements: arg.(*Set).Values()}
 
 	case RangeType:
 		// Convert range to array of integers
@@ -182,6 +179,15 @@ func arrayFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		}
 		return &Array{Elements: elements}
 
+	case GeneratorType, BytesType:
+		// Run the generator to its end, collecting the values (the bytes of
+		// a bytes value, as ints)
+		elements, err := Collect(rt, arg)
+		if err != nil {
+			return err
+		}
+		return &Array{Elements: elements}
+
 	default:
 		// Non-iterable single argument: wrap it in an array
 		return &Array{Elements: []GoMixObject{arg}}
@@ -248,7 +254,8 @@ func toArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 }
 
 // sortArray sorts the elements of an array in-place.
-// Currently performs lexicographical sorting based on ToString().
+// Integers are sorted numerically, struct instances with their __lt__ method
+// and other values lexicographically based on ToString().
 //
 // Syntax: sort_array(array)
 // Syntax: sort_array(array, [reverse])
@@ -270,24 +277,16 @@ func sortArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 
 	arr := args[0].(*Array)
 
+	var err GoMixObject
 	sort.Slice(arr.Elements, func(i, j int) bool {
-		// Basic implementation: compare string representations
-		// You could expand this to check types (e.g., numeric sort for ints)
-		if arr.Elements[i].GetType() == IntegerType && arr.Elements[j].GetType() == IntegerType {
-			v1 := arr.Elements[i].(*Integer).Value
-			v2 := arr.Elements[j].(*Integer).Value
-			if reverse {
-				return v1 > v2
-			}
-			return v1 < v2
-		}
-		s1 := arr.Elements[i].ToString()
-		s2 := arr.Elements[j].ToString()
 		if reverse {
-			return s1 > s2
+			return sortLess(rt, arr.Elements[j], arr.Elements[i], &err)
 		}
-		return s1 < s2
+		return sortLess(rt, arr.Elements[i], arr.Elements[j], &err)
 	})
+	if err != nil {
+		return err
+	}
 
 	return arr
 }
@@ -317,26 +316,39 @@ func sortedArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	newElements := make([]GoMixObject, len(arr.Elements))
 	copy(newElements, arr.Elements)
 
+	var err GoMixObject
 	sort.Slice(newElements, func(i, j int) bool {
-		if newElements[i].GetType() == IntegerType && newElements[j].GetType() == IntegerType {
-			v1 := newElements[i].(*Integer).Value
-			v2 := newElements[j].(*Integer).Value
-			if reverse {
-				return v1 > v2
-			}
-			return v1 < v2
-		}
-		s1 := newElements[i].ToString()
-		s2 := newElements[j].ToString()
 		if reverse {
-			return s1 > s2
+			return sortLess(rt, newElements[j], newElements[i], &err)
 		}
-		return s1 < s2
+		return sortLess(rt, newElements[i], newElements[j], &err)
 	})
+	if err != nil {
+		return err
+	}
 
 	return &Array{Elements: newElements}
 }
 
+// sortLess is the order of sort and sorted: __lt__ for struct instances,
+// numeric for integers (and bigints and decimals) and lexicographic on
+// ToString() otherwise. The first error of a __lt__ method is kept in err.
+func sortLess(rt Runtime, a, b GoMixObject, err *GoMixObject) bool {
+	if less, ok, lessErr := ProtocolLess(rt, a, b); ok {
+		if lessErr != nil && *err == nil {
+			*err = lessErr
+		}
+		return less
+	}
+	if a.GetType() == IntegerType && b.GetType() == IntegerType {
+		return a.(*Integer).Value < b.(*Integer).Value
+	}
+	if c, ok := CompareNumbers(a, b); ok {
+		return c < 0
+	}
+	return a.ToString() < b.ToString()
+}
+
 // cloneArray returns a shallow copy of the array.
 //
 // Syntax: clone_array(array)
@@ -440,24 +452,24 @@ func csortedArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	return &Array{Elements: newElements}
 }
 
-// mapArray applies a function to each element of an array and returns a new array.
+// mapArray applies a function to each element of an array (or any iterable) and returns a new array.
 //
 // Syntax: map_array(array, function)
 func mapArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: map_array expects 2 arguments (array, function)")
 	}
-	arr, ok := args[0].(*Array)
-	if !ok {
-		return createError("ERROR: first argument to `map_array` must be an array, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "map_array", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
 		return createError("ERROR: second argument to `map_array` must be a function, got '%s'", fn.GetType())
 	}
 
-	newElements := make([]GoMixObject, len(arr.Elements))
-	for i, elem := range arr.Elements {
+	newElements := make([]GoMixObject, len(elems))
+	for i, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if res.GetType() == ErrorType {
 			return res
@@ -468,16 +480,16 @@ func mapArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &Array{Elements: newElements}
 }
 
-// filterArray returns a new array containing elements that satisfy a predicate.
+// filterArray returns a new array containing the elements of an array (or any iterable) that satisfy a predicate.
 //
 // Syntax: filter_array(array, function)
 func filterArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: filter_array expects 2 arguments (array, function)")
 	}
-	arr, ok := args[0].(*Array)
-	if !ok {
-		return createError("ERROR: first argument to `filter_array` must be an array, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "filter_array", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
@@ -485,7 +497,7 @@ func filterArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	}
 
 	newElements := []GoMixObject{}
-	for _, elem := range arr.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if res.GetType() == ErrorType {
 			return res
@@ -507,7 +519,7 @@ func filterArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	return &Array{Elements: newElements}
 }
 
-// reduceArray accumulates a value by applying a function to each element of an array.
+// reduceArray accumulates a value by applying a function to each element of an array (or any iterable).
 //
 // Syntax: reduce_array(array, function, initial)
 // Example:
@@ -518,9 +530,9 @@ func reduceArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	if len(args) != 3 {
 		return createError("ERROR: reduce_array expects 3 arguments (array, function, initial)")
 	}
-	arr, ok := args[0].(*Array)
-	if !ok {
-		return createError("ERROR: first argument to `reduce_array` must be an array, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "reduce_array", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
@@ -528,7 +540,7 @@ func reduceArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	}
 
 	accumulator := args[2]
-	for _, elem := range arr.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, accumulator, elem)
 		if res.GetType() == ErrorType {
 			return res
@@ -545,13 +557,13 @@ func findArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: find_array expects 2 arguments (array, function)")
 	}
-	arr, ok := args[0].(*Array)
-	if !ok {
-		return createError("ERROR: first argument to `find_array` must be an array")
+	elems, err := iterableArg(rt, "find_array", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 
-	for _, elem := range arr.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if IsTruthy(res) {
 			return elem
@@ -560,18 +572,18 @@ func findArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &Nil{}
 }
 
-// someArray tests whether at least one element in the array passes the test.
+// someArray tests whether at least one element in the array (or any iterable) passes the test.
 func someArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: some_array expects 2 arguments (array, function)")
 	}
-	arr, ok := args[0].(*Array)
-	if !ok {
-		return createError("ERROR: first argument to `some_array` must be an array")
+	elems, err := iterableArg(rt, "some_array", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 
-	for _, elem := range arr.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if IsTruthy(res) {
 			return &Boolean{Value: true}
@@ -580,18 +592,18 @@ func someArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &Boolean{Value: false}
 }
 
-// everyArray tests whether all elements in the array pass the test.
+// everyArray tests whether all elements in the array (or any iterable) pass the test.
 func everyArray(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: every_array expects 2 arguments (array, function)")
 	}
-	arr, ok := args[0].(*Array)
-	if !ok {
-		return createError("ERROR: first argument to `every_array` must be an array")
+	elems, err := iterableArg(rt, "every_array", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 
-	for _, elem := range arr.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if !IsTruthy(res) {
 			return &Boolean{Value: false}
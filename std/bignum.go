@@ -0,0 +1,510 @@
+/*
+File    : go-mix/std/bignum.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - bignum.go
+// This file implements the arbitrary-precision numbers of Go-Mix, backed by
+// math/big:
+//   - bigint: an integer of any size. An int operation that overflows (such
+//     as 9223372036854775807 + 1) gives a bigint, and integer literals with
+//     an n suffix (123n) or too large for an int are bigints.
+//   - decimal: a decimal number with a fixed number of digits after the
+//     decimal point (its scale), such as an amount of money. decimal("19.99")
+//     has scale 2.
+//
+// Arithmetic on a bigint and an int gives a bigint, and on a bigint and a
+// float gives a float. Arithmetic on a decimal and an int or bigint gives a
+// decimal; mixing decimals and floats is an error, as the float would bring
+// its rounding error along.
+//
+// The result of decimal arithmetic has the larger scale of the operands, and
+// is rounded half away from zero to that scale:
+//
+//	decimal("10.00") / 3        // 3.33
+//	decimal("1.005", 3) * 2     // 2.010
+//	decimal(1, 4) / 3           // 0.3333
+package std
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimalScale is the largest scale (digits after the decimal point) of a decimal.
+const MaxDecimalScale = 1000
+
+// maxShift is the largest shift count of a bigint shift, which bounds the
+// size of its result.
+const maxShift = 1 << 24
+
+// BigInt represents an integer of any size in Go-Mix.
+type BigInt struct {
+	Value *big.Int // The underlying integer value
+}
+
+// GetType returns the type of the BigInt object
+func (b *BigInt) GetType() GoMixType {
+	return BigIntType
+}
+
+// ToString returns the string representation of the integer (e.g., "123")
+func (b *BigInt) ToString() string {
+	return b.Value.String()
+}
+
+// ToObject returns a detailed representation including type info (e.g., "<bigint(123)>")
+func (b *BigInt) ToObject() string {
+	return fmt.Sprintf("<bigint(%s)>", b.Value.String())
+}
+
+// Format formats the integer for printf: the integer verbs (%d, %x, %o, %b,
+// %v, %s) print it exactly, the float verbs (%f, %e, %g) as a float.
+func (b *BigInt) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'f', 'F', 'e', 'E', 'g', 'G':
+		new(big.Float).SetInt(b.Value).Format(s, verb)
+	default:
+		b.Value.Format(s, verb)
+	}
+}
+
+// Decimal represents a decimal number with a fixed scale in Go-Mix.
+// Its value is Unscaled / 10^Scale.
+type Decimal struct {
+	Unscaled *big.Int // The value, without its decimal point
+	Scale    int      // The number of digits after the decimal point
+}
+
+// GetType returns the type of the Decimal object
+func (d *Decimal) GetType() GoMixType {
+	return DecimalType
+}
+
+// ToString returns the number with all the digits of its scale (e.g., "19.90")
+func (d *Decimal) ToString() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	if d.Scale > 0 {
+		if len(digits) <= d.Scale {
+			digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+	}
+	if d.Unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// ToObject returns a detailed representation including type info (e.g., "<decimal(19.90)>")
+func (d *Decimal) ToObject() string {
+	return fmt.Sprintf("<decimal(%s)>", d.ToString())
+}
+
+// Format formats the number for printf: %f and %F with a precision round it
+// exactly (half away from zero), %v, %s and %f without a precision print it
+// with its own scale, and %e and %g print it as a float.
+func (d *Decimal) Format(s fmt.State, verb rune) {
+	var str string
+	switch verb {
+	case 'f', 'F', 'v', 's':
+		if prec, ok := s.Precision(); ok && (verb == 'f' || verb == 'F') {
+			str = d.Rescale(prec).ToString()
+		} else {
+			str = d.ToString()
+		}
+		if s.Flag('+') && d.Unscaled.Sign() >= 0 {
+			str = "+" + str
+		}
+	case 'e', 'E', 'g', 'G':
+		f, _ := d.Rat().Float64()
+		fmt.Fprintf(s, fmt.FormatString(s, verb), f)
+		return
+	default:
+		fmt.Fprintf(s, "%%!%c(decimal=%s)", verb, d.ToString())
+		return
+	}
+	if width, ok := s.Width(); ok && len(str) < width {
+		pad := strings.Repeat(" ", width-len(str))
+		if s.Flag('-') {
+			str += pad
+		} else if s.Flag('0') {
+			sign := ""
+			if str[0] == '-' || str[0] == '+' {
+				sign, str = str[:1], str[1:]
+			}
+			str = sign + strings.Repeat("0", len(pad)) + str
+		} else {
+			str = pad + str
+		}
+	}
+	fmt.Fprint(s, str)
+}
+
+// Hash returns the HashKey of a BigInt.
+func (b *BigInt) Hash() HashKey {
+	return HashKey{Type: BigIntType, Value: b.Value.String()}
+}
+
+// Hash returns the HashKey of a Decimal. Decimals of different scales with
+// the same value (1.5 and 1.50) are the same key.
+func (d *Decimal) Hash() HashKey {
+	return HashKey{Type: DecimalType, Value: d.Rat().RatString()}
+}
+
+// NewBigInt returns the bigint with the value of an int.
+func NewBigInt(v int64) *BigInt {
+	return &BigInt{Value: big.NewInt(v)}
+}
+
+// NewInteger returns an integer: an int if v fits in one, a bigint otherwise.
+func NewInteger(v *big.Int) GoMixObject {
+	if v.IsInt64() {
+		return &Integer{Value: v.Int64()}
+	}
+	return &BigInt{Value: v}
+}
+
+// pow10 returns 10^n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundQuo returns n / d rounded half away from zero.
+func roundQuo(n, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	// Round away from zero when 2|r| >= |d|
+	if new(big.Int).Lsh(r.Abs(r), 1).Cmp(new(big.Int).Abs(d)) >= 0 {
+		if (n.Sign() < 0) != (d.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// Rescale returns the decimal with scale digits after the decimal point,
+// rounded half away from zero if it has fewer digits than d.
+func (d *Decimal) Rescale(scale int) *Decimal {
+	if scale == d.Scale {
+		return d
+	}
+	if scale > d.Scale {
+		return &Decimal{Unscaled: new(big.Int).Mul(d.Unscaled, pow10(scale-d.Scale)), Scale: scale}
+	}
+	return &Decimal{Unscaled: roundQuo(d.Unscaled, pow10(d.Scale-scale)), Scale: scale}
+}
+
+// Rat returns the exact value of the decimal as a fraction.
+func (d *Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Unscaled, pow10(d.Scale))
+}
+
+// Trunc returns the integer part of the decimal, truncated toward zero.
+func (d *Decimal) Trunc() *big.Int {
+	return new(big.Int).Quo(d.Unscaled, pow10(d.Scale))
+}
+
+// IsInteger reports whether the decimal has no fractional part.
+func (d *Decimal) IsInteger() bool {
+	return new(big.Int).Rem(d.Unscaled, pow10(d.Scale)).Sign() == 0
+}
+
+// ParseDecimal parses a decimal number such as "19.99", "-0.5" or "1_000.00";
+// its scale is the number of digits after the decimal point.
+func ParseDecimal(s string) (*Decimal, bool) {
+	digits := strings.ReplaceAll(strings.TrimSpace(s), "_", "")
+	scale := 0
+	if dot := strings.IndexByte(digits, '.'); dot >= 0 {
+		scale = len(digits) - dot - 1
+		digits = digits[:dot] + digits[dot+1:]
+	}
+	if scale > MaxDecimalScale || strings.ContainsAny(digits, ".xXoObBeE") {
+		return nil, false
+	}
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, false
+	}
+	return &Decimal{Unscaled: unscaled, Scale: scale}, true
+}
+
+// FloatToDecimal converts a float to the decimal with the fewest digits that
+// reads back as the same float (0.1 becomes 0.1, not 0.1000000000000000055).
+func FloatToDecimal(f float64) (*Decimal, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// IsBigNumber reports whether obj is a bigint or a decimal.
+func IsBigNumber(obj GoMixObject) bool {
+	switch obj.(type) {
+	case *BigInt, *Decimal:
+		return true
+	}
+	return false
+}
+
+// ToFloat64 converts an int, float, bigint or decimal to a float64
+// (bigints and decimals to the nearest float).
+func ToFloat64(obj GoMixObject) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value), true
+	case *Float:
+		return obj.Value, true
+	case *BigInt:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f, true
+	case *Decimal:
+		f, _ := obj.Rat().Float64()
+		return f, true
+	}
+	return 0, false
+}
+
+// toBigInt returns the value of an int or bigint.
+func toBigInt(obj GoMixObject) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInt:
+		return obj.Value, true
+	}
+	return nil, false
+}
+
+// toDecimal returns the value of an int, bigint or decimal as a decimal.
+func toDecimal(obj GoMixObject) (*Decimal, bool) {
+	if d, ok := obj.(*Decimal); ok {
+		return d, true
+	}
+	if v, ok := toBigInt(obj); ok {
+		return &Decimal{Unscaled: v, Scale: 0}, true
+	}
+	return nil, false
+}
+
+// toRat returns the exact value of an int, bigint, decimal or finite float.
+func toRat(obj GoMixObject) (*big.Rat, bool) {
+	switch obj := obj.(type) {
+	case *Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(obj.Value), true
+	case *Decimal:
+		return obj.Rat(), true
+	}
+	if v, ok := toBigInt(obj); ok {
+		return new(big.Rat).SetInt(v), true
+	}
+	return nil, false
+}
+
+// IntOverflows reports whether the result of the int operation a op b does
+// not fit in an int, for the operators that can overflow (+, -, *, / and <<).
+func IntOverflows(op string, a, b int64) bool {
+	switch op {
+	case "+":
+		sum := a + b
+		return (b > 0 && sum < a) || (b < 0 && sum > a)
+	case "-":
+		diff := a - b
+		return (b > 0 && diff > a) || (b < 0 && diff < a)
+	case "*":
+		if a == 0 || b == 0 {
+			return false
+		}
+		return (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) || (a*b)/b != a
+	case "/":
+		return a == math.MinInt64 && b == -1
+	case "<<":
+		if b >= 64 {
+			return a != 0
+		}
+		return b > 0 && (a<<b)>>b != a
+	}
+	return false
+}
+
+// BigArithmetic applies an arithmetic (+, -, *, /, %) or bitwise (&, |, ^,
+// <<, >>) operator to two numbers, at least one of which is a bigint or a
+// decimal (bitwise operators apply to ints and bigints only).
+//
+// Parameters:
+//   - op: The operator
+//   - left: The left operand
+//   - right: The right operand
+//
+// Returns:
+//   - GoMixObject: The result, or an Error (division by zero, a decimal
+//     mixed with a float, a bad shift count)
+//   - bool: false if neither operand is a bigint or a decimal, or the
+//     operator does not apply to the operands
+func BigArithmetic(op string, left, right GoMixObject) (GoMixObject, bool) {
+	if !IsBigNumber(left) && !IsBigNumber(right) {
+		return nil, false
+	}
+	_, leftFloat := left.(*Float)
+	_, rightFloat := right.(*Float)
+	_, leftDecimal := left.(*Decimal)
+	_, rightDecimal := right.(*Decimal)
+
+	if leftDecimal || rightDecimal {
+		if leftFloat || rightFloat {
+			return createError("ERROR: operator (%s) cannot mix decimal and float: convert the float with decimal(x)", op), true
+		}
+		l, lok := toDecimal(left)
+		r, rok := toDecimal(right)
+		if !lok || !rok {
+			return nil, false
+		}
+		return decimalOp(op, l, r)
+	}
+
+	if leftFloat || rightFloat {
+		l, lok := ToFloat64(left)
+		r, rok := ToFloat64(right)
+		if !lok || !rok {
+			return nil, false
+		}
+		switch op {
+		case "+":
+			return &Float{Value: l + r}, true
+		case "-":
+			return &Float{Value: l - r}, true
+		case "*":
+			return &Float{Value: l * r}, true
+		case "/":
+			return &Float{Value: l / r}, true
+		}
+		return nil, false
+	}
+
+	l, lok := toBigInt(left)
+	r, rok := toBigInt(right)
+	if !lok || !rok {
+		return nil, false
+	}
+	return bigIntOp(op, l, r)
+}
+
+// bigIntOp applies an arithmetic or bitwise operator to two bigints.
+func bigIntOp(op string, a, b *big.Int) (GoMixObject, bool) {
+	z := new(big.Int)
+	switch op {
+	case "+":
+		z.Add(a, b)
+	case "-":
+		z.Sub(a, b)
+	case "*":
+		z.Mul(a, b)
+	case "/", "%":
+		if b.Sign() == 0 {
+			return createError("ERROR: division by zero"), true
+		}
+		if op == "/" {
+			z.Quo(a, b)
+		} else {
+			z.Rem(a, b)
+		}
+	case "&":
+		z.And(a, b)
+	case "|":
+		z.Or(a, b)
+	case "^":
+		z.Xor(a, b)
+	case "<<", ">>":
+		if b.Sign() < 0 || !b.IsInt64() || b.Int64() > maxShift {
+			return createError("ERROR: shift count must be an int from 0 to %d, got %s", maxShift, b.String()), true
+		}
+		if op == "<<" {
+			z.Lsh(a, uint(b.Int64()))
+		} else {
+			z.Rsh(a, uint(b.Int64()))
+		}
+	default:
+		return nil, false
+	}
+	return &BigInt{Value: z}, true
+}
+
+// decimalOp applies an arithmetic operator to two decimals. The result has
+// the larger scale of the two.
+func decimalOp(op string, a, b *Decimal) (GoMixObject, bool) {
+	scale := a.Scale
+	if b.Scale > scale {
+		scale = b.Scale
+	}
+	x := a.Rescale(scale).Unscaled
+	y := b.Rescale(scale).Unscaled
+	z := new(big.Int)
+	switch op {
+	case "+":
+		z.Add(x, y)
+	case "-":
+		z.Sub(x, y)
+	case "*":
+		z = roundQuo(z.Mul(x, y), pow10(scale))
+	case "/", "%":
+		if y.Sign() == 0 {
+			return createError("ERROR: division by zero"), true
+		}
+		if op == "/" {
+			z = roundQuo(z.Mul(x, pow10(scale)), y)
+		} else {
+			z.Rem(x, y)
+		}
+	default:
+		return nil, false
+	}
+	return &Decimal{Unscaled: z, Scale: scale}, true
+}
+
+// CompareNumbers compares two numbers exactly, when at least one of them is
+// a bigint or a decimal (and the other an int, float, bigint or decimal).
+//
+// Returns:
+//   - int: -1, 0 or +1 as left is less than, equal to or greater than right
+//   - bool: false if neither is a bigint or a decimal, an operand is not a
+//     number, or a float operand is NaN
+func CompareNumbers(left, right GoMixObject) (int, bool) {
+	if !IsBigNumber(left) && !IsBigNumber(right) {
+		return 0, false
+	}
+	if f, ok := left.(*Float); ok && math.IsInf(f.Value, 0) {
+		if _, ok := toRat(right); ok {
+			return int(math.Copysign(1, f.Value)), true
+		}
+	}
+	if f, ok := right.(*Float); ok && math.IsInf(f.Value, 0) {
+		if _, ok := toRat(left); ok {
+			return -int(math.Copysign(1, f.Value)), true
+		}
+	}
+	l, lok := toRat(left)
+	r, rok := toRat(right)
+	if !lok || !rok {
+		return 0, false
+	}
+	return l.Cmp(r), true
+}
+
+// NegateNumber returns -obj for a bigint or decimal.
+func NegateNumber(obj GoMixObject) (GoMixObject, bool) {
+	switch obj := obj.(type) {
+	case *BigInt:
+		return &BigInt{Value: new(big.Int).Neg(obj.Value)}, true
+	case *Decimal:
+		return &Decimal{Unscaled: new(big.Int).Neg(obj.Unscaled), Scale: obj.Scale}, true
+	}
+	return nil, false
+}
@@ -14,13 +14,35 @@ package std
 import (
 	"bufio"
 	"io" // io.Writer is used for output operations in builtin functions
+	"time"
 )
 
 // Runtime defines the interface for the evaluator to allow builtins
 // to call back into Go-Mix functions (e.g., for custom sorting).
+//
+// Go-Mix goroutines (see sync.go) take turns running: Wait and Blocking let
+// other goroutines run while a builtin waits, and Attach lets a Go goroutine
+// (e.g., an HTTP handler) run Go-Mix code in turn with them.
 type Runtime interface {
 	CallFunction(fn GoMixObject, args ...GoMixObject) GoMixObject
+	CallMethod(obj *GoMixObjectInstance, name string, args ...GoMixObject) GoMixObject // Calls a method with 'this' bound to obj
 	GetInputReader() *bufio.Reader
+	GetPermissions() *Permissions // Capabilities granted to the program (nil grants all)
+
+	// SetField assigns a field of a struct instance as obj.name = val does,
+	// checking the annotated type of the field. It returns nil or an Error.
+	SetField(obj *GoMixObjectInstance, name string, val GoMixObject) GoMixObject
+	// LookUpType resolves the name of a struct, interface or enum type as seen
+	// from the running code (e.g., "Point" or "util.Point").
+	LookUpType(name string) (GoMixObject, bool)
+
+	// Wait blocks until ready returns true, or until timeout passes (if > 0), and
+	// reports whether ready succeeded. ready may update shared state (it runs in
+	// turn with the other goroutines, which are woken when it succeeds). The
+	// error is non-nil when every goroutine is blocked or the program is stopped.
+	Wait(ready func() bool, timeout time.Duration) (bool, GoMixObject)
+	Blocking(fn func())                                 // Runs fn (e.g., a sleep) while other goroutines run
+	Attach(fn func(rt Runtime) GoMixObject) GoMixObject // Runs fn as a new Go-Mix goroutine on the calling goroutine
 }
 
 // CallbackFunc is the function signature for builtin functions.
@@ -30,9 +52,43 @@ type CallbackFunc func(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixO
 
 // Builtin represents a builtin function with a name and its implementation callback.
 // This struct is used to store and invoke builtin functions in the language.
+// The signature is the documented form shown by the language server.
 type Builtin struct {
-	Name     string       // The name of the builtin function (e.g., "print")
-	Callback CallbackFunc // The function that implements the builtin behavior
+	Name      string       // The name of the builtin function (e.g., "print")
+	Callback  CallbackFunc // The function that implements the builtin behavior
+	Signature string       // The documented signature (e.g., "abs(n) -> int")
+}
+
+// GetType returns the type of the builtin, which is "func".
+// Builtins are first-class values: they can be stored in variables and passed
+// to other functions (e.g., map_array(words, upper)).
+func (b *Builtin) GetType() GoMixType {
+	return FunctionType
+}
+
+// ToString returns the string representation of the builtin in the format "builtin name".
+func (b *Builtin) ToString() string {
+	return "builtin " + b.Name
+}
+
+// ToObject returns the representation of the builtin in the format "<builtin name>".
+func (b *Builtin) ToObject() string {
+	return "<builtin " + b.Name + ">"
+}
+
+// GetName returns the name of the builtin (implements FunctionInterface).
+func (b *Builtin) GetName() string {
+	return b.Name
+}
+
+// GetParameters returns nil: builtins check their own arguments (implements FunctionInterface).
+func (b *Builtin) GetParameters() []string {
+	return nil
+}
+
+// GetBody returns an empty string: builtins are implemented in Go (implements FunctionInterface).
+func (b *Builtin) GetBody() string {
+	return ""
 }
 
 // Builtins is a global slice of pointers to Builtin structs.
@@ -44,10 +100,13 @@ var Builtins = make([]*Builtin, 0)
 // It provides a way to organize builtins into namespaces.
 // Packages can be imported with optional aliases (e.g., "import math as m").
 // If an alias is provided, the alias is used as the namespace; otherwise, the original name is used.
+// Packages loaded from user modules (e.g., "import \"lib/util.gm\" as util") also carry
+// the module's exported structs, enums, constants and functions in Members.
 type Package struct {
-	Name      string              // The package name (e.g., "math")
-	Alias     string              // Optional alias for the package (e.g., "m"); if empty, Name is used
-	Functions map[string]*Builtin // Map of function name to builtin function
+	Name      string                 // The package name (e.g., "math")
+	Alias     string                 // Optional alias for the package (e.g., "m"); if empty, Name is used
+	Functions map[string]*Builtin    // Map of function name to builtin function
+	Members   map[string]GoMixObject // Exported non-builtin members of a user module (nil for builtin packages)
 }
 
 // GetType returns the type of the Package object
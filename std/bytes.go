@@ -0,0 +1,572 @@
+/*
+File    : go-mix/std/bytes.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - bytes.go
+// This file implements the bytes type of Go-Mix, an immutable sequence of
+// bytes for binary data: file contents, digests, network payloads and the
+// fields of binary protocols.
+//
+// A bytes value is indexed (b[0] is an int from 0 to 255), sliced (b[1:3]
+// is bytes), measured with length, joined with + and iterated with foreach.
+// bytes("text") encodes a string as UTF-8 and decode_bytes(b) decodes it.
+//
+// The builtins that read binary data return bytes when asked with a last
+// argument "bytes" (read_file(path, "bytes"), hex_decode(s, "bytes"),
+// get_http(url, "bytes"), ...) or, for file handles, when the file is opened
+// in a binary mode ("rb", "wb", ...). The builtins that write data, hash it
+// or send it accept bytes as well as strings.
+//
+// pack and unpack convert between values and their binary encoding, with a
+// format of type codes like that of Python's struct module:
+//
+//	pack("<HI", 1, 2)            // b"\x01\x00\x02\x00\x00\x00"
+//	unpack(">h", bytes([255, 254]))   // tuple(-2)
+package std
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Bytes represents an immutable sequence of bytes in Go-Mix.
+type Bytes struct {
+	Value []byte // The underlying bytes (never modified once created)
+}
+
+// GetType returns the type of the Bytes object
+func (b *Bytes) GetType() GoMixType {
+	return BytesType
+}
+
+// ToString returns the bytes as a quoted literal, with the printable ASCII
+// characters as they are and the other bytes escaped (e.g., b"PNG\x0d\x0a")
+func (b *Bytes) ToString() string {
+	var sb strings.Builder
+	sb.WriteString(`b"`)
+	for _, c := range b.Value {
+		switch {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c >= 0x20 && c < 0x7f:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// ToObject returns a detailed representation including type info (e.g., `<bytes(b"ab")>`)
+func (b *Bytes) ToObject() string {
+	return fmt.Sprintf("<bytes(%s)>", b.ToString())
+}
+
+// Hash returns the HashKey of a Bytes.
+func (b *Bytes) Hash() HashKey {
+	return HashKey{Type: BytesType, Value: string(b.Value)}
+}
+
+// BytesOf returns the data of a builtin argument: the bytes of a bytes
+// value, or the string form of any other value.
+func BytesOf(obj GoMixObject) []byte {
+	if b, ok := obj.(*Bytes); ok {
+		return b.Value
+	}
+	return []byte(obj.ToString())
+}
+
+// ResultMode checks the optional last argument of the builtin name, which
+// takes n other arguments, and reports whether it asks for a bytes result
+// ("bytes"; "string" is the default).
+//
+// Returns:
+//   - true if the result is to be bytes
+//   - nil, or an Error if the argument is neither "bytes" nor "string"
+func ResultMode(name string, args []GoMixObject, n int) (bool, GoMixObject) {
+	if len(args) <= n {
+		return false, nil
+	}
+	mode, ok := args[n].(*String)
+	if !ok || (mode.Value != "bytes" && mode.Value != "string") {
+		return false, createError("ERROR: last argument to `%s` must be \"bytes\" or \"string\", got %s", name, args[n].ToString())
+	}
+	return mode.Value == "bytes", nil
+}
+
+// NewData returns data as bytes or as a string, as chosen by ResultMode.
+func NewData(asBytes bool, data []byte) GoMixObject {
+	if asBytes {
+		return &Bytes{Value: data}
+	}
+	return &String{Value: string(data)}
+}
+
+// bytesIterator walks the bytes of a bytes value, as ints.
+type bytesIterator struct {
+	data []byte
+	pos  int
+}
+
+// Next implements Iterator.
+func (it *bytesIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	if it.pos >= len(it.data) {
+		return nil, nil, nil
+	}
+	it.pos++
+	return &Integer{Value: int64(it.pos - 1)}, &Integer{Value: int64(it.data[it.pos-1])}, nil
+}
+
+var bytesMethods = []*Builtin{
+	{Name: "bytes", Callback: bytesFunc, Signature: "bytes(value) -> bytes"},                        // Converts a value to bytes
+	{Name: "decode_bytes", Callback: decodeBytes, Signature: "decode_bytes(b) -> string"},           // Decodes UTF-8 bytes into a string
+	{Name: "join_bytes", Callback: joinBytes, Signature: "join_bytes(parts, [separator]) -> bytes"}, // Joins bytes values with a separator
+	{Name: "index_bytes", Callback: indexBytes, Signature: "index_bytes(b, sub) -> int"},            // Returns the position of a sub-sequence, or -1
+	{Name: "pack", Callback: packFunc, Signature: "pack(format, ...values) -> bytes"},               // Encodes values into bytes with a format
+	{Name: "unpack", Callback: unpackFunc, Signature: "unpack(format, data, [offset]) -> tuple"},    // Decodes bytes into a tuple of values with a format
+	{Name: "size_pack", Callback: sizePack, Signature: "size_pack(format) -> int"},                  // Returns the number of bytes of a pack format
+}
+
+// init registers the bytes methods as global builtins and as a package for import.
+func init() {
+	// Register as global builtins (for backward compatibility)
+	Builtins = append(Builtins, bytesMethods...)
+
+	// Register as a package (for import functionality)
+	bytesPackage := &Package{
+		Name:      "bytes",
+		Functions: make(map[string]*Builtin),
+	}
+	for _, method := range bytesMethods {
+		bytesPackage.Functions[method.Name] = method
+	}
+	RegisterPackage(bytesPackage)
+}
+
+// bytesFunc converts a value to bytes.
+//
+// Syntax: bytes(value)
+//
+// Usage:
+//   - If value is a string (or a char), returns its UTF-8 encoding.
+//   - If value is an int n, returns n zero bytes.
+//   - If value is bytes, returns it as is.
+//   - If value is any other iterable, its elements must be ints from 0 to 255.
+//
+// Example:
+//
+//	bytes("hi")          // b"hi"
+//	bytes(3)             // b"\x00\x00\x00"
+//	bytes([0xCA, 0xFE])  // b"\xca\xfe"
+func bytesFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: bytes expects 1 argument, got %d", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *Bytes:
+		return arg
+	case *String:
+		return &Bytes{Value: []byte(arg.Value)}
+	case *Char:
+		return &Bytes{Value: []byte(string(arg.Value))}
+	case *Integer:
+		if arg.Value < 0 {
+			return createError("ERROR: size of bytes must be non-negative, got %d", arg.Value)
+		}
+		return &Bytes{Value: make([]byte, arg.Value)}
+	}
+
+	if NewIterator(args[0]) == nil {
+		return createError("ERROR: cannot convert %s to bytes", args[0].GetType())
+	}
+	data := make([]byte, 0)
+	var errObj GoMixObject
+	err := Iterate(rt, args[0], func(key, value GoMixObject) bool {
+		n, ok := value.(*Integer)
+		if !ok || n.Value < 0 || n.Value > 255 {
+			errObj = createError("ERROR: elements of bytes must be ints from 0 to 255, got %s", value.ToString())
+			return false
+		}
+		data = append(data, byte(n.Value))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if errObj != nil {
+		return errObj
+	}
+	return &Bytes{Value: data}
+}
+
+// decodeBytes decodes UTF-8 bytes into a string.
+//
+// Syntax: decode_bytes(b)
+//
+// Bytes that are not valid UTF-8 are an error.
+//
+// Example:
+//
+//	decode_bytes(bytes("héllo"))   // "héllo"
+func decodeBytes(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: decode_bytes expects 1 argument, got %d", len(args))
+	}
+	b, ok := args[0].(*Bytes)
+	if !ok {
+		return createError("ERROR: argument to `decode_bytes` must be bytes, got '%s'", args[0].GetType())
+	}
+	if !utf8.Valid(b.Value) {
+		return createError("ERROR: bytes are not valid UTF-8")
+	}
+	return &String{Value: string(b.Value)}
+}
+
+// joinBytes joins the bytes values of an iterable, with an optional separator.
+//
+// Syntax: join_bytes(parts, [separator])
+//
+// Example:
+//
+//	join_bytes([bytes("a"), bytes("b")], bytes(","))   // b"a,b"
+func joinBytes(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: join_bytes expects 1 or 2 arguments (parts, [separator]), got %d", len(args))
+	}
+	parts, err := iterableArg(rt, "join_bytes", args[0])
+	if err != nil {
+		return err
+	}
+	var sep []byte
+	if len(args) == 2 {
+		sepBytes, ok := args[1].(*Bytes)
+		if !ok {
+			return createError("ERROR: separator of `join_bytes` must be bytes, got '%s'", args[1].GetType())
+		}
+		sep = sepBytes.Value
+	}
+	data := make([]byte, 0)
+	for i, part := range parts {
+		b, ok := part.(*Bytes)
+		if !ok {
+			return createError("ERROR: elements joined by `join_bytes` must be bytes, got '%s'", part.GetType())
+		}
+		if i > 0 {
+			data = append(data, sep...)
+		}
+		data = append(data, b.Value...)
+	}
+	return &Bytes{Value: data}
+}
+
+// indexBytes returns the position of the first occurrence of sub in b, or -1.
+//
+// Syntax: index_bytes(b, sub)
+//
+// Example:
+//
+//	index_bytes(bytes("a=b"), bytes("="))   // 1
+func indexBytes(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 {
+		return createError("ERROR: index_bytes expects 2 arguments (b, sub), got %d", len(args))
+	}
+	b, ok := args[0].(*Bytes)
+	if !ok {
+		return createError("ERROR: first argument to `index_bytes` must be bytes, got '%s'", args[0].GetType())
+	}
+	sub, ok := args[1].(*Bytes)
+	if !ok {
+		return createError("ERROR: second argument to `index_bytes` must be bytes, got '%s'", args[1].GetType())
+	}
+	return &Integer{Value: int64(strings.Index(string(b.Value), string(sub.Value)))}
+}
+
+// packField is one value of a pack format: its type code and byte order.
+type packField struct {
+	code  byte
+	order binary.ByteOrder
+}
+
+// packSizes gives the number of bytes of each type code of a pack format.
+var packSizes = map[byte]int{
+	'x': 1,         // a padding byte (no value)
+	'b': 1, 'B': 1, // int8, uint8
+	'h': 2, 'H': 2, // int16, uint16
+	'i': 4, 'I': 4, // int32, uint32
+	'q': 8, 'Q': 8, // int64, uint64
+	'f': 4, 'd': 8, // float32, float64
+}
+
+// parsePackFormat parses a pack format: an optional byte order ('<' for
+// little-endian, '>' or '!' for big-endian, the default) followed by type
+// codes, each with an optional repeat count ("3B" is "BBB").
+//
+// Returns:
+//   - The fields of the format, padding included
+//   - The number of bytes of the format
+//   - nil, or an Error for an invalid format
+func parsePackFormat(name, format string) ([]packField, int, GoMixObject) {
+	var order binary.ByteOrder = binary.BigEndian
+	if format != "" {
+		switch format[0] {
+		case '<':
+			order, format = binary.LittleEndian, format[1:]
+		case '>', '!':
+			format = format[1:]
+		}
+	}
+	fields := make([]packField, 0)
+	size := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == ' ' {
+			continue
+		}
+		count := 1
+		if c >= '0' && c <= '9' {
+			start := i
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+			n, err := strconv.Atoi(format[start:i])
+			if err != nil || i == len(format) || n > math.MaxInt32 {
+				return nil, 0, createError("ERROR: invalid format for `%s`: repeat count must be followed by a type code", name)
+			}
+			count, c = n, format[i]
+		}
+		codeSize, ok := packSizes[c]
+		if !ok {
+			return nil, 0, createError("ERROR: invalid format for `%s`: unknown type code '%c'", name, c)
+		}
+		for j := 0; j < count; j++ {
+			fields = append(fields, packField{code: c, order: order})
+		}
+		size += count * codeSize
+	}
+	return fields, size, nil
+}
+
+// packInteger returns the int value of obj, checked to fit the type code.
+func packInteger(name string, code byte, obj GoMixObject) (uint64, GoMixObject) {
+	var n *big.Int
+	switch v := obj.(type) {
+	case *Integer:
+		n = big.NewInt(v.Value)
+	case *BigInt:
+		n = v.Value
+	default:
+		return 0, createError("ERROR: `%s` format '%c' expects an int, got '%s'", name, code, obj.GetType())
+	}
+	bits := uint(packSizes[code] * 8)
+	var lo, hi *big.Int
+	if code >= 'a' {
+		// signed: -2^(bits-1) .. 2^(bits-1)-1
+		hi = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits-1), big.NewInt(1))
+		lo = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), bits-1))
+	} else {
+		// unsigned: 0 .. 2^bits-1
+		hi = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+		lo = big.NewInt(0)
+	}
+	if n.Cmp(lo) < 0 || n.Cmp(hi) > 0 {
+		return 0, createError("ERROR: `%s` format '%c' requires %s <= value <= %s, got %s", name, code, lo, hi, n)
+	}
+	if n.Sign() < 0 {
+		return uint64(n.Int64()), nil
+	}
+	return n.Uint64(), nil
+}
+
+// packFunc encodes values into bytes, as described by a format.
+//
+// Syntax: pack(format, value1, value2, ...)
+//
+// Type codes: b/B (int8/uint8), h/H (int16/uint16), i/I (int32/uint32),
+// q/Q (int64/uint64), f (float32), d (float64) and x (a zero padding byte,
+// which takes no value). A format starts with '<' for little-endian or '>'
+// (or '!') for big-endian, the default.
+//
+// Example:
+//
+//	pack(">HHf", 1, 2, 1.5)   // b"\x00\x01\x00\x02?\xc0\x00\x00"
+func packFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) == 0 {
+		return createError("ERROR: pack expects at least 1 argument (format)")
+	}
+	if args[0].GetType() != StringType {
+		return createError("ERROR: first argument to `pack` must be a string, got '%s'", args[0].GetType())
+	}
+	fields, size, errObj := parsePackFormat("pack", args[0].ToString())
+	if errObj != nil {
+		return errObj
+	}
+
+	values := args[1:]
+	wanted := 0
+	for _, field := range fields {
+		if field.code != 'x' {
+			wanted++
+		}
+	}
+	if len(values) != wanted {
+		return createError("ERROR: pack format %q expects %d values, got %d", args[0].ToString(), wanted, len(values))
+	}
+
+	data := make([]byte, size)
+	pos := 0
+	for _, field := range fields {
+		width := packSizes[field.code]
+		chunk := data[pos : pos+width]
+		pos += width
+		if field.code == 'x' {
+			continue
+		}
+		value := values[0]
+		values = values[1:]
+		var raw uint64
+		switch field.code {
+		case 'f', 'd':
+			f, ok := ToFloat64(value)
+			if !ok {
+				return createError("ERROR: `pack` format '%c' expects a number, got '%s'", field.code, value.GetType())
+			}
+			if field.code == 'f' {
+				raw = uint64(math.Float32bits(float32(f)))
+			} else {
+				raw = math.Float64bits(f)
+			}
+		default:
+			n, errObj := packInteger("pack", field.code, value)
+			if errObj != nil {
+				return errObj
+			}
+			raw = n
+		}
+		switch width {
+		case 1:
+			chunk[0] = byte(raw)
+		case 2:
+			field.order.PutUint16(chunk, uint16(raw))
+		case 4:
+			field.order.PutUint32(chunk, uint32(raw))
+		case 8:
+			field.order.PutUint64(chunk, raw)
+		}
+	}
+	return &Bytes{Value: data}
+}
+
+// unpackFunc decodes the values of a format from bytes, starting at an
+// optional offset. Bytes after the values are ignored.
+//
+// Syntax: unpack(format, data, [offset])
+//
+// Returns a tuple of the values: ints for the integer codes (a bigint for a
+// Q value too large for an int) and floats for f and d.
+//
+// Example:
+//
+//	var (kind, length) = unpack(">BH", header);
+func unpackFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 && len(args) != 3 {
+		return createError("ERROR: unpack expects 2 or 3 arguments (format, data, [offset]), got %d", len(args))
+	}
+	if args[0].GetType() != StringType {
+		return createError("ERROR: first argument to `unpack` must be a string, got '%s'", args[0].GetType())
+	}
+	b, ok := args[1].(*Bytes)
+	if !ok {
+		return createError("ERROR: second argument to `unpack` must be bytes, got '%s'", args[1].GetType())
+	}
+	fields, size, errObj := parsePackFormat("unpack", args[0].ToString())
+	if errObj != nil {
+		return errObj
+	}
+	offset := 0
+	if len(args) == 3 {
+		off, ok := args[2].(*Integer)
+		if !ok || off.Value < 0 || off.Value > int64(len(b.Value)) {
+			return createError("ERROR: offset of `unpack` must be an int from 0 to %d, got %s", len(b.Value), args[2].ToString())
+		}
+		offset = int(off.Value)
+	}
+	data := b.Value[offset:]
+	if len(data) < size {
+		return createError("ERROR: unpack format %q needs %d bytes, got %d", args[0].ToString(), size, len(data))
+	}
+
+	values := make([]GoMixObject, 0, len(fields))
+	for _, field := range fields {
+		width := packSizes[field.code]
+		chunk := data[:width]
+		data = data[width:]
+		var raw uint64
+		switch width {
+		case 1:
+			raw = uint64(chunk[0])
+		case 2:
+			raw = uint64(field.order.Uint16(chunk))
+		case 4:
+			raw = uint64(field.order.Uint32(chunk))
+		case 8:
+			raw = field.order.Uint64(chunk)
+		}
+		switch field.code {
+		case 'x':
+			continue
+		case 'b':
+			values = append(values, &Integer{Value: int64(int8(raw))})
+		case 'h':
+			values = append(values, &Integer{Value: int64(int16(raw))})
+		case 'i':
+			values = append(values, &Integer{Value: int64(int32(raw))})
+		case 'q':
+			values = append(values, &Integer{Value: int64(raw)})
+		case 'Q':
+			values = append(values, NewInteger(new(big.Int).SetUint64(raw)))
+		case 'f':
+			values = append(values, &Float{Value: float64(math.Float32frombits(uint32(raw)))})
+		case 'd':
+			values = append(values, &Float{Value: math.Float64frombits(raw)})
+		default:
+			values = append(values, &Integer{Value: int64(raw)})
+		}
+	}
+	return &Tuple{Elements: values}
+}
+
+// sizePack returns the number of bytes that a pack format encodes.
+//
+// Syntax: size_pack(format)
+//
+// Example:
+//
+//	size_pack("<HId")   // 14
+func sizePack(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: size_pack expects 1 argument (format), got %d", len(args))
+	}
+	if args[0].GetType() != StringType {
+		return createError("ERROR: argument to `size_pack` must be a string, got '%s'", args[0].GetType())
+	}
+	_, size, errObj := parsePackFormat("size_pack", args[0].ToString())
+	if errObj != nil {
+		return errObj
+	}
+	return &Integer{Value: int64(size)}
+}
@@ -0,0 +1,223 @@
+/*
+File    : go-mix/std/capabilities.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - capabilities.go
+// This file defines the capability groups that guard builtins touching the host
+// (files, processes, environment and network), and the Permissions that grant them.
+// Builtins in these groups check the permissions of their Runtime before acting
+// and fail with a permission error when the capability is not granted.
+package std
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Capability names a group of host operations that scripts may be granted.
+type Capability string
+
+const (
+	// CapFSRead allows reading files and directories (read_file, list_dir, fopen "r", ...)
+	CapFSRead Capability = "fs-read"
+	// CapFSWrite allows creating, changing and removing files (write_file, remove_all, ...)
+	CapFSWrite Capability = "fs-write"
+	// CapProcess allows running commands and terminating the process (exec, exit)
+	CapProcess Capability = "process"
+	// CapEnv allows reading and changing environment variables (getenv, setenv, unsetenv)
+	CapEnv Capability = "env"
+	// CapNetwork allows HTTP requests and servers (get_http, download_file, listen_http, ...)
+	CapNetwork Capability = "network"
+)
+
+// Capabilities lists every capability, in a stable order.
+var Capabilities = []Capability{CapFSRead, CapFSWrite, CapProcess, CapEnv, CapNetwork}
+
+// ParseCapability converts a capability name into a Capability.
+// "net" is accepted as a short name for "network".
+func ParseCapability(name string) (Capability, bool) {
+	if name == "net" {
+		return CapNetwork, true
+	}
+	for _, c := range Capabilities {
+		if string(c) == name {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// grant records how a capability is granted: everywhere, or (for the file
+// system capabilities) only below a set of directories.
+type grant struct {
+	all   bool
+	roots []string
+}
+
+// Permissions is the set of capabilities granted to a program.
+//
+// The file system capabilities can be granted for the whole file system or
+// only below some directories; the other capabilities are all-or-nothing.
+//
+// Example:
+//
+//	perms := std.Restricted()
+//	perms.Grant(std.CapFSRead, "./data")  // read files below ./data only
+//	perms.Grant(std.CapEnv)               // read and change environment variables
+type Permissions struct {
+	grants map[Capability]*grant
+}
+
+// AllowAll returns permissions granting every capability (the default of the CLI).
+func AllowAll() *Permissions {
+	p := Restricted()
+	for _, c := range Capabilities {
+		p.Grant(c)
+	}
+	return p
+}
+
+// Restricted returns permissions granting no capability (the sandbox profile).
+func Restricted() *Permissions {
+	return &Permissions{grants: make(map[Capability]*grant)}
+}
+
+// Grant grants a capability. Without paths the capability is granted fully;
+// with paths, file system access is granted below those paths only, in
+// addition to any paths granted before.
+//
+// Parameters:
+//   - c: The capability to grant
+//   - paths: Directories (or files) that file system access is limited to
+func (p *Permissions) Grant(c Capability, paths ...string) {
+	g, ok := p.grants[c]
+	if !ok {
+		g = &grant{}
+		p.grants[c] = g
+	}
+	if len(paths) == 0 {
+		g.all = true
+		g.roots = nil
+		return
+	}
+	if g.all {
+		// a full grant is narrowed to the given paths
+		g.all = false
+	}
+	for _, path := range paths {
+		g.roots = append(g.roots, resolvePath(path))
+	}
+}
+
+// Deny revokes a capability.
+func (p *Permissions) Deny(c Capability) {
+	delete(p.grants, c)
+}
+
+// Allows reports whether the capability is granted for a resource, which is
+// the path being accessed for the file system capabilities (ignored otherwise).
+func (p *Permissions) Allows(c Capability, resource string) bool {
+	g, ok := p.grants[c]
+	if !ok {
+		return false
+	}
+	if g.all || (c != CapFSRead && c != CapFSWrite) {
+		return true
+	}
+	path := resolvePath(resource)
+	for _, root := range g.roots {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy returns an independent copy of the permissions.
+func (p *Permissions) Copy() *Permissions {
+	cp := Restricted()
+	for c, g := range p.grants {
+		cp.grants[c] = &grant{all: g.all, roots: append([]string(nil), g.roots...)}
+	}
+	return cp
+}
+
+// String describes the granted capabilities, e.g. "fs-read(/srv/data) env".
+func (p *Permissions) String() string {
+	parts := make([]string, 0, len(p.grants))
+	for _, c := range Capabilities {
+		g, ok := p.grants[c]
+		if !ok {
+			continue
+		}
+		if g.all {
+			parts = append(parts, string(c))
+		} else {
+			roots := append([]string(nil), g.roots...)
+			sort.Strings(roots)
+			parts = append(parts, fmt.Sprintf("%s(%s)", c, strings.Join(roots, ",")))
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " ")
+}
+
+// resolvePath returns the absolute, symlink-free form of a path, so that
+// links cannot be used to escape a granted directory. Paths that do not
+// exist yet are resolved through their closest existing parent.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	rest := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(real, rest)
+		}
+		if _, err := os.Lstat(dir); err == nil || filepath.Dir(dir) == dir {
+			return abs
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
+
+// checkCapability returns a permission error if the runtime does not grant
+// capability c for resource, or nil. Builtins of the guarded groups call it
+// before touching the host; a nil runtime or nil permissions grant everything.
+//
+// Parameters:
+//   - rt: The runtime calling the builtin
+//   - name: The builtin name, for the error message
+//   - c: The capability required
+//   - resource: The path accessed (file system capabilities only)
+func checkCapability(rt Runtime, name string, c Capability, resource string) *Error {
+	if rt == nil {
+		return nil
+	}
+	perms := rt.GetPermissions()
+	if perms == nil || perms.Allows(c, resource) {
+		return nil
+	}
+	if c == CapFSRead || c == CapFSWrite {
+		return createError("ERROR: permission denied: `%s` needs %s access to '%s'", name, c, resource)
+	}
+	return createError("ERROR: permission denied: `%s` needs the %s capability", name, c)
+}
+
+// CheckCapability is the exported form of checkCapability, for builtins
+// defined outside this package (e.g., the file package). It returns nil when
+// the capability is granted.
+func CheckCapability(rt Runtime, name string, c Capability, resource string) GoMixObject {
+	if err := checkCapability(rt, name, c, resource); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+/*
+File    : go-mix/std/codec.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - codec.go
+// This file holds what the data file packages (json, csv, toml and yaml) share:
+// the conversion of Go-Mix values into plain data before they are encoded, and
+// the reading of their options and input.
+//
+// Plain data is made of maps with string keys (in insertion order), arrays,
+// strings, ints, bigints, floats, decimals, bools and nil. Values are converted
+// into it as follows (the rules of the json package):
+//   - map: a map whose keys are converted to strings (e.g. 1 -> "1")
+//   - array, list, tuple, set, range: an array
+//   - char: a string; bytes: a base64 string
+//   - struct instance: the value returned by its __json__() method, or a map
+//     of its fields: the declared fields (the parents' first, in declaration
+//     order, constant fields left out), then the fields set only on the
+//     instance, by name
+//   - enum type: a map of its members and their values
+//
+// Other values (functions, files, ...) and values containing themselves cannot
+// be encoded.
+package std
+
+import (
+	"encoding/base64"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// codecData converts obj into plain data, for encoding in the given format
+// (e.g., "JSON", named in errors).
+func codecData(rt Runtime, format string, obj GoMixObject) (GoMixObject, GoMixObject) {
+	c := &dataConverter{rt: rt, format: format, seen: make(map[GoMixObject]bool)}
+	return c.convert(obj)
+}
+
+// dataConverter converts values into plain data (see codecData).
+type dataConverter struct {
+	rt     Runtime
+	format string
+	seen   map[GoMixObject]bool // The collections being converted, to reject cycles
+}
+
+// convert converts one value into plain data.
+func (c *dataConverter) convert(obj GoMixObject) (GoMixObject, GoMixObject) {
+	switch o := obj.(type) {
+	case *Nil, *Boolean, *Integer, *Float, *BigInt, *Decimal, *String:
+		return obj, nil
+	case *Char:
+		return &String{Value: string(o.Value)}, nil
+	case *Bytes:
+		return &String{Value: base64.StdEncoding.EncodeToString(o.Value)}, nil
+	case *Array:
+		return c.array(o, o.Elements)
+	case *List:
+		return c.array(o, o.Elements)
+	case *Tuple:
+		return c.array(o, o.Elements)
+	case *Set:
+		return c.array(o, o.Values())
+	case *Range:
+		elements := make([]GoMixObject, 0)
+		step := int64(1)
+		if o.End < o.Start {
+			step = -1
+		}
+		for i := o.Start; ; i += step {
+			elements = append(elements, &Integer{Value: i})
+			if i == o.End {
+				break
+			}
+		}
+		return &Array{Elements: elements}, nil
+	case *Map:
+		return c.object(o, o.Entries())
+	case *GoMixEnum:
+		return c.object(o, enumEntries(o))
+	case *GoMixObjectInstance:
+		return c.instance(o)
+	}
+	return nil, createError("ERROR: cannot encode %s as %s", obj.GetType(), c.format)
+}
+
+// array converts the elements of a collection into an array.
+func (c *dataConverter) array(obj GoMixObject, elements []GoMixObject) (GoMixObject, GoMixObject) {
+	if c.seen[obj] {
+		return nil, createError("ERROR: cannot encode %s containing itself as %s", obj.GetType(), c.format)
+	}
+	c.seen[obj] = true
+	defer delete(c.seen, obj)
+	res := make([]GoMixObject, len(elements))
+	for i, elem := range elements {
+		val, err := c.convert(elem)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = val
+	}
+	return &Array{Elements: res}, nil
+}
+
+// object converts key-value pairs into a map with string keys.
+func (c *dataConverter) object(obj GoMixObject, pairs []*MapPair) (GoMixObject, GoMixObject) {
+	if c.seen[obj] {
+		return nil, createError("ERROR: cannot encode %s containing itself as %s", obj.GetType(), c.format)
+	}
+	c.seen[obj] = true
+	defer delete(c.seen, obj)
+	res := NewMap()
+	for _, pair := range pairs {
+		val, err := c.convert(pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		res.SetString(pair.Key.ToString(), val)
+	}
+	return res, nil
+}
+
+// instance converts a struct instance: the value returned by its __json__
+// method, or a map of its fields.
+func (c *dataConverter) instance(inst *GoMixObjectInstance) (GoMixObject, GoMixObject) {
+	if c.seen[inst] {
+		return nil, createError("ERROR: cannot encode object(%s) containing itself as %s", inst.Struct.Name, c.format)
+	}
+	if res, ok := CallProtocol(c.rt, inst, "__json__"); ok {
+		if res.GetType() == ErrorType {
+			return nil, res
+		}
+		c.seen[inst] = true
+		defer delete(c.seen, inst)
+		return c.convert(res)
+	}
+	return c.object(inst, instanceEntries(inst))
+}
+
+// instanceEntries returns the fields of a struct instance in the order they
+// are encoded: the declared fields, from the root parent down and in
+// declaration order (constant fields left out), then the fields set only on
+// the instance, sorted by name.
+func instanceEntries(inst *GoMixObjectInstance) []*MapPair {
+	chain := make([]*GoMixStruct, 0)
+	for s := inst.Struct; s != nil; s = s.Parent {
+		chain = append([]*GoMixStruct{s}, chain...)
+	}
+	pairs := make([]*MapPair, 0, len(inst.InstanceFields))
+	done := make(map[string]bool)
+	for _, s := range chain {
+		for _, name := range s.Fields {
+			if done[name] {
+				continue
+			}
+			done[name] = true
+			if _, owner, ok := inst.Struct.LookUpField(name); ok && owner.ConstFields[name] {
+				continue
+			}
+			val, ok := inst.InstanceFields[name]
+			if !ok {
+				if val, _, ok = inst.Struct.LookUpField(name); !ok {
+					continue
+				}
+			}
+			pairs = append(pairs, &MapPair{Key: &String{Value: name}, Value: val})
+		}
+	}
+	rest := make([]string, 0)
+	for name := range inst.InstanceFields {
+		if !done[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		pairs = append(pairs, &MapPair{Key: &String{Value: name}, Value: inst.InstanceFields[name]})
+	}
+	return pairs
+}
+
+// enumEntries returns the members of an enum type with their values, in the
+// order of their values (then of their names).
+func enumEntries(e *GoMixEnum) []*MapPair {
+	pairs := make([]*MapPair, 0, len(e.Members))
+	for name, val := range e.Members {
+		pairs = append(pairs, &MapPair{Key: &String{Value: name}, Value: val})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		a, aok := pairs[i].Value.(*Integer)
+		b, bok := pairs[j].Value.(*Integer)
+		if aok && bok && a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return pairs[i].Key.ToString() < pairs[j].Key.ToString()
+	})
+	return pairs
+}
+
+// formatFloat formats a finite float like encoding/json does, with a ".0"
+// added to integral values so that they decode as floats again.
+func formatFloat(f float64) string {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9, like encoding/json
+		n := len(s)
+		if n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// codecOptions returns the map of options passed to the builtin name, after
+// checking that it only has the given keys. A missing or nil argument gives
+// an empty map.
+func codecOptions(name string, args []GoMixObject, i int, keys ...string) (*Map, GoMixObject) {
+	if len(args) <= i || args[i].GetType() == NilType {
+		return NewMap(), nil
+	}
+	m, ok := args[i].(*Map)
+	if !ok {
+		return nil, createError("ERROR: options of `%s` must be a map, got '%s'", name, args[i].GetType())
+	}
+	for _, pair := range m.Entries() {
+		known := false
+		for _, key := range keys {
+			if pair.Key.ToString() == key {
+				known = true
+			}
+		}
+		if !known {
+			return nil, createError("ERROR: unknown option %q of `%s` (expected %s)", pair.Key.ToString(), name, quoteList(keys))
+		}
+	}
+	return m, nil
+}
+
+// quoteList lists quoted names in errors (e.g., "a", "b" or "c").
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// boolOption returns the bool option key of a map of options, or def if it
+// is not set.
+func boolOption(name string, opts *Map, key string, def bool) (bool, GoMixObject) {
+	val, ok := opts.GetString(key)
+	if !ok {
+		return def, nil
+	}
+	b, ok := val.(*Boolean)
+	if !ok {
+		return false, createError("ERROR: option %q of `%s` must be a bool, got '%s'", key, name, val.GetType())
+	}
+	return b.Value, nil
+}
+
+// readCodecFile reads the input file of the builtin name, after checking
+// that the program may read it.
+func readCodecFile(rt Runtime, name string, path string) ([]byte, GoMixObject) {
+	if err := checkCapability(rt, name, CapFSRead, path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, createError("ERROR: could not read file '%s': %v", path, err)
+	}
+	return data, nil
+}
+
+// writeCodecFile writes the output file of the builtin name, after checking
+// that the program may write it.
+func writeCodecFile(rt Runtime, name string, path string, data []byte) GoMixObject {
+	if err := checkCapability(rt, name, CapFSWrite, path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return createError("ERROR: could not write file '%s': %v", path, err)
+	}
+	return &Nil{}
+}
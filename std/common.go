@@ -21,49 +21,50 @@ import (
 // These include printing functions, length calculation, and string conversion.
 var commonMethods = []*Builtin{
 	// print
-	{Name: "print", Callback: print},     // Prints arguments without a newline
-	{Name: "println", Callback: println}, // Prints arguments with a newline
-	{Name: "printf", Callback: printf},   // Prints formatted string with arguments
+	{Name: "print", Callback: print, Signature: "print(...args) -> nil"},            // Prints arguments without a newline
+	{Name: "println", Callback: println, Signature: "println(...args) -> nil"},      // Prints arguments with a newline
+	{Name: "printf", Callback: printf, Signature: "printf(format, ...args) -> nil"}, // Prints formatted string with arguments
 
-	{Name: "length", Callback: length}, // Returns the length of strings or arrays
-	{Name: "size", Callback: length},   // Alias for length - returns the size of strings, arrays, maps, or sets
+	{Name: "length", Callback: length, Signature: "length(obj) -> int"}, // Returns the length of strings or arrays
+	{Name: "size", Callback: length, Signature: "size(arr) -> int"},     // Alias for length - returns the size of strings, arrays, maps, or sets
 
-	{Name: "to_string", Callback: tostring}, // Converts an object to its string representation
+	{Name: "to_string", Callback: tostring, Signature: "to_string(obj) -> string"}, // Converts an object to its string representation
 	// {Name: "string", Callback: tostring},                     // Alias for tostring - converts an object to a string
-	{Name: "range", Callback: rangeFunc}, // Creates an inclusive range from start to end
+	{Name: "range", Callback: rangeFunc, Signature: "range(start, end) -> range"}, // Creates an inclusive range from start to end
 
 	// constructors
-	{Name: "array", Callback: arrayFunc}, // Converts any iterable to a new array
-	{Name: "list", Callback: listFunc},   // Creates a new mutable list from arguments
-	{Name: "tuple", Callback: tupleFunc}, // Creates a new immutable tuple from arguments
+	{Name: "array", Callback: arrayFunc, Signature: "array(...args) -> array"}, // Converts any iterable to a new array
+	{Name: "list", Callback: listFunc, Signature: "list(...args) -> list"},     // Creates a new mutable list from arguments
+	{Name: "tuple", Callback: tupleFunc, Signature: "tuple(...args) -> tuple"}, // Creates a new immutable tuple from arguments
 
 	// array methods
-	{Name: "push", Callback: pushArray},       // Adds an element to the end of the array
-	{Name: "pop", Callback: popArray},         // Removes and returns the last element of the array
-	{Name: "shift", Callback: shiftArray},     // Removes and returns the first element of the array
-	{Name: "unshift", Callback: unshiftArray}, // Adds an element to the beginning of the array
-	{Name: "sort", Callback: sortArray},       // Sorts the elements of the array in-place
-	{Name: "sorted", Callback: sortedArray},   // Returns a new sorted array
-	{Name: "clone", Callback: cloneArray},     // Returns a shallow copy of the array
-	{Name: "csort", Callback: csortArray},     // Custom sort for an array using a comparator
-	{Name: "csorted", Callback: csortedArray}, // Returns a new sorted array using a comparator
-
-	{Name: "find", Callback: findArray},   // Finds the first element matching a predicate
-	{Name: "some", Callback: someArray},   // Checks if at least one element matches
-	{Name: "every", Callback: everyArray}, // Checks if all elements match
-
-	{Name: "reverse", Callback: reverseArray},   // Returns a new reversed array
-	{Name: "contains", Callback: containsArray}, // Checks if a value exists in the array
-	{Name: "replace", Callback: replaceArray},   // Returns the index of the first occurrence of a value, or -1 if not found
-	{Name: "index", Callback: indexArray},       // Returns the index of the first occurrence of a value, or -1 if not found
-
-	{Name: "json_string_to_map", Callback: jsonParse},     // Converts a JSON string into a map
-	{Name: "map_to_json_string", Callback: jsonStringify}, // Converts a map into a JSON string
-	{Name: "json_encode", Callback: jsonStringify},        // Alias for jsonStringEncode
-
-	{Name: "typeof", Callback: typeofFunc},     // Returns the type of a Go-Mix object as a string
-	{Name: "addr", Callback: addrFunc},         // Returns the memory address of an object as an integer
-	{Name: "is_same_ref", Callback: IsSameRef}, // Checks if two objects point to the same memory address
+	{Name: "push", Callback: pushArray, Signature: "push(arr, elem) -> array"},                // Adds an element to the end of the array
+	{Name: "pop", Callback: popArray, Signature: "pop(arr) -> any"},                           // Removes and returns the last element of the array
+	{Name: "shift", Callback: shiftArray, Signature: "shift(arr) -> any"},                     // Removes and returns the first element of the array
+	{Name: "unshift", Callback: unshiftArray, Signature: "unshift(arr, elem) -> array"},       // Adds an element to the beginning of the array
+	{Name: "sort", Callback: sortArray, Signature: "sort(arr, [reverse]) -> array"},           // Sorts the elements of the array in-place
+	{Name: "sorted", Callback: sortedArray, Signature: "sorted(arr, [reverse]) -> array"},     // Returns a new sorted array
+	{Name: "clone", Callback: cloneArray, Signature: "clone(arr) -> array"},                   // Returns a shallow copy of the array
+	{Name: "csort", Callback: csortArray, Signature: "csort(arr, comparator) -> array"},       // Custom sort for an array using a comparator
+	{Name: "csorted", Callback: csortedArray, Signature: "csorted(arr, comparator) -> array"}, // Returns a new sorted array using a comparator
+
+	{Name: "find", Callback: findArray, Signature: "find(arr, func) -> any"},     // Finds the first element matching a predicate
+	{Name: "some", Callback: someArray, Signature: "some(arr, func) -> bool"},    // Checks if at least one element matches
+	{Name: "every", Callback: everyArray, Signature: "every(arr, func) -> bool"}, // Checks if all elements match
+
+	{Name: "reverse", Callback: reverseArray, Signature: "reverse(arr) -> array"},                 // Returns a new reversed array
+	{Name: "contains", Callback: containsArray, Signature: "contains(arr, value) -> bool"},        // Checks if a value exists in the array
+	{Name: "replace", Callback: replaceArray, Signature: "replace(arr, old_val, new_val) -> int"}, // Returns the index of the first occurrence of a value, or -1 if not found
+	{Name: "index", Callback: indexArray, Signature: "index(arr, value) -> int"},                  // Returns the index of the first occurrence of a value, or -1 if not found
+
+	{Name: "json_string_to_map", Callback: jsonParse, Signature: "json_string_to_map(json) -> map"},     // Converts a JSON string into a map
+	{Name: "map_to_json_string", Callback: jsonStringify, Signature: "map_to_json_string(m) -> string"}, // Converts a map into a JSON string
+	{Name: "json_encode", Callback: jsonStringify, Signature: "json_encode(m) -> string"},               // Alias for map_to_json_string
+
+	{Name: "typeof", Callback: typeofFunc, Signature: "typeof(obj) -> string"},               // Returns the type of a Go-Mix object as a string
+	{Name: "hierarchy", Callback: hierarchyFunc, Signature: "hierarchy(obj) -> string"},      // Returns the parents and interfaces of a struct or of its instance
+	{Name: "addr", Callback: addrFunc, Signature: "addr(obj) -> int"},                        // Returns the memory address of an object as an integer
+	{Name: "is_same_ref", Callback: IsSameRef, Signature: "is_same_ref(obj1, obj2) -> bool"}, // Checks if two objects point to the same memory address
 }
 
 // init registers the common builtin methods by appending them to the global Builtins slice.
@@ -99,8 +100,12 @@ func tostring(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) == 0 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=1", len(args))
 	}
-	// Return the string representation wrapped in quotes
-	return &String{Value: args[0].ToString()}
+	// Return the string representation (from __str__ for struct instances)
+	str, err := Stringify(rt, args[0])
+	if err != nil {
+		return err
+	}
+	return &String{Value: str}
 }
 
 // print outputs the string representations of its arguments to the writer without a trailing newline.
@@ -114,7 +119,11 @@ func print(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	// Build the output string by concatenating string representations with spaces
 	res := ""
 	for _, arg := range args {
-		res += arg.ToString() + " "
+		str, err := Stringify(rt, arg)
+		if err != nil {
+			return err
+		}
+		res += str + " "
 	}
 	// Remove the trailing space if there are arguments
 	if len(args) > 0 {
@@ -141,7 +150,11 @@ func println(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	// Build the output string by concatenating string representations with spaces
 	res := ""
 	for _, arg := range args {
-		res += arg.ToString() + " "
+		str, err := Stringify(rt, arg)
+		if err != nil {
+			return err
+		}
+		res += str + " "
 	}
 	// Remove the trailing space if there are arguments
 	if len(args) > 0 {
@@ -195,13 +208,18 @@ func printf(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 // It takes one argument: the string, array, map, set, list, or tuple to measure.
 // For strings, returns the number of characters; for arrays, returns the number of elements;
 // for maps, returns the number of key-value pairs; for sets, returns the number of unique values;
-// for lists and tuples, returns the number of elements.
+// for lists and tuples, returns the number of elements; for bytes, returns the number
+// of bytes; for struct instances, returns the result of their __len__ method.
 // Returns an error for unsupported types.
 func length(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	// Check if exactly one argument is provided
 	if len(args) != 1 {
 		return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
 	}
+	// Struct instances report their length from __len__
+	if res, ok := ProtocolLength(rt, args[0]); ok {
+		return res
+	}
 	// Determine the type and calculate length accordingly
 	switch args[0].GetType() {
 	case StringType:
@@ -212,16 +230,19 @@ func length(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return &Integer{Value: int64(len(args[0].(*Array).Elements))}
 	case MapType:
 		// Return the number of key-value pairs in the map
-		return &Integer{Value: int64(len(args[0].(*Map).Pairs))}
+		return &Integer{Value: int64(args[0].(*Map).Len())}
 	case SetType:
 		// Return the number of unique values in the set
-		return &Integer{Value: int64(len(args[0].(*Set).Values))}
+		return &Integer{Value: int64(args[0].(*Set).Len())}
 	case ListType:
 		// Return the number of elements in the list
 		return &Integer{Value: int64(len(args[0].(*List).Elements))}
 	case TupleType:
 		// Return the number of elements in the tuple
 		return &Integer{Value: int64(len(args[0].(*Tuple).Elements))}
+	case BytesType:
+		// Return the number of bytes
+		return &Integer{Value: int64(len(args[0].(*Bytes).Value))}
 	default:
 		// Return an error for unsupported types
 		return &Error{Message: fmt.Sprintf("argument to `length` not supported, got '%s'", args[0].GetType())}
@@ -277,6 +298,7 @@ func rangeFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //	typeof([1, 2, 3])    -> "array"
 //	typeof(range(1, 5))  -> "range"
 //	typeof(myFunc)       -> "func"
+//	typeof(new Dog())    -> "object" (see hierarchy for the struct)
 func typeofFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	// Check if exactly one argument is provided
 	if len(args) != 1 {
@@ -287,6 +309,29 @@ func typeofFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &String{Value: string(args[0].GetType())}
 }
 
+// hierarchyFunc returns the struct of a struct instance (or a struct itself)
+// with the structs it extends, in method resolution order, and the
+// interfaces it implements.
+//
+// Examples:
+//
+//	hierarchy(new Dog())  -> "Dog < Animal implements Pet"
+//	hierarchy(Point)      -> "Point"
+func hierarchyFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch obj := args[0].(type) {
+	case *GoMixObjectInstance:
+		if obj.Struct != nil {
+			return &String{Value: obj.Struct.Hierarchy()}
+		}
+	case *GoMixStruct:
+		return &String{Value: obj.Hierarchy()}
+	}
+	return createError("ERROR: argument to `hierarchy` must be a struct or an object, got '%s'", args[0].GetType())
+}
+
 // addrFunc returns the memory address of a GoMixObject as an integer.
 // It uses Go's pointer representation to extract the address of the underlying value.
 //
@@ -18,15 +18,15 @@ import (
 )
 
 var cryptoMethods = []*Builtin{
-	{Name: "md5", Callback: md5Func},
-	{Name: "sha1", Callback: sha1Func},
-	{Name: "sha256", Callback: sha256Func},
-	{Name: "base64_encode", Callback: base64Encode},
-	{Name: "base64_decode", Callback: base64Decode},
-	{Name: "hex_encode", Callback: hexEncode},
-	{Name: "hex_decode", Callback: hexDecode},
-	{Name: "uuid", Callback: uuidFunc},
-	{Name: "random", Callback: randomFunc},
+	{Name: "md5", Callback: md5Func, Signature: "md5(data, [\"bytes\"]) -> string"},
+	{Name: "sha1", Callback: sha1Func, Signature: "sha1(data, [\"bytes\"]) -> string"},
+	{Name: "sha256", Callback: sha256Func, Signature: "sha256(data, [\"bytes\"]) -> string"},
+	{Name: "base64_encode", Callback: base64Encode, Signature: "base64_encode(data) -> string"},
+	{Name: "base64_decode", Callback: base64Decode, Signature: "base64_decode(str, [\"bytes\"]) -> string"},
+	{Name: "hex_encode", Callback: hexEncode, Signature: "hex_encode(data) -> string"},
+	{Name: "hex_decode", Callback: hexDecode, Signature: "hex_decode(str, [\"bytes\"]) -> string"},
+	{Name: "uuid", Callback: uuidFunc, Signature: "uuid() -> string"},
+	{Name: "random", Callback: randomFunc, Signature: "random() -> float"},
 }
 
 func init() {
@@ -40,73 +40,114 @@ func init() {
 	RegisterPackage(cryptoPackage)
 }
 
+// md5Func returns the MD5 digest of a string or bytes, as hex, or as bytes
+// when asked.
+//
+// Syntax: md5(data, ["bytes"])
 func md5Func(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: md5 expects 1 argument (string)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: md5 expects 1 or 2 arguments (data, [\"bytes\"])")
 	}
-	data := args[0].ToString()
-	hash := md5.Sum([]byte(data))
-	return &String{Value: fmt.Sprintf("%x", hash)}
+	asBytes, errObj := ResultMode("md5", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	hash := md5.Sum(BytesOf(args[0]))
+	return digest(asBytes, hash[:])
 }
 
+// sha1Func returns the SHA-1 digest of a string or bytes, as hex, or as
+// bytes when asked.
+//
+// Syntax: sha1(data, ["bytes"])
 func sha1Func(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: sha1 expects 1 argument (string)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: sha1 expects 1 or 2 arguments (data, [\"bytes\"])")
 	}
-	data := args[0].ToString()
-	hash := sha1.Sum([]byte(data))
-	return &String{Value: fmt.Sprintf("%x", hash)}
+	asBytes, errObj := ResultMode("sha1", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	hash := sha1.Sum(BytesOf(args[0]))
+	return digest(asBytes, hash[:])
 }
 
+// sha256Func returns the SHA-256 digest of a string or bytes, as hex, or as
+// bytes when asked.
+//
+// Syntax: sha256(data, ["bytes"])
 func sha256Func(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: sha256 expects 1 argument (string)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: sha256 expects 1 or 2 arguments (data, [\"bytes\"])")
 	}
-	data := args[0].ToString()
-	hash := sha256.Sum256([]byte(data))
-	return &String{Value: fmt.Sprintf("%x", hash)}
+	asBytes, errObj := ResultMode("sha256", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	hash := sha256.Sum256(BytesOf(args[0]))
+	return digest(asBytes, hash[:])
+}
+
+// digest returns a digest as bytes, or as a hex string.
+func digest(asBytes bool, sum []byte) GoMixObject {
+	if asBytes {
+		return &Bytes{Value: sum}
+	}
+	return &String{Value: fmt.Sprintf("%x", sum)}
 }
 
 func base64Encode(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
-		return createError("ERROR: base64_encode expects 1 argument (string)")
+		return createError("ERROR: base64_encode expects 1 argument (string or bytes)")
 	}
-	data := args[0].ToString()
-	encoded := base64.StdEncoding.EncodeToString([]byte(data))
+	encoded := base64.StdEncoding.EncodeToString(BytesOf(args[0]))
 	return &String{Value: encoded}
 }
 
+// base64Decode decodes base64 into a string, or into bytes when asked.
+//
+// Syntax: base64_decode(str, ["bytes"])
 func base64Decode(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: base64_decode expects 1 argument (string)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: base64_decode expects 1 or 2 arguments (string, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("base64_decode", args, 1)
+	if errObj != nil {
+		return errObj
 	}
 	data := args[0].ToString()
 	decoded, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
 		return createError("ERROR: failed to decode base64: %v", err)
 	}
-	return &String{Value: string(decoded)}
+	return NewData(asBytes, decoded)
 }
 
 func hexEncode(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
-		return createError("ERROR: hex_encode expects 1 argument (string)")
+		return createError("ERROR: hex_encode expects 1 argument (string or bytes)")
 	}
-	data := args[0].ToString()
-	encoded := hex.EncodeToString([]byte(data))
+	encoded := hex.EncodeToString(BytesOf(args[0]))
 	return &String{Value: encoded}
 }
 
+// hexDecode decodes hex into a string, or into bytes when asked.
+//
+// Syntax: hex_decode(str, ["bytes"])
 func hexDecode(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: hex_decode expects 1 argument (string)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: hex_decode expects 1 or 2 arguments (string, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("hex_decode", args, 1)
+	if errObj != nil {
+		return errObj
 	}
 	data := args[0].ToString()
 	decoded, err := hex.DecodeString(data)
 	if err != nil {
 		return createError("ERROR: failed to decode hex: %v", err)
 	}
-	return &String{Value: string(decoded)}
+	return NewData(asBytes, decoded)
 }
 
 func uuidFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
@@ -125,9 +166,17 @@ func uuidFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &String{Value: fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])}
 }
 
+// randomFunc returns n cryptographically random bytes, as a string, or as
+// bytes when asked.
+//
+// Syntax: random(n, ["bytes"])
 func randomFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: random expects 1 argument (number of bytes)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: random expects 1 or 2 arguments (number of bytes, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("random", args, 1)
+	if errObj != nil {
+		return errObj
 	}
 	n, ok := args[0].(*Integer)
 	if !ok {
@@ -141,5 +190,5 @@ func randomFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if err != nil {
 		return createError("ERROR: failed to generate random bytes: %v", err)
 	}
-	return &String{Value: string(bytes)}
+	return NewData(asBytes, bytes)
 }
@@ -0,0 +1,435 @@
+/*
+File    : go-mix/std/csv.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - csv.go
+// This file implements the csv package: reading CSV text into arrays of rows
+// and writing rows as CSV text.
+//
+// Read rows are arrays of strings or, in header mode, maps from the names of
+// the header row to the fields (in the order of the columns). Written rows
+// are arrays of fields or maps, converted into plain data like JSON values
+// (see codec.go); a field is a string, a number, a bool or nil (written as an
+// empty field).
+package std
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var csvMethods = []*Builtin{
+	{Name: "parse_csv", Callback: csvParse, Signature: "parse_csv(text, [options]) -> array"},              // Reads CSV text into an array of rows
+	{Name: "read_csv", Callback: csvRead, Signature: "read_csv(path, [options]) -> array"},                 // Reads a CSV file into an array of rows
+	{Name: "stringify_csv", Callback: csvStringify, Signature: "stringify_csv(rows, [options]) -> string"}, // Writes rows as CSV text
+	{Name: "write_csv", Callback: csvWrite, Signature: "write_csv(path, rows, [options]) -> nil"},          // Writes rows into a CSV file
+}
+
+func init() {
+	csvPackage := &Package{
+		Name:      "csv",
+		Functions: make(map[string]*Builtin),
+	}
+	for _, method := range csvMethods {
+		csvPackage.Functions[method.Name] = method
+	}
+	RegisterPackage(csvPackage)
+}
+
+// csvReadOptions are the options of parse_csv and read_csv.
+var csvReadOptions = []string{"delimiter", "header", "comment", "trim_space", "lazy_quotes", "types"}
+
+// csvWriteOptions are the options of stringify_csv and write_csv.
+var csvWriteOptions = []string{"delimiter", "header", "quote_all", "crlf"}
+
+// csvParse reads CSV text into an array of rows. The options are:
+//   - "delimiter": the field separator (a char or a one-character string, default ',')
+//   - "header": true to read the first row as the names of the columns, and
+//     the other rows as maps from the names to the fields
+//   - "comment": a character starting the lines to ignore
+//   - "trim_space": true to ignore the leading spaces of fields
+//   - "lazy_quotes": true to accept quotes inside unquoted fields
+//   - "types": true to read fields that look like ints, floats or bools as
+//     such (by the rules of JSON numbers); the other fields stay strings
+//
+// Syntax: parse_csv(text, [options])
+//
+// Example:
+//
+//	parse_csv("a,b\n1,2\n")                       -> [["a", "b"], ["1", "2"]]
+//	parse_csv("a,b\n1,2\n", map{"header": true})  -> [map{a: "1", b: "2"}]
+func csvParse(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: parse_csv expects 1 or 2 arguments (text, [options])")
+	}
+	if args[0].GetType() != StringType && args[0].GetType() != BytesType {
+		return createError("ERROR: argument to `parse_csv` must be a string or bytes, got '%s'", args[0].GetType())
+	}
+	return decodeCSV("parse_csv", BytesOf(args[0]), "", args)
+}
+
+// csvRead reads a CSV file into an array of rows, with the options of
+// parse_csv.
+//
+// Syntax: read_csv(path, [options])
+func csvRead(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: read_csv expects 1 or 2 arguments (path, [options])")
+	}
+	path := args[0].ToString()
+	data, errObj := readCodecFile(rt, "read_csv", path)
+	if errObj != nil {
+		return errObj
+	}
+	return decodeCSV("read_csv", data, " of "+path, args)
+}
+
+// csvStringify writes rows (arrays of fields or maps) as CSV text. The rows
+// may be any iterable. The options are:
+//   - "delimiter": the field separator (default ',')
+//   - "header": false to leave out the header row written for rows that are
+//     maps (the keys of the first row), or an array of column names, written
+//     first (and giving the order of the fields of maps)
+//   - "quote_all": true to quote every field, not only those that need it
+//   - "crlf": true to end lines with \r\n
+//
+// Syntax: stringify_csv(rows, [options])
+//
+// Example:
+//
+//	stringify_csv([["a", "b"], [1, "x,y"]])       -> "a,b\n1,\"x,y\"\n"
+//	stringify_csv([map{"id": 1, "ok": true}])     -> "id,ok\n1,true\n"
+func csvStringify(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: stringify_csv expects 1 or 2 arguments (rows, [options])")
+	}
+	text, errObj := encodeCSV(rt, "stringify_csv", args[0], args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &String{Value: string(text)}
+}
+
+// csvWrite writes rows into a CSV file, with the options of stringify_csv.
+//
+// Syntax: write_csv(path, rows, [options])
+func csvWrite(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 && len(args) != 3 {
+		return createError("ERROR: write_csv expects 2 or 3 arguments (path, rows, [options])")
+	}
+	text, errObj := encodeCSV(rt, "write_csv", args[1], args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	return writeCodecFile(rt, "write_csv", args[0].ToString(), text)
+}
+
+// csvRune returns the character option key of a map of options, or def if
+// it is not set.
+func csvRune(name string, opts *Map, key string, def rune) (rune, GoMixObject) {
+	val, ok := opts.GetString(key)
+	if !ok {
+		return def, nil
+	}
+	var r rune
+	switch v := val.(type) {
+	case *Char:
+		r = v.Value
+	case *String:
+		if utf8.RuneCountInString(v.Value) == 1 {
+			r, _ = utf8.DecodeRuneInString(v.Value)
+		}
+	}
+	if r == 0 || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, createError("ERROR: option %q of `%s` must be a single character other than a quote or a line break, got '%s'", key, name, val.ToString())
+	}
+	return r, nil
+}
+
+// decodeCSV reads CSV text with the options given as the argument 1 of the
+// builtin name. where is appended to the positions of errors.
+func decodeCSV(name string, data []byte, where string, args []GoMixObject) GoMixObject {
+	opts, errObj := codecOptions(name, args, 1, csvReadOptions...)
+	if errObj != nil {
+		return errObj
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	if reader.Comma, errObj = csvRune(name, opts, "delimiter", ','); errObj != nil {
+		return errObj
+	}
+	if _, ok := opts.GetString("comment"); ok {
+		if reader.Comment, errObj = csvRune(name, opts, "comment", 0); errObj != nil {
+			return errObj
+		}
+	}
+	if reader.Comment == reader.Comma {
+		return createError("ERROR: options \"comment\" and \"delimiter\" of `%s` must differ", name)
+	}
+	if reader.TrimLeadingSpace, errObj = boolOption(name, opts, "trim_space", false); errObj != nil {
+		return errObj
+	}
+	if reader.LazyQuotes, errObj = boolOption(name, opts, "lazy_quotes", false); errObj != nil {
+		return errObj
+	}
+	header, errObj := boolOption(name, opts, "header", false)
+	if errObj != nil {
+		return errObj
+	}
+	types, errObj := boolOption(name, opts, "types", false)
+	if errObj != nil {
+		return errObj
+	}
+
+	rows := make([]GoMixObject, 0)
+	var columns []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return createError("ERROR: invalid CSV at line %d, column %d%s: %v", parseErr.Line, parseErr.Column, where, parseErr.Err)
+			}
+			return createError("ERROR: failed to read CSV%s: %v", where, err)
+		}
+		if header && columns == nil {
+			seen := make(map[string]bool)
+			for _, column := range record {
+				if seen[column] {
+					return createError("ERROR: duplicate column %q in the CSV header%s", column, where)
+				}
+				seen[column] = true
+			}
+			columns = record
+			continue
+		}
+		fields := make([]GoMixObject, len(record))
+		for i, field := range record {
+			fields[i] = csvField(field, types)
+		}
+		if !header {
+			rows = append(rows, &Array{Elements: fields})
+			continue
+		}
+		if len(fields) != len(columns) {
+			line, _ := reader.FieldPos(0)
+			return createError("ERROR: invalid CSV at line %d%s: the row has %d fields, the header has %d", line, where, len(fields), len(columns))
+		}
+		row := NewMap()
+		for i, column := range columns {
+			row.SetString(column, fields[i])
+		}
+		rows = append(rows, row)
+	}
+	return &Array{Elements: rows}
+}
+
+// csvNumber matches the fields read as numbers with the "types" option: JSON
+// numbers, with an optional leading +.
+var csvNumber = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// csvField returns a field that was read, converted to an int, a float or a
+// bool if types is set and it looks like one.
+func csvField(field string, types bool) GoMixObject {
+	if !types {
+		return &String{Value: field}
+	}
+	switch field {
+	case "true":
+		return &Boolean{Value: true}
+	case "false":
+		return &Boolean{Value: false}
+	}
+	if !csvNumber.MatchString(field) {
+		return &String{Value: field}
+	}
+	if !strings.ContainsAny(field, ".eE") {
+		if i, err := strconv.ParseInt(field, 10, 64); err == nil {
+			return &Integer{Value: i}
+		}
+		if b, ok := new(big.Int).SetString(strings.TrimPrefix(field, "+"), 10); ok {
+			return NewInteger(b)
+		}
+	}
+	if f, err := strconv.ParseFloat(field, 64); err == nil {
+		return &Float{Value: f}
+	}
+	return &String{Value: field}
+}
+
+// encodeCSV writes rows as CSV text with the options given as the argument
+// i of the builtin name.
+func encodeCSV(rt Runtime, name string, rows GoMixObject, args []GoMixObject, i int) ([]byte, GoMixObject) {
+	opts, errObj := codecOptions(name, args, i, csvWriteOptions...)
+	if errObj != nil {
+		return nil, errObj
+	}
+	enc := &csvEncoder{name: name, header: true}
+	if enc.delimiter, errObj = csvRune(name, opts, "delimiter", ','); errObj != nil {
+		return nil, errObj
+	}
+	if enc.quoteAll, errObj = boolOption(name, opts, "quote_all", false); errObj != nil {
+		return nil, errObj
+	}
+	crlf, errObj := boolOption(name, opts, "crlf", false)
+	if errObj != nil {
+		return nil, errObj
+	}
+	enc.newline = "\n"
+	if crlf {
+		enc.newline = "\r\n"
+	}
+	if val, ok := opts.GetString("header"); ok {
+		switch v := val.(type) {
+		case *Boolean:
+			enc.header = v.Value
+		case *Array:
+			enc.columns = make([]string, len(v.Elements))
+			for j, column := range v.Elements {
+				enc.columns[j] = column.ToString()
+			}
+		default:
+			return nil, createError("ERROR: option \"header\" of `%s` must be a bool or an array of column names, got '%s'", name, val.GetType())
+		}
+	}
+	if enc.columns != nil {
+		enc.record(enc.columns)
+	}
+
+	n := 0
+	iterErr := Iterate(rt, rows, func(key, value GoMixObject) bool {
+		n++
+		errObj = enc.row(rt, n, value)
+		return errObj == nil
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	if errObj != nil {
+		return nil, errObj
+	}
+	return enc.buf.Bytes(), nil
+}
+
+// csvEncoder writes the rows of stringify_csv and write_csv.
+type csvEncoder struct {
+	name      string
+	buf       bytes.Buffer
+	delimiter rune
+	quoteAll  bool
+	newline   string
+	header    bool     // Whether the header row of map rows is written
+	columns   []string // The columns of map rows (nil until known)
+}
+
+// row writes the row number n, an array of fields or a map.
+func (enc *csvEncoder) row(rt Runtime, n int, value GoMixObject) GoMixObject {
+	data, errObj := codecData(rt, "CSV", value)
+	if errObj != nil {
+		return errObj
+	}
+	var fields []GoMixObject
+	switch row := data.(type) {
+	case *Array:
+		fields = row.Elements
+	case *Map:
+		if enc.columns == nil {
+			enc.columns = make([]string, 0, row.Len())
+			for _, pair := range row.Entries() {
+				enc.columns = append(enc.columns, pair.Key.ToString())
+			}
+			if enc.header {
+				enc.record(enc.columns)
+			}
+		}
+		fields = make([]GoMixObject, len(enc.columns))
+		for i, column := range enc.columns {
+			if val, ok := row.GetString(column); ok {
+				fields[i] = val
+			} else {
+				fields[i] = &Nil{}
+			}
+		}
+		for _, pair := range row.Entries() {
+			if !isColumn(enc.columns, pair.Key.ToString()) {
+				return createError("ERROR: row %d of `%s` has the key %q, which is not a column", n, enc.name, pair.Key.ToString())
+			}
+		}
+	default:
+		return createError("ERROR: row %d of `%s` must be an array or a map, got '%s'", n, enc.name, value.GetType())
+	}
+	texts := make([]string, len(fields))
+	for i, field := range fields {
+		switch f := field.(type) {
+		case *Nil:
+			texts[i] = ""
+		case *String:
+			texts[i] = f.Value
+		case *Float:
+			if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+				texts[i] = strconv.FormatFloat(f.Value, 'g', -1, 64)
+			} else {
+				texts[i] = formatFloat(f.Value)
+			}
+		case *Boolean, *Integer, *BigInt, *Decimal:
+			texts[i] = f.ToString()
+		default:
+			return createError("ERROR: field %d of row %d of `%s` must be a string, a number, a bool or nil, got '%s'", i+1, n, enc.name, field.GetType())
+		}
+	}
+	enc.record(texts)
+	return nil
+}
+
+// record writes one line of fields, quoting those that need it.
+func (enc *csvEncoder) record(fields []string) {
+	for i, field := range fields {
+		if i > 0 {
+			enc.buf.WriteRune(enc.delimiter)
+		}
+		if !enc.quoteAll && !enc.needsQuotes(field, len(fields)) {
+			enc.buf.WriteString(field)
+			continue
+		}
+		enc.buf.WriteByte('"')
+		enc.buf.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		enc.buf.WriteByte('"')
+	}
+	enc.buf.WriteString(enc.newline)
+}
+
+// needsQuotes reports whether a field must be quoted to be read back as it is.
+func (enc *csvEncoder) needsQuotes(field string, count int) bool {
+	if field == "" {
+		// A line with a single empty field would be read as an empty line
+		return count == 1
+	}
+	if strings.ContainsRune(field, enc.delimiter) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return r == ' ' || r == '\t'
+}
+
+// isColumn reports whether name is one of the columns.
+func isColumn(columns []string, name string) bool {
+	for _, column := range columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
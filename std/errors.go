@@ -0,0 +1,191 @@
+/*
+File    : go-mix/std/errors.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - errors.go
+// This file defines the object handed to catch blocks in try/catch statements,
+// the execution limit errors and the tracebacks attached to runtime errors.
+// A raised *Error cannot be stored in a variable (the evaluator would keep
+// propagating it), so a caught error is converted into an instance of the
+// builtin Error struct whose fields can be inspected like any other object.
+package std
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrorStruct is the builtin struct type of caught errors.
+//
+// Instances carry the following fields:
+//   - message: The error message without the position and "ERROR: " prefixes
+//   - line:    The source line where the error was raised (0 if unknown)
+//   - column:  The source column where the error was raised (0 if unknown)
+//   - value:   The value passed to throw, or nil for runtime errors
+var ErrorStruct = &GoMixStruct{
+	Name:        "Error",
+	Fields:      []string{"message", "line", "column", "value"},
+	Methods:     make(map[string]FunctionInterface),
+	ClassFields: make(map[string]GoMixObject),
+	ConstFields: make(map[string]bool),
+	LetFields:   make(map[string]bool),
+	LetTypes:    make(map[string]GoMixType),
+}
+
+// ErrorText returns the message of an error without its "[line:col] " and
+// "ERROR: " prefixes.
+//
+// Example:
+//
+//	(&Error{Message: "[3:7] ERROR: division by zero", Line: 3, Column: 7}).ErrorText()
+//	-> "division by zero"
+func (e *Error) ErrorText() string {
+	msg := e.Message
+	if e.Line > 0 {
+		msg = strings.TrimPrefix(msg, fmt.Sprintf("[%d:%d] ", e.Line, e.Column))
+	}
+	return strings.TrimPrefix(msg, "ERROR: ")
+}
+
+// NewErrorInstance converts a raised error into an inspectable Error instance.
+// This is the value bound to the parameter of a catch clause.
+func NewErrorInstance(err *Error) *GoMixObjectInstance {
+	inst := NewStructInstance(ErrorStruct)
+	inst.InstanceFields["message"] = &String{Value: err.ErrorText()}
+	inst.InstanceFields["line"] = &Integer{Value: int64(err.Line)}
+	inst.InstanceFields["column"] = &Integer{Value: int64(err.Column)}
+	if err.Value != nil {
+		inst.InstanceFields["value"] = err.Value
+	} else {
+		inst.InstanceFields["value"] = &Nil{}
+	}
+	return inst
+}
+
+// ErrorFromInstance converts a caught Error instance back into a raised error.
+// It is used to rethrow a caught error (throw err;) with its original position.
+// Returns false if obj is not an instance of ErrorStruct.
+func ErrorFromInstance(obj GoMixObject) (*Error, bool) {
+	inst, ok := obj.(*GoMixObjectInstance)
+	if !ok || inst.Struct != ErrorStruct {
+		return nil, false
+	}
+	err := &Error{}
+	if line, ok := inst.InstanceFields["line"].(*Integer); ok {
+		err.Line = int(line.Value)
+	}
+	if col, ok := inst.InstanceFields["column"].(*Integer); ok {
+		err.Column = int(col.Value)
+	}
+	if val, ok := inst.InstanceFields["value"]; ok && val.GetType() != NilType {
+		err.Value = val
+	}
+	msg := ""
+	if m, ok := inst.InstanceFields["message"]; ok {
+		msg = m.ToString()
+	}
+	err.Message = fmt.Sprintf("ERROR: %s", msg)
+	if err.Line > 0 {
+		err.Message = fmt.Sprintf("[%d:%d] %s", err.Line, err.Column, err.Message)
+	}
+	return err, true
+}
+
+// Execution limits reported by LimitError.
+const (
+	LimitSteps     = "steps"      // Maximum number of evaluation steps
+	LimitCallDepth = "call depth" // Maximum depth of nested function calls
+	LimitTimeout   = "timeout"    // Wall-clock time allowed for a program
+	LimitAlloc     = "allocation" // Approximate allocation budget
+	LimitCanceled  = "canceled"   // The host canceled the evaluation's context
+)
+
+// LimitError is raised when a program exceeds one of the evaluator's
+// execution limits or its context is canceled.
+//
+// It is an error like any other for the code that propagates it (its type is
+// ErrorType), but it is a distinct Go type so that try/catch lets it through
+// and hosts can tell it apart from the script's own errors.
+type LimitError struct {
+	Error
+	Limit string // The limit that stopped the program (one of the Limit* constants)
+}
+
+// Frame is one entry of the traceback of an error: a function (or module)
+// that was running when the error was raised, and the line it had reached.
+type Frame struct {
+	Function string // Function name, "Struct.method", "<module>" or "<main>"
+	File     string // Source file of the function ("" for code not read from a file)
+	Line     int    // Line being executed in the function (0 if unknown)
+}
+
+// String formats the frame as "name (file:line)".
+func (f Frame) String() string {
+	if f.File == "" {
+		return fmt.Sprintf("%s (line %d)", f.Function, f.Line)
+	}
+	return fmt.Sprintf("%s (%s:%d)", f.Function, DisplayPath(f.File), f.Line)
+}
+
+// DisplayPath returns file relative to the working directory if it is inside
+// it, or file itself otherwise, as error messages and tracebacks show it.
+func DisplayPath(file string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return file
+}
+
+// maxTraceFrames is the number of innermost frames printed by Traceback;
+// deeper stacks are elided down to the program frame. Runs of identical
+// frames (e.g., runaway recursion) are collapsed first.
+const maxTraceFrames = 20
+
+// Traceback formats the call stack recorded on an error (an *Error or a
+// *LimitError), one frame per line with the innermost call first. It returns
+// "" if obj is not an error or has no call stack.
+//
+// Example output:
+//
+//	Traceback (most recent call first):
+//	  at Account.withdraw (bank.gm:12)
+//	  at transfer (bank.gm:30)
+//	  at <main> (bank.gm:41)
+func Traceback(obj GoMixObject) string {
+	var trace []Frame
+	switch err := obj.(type) {
+	case *Error:
+		trace = err.Trace
+	case *LimitError:
+		trace = err.Trace
+	}
+	if len(trace) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(trace))
+	for i := 0; i < len(trace); {
+		run := 1
+		for i+run < len(trace) && trace[i+run] == trace[i] {
+			run++
+		}
+		for j := 0; j < run && j < 3; j++ {
+			lines = append(lines, "  at "+trace[i].String())
+		}
+		if run > 3 {
+			lines = append(lines, fmt.Sprintf("  ... previous frame repeated %d more times", run-3))
+		}
+		i += run
+	}
+	if len(lines) > maxTraceFrames+1 {
+		omitted := len(lines) - maxTraceFrames - 1
+		lines = append(append(lines[:maxTraceFrames:maxTraceFrames], fmt.Sprintf("  ... %d more lines ...", omitted)), lines[len(lines)-1])
+	}
+	return "Traceback (most recent call first):\n" + strings.Join(lines, "\n") + "\n"
+}
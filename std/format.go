@@ -6,20 +6,25 @@ Contact : akashmaji(@iisc.ac.in)
 
 // Package std - format.go
 // This file defines the type conversion builtin functions for the Go-Mix language.
-// It provides functions for converting between integers, floats, booleans, and strings.
+// It provides functions for converting between integers, floats, booleans, and strings,
+// and to the arbitrary-precision bigints and decimals (see bignum.go).
 package std
 
 import (
 	"io"
+	"math"
+	"math/big"
 	"strconv"
 )
 
 var formatMethods = []*Builtin{
-	{Name: "to_int", Callback: toInt},     // Converts a value to an integer
-	{Name: "to_float", Callback: toFloat}, // Converts a value to a float
-	{Name: "to_bool", Callback: toBool},   // Converts a value to a boolean
-	{Name: "to_str", Callback: toString},  // Converts a value to a string
-	{Name: "to_char", Callback: toChar},   // Converts a value to a character
+	{Name: "to_int", Callback: toInt, Signature: "to_int(value) -> int"},                     // Converts a value to an integer
+	{Name: "to_float", Callback: toFloat, Signature: "to_float(value) -> float"},             // Converts a value to a float
+	{Name: "to_bool", Callback: toBool, Signature: "to_bool(value) -> bool"},                 // Converts a value to a boolean
+	{Name: "to_str", Callback: toString, Signature: "to_str(value) -> string"},               // Converts a value to a string
+	{Name: "to_char", Callback: toChar, Signature: "to_char(value) -> char"},                 // Converts a value to a character
+	{Name: "bigint", Callback: bigintFunc, Signature: "bigint(value) -> bigint"},             // Converts a value to a bigint
+	{Name: "decimal", Callback: decimalFunc, Signature: "decimal(value, scale?) -> decimal"}, // Converts a value to a decimal
 }
 
 // init registers the format methods as global builtins and as a package for import.
@@ -44,16 +49,20 @@ func init() {
 //
 // Usage:
 //   - If value is an integer, returns it as is.
-//   - If value is a float, truncates it to an integer.
+//   - If value is a float or a decimal, truncates it to an integer.
+//   - If value is a bigint, returns it as an int.
 //   - If value is a boolean, returns 1 for true and 0 for false.
 //   - If value is a string, parses it as an integer (supports 0x and 0 prefixes).
 //   - If value is a character, returns its Unicode code point.
 //
+// A bigint or decimal too large for an int is an error.
+//
 // Example:
 //
 //	to_int("123"); // Returns 123
 //	to_int(3.14);  // Returns 3
 //	to_int(true);  // Returns 1
+//	to_int(10n);   // Returns 10
 func toInt(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
 		return createError("ERROR: to_int expects 1 argument, got %d", len(args))
@@ -78,6 +87,17 @@ func toInt(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return &Integer{Value: val}
 	case CharType:
 		return &Integer{Value: int64(arg.(*Char).Value)}
+	case BigIntType:
+		if !arg.(*BigInt).Value.IsInt64() {
+			return createError("ERROR: bigint %s is too large for an int", arg.ToString())
+		}
+		return &Integer{Value: arg.(*BigInt).Value.Int64()}
+	case DecimalType:
+		value := arg.(*Decimal).Trunc()
+		if !value.IsInt64() {
+			return createError("ERROR: decimal %s is too large for an int", arg.ToString())
+		}
+		return &Integer{Value: value.Int64()}
 	default:
 		return createError("ERROR: cannot convert %s to int", arg.GetType())
 	}
@@ -90,14 +110,16 @@ func toInt(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 // Usage:
 //   - If value is a float, returns it as is.
 //   - If value is an integer, converts it to a float.
+//   - If value is a bigint or a decimal, returns the nearest float.
 //   - If value is a boolean, returns 1.0 for true and 0.0 for false.
 //   - If value is a string, parses it as a float.
 //
 // Example:
 //
-//	to_float("3.14"); // Returns 3.14
-//	to_float(123);    // Returns 123.0
-//	to_float(false);  // Returns 0.0
+//	to_float("3.14");           // Returns 3.14
+//	to_float(123);              // Returns 123.0
+//	to_float(false);            // Returns 0.0
+//	to_float(decimal("19.99")); // Returns 19.99
 func toFloat(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
 		return createError("ERROR: to_float expects 1 argument, got %d", len(args))
@@ -109,6 +131,9 @@ func toFloat(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return arg
 	case IntegerType:
 		return &Float{Value: float64(arg.(*Integer).Value)}
+	case BigIntType, DecimalType:
+		value, _ := ToFloat64(arg)
+		return &Float{Value: value}
 	case BooleanType:
 		if arg.(*Boolean).Value {
 			return &Float{Value: 1.0}
@@ -133,6 +158,7 @@ func toFloat(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //   - If value is a boolean, returns it as is.
 //   - If value is an integer, returns false for 0 and true otherwise.
 //   - If value is a float, returns false for 0.0 and true otherwise.
+//   - If value is a bigint or a decimal, returns false for 0 and true otherwise.
 //   - If value is a string, parses it using standard boolean rules (e.g., "true", "false", "1", "0").
 //   - If value is nil, returns false.
 //
@@ -154,6 +180,10 @@ func toBool(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return &Boolean{Value: arg.(*Integer).Value != 0}
 	case FloatType:
 		return &Boolean{Value: arg.(*Float).Value != 0.0}
+	case BigIntType:
+		return &Boolean{Value: arg.(*BigInt).Value.Sign() != 0}
+	case DecimalType:
+		return &Boolean{Value: arg.(*Decimal).Unscaled.Sign() != 0}
 	case StringType:
 		val, err := strconv.ParseBool(arg.ToString())
 		if err != nil {
@@ -219,3 +249,106 @@ func toChar(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: cannot convert %s to char", arg.GetType())
 	}
 }
+
+// bigintFunc converts a value to a bigint.
+//
+// Syntax: bigint(value)
+//
+// Usage:
+//   - If value is a bigint, returns it as is.
+//   - If value is an integer, converts it to a bigint.
+//   - If value is a float or a decimal, truncates it to an integer.
+//   - If value is a string, parses it as an integer of any size (supports
+//     0x, 0o and 0b prefixes and _ separators).
+//
+// Example:
+//
+//	bigint("123456789012345678901234567890"); // Returns 123456789012345678901234567890
+//	bigint(42);                               // Returns 42
+//	bigint(2.5e20);                           // Returns 250000000000000000000
+func bigintFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: bigint expects 1 argument, got %d", len(args))
+	}
+
+	arg := args[0]
+	switch arg.GetType() {
+	case BigIntType:
+		return arg
+	case IntegerType:
+		return NewBigInt(arg.(*Integer).Value)
+	case FloatType:
+		value := arg.(*Float).Value
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return createError("ERROR: cannot convert %v to bigint", value)
+		}
+		integer, _ := big.NewFloat(value).Int(nil)
+		return &BigInt{Value: integer}
+	case DecimalType:
+		return &BigInt{Value: arg.(*Decimal).Trunc()}
+	case StringType:
+		value, ok := new(big.Int).SetString(arg.ToString(), 0)
+		if !ok {
+			return createError("ERROR: could not convert string to bigint: %q", arg.ToString())
+		}
+		return &BigInt{Value: value}
+	default:
+		return createError("ERROR: cannot convert %s to bigint", arg.GetType())
+	}
+}
+
+// decimalFunc converts a value to a decimal.
+//
+// Syntax: decimal(value) or decimal(value, scale)
+//
+// Usage:
+//   - If value is a decimal, returns it as is.
+//   - If value is an integer or a bigint, converts it to a decimal of scale 0.
+//   - If value is a string, parses it as a decimal number; its scale is the
+//     number of digits after the decimal point ("19.90" has scale 2).
+//   - If value is a float, converts the shortest decimal that reads back as
+//     the same float (0.1 becomes 0.1).
+//
+// With a scale, the result is rounded half away from zero (or padded with
+// zeros) to scale digits after the decimal point.
+//
+// Example:
+//
+//	decimal("19.99");  // Returns 19.99
+//	decimal(5, 2);     // Returns 5.00
+//	decimal(2.675, 2); // Returns 2.68
+func decimalFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: decimal expects 1 or 2 arguments (value, scale), got %d", len(args))
+	}
+
+	var value *Decimal
+	arg := args[0]
+	switch arg.GetType() {
+	case DecimalType:
+		value = arg.(*Decimal)
+	case IntegerType, BigIntType:
+		value, _ = toDecimal(arg)
+	case FloatType:
+		var ok bool
+		if value, ok = FloatToDecimal(arg.(*Float).Value); !ok {
+			return createError("ERROR: cannot convert %v to decimal", arg.(*Float).Value)
+		}
+	case StringType:
+		var ok bool
+		if value, ok = ParseDecimal(arg.ToString()); !ok {
+			return createError("ERROR: could not convert string to decimal: %q", arg.ToString())
+		}
+	default:
+		return createError("ERROR: cannot convert %s to decimal", arg.GetType())
+	}
+
+	if len(args) == 2 {
+		scale, ok := args[1].(*Integer)
+		if !ok || scale.Value < 0 || scale.Value > MaxDecimalScale {
+			return createError("ERROR: scale of a decimal must be an int from 0 to %d, got %s", MaxDecimalScale, args[1].ToString())
+		}
+		value = value.Rescale(int(scale.Value))
+	}
+	return value
+}
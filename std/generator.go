@@ -0,0 +1,89 @@
+/*
+File    : go-mix/std/generator.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - generator.go
+// This file defines generators: lazy sequences whose values are computed one
+// at a time, as foreach (or a builtin walking an iterable) asks for them.
+// Calling a generator function (a function whose body has a yield statement)
+// returns one, as do the combinators of the lazy package (see lazy.go).
+package std
+
+// Generator is a lazy sequence of values.
+//
+// A generator is its own iterator: it can be walked once, and a walk that
+// stops early (e.g., with break) leaves it where it stopped, so that the next
+// walk continues with the following values. Close ends it early, for code
+// that knows nothing else can walk it (such as a foreach over gen()).
+type Generator struct {
+	Name  string                                    // What the generator comes from (e.g., "squares" or "lazy.take")
+	next  func(rt Runtime) (value, err GoMixObject) // Computes the next value (nil once exhausted)
+	stop  func()                                    // Releases what computes the values when closed early (may be nil)
+	done  bool                                      // Whether the sequence is exhausted (or failed, or closed)
+	index int64                                     // Number of values produced so far
+}
+
+// NewGenerator creates a generator whose values are computed by next.
+//
+// Parameters:
+//   - name: What the generator comes from, shown when it is printed
+//   - next: Returns the next value, or nil when the sequence is exhausted;
+//     a non-nil error ends the sequence
+func NewGenerator(name string, next func(rt Runtime) (value, err GoMixObject)) *Generator {
+	return &Generator{Name: name, next: next}
+}
+
+// OnClose sets the function Close calls when the generator is closed before
+// it is exhausted (e.g., to end the goroutine running a generator function).
+func (g *Generator) OnClose(stop func()) {
+	g.stop = stop
+}
+
+// Close ends the generator: it is exhausted from now on. If it was not
+// exhausted yet, the function set with OnClose is called.
+func (g *Generator) Close() {
+	if g.done {
+		return
+	}
+	g.done = true
+	if g.stop != nil {
+		g.stop()
+	}
+}
+
+// GetType returns the type of the Generator object
+func (g *Generator) GetType() GoMixType {
+	return GeneratorType
+}
+
+// ToString returns a description of the generator (e.g., "generator squares")
+func (g *Generator) ToString() string {
+	return "generator " + g.Name
+}
+
+// ToObject returns a description of the generator (e.g., "<generator squares>")
+func (g *Generator) ToObject() string {
+	return "<" + g.ToString() + ">"
+}
+
+// Iter implements Iterable: a generator is its own iterator.
+func (g *Generator) Iter() Iterator {
+	return g
+}
+
+// Next implements Iterator: the keys are the positions of the values in the
+// sequence.
+func (g *Generator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	if g.done {
+		return nil, nil, nil
+	}
+	value, err := g.next(rt)
+	if err != nil || value == nil {
+		g.done = true
+		return nil, nil, err
+	}
+	g.index++
+	return &Integer{Value: g.index - 1}, value, nil
+}
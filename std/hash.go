@@ -0,0 +1,131 @@
+/*
+File    : go-mix/std/hash.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - hash.go
+// This file implements the hashing of map keys and set elements. A key is
+// stored under its HashKey, which keeps the type of the key: 1 and "1" are
+// different keys, and so are 1 and 1.0.
+//
+// Hashable values:
+//   - ints, floats, chars, strings, bytes, bools and nil
+//   - bigints and decimals (a decimal is the same key at any scale: 1.5
+//     and 1.50 are one key)
+//   - enum types (the members of an enum are ints)
+//   - tuples of hashable values
+//   - instances of structs that declare __hash__(), which returns a hashable
+//     value: two instances of a struct are the same key when their
+//     __hash__ values are equal
+package std
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HashKey identifies a map key or set element: two values are the same key
+// exactly when their HashKeys are equal.
+type HashKey struct {
+	Type  GoMixType // The type of the value (the struct name for instances)
+	Value string    // The value, written so that it is unique within its type
+}
+
+// Hashable is implemented by the values that hash without calling any
+// Go-Mix code.
+type Hashable interface {
+	Hash() HashKey
+}
+
+// Hash returns the HashKey of an Integer.
+func (i *Integer) Hash() HashKey {
+	return HashKey{Type: IntegerType, Value: strconv.FormatInt(i.Value, 10)}
+}
+
+// Hash returns the HashKey of a Float.
+func (f *Float) Hash() HashKey {
+	return HashKey{Type: FloatType, Value: strconv.FormatFloat(f.Value, 'g', -1, 64)}
+}
+
+// Hash returns the HashKey of a Char.
+func (c *Char) Hash() HashKey {
+	return HashKey{Type: CharType, Value: string(c.Value)}
+}
+
+// Hash returns the HashKey of a String.
+func (s *String) Hash() HashKey {
+	return StringKey(s.Value)
+}
+
+// Hash returns the HashKey of a Boolean.
+func (b *Boolean) Hash() HashKey {
+	return HashKey{Type: BooleanType, Value: strconv.FormatBool(b.Value)}
+}
+
+// Hash returns the HashKey of nil.
+func (n *Nil) Hash() HashKey {
+	return HashKey{Type: NilType}
+}
+
+// Hash returns the HashKey of an enum type.
+func (e *GoMixEnum) Hash() HashKey {
+	return HashKey{Type: EnumType, Value: e.Name}
+}
+
+// StringKey returns the HashKey of a string, such as a field name used as
+// a map key.
+func StringKey(s string) HashKey {
+	return HashKey{Type: StringType, Value: s}
+}
+
+// encode writes a HashKey as a string that is unique among all HashKeys.
+func (k HashKey) encode() string {
+	return fmt.Sprintf("%s:%d:%s", k.Type, len(k.Value), k.Value)
+}
+
+// Hash returns the HashKey of a value used as a map key or set element.
+//
+// Parameters:
+//   - rt: The runtime calling the __hash__ methods of struct instances
+//   - obj: The key
+//
+// Returns:
+//   - The HashKey of obj
+//   - nil, or an Error if obj (or an element of it) is not hashable or a
+//     __hash__ method failed
+func Hash(rt Runtime, obj GoMixObject) (HashKey, GoMixObject) {
+	switch obj := obj.(type) {
+	case Hashable:
+		return obj.Hash(), nil
+	case *Tuple:
+		var value strings.Builder
+		for _, elem := range obj.Elements {
+			key, err := Hash(rt, elem)
+			if err != nil {
+				return HashKey{}, err
+			}
+			value.WriteString(key.encode())
+		}
+		return HashKey{Type: TupleType, Value: value.String()}, nil
+	case *GoMixObjectInstance:
+		name := obj.Struct.GetName()
+		res, ok := CallProtocol(rt, obj, "__hash__")
+		if !ok {
+			return HashKey{}, createError("ERROR: unhashable type '%s': struct (%s) does not declare __hash__", name, name)
+		}
+		if res.GetType() == ErrorType {
+			return HashKey{}, res
+		}
+		if _, ok := res.(*GoMixObjectInstance); ok {
+			return HashKey{}, createError("ERROR: __hash__ of struct (%s) must return a hashable value, got %s", name, res.GetType())
+		}
+		key, err := Hash(rt, res)
+		if err != nil {
+			return HashKey{}, err
+		}
+		return HashKey{Type: GoMixType(name), Value: key.encode()}, nil
+	}
+	return HashKey{}, createError("ERROR: unhashable type '%s': map keys and set elements must be ints, floats, bigints, decimals, chars, strings, bytes, bools, nil, enums, tuples or instances with __hash__", obj.GetType())
+}
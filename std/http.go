@@ -9,6 +9,7 @@ Contact : akashmaji(@iisc.ac.in)
 package std
 
 import (
+	"bytes"
 	"io"
 	"net/http"
 	"net/url"
@@ -17,19 +18,19 @@ import (
 )
 
 var httpMethods = []*Builtin{
-	{Name: "get_http", Callback: httpGet},           // Performs an HTTP GET request
-	{Name: "post_http", Callback: httpPost},         // Performs an HTTP POST request
-	{Name: "put_http", Callback: httpPut},           // Performs an HTTP PUT request
-	{Name: "delete_http", Callback: httpDelete},     // Performs an HTTP DELETE request
-	{Name: "listen_http", Callback: listenHttp},     // Starts an HTTP server
-	{Name: "create_server", Callback: createServer}, // Creates a new HTTP server
-	{Name: "handle_server", Callback: handleServer}, // Registers a handler for a server
-	{Name: "start_server", Callback: startServer},   // Starts the HTTP server
-	{Name: "request_http", Callback: httpRequest},   // Performs a generic HTTP request
-	{Name: "serve_static", Callback: serveStatic},   // Serves static files from a directory
-	{Name: "url_encode", Callback: urlEncode},       // URL encodes a string
-	{Name: "url_decode", Callback: urlDecode},       // URL decodes a string
-	{Name: "download_file", Callback: downloadFile}, // Downloads a file from a URL
+	{Name: "get_http", Callback: httpGet, Signature: "get_http(url, [\"bytes\"]) -> string"},                                     // Performs an HTTP GET request
+	{Name: "post_http", Callback: httpPost, Signature: "post_http(url, content_type, body, [\"bytes\"]) -> string"},              // Performs an HTTP POST request
+	{Name: "put_http", Callback: httpPut, Signature: "put_http(url, content_type, body, [\"bytes\"]) -> string"},                 // Performs an HTTP PUT request
+	{Name: "delete_http", Callback: httpDelete, Signature: "delete_http(url, [\"bytes\"]) -> string"},                            // Performs an HTTP DELETE request
+	{Name: "listen_http", Callback: listenHttp, Signature: "listen_http(address, handler) -> nil"},                               // Starts an HTTP server
+	{Name: "create_server", Callback: createServer, Signature: "create_server() -> server"},                                      // Creates a new HTTP server
+	{Name: "handle_server", Callback: handleServer, Signature: "handle_server(server, path, handler) -> nil"},                    // Registers a handler for a server
+	{Name: "start_server", Callback: startServer, Signature: "start_server(server, address) -> nil"},                             // Starts the HTTP server
+	{Name: "request_http", Callback: httpRequest, Signature: "request_http(method, url, [headers], [body], [\"bytes\"]) -> map"}, // Performs a generic HTTP request
+	{Name: "serve_static", Callback: serveStatic, Signature: "serve_static(server, prefix, root_path) -> nil"},                   // Serves static files from a directory
+	{Name: "url_encode", Callback: urlEncode, Signature: "url_encode(str) -> string"},                                            // URL encodes a string
+	{Name: "url_decode", Callback: urlDecode, Signature: "url_decode(str) -> string"},                                            // URL decodes a string
+	{Name: "download_file", Callback: downloadFile, Signature: "download_file(url, path) -> nil"},                                // Downloads a file from a URL
 }
 
 func init() {
@@ -63,11 +64,18 @@ func (s *Server) ToObject() string {
 }
 
 // httpGet performs a GET request to the specified URL.
-// Returns the response body as a string.
-// Syntax: get_http(url)
+// Returns the response body as a string, or as bytes when asked.
+// Syntax: get_http(url, ["bytes"])
 func httpGet(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: get_http expects 1 argument (url)")
+	if err := checkCapability(rt, "get_http", CapNetwork, ""); err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: get_http expects 1 or 2 arguments (url, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("get_http", args, 1)
+	if errObj != nil {
+		return errObj
 	}
 	url := args[0].ToString()
 
@@ -82,22 +90,30 @@ func httpGet(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: failed to read response body: %v", err)
 	}
 
-	return &String{Value: string(body)}
+	return NewData(asBytes, body)
 }
 
 // httpRequest performs a generic HTTP request with custom headers and body.
-// Returns a map containing status, headers, and body.
-// Syntax: request_http(method, url, [headers], [body])
+// Returns a map containing status, headers, and body (a string, or bytes
+// when asked).
+// Syntax: request_http(method, url, [headers], [body], ["bytes"])
 func httpRequest(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) < 2 || len(args) > 4 {
-		return createError("ERROR: request_http expects 2 to 4 arguments (method, url, [headers], [body])")
+	if err := checkCapability(rt, "request_http", CapNetwork, ""); err != nil {
+		return err
+	}
+	if len(args) < 2 || len(args) > 5 {
+		return createError("ERROR: request_http expects 2 to 5 arguments (method, url, [headers], [body], [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("request_http", args, 4)
+	if errObj != nil {
+		return errObj
 	}
 	method := strings.ToUpper(args[0].ToString())
 	urlStr := args[1].ToString()
 
 	var bodyReader io.Reader
-	if len(args) == 4 {
-		bodyReader = strings.NewReader(args[3].ToString())
+	if len(args) >= 4 && args[3].GetType() != NilType {
+		bodyReader = bytes.NewReader(BytesOf(args[3]))
 	}
 
 	req, err := http.NewRequest(method, urlStr, bodyReader)
@@ -110,8 +126,8 @@ func httpRequest(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 			return createError("ERROR: headers argument must be a map")
 		}
 		headers := args[2].(*Map)
-		for _, k := range headers.Keys {
-			req.Header.Set(k, headers.Pairs[k].ToString())
+		for _, pair := range headers.Entries() {
+			req.Header.Set(pair.Key.ToString(), pair.Value.ToString())
 		}
 	}
 
@@ -128,26 +144,15 @@ func httpRequest(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	}
 
 	// Construct response map
-	respMap := &Map{
-		Pairs: make(map[string]GoMixObject),
-		Keys:  []string{},
-	}
-	addKV := func(k string, v GoMixObject) {
-		respMap.Pairs[k] = v
-		respMap.Keys = append(respMap.Keys, k)
-	}
+	respMap := NewMap()
+	addKV := respMap.SetString
 
 	addKV("status", &Integer{Value: int64(resp.StatusCode)})
-	addKV("body", &String{Value: string(respBody)})
+	addKV("body", NewData(asBytes, respBody))
 
-	headersMap := &Map{
-		Pairs: make(map[string]GoMixObject),
-		Keys:  []string{},
-	}
+	headersMap := NewMap()
 	for k, v := range resp.Header {
-		val := &String{Value: strings.Join(v, ", ")}
-		headersMap.Pairs[k] = val
-		headersMap.Keys = append(headersMap.Keys, k)
+		headersMap.SetString(k, &String{Value: strings.Join(v, ", ")})
 	}
 	addKV("headers", headersMap)
 
@@ -167,6 +172,9 @@ func serveStatic(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	server := args[0].(*Server)
 	prefix := args[1].ToString()
 	root := args[2].ToString()
+	if err := checkCapability(rt, "serve_static", CapFSRead, root); err != nil {
+		return err
+	}
 
 	fs := http.FileServer(http.Dir(root))
 	server.Mux.Handle(prefix, http.StripPrefix(prefix, fs))
@@ -201,11 +209,17 @@ func urlDecode(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 // Syntax: download_file(url, path)
 // Example: download_file("https://example.com/file.txt", "local_file.txt")
 func downloadFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "download_file", CapNetwork, ""); err != nil {
+		return err
+	}
 	if len(args) != 2 {
 		return createError("ERROR: download_file expects 2 arguments (url, path)")
 	}
 	urlStr := args[0].ToString()
 	path := args[1].ToString()
+	if err := checkCapability(rt, "download_file", CapFSWrite, path); err != nil {
+		return err
+	}
 
 	resp, err := http.Get(urlStr)
 	if err != nil {
@@ -230,6 +244,9 @@ func downloadFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 // It blocks execution.
 // Syntax: listen_http(address, handler_function)
 func listenHttp(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "listen_http", CapNetwork, ""); err != nil {
+		return err
+	}
 	if len(args) != 2 {
 		return createError("ERROR: listen_http expects 2 arguments (address, handler)")
 	}
@@ -243,7 +260,9 @@ func listenHttp(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", createHttpHandler(rt, handler))
 
-	err := http.ListenAndServe(address, mux)
+	// handlers run as goroutines in turn with the rest of the program
+	var err error
+	rt.Blocking(func() { err = http.ListenAndServe(address, mux) })
 	if err != nil {
 		return createError("ERROR: listen_http failed: %v", err)
 	}
@@ -289,6 +308,9 @@ func handleServer(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 // handle_server(srv, "/hello", func(req) { return "Hello, World!" });
 // start_server(srv, ":8081");
 func startServer(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "start_server", CapNetwork, ""); err != nil {
+		return err
+	}
 	if len(args) != 2 {
 		return createError("ERROR: start_server expects 2 arguments (server, address)")
 	}
@@ -298,7 +320,9 @@ func startServer(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	server := args[0].(*Server)
 	address := args[1].ToString()
 
-	err := http.ListenAndServe(address, server.Mux)
+	// handlers run as goroutines in turn with the rest of the program
+	var err error
+	rt.Blocking(func() { err = http.ListenAndServe(address, server.Mux) })
 	if err != nil {
 		return createError("ERROR: start_server failed: %v", err)
 	}
@@ -310,16 +334,10 @@ func startServer(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 func createHttpHandler(rt Runtime, handler GoMixObject) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Construct the request map
-		reqMap := &Map{
-			Pairs: make(map[string]GoMixObject),
-			Keys:  []string{},
-		}
+		reqMap := NewMap()
 
 		// Helper to add key-value to map
-		addKV := func(k string, v GoMixObject) {
-			reqMap.Pairs[k] = v
-			reqMap.Keys = append(reqMap.Keys, k)
-		}
+		addKV := reqMap.SetString
 
 		addKV("method", &String{Value: r.Method})
 		addKV("url", &String{Value: r.URL.String()})
@@ -328,14 +346,9 @@ func createHttpHandler(rt Runtime, handler GoMixObject) http.HandlerFunc {
 		addKV("protocol", &String{Value: r.Proto})
 
 		// Headers
-		headersMap := &Map{
-			Pairs: make(map[string]GoMixObject),
-			Keys:  []string{},
-		}
+		headersMap := NewMap()
 		for k, v := range r.Header {
-			val := &String{Value: strings.Join(v, ", ")}
-			headersMap.Pairs[k] = val
-			headersMap.Keys = append(headersMap.Keys, k)
+			headersMap.SetString(k, &String{Value: strings.Join(v, ", ")})
 		}
 		addKV("headers", headersMap)
 
@@ -343,8 +356,10 @@ func createHttpHandler(rt Runtime, handler GoMixObject) http.HandlerFunc {
 		bodyBytes, _ := io.ReadAll(r.Body)
 		addKV("body", &String{Value: string(bodyBytes)})
 
-		// Call the Go-Mix handler
-		result := rt.CallFunction(handler, reqMap)
+		// Call the Go-Mix handler (as a goroutine of the program, see sync.go)
+		result := rt.Attach(func(rt Runtime) GoMixObject {
+			return rt.CallFunction(handler, reqMap)
+		})
 
 		// Handle the response
 		if result.GetType() == ErrorType {
@@ -353,48 +368,56 @@ func createHttpHandler(rt Runtime, handler GoMixObject) http.HandlerFunc {
 		}
 
 		statusCode := 200
-		responseBody := ""
+		var responseBody []byte
 
 		// Check if result is a map (structured response) or just a string/other (body)
 		if resMap, ok := result.(*Map); ok {
 			// Check for status
-			if s, ok := resMap.Pairs["status"]; ok {
+			if s, ok := resMap.GetString("status"); ok {
 				if sInt, ok := s.(*Integer); ok {
 					statusCode = int(sInt.Value)
 				}
 			}
 			// Check for body
-			if b, ok := resMap.Pairs["body"]; ok {
-				responseBody = b.ToString()
+			if b, ok := resMap.GetString("body"); ok {
+				responseBody = BytesOf(b)
 			}
 			// Check for headers
-			if h, ok := resMap.Pairs["headers"]; ok {
+			if h, ok := resMap.GetString("headers"); ok {
 				if hMap, ok := h.(*Map); ok {
-					for _, k := range hMap.Keys {
-						w.Header().Set(k, hMap.Pairs[k].ToString())
+					for _, pair := range hMap.Entries() {
+						w.Header().Set(pair.Key.ToString(), pair.Value.ToString())
 					}
 				}
 			}
 		} else {
-			responseBody = result.ToString()
+			responseBody = BytesOf(result)
 		}
 
 		w.WriteHeader(statusCode)
-		w.Write([]byte(responseBody))
+		w.Write(responseBody)
 	}
 }
 
-// httpPost performs a POST request.
-// Syntax: post_http(url, content_type, body)
+// httpPost performs a POST request with a string or bytes body.
+// Returns the response body as a string, or as bytes when asked.
+// Syntax: post_http(url, content_type, body, ["bytes"])
 func httpPost(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 3 {
-		return createError("ERROR: post_http expects 3 arguments (url, content_type, body)")
+	if err := checkCapability(rt, "post_http", CapNetwork, ""); err != nil {
+		return err
+	}
+	if len(args) != 3 && len(args) != 4 {
+		return createError("ERROR: post_http expects 3 or 4 arguments (url, content_type, body, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("post_http", args, 3)
+	if errObj != nil {
+		return errObj
 	}
 	url := args[0].ToString()
 	contentType := args[1].ToString()
-	bodyContent := args[2].ToString()
+	bodyContent := BytesOf(args[2])
 
-	resp, err := http.Post(url, contentType, strings.NewReader(bodyContent))
+	resp, err := http.Post(url, contentType, bytes.NewReader(bodyContent))
 	if err != nil {
 		return createError("ERROR: post_http failed: %v", err)
 	}
@@ -405,20 +428,28 @@ func httpPost(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: failed to read response body: %v", err)
 	}
 
-	return &String{Value: string(body)}
+	return NewData(asBytes, body)
 }
 
-// httpPut performs a PUT request.
-// Syntax: put_http(url, content_type, body)
+// httpPut performs a PUT request with a string or bytes body.
+// Returns the response body as a string, or as bytes when asked.
+// Syntax: put_http(url, content_type, body, ["bytes"])
 func httpPut(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 3 {
-		return createError("ERROR: put_http expects 3 arguments (url, content_type, body)")
+	if err := checkCapability(rt, "put_http", CapNetwork, ""); err != nil {
+		return err
+	}
+	if len(args) != 3 && len(args) != 4 {
+		return createError("ERROR: put_http expects 3 or 4 arguments (url, content_type, body, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("put_http", args, 3)
+	if errObj != nil {
+		return errObj
 	}
 	url := args[0].ToString()
 	contentType := args[1].ToString()
-	bodyContent := args[2].ToString()
+	bodyContent := BytesOf(args[2])
 
-	req, err := http.NewRequest("PUT", url, strings.NewReader(bodyContent))
+	req, err := http.NewRequest("PUT", url, bytes.NewReader(bodyContent))
 	if err != nil {
 		return createError("ERROR: failed to create request: %v", err)
 	}
@@ -436,14 +467,22 @@ func httpPut(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: failed to read response body: %v", err)
 	}
 
-	return &String{Value: string(body)}
+	return NewData(asBytes, body)
 }
 
 // httpDelete performs a DELETE request.
-// Syntax: delete_http(url)
+// Returns the response body as a string, or as bytes when asked.
+// Syntax: delete_http(url, ["bytes"])
 func httpDelete(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: delete_http expects 1 argument (url)")
+	if err := checkCapability(rt, "delete_http", CapNetwork, ""); err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: delete_http expects 1 or 2 arguments (url, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("delete_http", args, 1)
+	if errObj != nil {
+		return errObj
 	}
 	url := args[0].ToString()
 
@@ -464,5 +503,5 @@ func httpDelete(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: failed to read response body: %v", err)
 	}
 
-	return &String{Value: string(body)}
+	return NewData(asBytes, body)
 }
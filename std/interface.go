@@ -0,0 +1,54 @@
+/*
+File    : go-mix/std/interface.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - interface.go
+// This file defines the GoMixInterface type which represents user-defined interfaces.
+package std
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InterfaceMethod is a method signature of an interface.
+type InterfaceMethod struct {
+	Name   string   // The name of the method
+	Params []string // The names of its parameters
+}
+
+// GoMixInterface represents an interface declaration in Go-Mix.
+// A struct that declares it implements an interface must have (or inherit)
+// every method of the interface, taking the same number of arguments.
+//
+// Example:
+//
+//	interface Shape { area(); perimeter(); }
+//	struct Square implements Shape { ... }
+type GoMixInterface struct {
+	Name    string            // The name of the interface
+	Methods []InterfaceMethod // The method signatures, in declaration order
+}
+
+// GetType returns the type of the GoMixInterface object
+func (i *GoMixInterface) GetType() GoMixType {
+	return InterfaceType
+}
+
+// ToString returns the declaration of the interface (e.g., "interface Shape { area(); perimeter(); }")
+func (i *GoMixInterface) ToString() string {
+	var sb strings.Builder
+	sb.WriteString("interface " + i.Name + " {")
+	for _, m := range i.Methods {
+		sb.WriteString(" " + m.Name + "(" + strings.Join(m.Params, ", ") + ");")
+	}
+	sb.WriteString(" }")
+	return sb.String()
+}
+
+// ToObject returns a detailed representation of the interface
+func (i *GoMixInterface) ToObject() string {
+	return fmt.Sprintf("<interface(%s)>", i.Name)
+}
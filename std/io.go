@@ -18,19 +18,19 @@ import (
 )
 
 var ioMethods = []*Builtin{
-	{Name: "scanln", Callback: scanln},     // Reads a line of text from stdin
-	{Name: "scanf", Callback: scanf},       // Reads formatted input from stdin
-	{Name: "input", Callback: input},       // Prints a prompt and reads a line
-	{Name: "scan", Callback: scan},         // Reads until a specific string delimiter
-	{Name: "getchar", Callback: getchar},   // Reads a single character
-	{Name: "putchar", Callback: putchar},   // Prints a single character
-	{Name: "gets", Callback: scanln},       // Alias for scanln (C-style)
-	{Name: "puts", Callback: println},      // Alias for println (C-style)
-	{Name: "sprintf", Callback: sprintf},   // Returns a formatted string
-	{Name: "flush", Callback: flush},       // Flushes the input buffer and output writer
-	{Name: "eprintln", Callback: eprintln}, // Prints to standard error with newline
-	{Name: "eprintf", Callback: eprintf},   // Prints formatted string to standard error
-	// {Name: "exit", Callback: exitFunc},     // Terminates the program
+	{Name: "scanln", Callback: scanln, Signature: "scanln() -> string"},                   // Reads a line of text from stdin
+	{Name: "scanf", Callback: scanf, Signature: "scanf(format) -> array"},                 // Reads formatted input from stdin
+	{Name: "input", Callback: input, Signature: "input(prompt) -> string"},                // Prints a prompt and reads a line
+	{Name: "scan", Callback: scan, Signature: "scan(delimiter) -> string"},                // Reads until a specific string delimiter
+	{Name: "getchar", Callback: getchar, Signature: "getchar() -> string"},                // Reads a single character
+	{Name: "putchar", Callback: putchar, Signature: "putchar(char_or_int) -> nil"},        // Prints a single character
+	{Name: "gets", Callback: scanln, Signature: "gets() -> string"},                       // Alias for scanln (C-style)
+	{Name: "puts", Callback: println, Signature: "puts(...args) -> nil"},                  // Alias for println (C-style)
+	{Name: "sprintf", Callback: sprintf, Signature: "sprintf(format, ...args) -> string"}, // Returns a formatted string
+	{Name: "flush", Callback: flush, Signature: "flush() -> nil"},                         // Flushes the input buffer and output writer
+	{Name: "eprintln", Callback: eprintln, Signature: "eprintln(...args) -> nil"},         // Prints to standard error with newline
+	{Name: "eprintf", Callback: eprintf, Signature: "eprintf(format, ...args) -> nil"},    // Prints formatted string to standard error
+	// {Name: "exit", Callback: exitFunc, Signature: "exit([code]) -> nil"},     // Terminates the program
 }
 
 // init registers the I/O methods as global builtins and as a package for import.
@@ -0,0 +1,271 @@
+/*
+File    : go-mix/std/iter.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - iter.go
+// This file implements the iteration protocol shared by the foreach loop
+// (in the tree walker and the VM) and by the builtins that accept any iterable.
+//
+// Iterables and what they yield (key, value):
+//   - range: (position, integer), counting up or down to the end inclusively
+//   - array, list, tuple: (index, element)
+//   - string: (index, char)
+//   - bytes: (index, byte as an int)
+//   - map: (key, value), in insertion order
+//   - set: (index, element), in insertion order
+//   - chan: (index, value received), until the channel is closed and drained
+//   - Go types implementing Iterable (e.g., file handles yield their lines)
+//   - struct instances with an iter() method returning an iterable or an
+//     iterator, or with a next() method returning the next value (nil ends it)
+package std
+
+import "unicode/utf8"
+
+// Iterator walks the elements of an iterable value one at a time.
+type Iterator interface {
+	// Next returns the key and the value of the next element. The value is
+	// nil once the iterable is exhausted; a non-nil error stops the iteration.
+	Next(rt Runtime) (key, value, err GoMixObject)
+}
+
+// Iterable is implemented by Go types that foreach can walk.
+type Iterable interface {
+	Iter() Iterator
+}
+
+// NewIterator returns an iterator over obj, or nil if obj is not iterable.
+func NewIterator(obj GoMixObject) Iterator {
+	switch obj := obj.(type) {
+	case *Range:
+		return &rangeIterator{rng: obj}
+	case *Array:
+		return &sliceIterator{elems: obj.Elements}
+	case *List:
+		return &sliceIterator{elems: obj.Elements}
+	case *Tuple:
+		return &sliceIterator{elems: obj.Elements}
+	case *String:
+		return &stringIterator{str: obj.Value}
+	case *Bytes:
+		return &bytesIterator{data: obj.Value}
+	case *Map:
+		return &mapIterator{m: obj}
+	case *Set:
+		return &setIterator{set: obj}
+	case *Channel:
+		return &chanIterator{ch: obj}
+	case *GoMixObjectInstance:
+		_, hasIter := obj.Struct.GetMethod("iter")
+		_, hasNext := obj.Struct.GetMethod("next")
+		if hasIter || hasNext {
+			return &instanceIterator{obj: obj, started: !hasIter}
+		}
+	case Iterable:
+		return obj.Iter()
+	}
+	return nil
+}
+
+// Iterate calls yield with the key and the value of each element of obj,
+// until yield returns false or an error occurs.
+//
+// Returns:
+//   - nil, or an Error if obj is not iterable or the iteration failed
+func Iterate(rt Runtime, obj GoMixObject, yield func(key, value GoMixObject) bool) GoMixObject {
+	it := NewIterator(obj)
+	if it == nil {
+		return createError("ERROR: `%s` is not iterable", obj.GetType())
+	}
+	for {
+		key, value, err := it.Next(rt)
+		if err != nil {
+			return err
+		}
+		if value == nil || !yield(key, value) {
+			return nil
+		}
+	}
+}
+
+// Collect returns the values of an iterable as a slice.
+// Arrays, lists and tuples are returned as they are (not copied).
+//
+// Returns:
+//   - The values of obj
+//   - nil, or an Error if obj is not iterable or the iteration failed
+func Collect(rt Runtime, obj GoMixObject) ([]GoMixObject, GoMixObject) {
+	switch obj := obj.(type) {
+	case *Array:
+		return obj.Elements, nil
+	case *List:
+		return obj.Elements, nil
+	case *Tuple:
+		return obj.Elements, nil
+	}
+	values := make([]GoMixObject, 0)
+	err := Iterate(rt, obj, func(key, value GoMixObject) bool {
+		values = append(values, value)
+		return true
+	})
+	return values, err
+}
+
+// iterableArg returns the values of the first argument of the builtin name,
+// which accepts any iterable in place of an array or list.
+func iterableArg(rt Runtime, name string, arg GoMixObject) ([]GoMixObject, GoMixObject) {
+	if NewIterator(arg) == nil {
+		return nil, createError("ERROR: first argument to `%s` must be an iterable, got '%s'", name, arg.GetType())
+	}
+	return Collect(rt, arg)
+}
+
+// rangeIterator walks a range.
+type rangeIterator struct {
+	rng *Range
+	pos int64
+}
+
+// Next implements Iterator.
+func (it *rangeIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	var value int64
+	if it.rng.Start <= it.rng.End {
+		value = it.rng.Start + it.pos
+		if value > it.rng.End {
+			return nil, nil, nil
+		}
+	} else {
+		value = it.rng.Start - it.pos
+		if value < it.rng.End {
+			return nil, nil, nil
+		}
+	}
+	it.pos++
+	return &Integer{Value: it.pos - 1}, &Integer{Value: value}, nil
+}
+
+// sliceIterator walks the elements of an array, list or tuple.
+type sliceIterator struct {
+	elems []GoMixObject
+	pos   int
+}
+
+// Next implements Iterator.
+func (it *sliceIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	if it.pos >= len(it.elems) {
+		return nil, nil, nil
+	}
+	it.pos++
+	return &Integer{Value: int64(it.pos - 1)}, it.elems[it.pos-1], nil
+}
+
+// stringIterator walks the characters of a string.
+type stringIterator struct {
+	str   string
+	pos   int   // byte offset of the next character
+	index int64 // index of the next character
+}
+
+// Next implements Iterator.
+func (it *stringIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	if it.pos >= len(it.str) {
+		return nil, nil, nil
+	}
+	r, size := utf8.DecodeRuneInString(it.str[it.pos:])
+	it.pos += size
+	it.index++
+	return &Integer{Value: it.index - 1}, &Char{Value: r}, nil
+}
+
+// mapIterator walks the entries of a map.
+// Keys removed while iterating are skipped.
+type mapIterator struct {
+	m   *Map
+	pos int
+}
+
+// Next implements Iterator.
+func (it *mapIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	for it.pos < len(it.m.Keys) {
+		hk := it.m.Keys[it.pos]
+		it.pos++
+		if pair, ok := it.m.Pairs[hk]; ok {
+			return pair.Key, pair.Value, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// setIterator walks the elements of a set.
+type setIterator struct {
+	set *Set
+	pos int
+}
+
+// Next implements Iterator.
+func (it *setIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	if it.pos >= len(it.set.Keys) {
+		return nil, nil, nil
+	}
+	it.pos++
+	return &Integer{Value: int64(it.pos - 1)}, it.set.Elements[it.set.Keys[it.pos-1]], nil
+}
+
+// chanIterator receives from a channel until it is closed and drained.
+type chanIterator struct {
+	ch    *Channel
+	index int64
+}
+
+// Next implements Iterator.
+func (it *chanIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	value, ok, err := it.ch.Receive(rt)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	it.index++
+	return &Integer{Value: it.index - 1}, value, nil
+}
+
+// instanceIterator implements the iteration protocol of struct instances.
+// iter() is called on the first step; the values then come from the next()
+// method of the instance it returned, or from the iterable it returned.
+type instanceIterator struct {
+	obj     *GoMixObjectInstance
+	started bool     // whether iter() has been called (or obj has no iter())
+	inner   Iterator // the iterator over what iter() returned, if not an instance
+	index   int64
+}
+
+// Next implements Iterator.
+func (it *instanceIterator) Next(rt Runtime) (GoMixObject, GoMixObject, GoMixObject) {
+	if !it.started {
+		it.started = true
+		res := rt.CallMethod(it.obj, "iter")
+		if res.GetType() == ErrorType {
+			return nil, nil, res
+		}
+		inst, ok := res.(*GoMixObjectInstance)
+		if ok {
+			if _, hasNext := inst.Struct.GetMethod("next"); !hasNext {
+				return nil, nil, createError("ERROR: iter() of struct (%s) returned an instance of (%s), which has no next() method", it.obj.Struct.GetName(), inst.Struct.GetName())
+			}
+			it.obj = inst
+		} else if it.inner = NewIterator(res); it.inner == nil {
+			return nil, nil, createError("ERROR: iter() of struct (%s) must return an iterable, got '%s'", it.obj.Struct.GetName(), res.GetType())
+		}
+	}
+	if it.inner != nil {
+		return it.inner.Next(rt)
+	}
+	value := rt.CallMethod(it.obj, "next")
+	switch value.GetType() {
+	case ErrorType:
+		return nil, nil, value
+	case NilType:
+		return nil, nil, nil
+	}
+	it.index++
+	return &Integer{Value: it.index - 1}, value, nil
+}
@@ -4,16 +4,45 @@ Author  : Akash Maji
 Contact : akashmaji(@iisc.ac.in)
 */
 
+// Package std - json.go
+// This file implements the json package: encoding Go-Mix values as JSON text
+// and decoding JSON text into Go-Mix values.
+//
+// Encoding (stringify_json, write_json) converts values into plain data (see
+// codec.go): maps become objects with the keys in insertion order, the other
+// collections arrays, and bigints and decimals numbers with all their digits
+// (a float always has a fraction or an exponent, e.g. 1.0).
+//
+// Decoding (parse_json, read_json, lines_json) keeps the order of the keys of
+// objects. Numbers without a fraction or an exponent become ints (bigints if
+// they do not fit), the others floats. Given a struct type, objects become
+// instances of it; fields annotated with a struct type (or with a collection
+// of one, e.g. array<Point>) are decoded into that struct too.
 package std
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 var jsonMethods = []*Builtin{
-	{Name: "parse_json", Callback: jsonParse},
-	{Name: "stringify_json", Callback: jsonStringify},
+	{Name: "parse_json", Callback: jsonParse, Signature: "parse_json(text, [struct_type]) -> any"},            // Decodes JSON text, optionally into a struct type
+	{Name: "stringify_json", Callback: jsonStringify, Signature: "stringify_json(value, [indent]) -> string"}, // Encodes a value as JSON text, optionally indented
+	{Name: "read_json", Callback: jsonRead, Signature: "read_json(path, [struct_type]) -> any"},               // Decodes a JSON file
+	{Name: "write_json", Callback: jsonWrite, Signature: "write_json(path, value, [indent]) -> nil"},          // Encodes a value as JSON into a file
+	{Name: "lines_json", Callback: jsonLines, Signature: "lines_json(path, [struct_type]) -> generator"},      // Lazily decodes a line-delimited JSON file
 }
 
 func init() {
@@ -27,99 +56,681 @@ func init() {
 	RegisterPackage(jsonPackage)
 }
 
-// jsonParse parses a JSON string into a Go-Mix Map.
+// jsonParse decodes JSON text (a string or bytes) into a Go-Mix value. Given
+// a struct type, an object becomes an instance of it (and an array of objects
+// an array of instances).
+//
+// Syntax: parse_json(text, [struct_type])
+//
+// Example:
+//
+//	parse_json("{\"b\": 1, \"a\": [true, null]}") -> map{"b": 1, "a": [true, nil]}
+//	parse_json("{\"x\": 1, \"y\": 2}", Point)    -> a Point instance
 func jsonParse(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: json.parse expects 1 argument (string)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: parse_json expects 1 or 2 arguments (text, [struct_type])")
 	}
+	if args[0].GetType() != StringType && args[0].GetType() != BytesType {
+		return createError("ERROR: argument to `parse_json` must be a string or bytes, got '%s'", args[0].GetType())
+	}
+	target, errObj := jsonTarget(rt, "parse_json", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	val, errObj := decodeJSON(BytesOf(args[0]), 1, "")
+	if errObj != nil {
+		return errObj
+	}
+	return decodeTarget(rt, val, target)
+}
+
+// jsonStringify encodes a Go-Mix value as JSON text. The options are an
+// indent (a number of spaces or a string), or a map of options:
+//   - "indent": a number of spaces or a string, to write one value per line
+//   - "prefix": a string written at the start of each line after the first
+//   - "sort_keys": true to write the keys of objects in sorted order
+//
+// Syntax: stringify_json(value, [indent | options])
+//
+// Example:
+//
+//	stringify_json(map{"b": 1, "a": [1, 2]})    -> {"b":1,"a":[1,2]}
+//	stringify_json(map{"a": 1}, 2)              -> "{\n  \"a\": 1\n}"
+//	stringify_json(m, map{"sort_keys": true})   -> the keys of m sorted
+func jsonStringify(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: stringify_json expects 1 or 2 arguments (value, [indent or options])")
+	}
+	text, errObj := encodeJSON(rt, "stringify_json", args[0], args[1:])
+	if errObj != nil {
+		return errObj
+	}
+	return &String{Value: string(text)}
+}
 
-	if args[0].GetType() != StringType {
-		return createError("ERROR: argument to `json.parse` must be a string, got '%s'", args[0].GetType())
+// jsonRead decodes the JSON file at path, optionally into a struct type (see
+// parse_json).
+//
+// Syntax: read_json(path, [struct_type])
+func jsonRead(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: read_json expects 1 or 2 arguments (path, [struct_type])")
 	}
+	path := args[0].ToString()
+	target, errObj := jsonTarget(rt, "read_json", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	data, errObj := readCodecFile(rt, "read_json", path)
+	if errObj != nil {
+		return errObj
+	}
+	val, errObj := decodeJSON(data, 1, " of "+path)
+	if errObj != nil {
+		return errObj
+	}
+	return decodeTarget(rt, val, target)
+}
 
-	var data interface{}
-	err := json.Unmarshal([]byte(args[0].ToString()), &data)
+// jsonWrite encodes a value as JSON into the file at path, followed by a
+// newline. The options are those of stringify_json.
+//
+// Syntax: write_json(path, value, [indent | options])
+func jsonWrite(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 && len(args) != 3 {
+		return createError("ERROR: write_json expects 2 or 3 arguments (path, value, [indent or options])")
+	}
+	text, errObj := encodeJSON(rt, "write_json", args[1], args[2:])
+	if errObj != nil {
+		return errObj
+	}
+	return writeCodecFile(rt, "write_json", args[0].ToString(), append(text, '\n'))
+}
+
+// jsonLines returns a generator over the values of a line-delimited JSON file
+// (one value per line, blank lines skipped), optionally decoded into a struct
+// type. The file is read one line at a time as the generator is walked, and
+// closed once it is exhausted or a line fails to decode.
+//
+// Syntax: lines_json(path, [struct_type])
+//
+// Example:
+//
+//	foreach event in lines_json("events.jsonl") { println(event["type"]); }
+func jsonLines(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: lines_json expects 1 or 2 arguments (path, [struct_type])")
+	}
+	path := args[0].ToString()
+	if err := checkCapability(rt, "lines_json", CapFSRead, path); err != nil {
+		return err
+	}
+	target, errObj := jsonTarget(rt, "lines_json", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	file, err := os.Open(path)
 	if err != nil {
-		return createError("ERROR: failed to decode JSON: %v", err)
+		return createError("ERROR: could not open file '%s': %v", path, err)
 	}
+	reader := bufio.NewReader(file)
+	lineNo := 0
+	return NewGenerator("json.lines_json", func(rt Runtime) (GoMixObject, GoMixObject) {
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) == 0 && err != nil {
+				file.Close()
+				if err == io.EOF {
+					return nil, nil
+				}
+				return nil, createError("ERROR: could not read file '%s': %v", path, err)
+			}
+			lineNo++
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			val, errObj := decodeJSON(line, lineNo, " of "+path)
+			if errObj != nil {
+				file.Close()
+				return nil, errObj
+			}
+			val = decodeTarget(rt, val, target)
+			if val.GetType() == ErrorType {
+				file.Close()
+				return nil, val
+			}
+			return val, nil
+		}
+	})
+}
 
-	return convertToGoMix(data)
+// jsonTarget returns the struct type given as the optional argument i of a
+// decoding builtin, as a struct or by name.
+func jsonTarget(rt Runtime, name string, args []GoMixObject, i int) (*GoMixStruct, GoMixObject) {
+	if len(args) <= i || args[i].GetType() == NilType {
+		return nil, nil
+	}
+	if s, ok := args[i].(*GoMixStruct); ok {
+		return s, nil
+	}
+	if str, ok := args[i].(*String); ok && rt != nil {
+		if s, ok := rt.LookUpType(str.Value); ok {
+			if s, ok := s.(*GoMixStruct); ok {
+				return s, nil
+			}
+		}
+		return nil, createError("ERROR: struct type '%s' not defined", str.Value)
+	}
+	return nil, createError("ERROR: last argument to `%s` must be a struct type, got '%s'", name, args[i].GetType())
 }
 
-// jsonStringify converts a Go-Mix object into a JSON string.
-func jsonStringify(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: json.stringify expects 1 argument")
+// decodeTarget converts a decoded value into instances of the struct type s:
+// an object becomes an instance, and an array of objects an array of
+// instances. Without a struct type the value is returned as it is.
+func decodeTarget(rt Runtime, val GoMixObject, s *GoMixStruct) GoMixObject {
+	if s == nil {
+		return val
 	}
+	if arr, ok := val.(*Array); ok {
+		elements := make([]GoMixObject, len(arr.Elements))
+		for i, elem := range arr.Elements {
+			inst := decodeStruct(rt, elem, s)
+			if inst.GetType() == ErrorType {
+				return inst
+			}
+			elements[i] = inst
+		}
+		return &Array{Elements: elements}
+	}
+	return decodeStruct(rt, val, s)
+}
 
-	data := convertFromGoMix(args[0])
-	bytes, err := json.Marshal(data)
-	if err != nil {
-		return createError("ERROR: failed to encode JSON: %v", err)
+// decodeStruct creates an instance of s (without calling its constructor)
+// whose fields are the keys of a decoded object. The fields keep the defaults
+// of the struct for the keys the object does not have, and are checked
+// against their annotated types.
+func decodeStruct(rt Runtime, val GoMixObject, s *GoMixStruct) GoMixObject {
+	m, ok := val.(*Map)
+	if !ok {
+		return createError("ERROR: cannot decode JSON %s into struct (%s)", jsonKind(val), s.Name)
+	}
+	inst := NewStructInstance(s)
+	for _, pair := range m.Entries() {
+		name := pair.Key.ToString()
+		fieldVal := pair.Value
+		if typ := s.LookUpFieldType(name); typ != nil {
+			fieldVal = decodeAnnotated(rt, fieldVal, typ)
+			if fieldVal.GetType() == ErrorType {
+				return fieldVal
+			}
+		}
+		if rt == nil {
+			inst.InstanceFields[name] = fieldVal
+		} else if err := rt.SetField(inst, name, fieldVal); err != nil {
+			return err
+		}
 	}
+	return inst
+}
 
-	return &String{Value: string(bytes)}
+// decodeAnnotated converts a decoded value towards the annotated type of the
+// field it is stored in: objects into instances of a struct type, arrays into
+// tuples and sets, numbers into bigints and decimals and base64 strings into
+// bytes. Values that do not match the annotation are returned as they are,
+// for the field check to report.
+func decodeAnnotated(rt Runtime, val GoMixObject, typ *TypeAnnotation) GoMixObject {
+	if typ.IsUnion() {
+		// An optional type (Point | nil) decodes like its other alternative
+		var only *TypeAnnotation
+		for _, alt := range typ.Args {
+			if alt.Name == string(NilType) {
+				continue
+			}
+			if only != nil {
+				return val
+			}
+			only = alt
+		}
+		if only == nil || val.GetType() == NilType {
+			return val
+		}
+		return decodeAnnotated(rt, val, only)
+	}
+	if typ.Name == AnyType {
+		return val
+	}
+	switch GoMixType(typ.Name) {
+	case ArrayType, ListType:
+		arr, ok := val.(*Array)
+		if !ok {
+			return val
+		}
+		elements, errObj := decodeElements(rt, arr.Elements, typ, true)
+		if errObj != nil {
+			return errObj
+		}
+		if typ.Name == string(ListType) {
+			return &List{Elements: elements}
+		}
+		return &Array{Elements: elements}
+	case TupleType:
+		arr, ok := val.(*Array)
+		if !ok {
+			return val
+		}
+		elements, errObj := decodeElements(rt, arr.Elements, typ, len(typ.Args) <= 1)
+		if errObj != nil {
+			return errObj
+		}
+		return &Tuple{Elements: elements}
+	case SetType:
+		arr, ok := val.(*Array)
+		if !ok {
+			return val
+		}
+		elements, errObj := decodeElements(rt, arr.Elements, typ, true)
+		if errObj != nil {
+			return errObj
+		}
+		set := NewSet()
+		for _, elem := range elements {
+			hk, errObj := Hash(rt, elem)
+			if errObj != nil {
+				return errObj
+			}
+			set.Add(hk, elem)
+		}
+		return set
+	case MapType:
+		m, ok := val.(*Map)
+		if !ok || typ.Arg(1) == nil {
+			return val
+		}
+		for _, pair := range m.Entries() {
+			v := decodeAnnotated(rt, pair.Value, typ.Arg(1))
+			if v.GetType() == ErrorType {
+				return v
+			}
+			pair.Value = v
+		}
+		return m
+	case BigIntType:
+		if i, ok := val.(*Integer); ok {
+			return NewBigInt(i.Value)
+		}
+		return val
+	case DecimalType:
+		switch v := val.(type) {
+		case *Integer:
+			return &Decimal{Unscaled: big.NewInt(v.Value)}
+		case *BigInt:
+			return &Decimal{Unscaled: new(big.Int).Set(v.Value)}
+		case *Float:
+			if d, ok := FloatToDecimal(v.Value); ok {
+				return d
+			}
+		}
+		return val
+	case BytesType:
+		if str, ok := val.(*String); ok {
+			if data, err := base64.StdEncoding.DecodeString(str.Value); err == nil {
+				return &Bytes{Value: data}
+			}
+		}
+		return val
+	}
+	if typ.IsBuiltin() || rt == nil {
+		return val
+	}
+	if declared, ok := rt.LookUpType(typ.Name); ok {
+		if s, ok := declared.(*GoMixStruct); ok {
+			if _, isMap := val.(*Map); isMap {
+				return decodeStruct(rt, val, s)
+			}
+		}
+	}
+	return val
 }
 
-func convertFromGoMix(obj GoMixObject) interface{} {
-	switch obj.GetType() {
-	case ArrayType:
-		arr := obj.(*Array)
-		res := make([]interface{}, len(arr.Elements))
-		for i, e := range arr.Elements {
-			res[i] = convertFromGoMix(e)
+// decodeElements converts the elements of a decoded array towards the type
+// arguments of a collection annotation: the first one for every element, or
+// one per position (tuple<int, string>) when same is false.
+func decodeElements(rt Runtime, elems []GoMixObject, typ *TypeAnnotation, same bool) ([]GoMixObject, GoMixObject) {
+	res := make([]GoMixObject, len(elems))
+	for i, elem := range elems {
+		argType := typ.Arg(0)
+		if !same {
+			argType = typ.Arg(i)
+		}
+		if argType == nil {
+			res[i] = elem
+			continue
 		}
-		return res
+		res[i] = decodeAnnotated(rt, elem, argType)
+		if res[i].GetType() == ErrorType {
+			return nil, res[i]
+		}
+	}
+	return res, nil
+}
+
+// jsonKind names the JSON kind of a decoded value in errors.
+func jsonKind(val GoMixObject) string {
+	switch val.GetType() {
 	case MapType:
-		m := obj.(*Map)
-		res := make(map[string]interface{})
-		for k, v := range m.Pairs {
-			res[k] = convertFromGoMix(v)
-		}
-		return res
-	case IntegerType:
-		return obj.(*Integer).Value
-	case FloatType:
-		return obj.(*Float).Value
-	case BooleanType:
-		return obj.(*Boolean).Value
+		return "object"
+	case ArrayType:
+		return "array"
 	case StringType:
-		return obj.(*String).Value
+		return "string"
+	case BooleanType:
+		return "boolean"
 	case NilType:
-		return nil
-	default:
-		return obj.ToString()
+		return "null"
 	}
+	return "number"
 }
 
-func convertToGoMix(val interface{}) GoMixObject {
-	switch v := val.(type) {
-	case map[string]interface{}:
-		m := &Map{
-			Pairs: make(map[string]GoMixObject),
-			Keys:  make([]string, 0, len(v)),
-		}
-		for k, rawVal := range v {
-			m.Pairs[k] = convertToGoMix(rawVal)
-			m.Keys = append(m.Keys, k)
-		}
-		return m
-	case []interface{}:
-		elements := make([]GoMixObject, len(v))
-		for i, rawVal := range v {
-			elements[i] = convertToGoMix(rawVal)
+// decodeJSON decodes JSON text into a Go-Mix value. Syntax errors give the
+// line and the column of the offending character, counting the lines of the
+// text from line; where is appended to the position (e.g., " of data.json").
+func decodeJSON(data []byte, line int, where string) (GoMixObject, GoMixObject) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	d := &jsonDecoder{dec: dec, data: data, line: line, where: where}
+	val, errObj := d.value()
+	if errObj != nil {
+		return nil, errObj
+	}
+	rest := int(dec.InputOffset())
+	for rest < len(data) && strings.ContainsRune(" \t\r\n", rune(data[rest])) {
+		rest++
+	}
+	if rest < len(data) {
+		r, _ := utf8.DecodeRune(data[rest:])
+		return nil, d.errorAt(rest, fmt.Sprintf("invalid character %q after top-level value", r))
+	}
+	return val, nil
+}
+
+// jsonDecoder builds Go-Mix values from the tokens of a json.Decoder, which
+// gives the keys of objects in order.
+type jsonDecoder struct {
+	dec   *json.Decoder
+	data  []byte
+	line  int    // The line number of the start of data
+	where string // Appended to the positions of errors
+}
+
+// value decodes the next value of the input.
+func (d *jsonDecoder) value() (GoMixObject, *Error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, d.tokenError(err)
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return d.object()
 		}
-		return &Array{Elements: elements}
+		return d.array()
 	case string:
-		return &String{Value: v}
+		return &String{Value: t}, nil
 	case bool:
-		return &Boolean{Value: v}
-	case float64:
-		if v == float64(int64(v)) {
-			return &Integer{Value: int64(v)}
+		return &Boolean{Value: t}, nil
+	case json.Number:
+		return d.number(t)
+	case nil:
+		return &Nil{}, nil
+	}
+	return nil, d.errorAt(int(d.dec.InputOffset()), fmt.Sprintf("unexpected token %v", tok))
+}
+
+// object decodes the keys and values of an object after its '{'.
+func (d *jsonDecoder) object() (GoMixObject, *Error) {
+	m := NewMap()
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, d.tokenError(err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, d.errorAt(int(d.dec.InputOffset())-1, "object key must be a string")
+		}
+		val, errObj := d.value()
+		if errObj != nil {
+			return nil, errObj
+		}
+		m.SetString(key, val)
+	}
+	if _, err := d.dec.Token(); err != nil {
+		return nil, d.tokenError(err)
+	}
+	return m, nil
+}
+
+// array decodes the elements of an array after its '['.
+func (d *jsonDecoder) array() (GoMixObject, *Error) {
+	elements := make([]GoMixObject, 0)
+	for d.dec.More() {
+		val, errObj := d.value()
+		if errObj != nil {
+			return nil, errObj
+		}
+		elements = append(elements, val)
+	}
+	if _, err := d.dec.Token(); err != nil {
+		return nil, d.tokenError(err)
+	}
+	return &Array{Elements: elements}, nil
+}
+
+// number decodes a number: an int (or a bigint) without a fraction or an
+// exponent, a float otherwise.
+func (d *jsonDecoder) number(n json.Number) (GoMixObject, *Error) {
+	text := n.String()
+	if !strings.ContainsAny(text, ".eE") {
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return &Integer{Value: i}, nil
+		}
+		if b, ok := new(big.Int).SetString(text, 10); ok {
+			return NewInteger(b), nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, d.errorAt(int(d.dec.InputOffset())-len(text), fmt.Sprintf("number %s is out of range", text))
+	}
+	return &Float{Value: f}, nil
+}
+
+// tokenError converts an error of the tokenizer into a positioned error.
+func (d *jsonDecoder) tokenError(err error) *Error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return d.errorAt(int(syntaxErr.Offset)-1, syntaxErr.Error())
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return d.errorAt(len(d.data), "unexpected end of JSON input")
+	}
+	return d.errorAt(int(d.dec.InputOffset()), err.Error())
+}
+
+// errorAt returns an error at the byte offset of the input, given as a line
+// and a column (in characters, counted from 1).
+func (d *jsonDecoder) errorAt(offset int, msg string) *Error {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(d.data) {
+		offset = len(d.data)
+	}
+	before := d.data[:offset]
+	line := d.line + bytes.Count(before, []byte("\n"))
+	column := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return createError("ERROR: invalid JSON at line %d, column %d%s: %s", line, column, d.where, msg)
+}
+
+// encodeJSON encodes obj as JSON text with the options of stringify_json.
+func encodeJSON(rt Runtime, name string, obj GoMixObject, opts []GoMixObject) ([]byte, GoMixObject) {
+	enc := &jsonEncoder{}
+	indent, prefix := "", ""
+	if len(opts) > 0 {
+		var errObj GoMixObject
+		indent, prefix, enc.sortKeys, errObj = jsonOptions(name, opts[0])
+		if errObj != nil {
+			return nil, errObj
+		}
+	}
+	data, errObj := codecData(rt, "JSON", obj)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if errObj := enc.encode(data); errObj != nil {
+		return nil, errObj
+	}
+	if indent == "" && prefix == "" {
+		return enc.buf.Bytes(), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, enc.buf.Bytes(), prefix, indent); err != nil {
+		return nil, createError("ERROR: failed to encode JSON: %v", err)
+	}
+	return out.Bytes(), nil
+}
+
+// jsonOptions reads the indent or the map of options of stringify_json.
+func jsonOptions(name string, opt GoMixObject) (indent, prefix string, sortKeys bool, errObj GoMixObject) {
+	m, ok := opt.(*Map)
+	if !ok {
+		indent, errObj = jsonIndent(name, opt)
+		return indent, "", false, errObj
+	}
+	for _, pair := range m.Entries() {
+		switch pair.Key.ToString() {
+		case "indent":
+			indent, errObj = jsonIndent(name, pair.Value)
+		case "prefix":
+			if pair.Value.GetType() != StringType {
+				errObj = createError("ERROR: option \"prefix\" of `%s` must be a string, got '%s'", name, pair.Value.GetType())
+			}
+			prefix = pair.Value.ToString()
+		case "sort_keys":
+			b, ok := pair.Value.(*Boolean)
+			if !ok {
+				errObj = createError("ERROR: option \"sort_keys\" of `%s` must be a bool, got '%s'", name, pair.Value.GetType())
+			} else {
+				sortKeys = b.Value
+			}
+		default:
+			errObj = createError("ERROR: unknown option %q of `%s` (expected \"indent\", \"prefix\" or \"sort_keys\")", pair.Key.ToString(), name)
+		}
+		if errObj != nil {
+			return "", "", false, errObj
+		}
+	}
+	return indent, prefix, sortKeys, nil
+}
+
+// jsonIndent reads an indent: a number of spaces or a string.
+func jsonIndent(name string, opt GoMixObject) (string, GoMixObject) {
+	switch o := opt.(type) {
+	case *Integer:
+		if o.Value < 0 || o.Value > 16 {
+			return "", createError("ERROR: indent of `%s` must be from 0 to 16 spaces, got %d", name, o.Value)
+		}
+		return strings.Repeat(" ", int(o.Value)), nil
+	case *String:
+		return o.Value, nil
+	}
+	return "", createError("ERROR: indent of `%s` must be a number of spaces, a string or a map of options, got '%s'", name, opt.GetType())
+}
+
+// jsonEncoder writes the compact JSON text of plain data.
+type jsonEncoder struct {
+	buf      bytes.Buffer
+	sortKeys bool // Whether the keys of objects are sorted
+}
+
+// encode writes the JSON text of a plain data value.
+func (enc *jsonEncoder) encode(obj GoMixObject) GoMixObject {
+	switch o := obj.(type) {
+	case *Nil:
+		enc.buf.WriteString("null")
+	case *Boolean:
+		enc.buf.WriteString(strconv.FormatBool(o.Value))
+	case *Integer:
+		enc.buf.WriteString(strconv.FormatInt(o.Value, 10))
+	case *Float:
+		if math.IsNaN(o.Value) || math.IsInf(o.Value, 0) {
+			return createError("ERROR: cannot encode float %s as JSON", o.ToString())
+		}
+		enc.buf.WriteString(formatFloat(o.Value))
+	case *BigInt, *Decimal:
+		enc.buf.WriteString(o.ToString())
+	case *String:
+		writeJSONString(&enc.buf, o.Value)
+	case *Array:
+		enc.buf.WriteByte('[')
+		for i, elem := range o.Elements {
+			if i > 0 {
+				enc.buf.WriteByte(',')
+			}
+			if err := enc.encode(elem); err != nil {
+				return err
+			}
+		}
+		enc.buf.WriteByte(']')
+	case *Map:
+		pairs := o.Entries()
+		if enc.sortKeys {
+			sort.SliceStable(pairs, func(i, j int) bool {
+				return pairs[i].Key.ToString() < pairs[j].Key.ToString()
+			})
+		}
+		enc.buf.WriteByte('{')
+		for i, pair := range pairs {
+			if i > 0 {
+				enc.buf.WriteByte(',')
+			}
+			writeJSONString(&enc.buf, pair.Key.ToString())
+			enc.buf.WriteByte(':')
+			if err := enc.encode(pair.Value); err != nil {
+				return err
+			}
+		}
+		enc.buf.WriteByte('}')
+	}
+	return nil
+}
+
+// writeJSONString writes s as a JSON string. Invalid UTF-8 is replaced by
+// U+FFFD.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range strings.ToValidUTF8(s, "\uFFFD") {
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20, r == '\u2028', r == '\u2029':
+			buf.WriteString(`\u`)
+			buf.WriteByte(hex[r>>12&0xF])
+			buf.WriteByte(hex[r>>8&0xF])
+			buf.WriteByte(hex[r>>4&0xF])
+			buf.WriteByte(hex[r&0xF])
+		default:
+			buf.WriteRune(r)
 		}
-		return &Float{Value: v}
-	default:
-		return &Nil{}
 	}
+	buf.WriteByte('"')
 }
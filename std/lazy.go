@@ -0,0 +1,292 @@
+/*
+File    : go-mix/std/lazy.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - lazy.go
+// This file implements the lazy package: combinators that take iterables
+// (arrays, ranges, files, generators, ...) and return generators, so that
+// long or infinite sequences are processed one value at a time. Closing a
+// combinator's generator (e.g., when a foreach over it ends early) closes the
+// generators it reads from.
+//
+// Example:
+//
+//	foreach pair in lazy.enumerate(lazy.take(naturals(), 3)) { ... }
+package std
+
+import "io"
+
+var lazyMethods = []*Builtin{
+	{Name: "take", Callback: lazyTake, Signature: "take(iterable, n) -> generator"},                      // The first n values of an iterable
+	{Name: "skip", Callback: lazySkip, Signature: "skip(iterable, n) -> generator"},                      // The values of an iterable after the first n
+	{Name: "zip", Callback: lazyZip, Signature: "zip(iterable1, iterable2, ...) -> generator"},           // Tuples of the values of several iterables, in step
+	{Name: "chain", Callback: lazyChain, Signature: "chain(iterable1, iterable2, ...) -> generator"},     // The values of several iterables, one after the other
+	{Name: "enumerate", Callback: lazyEnumerate, Signature: "enumerate(iterable, [start]) -> generator"}, // Tuples (index, value) of an iterable
+	{Name: "window", Callback: lazyWindow, Signature: "window(iterable, size, [step]) -> generator"},     // Arrays of consecutive values of an iterable
+}
+
+func init() {
+	lazyPackage := &Package{
+		Name:      "lazy",
+		Functions: make(map[string]*Builtin),
+	}
+	for _, method := range lazyMethods {
+		lazyPackage.Functions[method.Name] = method
+	}
+	RegisterPackage(lazyPackage)
+}
+
+// ordinals names the positions of arguments in error messages.
+var ordinals = []string{"first", "second", "third"}
+
+// lazyIterator returns an iterator over the i-th argument of the combinator name.
+func lazyIterator(name string, args []GoMixObject, i int) (Iterator, GoMixObject) {
+	it := NewIterator(args[i])
+	if it == nil {
+		position := "argument"
+		if i < len(ordinals) {
+			position = ordinals[i] + " argument"
+		}
+		return nil, createError("ERROR: %s to `lazy.%s` must be an iterable, got '%s'", position, name, args[i].GetType())
+	}
+	return it, nil
+}
+
+// lazyCount returns the i-th argument of the combinator name, which must be
+// an integer of at least min.
+func lazyCount(name string, args []GoMixObject, i int, min int64) (int64, GoMixObject) {
+	n, ok := args[i].(*Integer)
+	if !ok || n.Value < min {
+		return 0, createError("ERROR: %s argument to `lazy.%s` must be an integer >= %d, got '%s'", ordinals[i], name, min, args[i].ToString())
+	}
+	return n.Value, nil
+}
+
+// closeSources closes the generators among the iterables of a combinator,
+// once it has read all it needs from them or is closed itself (so that the
+// goroutines of generator functions end with it).
+func closeSources(args ...GoMixObject) {
+	for _, arg := range args {
+		if gen, ok := arg.(*Generator); ok {
+			gen.Close()
+		}
+	}
+}
+
+// lazyTake returns a generator over the first n values of an iterable. A
+// generator it takes from is closed after its n-th value.
+//
+// Syntax: lazy.take(iterable, n)
+func lazyTake(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 {
+		return createError("ERROR: lazy.take expects 2 arguments (iterable, n)")
+	}
+	it, err := lazyIterator("take", args, 0)
+	if err != nil {
+		return err
+	}
+	n, err := lazyCount("take", args, 1, 0)
+	if err != nil {
+		return err
+	}
+	taken := int64(0)
+	gen := NewGenerator("lazy.take", func(rt Runtime) (GoMixObject, GoMixObject) {
+		if taken >= n {
+			closeSources(args[0])
+			return nil, nil
+		}
+		taken++
+		_, value, err := it.Next(rt)
+		return value, err
+	})
+	gen.OnClose(func() { closeSources(args[0]) })
+	return gen
+}
+
+// lazySkip returns a generator over the values of an iterable after the
+// first n. The first n values are only read when the first value is asked for.
+//
+// Syntax: lazy.skip(iterable, n)
+func lazySkip(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 {
+		return createError("ERROR: lazy.skip expects 2 arguments (iterable, n)")
+	}
+	it, err := lazyIterator("skip", args, 0)
+	if err != nil {
+		return err
+	}
+	n, err := lazyCount("skip", args, 1, 0)
+	if err != nil {
+		return err
+	}
+	gen := NewGenerator("lazy.skip", func(rt Runtime) (GoMixObject, GoMixObject) {
+		for ; n > 0; n-- {
+			if _, value, err := it.Next(rt); value == nil {
+				return nil, err
+			}
+		}
+		_, value, err := it.Next(rt)
+		return value, err
+	})
+	gen.OnClose(func() { closeSources(args[0]) })
+	return gen
+}
+
+// lazyZip returns a generator over tuples holding the next value of each
+// iterable. It ends with the shortest iterable, closing the generators among
+// the others.
+//
+// Syntax: lazy.zip(iterable1, iterable2, ...)
+func lazyZip(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) < 2 {
+		return createError("ERROR: lazy.zip expects at least 2 arguments (iterables)")
+	}
+	its := make([]Iterator, len(args))
+	for i := range args {
+		it, err := lazyIterator("zip", args, i)
+		if err != nil {
+			return err
+		}
+		its[i] = it
+	}
+	gen := NewGenerator("lazy.zip", func(rt Runtime) (GoMixObject, GoMixObject) {
+		values := make([]GoMixObject, len(its))
+		for i, it := range its {
+			_, value, err := it.Next(rt)
+			if value == nil {
+				closeSources(args...)
+				return nil, err
+			}
+			values[i] = value
+		}
+		return &Tuple{Elements: values}, nil
+	})
+	gen.OnClose(func() { closeSources(args...) })
+	return gen
+}
+
+// lazyChain returns a generator over the values of each iterable in turn.
+//
+// Syntax: lazy.chain(iterable1, iterable2, ...)
+func lazyChain(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) == 0 {
+		return createError("ERROR: lazy.chain expects at least 1 argument (iterables)")
+	}
+	its := make([]Iterator, len(args))
+	for i := range args {
+		it, err := lazyIterator("chain", args, i)
+		if err != nil {
+			return err
+		}
+		its[i] = it
+	}
+	gen := NewGenerator("lazy.chain", func(rt Runtime) (GoMixObject, GoMixObject) {
+		for len(its) > 0 {
+			_, value, err := its[0].Next(rt)
+			if err != nil || value != nil {
+				return value, err
+			}
+			its = its[1:]
+		}
+		return nil, nil
+	})
+	// the iterables not walked to their end yet
+	gen.OnClose(func() { closeSources(args[len(args)-len(its):]...) })
+	return gen
+}
+
+// lazyEnumerate returns a generator over tuples (index, value) of the values
+// of an iterable, counting from start (0 by default).
+//
+// Syntax: lazy.enumerate(iterable) or lazy.enumerate(iterable, start)
+func lazyEnumerate(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: lazy.enumerate expects 1 or 2 arguments (iterable, start)")
+	}
+	it, err := lazyIterator("enumerate", args, 0)
+	if err != nil {
+		return err
+	}
+	index := int64(0)
+	if len(args) == 2 {
+		start, ok := args[1].(*Integer)
+		if !ok {
+			return createError("ERROR: second argument to `lazy.enumerate` must be an integer, got '%s'", args[1].GetType())
+		}
+		index = start.Value
+	}
+	gen := NewGenerator("lazy.enumerate", func(rt Runtime) (GoMixObject, GoMixObject) {
+		_, value, err := it.Next(rt)
+		if value == nil {
+			return nil, err
+		}
+		index++
+		return &Tuple{Elements: []GoMixObject{&Integer{Value: index - 1}, value}}, nil
+	})
+	gen.OnClose(func() { closeSources(args[0]) })
+	return gen
+}
+
+// lazyWindow returns a generator over arrays of size consecutive values of an
+// iterable, each starting step values (1 by default) after the previous one.
+// Values left over at the end that do not fill a window are dropped.
+//
+// Syntax: lazy.window(iterable, size) or lazy.window(iterable, size, step)
+//
+// Example:
+//
+//	lazy.window([1, 2, 3, 4], 2)    // [1, 2], [2, 3], [3, 4]
+//	lazy.window([1, 2, 3, 4], 2, 2) // [1, 2], [3, 4]
+func lazyWindow(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 && len(args) != 3 {
+		return createError("ERROR: lazy.window expects 2 or 3 arguments (iterable, size, step)")
+	}
+	it, err := lazyIterator("window", args, 0)
+	if err != nil {
+		return err
+	}
+	size, err := lazyCount("window", args, 1, 1)
+	if err != nil {
+		return err
+	}
+	step := int64(1)
+	if len(args) == 3 {
+		if step, err = lazyCount("window", args, 2, 1); err != nil {
+			return err
+		}
+	}
+
+	var window []GoMixObject
+	started := false
+	gen := NewGenerator("lazy.window", func(rt Runtime) (GoMixObject, GoMixObject) {
+		skip := int64(0)
+		if started {
+			if step < int64(len(window)) {
+				window = window[step:]
+			} else {
+				skip = step - int64(len(window))
+				window = nil
+			}
+		}
+		started = true
+		for ; skip > 0; skip-- {
+			if _, value, err := it.Next(rt); value == nil {
+				return nil, err
+			}
+		}
+		for int64(len(window)) < size {
+			_, value, err := it.Next(rt)
+			if value == nil {
+				return nil, err
+			}
+			window = append(window, value)
+		}
+		elems := make([]GoMixObject, len(window))
+		copy(elems, window)
+		return &Array{Elements: elems}, nil
+	})
+	gen.OnClose(func() { closeSources(args[0]) })
+	return gen
+}
@@ -17,29 +17,29 @@ import (
 // Each Builtin has a name (the method name) and a callback function that implements the behavior.
 // These are appended to the global Builtins slice during package initialization.
 var listMethods = []*Builtin{
-	{Name: "make_list", Callback: listFunc},
-
-	{Name: "pushback_list", Callback: pushbackList},   // Appends an element to the end of a list
-	{Name: "pushfront_list", Callback: pushfrontList}, // Prepends an element to the start of a list
-	{Name: "popback_list", Callback: popbackList},     // Removes and returns the last element of a list
-	{Name: "popfront_list", Callback: popfrontList},   // Removes and returns the first element of a list
-
-	{Name: "peekback_list", Callback: peekbackList},   // Returns the last element without removing it
-	{Name: "peekfront_list", Callback: peekfrontList}, // Returns the first element without removing it
-	{Name: "insert_list", Callback: insertList},       // Inserts an element at a specific index
-	{Name: "remove_list", Callback: removeList},       // Removes an element at a specific index
-	{Name: "contains_list", Callback: containsList},   // Checks if a value exists in the list
-
-	{Name: "map_list", Callback: mapList},       // Applies a function to each element
-	{Name: "filter_list", Callback: filterList}, // Filters elements based on a predicate
-	{Name: "reduce_list", Callback: reduceList}, // Reduces the list to a single value using a binary function
-	{Name: "find_list", Callback: findList},     // Finds the first element matching a predicate
-	{Name: "some_list", Callback: someList},     // Checks if at least one element matches
-	{Name: "every_list", Callback: everyList},   // Checks if all elements match
-
-	{Name: "to_list", Callback: toList},       // Converts array/tuple to list
-	{Name: "size_list", Callback: sizeList},   // Returns the number of elements in a list
-	{Name: "length_list", Callback: sizeList}, // Returns the number of elements in a list (alias)
+	{Name: "make_list", Callback: listFunc, Signature: "make_list(...args) -> list"},
+
+	{Name: "pushback_list", Callback: pushbackList, Signature: "pushback_list(l, elem) -> list"},    // Appends an element to the end of a list
+	{Name: "pushfront_list", Callback: pushfrontList, Signature: "pushfront_list(l, elem) -> list"}, // Prepends an element to the start of a list
+	{Name: "popback_list", Callback: popbackList, Signature: "popback_list(l) -> any"},              // Removes and returns the last element of a list
+	{Name: "popfront_list", Callback: popfrontList, Signature: "popfront_list(l) -> any"},           // Removes and returns the first element of a list
+
+	{Name: "peekback_list", Callback: peekbackList, Signature: "peekback_list(l) -> any"},         // Returns the last element without removing it
+	{Name: "peekfront_list", Callback: peekfrontList, Signature: "peekfront_list(l) -> any"},      // Returns the first element without removing it
+	{Name: "insert_list", Callback: insertList, Signature: "insert_list(l, index, elem) -> list"}, // Inserts an element at a specific index
+	{Name: "remove_list", Callback: removeList, Signature: "remove_list(l, index) -> any"},        // Removes an element at a specific index
+	{Name: "contains_list", Callback: containsList, Signature: "contains_list(l, elem) -> bool"},  // Checks if a value exists in the list
+
+	{Name: "map_list", Callback: mapList, Signature: "map_list(l, func) -> list"},                  // Applies a function to each element
+	{Name: "filter_list", Callback: filterList, Signature: "filter_list(l, func) -> list"},         // Filters elements based on a predicate
+	{Name: "reduce_list", Callback: reduceList, Signature: "reduce_list(l, func, initial) -> any"}, // Reduces the list to a single value using a binary function
+	{Name: "find_list", Callback: findList, Signature: "find_list(l, func) -> any"},                // Finds the first element matching a predicate
+	{Name: "some_list", Callback: someList, Signature: "some_list(l, func) -> bool"},               // Checks if at least one element matches
+	{Name: "every_list", Callback: everyList, Signature: "every_list(l, func) -> bool"},            // Checks if all elements match
+
+	{Name: "to_list", Callback: toList, Signature: "to_list(iterable) -> list"},   // Converts array/tuple to list
+	{Name: "size_list", Callback: sizeList, Signature: "size_list(l) -> int"},     // Returns the number of elements in a list
+	{Name: "length_list", Callback: sizeList, Signature: "length_list(l) -> int"}, // Returns the number of elements in a list (alias)
 
 }
 
@@ -399,24 +399,24 @@ func containsList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	return &Boolean{Value: false}
 }
 
-// mapList applies a function to each element of a list and returns a new list.
+// mapList applies a function to each element of a list (or any iterable) and returns a new list.
 // Syntax: map_list(list, function)
 func mapList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: map_list expects 2 arguments (list, function)")
 	}
-	if args[0].GetType() != ListType {
-		return createError("ERROR: first argument to `map_list` must be a list, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "map_list", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
 		return createError("ERROR: second argument to `map_list` must be a function, got '%s'", fn.GetType())
 	}
 
-	list := args[0].(*List)
-	newElements := make([]GoMixObject, len(list.Elements))
+	newElements := make([]GoMixObject, len(elems))
 
-	for i, elem := range list.Elements {
+	for i, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if res.GetType() == ErrorType {
 			return res
@@ -427,7 +427,7 @@ func mapList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &List{Elements: newElements}
 }
 
-// toList converts an array or tuple to a list.
+// toList converts an array, tuple or any other iterable to a list.
 // Syntax: to_list(iterable)
 func toList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
@@ -448,28 +448,35 @@ func toList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		copy(newElements, t.Elements)
 		return &List{Elements: newElements}
 	default:
-		return createError("ERROR: argument to `to_list` must be an array or tuple, got '%s'", arg.GetType())
+		if NewIterator(arg) == nil {
+			return createError("ERROR: argument to `to_list` must be an iterable, got '%s'", arg.GetType())
+		}
+		elems, err := Collect(rt, arg)
+		if err != nil {
+			return err
+		}
+		return &List{Elements: elems}
 	}
 }
 
-// filterList returns a new list containing elements that satisfy a predicate.
+// filterList returns a new list containing the elements of a list (or any iterable) that satisfy a predicate.
 // Syntax: filter_list(list, function)
 func filterList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: filter_list expects 2 arguments (list, function)")
 	}
-	if args[0].GetType() != ListType {
-		return createError("ERROR: first argument to `filter_list` must be a list, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "filter_list", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
 		return createError("ERROR: second argument to `filter_list` must be a function, got '%s'", fn.GetType())
 	}
 
-	list := args[0].(*List)
 	newElements := []GoMixObject{}
 
-	for _, elem := range list.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if res.GetType() == ErrorType {
 			return res
@@ -490,22 +497,22 @@ func filterList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &List{Elements: newElements}
 }
 
-// reduceList reduces a list to a single value using a binary function.
+// reduceList reduces a list (or any iterable) to a single value using a binary function.
 // Syntax: reduce_list(list, function, [initial])
 func reduceList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) < 2 || len(args) > 3 {
 		return createError("ERROR: reduce_list expects 2 or 3 arguments (list, function, [initial])")
 	}
-	if args[0].GetType() != ListType {
-		return createError("ERROR: first argument to `reduce_list` must be a list, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "reduce_list", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
 		return createError("ERROR: second argument to `reduce_list` must be a function, got '%s'", fn.GetType())
 	}
 
-	list := args[0].(*List)
-	if len(list.Elements) == 0 {
+	if len(elems) == 0 {
 		return createError("ERROR: cannot reduce an empty list without an initial value")
 	}
 
@@ -515,12 +522,12 @@ func reduceList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) == 3 {
 		accumulator = args[2]
 	} else {
-		accumulator = list.Elements[0]
+		accumulator = elems[0]
 		startIndex = 1
 	}
 
-	for i := startIndex; i < len(list.Elements); i++ {
-		elem := list.Elements[i]
+	for i := startIndex; i < len(elems); i++ {
+		elem := elems[i]
 		res := rt.CallFunction(fn, accumulator, elem)
 		if res.GetType() == ErrorType {
 			return res
@@ -537,16 +544,16 @@ func findList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: find_list expects 2 arguments (list, function)")
 	}
-	if args[0].GetType() != ListType {
-		return createError("ERROR: first argument to `find_list` must be a list, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "find_list", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
 		return createError("ERROR: second argument to `find_list` must be a function, got '%s'", fn.GetType())
 	}
 
-	list := args[0].(*List)
-	for _, elem := range list.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if IsTruthy(res) {
 			return elem
@@ -555,22 +562,22 @@ func findList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &Nil{}
 }
 
-// someList tests whether at least one element in the list passes the test.
+// someList tests whether at least one element in the list (or any iterable) passes the test.
 // Syntax: some_list(list, function)
 func someList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: some_list expects 2 arguments (list, function)")
 	}
-	if args[0].GetType() != ListType {
-		return createError("ERROR: first argument to `some_list` must be a list, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "some_list", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
 		return createError("ERROR: second argument to `some_list` must be a function, got '%s'", fn.GetType())
 	}
 
-	list := args[0].(*List)
-	for _, elem := range list.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if IsTruthy(res) {
 			return &Boolean{Value: true}
@@ -579,22 +586,22 @@ func someList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &Boolean{Value: false}
 }
 
-// everyList tests whether all elements in the list pass the test.
+// everyList tests whether all elements in the list (or any iterable) pass the test.
 // Syntax: every_list(list, function)
 func everyList(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: every_list expects 2 arguments (list, function)")
 	}
-	if args[0].GetType() != ListType {
-		return createError("ERROR: first argument to `every_list` must be a list, got '%s'", args[0].GetType())
+	elems, err := iterableArg(rt, "every_list", args[0])
+	if err != nil {
+		return err
 	}
 	fn := args[1]
 	if fn.GetType() != FunctionType {
 		return createError("ERROR: second argument to `every_list` must be a function, got '%s'", fn.GetType())
 	}
 
-	list := args[0].(*List)
-	for _, elem := range list.Elements {
+	for _, elem := range elems {
 		res := rt.CallFunction(fn, elem)
 		if !IsTruthy(res) {
 			return &Boolean{Value: false}
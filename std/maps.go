@@ -18,17 +18,17 @@ import (
 // Each Builtin has a name (the method name) and a callback function that implements the behavior.
 // These are appended to the global Builtins slice during package initialization.
 var mapMethods = []*Builtin{
-	{Name: "make_map", Callback: mapMake},           // Creates a new  map
-	{Name: "keys_map", Callback: mapKeys},           // Returns an array of all keys in a map
-	{Name: "insert_map", Callback: mapInsert},       // Inserts or updates a key-value pair in a map
-	{Name: "remove_map", Callback: mapRemove},       // Removes a key-value pair from a map
-	{Name: "contain_map", Callback: mapContain},     // Checks if a map contains a key
-	{Name: "enumerate_map", Callback: mapEnumerate}, // Returns array of [key, value] pairs
+	{Name: "make_map", Callback: mapMake, Signature: "make_map(key1, val1, key2, val2, ...) -> map"}, // Creates a new  map
+	{Name: "keys_map", Callback: mapKeys, Signature: "keys_map(m) -> array"},                         // Returns an array of all keys in a map
+	{Name: "insert_map", Callback: mapInsert, Signature: "insert_map(m, key, value) -> any"},         // Inserts or updates a key-value pair in a map
+	{Name: "remove_map", Callback: mapRemove, Signature: "remove_map(m, key) -> any"},                // Removes a key-value pair from a map
+	{Name: "contain_map", Callback: mapContain, Signature: "contain_map(m, key) -> bool"},            // Checks if a map contains a key
+	{Name: "enumerate_map", Callback: mapEnumerate, Signature: "enumerate_map(m) -> array"},          // Returns array of [key, value] pairs
 
-	{Name: "size_map", Callback: mapSize},   // Returns the number of key-value pairs in a map
-	{Name: "length_map", Callback: mapSize}, // Applies a function to each key-value pair, returning a new map
+	{Name: "size_map", Callback: mapSize, Signature: "size_map(m) -> int"},     // Returns the number of key-value pairs in a map
+	{Name: "length_map", Callback: mapSize, Signature: "length_map(m) -> int"}, // Applies a function to each key-value pair, returning a new map
 
-	{Name: "values_map", Callback: mapValues}, // Returns an array of all values in a map
+	{Name: "values_map", Callback: mapValues, Signature: "values_map(m) -> array"}, // Returns an array of all values in a map
 
 }
 
@@ -77,22 +77,14 @@ func mapMake(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: make_map expects an even number of arguments (key-value pairs)")
 	}
 
-	m := &Map{
-		Pairs: make(map[string]GoMixObject),
-		Keys:  make([]string, 0, len(args)/2),
-	}
+	m := NewMap()
 
 	for i := 0; i < len(args); i += 2 {
-		keyStr := args[i].ToString()
-		value := args[i+1]
-
-		// Check if key already exists
-		if _, exists := m.Pairs[keyStr]; !exists {
-			// New key, add to keys list
-			m.Keys = append(m.Keys, keyStr)
+		hk, err := Hash(rt, args[i])
+		if err != nil {
+			return err
 		}
-
-		m.Pairs[keyStr] = value
+		m.Set(hk, args[i], args[i+1])
 	}
 
 	return m
@@ -120,7 +112,7 @@ func mapSize(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	mapObj := args[0].(*Map)
-	return &Integer{Value: int64(len(mapObj.Pairs))}
+	return &Integer{Value: int64(mapObj.Len())}
 }
 
 // mapKeys returns an array of all keys in a map.
@@ -130,12 +122,12 @@ func mapSize(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //   - args[0]: The map to get keys from
 //
 // Returns:
-//   - Array of string keys, or Error if argument is not a map
+//   - Array of the keys (with their types), or Error if argument is not a map
 //
 // Example:
 //
-//	var m = map{"name": "John", "age": 25};
-//	keys_map(m);  // Returns ["name", "age"]
+//	var m = map{"name": "John", 1: 25};
+//	keys_map(m);  // Returns ["name", 1]
 func mapKeys(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=1", len(args))
@@ -146,10 +138,10 @@ func mapKeys(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	mapObj := args[0].(*Map)
-	keyObjects := make([]GoMixObject, len(mapObj.Keys))
+	keyObjects := make([]GoMixObject, mapObj.Len())
 
-	for i, key := range mapObj.Keys {
-		keyObjects[i] = &String{Value: key}
+	for i, pair := range mapObj.Entries() {
+		keyObjects[i] = pair.Key
 	}
 
 	return &Array{Elements: keyObjects}
@@ -160,7 +152,7 @@ func mapKeys(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 // Parameters:
 //   - args[0]: The map to insert into
-//   - args[1]: The key (will be converted to string)
+//   - args[1]: The key (any hashable value)
 //   - args[2]: The value to insert
 //
 // Returns:
@@ -181,17 +173,14 @@ func mapInsert(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	mapObj := args[0].(*Map)
-	keyStr := args[1].ToString()
-	value := args[2]
-
-	// Check if key already exists
-	if _, exists := mapObj.Pairs[keyStr]; !exists {
-		// New key, add to keys list
-		mapObj.Keys = append(mapObj.Keys, keyStr)
+	hk, err := Hash(rt, args[1])
+	if err != nil {
+		return err
 	}
+	value := args[2]
 
 	// Insert or update the value
-	mapObj.Pairs[keyStr] = value
+	mapObj.Set(hk, args[1], value)
 
 	return value
 }
@@ -201,7 +190,7 @@ func mapInsert(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 // Parameters:
 //   - args[0]: The map to remove from
-//   - args[1]: The key to remove (will be converted to string)
+//   - args[1]: The key to remove
 //
 // Returns:
 //   - The removed value if key existed, nil otherwise, or Error if wrong arguments
@@ -221,25 +210,17 @@ func mapRemove(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	mapObj := args[0].(*Map)
-	keyStr := args[1].ToString()
+	hk, err := Hash(rt, args[1])
+	if err != nil {
+		return err
+	}
 
-	// Check if key exists
-	value, exists := mapObj.Pairs[keyStr]
+	// Remove the key, if it exists
+	value, exists := mapObj.Delete(hk)
 	if !exists {
 		return &Nil{}
 	}
 
-	// Remove from pairs
-	delete(mapObj.Pairs, keyStr)
-
-	// Remove from keys list
-	for i, k := range mapObj.Keys {
-		if k == keyStr {
-			mapObj.Keys = append(mapObj.Keys[:i], mapObj.Keys[i+1:]...)
-			break
-		}
-	}
-
 	return value
 }
 
@@ -247,7 +228,7 @@ func mapRemove(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 // Parameters:
 //   - args[0]: The map to check
-//   - args[1]: The key to look for (will be converted to string)
+//   - args[1]: The key to look for
 //
 // Returns:
 //   - Boolean true if key exists, false otherwise, or Error if wrong arguments
@@ -267,9 +248,12 @@ func mapContain(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	mapObj := args[0].(*Map)
-	keyStr := args[1].ToString()
+	hk, err := Hash(rt, args[1])
+	if err != nil {
+		return err
+	}
 
-	_, exists := mapObj.Pairs[keyStr]
+	_, exists := mapObj.Get(hk)
 	return &Boolean{Value: exists}
 }
 
@@ -296,13 +280,13 @@ func mapEnumerate(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	}
 
 	mapObj := args[0].(*Map)
-	pairs := make([]GoMixObject, len(mapObj.Keys))
+	pairs := make([]GoMixObject, mapObj.Len())
 
-	for i, key := range mapObj.Keys {
+	for i, entry := range mapObj.Entries() {
 		pair := &Array{
 			Elements: []GoMixObject{
-				&String{Value: key},
-				mapObj.Pairs[key],
+				entry.Key,
+				entry.Value,
 			},
 		}
 		pairs[i] = pair
@@ -334,10 +318,10 @@ func mapValues(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	mapObj := args[0].(*Map)
-	valueObjects := make([]GoMixObject, len(mapObj.Keys))
+	valueObjects := make([]GoMixObject, mapObj.Len())
 
-	for i, key := range mapObj.Keys {
-		valueObjects[i] = mapObj.Pairs[key]
+	for i, pair := range mapObj.Entries() {
+		valueObjects[i] = pair.Value
 	}
 
 	return &Array{Elements: valueObjects}
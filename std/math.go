@@ -7,38 +7,40 @@ Contact : akashmaji(@iisc.ac.in)
 // Package std - math.go
 // This file defines the math builtin functions available in the Go-Mix language.
 //	It includes functions for absolute value, min/max, rounding, square root, power, and trigonometric functions.
+//	abs, min, max, floor, ceil, round, sqrt and pow also take bigints and decimals (see bignum.go).
 
 package std
 
 import (
 	"io"
 	"math"
+	"math/big"
 	"math/rand"
 	"time"
 )
 
 var mathMethods = []*Builtin{
-	{Name: "abs", Callback: abs},          // Returns the absolute value of a number
-	{Name: "fabs", Callback: fabs},        // Returns the absolute value of a floating-point number (alias for abs)
-	{Name: "min", Callback: min},          // Returns the smaller of two numbers
-	{Name: "max", Callback: max},          // Returns the larger of two numbers
-	{Name: "floor", Callback: floor},      // Returns the largest integer less than or equal to a number
-	{Name: "ceil", Callback: ceil},        // Returns the smallest integer greater than or equal to a number
-	{Name: "round", Callback: round},      // Returns the nearest integer to a number
-	{Name: "sqrt", Callback: sqrt},        // Returns the square root of a number
-	{Name: "pow", Callback: pow},          // Returns the result of raising a number to a power
-	{Name: "sin", Callback: sin},          // Returns the sine of the radian argument
-	{Name: "cos", Callback: cos},          // Returns the cosine of the radian argument
-	{Name: "tan", Callback: tan},          // Returns the tangent of the radian argument
-	{Name: "asin", Callback: asin},        // Returns the arcsine, in radians
-	{Name: "acos", Callback: acos},        // Returns the arccosine, in radians
-	{Name: "atan", Callback: atan},        // Returns the arctangent, in radians
-	{Name: "atan2", Callback: atan2},      // Returns the arctangent of y/x, in radians
-	{Name: "log", Callback: log},          // Returns the natural logarithm
-	{Name: "log10", Callback: log10},      // Returns the decimal logarithm
-	{Name: "exp", Callback: exp},          // Returns e**x
-	{Name: "rand", Callback: randFunc},    // Returns a random float [0.0, 1.0)
-	{Name: "rand_int", Callback: randInt}, // Returns a random integer in range
+	{Name: "abs", Callback: abs, Signature: "abs(n) -> int"},                      // Returns the absolute value of a number
+	{Name: "fabs", Callback: fabs, Signature: "fabs(n) -> float"},                 // Returns the absolute value of a floating-point number (alias for abs)
+	{Name: "min", Callback: min, Signature: "min(a, b) -> number"},                // Returns the smaller of two numbers
+	{Name: "max", Callback: max, Signature: "max(a, b) -> number"},                // Returns the larger of two numbers
+	{Name: "floor", Callback: floor, Signature: "floor(n) -> int"},                // Returns the largest integer less than or equal to a number
+	{Name: "ceil", Callback: ceil, Signature: "ceil(n) -> int"},                   // Returns the smallest integer greater than or equal to a number
+	{Name: "round", Callback: round, Signature: "round(n, [precision]) -> float"}, // Returns the nearest integer to a number
+	{Name: "sqrt", Callback: sqrt, Signature: "sqrt(n) -> float"},                 // Returns the square root of a number
+	{Name: "pow", Callback: pow, Signature: "pow(base, exp) -> float"},            // Returns the result of raising a number to a power
+	{Name: "sin", Callback: sin, Signature: "sin(rad) -> float"},                  // Returns the sine of the radian argument
+	{Name: "cos", Callback: cos, Signature: "cos(rad) -> float"},                  // Returns the cosine of the radian argument
+	{Name: "tan", Callback: tan, Signature: "tan(rad) -> float"},                  // Returns the tangent of the radian argument
+	{Name: "asin", Callback: asin, Signature: "asin(value) -> float"},             // Returns the arcsine, in radians
+	{Name: "acos", Callback: acos, Signature: "acos(value) -> float"},             // Returns the arccosine, in radians
+	{Name: "atan", Callback: atan, Signature: "atan(value) -> float"},             // Returns the arctangent, in radians
+	{Name: "atan2", Callback: atan2, Signature: "atan2(y, x) -> float"},           // Returns the arctangent of y/x, in radians
+	{Name: "log", Callback: log, Signature: "log(n) -> float"},                    // Returns the natural logarithm
+	{Name: "log10", Callback: log10, Signature: "log10(n) -> float"},              // Returns the decimal logarithm
+	{Name: "exp", Callback: exp, Signature: "exp(n) -> float"},                    // Returns e**x
+	{Name: "rand", Callback: randFunc, Signature: "rand() -> float"},              // Returns a random float [0.0, 1.0)
+	{Name: "rand_int", Callback: randInt, Signature: "rand_int(min, max) -> int"}, // Returns a random integer in range
 }
 
 // init registers the math methods as global builtins by appending them to the Builtins slice.
@@ -60,28 +62,38 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// abs returns the absolute value of an integer.
+// abs returns the absolute value of an integer or a decimal.
 //
 // Syntax: abs(integer)
 //
 // Usage:
 //
-//	Returns the non-negative value of the given integer.
+//	Returns the non-negative value of the given integer (int or bigint) or decimal.
 //	If the input is negative, it returns the positive equivalent.
 //
 // Example:
 //
-//	abs(-5); // Returns 5
-//	abs(10); // Returns 10
+//	abs(-5);              // Returns 5
+//	abs(10);              // Returns 10
+//	abs(decimal("-2.50")) // Returns 2.50
 func abs(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=1", len(args))
 	}
+	switch arg := args[0].(type) {
+	case *BigInt:
+		return &BigInt{Value: new(big.Int).Abs(arg.Value)}
+	case *Decimal:
+		return &Decimal{Unscaled: new(big.Int).Abs(arg.Unscaled), Scale: arg.Scale}
+	}
 	if args[0].GetType() != IntegerType {
 		return createError("ERROR: argument to `abs` must be an integer, got '%s'", args[0].GetType())
 	}
 
 	value := args[0].(*Integer).Value
+	if value == math.MinInt64 {
+		return &BigInt{Value: new(big.Int).Neg(big.NewInt(value))}
+	}
 	if value < 0 {
 		value = -value
 	}
@@ -124,6 +136,7 @@ func fabs(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 //	Compares two numbers (integers or floats) and returns the smaller one.
 //	If types are mixed, the result is promoted to float if necessary.
+//	Bigints and decimals are compared exactly, and returned as they are.
 //
 // Example:
 //
@@ -133,6 +146,12 @@ func min(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=2", len(args))
 	}
+	if c, ok := CompareNumbers(args[0], args[1]); ok {
+		if c < 0 {
+			return args[0]
+		}
+		return args[1]
+	}
 	if (args[0].GetType() != IntegerType && args[0].GetType() != FloatType) ||
 		(args[1].GetType() != IntegerType && args[1].GetType() != FloatType) {
 		return createError("ERROR: arguments to `min` must be integers or floats, got '%s' and '%s'", args[0].GetType(), args[1].GetType())
@@ -171,6 +190,7 @@ func min(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 //	Compares two numbers (integers or floats) and returns the larger one.
 //	If types are mixed, the result is promoted to float if necessary.
+//	Bigints and decimals are compared exactly, and returned as they are.
 //
 // Example:
 //
@@ -180,6 +200,12 @@ func max(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=2", len(args))
 	}
+	if c, ok := CompareNumbers(args[0], args[1]); ok {
+		if c > 0 {
+			return args[0]
+		}
+		return args[1]
+	}
 	if (args[0].GetType() != IntegerType && args[0].GetType() != FloatType) ||
 		(args[1].GetType() != IntegerType && args[1].GetType() != FloatType) {
 		return createError("ERROR: arguments to `max` must be integers or floats, got '%s' and '%s'", args[0].GetType(), args[1].GetType())
@@ -217,6 +243,7 @@ func max(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 // Usage:
 //
 //	Returns the greatest integer value that is less than or equal to the input float.
+//	For a decimal, the result is an int, or a bigint if it does not fit in an int.
 //
 // Example:
 //
@@ -226,6 +253,13 @@ func floor(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=1", len(args))
 	}
+	if d, ok := args[0].(*Decimal); ok {
+		value := d.Trunc()
+		if d.Unscaled.Sign() < 0 && !d.IsInteger() {
+			value.Sub(value, big.NewInt(1))
+		}
+		return NewInteger(value)
+	}
 	if args[0].GetType() != FloatType {
 		return createError("ERROR: argument to `floor` must be a float, got '%s'", args[0].GetType())
 	}
@@ -245,6 +279,7 @@ func floor(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 // Usage:
 //
 //	Returns the smallest integer value that is greater than or equal to the input float.
+//	For a decimal, the result is an int, or a bigint if it does not fit in an int.
 //
 // Example:
 //
@@ -254,6 +289,13 @@ func ceil(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 1 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=1", len(args))
 	}
+	if d, ok := args[0].(*Decimal); ok {
+		value := d.Trunc()
+		if d.Unscaled.Sign() > 0 && !d.IsInteger() {
+			value.Add(value, big.NewInt(1))
+		}
+		return NewInteger(value)
+	}
 	if args[0].GetType() != FloatType {
 		return createError("ERROR: argument to `ceil` must be a float, got '%s'", args[0].GetType())
 	}
@@ -275,15 +317,29 @@ func ceil(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //	Rounds the given float to the nearest value.
 //	The optional precision argument specifies the number of decimal places (defaults to 0).
 //	Uses standard rounding rules (0.5 rounds up).
+//	A decimal is rounded exactly, half away from zero, to a decimal with
+//	precision digits after the decimal point.
 //
 // Example:
 //
-//	round(3.5);       // Returns 4.0
-//	round(3.14159, 2); // Returns 3.14
+//	round(3.5);                 // Returns 4.0
+//	round(3.14159, 2);          // Returns 3.14
+//	round(decimal("2.675"), 2); // Returns 2.68
 func round(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) == 0 || len(args) > 2 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=1 or 2", len(args))
 	}
+	if d, ok := args[0].(*Decimal); ok {
+		precision := int64(0)
+		if len(args) == 2 {
+			p, ok := args[1].(*Integer)
+			if !ok || p.Value < 0 || p.Value > MaxDecimalScale {
+				return createError("ERROR: second argument to `round` a decimal must be an int from 0 to %d, got '%s'", MaxDecimalScale, args[1].ToString())
+			}
+			precision = p.Value
+		}
+		return d.Rescale(int(precision))
+	}
 	if args[0].GetType() != FloatType {
 		return createError("ERROR: first argument to `round` must be a float, got '%s'", args[0].GetType())
 	}
@@ -320,8 +376,8 @@ func sqrt(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 	if args[0].GetType() != FloatType {
 		// convert integer to float if needed
-		if args[0].GetType() == IntegerType {
-			args[0] = &Float{Value: float64(args[0].(*Integer).Value)}
+		if value, ok := ToFloat64(args[0]); ok {
+			args[0] = &Float{Value: value}
 		} else {
 			return createError("argument to `sqrt` must be a `number`, got '%s'", args[0].GetType())
 		}
@@ -343,15 +399,38 @@ func sqrt(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 //	Returns the base raised to the power of the exponent (base^exponent).
 //	Supports both integer and floating-point arguments.
+//	A bigint or decimal base with a non-negative int exponent gives an exact
+//	bigint, or a decimal rounded to the scale of the base.
 //
 // Example:
 //
-//	pow(2, 3);   // Returns 8.0
-//	pow(9, 0.5); // Returns 3.0
+//	pow(2, 3);                 // Returns 8.0
+//	pow(9, 0.5);               // Returns 3.0
+//	pow(2n, 100);              // Returns 1267650600228229401496703205376
+//	pow(decimal("1.05"), 2);   // Returns 1.10
 func pow(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	if len(args) != 2 {
 		return createError("ERROR: wrong number of arguments. got=%d, want=2", len(args))
 	}
+	if IsBigNumber(args[0]) {
+		exponent, ok := args[1].(*Integer)
+		if !ok || exponent.Value < 0 {
+			return createError("ERROR: exponent of a %s in `pow` must be a non-negative int, got '%s'", args[0].GetType(), args[1].ToString())
+		}
+		e := big.NewInt(exponent.Value)
+		if d, ok := args[0].(*Decimal); ok {
+			if exponent.Value > 1 && int64(d.Unscaled.BitLen()) > maxShift/exponent.Value {
+				return createError("ERROR: result of `pow` is too large")
+			}
+			unscaled := new(big.Int).Exp(d.Unscaled, e, nil)
+			return (&Decimal{Unscaled: unscaled, Scale: d.Scale * int(exponent.Value)}).Rescale(d.Scale)
+		}
+		base := args[0].(*BigInt).Value
+		if exponent.Value > 1 && int64(base.BitLen()) > maxShift/exponent.Value {
+			return createError("ERROR: result of `pow` is too large")
+		}
+		return &BigInt{Value: new(big.Int).Exp(base, e, nil)}
+	}
 	if (args[0].GetType() != IntegerType && args[0].GetType() != FloatType) ||
 		(args[1].GetType() != IntegerType && args[1].GetType() != FloatType) {
 		return createError("ERROR: arguments to `pow` must be integers or floats, got '%s' and '%s'", args[0].GetType(), args[1].GetType())
@@ -19,26 +19,26 @@ import (
 )
 
 var osMethods = []*Builtin{
-	{Name: "getenv", Callback: getenv},     // Gets an environment variable
-	{Name: "setenv", Callback: setenv},     // Sets an environment variable
-	{Name: "unsetenv", Callback: unsetenv}, // Unsets an environment variable
-	{Name: "exec", Callback: execCmd},      // Executes a shell command
-	{Name: "exit", Callback: exitFunc},     // Terminates the program
-	{Name: "args", Callback: argsFunc},     // Returns command-line arguments
-	{Name: "sleep", Callback: sleepFunc},   // Pauses execution for N milliseconds
-
-	{Name: "getcwd", Callback: getcwd},     // Returns the current working directory
-	{Name: "getpid", Callback: getpid},     // Returns the process ID
-	{Name: "hostname", Callback: hostname}, // Returns the system hostname
-	{Name: "user", Callback: userFunc},     // Returns the current username
-	{Name: "platform", Callback: platform}, // Returns the OS name
-	{Name: "arch", Callback: arch},         // Returns the system architecture
+	{Name: "getenv", Callback: getenv, Signature: "getenv(key) -> string"},           // Gets an environment variable
+	{Name: "setenv", Callback: setenv, Signature: "setenv(key, value) -> nil"},       // Sets an environment variable
+	{Name: "unsetenv", Callback: unsetenv, Signature: "unsetenv(key) -> nil"},        // Unsets an environment variable
+	{Name: "exec", Callback: execCmd, Signature: "exec(command, ...args) -> string"}, // Executes a shell command
+	{Name: "exit", Callback: exitFunc, Signature: "exit([code]) -> nil"},             // Terminates the program
+	{Name: "args", Callback: argsFunc, Signature: "args() -> array"},                 // Returns command-line arguments
+	{Name: "sleep", Callback: sleepFunc, Signature: "sleep(milliseconds) -> nil"},    // Pauses execution for N milliseconds
+
+	{Name: "getcwd", Callback: getcwd, Signature: "getcwd() -> string"},       // Returns the current working directory
+	{Name: "getpid", Callback: getpid, Signature: "getpid() -> int"},          // Returns the process ID
+	{Name: "hostname", Callback: hostname, Signature: "hostname() -> string"}, // Returns the system hostname
+	{Name: "user", Callback: userFunc, Signature: "user() -> string"},         // Returns the current username
+	{Name: "platform", Callback: platform, Signature: "platform() -> string"}, // Returns the OS name
+	{Name: "arch", Callback: arch, Signature: "arch() -> string"},             // Returns the system architecture
 
 	// assertions
-	{Name: "assert", Callback: assert},            // Asserts that a condition is true
-	{Name: "assert_equal", Callback: assertEqual}, // Asserts that two values are equal
-	{Name: "assert_true", Callback: assertTrue},   // Asserts that a condition is true
-	{Name: "assert_false", Callback: assertFalse}, // Asserts that a condition is false
+	{Name: "assert", Callback: assert, Signature: "assert(condition, message) -> nil"},                       // Asserts that a condition is true
+	{Name: "assert_equal", Callback: assertEqual, Signature: "assert_equal(value1, value2, message) -> nil"}, // Asserts that two values are equal
+	{Name: "assert_true", Callback: assertTrue, Signature: "assert_true(condition, message) -> nil"},         // Asserts that a condition is true
+	{Name: "assert_false", Callback: assertFalse, Signature: "assert_false(condition, message) -> nil"},      // Asserts that a condition is false
 
 }
 
@@ -67,6 +67,9 @@ func init() {
 //
 //	var path = getenv("PATH");
 func getenv(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "getenv", CapEnv, ""); err != nil {
+		return err
+	}
 	if len(args) != 1 {
 		return createError("ERROR: getenv expects 1 argument (key)")
 	}
@@ -83,6 +86,9 @@ func getenv(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 // Syntax: setenv(key, value)
 func setenv(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "setenv", CapEnv, ""); err != nil {
+		return err
+	}
 	if len(args) != 2 {
 		return createError("ERROR: setenv expects 2 arguments (key, value)")
 	}
@@ -99,6 +105,9 @@ func setenv(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 // Syntax: unsetenv(key)
 func unsetenv(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "unsetenv", CapEnv, ""); err != nil {
+		return err
+	}
 	if len(args) != 1 {
 		return createError("ERROR: unsetenv expects 1 argument (key)")
 	}
@@ -119,6 +128,9 @@ func unsetenv(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 //	var output = exec("ls", "-la");
 func execCmd(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "exec", CapProcess, ""); err != nil {
+		return err
+	}
 	if len(args) == 0 {
 		return createError("ERROR: exec expects at least 1 argument (command)")
 	}
@@ -146,6 +158,9 @@ func execCmd(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 // Syntax: exit([code])
 // Default code is 0.
 func exitFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if err := checkCapability(rt, "exit", CapProcess, ""); err != nil {
+		return err
+	}
 	code := 0
 	if len(args) > 0 {
 		if args[0].GetType() == IntegerType {
@@ -180,7 +195,8 @@ func sleepFunc(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: argument to `sleep` must be an integer, got '%s'", args[0].GetType())
 	}
 	ms := args[0].(*Integer).Value
-	time.Sleep(time.Duration(ms) * time.Millisecond)
+	// other goroutines run while this one sleeps
+	rt.Blocking(func() { time.Sleep(time.Duration(ms) * time.Millisecond) })
 	return &Nil{}
 }
 
@@ -346,7 +362,7 @@ func isEqual(a, b GoMixObject) bool {
 		}
 		for key, valA := range mapA {
 			valB, exists := mapB[key]
-			if !exists || !isEqual(valA, valB) {
+			if !exists || !isEqual(valA.Value, valB.Value) {
 				return false
 			}
 		}
@@ -386,6 +402,10 @@ func isEqual(a, b GoMixObject) bool {
 		if b.GetType() != FunctionType {
 			return false
 		}
+		if mA, ok := a.(*BoundMethod); ok {
+			mB, ok := b.(*BoundMethod)
+			return ok && mA.Receiver == mB.Receiver && mA.Name == mB.Name
+		}
 		fnA, okA := a.(FunctionInterface)
 		fnB, okB := b.(FunctionInterface)
 		if !okA || !okB {
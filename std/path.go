@@ -18,35 +18,35 @@ import (
 )
 
 var fileIOMethods = []*Builtin{
-	{Name: "read_file", Callback: readFile},     // Reads entire file content as string
-	{Name: "write_file", Callback: writeFile},   // Writes string to a file
-	{Name: "append_file", Callback: appendFile}, // Appends string to a file
-
-	{Name: "file_exists", Callback: fileExists}, // Checks if a file or directory exists
-	{Name: "is_dir", Callback: isDir},           // Checks if path is a directory
-	{Name: "is_file", Callback: isFile},         // Checks if path is a regular file
-
-	{Name: "mkdir", Callback: mkdir},            // Creates a directory (and parents)
-	{Name: "remove_file", Callback: removeFile}, // Removes a file or directory
-	{Name: "touch", Callback: touch},            // Creates an empty file or updates timestamps
-	{Name: "list_dir", Callback: listDir},       // Returns an array of names in a directory
-
-	{Name: "pwd", Callback: pwd},                    // Returns the current working directory
-	{Name: "home", Callback: home},                  // Returns the user's home directory
-	{Name: "truncate_file", Callback: truncateFile}, // Changes the size of a file
-	{Name: "remove_all", Callback: removeAll},       // Removes path and any children (rm -rf)
-	{Name: "rename_file", Callback: renameFile},     // Renames or moves a file/directory
-
-	{Name: "chmod", Callback: chmod}, // Changes file permissions
-	{Name: "cat", Callback: cat},     // Prints file content to output
-
-	{Name: "path_join", Callback: pathJoin}, // Joins path elements
-	{Name: "path_base", Callback: pathBase}, // Returns the last element of path
-	{Name: "path_dir", Callback: pathDir},   // Returns all but the last element of path
-	{Name: "path_ext", Callback: pathExt},   // Returns the file name extension
-	{Name: "path_abs", Callback: pathAbs},   // Returns an absolute representation of path
-	{Name: "glob", Callback: glob},          // Returns files matching a pattern
-	{Name: "copy_file", Callback: copyFile}, // Copies a file from src to dst
+	{Name: "read_file", Callback: readFile, Signature: "read_file(path, [\"bytes\"]) -> string"}, // Reads entire file content as string (or bytes)
+	{Name: "write_file", Callback: writeFile, Signature: "write_file(path, content) -> nil"},     // Writes string or bytes to a file
+	{Name: "append_file", Callback: appendFile, Signature: "append_file(path, content) -> nil"},  // Appends string or bytes to a file
+
+	{Name: "file_exists", Callback: fileExists, Signature: "file_exists(path) -> bool"}, // Checks if a file or directory exists
+	{Name: "is_dir", Callback: isDir, Signature: "is_dir(path) -> bool"},                // Checks if path is a directory
+	{Name: "is_file", Callback: isFile, Signature: "is_file(path) -> bool"},             // Checks if path is a regular file
+
+	{Name: "mkdir", Callback: mkdir, Signature: "mkdir(path) -> nil"},                  // Creates a directory (and parents)
+	{Name: "remove_file", Callback: removeFile, Signature: "remove_file(path) -> nil"}, // Removes a file or directory
+	{Name: "touch", Callback: touch, Signature: "touch(path) -> nil"},                  // Creates an empty file or updates timestamps
+	{Name: "list_dir", Callback: listDir, Signature: "list_dir(path) -> array"},        // Returns an array of names in a directory
+
+	{Name: "pwd", Callback: pwd, Signature: "pwd() -> string"},                                       // Returns the current working directory
+	{Name: "home", Callback: home, Signature: "home() -> string"},                                    // Returns the user's home directory
+	{Name: "truncate_file", Callback: truncateFile, Signature: "truncate_file(path, size) -> nil"},   // Changes the size of a file
+	{Name: "remove_all", Callback: removeAll, Signature: "remove_all(path) -> nil"},                  // Removes path and any children (rm -rf)
+	{Name: "rename_file", Callback: renameFile, Signature: "rename_file(old_path, new_path) -> nil"}, // Renames or moves a file/directory
+
+	{Name: "chmod", Callback: chmod, Signature: "chmod(path, mode) -> nil"}, // Changes file permissions
+	{Name: "cat", Callback: cat, Signature: "cat(path, ...) -> nil"},        // Prints file content to output
+
+	{Name: "path_join", Callback: pathJoin, Signature: "path_join(elem1, elem2, ...) -> string"}, // Joins path elements
+	{Name: "path_base", Callback: pathBase, Signature: "path_base(path) -> string"},              // Returns the last element of path
+	{Name: "path_dir", Callback: pathDir, Signature: "path_dir(path) -> string"},                 // Returns all but the last element of path
+	{Name: "path_ext", Callback: pathExt, Signature: "path_ext(path) -> string"},                 // Returns the file name extension
+	{Name: "path_abs", Callback: pathAbs, Signature: "path_abs(path) -> string"},                 // Returns an absolute representation of path
+	{Name: "glob", Callback: glob, Signature: "glob(pattern) -> array"},                          // Returns files matching a pattern
+	{Name: "copy_file", Callback: copyFile, Signature: "copy_file(src, dst) -> nil"},             // Copies a file from src to dst
 }
 
 // init registers the file I/O methods as global builtins and as a package for import.
@@ -65,22 +65,30 @@ func init() {
 	RegisterPackage(filePackage)
 }
 
-// readFile reads the entire contents of a file into a string.
+// readFile reads the entire contents of a file into a string, or into bytes
+// when asked.
 //
-// Syntax: read_file(path)
+// Syntax: read_file(path, ["bytes"])
 func readFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	if len(args) != 1 {
-		return createError("ERROR: read_file expects 1 argument (path)")
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: read_file expects 1 or 2 arguments (path, [\"bytes\"])")
+	}
+	asBytes, errObj := ResultMode("read_file", args, 1)
+	if errObj != nil {
+		return errObj
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "read_file", CapFSRead, path); err != nil {
+		return err
+	}
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return createError("ERROR: could not read file '%s': %v", path, err)
 	}
-	return &String{Value: string(content)}
+	return NewData(asBytes, content)
 }
 
-// writeFile writes a string to a file, creating it if it doesn't exist.
+// writeFile writes a string or bytes to a file, creating it if it doesn't exist.
 //
 // Syntax: write_file(path, content)
 func writeFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
@@ -88,8 +96,11 @@ func writeFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: write_file expects 2 arguments (path, content)")
 	}
 	path := args[0].ToString()
-	data := args[1].ToString()
-	err := os.WriteFile(path, []byte(data), 0644)
+	data := BytesOf(args[1])
+	if err := checkCapability(rt, "write_file", CapFSWrite, path); err != nil {
+		return err
+	}
+	err := os.WriteFile(path, data, 0644)
 	if err != nil {
 		return createError("ERROR: could not write to file '%s': %v", path, err)
 	}
@@ -188,6 +199,9 @@ func glob(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: glob expects 1 argument (pattern)")
 	}
 	pattern := args[0].ToString()
+	if err := checkCapability(rt, "glob", CapFSRead, filepath.Dir(pattern)); err != nil {
+		return err
+	}
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return createError("ERROR: glob failed: %v", err)
@@ -208,6 +222,12 @@ func copyFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 	src := args[0].ToString()
 	dst := args[1].ToString()
+	if err := checkCapability(rt, "copy_file", CapFSRead, src); err != nil {
+		return err
+	}
+	if err := checkCapability(rt, "copy_file", CapFSWrite, dst); err != nil {
+		return err
+	}
 
 	sourceFile, err := os.Open(src)
 	if err != nil {
@@ -241,6 +261,9 @@ func cat(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 	for _, arg := range args {
 		path := arg.ToString()
+		if err := checkCapability(rt, "cat", CapFSRead, path); err != nil {
+			return err
+		}
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return createError("ERROR: could not read file '%s': %v", path, err)
@@ -262,6 +285,9 @@ func touch(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: touch expects 1 argument (path)")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "touch", CapFSWrite, path); err != nil {
+		return err
+	}
 
 	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
@@ -287,6 +313,9 @@ func listDir(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: list_dir expects 1 argument (path)")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "list_dir", CapFSRead, path); err != nil {
+		return err
+	}
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		return createError("ERROR: could not read directory '%s': %v", path, err)
@@ -335,6 +364,9 @@ func truncateFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	}
 
 	path := args[0].ToString()
+	if err := checkCapability(rt, "truncate_file", CapFSWrite, path); err != nil {
+		return err
+	}
 	size := args[1].(*Integer).Value
 
 	err := os.Truncate(path, size)
@@ -353,6 +385,9 @@ func removeAll(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: remove_all expects 1 argument (path)")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "remove_all", CapFSWrite, path); err != nil {
+		return err
+	}
 	err := os.RemoveAll(path)
 	if err != nil {
 		return createError("ERROR: could not remove '%s': %v", path, err)
@@ -370,6 +405,12 @@ func renameFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 	oldPath := args[0].ToString()
 	newPath := args[1].ToString()
+	if err := checkCapability(rt, "rename_file", CapFSWrite, oldPath); err != nil {
+		return err
+	}
+	if err := checkCapability(rt, "rename_file", CapFSWrite, newPath); err != nil {
+		return err
+	}
 
 	err := os.Rename(oldPath, newPath)
 	if err != nil {
@@ -394,6 +435,9 @@ func chmod(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	path := args[0].ToString()
+	if err := checkCapability(rt, "chmod", CapFSWrite, path); err != nil {
+		return err
+	}
 	mode := args[1].(*Integer).Value
 
 	err := os.Chmod(path, os.FileMode(mode))
@@ -423,7 +467,7 @@ func home(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	return &String{Value: dir}
 }
 
-// appendFile appends a string to a file, creating it if it doesn't exist.
+// appendFile appends a string or bytes to a file, creating it if it doesn't exist.
 //
 // Syntax: append_file(path, content)
 func appendFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
@@ -431,13 +475,16 @@ func appendFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: append_file expects 2 arguments (path, content)")
 	}
 	path := args[0].ToString()
-	data := args[1].ToString()
+	data := BytesOf(args[1])
+	if err := checkCapability(rt, "append_file", CapFSWrite, path); err != nil {
+		return err
+	}
 	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return createError("ERROR: could not open file '%s' for appending: %v", path, err)
 	}
 	defer f.Close()
-	if _, err := f.WriteString(data); err != nil {
+	if _, err := f.Write(data); err != nil {
 		return createError("ERROR: could not write to file '%s': %v", path, err)
 	}
 	return &Nil{}
@@ -451,6 +498,9 @@ func fileExists(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: file_exists expects 1 argument")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "file_exists", CapFSRead, path); err != nil {
+		return err
+	}
 	_, err := os.Stat(path)
 	return &Boolean{Value: !os.IsNotExist(err)}
 }
@@ -463,6 +513,9 @@ func isDir(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: is_dir expects 1 argument")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "is_dir", CapFSRead, path); err != nil {
+		return err
+	}
 	info, err := os.Stat(path)
 	if err != nil {
 		return &Boolean{Value: false}
@@ -478,6 +531,9 @@ func isFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: is_file expects 1 argument")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "is_file", CapFSRead, path); err != nil {
+		return err
+	}
 	info, err := os.Stat(path)
 	if err != nil {
 		return &Boolean{Value: false}
@@ -493,6 +549,9 @@ func mkdir(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: mkdir expects 1 argument")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "mkdir", CapFSWrite, path); err != nil {
+		return err
+	}
 	err := os.MkdirAll(path, 0755)
 	if err != nil {
 		return createError("ERROR: could not create directory '%s': %v", path, err)
@@ -519,6 +578,9 @@ func removeFile(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 		return createError("ERROR: remove_file expects 1 or 2 arguments")
 	}
 	path := args[0].ToString()
+	if err := checkCapability(rt, "remove_file", CapFSWrite, path); err != nil {
+		return err
+	}
 	force := false
 	if len(args) == 2 {
 		if args[1].GetType() != BooleanType {
@@ -0,0 +1,189 @@
+/*
+File    : go-mix/std/protocols.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - protocols.go
+// This file implements the protocol methods through which struct instances
+// work with the operators and the builtins, like the builtin types do.
+//
+// Protocol methods (declared like any other method of a struct):
+//   - __add__(other), __sub__(other), __mul__(other), __div__(other),
+//     __mod__(other): the arithmetic operators, with the instance on the left
+//   - __eq__(other): == and != (and the builtins comparing for equality)
+//   - __lt__(other): <, >, <=, >= and the order of sort and sorted
+//   - __index__(key) and __setindex__(key, value): obj[key] and obj[key] = value
+//   - __len__(): length and size
+//   - __str__(): print, println, to_string, string concatenation and
+//     interpolation
+//   - __hash__(): map keys and set elements (see hash.go)
+//   - __json__(): the value encoded in place of the instance by the json
+//     package (see json.go)
+package std
+
+// ProtocolMethod returns obj as a struct instance if its struct (or one of
+// its parents) declares the protocol method name.
+func ProtocolMethod(obj GoMixObject, name string) (*GoMixObjectInstance, bool) {
+	inst, ok := obj.(*GoMixObjectInstance)
+	if !ok || inst.Struct == nil {
+		return nil, false
+	}
+	if _, ok := inst.Struct.GetMethod(name); !ok {
+		return nil, false
+	}
+	return inst, true
+}
+
+// CallProtocol calls the protocol method name of obj with args.
+//
+// Returns:
+//   - The result of the method, or an Error
+//   - false if obj has no such method (nothing is called)
+func CallProtocol(rt Runtime, obj GoMixObject, name string, args ...GoMixObject) (GoMixObject, bool) {
+	inst, ok := ProtocolMethod(obj, name)
+	if !ok {
+		return nil, false
+	}
+	return rt.CallMethod(inst, name, args...), true
+}
+
+// protocolResult checks that the protocol method name of obj returned a value
+// of the type want.
+func protocolResult(obj GoMixObject, name string, res GoMixObject, want GoMixType) GoMixObject {
+	if res.GetType() == ErrorType {
+		return res
+	}
+	if res.GetType() != want {
+		return createError("ERROR: %s of struct (%s) must return %s, got %s", name, obj.(*GoMixObjectInstance).Struct.GetName(), want, res.GetType())
+	}
+	return nil
+}
+
+// ProtocolEqual compares a and b with the __eq__ method of a, or of b if a
+// has none.
+//
+// Returns:
+//   - Whether a and b are equal
+//   - false if neither a nor b has an __eq__ method
+//   - nil, or an Error if the method failed or did not return a bool
+func ProtocolEqual(rt Runtime, a, b GoMixObject) (bool, bool, GoMixObject) {
+	if _, ok := ProtocolMethod(a, "__eq__"); !ok {
+		if _, ok := ProtocolMethod(b, "__eq__"); !ok {
+			return false, false, nil
+		}
+		a, b = b, a
+	}
+	res, _ := CallProtocol(rt, a, "__eq__", b)
+	if err := protocolResult(a, "__eq__", res, BooleanType); err != nil {
+		return false, true, err
+	}
+	return res.(*Boolean).Value, true, nil
+}
+
+// ProtocolLess reports whether a < b with the __lt__ method of a. If only b
+// has one, a < b is taken to mean that neither b < a nor b == a.
+//
+// Returns:
+//   - Whether a is less than b
+//   - false if neither a nor b has an __lt__ method
+//   - nil, or an Error if a method failed or did not return a bool
+func ProtocolLess(rt Runtime, a, b GoMixObject) (bool, bool, GoMixObject) {
+	if _, ok := ProtocolMethod(a, "__lt__"); ok {
+		res, _ := CallProtocol(rt, a, "__lt__", b)
+		if err := protocolResult(a, "__lt__", res, BooleanType); err != nil {
+			return false, true, err
+		}
+		return res.(*Boolean).Value, true, nil
+	}
+	if _, ok := ProtocolMethod(b, "__lt__"); !ok {
+		return false, false, nil
+	}
+	greater, _, err := ProtocolLess(rt, b, a)
+	if err != nil || greater {
+		return false, true, err
+	}
+	eq, ok, err := ProtocolEqual(rt, b, a)
+	if !ok {
+		eq = a.ToString() == b.ToString()
+	}
+	return !eq, true, err
+}
+
+// ProtocolLength returns the length of obj from its __len__ method.
+//
+// Returns:
+//   - An Integer, or an Error if the method failed or did not return an int
+//   - false if obj has no __len__ method
+func ProtocolLength(rt Runtime, obj GoMixObject) (GoMixObject, bool) {
+	res, ok := CallProtocol(rt, obj, "__len__")
+	if !ok {
+		return nil, false
+	}
+	if err := protocolResult(obj, "__len__", res, IntegerType); err != nil {
+		return err, true
+	}
+	return res, true
+}
+
+// Stringify returns the string representation of obj, as ToString does, but
+// using the __str__ method of struct instances, including the ones inside
+// arrays, lists, tuples, maps and sets.
+//
+// Returns:
+//   - The string representation of obj
+//   - nil, or an Error if a __str__ method failed or did not return a string
+func Stringify(rt Runtime, obj GoMixObject) (string, GoMixObject) {
+	var elems []GoMixObject
+	var open, close string
+	switch obj := obj.(type) {
+	case *GoMixObjectInstance:
+		res, ok := CallProtocol(rt, obj, "__str__")
+		if !ok {
+			return obj.ToString(), nil
+		}
+		if err := protocolResult(obj, "__str__", res, StringType); err != nil {
+			return "", err
+		}
+		return res.(*String).Value, nil
+	case *Array:
+		elems, open, close = obj.Elements, "[", "]"
+	case *List:
+		elems, open, close = obj.Elements, "list(", ")"
+	case *Tuple:
+		elems, open, close = obj.Elements, "tuple(", ")"
+	case *Set:
+		elems, open, close = obj.Values(), "set{", "}"
+	case *Map:
+		result := "map{"
+		for i, pair := range obj.Entries() {
+			if i > 0 {
+				result += ", "
+			}
+			key, err := Stringify(rt, pair.Key)
+			if err != nil {
+				return "", err
+			}
+			value, err := Stringify(rt, pair.Value)
+			if err != nil {
+				return "", err
+			}
+			result += key + ": " + value
+		}
+		return result + "}", nil
+	default:
+		return obj.ToString(), nil
+	}
+	result := open
+	for i, elem := range elems {
+		if i > 0 {
+			result += ", "
+		}
+		str, err := Stringify(rt, elem)
+		if err != nil {
+			return "", err
+		}
+		result += str
+	}
+	return result + close, nil
+}
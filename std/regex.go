@@ -14,11 +14,11 @@ import (
 )
 
 var regexMethods = []*Builtin{
-	{Name: "match_regex", Callback: regexMatch},     // Checks if a pattern matches a string
-	{Name: "find_regex", Callback: regexFind},       // Finds the first match in a string
-	{Name: "findall_regex", Callback: regexFindAll}, // Finds all matches in a string
-	{Name: "replace_regex", Callback: regexReplace}, // Replaces matches in a string
-	{Name: "split_regex", Callback: regexSplit},     // Splits string by pattern
+	{Name: "match_regex", Callback: regexMatch, Signature: "match_regex(pattern, str) -> bool"},                      // Checks if a pattern matches a string
+	{Name: "find_regex", Callback: regexFind, Signature: "find_regex(pattern, str) -> string"},                       // Finds the first match in a string
+	{Name: "findall_regex", Callback: regexFindAll, Signature: "findall_regex(pattern, str, [n]) -> array"},          // Finds all matches in a string
+	{Name: "replace_regex", Callback: regexReplace, Signature: "replace_regex(pattern, str, replacement) -> string"}, // Replaces matches in a string
+	{Name: "split_regex", Callback: regexSplit, Signature: "split_regex(pattern, str, [n]) -> array"},                // Splits string by pattern
 }
 
 func init() {
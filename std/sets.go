@@ -18,14 +18,14 @@ import (
 // Each Builtin has a name (the method name) and a callback function that implements the behavior.
 // These are appended to the global Builtins slice during package initialization.
 var setMethods = []*Builtin{
-	{Name: "make_set", Callback: setMake},         // Creates a new set
-	{Name: "insert_set", Callback: setInsert},     // Inserts a value into a set
-	{Name: "remove_set", Callback: setRemove},     // Removes a value from a set
-	{Name: "contains_set", Callback: setContains}, // Checks if a set contains a value
-	{Name: "values_set", Callback: setValues},     // Returns an array of all values in a set
+	{Name: "make_set", Callback: setMake, Signature: "make_set(...elements) -> set"},          // Creates a new set
+	{Name: "insert_set", Callback: setInsert, Signature: "insert_set(s, elem) -> any"},        // Inserts a value into a set
+	{Name: "remove_set", Callback: setRemove, Signature: "remove_set(s, elem) -> bool"},       // Removes a value from a set
+	{Name: "contains_set", Callback: setContains, Signature: "contains_set(s, elem) -> bool"}, // Checks if a set contains a value
+	{Name: "values_set", Callback: setValues, Signature: "values_set(s) -> array"},            // Returns an array of all values in a set
 
-	{Name: "size_set", Callback: setSize},   // Returns the number of elements in a set
-	{Name: "length_set", Callback: setSize}, // Applies a function to each element, returning a new set
+	{Name: "size_set", Callback: setSize, Signature: "size_set(s) -> int"},     // Returns the number of elements in a set
+	{Name: "length_set", Callback: setSize, Signature: "length_set(s) -> int"}, // Applies a function to each element, returning a new set
 
 }
 
@@ -64,7 +64,7 @@ func init() {
 // Sets are mutable collections of unique values, implemented using a map for O(1) lookups.
 //
 // Parameters:
-//   - args: Zero or more values to include in the set (will be converted to strings)
+//   - args: Zero or more hashable values to include in the set
 //
 // Returns:
 //   - A new Set object containing the unique values
@@ -74,17 +74,14 @@ func init() {
 //	var s = make_set(1, 2, 3, 2);
 //	// s is now set{1, 2, 3} (duplicate '2' is ignored)
 func setMake(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
-	setObj := &Set{
-		Elements: make(map[string]bool),
-		Values:   []string{},
-	}
+	setObj := NewSet()
 
 	for _, arg := range args {
-		valueStr := arg.ToString()
-		if !setObj.Elements[valueStr] {
-			setObj.Elements[valueStr] = true
-			setObj.Values = append(setObj.Values, valueStr)
+		hk, err := Hash(rt, arg)
+		if err != nil {
+			return err
 		}
+		setObj.Add(hk, arg)
 	}
 
 	return setObj
@@ -95,7 +92,7 @@ func setMake(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 // Parameters:
 //   - args[0]: The set to insert into
-//   - args[1]: The value to insert (will be converted to string)
+//   - args[1]: The value to insert (any hashable value)
 //
 // Returns:
 //   - The inserted value, or Error if wrong arguments
@@ -115,15 +112,14 @@ func setInsert(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	setObj := args[0].(*Set)
-	valueStr := args[1].ToString()
-
-	// Check if value already exists
-	if !setObj.Elements[valueStr] {
-		// New value, add to set
-		setObj.Elements[valueStr] = true
-		setObj.Values = append(setObj.Values, valueStr)
+	hk, err := Hash(rt, args[1])
+	if err != nil {
+		return err
 	}
 
+	// Add the value, unless it already exists
+	setObj.Add(hk, args[1])
+
 	return args[1]
 }
 
@@ -132,7 +128,7 @@ func setInsert(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 //
 // Parameters:
 //   - args[0]: The set to remove from
-//   - args[1]: The value to remove (will be converted to string)
+//   - args[1]: The value to remove
 //
 // Returns:
 //   - Boolean true if value was removed, false if it didn't exist, or Error if wrong arguments
@@ -152,32 +148,19 @@ func setRemove(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	setObj := args[0].(*Set)
-	valueStr := args[1].ToString()
-
-	// Check if value exists
-	if !setObj.Elements[valueStr] {
-		return &Boolean{Value: false}
-	}
-
-	// Remove from elements map
-	delete(setObj.Elements, valueStr)
-
-	// Remove from values list
-	for i, v := range setObj.Values {
-		if v == valueStr {
-			setObj.Values = append(setObj.Values[:i], setObj.Values[i+1:]...)
-			break
-		}
+	hk, err := Hash(rt, args[1])
+	if err != nil {
+		return err
 	}
 
-	return &Boolean{Value: true}
+	return &Boolean{Value: setObj.Remove(hk)}
 }
 
 // setContains checks if a set contains a specific value.
 //
 // Parameters:
 //   - args[0]: The set to check
-//   - args[1]: The value to look for (will be converted to string)
+//   - args[1]: The value to look for
 //
 // Returns:
 //   - Boolean true if value exists, false otherwise, or Error if wrong arguments
@@ -197,9 +180,12 @@ func setContains(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject
 	}
 
 	setObj := args[0].(*Set)
-	valueStr := args[1].ToString()
+	hk, err := Hash(rt, args[1])
+	if err != nil {
+		return err
+	}
 
-	return &Boolean{Value: setObj.Elements[valueStr]}
+	return &Boolean{Value: setObj.Has(hk)}
 }
 
 // setValues returns an array of all values in a set.
@@ -225,13 +211,7 @@ func setValues(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	setObj := args[0].(*Set)
-	valueObjects := make([]GoMixObject, len(setObj.Values))
-
-	for i, value := range setObj.Values {
-		valueObjects[i] = &String{Value: value}
-	}
-
-	return &Array{Elements: valueObjects}
+	return &Array{Elements: setObj.Values()}
 }
 
 // setSize returns the number of elements in a set.
@@ -252,5 +232,5 @@ func setSize(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
 	}
 
 	setObj := args[0].(*Set)
-	return &Integer{Value: int64(len(setObj.Values))}
+	return &Integer{Value: int64(setObj.Len())}
 }
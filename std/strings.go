@@ -18,34 +18,34 @@ import (
 
 var stringMethods = []*Builtin{
 
-	{Name: "upper", Callback: upperString}, // Converts string to uppercase
-	{Name: "lower", Callback: lowerString}, // Converts string to lowercase
-	{Name: "trim", Callback: trimString},   // Trims whitespace from both ends
-	{Name: "ltrim", Callback: ltrimString}, // Trims whitespace from the left
-	{Name: "rtrim", Callback: rtrimString}, // Trims whitespace from the right
-	{Name: "split", Callback: splitString}, // Splits string into an array by separator
-	{Name: "join", Callback: joinString},   // Joins an array into a string with separator
-
-	{Name: "contains_string", Callback: containsString}, // Checks if string contains a substring
-	{Name: "reverse_string", Callback: reverseString},   // Reverses a string
-	{Name: "replace_string", Callback: replaceString},   // Replaces occurrences of a substring
-	{Name: "index_string", Callback: indexString},       // Returns index of first occurrence of substring
-
-	{Name: "ord", Callback: ordString},                // Returns integer value of a character
-	{Name: "chr", Callback: chrString},                // Returns character from integer value
-	{Name: "starts_with", Callback: startsWithString}, // Checks if string starts with prefix
-	{Name: "ends_with", Callback: endsWithString},     // Checks if string ends with suffix
-	{Name: "strcmp", Callback: strcmpString},          // Compares two strings (-1, 0, 1)
-
-	{Name: "substring", Callback: substringString},   // Extracts a part of a string
-	{Name: "capitalize", Callback: capitalizeString}, // Capitalizes the first letter
-	{Name: "count", Callback: countString},           // Counts occurrences of a substring
-	{Name: "repeat", Callback: repeatString},         // Repeats a string n times
-	{Name: "is_digit", Callback: isDigitFuncString},  // Checks if string contains only digits
-	{Name: "is_alpha", Callback: isAlphaFuncString},  // Checks if string contains only letters
-
-	{Name: "size_string", Callback: stringLengthString},   // Returns the length of a string
-	{Name: "length_string", Callback: stringLengthString}, // Returns the length of a string (alias)
+	{Name: "upper", Callback: upperString, Signature: "upper(str) -> string"},     // Converts string to uppercase
+	{Name: "lower", Callback: lowerString, Signature: "lower(str) -> string"},     // Converts string to lowercase
+	{Name: "trim", Callback: trimString, Signature: "trim(str) -> string"},        // Trims whitespace from both ends
+	{Name: "ltrim", Callback: ltrimString, Signature: "ltrim(str) -> string"},     // Trims whitespace from the left
+	{Name: "rtrim", Callback: rtrimString, Signature: "rtrim(str) -> string"},     // Trims whitespace from the right
+	{Name: "split", Callback: splitString, Signature: "split(str, sep) -> array"}, // Splits string into an array by separator
+	{Name: "join", Callback: joinString, Signature: "join(arr, sep) -> string"},   // Joins an array into a string with separator
+
+	{Name: "contains_string", Callback: containsString, Signature: "contains_string(str, sub) -> bool"},     // Checks if string contains a substring
+	{Name: "reverse_string", Callback: reverseString, Signature: "reverse_string(str) -> string"},           // Reverses a string
+	{Name: "replace_string", Callback: replaceString, Signature: "replace_string(str, old, new) -> string"}, // Replaces occurrences of a substring
+	{Name: "index_string", Callback: indexString, Signature: "index_string(str, sub) -> int"},               // Returns index of first occurrence of substring
+
+	{Name: "ord", Callback: ordString, Signature: "ord(char_or_string) -> int"},                      // Returns integer value of a character
+	{Name: "chr", Callback: chrString, Signature: "chr(integer) -> char"},                            // Returns character from integer value
+	{Name: "starts_with", Callback: startsWithString, Signature: "starts_with(str, prefix) -> bool"}, // Checks if string starts with prefix
+	{Name: "ends_with", Callback: endsWithString, Signature: "ends_with(str, suffix) -> bool"},       // Checks if string ends with suffix
+	{Name: "strcmp", Callback: strcmpString, Signature: "strcmp(s1, s2) -> int"},                     // Compares two strings (-1, 0, 1)
+
+	{Name: "substring", Callback: substringString, Signature: "substring(str, start, length) -> string"}, // Extracts a part of a string
+	{Name: "capitalize", Callback: capitalizeString, Signature: "capitalize(str) -> string"},             // Capitalizes the first letter
+	{Name: "count", Callback: countString, Signature: "count(str, sub) -> int"},                          // Counts occurrences of a substring
+	{Name: "repeat", Callback: repeatString, Signature: "repeat(str, count) -> string"},                  // Repeats a string n times
+	{Name: "is_digit", Callback: isDigitFuncString, Signature: "is_digit(str) -> bool"},                  // Checks if string contains only digits
+	{Name: "is_alpha", Callback: isAlphaFuncString, Signature: "is_alpha(str) -> bool"},                  // Checks if string contains only letters
+
+	{Name: "size_string", Callback: stringLengthString, Signature: "size_string(str) -> int"},     // Returns the length of a string
+	{Name: "length_string", Callback: stringLengthString, Signature: "length_string(str) -> int"}, // Returns the length of a string (alias)
 }
 
 // init registers the string methods as global builtins and as a package for import.
@@ -21,14 +21,22 @@ type FunctionInterface interface {
 
 // GoMixStruct represents a user-defined struct type in Go-Mix.
 // It stores the struct name and a list of methods associated with it.
+//
+// A struct may extend another struct: the methods and static fields it does
+// not declare itself are looked up in its parent, then in the parent's parent
+// and so on (the method resolution order is the chain from the struct up).
 type GoMixStruct struct {
 	Name        string                       // Name of the struct type
+	Parent      *GoMixStruct                 // The struct this struct extends (nil if none)
+	Interfaces  []*GoMixInterface            // The interfaces the struct declares it implements
 	Methods     map[string]FunctionInterface // Slice of method objects (using interface to avoid circular imports)
 	FieldNodes  []interface{}                // AST nodes for field declarations (interface{} to avoid import cycle)
+	Fields      []string                     // Names of the declared fields, in declaration order
 	ClassFields map[string]GoMixObject       // Map of class fields (if needed)
 	ConstFields map[string]bool              // Set of constant field names
 	LetFields   map[string]bool              // Set of let field names
 	LetTypes    map[string]GoMixType         // Map of let field types
+	FieldTypes  map[string]*TypeAnnotation   // Map of the declared field types (var x: int = 0)
 }
 
 // GetConstructor returns the constructor function for the struct instance,
@@ -37,13 +45,102 @@ func (o *GoMixStruct) GetConstructor() (FunctionInterface, bool) {
 	return o.GetMethod("init")
 }
 
-// GetMethod retrieves a method by name from the struct's methods.
+// GetMethod retrieves a method by name from the struct's methods, or from
+// the methods inherited from its parents.
 // It returns the method and a boolean indicating if it was found.
 func (g *GoMixStruct) GetMethod(name string) (FunctionInterface, bool) {
-	method, found := g.Methods[name]
+	method, _, found := g.LookUpMethod(name)
 	return method, found
 }
 
+// LookUpMethod finds a method by name along the method resolution order.
+// It returns the method, the struct that declares it and whether it was found.
+func (g *GoMixStruct) LookUpMethod(name string) (FunctionInterface, *GoMixStruct, bool) {
+	for s := g; s != nil; s = s.Parent {
+		if method, found := s.Methods[name]; found {
+			return method, s, true
+		}
+	}
+	return nil, nil, false
+}
+
+// LookUpField finds a static field by name in the struct or its parents.
+// It returns the value, the struct that declares the field and whether it was found.
+func (g *GoMixStruct) LookUpField(name string) (GoMixObject, *GoMixStruct, bool) {
+	for s := g; s != nil; s = s.Parent {
+		if val, found := s.ClassFields[name]; found {
+			return val, s, true
+		}
+	}
+	return nil, nil, false
+}
+
+// LookUpFieldType finds the declared type of a field in the struct or its
+// parents. It returns nil if the field is not annotated.
+func (g *GoMixStruct) LookUpFieldType(name string) *TypeAnnotation {
+	for s := g; s != nil; s = s.Parent {
+		if typ, found := s.FieldTypes[name]; found {
+			return typ
+		}
+	}
+	return nil
+}
+
+// IsA reports whether the struct is other or extends it (directly or not).
+func (g *GoMixStruct) IsA(other *GoMixStruct) bool {
+	for s := g; s != nil; s = s.Parent {
+		if s == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Implements reports whether the struct or one of its parents declares that
+// it implements the interface.
+func (g *GoMixStruct) Implements(iface *GoMixInterface) bool {
+	for _, i := range g.AllInterfaces() {
+		if i == iface {
+			return true
+		}
+	}
+	return false
+}
+
+// AllInterfaces returns the interfaces declared by the struct and by its
+// parents, without duplicates, the struct's own first.
+func (g *GoMixStruct) AllInterfaces() []*GoMixInterface {
+	res := make([]*GoMixInterface, 0)
+	seen := make(map[*GoMixInterface]bool)
+	for s := g; s != nil; s = s.Parent {
+		for _, i := range s.Interfaces {
+			if !seen[i] {
+				seen[i] = true
+				res = append(res, i)
+			}
+		}
+	}
+	return res
+}
+
+// Hierarchy describes the struct by its method resolution order and its
+// interfaces (e.g., "Dog < Animal implements Pet").
+func (g *GoMixStruct) Hierarchy() string {
+	res := g.Name
+	for s := g.Parent; s != nil; s = s.Parent {
+		res += " < " + s.Name
+	}
+	for i, iface := range g.AllInterfaces() {
+		if i == 0 {
+			res += " implements "
+		} else {
+			res += ", "
+		}
+		res += iface.Name
+	}
+	return res
+}
+
 // GetName returns the name of the struct type.
 func (g *GoMixStruct) GetName() string {
 	return g.Name
@@ -87,7 +184,7 @@ func (g *GoMixStruct) ToString() string {
 		}
 		methodStr += fmt.Sprintf("%s(%s); ", name, args)
 	}
-	return fmt.Sprintf("struct(%s) {\nstatic fields: %s\nmethods: %s\n}", g.Name, fieldStr, methodStr)
+	return fmt.Sprintf("struct(%s) {\nstatic fields: %s\nmethods: %s\n}", g.Hierarchy(), fieldStr, methodStr)
 }
 
 // ToObject returns the detailed string representation of the struct including methods.
@@ -160,3 +257,54 @@ func (o *GoMixObjectInstance) ToString() string {
 	res += fmt.Sprintf("methods: %s\n}", methodStr)
 	return res
 }
+
+// TypeName returns the name of the type of obj as shown by the REPL: its
+// type, followed by the hierarchy of the struct for the structs that extend
+// another struct or implement interfaces (e.g., "object(Dog < Animal
+// implements Pet)"). typeof only gives the type; hierarchy gives the rest.
+func TypeName(obj GoMixObject) string {
+	var s *GoMixStruct
+	switch o := obj.(type) {
+	case *GoMixObjectInstance:
+		s = o.Struct
+	case *GoMixStruct:
+		s = o
+	}
+	if s == nil || (s.Parent == nil && len(s.Interfaces) == 0) {
+		return string(obj.GetType())
+	}
+	return fmt.Sprintf("%s(%s)", obj.GetType(), s.Hierarchy())
+}
+
+// BoundMethod is a method of a struct instance used as a value (e.g., var f = obj.area).
+// Calling it calls the method with 'this' bound to the receiver.
+type BoundMethod struct {
+	Receiver *GoMixObjectInstance // The instance the method was taken from
+	Name     string               // The name of the method
+	Struct   *GoMixStruct         // The struct the method is looked up from (nil for the receiver's struct); set by super.method
+}
+
+// LookUp finds the method along the method resolution order of the struct it
+// is looked up from. It returns the method, the struct that declares it and
+// whether it was found.
+func (m *BoundMethod) LookUp() (FunctionInterface, *GoMixStruct, bool) {
+	if m.Struct != nil {
+		return m.Struct.LookUpMethod(m.Name)
+	}
+	return m.Receiver.Struct.LookUpMethod(m.Name)
+}
+
+// GetType returns the type of the bound method, which is "func".
+func (m *BoundMethod) GetType() GoMixType {
+	return FunctionType
+}
+
+// ToString returns the string representation of the bound method in the format "method Struct.name".
+func (m *BoundMethod) ToString() string {
+	return fmt.Sprintf("method %s.%s", m.Receiver.Struct.Name, m.Name)
+}
+
+// ToObject returns the representation of the bound method in the format "<method Struct.name>".
+func (m *BoundMethod) ToObject() string {
+	return fmt.Sprintf("<method %s.%s>", m.Receiver.Struct.Name, m.Name)
+}
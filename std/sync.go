@@ -0,0 +1,513 @@
+/*
+File    : go-mix/std/sync.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - sync.go
+// This file implements channels, mutexes and wait groups for goroutines started
+// with the 'spawn' statement.
+//
+// Go-Mix goroutines take turns running (only one runs Go-Mix code at a time),
+// so the objects below need no locks of their own: every operation that may
+// have to wait goes through Runtime.Wait, which lets the other goroutines run
+// until the operation can complete.
+package std
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// syncMethods is a slice of Builtin pointers representing the concurrency functions.
+// These are appended to the global Builtins slice during package initialization.
+var syncMethods = []*Builtin{
+	{Name: "make_chan", Callback: chanMake, Signature: "make_chan([capacity]) -> chan"},                 // Creates a channel (unbuffered or buffered)
+	{Name: "send_chan", Callback: chanSend, Signature: "send_chan(ch, value) -> nil"},                   // Sends a value on a channel
+	{Name: "recv_chan", Callback: chanRecv, Signature: "recv_chan(ch) -> any"},                          // Receives a value from a channel
+	{Name: "close_chan", Callback: chanClose, Signature: "close_chan(ch) -> nil"},                       // Closes a channel
+	{Name: "select_chan", Callback: chanSelect, Signature: "select_chan(chans, [timeout_ms]) -> array"}, // Receives from the first ready channel of an array
+	{Name: "size_chan", Callback: chanSize, Signature: "size_chan(ch) -> int"},                          // Returns the number of buffered values
+	{Name: "length_chan", Callback: chanSize, Signature: "length_chan(ch) -> int"},                      // Alias for size_chan
+
+	{Name: "make_mutex", Callback: mutexMake, Signature: "make_mutex() -> mutex"},      // Creates a mutex
+	{Name: "lock_mutex", Callback: mutexLock, Signature: "lock_mutex(m) -> nil"},       // Locks a mutex, waiting while it is held
+	{Name: "unlock_mutex", Callback: mutexUnlock, Signature: "unlock_mutex(m) -> nil"}, // Unlocks a mutex
+
+	{Name: "make_waitgroup", Callback: waitGroupMake, Signature: "make_waitgroup() -> waitgroup"}, // Creates a wait group
+	{Name: "add_waitgroup", Callback: waitGroupAdd, Signature: "add_waitgroup(wg, n) -> nil"},     // Adds to the wait group counter
+	{Name: "done_waitgroup", Callback: waitGroupDone, Signature: "done_waitgroup(wg) -> nil"},     // Decrements the wait group counter
+	{Name: "wait_waitgroup", Callback: waitGroupWait, Signature: "wait_waitgroup(wg) -> nil"},     // Waits until the counter drops to zero
+}
+
+// init registers the concurrency functions as global builtins and as the
+// "sync" package.
+//
+// Import Examples:
+//
+//	import "sync"
+//	var wg = sync.make_waitgroup()
+func init() {
+	// Register as global builtins (for backward compatibility)
+	Builtins = append(Builtins, syncMethods...)
+
+	// Register as a package (for import functionality)
+	syncPackage := &Package{
+		Name:      "sync",
+		Functions: make(map[string]*Builtin),
+	}
+	for _, method := range syncMethods {
+		syncPackage.Functions[method.Name] = method
+	}
+	RegisterPackage(syncPackage)
+}
+
+// Channel passes values between goroutines in FIFO order.
+//
+// A channel with capacity 0 is unbuffered: a send waits until a receiver has
+// taken the value. Otherwise a send only waits while Cap values are buffered.
+// Values sent before the channel was closed can still be received; once it is
+// closed and drained, receives return nil immediately.
+type Channel struct {
+	Cap      int           // Capacity of the buffer (0 for an unbuffered channel)
+	Buffer   []GoMixObject // Values sent but not yet received
+	Closed   bool          // Whether close_chan has been called
+	sent     int64         // Number of values sent so far
+	received int64         // Number of values received so far
+}
+
+// GetType returns the type of the Channel object
+func (c *Channel) GetType() GoMixType {
+	return ChanType
+}
+
+// ToString returns a description of the channel
+func (c *Channel) ToString() string {
+	if c.Closed {
+		return fmt.Sprintf("chan(%d/%d, closed)", len(c.Buffer), c.Cap)
+	}
+	return fmt.Sprintf("chan(%d/%d)", len(c.Buffer), c.Cap)
+}
+
+// ToObject returns a description of the channel
+func (c *Channel) ToObject() string {
+	return "<" + c.ToString() + ">"
+}
+
+// Send sends value on the channel, waiting for buffer space (or, for an
+// unbuffered channel, for a receiver).
+//
+// Returns:
+//   - nil on success, or an Error if the channel is closed or the wait failed
+func (c *Channel) Send(rt Runtime, value GoMixObject) GoMixObject {
+	var ticket int64
+	closed := false
+	_, err := rt.Wait(func() bool {
+		if c.Closed {
+			closed = true
+			return true
+		}
+		if len(c.Buffer) >= c.Cap && (c.Cap > 0 || len(c.Buffer) > 0) {
+			return false
+		}
+		c.Buffer = append(c.Buffer, value)
+		c.sent++
+		ticket = c.sent
+		return true
+	}, 0)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return createError("ERROR: send on closed channel")
+	}
+	if c.Cap == 0 {
+		// unbuffered: hand over the value before returning
+		if _, err := rt.Wait(func() bool { return c.received >= ticket || c.Closed }, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Receive takes the next value from the channel, waiting until one is sent.
+//
+// Returns:
+//   - The value, or nil once the channel is closed and drained
+//   - Whether a value was received
+//   - nil, or an Error if the wait failed
+func (c *Channel) Receive(rt Runtime) (GoMixObject, bool, GoMixObject) {
+	var value GoMixObject
+	if _, err := rt.Wait(func() bool {
+		value = c.take()
+		return value != nil || c.Closed
+	}, 0); err != nil {
+		return nil, false, err
+	}
+	if value == nil {
+		return &Nil{}, false, nil
+	}
+	return value, true, nil
+}
+
+// take removes and returns the first buffered value, or nil if there is none.
+func (c *Channel) take() GoMixObject {
+	if len(c.Buffer) == 0 {
+		return nil
+	}
+	value := c.Buffer[0]
+	c.Buffer = c.Buffer[1:]
+	c.received++
+	return value
+}
+
+// Mutex is a mutual exclusion lock for goroutines.
+type Mutex struct {
+	Locked bool // Whether a goroutine holds the lock
+}
+
+// GetType returns the type of the Mutex object
+func (m *Mutex) GetType() GoMixType {
+	return MutexType
+}
+
+// ToString returns a description of the mutex
+func (m *Mutex) ToString() string {
+	if m.Locked {
+		return "mutex(locked)"
+	}
+	return "mutex(unlocked)"
+}
+
+// ToObject returns a description of the mutex
+func (m *Mutex) ToObject() string {
+	return "<" + m.ToString() + ">"
+}
+
+// WaitGroup waits for a collection of goroutines to finish.
+type WaitGroup struct {
+	Count int64 // Number of goroutines still running
+}
+
+// GetType returns the type of the WaitGroup object
+func (wg *WaitGroup) GetType() GoMixType {
+	return WaitGroupType
+}
+
+// ToString returns a description of the wait group
+func (wg *WaitGroup) ToString() string {
+	return fmt.Sprintf("waitgroup(%d)", wg.Count)
+}
+
+// ToObject returns a description of the wait group
+func (wg *WaitGroup) ToObject() string {
+	return "<" + wg.ToString() + ">"
+}
+
+// chanMake creates a new channel.
+//
+// Syntax: make_chan([capacity])
+//
+// Example:
+//
+//	var jobs = make_chan(10);   // buffered channel
+//	var done = make_chan();     // unbuffered channel
+func chanMake(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) > 1 {
+		return createError("ERROR: make_chan expects 0 or 1 argument (capacity)")
+	}
+	capacity := int64(0)
+	if len(args) == 1 {
+		if args[0].GetType() != IntegerType {
+			return createError("ERROR: capacity of make_chan must be an integer, got '%s'", args[0].GetType())
+		}
+		capacity = args[0].(*Integer).Value
+		if capacity < 0 {
+			return createError("ERROR: capacity of make_chan must not be negative, got %d", capacity)
+		}
+	}
+	return &Channel{Cap: int(capacity)}
+}
+
+// chanSend sends a value on a channel, waiting while the channel is full.
+//
+// Syntax: send_chan(ch, value)
+func chanSend(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 {
+		return createError("ERROR: send_chan expects 2 arguments (chan, value)")
+	}
+	ch, ok := args[0].(*Channel)
+	if !ok {
+		return createError("ERROR: first argument to send_chan must be a chan, got '%s'", args[0].GetType())
+	}
+	if err := ch.Send(rt, args[1]); err != nil {
+		return err
+	}
+	return &Nil{}
+}
+
+// chanRecv receives a value from a channel, waiting until one is sent.
+// It returns nil once the channel is closed and drained.
+//
+// Syntax: recv_chan(ch)
+func chanRecv(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: recv_chan expects 1 argument (chan)")
+	}
+	ch, ok := args[0].(*Channel)
+	if !ok {
+		return createError("ERROR: argument to recv_chan must be a chan, got '%s'", args[0].GetType())
+	}
+	value, _, err := ch.Receive(rt)
+	if err != nil {
+		return err
+	}
+	return value
+}
+
+// chanClose closes a channel: waiting receivers get nil once it is drained.
+//
+// Syntax: close_chan(ch)
+func chanClose(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: close_chan expects 1 argument (chan)")
+	}
+	ch, ok := args[0].(*Channel)
+	if !ok {
+		return createError("ERROR: argument to close_chan must be a chan, got '%s'", args[0].GetType())
+	}
+	if ch.Closed {
+		return createError("ERROR: close of closed channel")
+	}
+	// wake up the goroutines waiting on the channel
+	if _, err := rt.Wait(func() bool {
+		ch.Closed = true
+		return true
+	}, 0); err != nil {
+		return err
+	}
+	return &Nil{}
+}
+
+// chanSelect receives from whichever channel of an array is ready first.
+// A channel is ready when it holds a value or is closed; channels earlier in
+// the array win ties. With a timeout (in milliseconds) it gives up after that
+// long, and a timeout of 0 only takes a value that is ready right away.
+//
+// Syntax: select_chan([ch1, ch2, ...], [timeout_ms])
+//
+// Returns:
+//   - [index, value]: the index of the channel and the received value (nil if
+//     the channel is closed), or [-1, nil] if the timeout expired
+//
+// Example:
+//
+//	var res = select_chan([results, errors], 1000);
+//	if (res[0] == 0) { println("result:", res[1]); }
+func chanSelect(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) < 1 || len(args) > 2 {
+		return createError("ERROR: select_chan expects 1 or 2 arguments (chans, [timeout_ms])")
+	}
+	arr, ok := args[0].(*Array)
+	if !ok {
+		return createError("ERROR: first argument to select_chan must be an array of chans, got '%s'", args[0].GetType())
+	}
+	chans := make([]*Channel, len(arr.Elements))
+	for i, elem := range arr.Elements {
+		if chans[i], ok = elem.(*Channel); !ok {
+			return createError("ERROR: select_chan expects an array of chans, got '%s' at index %d", elem.GetType(), i)
+		}
+	}
+	timeout := time.Duration(0)
+	poll := false
+	if len(args) == 2 {
+		if args[1].GetType() != IntegerType {
+			return createError("ERROR: timeout of select_chan must be an integer, got '%s'", args[1].GetType())
+		}
+		timeout = time.Duration(args[1].(*Integer).Value) * time.Millisecond
+		poll = timeout <= 0
+	}
+
+	index := -1
+	var value GoMixObject = &Nil{}
+	ready := func() bool {
+		for i, ch := range chans {
+			if v := ch.take(); v != nil {
+				index, value = i, v
+				return true
+			}
+			if ch.Closed {
+				index = i
+				return true
+			}
+		}
+		return false
+	}
+	if poll {
+		// poll without waiting (still waking the senders of a taken value)
+		if _, err := rt.Wait(func() bool { ready(); return true }, 0); err != nil {
+			return err
+		}
+	} else if _, err := rt.Wait(ready, timeout); err != nil {
+		return err
+	}
+	return &Array{Elements: []GoMixObject{&Integer{Value: int64(index)}, value}}
+}
+
+// chanSize returns the number of values buffered in a channel.
+//
+// Syntax: size_chan(ch)
+func chanSize(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: size_chan expects 1 argument (chan)")
+	}
+	ch, ok := args[0].(*Channel)
+	if !ok {
+		return createError("ERROR: argument to size_chan must be a chan, got '%s'", args[0].GetType())
+	}
+	return &Integer{Value: int64(len(ch.Buffer))}
+}
+
+// mutexMake creates a new, unlocked mutex.
+//
+// Syntax: make_mutex()
+func mutexMake(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 0 {
+		return createError("ERROR: make_mutex expects 0 arguments")
+	}
+	return &Mutex{}
+}
+
+// mutexLock locks a mutex, waiting while another goroutine holds it.
+//
+// Syntax: lock_mutex(m)
+func mutexLock(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	m, err := mutexArg("lock_mutex", args)
+	if err != nil {
+		return err
+	}
+	if _, err := rt.Wait(func() bool {
+		if m.Locked {
+			return false
+		}
+		m.Locked = true
+		return true
+	}, 0); err != nil {
+		return err
+	}
+	return &Nil{}
+}
+
+// mutexUnlock unlocks a mutex.
+//
+// Syntax: unlock_mutex(m)
+func mutexUnlock(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	m, err := mutexArg("unlock_mutex", args)
+	if err != nil {
+		return err
+	}
+	if !m.Locked {
+		return createError("ERROR: unlock of unlocked mutex")
+	}
+	if _, err := rt.Wait(func() bool {
+		m.Locked = false
+		return true
+	}, 0); err != nil {
+		return err
+	}
+	return &Nil{}
+}
+
+// mutexArg checks the arguments of a mutex builtin.
+func mutexArg(name string, args []GoMixObject) (*Mutex, GoMixObject) {
+	if len(args) != 1 {
+		return nil, createError("ERROR: %s expects 1 argument (mutex)", name)
+	}
+	m, ok := args[0].(*Mutex)
+	if !ok {
+		return nil, createError("ERROR: argument to %s must be a mutex, got '%s'", name, args[0].GetType())
+	}
+	return m, nil
+}
+
+// waitGroupMake creates a new wait group with a zero counter.
+//
+// Syntax: make_waitgroup()
+//
+// Example:
+//
+//	var wg = make_waitgroup();
+//	foreach i in 1..3 {
+//	    add_waitgroup(wg, 1);
+//	    spawn worker(i, wg);    // calls done_waitgroup(wg) when finished
+//	}
+//	wait_waitgroup(wg);
+func waitGroupMake(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 0 {
+		return createError("ERROR: make_waitgroup expects 0 arguments")
+	}
+	return &WaitGroup{}
+}
+
+// waitGroupAdd adds delta to the counter of a wait group.
+//
+// Syntax: add_waitgroup(wg, delta)
+func waitGroupAdd(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 {
+		return createError("ERROR: add_waitgroup expects 2 arguments (waitgroup, delta)")
+	}
+	wg, ok := args[0].(*WaitGroup)
+	if !ok {
+		return createError("ERROR: first argument to add_waitgroup must be a waitgroup, got '%s'", args[0].GetType())
+	}
+	if args[1].GetType() != IntegerType {
+		return createError("ERROR: delta of add_waitgroup must be an integer, got '%s'", args[1].GetType())
+	}
+	return waitGroupChange(rt, wg, args[1].(*Integer).Value)
+}
+
+// waitGroupDone decrements the counter of a wait group.
+//
+// Syntax: done_waitgroup(wg)
+func waitGroupDone(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: done_waitgroup expects 1 argument (waitgroup)")
+	}
+	wg, ok := args[0].(*WaitGroup)
+	if !ok {
+		return createError("ERROR: argument to done_waitgroup must be a waitgroup, got '%s'", args[0].GetType())
+	}
+	return waitGroupChange(rt, wg, -1)
+}
+
+// waitGroupChange adds delta to the counter of wg, waking its waiters.
+func waitGroupChange(rt Runtime, wg *WaitGroup, delta int64) GoMixObject {
+	if wg.Count+delta < 0 {
+		return createError("ERROR: negative waitgroup counter")
+	}
+	if _, err := rt.Wait(func() bool {
+		wg.Count += delta
+		return true
+	}, 0); err != nil {
+		return err
+	}
+	return &Nil{}
+}
+
+// waitGroupWait waits until the counter of a wait group drops to zero.
+//
+// Syntax: wait_waitgroup(wg)
+func waitGroupWait(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: wait_waitgroup expects 1 argument (waitgroup)")
+	}
+	wg, ok := args[0].(*WaitGroup)
+	if !ok {
+		return createError("ERROR: argument to wait_waitgroup must be a waitgroup, got '%s'", args[0].GetType())
+	}
+	if _, err := rt.Wait(func() bool { return wg.Count == 0 }, 0); err != nil {
+		return err
+	}
+	return &Nil{}
+}
@@ -16,12 +16,12 @@ import (
 )
 
 var timeMethods = []*Builtin{
-	{Name: "now", Callback: now},                // Returns current Unix timestamp (seconds)
-	{Name: "now_ms", Callback: nowMs},           // Returns current Unix timestamp (milliseconds)
-	{Name: "utc_now", Callback: utcNow},         // Returns current UTC Unix timestamp
-	{Name: "format_time", Callback: formatTime}, // Formats a Unix timestamp
-	{Name: "parse_time", Callback: parseTime},   // Parses a time string to Unix timestamp
-	{Name: "timezone", Callback: timezone},      // Returns current timezone name
+	{Name: "now", Callback: now, Signature: "now() -> int"},                                            // Returns current Unix timestamp (seconds)
+	{Name: "now_ms", Callback: nowMs, Signature: "now_ms() -> int"},                                    // Returns current Unix timestamp (milliseconds)
+	{Name: "utc_now", Callback: utcNow, Signature: "utc_now() -> int"},                                 // Returns current UTC Unix timestamp
+	{Name: "format_time", Callback: formatTime, Signature: "format_time(timestamp, layout) -> string"}, // Formats a Unix timestamp
+	{Name: "parse_time", Callback: parseTime, Signature: "parse_time(value, layout) -> int"},           // Parses a time string to Unix timestamp
+	{Name: "timezone", Callback: timezone, Signature: "timezone() -> string"},                          // Returns current timezone name
 }
 
 // init registers the time methods as global builtins and as a package for import.
@@ -0,0 +1,905 @@
+/*
+File    : go-mix/std/toml.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - toml.go
+// This file implements the toml package: reading TOML documents into maps and
+// writing maps as TOML documents.
+//
+// Tables become maps (keys in the order of the document) and arrays of tables
+// arrays of maps. Ints become ints (bigints past 64 bits), floats (with inf
+// and nan) floats, and dates and times are read as strings (e.g.,
+// "1979-05-27T07:32:00Z"). Written values are converted into plain data like
+// JSON values (see codec.go); a document must be a map, and nil cannot be
+// written, as TOML has no null.
+package std
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var tomlMethods = []*Builtin{
+	{Name: "parse_toml", Callback: tomlParse, Signature: "parse_toml(text) -> map"},                 // Reads a TOML document into a map
+	{Name: "read_toml", Callback: tomlRead, Signature: "read_toml(path) -> map"},                    // Reads a TOML file into a map
+	{Name: "stringify_toml", Callback: tomlStringify, Signature: "stringify_toml(value) -> string"}, // Writes a map as a TOML document
+	{Name: "write_toml", Callback: tomlWrite, Signature: "write_toml(path, value) -> nil"},          // Writes a map into a TOML file
+}
+
+func init() {
+	tomlPackage := &Package{
+		Name:      "toml",
+		Functions: make(map[string]*Builtin),
+	}
+	for _, method := range tomlMethods {
+		tomlPackage.Functions[method.Name] = method
+	}
+	RegisterPackage(tomlPackage)
+}
+
+// tomlParse reads a TOML document into a map.
+//
+// Syntax: parse_toml(text)
+//
+// Example:
+//
+//	parse_toml("name = \"app\"\n[server]\nport = 8080\n") -> map{name: "app", server: map{port: 8080}}
+func tomlParse(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: parse_toml expects 1 argument (text)")
+	}
+	if args[0].GetType() != StringType && args[0].GetType() != BytesType {
+		return createError("ERROR: argument to `parse_toml` must be a string or bytes, got '%s'", args[0].GetType())
+	}
+	return decodeTOML(BytesOf(args[0]), "")
+}
+
+// tomlRead reads a TOML file into a map.
+//
+// Syntax: read_toml(path)
+func tomlRead(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: read_toml expects 1 argument (path)")
+	}
+	path := args[0].ToString()
+	data, errObj := readCodecFile(rt, "read_toml", path)
+	if errObj != nil {
+		return errObj
+	}
+	return decodeTOML(data, " of "+path)
+}
+
+// tomlStringify writes a map as a TOML document: its plain keys first, then
+// its tables as [table] sections and its arrays of maps as [[array]]
+// sections. Maps inside arrays are written as inline tables.
+//
+// Syntax: stringify_toml(value)
+//
+// Example:
+//
+//	stringify_toml(map{"name": "app", "server": map{"port": 8080}})
+//	-> "name = \"app\"\n\n[server]\nport = 8080\n"
+func tomlStringify(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: stringify_toml expects 1 argument (value)")
+	}
+	text, errObj := encodeTOML(rt, "stringify_toml", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	return &String{Value: string(text)}
+}
+
+// tomlWrite writes a map into a TOML file (see stringify_toml).
+//
+// Syntax: write_toml(path, value)
+func tomlWrite(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 {
+		return createError("ERROR: write_toml expects 2 arguments (path, value)")
+	}
+	text, errObj := encodeTOML(rt, "write_toml", args[1])
+	if errObj != nil {
+		return errObj
+	}
+	return writeCodecFile(rt, "write_toml", args[0].ToString(), text)
+}
+
+// decodeTOML reads a TOML document into a map. where is appended to the
+// positions of errors (e.g., " of config.toml").
+func decodeTOML(data []byte, where string) GoMixObject {
+	p := &tomlParser{
+		src:     string(data),
+		where:   where,
+		root:    NewMap(),
+		headers: make(map[*Map]bool),
+		dotted:  make(map[*Map]bool),
+		inline:  make(map[*Map]bool),
+		arrays:  make(map[*Array]bool),
+	}
+	p.table = p.root
+	if err := p.parse(); err != nil {
+		return err
+	}
+	return p.root
+}
+
+// tomlParser reads a TOML document. Besides the maps it builds, it keeps how
+// each table was defined, to reject the redefinitions TOML forbids.
+type tomlParser struct {
+	src     string
+	pos     int
+	where   string // Appended to the positions of errors
+	root    *Map
+	table   *Map            // The table the key-value pairs go into
+	headers map[*Map]bool   // The tables defined by a [table] header
+	dotted  map[*Map]bool   // The tables defined by dotted keys (a.b = 1)
+	inline  map[*Map]bool   // The inline tables, which cannot be extended
+	arrays  map[*Array]bool // The arrays defined by [[array]] headers
+}
+
+// parse reads the document, one header or key-value pair per line.
+func (p *tomlParser) parse() *Error {
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil
+		}
+		var err *Error
+		if p.src[p.pos] == '[' {
+			err = p.header()
+		} else {
+			err = p.keyValue(p.table)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.lineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+// header reads a [table] or [[array]] header and makes its table the current
+// one.
+func (p *tomlParser) header() *Error {
+	start := p.pos
+	open, close := "[", "]"
+	if strings.HasPrefix(p.src[p.pos:], "[[") {
+		open, close = "[[", "]]"
+	}
+	p.pos += len(open)
+	p.skipSpace()
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(p.src[p.pos:], close) {
+		return p.errorAt(p.pos, fmt.Sprintf("expected %q after the table name, got %s", close, p.next()))
+	}
+	p.pos += len(close)
+
+	table := p.root
+	for i := range keys[:len(keys)-1] {
+		if table, err = p.descend(table, keys[:i+1], start); err != nil {
+			return err
+		}
+	}
+	last := keys[len(keys)-1]
+	val, ok := table.GetString(last)
+	if open == "[[" {
+		if !ok {
+			arr := &Array{Elements: make([]GoMixObject, 0)}
+			p.arrays[arr] = true
+			table.SetString(last, arr)
+			val = arr
+		}
+		arr, isArray := val.(*Array)
+		if !isArray || !p.arrays[arr] {
+			return p.errorAt(start, fmt.Sprintf("key %s is already defined", tomlKeyPath(keys)))
+		}
+		p.table = NewMap()
+		arr.Elements = append(arr.Elements, p.table)
+		return nil
+	}
+	if !ok {
+		p.table = NewMap()
+		p.headers[p.table] = true
+		table.SetString(last, p.table)
+		return nil
+	}
+	t, isMap := val.(*Map)
+	if !isMap || p.headers[t] || p.dotted[t] || p.inline[t] {
+		return p.errorAt(start, fmt.Sprintf("table %s is already defined", tomlKeyPath(keys)))
+	}
+	// A table created by the header of one of its sub-tables
+	p.headers[t] = true
+	p.table = t
+	return nil
+}
+
+// descend returns the table named by the last of keys in table, for the
+// header at start: a new table if there is none, or the last table of an
+// array of tables.
+func (p *tomlParser) descend(table *Map, keys []string, start int) (*Map, *Error) {
+	name := keys[len(keys)-1]
+	val, ok := table.GetString(name)
+	if !ok {
+		t := NewMap()
+		table.SetString(name, t)
+		return t, nil
+	}
+	switch v := val.(type) {
+	case *Map:
+		if !p.inline[v] {
+			return v, nil
+		}
+		return nil, p.errorAt(start, fmt.Sprintf("inline table %s cannot be extended", tomlKeyPath(keys)))
+	case *Array:
+		if p.arrays[v] {
+			return v.Elements[len(v.Elements)-1].(*Map), nil
+		}
+	}
+	return nil, p.errorAt(start, fmt.Sprintf("key %s is already defined as a value", tomlKeyPath(keys)))
+}
+
+// keyValue reads a key-value pair into table. A dotted key defines the
+// tables it goes through.
+func (p *tomlParser) keyValue(table *Map) *Error {
+	start := p.pos
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return p.errorAt(p.pos, fmt.Sprintf("expected '=' after the key, got %s", p.next()))
+	}
+	p.pos++
+	p.skipSpace()
+	val, err := p.value()
+	if err != nil {
+		return err
+	}
+	for i, name := range keys[:len(keys)-1] {
+		v, ok := table.GetString(name)
+		if !ok {
+			t := NewMap()
+			p.dotted[t] = true
+			table.SetString(name, t)
+			table = t
+			continue
+		}
+		t, isMap := v.(*Map)
+		if !isMap || !p.dotted[t] {
+			return p.errorAt(start, fmt.Sprintf("key %s is already defined", tomlKeyPath(keys[:i+1])))
+		}
+		table = t
+	}
+	last := keys[len(keys)-1]
+	if _, ok := table.GetString(last); ok {
+		return p.errorAt(start, fmt.Sprintf("key %s is already defined", tomlKeyPath(keys)))
+	}
+	table.SetString(last, val)
+	return nil
+}
+
+// key reads a key (bare, quoted or dotted) and the spaces after it.
+func (p *tomlParser) key() ([]string, *Error) {
+	keys := make([]string, 0, 1)
+	for {
+		var name string
+		var err *Error
+		switch p.peek() {
+		case '"':
+			p.pos++
+			name, err = p.basicString(false)
+		case '\'':
+			p.pos++
+			name, err = p.literalString(false)
+		default:
+			start := p.pos
+			for p.pos < len(p.src) && isTOMLBare(p.src[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.errorAt(p.pos, fmt.Sprintf("expected a key, got %s", p.next()))
+			}
+			name = p.src[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, name)
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+		p.skipSpace()
+	}
+}
+
+// tomlDateTime matches the dates and times that start a value: an offset or
+// local date-time, a local date or a local time.
+var tomlDateTime = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?([Zz]|[+-]\d{2}:\d{2})?)?|\d{2}:\d{2}(:\d{2}(\.\d+)?)?)`)
+
+// The forms of TOML numbers (underscores only between digits)
+var (
+	tomlDecimal = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlFloat   = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	tomlHex     = regexp.MustCompile(`^0x[0-9a-fA-F](_?[0-9a-fA-F])*$`)
+	tomlOctal   = regexp.MustCompile(`^0o[0-7](_?[0-7])*$`)
+	tomlBinary  = regexp.MustCompile(`^0b[01](_?[01])*$`)
+)
+
+// value reads a value.
+func (p *tomlParser) value() (GoMixObject, *Error) {
+	start := p.pos
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		p.pos += 3
+		s, err := p.basicString(true)
+		return &String{Value: s}, err
+	case strings.HasPrefix(rest, `'''`):
+		p.pos += 3
+		s, err := p.literalString(true)
+		return &String{Value: s}, err
+	case strings.HasPrefix(rest, `"`):
+		p.pos++
+		s, err := p.basicString(false)
+		return &String{Value: s}, err
+	case strings.HasPrefix(rest, `'`):
+		p.pos++
+		s, err := p.literalString(false)
+		return &String{Value: s}, err
+	case strings.HasPrefix(rest, "["):
+		p.pos++
+		return p.array()
+	case strings.HasPrefix(rest, "{"):
+		p.pos++
+		return p.inlineTable()
+	}
+	if m := tomlDateTime.FindString(rest); m != "" {
+		p.pos += len(m)
+		return &String{Value: m}, nil
+	}
+	for p.pos < len(p.src) && (isTOMLBare(p.src[p.pos]) || p.src[p.pos] == '+' || p.src[p.pos] == '.') {
+		p.pos++
+	}
+	word := p.src[start:p.pos]
+	switch word {
+	case "true":
+		return &Boolean{Value: true}, nil
+	case "false":
+		return &Boolean{Value: false}, nil
+	case "inf", "+inf":
+		return &Float{Value: math.Inf(1)}, nil
+	case "-inf":
+		return &Float{Value: math.Inf(-1)}, nil
+	case "nan", "+nan", "-nan":
+		return &Float{Value: math.NaN()}, nil
+	case "":
+		return nil, p.errorAt(start, fmt.Sprintf("expected a value, got %s", p.next()))
+	}
+	digits := strings.ReplaceAll(word, "_", "")
+	base := 0
+	switch {
+	case tomlDecimal.MatchString(word):
+		base = 10
+	case tomlHex.MatchString(word):
+		base, digits = 16, digits[2:]
+	case tomlOctal.MatchString(word):
+		base, digits = 8, digits[2:]
+	case tomlBinary.MatchString(word):
+		base, digits = 2, digits[2:]
+	case tomlFloat.MatchString(word):
+		f, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return nil, p.errorAt(start, fmt.Sprintf("number %s is out of range", word))
+		}
+		return &Float{Value: f}, nil
+	default:
+		return nil, p.errorAt(start, fmt.Sprintf("invalid value %q", word))
+	}
+	n, _ := new(big.Int).SetString(strings.TrimPrefix(digits, "+"), base)
+	return NewInteger(n), nil
+}
+
+// array reads the elements of an array, after its '['. Elements may be on
+// several lines, with comments, and followed by a comma.
+func (p *tomlParser) array() (GoMixObject, *Error) {
+	elements := make([]GoMixObject, 0)
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return &Array{Elements: elements}, nil
+		}
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, val)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorAt(p.pos, fmt.Sprintf("expected ',' or ']' in the array, got %s", p.next()))
+		}
+	}
+}
+
+// inlineTable reads the pairs of an inline table, after its '{'. It is on
+// one line, and neither it nor its sub-tables can be extended afterwards.
+func (p *tomlParser) inlineTable() (GoMixObject, *Error) {
+	table := NewMap()
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		p.inline[table] = true
+		return table, nil
+	}
+	for {
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			p.skipSpace()
+		case '}':
+			p.pos++
+			p.freeze(table)
+			return table, nil
+		default:
+			return nil, p.errorAt(p.pos, fmt.Sprintf("expected ',' or '}' in the inline table, got %s", p.next()))
+		}
+	}
+}
+
+// freeze marks an inline table and the tables defined in it as inline.
+func (p *tomlParser) freeze(table *Map) {
+	p.inline[table] = true
+	for _, pair := range table.Entries() {
+		if t, ok := pair.Value.(*Map); ok && p.dotted[t] {
+			p.freeze(t)
+		}
+	}
+}
+
+// basicString reads a string in double quotes, after its opening quotes.
+func (p *tomlParser) basicString(multiline bool) (string, *Error) {
+	start := p.pos
+	if multiline {
+		p.skipNewline()
+	}
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorAt(start, "unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '"' && !multiline:
+			p.pos++
+			return sb.String(), nil
+		case c == '"' && strings.HasPrefix(p.src[p.pos:], `"""`):
+			return p.closeMultiline(&sb, '"')
+		case c == '\\':
+			if err := p.escape(&sb, multiline); err != nil {
+				return "", err
+			}
+		case c == '\n' && multiline:
+			sb.WriteByte(c)
+			p.pos++
+		case c == '\r' && multiline && strings.HasPrefix(p.src[p.pos:], "\r\n"):
+			sb.WriteString("\r\n")
+			p.pos += 2
+		case c < 0x20 && c != '\t', c == 0x7F:
+			return "", p.errorAt(p.pos, fmt.Sprintf("control character %U in a string", c))
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// literalString reads a string in single quotes (without escapes), after its
+// opening quotes.
+func (p *tomlParser) literalString(multiline bool) (string, *Error) {
+	start := p.pos
+	if multiline {
+		p.skipNewline()
+	}
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorAt(start, "unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '\'' && !multiline:
+			p.pos++
+			return sb.String(), nil
+		case c == '\'' && strings.HasPrefix(p.src[p.pos:], "'''"):
+			return p.closeMultiline(&sb, '\'')
+		case c == '\n' && multiline:
+			sb.WriteByte(c)
+			p.pos++
+		case c == '\r' && multiline && strings.HasPrefix(p.src[p.pos:], "\r\n"):
+			sb.WriteString("\r\n")
+			p.pos += 2
+		case c < 0x20 && c != '\t', c == 0x7F:
+			return "", p.errorAt(p.pos, fmt.Sprintf("control character %U in a string", c))
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// closeMultiline ends a multiline string at its closing quotes. Up to two
+// more quotes before them belong to the string.
+func (p *tomlParser) closeMultiline(sb *strings.Builder, quote byte) (string, *Error) {
+	n := 0
+	for p.pos+n < len(p.src) && p.src[p.pos+n] == quote {
+		n++
+	}
+	if n > 5 {
+		return "", p.errorAt(p.pos+5, fmt.Sprintf("too many %c quotes at the end of a string", quote))
+	}
+	sb.WriteString(strings.Repeat(string(quote), n-3))
+	p.pos += n
+	return sb.String(), nil
+}
+
+// escape reads an escape sequence of a basic string. In a multiline string, a
+// backslash at the end of a line removes the line break and the whitespace
+// after it.
+func (p *tomlParser) escape(sb *strings.Builder, multiline bool) *Error {
+	start := p.pos
+	p.pos++
+	if p.pos >= len(p.src) {
+		return p.errorAt(start, "unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte(0x1B)
+	case '"', '\\':
+		sb.WriteByte(c)
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorAt(start, "invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorAt(start, fmt.Sprintf("invalid unicode escape \\%c%s", c, p.src[p.pos:p.pos+n]))
+		}
+		sb.WriteRune(rune(code))
+		p.pos += n
+	default:
+		if multiline && (c == ' ' || c == '\t' || c == '\n' || c == '\r') {
+			p.pos--
+			p.skipSpace()
+			if p.peek() == '\n' || strings.HasPrefix(p.src[p.pos:], "\r\n") {
+				p.skipBlankLines()
+				return nil
+			}
+		}
+		return p.errorAt(start, fmt.Sprintf("invalid escape \\%c", c))
+	}
+	return nil
+}
+
+// lineEnd reads the end of a line: spaces, a comment, then a line break or
+// the end of the document.
+func (p *tomlParser) lineEnd() *Error {
+	p.skipSpace()
+	p.skipComment()
+	if p.pos < len(p.src) && !p.skipNewline() {
+		return p.errorAt(p.pos, fmt.Sprintf("expected the end of the line, got %s", p.next()))
+	}
+	return nil
+}
+
+// skipSpace skips spaces and tabs.
+func (p *tomlParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment up to the end of its line.
+func (p *tomlParser) skipComment() {
+	if p.peek() == '#' {
+		for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+			p.pos++
+		}
+	}
+}
+
+// skipNewline skips a line break (\n or \r\n) and reports whether there was
+// one.
+func (p *tomlParser) skipNewline() bool {
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "\n"):
+		p.pos++
+	case strings.HasPrefix(p.src[p.pos:], "\r\n"):
+		p.pos += 2
+	default:
+		return false
+	}
+	return true
+}
+
+// skipBlankLines skips whitespace and line breaks.
+func (p *tomlParser) skipBlankLines() {
+	for {
+		p.skipSpace()
+		if !p.skipNewline() {
+			return
+		}
+	}
+}
+
+// skipBlank skips whitespace, line breaks and comments.
+func (p *tomlParser) skipBlank() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		if !p.skipNewline() {
+			return
+		}
+	}
+}
+
+// peek returns the byte at the current position, or 0 at the end.
+func (p *tomlParser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// next describes the character at the current position for errors.
+func (p *tomlParser) next() string {
+	if p.pos >= len(p.src) {
+		return "the end of the document"
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return strconv.QuoteRune(r)
+}
+
+// errorAt returns an error at the byte offset of the document, given as a
+// line and a column (in characters, counted from 1).
+func (p *tomlParser) errorAt(offset int, msg string) *Error {
+	before := p.src[:offset]
+	line := 1 + strings.Count(before, "\n")
+	column := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return createError("ERROR: invalid TOML at line %d, column %d%s: %s", line, column, p.where, msg)
+}
+
+// isTOMLBare reports whether c may appear in a bare key.
+func isTOMLBare(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// tomlKey returns a key as written in TOML: bare if it can be, quoted if not.
+func tomlKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isTOMLBare(key[i]) {
+			return tomlString(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
+// tomlKeyPath returns the dotted key of a path of keys (e.g., server."web host").
+func tomlKeyPath(keys []string) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = tomlKey(key)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlString returns s as a TOML basic string.
+func tomlString(s string) string {
+	var buf bytes.Buffer
+	writeJSONString(&buf, s)
+	// The JSON escapes are TOML escapes too, but TOML also escapes DEL
+	return strings.ReplaceAll(buf.String(), "\x7f", `\u007f`)
+}
+
+// encodeTOML writes a map as a TOML document.
+func encodeTOML(rt Runtime, name string, obj GoMixObject) ([]byte, GoMixObject) {
+	data, errObj := codecData(rt, "TOML", obj)
+	if errObj != nil {
+		return nil, errObj
+	}
+	doc, ok := data.(*Map)
+	if !ok {
+		return nil, createError("ERROR: argument to `%s` must be a map (a TOML document is a table), got '%s'", name, obj.GetType())
+	}
+	enc := &tomlEncoder{}
+	if errObj := enc.table(nil, doc); errObj != nil {
+		return nil, errObj
+	}
+	return enc.buf.Bytes(), nil
+}
+
+// tomlEncoder writes the TOML document of plain data.
+type tomlEncoder struct {
+	buf bytes.Buffer
+}
+
+// table writes the pairs of the table at path: its plain values, then its
+// tables and arrays of tables, each under its own header. A table holding
+// only tables gets no header of its own.
+func (enc *tomlEncoder) table(path []string, table *Map) GoMixObject {
+	pairs := table.Entries()
+	for _, pair := range pairs {
+		if isTOMLTable(pair.Value) || isTOMLTableArray(pair.Value) {
+			continue
+		}
+		key := pair.Key.ToString()
+		enc.buf.WriteString(tomlKey(key) + " = ")
+		if errObj := enc.value(append(path[:len(path):len(path)], key), pair.Value); errObj != nil {
+			return errObj
+		}
+		enc.buf.WriteByte('\n')
+	}
+	for _, pair := range pairs {
+		sub := append(path[:len(path):len(path)], pair.Key.ToString())
+		switch v := pair.Value.(type) {
+		case *Map:
+			if v.Len() == 0 || !tomlOnlyTables(v) {
+				enc.header("[", sub, "]")
+			}
+			if errObj := enc.table(sub, v); errObj != nil {
+				return errObj
+			}
+		case *Array:
+			if !isTOMLTableArray(v) {
+				continue
+			}
+			for _, elem := range v.Elements {
+				enc.header("[[", sub, "]]")
+				if errObj := enc.table(sub, elem.(*Map)); errObj != nil {
+					return errObj
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// header writes the header of a table, after a blank line.
+func (enc *tomlEncoder) header(open string, path []string, close string) {
+	if enc.buf.Len() > 0 {
+		enc.buf.WriteByte('\n')
+	}
+	enc.buf.WriteString(open + tomlKeyPath(path) + close + "\n")
+}
+
+// value writes a value on one line; path is the key it belongs to, for
+// errors.
+func (enc *tomlEncoder) value(path []string, obj GoMixObject) GoMixObject {
+	switch o := obj.(type) {
+	case *Nil:
+		return createError("ERROR: cannot encode nil as TOML (key %s)", tomlKeyPath(path))
+	case *Boolean:
+		enc.buf.WriteString(strconv.FormatBool(o.Value))
+	case *Integer:
+		enc.buf.WriteString(strconv.FormatInt(o.Value, 10))
+	case *BigInt:
+		return createError("ERROR: cannot encode bigint %s as TOML (key %s): TOML ints have 64 bits", o.ToString(), tomlKeyPath(path))
+	case *Float:
+		switch {
+		case math.IsNaN(o.Value):
+			enc.buf.WriteString("nan")
+		case math.IsInf(o.Value, 1):
+			enc.buf.WriteString("inf")
+		case math.IsInf(o.Value, -1):
+			enc.buf.WriteString("-inf")
+		default:
+			enc.buf.WriteString(formatFloat(o.Value))
+		}
+	case *Decimal:
+		s := o.ToString()
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		enc.buf.WriteString(s)
+	case *String:
+		enc.buf.WriteString(tomlString(o.Value))
+	case *Array:
+		enc.buf.WriteByte('[')
+		for i, elem := range o.Elements {
+			if i > 0 {
+				enc.buf.WriteString(", ")
+			}
+			if errObj := enc.value(path, elem); errObj != nil {
+				return errObj
+			}
+		}
+		enc.buf.WriteByte(']')
+	case *Map:
+		enc.buf.WriteByte('{')
+		for i, pair := range o.Entries() {
+			if i > 0 {
+				enc.buf.WriteString(", ")
+			}
+			key := pair.Key.ToString()
+			enc.buf.WriteString(tomlKey(key) + " = ")
+			if errObj := enc.value(append(path[:len(path):len(path)], key), pair.Value); errObj != nil {
+				return errObj
+			}
+		}
+		enc.buf.WriteByte('}')
+	}
+	return nil
+}
+
+// isTOMLTable reports whether a value is written as a [table].
+func isTOMLTable(obj GoMixObject) bool {
+	_, ok := obj.(*Map)
+	return ok
+}
+
+// isTOMLTableArray reports whether a value is written as an [[array]] of
+// tables: a non-empty array of maps.
+func isTOMLTableArray(obj GoMixObject) bool {
+	arr, ok := obj.(*Array)
+	if !ok || len(arr.Elements) == 0 {
+		return false
+	}
+	for _, elem := range arr.Elements {
+		if !isTOMLTable(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlOnlyTables reports whether all the values of a table are tables or
+// arrays of tables.
+func tomlOnlyTables(table *Map) bool {
+	for _, pair := range table.Entries() {
+		if !isTOMLTable(pair.Value) && !isTOMLTableArray(pair.Value) {
+			return false
+		}
+	}
+	return true
+}
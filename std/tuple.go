@@ -18,23 +18,23 @@ import (
 // These are appended to the global Builtins slice during package initialization.
 var tupleMethods = []*Builtin{
 
-	{Name: "make_tuple", Callback: tupleFunc}, // Creates a new immutable tuple from arguments
+	{Name: "make_tuple", Callback: tupleFunc, Signature: "make_tuple(...elements) -> tuple"}, // Creates a new immutable tuple from arguments
 
-	{Name: "peekback_tuple", Callback: peekbackTuple},   // Returns the last element of a tuple
-	{Name: "peekfront_tuple", Callback: peekfrontTuple}, // Returns the first element of a tuple
-	{Name: "contains_tuple", Callback: containsTuple},   // Checks if a value exists in the tuple
+	{Name: "peekback_tuple", Callback: peekbackTuple, Signature: "peekback_tuple(t) -> any"},        // Returns the last element of a tuple
+	{Name: "peekfront_tuple", Callback: peekfrontTuple, Signature: "peekfront_tuple(t) -> any"},     // Returns the first element of a tuple
+	{Name: "contains_tuple", Callback: containsTuple, Signature: "contains_tuple(t, elem) -> bool"}, // Checks if a value exists in the tuple
 
-	{Name: "map_tuple", Callback: mapList},       // Applies a function to each element
-	{Name: "filter_tuple", Callback: filterList}, // Filters elements based on a predicate
-	{Name: "reduce_tuple", Callback: reduceList}, // Reduces the list to a single value using a binary function
-	{Name: "find_tuple", Callback: findTuple},    // Finds the first element matching a predicate
-	{Name: "some_tuple", Callback: someTuple},    // Checks if at least one element matches
-	{Name: "every_tuple", Callback: everyTuple},  // Checks if all elements match
+	{Name: "map_tuple", Callback: mapList, Signature: "map_tuple(t, function) -> list"},                  // Applies a function to each element
+	{Name: "filter_tuple", Callback: filterList, Signature: "filter_tuple(t, function) -> list"},         // Filters elements based on a predicate
+	{Name: "reduce_tuple", Callback: reduceList, Signature: "reduce_tuple(t, function, initial) -> any"}, // Reduces the list to a single value using a binary function
+	{Name: "find_tuple", Callback: findTuple, Signature: "find_tuple(t, function) -> any"},               // Finds the first element matching a predicate
+	{Name: "some_tuple", Callback: someTuple, Signature: "some_tuple(t, function) -> bool"},              // Checks if at least one element matches
+	{Name: "every_tuple", Callback: everyTuple, Signature: "every_tuple(t, function) -> bool"},           // Checks if all elements match
 
-	{Name: "to_tuple", Callback: toTuple}, // Converts array/list to tuple
+	{Name: "to_tuple", Callback: toTuple, Signature: "to_tuple(iterable) -> tuple"}, // Converts array/list to tuple
 
-	{Name: "size_tuple", Callback: sizeTuple},   // Returns the number of elements in a tuple
-	{Name: "length_tuple", Callback: sizeTuple}, // Returns the number of elements in a tuple (alias)
+	{Name: "size_tuple", Callback: sizeTuple, Signature: "size_tuple(t) -> int"},     // Returns the number of elements in a tuple
+	{Name: "length_tuple", Callback: sizeTuple, Signature: "length_tuple(t) -> int"}, // Returns the number of elements in a tuple (alias)
 }
 
 // init registers the tuple methods by appending them to the global Builtins slice.
@@ -65,6 +65,22 @@ const (
 	ServerType GoMixType = "server"
 	// EnumType represents an enum type definition
 	EnumType GoMixType = "enum"
+	// InterfaceType represents an interface declaration
+	InterfaceType GoMixType = "interface"
+	// ChanType represents a channel between goroutines
+	ChanType GoMixType = "chan"
+	// MutexType represents a mutual exclusion lock
+	MutexType GoMixType = "mutex"
+	// WaitGroupType represents a wait group counting running goroutines
+	WaitGroupType GoMixType = "waitgroup"
+	// GeneratorType represents a lazy sequence (see generator.go)
+	GeneratorType GoMixType = "generator"
+	// BigIntType represents integers of any size (see bignum.go)
+	BigIntType GoMixType = "bigint"
+	// DecimalType represents decimal numbers with a fixed scale (see bignum.go)
+	DecimalType GoMixType = "decimal"
+	// BytesType represents immutable sequences of bytes (see bytes.go)
+	BytesType GoMixType = "bytes"
 )
 
 // GoMixObject is the core interface that all Go-Mix objects must implement.
@@ -92,6 +108,12 @@ func ExtractValue(obj GoMixObject) (interface{}, error) {
 	case FloatType:
 		// Extract the float64 value from a Float object
 		return obj.(*Float).Value, nil
+	case BigIntType, DecimalType:
+		// Bigints and decimals format themselves (see bignum.go)
+		return obj, nil
+	case BytesType:
+		// Extract the byte slice from a Bytes object (%x prints it as hex)
+		return obj.(*Bytes).Value, nil
 	case CharType:
 		// Extract the rune value from a Char object
 		return obj.(*Char).Value, nil
@@ -246,8 +268,15 @@ func (n *Nil) ToObject() string {
 
 // Error represents an error object in Go-Mix.
 // It wraps an error message as a string and provides methods for type identification and display.
+// Line and Column record where the error was raised, when known, so that a catch block
+// can inspect them; Value holds the original value of a throw statement.
+// Trace records the calls that were active when the error left a function.
 type Error struct {
-	Message string // The error message
+	Message string      // The error message
+	Line    int         // Source line where the error was raised (0 if unknown)
+	Column  int         // Source column where the error was raised (0 if unknown)
+	Value   GoMixObject // The thrown value for errors raised by 'throw' (nil otherwise)
+	Trace   []Frame     // Call stack of the error, innermost call first (nil if not raised in a call)
 }
 
 // GetType returns the type of the Error object
@@ -352,11 +381,82 @@ func (r *Range) ToObject() string {
 }
 
 // Map represents a key-value map in Go-Mix.
-// It uses a Go map internally with string keys (converted from int/string Go-Mix objects)
-// and GoMixObject values. Maps are mutable and can be modified after creation.
+// Its keys are hashable values of any type (see hash.go), kept with their
+// types: map{1: "a", "1": "b"} has two keys. Maps keep the insertion order of
+// their keys and are mutable.
 type Map struct {
-	Pairs map[string]GoMixObject // Internal Go map storing key-value pairs
-	Keys  []string               // Ordered list of keys for iteration
+	Pairs map[HashKey]*MapPair // The pairs, by the hashes of their keys
+	Keys  []HashKey            // The hashes of the keys, in insertion order
+}
+
+// MapPair is a key of a map with its value.
+type MapPair struct {
+	Key   GoMixObject
+	Value GoMixObject
+}
+
+// NewMap returns an empty map.
+func NewMap() *Map {
+	return &Map{Pairs: make(map[HashKey]*MapPair), Keys: make([]HashKey, 0)}
+}
+
+// Get returns the value of the key with the hash hk.
+func (m *Map) Get(hk HashKey) (GoMixObject, bool) {
+	if pair, ok := m.Pairs[hk]; ok {
+		return pair.Value, true
+	}
+	return nil, false
+}
+
+// Set stores value under key, whose hash is hk. A key already in the map
+// keeps its position (and its original key object).
+func (m *Map) Set(hk HashKey, key, value GoMixObject) {
+	if pair, ok := m.Pairs[hk]; ok {
+		pair.Value = value
+		return
+	}
+	m.Pairs[hk] = &MapPair{Key: key, Value: value}
+	m.Keys = append(m.Keys, hk)
+}
+
+// Delete removes the key with the hash hk and returns its value.
+func (m *Map) Delete(hk HashKey) (GoMixObject, bool) {
+	pair, ok := m.Pairs[hk]
+	if !ok {
+		return nil, false
+	}
+	delete(m.Pairs, hk)
+	for i, k := range m.Keys {
+		if k == hk {
+			m.Keys = append(m.Keys[:i], m.Keys[i+1:]...)
+			break
+		}
+	}
+	return pair.Value, true
+}
+
+// GetString returns the value of the string key name.
+func (m *Map) GetString(name string) (GoMixObject, bool) {
+	return m.Get(StringKey(name))
+}
+
+// SetString stores value under the string key name.
+func (m *Map) SetString(name string, value GoMixObject) {
+	m.Set(StringKey(name), &String{Value: name}, value)
+}
+
+// Len returns the number of keys of the map.
+func (m *Map) Len() int {
+	return len(m.Keys)
+}
+
+// Entries returns the pairs of the map, in insertion order.
+func (m *Map) Entries() []*MapPair {
+	pairs := make([]*MapPair, len(m.Keys))
+	for i, hk := range m.Keys {
+		pairs[i] = m.Pairs[hk]
+	}
+	return pairs
 }
 
 // GetType returns the type of the Map object
@@ -370,11 +470,11 @@ func (m *Map) ToString() string {
 		return "map{}"
 	}
 	result := "map{"
-	for i, key := range m.Keys {
+	for i, pair := range m.Entries() {
 		if i > 0 {
 			result += ", "
 		}
-		result += key + ": " + m.Pairs[key].ToString()
+		result += pair.Key.ToString() + ": " + pair.Value.ToString()
 	}
 	result += "}"
 	return result
@@ -386,22 +486,73 @@ func (m *Map) ToObject() string {
 		return "<map{}>"
 	}
 	result := "<map{"
-	for i, key := range m.Keys {
+	for i, pair := range m.Entries() {
 		if i > 0 {
 			result += ", "
 		}
-		result += key + ": " + m.Pairs[key].ToObject()
+		result += pair.Key.ToString() + ": " + pair.Value.ToObject()
 	}
 	result += "}>"
 	return result
 }
 
 // Set represents a collection of unique values in Go-Mix.
-// It uses a Go map internally with string keys (converted from Go-Mix objects)
-// to ensure uniqueness. Sets are mutable and can be modified after creation.
+// Its elements are hashable values of any type (see hash.go), kept with
+// their types and in insertion order. Sets are mutable.
 type Set struct {
-	Elements map[string]bool // Internal Go map for O(1) membership testing
-	Values   []string        // Ordered list of values for iteration
+	Elements map[HashKey]GoMixObject // The elements, by their hashes
+	Keys     []HashKey               // The hashes of the elements, in insertion order
+}
+
+// NewSet returns an empty set.
+func NewSet() *Set {
+	return &Set{Elements: make(map[HashKey]GoMixObject), Keys: make([]HashKey, 0)}
+}
+
+// Has reports whether the set holds the element with the hash hk.
+func (s *Set) Has(hk HashKey) bool {
+	_, ok := s.Elements[hk]
+	return ok
+}
+
+// Add adds value, whose hash is hk, and reports whether it was new.
+func (s *Set) Add(hk HashKey, value GoMixObject) bool {
+	if s.Has(hk) {
+		return false
+	}
+	s.Elements[hk] = value
+	s.Keys = append(s.Keys, hk)
+	return true
+}
+
+// Remove removes the element with the hash hk and reports whether it was
+// in the set.
+func (s *Set) Remove(hk HashKey) bool {
+	if !s.Has(hk) {
+		return false
+	}
+	delete(s.Elements, hk)
+	for i, k := range s.Keys {
+		if k == hk {
+			s.Keys = append(s.Keys[:i], s.Keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Len returns the number of elements of the set.
+func (s *Set) Len() int {
+	return len(s.Keys)
+}
+
+// Values returns the elements of the set, in insertion order.
+func (s *Set) Values() []GoMixObject {
+	values := make([]GoMixObject, len(s.Keys))
+	for i, hk := range s.Keys {
+		values[i] = s.Elements[hk]
+	}
+	return values
 }
 
 // GetType returns the type of the Set object
@@ -411,15 +562,15 @@ func (s *Set) GetType() GoMixType {
 
 // ToString returns a string representation of the set as "set{elem1, elem2, ...}"
 func (s *Set) ToString() string {
-	if len(s.Values) == 0 {
+	if len(s.Keys) == 0 {
 		return "set{}"
 	}
 	result := "set{"
-	for i, val := range s.Values {
+	for i, val := range s.Values() {
 		if i > 0 {
 			result += ", "
 		}
-		result += val
+		result += val.ToString()
 	}
 	result += "}"
 	return result
@@ -427,15 +578,15 @@ func (s *Set) ToString() string {
 
 // ToObject returns a detailed representation of the set
 func (s *Set) ToObject() string {
-	if len(s.Values) == 0 {
+	if len(s.Keys) == 0 {
 		return "<set{}>"
 	}
 	result := "<set{"
-	for i, val := range s.Values {
+	for i, val := range s.Values() {
 		if i > 0 {
 			result += ", "
 		}
-		result += val
+		result += val.ToString()
 	}
 	result += "}>"
 	return result
@@ -0,0 +1,376 @@
+/*
+File    : go-mix/std/yaml.go
+Author  : Akash Maji
+Contact : akashmaji(@iisc.ac.in)
+*/
+
+// Package std - yaml.go
+// This file implements the yaml package: reading YAML documents into Go-Mix
+// values and writing values as YAML documents.
+//
+// Mappings become maps (keys read as strings, in the order of the document)
+// and sequences arrays. Scalars are read by their tags: ints become ints
+// (bigints past 64 bits), floats floats, bools bools, nulls nil, timestamps
+// strings and !!binary values bytes. Anchors and aliases are expanded, and
+// merge keys (<<) bring in the keys of other mappings. Written values are
+// converted into plain data like JSON values (see codec.go).
+package std
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var yamlMethods = []*Builtin{
+	{Name: "parse_yaml", Callback: yamlParse, Signature: "parse_yaml(text) -> any"},                            // Reads a YAML document into a value
+	{Name: "read_yaml", Callback: yamlRead, Signature: "read_yaml(path) -> any"},                               // Reads a YAML file into a value
+	{Name: "stringify_yaml", Callback: yamlStringify, Signature: "stringify_yaml(value, [options]) -> string"}, // Writes a value as a YAML document
+	{Name: "write_yaml", Callback: yamlWrite, Signature: "write_yaml(path, value, [options]) -> nil"},          // Writes a value into a YAML file
+}
+
+func init() {
+	yamlPackage := &Package{
+		Name:      "yaml",
+		Functions: make(map[string]*Builtin),
+	}
+	for _, method := range yamlMethods {
+		yamlPackage.Functions[method.Name] = method
+	}
+	RegisterPackage(yamlPackage)
+}
+
+// yamlParse reads a YAML document into a value. Only the first document of a
+// stream is read; an empty text gives nil.
+//
+// Syntax: parse_yaml(text)
+//
+// Example:
+//
+//	parse_yaml("name: app\nports: [80, 443]\n") -> map{name: "app", ports: [80, 443]}
+func yamlParse(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: parse_yaml expects 1 argument (text)")
+	}
+	if args[0].GetType() != StringType && args[0].GetType() != BytesType {
+		return createError("ERROR: argument to `parse_yaml` must be a string or bytes, got '%s'", args[0].GetType())
+	}
+	return decodeYAML(BytesOf(args[0]), "")
+}
+
+// yamlRead reads a YAML file into a value (see parse_yaml).
+//
+// Syntax: read_yaml(path)
+func yamlRead(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 {
+		return createError("ERROR: read_yaml expects 1 argument (path)")
+	}
+	path := args[0].ToString()
+	data, errObj := readCodecFile(rt, "read_yaml", path)
+	if errObj != nil {
+		return errObj
+	}
+	return decodeYAML(data, " of "+path)
+}
+
+// yamlStringify writes a value as a YAML document, in block style. Strings
+// that would read back as other values (e.g., "true" or "1") are quoted. The
+// options are:
+//   - "indent": the number of spaces of each level, from 2 to 9 (default 2)
+//
+// Syntax: stringify_yaml(value, [options])
+//
+// Example:
+//
+//	stringify_yaml(map{"name": "app", "ports": [80, 443]})
+//	-> "name: app\nports:\n  - 80\n  - 443\n"
+func yamlStringify(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 1 && len(args) != 2 {
+		return createError("ERROR: stringify_yaml expects 1 or 2 arguments (value, [options])")
+	}
+	text, errObj := encodeYAML(rt, "stringify_yaml", args[0], args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &String{Value: string(text)}
+}
+
+// yamlWrite writes a value into a YAML file, with the options of
+// stringify_yaml.
+//
+// Syntax: write_yaml(path, value, [options])
+func yamlWrite(rt Runtime, writer io.Writer, args ...GoMixObject) GoMixObject {
+	if len(args) != 2 && len(args) != 3 {
+		return createError("ERROR: write_yaml expects 2 or 3 arguments (path, value, [options])")
+	}
+	text, errObj := encodeYAML(rt, "write_yaml", args[1], args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	return writeCodecFile(rt, "write_yaml", args[0].ToString(), text)
+}
+
+// yamlErrorLine matches the errors of the YAML parser that give a line.
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlInt matches the plain scalars that are ints (decimal, hexadecimal,
+// octal or binary).
+var yamlInt = regexp.MustCompile(`^([-+]?[0-9][0-9_]*|0x[0-9a-fA-F_]+|0o[0-7_]+|0b[01_]+)$`)
+
+// decodeYAML reads the first YAML document of data. where is appended to the
+// positions of errors (e.g., " of config.yaml").
+func decodeYAML(data []byte, where string) GoMixObject {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		msg := strings.TrimPrefix(err.Error(), "yaml: ")
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			return createError("ERROR: invalid YAML at line %s%s: %s", m[1], where, m[2])
+		}
+		return createError("ERROR: invalid YAML%s: %s", where, msg)
+	}
+	if len(doc.Content) == 0 {
+		return &Nil{}
+	}
+	d := &yamlDecoder{where: where, active: make(map[*yaml.Node]bool)}
+	val, err := d.node(doc.Content[0])
+	if err != nil {
+		return err
+	}
+	return val
+}
+
+// yamlDecoder converts the nodes of a YAML document into Go-Mix values.
+type yamlDecoder struct {
+	where  string              // Appended to the positions of errors
+	active map[*yaml.Node]bool // The anchored nodes being expanded, to reject cycles
+}
+
+// node converts a node and its children.
+func (d *yamlDecoder) node(n *yaml.Node) (GoMixObject, *Error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return d.node(n.Content[0])
+	case yaml.AliasNode:
+		if d.active[n.Alias] {
+			return nil, d.errorAt(n, "alias *"+n.Value+" contains itself")
+		}
+		d.active[n.Alias] = true
+		defer delete(d.active, n.Alias)
+		return d.node(n.Alias)
+	case yaml.SequenceNode:
+		elements := make([]GoMixObject, len(n.Content))
+		for i, child := range n.Content {
+			val, err := d.node(child)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = val
+		}
+		return &Array{Elements: elements}, nil
+	case yaml.MappingNode:
+		return d.mapping(n)
+	}
+	return d.scalar(n)
+}
+
+// mapping converts a mapping node into a map. The keys of the mapping win
+// over merged ones, and earlier merged mappings over later ones.
+func (d *yamlDecoder) mapping(n *yaml.Node) (GoMixObject, *Error) {
+	m := NewMap()
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keyNode, valNode := n.Content[i], n.Content[i+1]
+		if keyNode.Kind == yaml.ScalarNode && keyNode.ShortTag() == "!!merge" {
+			if err := d.merge(m, valNode); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		key, err := d.key(keyNode)
+		if err != nil {
+			return nil, err
+		}
+		if explicit[key] {
+			return nil, d.errorAt(keyNode, strconv.Quote(key)+" is already defined")
+		}
+		explicit[key] = true
+		val, err := d.node(valNode)
+		if err != nil {
+			return nil, err
+		}
+		m.SetString(key, val)
+	}
+	return m, nil
+}
+
+// merge adds to m the keys it does not have yet from the value of a merge
+// key: a mapping, or a sequence of mappings.
+func (d *yamlDecoder) merge(m *Map, n *yaml.Node) *Error {
+	sources := []*yaml.Node{n}
+	if resolveAlias(n).Kind == yaml.SequenceNode {
+		sources = resolveAlias(n).Content
+	}
+	for _, source := range sources {
+		if resolveAlias(source).Kind != yaml.MappingNode {
+			return d.errorAt(source, "a merge key (<<) must refer to a mapping or a sequence of mappings")
+		}
+		val, err := d.node(source)
+		if err != nil {
+			return err
+		}
+		for _, pair := range val.(*Map).Entries() {
+			if _, ok := m.GetString(pair.Key.ToString()); !ok {
+				m.SetString(pair.Key.ToString(), pair.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node an alias refers to, or n if it is not one.
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// key returns the text of a mapping key, which must be a scalar.
+func (d *yamlDecoder) key(n *yaml.Node) (string, *Error) {
+	if resolveAlias(n).Kind != yaml.ScalarNode {
+		return "", d.errorAt(n, "a mapping key must be a scalar")
+	}
+	return resolveAlias(n).Value, nil
+}
+
+// scalar converts a scalar node by its tag.
+func (d *yamlDecoder) scalar(n *yaml.Node) (GoMixObject, *Error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return &Nil{}, nil
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return nil, d.errorAt(n, "invalid bool "+strconv.Quote(n.Value))
+		}
+		return &Boolean{Value: b}, nil
+	case "!!int":
+		var i int64
+		if err := n.Decode(&i); err == nil {
+			return &Integer{Value: i}, nil
+		}
+		if b, ok := new(big.Int).SetString(strings.ReplaceAll(n.Value, "_", ""), 0); ok {
+			return NewInteger(b), nil
+		}
+		return nil, d.errorAt(n, "invalid int "+strconv.Quote(n.Value))
+	case "!!float":
+		if n.Style&yaml.TaggedStyle == 0 && yamlInt.MatchString(n.Value) {
+			// An untagged int too large for 64 bits, which the parser tags !!float
+			if b, ok := new(big.Int).SetString(strings.ReplaceAll(n.Value, "_", ""), 0); ok {
+				return NewInteger(b), nil
+			}
+		}
+		var f float64
+		if err := n.Decode(&f); err != nil {
+			return nil, d.errorAt(n, "invalid float "+strconv.Quote(n.Value))
+		}
+		return &Float{Value: f}, nil
+	case "!!binary":
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(n.Value), ""))
+		if err != nil {
+			return nil, d.errorAt(n, "invalid base64 in a !!binary value")
+		}
+		return &Bytes{Value: data}, nil
+	}
+	// Strings, timestamps and values of other tags
+	return &String{Value: n.Value}, nil
+}
+
+// errorAt returns an error at the position of a node.
+func (d *yamlDecoder) errorAt(n *yaml.Node, msg string) *Error {
+	return createError("ERROR: invalid YAML at line %d, column %d%s: %s", n.Line, n.Column, d.where, msg)
+}
+
+// encodeYAML writes obj as a YAML document with the options given as the
+// argument i of the builtin name.
+func encodeYAML(rt Runtime, name string, obj GoMixObject, args []GoMixObject, i int) ([]byte, GoMixObject) {
+	opts, errObj := codecOptions(name, args, i, "indent")
+	if errObj != nil {
+		return nil, errObj
+	}
+	indent := 2
+	if val, ok := opts.GetString("indent"); ok {
+		n, isInt := val.(*Integer)
+		if !isInt || n.Value < 2 || n.Value > 9 {
+			return nil, createError("ERROR: option \"indent\" of `%s` must be a number of spaces from 2 to 9, got '%s'", name, val.ToString())
+		}
+		indent = int(n.Value)
+	}
+	data, errObj := codecData(rt, "YAML", obj)
+	if errObj != nil {
+		return nil, errObj
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(yamlNode(data)); err != nil {
+		return nil, createError("ERROR: failed to encode YAML: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, createError("ERROR: failed to encode YAML: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlNode returns the node of a plain data value. Tagging strings !!str has
+// the encoder quote those that would read back as other values.
+func yamlNode(obj GoMixObject) *yaml.Node {
+	switch o := obj.(type) {
+	case *Nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case *Boolean:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(o.Value)}
+	case *Integer, *BigInt:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: o.ToString()}
+	case *Float:
+		value := ""
+		switch {
+		case math.IsNaN(o.Value):
+			value = ".nan"
+		case math.IsInf(o.Value, 1):
+			value = ".inf"
+		case math.IsInf(o.Value, -1):
+			value = "-.inf"
+		default:
+			value = formatFloat(o.Value)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: value}
+	case *Decimal:
+		value := o.ToString()
+		if !strings.Contains(value, ".") {
+			value += ".0"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: value}
+	case *Array:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, elem := range o.Elements {
+			n.Content = append(n.Content, yamlNode(elem))
+		}
+		return n
+	case *Map:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, pair := range o.Entries() {
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: pair.Key.ToString()}
+			n.Content = append(n.Content, key, yamlNode(pair.Value))
+		}
+		return n
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: obj.ToString()}
+}
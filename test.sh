@@ -1,8 +1,5 @@
 #! /bin/bash
 # build.sh: runs all test files, builds project, and runs executable
 # test
-go test ./main/...
-go test ./lexer/...
-go test ./parser/...
-go test ./eval/...
-
+go test ./...
+go test ./eval/... -vm